@@ -3,114 +3,114 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/auth"
+	cfgpkg "github.com/Jerinji2016/halooid/backend/internal/config"
 	"github.com/Jerinji2016/halooid/backend/internal/gateway"
+	"github.com/Jerinji2016/halooid/backend/internal/migration"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/migrations"
+	"github.com/Jerinji2016/halooid/backend/pkg/health"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
 )
 
 func main() {
-	// Initialize logger
-	logger := log.New(os.Stdout, "API-GATEWAY: ", log.LstdFlags)
-
 	// Load configuration
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "./configs/api-gateway.yaml"
+	config, result, err := gateway.LoadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.PrintConfig {
+		if err := cfgpkg.Print(os.Stdout, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	config, err := gateway.LoadConfig(configPath)
+	// Initialize logger
+	appLogger, err := logger.Setup(logger.Config{
+		Level:  config.Logging.Level,
+		Format: config.Logging.Format,
+	}, "api-gateway")
 	if err != nil {
-		logger.Printf("Failed to load configuration from %s: %v", configPath, err)
-		logger.Println("Using default configuration")
-		config = gateway.DefaultConfig()
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Get database connection parameters from environment variables
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "halooid")
-	dbPassword := getEnv("DB_PASSWORD", "halooid_password")
-	dbName := getEnv("DB_NAME", "halooid")
+	redacted, err := cfgpkg.Redacted(config)
+	if err != nil {
+		logger.Fatal(appLogger.Logger, "failed to redact configuration", slog.Any("error", err))
+	}
+	appLogger.Info("configuration loaded",
+		slog.String("path", result.Path),
+		slog.Any("config", redacted),
+	)
 
 	// Connect to PostgreSQL
-	dbConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
-	db, err := sqlx.Connect("postgres", dbConnStr)
+	db, err := sqlx.Connect("postgres", config.Database.DSN())
 	if err != nil {
-		logger.Fatalf("Failed to connect to database: %v", err)
+		logger.Fatal(appLogger.Logger, "failed to connect to database", slog.Any("error", err))
 	}
 	defer db.Close()
 
-	// Get Redis connection parameters from environment variables
-	redisHost := getEnv("REDIS_HOST", "localhost")
-	redisPort := getEnv("REDIS_PORT", "6379")
-	redisPassword := getEnv("REDIS_PASSWORD", "")
-	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	db.SetMaxOpenConns(config.Database.MaxOpenConns)
+	db.SetMaxIdleConns(config.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(config.Database.ConnMaxLifetime)
+
+	// Apply pending schema migrations
+	if config.Database.AutoMigrate {
+		if err := migration.Up(context.Background(), db, migrations.FS); err != nil {
+			logger.Fatal(appLogger.Logger, "failed to apply migrations", slog.Any("error", err))
+		}
+	}
 
 	// Connect to Redis
 	redisClient := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", redisHost, redisPort),
-		Password: redisPassword,
-		DB:       redisDB,
+		Addr:     config.Redis.Addr(),
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
 	})
 	defer redisClient.Close()
 
 	// Ping Redis to check connection
 	_, err = redisClient.Ping(context.Background()).Result()
 	if err != nil {
-		logger.Fatalf("Failed to connect to Redis: %v", err)
+		logger.Fatal(appLogger.Logger, "failed to connect to Redis", slog.Any("error", err))
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewPostgresUserRepository(db)
 
-	// Get auth configuration from environment variables
-	accessTokenSecret := getEnv("ACCESS_TOKEN_SECRET", "your-access-token-secret")
-	refreshTokenSecret := getEnv("REFRESH_TOKEN_SECRET", "your-refresh-token-secret")
-	accessTokenExpiryStr := getEnv("ACCESS_TOKEN_EXPIRY", "15m")
-	refreshTokenExpiryStr := getEnv("REFRESH_TOKEN_EXPIRY", "168h")
-	issuer := getEnv("ISSUER", "halooid-auth-service")
-
-	// Parse token expiry durations
-	accessTokenExpiry, err := time.ParseDuration(accessTokenExpiryStr)
-	if err != nil {
-		logger.Printf("Invalid access token expiry: %v, using default", err)
-		accessTokenExpiry = 15 * time.Minute
-	}
-
-	refreshTokenExpiry, err := time.ParseDuration(refreshTokenExpiryStr)
-	if err != nil {
-		logger.Printf("Invalid refresh token expiry: %v, using default", err)
-		refreshTokenExpiry = 7 * 24 * time.Hour
-	}
-
 	// Initialize services
 	authService := auth.NewService(userRepo, redisClient, auth.Config{
-		AccessTokenSecret:  accessTokenSecret,
-		RefreshTokenSecret: refreshTokenSecret,
-		AccessTokenExpiry:  accessTokenExpiry,
-		RefreshTokenExpiry: refreshTokenExpiry,
-		Issuer:             issuer,
+		AccessTokenSecret:  config.Auth.AccessTokenSecret,
+		RefreshTokenSecret: config.Auth.RefreshTokenSecret,
+		AccessTokenExpiry:  config.Auth.AccessTokenExpiry,
+		RefreshTokenExpiry: config.Auth.RefreshTokenExpiry,
+		Issuer:             config.Auth.Issuer,
 	})
 
 	// Initialize API Gateway
-	apiGateway := gateway.NewService(config, authService, logger)
+	apiGateway := gateway.NewService(config, authService, appLogger)
+	apiGateway.AddHealthCheck("postgres", health.DatabaseCheck(db))
+	apiGateway.AddHealthCheck("redis", health.RedisCheck(redisClient))
 	apiGateway.Setup()
 
 	// Start API Gateway in a goroutine
 	go func() {
 		if err := apiGateway.Run(); err != nil {
-			logger.Fatalf("Failed to start API Gateway: %v", err)
+			logger.Fatal(appLogger.Logger, "failed to start API Gateway", slog.Any("error", err))
 		}
 	}()
 
@@ -120,22 +120,13 @@ func main() {
 	<-quit
 
 	// Create a deadline for server shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownDelay+15*time.Second)
 	defer cancel()
 
 	// Shutdown API Gateway
 	if err := apiGateway.Shutdown(ctx); err != nil {
-		logger.Fatalf("API Gateway forced to shutdown: %v", err)
+		logger.Fatal(appLogger.Logger, "API Gateway forced to shutdown", slog.Any("error", err))
 	}
 
-	logger.Println("API Gateway exited properly")
-}
-
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
+	appLogger.Info("API Gateway exited properly")
 }
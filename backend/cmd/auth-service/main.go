@@ -2,7 +2,8 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,7 +11,12 @@ import (
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/auth"
+	"github.com/Jerinji2016/halooid/backend/internal/config"
+	"github.com/Jerinji2016/halooid/backend/internal/migration"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/migrations"
+	"github.com/Jerinji2016/halooid/backend/pkg/health"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 	"github.com/gorilla/mux"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -18,46 +24,90 @@ import (
 )
 
 func main() {
+	// Load configuration
+	cfg := config.Default()
+	result, err := config.Load(cfg, config.Options{
+		DefaultPath: "./configs/auth-service.yaml",
+		Args:        os.Args[1:],
+		Name:        "auth-service",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize logger
-	logger := log.New(os.Stdout, "AUTH-SERVICE: ", log.LstdFlags)
+	appLogger, err := logger.Setup(logger.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	}, "auth-service")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Load configuration
-	// In a real application, this would be loaded from environment variables or a config file
-	config := auth.Config{
-		AccessTokenSecret:  "your-access-token-secret",
-		RefreshTokenSecret: "your-refresh-token-secret",
-		AccessTokenExpiry:  15 * time.Minute,
-		RefreshTokenExpiry: 7 * 24 * time.Hour,
-		Issuer:             "halooid-auth-service",
+	redacted, err := config.Redacted(cfg)
+	if err != nil {
+		logger.Fatal(appLogger.Logger, "failed to redact configuration", slog.Any("error", err))
+	}
+	appLogger.Info("configuration loaded",
+		slog.String("path", result.Path),
+		slog.Any("config", redacted),
+	)
+
+	authConfig := auth.Config{
+		AccessTokenSecret:  cfg.Auth.AccessTokenSecret,
+		RefreshTokenSecret: cfg.Auth.RefreshTokenSecret,
+		AccessTokenExpiry:  cfg.Auth.AccessTokenExpiry,
+		RefreshTokenExpiry: cfg.Auth.RefreshTokenExpiry,
+		Issuer:             cfg.Auth.Issuer,
 	}
 
 	// Connect to PostgreSQL
-	dbConnStr := "host=localhost port=5432 user=halooid password=halooid_password dbname=halooid sslmode=disable"
-	db, err := sqlx.Connect("postgres", dbConnStr)
+	db, err := sqlx.Connect("postgres", cfg.Database.DSN())
 	if err != nil {
-		logger.Fatalf("Failed to connect to database: %v", err)
+		logger.Fatal(appLogger.Logger, "failed to connect to database", slog.Any("error", err))
 	}
 	defer db.Close()
 
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+
+	// Apply pending schema migrations
+	if cfg.Database.AutoMigrate {
+		if err := migration.Up(context.Background(), db, migrations.FS); err != nil {
+			logger.Fatal(appLogger.Logger, "failed to apply migrations", slog.Any("error", err))
+		}
+	}
+
 	// Connect to Redis
 	redisClient := redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379",
-		Password: "", // no password set
-		DB:       0,  // use default DB
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
 	})
 	defer redisClient.Close()
 
 	// Ping Redis to check connection
 	_, err = redisClient.Ping(context.Background()).Result()
 	if err != nil {
-		logger.Fatalf("Failed to connect to Redis: %v", err)
+		logger.Fatal(appLogger.Logger, "failed to connect to Redis", slog.Any("error", err))
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewPostgresUserRepository(db)
 
 	// Initialize services
-	authService := auth.NewService(userRepo, redisClient, config)
+	authService := auth.NewService(userRepo, redisClient, authConfig)
 
 	// Initialize handlers
 	authHandlers := auth.NewHandlers(authService)
@@ -65,6 +115,13 @@ func main() {
 	// Initialize router
 	router := mux.NewRouter()
 
+	// Health check routes
+	healthChecker := health.NewChecker(health.DefaultTimeout)
+	healthChecker.Register("postgres", health.DatabaseCheck(db))
+	healthChecker.Register("redis", health.RedisCheck(redisClient))
+	router.Handle("/health", healthChecker.LivenessHandler()).Methods("GET")
+	router.Handle("/ready", healthChecker.ReadinessHandler()).Methods("GET")
+
 	// Register routes
 	router.HandleFunc("/api/auth/register", authHandlers.Register).Methods("POST")
 	router.HandleFunc("/api/auth/login", authHandlers.Login).Methods("POST")
@@ -74,18 +131,18 @@ func main() {
 
 	// Create server
 	server := &http.Server{
-		Addr:         ":8000",
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      logger.Middleware(appLogger.Logger)(router),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Start server in a goroutine
 	go func() {
-		logger.Printf("Starting server on port 8000")
+		appLogger.Info("starting server", slog.Int("port", cfg.Server.Port))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("Failed to start server: %v", err)
+			logger.Fatal(appLogger.Logger, "failed to start server", slog.Any("error", err))
 		}
 	}()
 
@@ -94,15 +151,20 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// Fail readiness so load balancers stop routing new traffic, and give
+	// them the shutdown delay to notice before connections are drained
+	appLogger.Info("shutting down server")
+	healthChecker.SetShuttingDown()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
 	// Create a deadline for server shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	// Shutdown server
-	logger.Println("Shutting down server...")
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatalf("Server forced to shutdown: %v", err)
+		logger.Fatal(appLogger.Logger, "server forced to shutdown", slog.Any("error", err))
 	}
 
-	logger.Println("Server exited properly")
+	appLogger.Info("server exited properly")
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/Jerinji2016/halooid/backend/internal/config"
+	"github.com/Jerinji2016/halooid/backend/internal/migration"
+	"github.com/Jerinji2016/halooid/backend/migrations"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up          apply all pending migrations
+  down [n]    roll back the last n migrations (default 1)
+  status      show the state of every migration
+  redo        roll back and re-apply the last migration
+`
+
+// migrateConfig is the configuration of the migrate command
+type migrateConfig struct {
+	Database config.DatabaseConfig `yaml:"database"`
+	Logging  config.LoggingConfig  `yaml:"logging"`
+}
+
+// Validate validates the configuration
+func (c *migrateConfig) Validate() error {
+	return errors.Join(c.Database.Validate(), c.Logging.Validate())
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// Load configuration
+	cfg := &migrateConfig{
+		Database: config.DefaultDatabaseConfig(),
+		Logging:  config.DefaultLoggingConfig(),
+	}
+	result, err := config.Load(cfg, config.Options{
+		Args: os.Args[1:],
+		Name: "migrate",
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.PrintConfig {
+		return config.Print(os.Stdout, cfg)
+	}
+
+	if len(result.Args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("missing command")
+	}
+
+	// Initialize logger
+	if _, err := logger.Setup(logger.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	}, "migrate"); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Connect to PostgreSQL
+	db, err := sqlx.ConnectContext(ctx, "postgres", cfg.Database.DSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	migrator, err := migration.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	command, args := result.Args[0], result.Args[1:]
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", len(applied))
+
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[0])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back %d migration(s)\n", len(rolledBack))
+
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("redid migration %d_%s\n", redone.Version, redone.Name)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	return nil
+}
+
+// printStatus prints the migration statuses as a table and fails if any migration drifted
+func printStatus(statuses []migration.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tDRIFT")
+
+	drifted := 0
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		if s.Drift != "" {
+			drifted++
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, s.Drift)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%w: %d migration(s) drifted", migration.ErrDrift, drifted)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/config"
+	"github.com/Jerinji2016/halooid/backend/internal/jobs"
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/storage"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/recurrence"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/reminder"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/trash"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// workerConfig is the configuration of the worker
+type workerConfig struct {
+	Database       config.DatabaseConfig `yaml:"database"`
+	Logging        config.LoggingConfig  `yaml:"logging"`
+	Storage        config.StorageConfig  `yaml:"storage"`
+	PollInterval   time.Duration         `yaml:"poll_interval" env:"JOBS_POLL_INTERVAL"`
+	TrashRetention time.Duration         `yaml:"trash_retention" env:"TRASH_RETENTION"`
+}
+
+// Validate validates the configuration
+func (c *workerConfig) Validate() error {
+	return errors.Join(c.Database.Validate(), c.Logging.Validate())
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "worker: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// Load configuration
+	cfg := &workerConfig{
+		Database: config.DefaultDatabaseConfig(),
+		Logging:  config.DefaultLoggingConfig(),
+		Storage: config.StorageConfig{
+			BasePath: "./storage",
+		},
+		PollInterval:   jobs.DefaultPollInterval,
+		TrashRetention: models.DefaultTrashRetention,
+	}
+	result, err := config.Load(cfg, config.Options{
+		Args: os.Args[1:],
+		Name: "worker",
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.PrintConfig {
+		return config.Print(os.Stdout, cfg)
+	}
+
+	// Initialize logger
+	log, err := logger.Setup(logger.Config{
+		Level:  cfg.Logging.Level,
+		Format: cfg.Logging.Format,
+	}, "worker")
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Connect to PostgreSQL
+	db, err := sqlx.ConnectContext(ctx, "postgres", cfg.Database.DSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+
+	// Initialize repositories
+	taskRepo := repository.NewPostgresTaskRepository(db)
+	projectRepo := repository.NewPostgresProjectRepository(db)
+	userRepo := repository.NewPostgresUserRepository(db)
+	reminderRepo := repository.NewPostgresTaskReminderRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	// Initialize services
+	notificationService := notification.NewService(repository.NewPostgresNotificationRepository(db), userRepo)
+	recurrenceService := recurrence.NewService(
+		repository.NewPostgresTaskRecurrenceRepository(db),
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(db),
+		repository.NewPostgresChecklistItemRepository(db),
+		workflow.NewService(repository.NewPostgresWorkflowRepository(db), projectRepo, taskRepo, txManager),
+		notificationService,
+		txManager,
+	)
+	trashService := trash.NewService(
+		repository.NewPostgresTrashRepository(db),
+		projectRepo,
+		taskRepo,
+		storage.NewLocalFileStorage(cfg.Storage.BasePath),
+		cfg.TrashRetention,
+	)
+	timeEntryService := timeentry.NewService(
+		repository.NewPostgresTimeEntryRepository(db),
+		taskRepo,
+		userRepo,
+		repository.NewPostgresTimesheetRepository(db),
+		repository.NewPostgresTimeEntryPolicyRepository(db),
+		notificationService,
+		txManager,
+	)
+
+	// Register jobs
+	scheduler := jobs.NewScheduler(db, repository.NewPostgresJobRunRepository(db), cfg.PollInterval)
+	scheduler.Register(recurrence.NewJob(recurrenceService), jobs.Config{Interval: time.Minute})
+	scheduler.Register(reminder.NewDueSoonJob(reminderRepo, notificationService, txManager, nil), jobs.Config{Interval: 5 * time.Minute})
+	scheduler.Register(reminder.NewOverdueJob(reminderRepo, notificationService, txManager), jobs.Config{Interval: 5 * time.Minute})
+	scheduler.Register(trash.NewJob(trashService), jobs.Config{Interval: time.Hour})
+	scheduler.Register(timeentry.NewAutoStopJob(timeEntryService), jobs.Config{Interval: 5 * time.Minute})
+
+	log.Info("worker started")
+	scheduler.Run(ctx)
+	log.Info("worker stopped")
+
+	return nil
+}
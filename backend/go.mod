@@ -1,6 +1,6 @@
 module github.com/Jerinji2016/halooid/backend
 
-go 1.20
+go 1.21
 
 require (
 	github.com/go-chi/chi/v5 v5.0.8
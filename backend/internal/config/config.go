@@ -1,69 +1,284 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"net/url"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 )
 
-// Config represents the application configuration
+// Config represents the application configuration shared by the backend services
 type Config struct {
 	Server   ServerConfig   `yaml:"server"`
 	Database DatabaseConfig `yaml:"database"`
+	Redis    RedisConfig    `yaml:"redis"`
 	Auth     AuthConfig     `yaml:"auth"`
+	Logging  LoggingConfig  `yaml:"logging"`
 	Storage  StorageConfig  `yaml:"storage"`
 	API      APIConfig      `yaml:"api"`
 }
 
 // ServerConfig represents the server configuration
 type ServerConfig struct {
-	Port         int `yaml:"port"`
-	Timeout      int `yaml:"timeout"`
-	ReadTimeout  int `yaml:"read_timeout"`
-	WriteTimeout int `yaml:"write_timeout"`
-	IdleTimeout  int `yaml:"idle_timeout"`
+	Port            int           `yaml:"port" env:"PORT" flag:"port" usage:"port to listen on"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay is how long readiness fails before connections are drained
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY"`
 }
 
-// DatabaseConfig represents the database configuration
+// DatabaseConfig represents the database configuration. URL takes precedence
+// over the individual connection parameters when set.
 type DatabaseConfig struct {
-	URL             string `yaml:"url"`
-	MaxOpenConns    int    `yaml:"max_open_conns"`
-	MaxIdleConns    int    `yaml:"max_idle_conns"`
-	ConnMaxLifetime int    `yaml:"conn_max_lifetime"`
+	URL             string        `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"ssl_mode" env:"DB_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	AutoMigrate     bool          `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// RedisConfig represents the Redis configuration
+type RedisConfig struct {
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     int    `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
 }
 
 // AuthConfig represents the authentication configuration
 type AuthConfig struct {
-	JWTSecret          string `yaml:"jwt_secret"`
-	TokenExpiry        int    `yaml:"token_expiry"`
-	RefreshTokenExpiry int    `yaml:"refresh_token_expiry"`
+	AccessTokenSecret  string        `yaml:"access_token_secret" env:"ACCESS_TOKEN_SECRET" secret:"true"`
+	RefreshTokenSecret string        `yaml:"refresh_token_secret" env:"REFRESH_TOKEN_SECRET" secret:"true"`
+	AccessTokenExpiry  time.Duration `yaml:"access_token_expiry" env:"ACCESS_TOKEN_EXPIRY"`
+	RefreshTokenExpiry time.Duration `yaml:"refresh_token_expiry" env:"REFRESH_TOKEN_EXPIRY"`
+	Issuer             string        `yaml:"issuer" env:"ISSUER"`
+}
+
+// LoggingConfig represents the logging configuration
+type LoggingConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"log level (debug, info, warn, error)"`
+	Format string `yaml:"format" env:"LOG_FORMAT" flag:"log-format" usage:"log format (json, text)"`
 }
 
 // StorageConfig represents the file storage configuration
 type StorageConfig struct {
-	BasePath string `yaml:"base_path"`
+	BasePath string `yaml:"base_path" env:"STORAGE_BASE_PATH"`
 }
 
 // APIConfig represents the API configuration
 type APIConfig struct {
-	BaseURL string `yaml:"base_url"`
+	BaseURL string `yaml:"base_url" env:"API_BASE_URL"`
 }
 
-// LoadConfig loads the configuration from a file
-func LoadConfig(path string) (*Config, error) {
-	// Read the configuration file
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// Default returns the default configuration. Secrets have no defaults and
+// must be provided through the configuration file or the environment.
+func Default() *Config {
+	return &Config{
+		Server:   DefaultServerConfig(),
+		Database: DefaultDatabaseConfig(),
+		Redis:    DefaultRedisConfig(),
+		Auth:     DefaultAuthConfig(),
+		Logging:  DefaultLoggingConfig(),
+		Storage: StorageConfig{
+			BasePath: "./storage",
+		},
+		API: APIConfig{
+			BaseURL: "http://localhost:8000",
+		},
+	}
+}
+
+// DefaultServerConfig returns the default server configuration
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Port:            8000,
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		ShutdownDelay:   5 * time.Second,
+	}
+}
+
+// DefaultDatabaseConfig returns the default database configuration
+func DefaultDatabaseConfig() DatabaseConfig {
+	return DatabaseConfig{
+		Host:            "localhost",
+		Port:            5432,
+		User:            "halooid",
+		Name:            "halooid",
+		SSLMode:         "disable",
+		MaxOpenConns:    25,
+		MaxIdleConns:    25,
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+}
+
+// DefaultRedisConfig returns the default Redis configuration
+func DefaultRedisConfig() RedisConfig {
+	return RedisConfig{
+		Host: "localhost",
+		Port: 6379,
+	}
+}
+
+// DefaultAuthConfig returns the default authentication configuration
+func DefaultAuthConfig() AuthConfig {
+	return AuthConfig{
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 7 * 24 * time.Hour,
+		Issuer:             "halooid-auth-service",
+	}
+}
+
+// DefaultLoggingConfig returns the default logging configuration
+func DefaultLoggingConfig() LoggingConfig {
+	return LoggingConfig{
+		Level:  "info",
+		Format: logger.FormatJSON,
+	}
+}
+
+// DSN returns the connection string for the database
+func (c DatabaseConfig) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// Addr returns the address of the Redis server
+func (c RedisConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Server.Validate(),
+		c.Database.Validate(),
+		c.Redis.Validate(),
+		c.Auth.Validate(),
+		c.Logging.Validate(),
+	)
+}
+
+// Validate validates the server configuration
+func (c ServerConfig) Validate() error {
+	var errs []error
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 || c.ShutdownTimeout < 0 || c.ShutdownDelay < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the database configuration
+func (c DatabaseConfig) Validate() error {
+	var errs []error
+	if c.MaxIdleConns > c.MaxOpenConns && c.MaxOpenConns > 0 {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+
+	if c.URL != "" {
+		// Do not wrap the parse error, it contains the URL and its password
+		if _, err := url.Parse(c.URL); err != nil {
+			errs = append(errs, errors.New("database.url is not a valid URL (DATABASE_URL)"))
+		}
+		return errors.Join(errs...)
+	}
+
+	if c.Host == "" {
+		errs = append(errs, errors.New("database.host is required (DB_HOST)"))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.User == "" {
+		errs = append(errs, errors.New("database.user is required (DB_USER)"))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("database.name is required (DB_NAME)"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validates the Redis configuration
+func (c RedisConfig) Validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("redis.host is required (REDIS_HOST)"))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("redis.port must be between 1 and 65535, got %d", c.Port))
 	}
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("redis.db must not be negative, got %d", c.DB))
+	}
+	return errors.Join(errs...)
+}
 
-	// Parse the configuration
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// Validate validates the authentication configuration
+func (c AuthConfig) Validate() error {
+	var errs []error
+	if c.AccessTokenSecret == "" {
+		errs = append(errs, errors.New("auth.access_token_secret is required (ACCESS_TOKEN_SECRET or ACCESS_TOKEN_SECRET_FILE)"))
+	}
+	if c.RefreshTokenSecret == "" {
+		errs = append(errs, errors.New("auth.refresh_token_secret is required (REFRESH_TOKEN_SECRET or REFRESH_TOKEN_SECRET_FILE)"))
+	}
+	if c.AccessTokenSecret != "" && c.AccessTokenSecret == c.RefreshTokenSecret {
+		errs = append(errs, errors.New("auth.access_token_secret and auth.refresh_token_secret must differ"))
+	}
+	if c.AccessTokenExpiry <= 0 {
+		errs = append(errs, errors.New("auth.access_token_expiry must be positive"))
+	}
+	if c.RefreshTokenExpiry <= 0 {
+		errs = append(errs, errors.New("auth.refresh_token_expiry must be positive"))
 	}
+	return errors.Join(errs...)
+}
 
-	return &config, nil
+// Validate validates the logging configuration
+func (c LoggingConfig) Validate() error {
+	var errs []error
+	if _, err := logger.ParseLevel(c.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	switch c.Format {
+	case "", logger.FormatJSON, logger.FormatText:
+	default:
+		errs = append(errs, fmt.Errorf("logging.format must be %q or %q, got %q", logger.FormatJSON, logger.FormatText, c.Format))
+	}
+	return errors.Join(errs...)
+}
+
+// LoadConfig loads the configuration from a file, applying defaults and
+// environment variables, and validates the result
+func LoadConfig(path string) (*Config, error) {
+	config := Default()
+	if _, err := Load(config, Options{Path: path}); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted returns the configuration as a map with the values of fields tagged
+// `secret:"true"` and the passwords of URLs replaced, suitable for logging or printing
+func Redacted(cfg interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("config must be a struct or a pointer to a struct")
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+
+	// Redact secrets
+	for _, f := range collectFields(v, "") {
+		if f.secret && !f.value.IsZero() {
+			setPath(values, strings.Split(f.path, "."), logger.RedactedValue)
+		}
+	}
+	redactURLs(values)
+
+	return values, nil
+}
+
+// Dump returns the configuration as YAML with secrets redacted
+func Dump(cfg interface{}) ([]byte, error) {
+	values, err := Redacted(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(values)
+}
+
+// setPath sets the value at a dotted path in a configuration map if it exists
+func setPath(values map[string]interface{}, path []string, value interface{}) {
+	for len(path) > 1 {
+		next, ok := values[path[0]].(map[string]interface{})
+		if !ok {
+			return
+		}
+		values, path = next, path[1:]
+	}
+
+	if _, ok := values[path[0]]; ok {
+		values[path[0]] = value
+	}
+}
+
+// redactURLs removes the passwords from URLs with credentials in a configuration map
+func redactURLs(values map[string]interface{}) {
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			redactURLs(v)
+		case string:
+			if !strings.Contains(v, "://") {
+				continue
+			}
+			u, err := url.Parse(v)
+			if err != nil || u.User == nil {
+				continue
+			}
+			if _, ok := u.User.Password(); ok {
+				values[key] = u.Redacted()
+			}
+		}
+	}
+}
+
+// Print writes the configuration as YAML with secrets redacted to w
+func Print(w io.Writer, cfg interface{}) error {
+	data, err := Dump(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigPath is the environment variable holding the configuration file path
+const EnvConfigPath = "CONFIG_PATH"
+
+// fileEnvSuffix is appended to an environment variable name to read its value from a file
+const fileEnvSuffix = "_FILE"
+
+// Options controls how a configuration is loaded
+type Options struct {
+	// Path is the configuration file to load. It overrides DefaultPath and
+	// CONFIG_PATH, and it is an error if the file does not exist.
+	Path string
+	// DefaultPath is loaded if it exists and no other path is given
+	DefaultPath string
+	// Args are the command-line arguments to parse, usually os.Args[1:]
+	Args []string
+	// Name is the program name used in flag usage messages
+	Name string
+}
+
+// Result describes how a configuration was loaded
+type Result struct {
+	// Path is the configuration file that was loaded, if any
+	Path string
+	// PrintConfig is set when the -print-config flag was given
+	PrintConfig bool
+	// Args are the command-line arguments remaining after the flags
+	Args []string
+}
+
+// validator is implemented by configurations that can validate themselves
+type validator interface {
+	Validate() error
+}
+
+// Load populates cfg, which must be a pointer to a struct holding the defaults,
+// by layering a YAML file, environment variables and command-line flags on top
+// of it, in that order, and validates the result.
+//
+// Fields are bound to environment variables with an `env` struct tag and to
+// flags with a `flag` struct tag. For every environment variable NAME, the
+// value is read from the file named by NAME_FILE if NAME itself is not set.
+func Load(cfg interface{}, opts Options) (*Result, error) {
+	root := reflect.ValueOf(cfg)
+	if root.Kind() != reflect.Pointer || root.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a pointer to a struct")
+	}
+
+	fields := collectFields(root.Elem(), "")
+
+	// Parse flags first so that -config can select the file
+	name := opts.Name
+	if name == "" {
+		name = os.Args[0]
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	result := &Result{}
+	configPath := fs.String("config", "", "path to the configuration file")
+	fs.BoolVar(&result.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+		flagName := f.flag
+		usage := f.usage
+		if f.env != "" {
+			usage = fmt.Sprintf("%s (env %s)", usage, f.env)
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(flagName, usage, func(s string) error {
+				flagValues[flagName] = s
+				return nil
+			})
+			continue
+		}
+		fs.Func(flagName, usage, func(s string) error {
+			flagValues[flagName] = s
+			return nil
+		})
+	}
+
+	if err := fs.Parse(opts.Args); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+	result.Args = fs.Args()
+
+	// Load configuration file
+	path, required := opts.Path, opts.Path != ""
+	if *configPath != "" {
+		path, required = *configPath, true
+	} else if env := os.Getenv(EnvConfigPath); env != "" && !required {
+		path, required = env, true
+	} else if !required {
+		path = opts.DefaultPath
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+			}
+			result.Path = path
+		case errors.Is(err, os.ErrNotExist) && !required:
+		default:
+			return nil, fmt.Errorf("failed to read configuration file: %w", err)
+		}
+	}
+
+	// Override with environment variables
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		value, ok, err := lookupEnv(f.env)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err := setField(f.value, value); err != nil {
+			return nil, fmt.Errorf("invalid value for %s (%s): %w", f.env, f.path, err)
+		}
+	}
+
+	// Override with flags
+	for _, f := range fields {
+		value, ok := flagValues[f.flag]
+		if f.flag == "" || !ok {
+			continue
+		}
+		if err := setField(f.value, value); err != nil {
+			return nil, fmt.Errorf("invalid value for -%s (%s): %w", f.flag, f.path, err)
+		}
+	}
+
+	// Validate configuration
+	if v, ok := cfg.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration:\n%w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// field is a configuration field bound to an environment variable or flag
+type field struct {
+	path   string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// durationType is the reflected type of time.Duration
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// collectFields walks a struct and returns its leaf fields
+func collectFields(v reflect.Value, prefix string) []field {
+	var fields []field
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && sf.Type != durationType {
+			fields = append(fields, collectFields(fv, path)...)
+			continue
+		}
+
+		fields = append(fields, field{
+			path:   path,
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  fv,
+		})
+	}
+
+	return fields
+}
+
+// lookupEnv returns the value of an environment variable, reading it from the
+// file named by NAME_FILE if the variable itself is empty
+func lookupEnv(name string) (string, bool, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, true, nil
+	}
+
+	path := os.Getenv(name + fileEnvSuffix)
+	if path == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s%s: %w", name, fileEnvSuffix, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// setField parses s into a string, integer, boolean, duration or string slice field
+func setField(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
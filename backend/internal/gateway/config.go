@@ -1,19 +1,31 @@
 package gateway
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
+
+	cfgpkg "github.com/Jerinji2016/halooid/backend/internal/config"
 )
 
 // Config represents the configuration for the API Gateway
 type Config struct {
 	// Server configuration
 	Server struct {
-		Port         int           `json:"port" yaml:"port"`
+		Port         int           `json:"port" yaml:"port" env:"PORT" flag:"port" usage:"port to listen on"`
 		ReadTimeout  time.Duration `json:"read_timeout" yaml:"read_timeout"`
 		WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
 		IdleTimeout  time.Duration `json:"idle_timeout" yaml:"idle_timeout"`
+		// ShutdownDelay is how long readiness fails before connections are drained
+		ShutdownDelay time.Duration `json:"shutdown_delay" yaml:"shutdown_delay"`
 	} `json:"server" yaml:"server"`
 
+	// Database, Redis and authentication configuration shared with the other services
+	Database cfgpkg.DatabaseConfig `json:"database" yaml:"database"`
+	Redis    cfgpkg.RedisConfig    `json:"redis" yaml:"redis"`
+	Auth     cfgpkg.AuthConfig     `json:"auth" yaml:"auth"`
+
 	// Rate limiting configuration
 	RateLimit struct {
 		Enabled   bool          `json:"enabled" yaml:"enabled"`
@@ -36,39 +48,40 @@ type Config struct {
 	// Services configuration
 	Services struct {
 		Auth struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"AUTH_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"auth" yaml:"auth"`
 		RBAC struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"RBAC_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"rbac" yaml:"rbac"`
 		Taskake struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"TASKAKE_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"taskake" yaml:"taskake"`
 		Qultrix struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"QULTRIX_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"qultrix" yaml:"qultrix"`
 		AdminHub struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"ADMINHUB_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"adminhub" yaml:"adminhub"`
 		CustomerConnect struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"CUSTOMERCONNECT_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"customerconnect" yaml:"customerconnect"`
 		Invantray struct {
-			URL     string        `json:"url" yaml:"url"`
+			URL     string        `json:"url" yaml:"url" env:"INVANTRAY_SERVICE_URL"`
 			Timeout time.Duration `json:"timeout" yaml:"timeout"`
 		} `json:"invantray" yaml:"invantray"`
 	} `json:"services" yaml:"services"`
 
 	// Logging configuration
 	Logging struct {
-		Level  string `json:"level" yaml:"level"`
-		Format string `json:"format" yaml:"format"`
+		Level         string `json:"level" yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"log level (debug, info, warn, error)"`
+		Format        string `json:"format" yaml:"format" env:"LOG_FORMAT" flag:"log-format" usage:"log format (json, text)"`
+		LevelEndpoint string `json:"level_endpoint" yaml:"level_endpoint"`
 	} `json:"logging" yaml:"logging"`
 
 	// Metrics configuration
@@ -79,8 +92,12 @@ type Config struct {
 
 	// Health check configuration
 	HealthCheck struct {
-		Enabled bool   `json:"enabled" yaml:"enabled"`
-		Path    string `json:"path" yaml:"path"`
+		Enabled       bool          `json:"enabled" yaml:"enabled"`
+		Path          string        `json:"path" yaml:"path"`
+		ReadinessPath string        `json:"readiness_path" yaml:"readiness_path"`
+		Timeout       time.Duration `json:"timeout" yaml:"timeout"`
+		Services      []string      `json:"services" yaml:"services"`
+		ServicePath   string        `json:"service_path" yaml:"service_path"`
 	} `json:"health_check" yaml:"health_check"`
 }
 
@@ -93,6 +110,12 @@ func DefaultConfig() *Config {
 	config.Server.ReadTimeout = 15 * time.Second
 	config.Server.WriteTimeout = 15 * time.Second
 	config.Server.IdleTimeout = 60 * time.Second
+	config.Server.ShutdownDelay = 5 * time.Second
+
+	// Database, Redis and authentication configuration
+	config.Database = cfgpkg.DefaultDatabaseConfig()
+	config.Redis = cfgpkg.DefaultRedisConfig()
+	config.Auth = cfgpkg.DefaultAuthConfig()
 
 	// Rate limiting configuration
 	config.RateLimit.Enabled = true
@@ -128,6 +151,7 @@ func DefaultConfig() *Config {
 	// Logging configuration
 	config.Logging.Level = "info"
 	config.Logging.Format = "json"
+	config.Logging.LevelEndpoint = "/admin/log-level"
 
 	// Metrics configuration
 	config.Metrics.Enabled = true
@@ -136,6 +160,58 @@ func DefaultConfig() *Config {
 	// Health check configuration
 	config.HealthCheck.Enabled = true
 	config.HealthCheck.Path = "/health"
+	config.HealthCheck.ReadinessPath = "/ready"
+	config.HealthCheck.Timeout = 2 * time.Second
+	config.HealthCheck.Services = []string{"auth", "rbac"}
+	config.HealthCheck.ServicePath = "/health"
 
 	return config
 }
+
+// ServiceURL returns the URL of the upstream service with the given name
+func (c *Config) ServiceURL(name string) (string, bool) {
+	switch name {
+	case "auth":
+		return c.Services.Auth.URL, true
+	case "rbac":
+		return c.Services.RBAC.URL, true
+	case "taskake":
+		return c.Services.Taskake.URL, true
+	case "qultrix":
+		return c.Services.Qultrix.URL, true
+	case "adminhub":
+		return c.Services.AdminHub.URL, true
+	case "customerconnect":
+		return c.Services.CustomerConnect.URL, true
+	case "invantray":
+		return c.Services.Invantray.URL, true
+	default:
+		return "", false
+	}
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.RateLimit.Enabled && (c.RateLimit.Requests <= 0 || c.RateLimit.Period <= 0) {
+		errs = append(errs, errors.New("rate_limit.requests and rate_limit.period must be positive when rate limiting is enabled"))
+	}
+	for _, name := range []string{"auth", "rbac", "taskake", "qultrix", "adminhub", "customerconnect", "invantray"} {
+		serviceURL, _ := c.ServiceURL(name)
+		if u, err := url.Parse(serviceURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("services.%s.url must be an absolute URL, got %q", name, serviceURL))
+		}
+	}
+	for _, name := range c.HealthCheck.Services {
+		if _, ok := c.ServiceURL(name); !ok {
+			errs = append(errs, fmt.Errorf("health_check.services contains unknown service %q", name))
+		}
+	}
+
+	logging := cfgpkg.LoggingConfig{Level: c.Logging.Level, Format: c.Logging.Format}
+	errs = append(errs, logging.Validate(), c.Database.Validate(), c.Redis.Validate(), c.Auth.Validate())
+	return errors.Join(errs...)
+}
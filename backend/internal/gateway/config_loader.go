@@ -1,83 +1,40 @@
 package gateway
 
 import (
-	"fmt"
 	"os"
-	"strconv"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	cfgpkg "github.com/Jerinji2016/halooid/backend/internal/config"
 )
 
-// LoadConfig loads the configuration from a file
-func LoadConfig(filePath string) (*Config, error) {
-	// Load default configuration
-	config := DefaultConfig()
+// DefaultConfigPath is the configuration file loaded when no other path is given
+const DefaultConfigPath = "./configs/api-gateway.yaml"
 
-	// Read configuration file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read configuration file: %w", err)
-	}
+// LoadConfig loads the configuration by layering the defaults, the configuration
+// file, environment variables and command-line flags, and validates the result
+func LoadConfig(args []string) (*Config, *cfgpkg.Result, error) {
+	// Load default configuration
+	cfg := DefaultConfig()
 
-	// Parse configuration file
-	err = yaml.Unmarshal(data, config)
+	result, err := cfgpkg.Load(cfg, cfgpkg.Options{
+		DefaultPath: DefaultConfigPath,
+		Args:        args,
+		Name:        "api-gateway",
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
-	}
-
-	// Override configuration with environment variables
-	overrideWithEnv(config)
-
-	return config, nil
-}
-
-// overrideWithEnv overrides configuration with environment variables
-func overrideWithEnv(config *Config) {
-	// Server configuration
-	if port, err := strconv.Atoi(os.Getenv("PORT")); err == nil && port > 0 {
-		config.Server.Port = port
+		return nil, nil, err
 	}
 
-	// Services configuration
-	if url := os.Getenv("AUTH_SERVICE_URL"); url != "" {
-		config.Services.Auth.URL = url
-	}
-	if url := os.Getenv("RBAC_SERVICE_URL"); url != "" {
-		config.Services.RBAC.URL = url
-	}
-	if url := os.Getenv("TASKAKE_SERVICE_URL"); url != "" {
-		config.Services.Taskake.URL = url
-	}
-	if url := os.Getenv("QULTRIX_SERVICE_URL"); url != "" {
-		config.Services.Qultrix.URL = url
-	}
-	if url := os.Getenv("ADMINHUB_SERVICE_URL"); url != "" {
-		config.Services.AdminHub.URL = url
-	}
-	if url := os.Getenv("CUSTOMERCONNECT_SERVICE_URL"); url != "" {
-		config.Services.CustomerConnect.URL = url
-	}
-	if url := os.Getenv("INVANTRAY_SERVICE_URL"); url != "" {
-		config.Services.Invantray.URL = url
-	}
-
-	// Timeouts
+	// A single timeout may be set for all services
 	if timeout, err := time.ParseDuration(os.Getenv("SERVICE_TIMEOUT")); err == nil {
-		config.Services.Auth.Timeout = timeout
-		config.Services.RBAC.Timeout = timeout
-		config.Services.Taskake.Timeout = timeout
-		config.Services.Qultrix.Timeout = timeout
-		config.Services.AdminHub.Timeout = timeout
-		config.Services.CustomerConnect.Timeout = timeout
-		config.Services.Invantray.Timeout = timeout
+		cfg.Services.Auth.Timeout = timeout
+		cfg.Services.RBAC.Timeout = timeout
+		cfg.Services.Taskake.Timeout = timeout
+		cfg.Services.Qultrix.Timeout = timeout
+		cfg.Services.AdminHub.Timeout = timeout
+		cfg.Services.CustomerConnect.Timeout = timeout
+		cfg.Services.Invantray.Timeout = timeout
 	}
 
-	// Logging configuration
-	if level := os.Getenv("LOG_LEVEL"); level != "" {
-		config.Logging.Level = level
-	}
-	if format := os.Getenv("LOG_FORMAT"); format != "" {
-		config.Logging.Format = format
-	}
+	return cfg, result, nil
 }
@@ -1,7 +1,6 @@
 package gateway
 
 import (
-	"log"
 	"net/http"
 	"time"
 
@@ -116,29 +115,3 @@ func getServiceFromPath(path string) string {
 
 	return path
 }
-
-// LoggingMiddleware creates a middleware that logs requests
-func LoggingMiddleware(logger *log.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get request ID
-			requestID := middleware.GetReqID(r.Context())
-
-			// Log request
-			logger.Printf("Request: %s %s %s", requestID, r.Method, r.URL.Path)
-
-			// Create response writer wrapper
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-
-			// Start timer
-			start := time.Now()
-
-			// Call next handler
-			next.ServeHTTP(ww, r)
-
-			// Log response
-			duration := time.Since(start)
-			logger.Printf("Response: %s %s %s %d %s", requestID, r.Method, r.URL.Path, ww.Status(), duration)
-		})
-	}
-}
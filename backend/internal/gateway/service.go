@@ -3,14 +3,18 @@ package gateway
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/auth"
+	"github.com/Jerinji2016/halooid/backend/pkg/health"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	authmw "github.com/Jerinji2016/halooid/backend/pkg/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -23,17 +27,40 @@ type Service struct {
 	config      *Config
 	router      *chi.Mux
 	authService auth.Service
-	logger      *log.Logger
+	logger      *logger.Logger
+	health      *health.Checker
+
+	mu     sync.Mutex
+	server *http.Server
 }
 
 // NewService creates a new API Gateway service
-func NewService(config *Config, authService auth.Service, logger *log.Logger) *Service {
-	return &Service{
+func NewService(config *Config, authService auth.Service, log *logger.Logger) *Service {
+	s := &Service{
 		config:      config,
 		router:      chi.NewRouter(),
 		authService: authService,
-		logger:      logger,
+		logger:      log,
+		health:      health.NewChecker(config.HealthCheck.Timeout),
+	}
+
+	// Check upstream services on readiness
+	client := &http.Client{Timeout: config.HealthCheck.Timeout}
+	for _, name := range config.HealthCheck.Services {
+		serviceURL, ok := config.ServiceURL(name)
+		if !ok {
+			log.Warn("unknown service in health check configuration", slog.String("service", name))
+			continue
+		}
+		s.health.Register(name, health.HTTPCheck(client, strings.TrimRight(serviceURL, "/")+config.HealthCheck.ServicePath))
 	}
+
+	return s
+}
+
+// AddHealthCheck registers a dependency check used by the readiness endpoint
+func (s *Service) AddHealthCheck(name string, check health.CheckFunc) {
+	s.health.Register(name, check)
 }
 
 // Setup sets up the API Gateway service
@@ -48,9 +75,8 @@ func (s *Service) Setup() {
 // setupMiddleware sets up middleware for the API Gateway
 func (s *Service) setupMiddleware() {
 	// Basic middleware
-	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
-	s.router.Use(middleware.Logger)
+	s.router.Use(logger.Middleware(s.logger.Logger))
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.Timeout(60 * time.Second))
 
@@ -77,9 +103,12 @@ func (s *Service) setupMiddleware() {
 
 // setupRoutes sets up routes for the API Gateway
 func (s *Service) setupRoutes() {
-	// Health check endpoint
+	// Health check endpoints
 	if s.config.HealthCheck.Enabled {
-		s.router.Get(s.config.HealthCheck.Path, s.handleHealthCheck)
+		s.router.Method(http.MethodGet, s.config.HealthCheck.Path, s.health.LivenessHandler())
+		if s.config.HealthCheck.ReadinessPath != "" {
+			s.router.Method(http.MethodGet, s.config.HealthCheck.ReadinessPath, s.health.ReadinessHandler())
+		}
 	}
 
 	// Metrics endpoint
@@ -87,6 +116,13 @@ func (s *Service) setupRoutes() {
 		s.router.Handle(s.config.Metrics.Path, promhttp.Handler())
 	}
 
+	// Log level admin endpoint
+	if s.config.Logging.LevelEndpoint != "" {
+		authMiddleware := authmw.NewAuthMiddleware(s.authService)
+		s.router.With(authMiddleware.Authenticate, requireTokenPermission(authmw.PermissionAdminAccess)).
+			Handle(s.config.Logging.LevelEndpoint, logger.LevelHandler(s.logger))
+	}
+
 	// API routes
 	s.router.Route("/api", func(r chi.Router) {
 		// Auth service routes
@@ -126,19 +162,12 @@ func (s *Service) setupRoutes() {
 	})
 }
 
-// handleHealthCheck handles health check requests
-func (s *Service) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
-}
-
 // handleProxy handles proxying requests to a service
 func (s *Service) handleProxy(r chi.Router, targetURL string) {
 	// Parse target URL
 	target, err := url.Parse(targetURL)
 	if err != nil {
-		s.logger.Printf("Error parsing target URL %s: %v", targetURL, err)
+		s.logger.Error("failed to parse target URL", slog.String("target_url", targetURL), slog.Any("error", err))
 		return
 	}
 
@@ -155,7 +184,11 @@ func (s *Service) handleProxy(r chi.Router, targetURL string) {
 
 	// Set up proxy error handler
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		s.logger.Printf("Proxy error: %v", err)
+		logger.FromContext(r.Context()).Error("proxy error",
+			slog.String("target_url", targetURL),
+			slog.String("path", r.URL.Path),
+			slog.Any("error", err),
+		)
 		w.WriteHeader(http.StatusBadGateway)
 		w.Write([]byte(`{"error":"Bad Gateway"}`))
 	}
@@ -185,18 +218,71 @@ func (s *Service) Run() error {
 		IdleTimeout:  s.config.Server.IdleTimeout,
 	}
 
+	s.mu.Lock()
+	s.server = server
+	s.mu.Unlock()
+
 	// Log server start
-	s.logger.Printf("Starting API Gateway on port %d", s.config.Server.Port)
+	s.logger.Info("starting API Gateway", slog.Int("port", s.config.Server.Port))
 
 	// Start server
-	return server.ListenAndServe()
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
 }
 
-// Shutdown shuts down the API Gateway service
+// Shutdown gracefully shuts down the API Gateway service. Readiness checks fail
+// immediately, then after the configured shutdown delay the server stops accepting
+// new connections and waits for in-flight requests to complete or ctx to expire.
 func (s *Service) Shutdown(ctx context.Context) error {
 	// Log server shutdown
-	s.logger.Println("Shutting down API Gateway")
+	s.logger.Info("shutting down API Gateway")
 
-	// Nothing to do here yet
+	// Fail readiness so load balancers stop routing new traffic
+	s.health.SetShuttingDown()
+
+	if delay := s.config.Server.ShutdownDelay; delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	s.mu.Lock()
+	server := s.server
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	// Drain connections
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to drain connections: %w", err)
+	}
 	return nil
 }
+
+// requireTokenPermission creates a middleware that requires a permission to be
+// present in the token claims of an authenticated request
+func requireTokenPermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := auth.GetTokenClaimsFromContext(r.Context())
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			for _, p := range claims.Permissions {
+				if p == permission {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			http.Error(w, "Permission denied", http.StatusForbidden)
+		})
+	}
+}
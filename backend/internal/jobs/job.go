@@ -0,0 +1,78 @@
+// Package jobs runs background jobs on a schedule. A single instance runs the
+// jobs at a time, elected with a PostgreSQL advisory lock; every run is
+// recorded, and failed runs are retried with exponential backoff.
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Default job configuration
+const (
+	DefaultInterval    = time.Minute
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = 30 * time.Second
+	DefaultMaxBackoff  = 30 * time.Minute
+	DefaultTimeout     = 5 * time.Minute
+)
+
+// Job is a unit of background work. A run may be interrupted and retried, so
+// running a job twice for the same time must be safe.
+type Job interface {
+	// Name returns the unique name of the job, used to record its runs
+	Name() string
+
+	// Run runs the job for the time now
+	Run(ctx context.Context, now time.Time) error
+}
+
+// Config configures how a job is scheduled
+type Config struct {
+	// Interval is the time between the starts of successful runs
+	Interval time.Duration
+
+	// MaxAttempts is the number of times a run is attempted before giving up
+	// until the next interval
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. It doubles with every
+	// retry, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Timeout bounds the duration of a run
+	Timeout time.Duration
+}
+
+// withDefaults returns the configuration with its unset fields defaulted
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// backoff returns the delay before retrying a failed attempt
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.Backoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+)
+
+// DefaultRetention is how long job runs are kept by default
+const DefaultRetention = 7 * 24 * time.Hour
+
+// PruneJob deletes old job runs
+type PruneJob struct {
+	runRepo   repository.JobRunRepository
+	retention time.Duration
+}
+
+// NewPruneJob creates a new PruneJob deleting the runs started more than
+// retention ago
+func NewPruneJob(runRepo repository.JobRunRepository, retention time.Duration) *PruneJob {
+	return &PruneJob{
+		runRepo:   runRepo,
+		retention: retention,
+	}
+}
+
+// Name returns the name of the job
+func (j *PruneJob) Name() string {
+	return "prune_job_runs"
+}
+
+// Run deletes the runs started more than the retention before now
+func (j *PruneJob) Run(ctx context.Context, now time.Time) error {
+	deleted, err := j.runRepo.DeleteBefore(ctx, now.Add(-j.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("pruned job runs", slog.Int64("count", deleted))
+	}
+	return nil
+}
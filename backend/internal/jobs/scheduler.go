@@ -0,0 +1,240 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// lockID is the key of the advisory lock held by the leading scheduler
+const lockID int64 = 7_370_617_224_911_202
+
+// DefaultPollInterval is how often the leading scheduler looks for due jobs
+// by default
+const DefaultPollInterval = 15 * time.Second
+
+// registeredJob is a job along with its configuration
+type registeredJob struct {
+	job    Job
+	config Config
+}
+
+// Scheduler runs the registered jobs when they are due. Any number of
+// instances can run a scheduler; they elect a leader with a session-level
+// advisory lock, and only the leader runs jobs.
+type Scheduler struct {
+	db           *sqlx.DB
+	runRepo      repository.JobRunRepository
+	jobs         []registeredJob
+	pollInterval time.Duration
+}
+
+// NewScheduler creates a new Scheduler polling every pollInterval. It
+// registers a job pruning the runs older than a week.
+func NewScheduler(db *sqlx.DB, runRepo repository.JobRunRepository, pollInterval time.Duration) *Scheduler {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	s := &Scheduler{
+		db:           db,
+		runRepo:      runRepo,
+		pollInterval: pollInterval,
+	}
+	s.Register(NewPruneJob(runRepo, DefaultRetention), Config{Interval: time.Hour})
+	return s
+}
+
+// Register adds a job to the scheduler. It panics if a job with the same name
+// is already registered.
+func (s *Scheduler) Register(job Job, config Config) {
+	for _, registered := range s.jobs {
+		if registered.job.Name() == job.Name() {
+			panic(fmt.Sprintf("jobs: job %q registered twice", job.Name()))
+		}
+	}
+	s.jobs = append(s.jobs, registeredJob{job: job, config: config.withDefaults()})
+}
+
+// Run runs the due jobs while this instance leads, and tries to become the
+// leader every poll interval while it does not, until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	log := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.lead(ctx, ticker.C); err != nil && ctx.Err() == nil {
+			log.Error("job scheduler lost leadership", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs the due jobs on every tick while holding the scheduler lock. It
+// returns at once if another instance holds the lock, and otherwise when ctx
+// is done or the lock's connection is lost.
+func (s *Scheduler) lead(ctx context.Context, tick <-chan time.Time) error {
+	log := logger.FromContext(ctx)
+
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Session-level advisory locks belong to the connection, which is held
+	// for as long as this instance leads
+	var acquired bool
+	if err := conn.GetContext(ctx, &acquired, "SELECT pg_try_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("failed to acquire job scheduler lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			log.Error("failed to release job scheduler lock", slog.Any("error", err))
+		}
+	}()
+
+	log.Info("job scheduler is leading")
+
+	// Runs left running were interrupted when the previous leader stopped,
+	// so they are retried right away
+	interrupted, err := s.runRepo.FailRunning(ctx, "interrupted", time.Now())
+	if err != nil {
+		return err
+	}
+	if interrupted > 0 {
+		log.Warn("marked interrupted job runs as failed", slog.Int64("count", interrupted))
+	}
+
+	for {
+		s.RunDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+		}
+
+		// The lock is released if the connection is lost
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to check job scheduler lock: %w", err)
+		}
+	}
+}
+
+// RunDue runs the jobs due at now, one after the other, and returns how many
+// were run
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) int {
+	log := logger.FromContext(ctx)
+
+	ran := 0
+	for _, registered := range s.jobs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		latest, err := s.runRepo.GetLatest(ctx, registered.job.Name())
+		if err != nil && !errors.Is(err, repository.ErrJobRunNotFound) {
+			log.Error("failed to get latest job run",
+				slog.String("job", registered.job.Name()),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		attempt, dueAt, ok := registered.next(latest)
+		if !ok || dueAt.After(now) {
+			continue
+		}
+
+		s.run(ctx, registered, attempt, now)
+		ran++
+	}
+
+	return ran
+}
+
+// next returns the attempt and time of the next run of a job given its latest
+// run. It returns false if the latest run is still running.
+func (j registeredJob) next(latest *models.JobRun) (int, time.Time, bool) {
+	switch {
+	case latest == nil:
+		return 1, time.Time{}, true
+	case latest.Status == models.JobRunStatusRunning:
+		return 0, time.Time{}, false
+	case latest.Status == models.JobRunStatusFailed && latest.NextRetryAt != nil:
+		return latest.Attempt + 1, *latest.NextRetryAt, true
+	}
+	return 1, latest.StartedAt.Add(j.config.Interval), true
+}
+
+// run runs a job and records the run
+func (s *Scheduler) run(ctx context.Context, registered registeredJob, attempt int, now time.Time) {
+	log := logger.FromContext(ctx).With(
+		slog.String("job", registered.job.Name()),
+		slog.Int("attempt", attempt),
+	)
+
+	run := models.NewJobRun(registered.job.Name(), attempt, now)
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		log.Error("failed to record job run", slog.Any("error", err))
+		return
+	}
+
+	start := time.Now()
+	runCtx, cancel := context.WithTimeout(ctx, registered.config.Timeout)
+	err := safeRun(runCtx, registered.job, now)
+	cancel()
+
+	// Times are relative to now, so that runs for a given time are
+	// scheduled consistently
+	elapsed := time.Since(start)
+	finishedAt := now.Add(elapsed)
+	if err == nil {
+		run.Succeed(finishedAt)
+		log.Info("job run succeeded", slog.Int64("duration_ms", elapsed.Milliseconds()))
+	} else {
+		var nextRetryAt *time.Time
+		if attempt < registered.config.MaxAttempts {
+			retryAt := finishedAt.Add(registered.config.backoff(attempt))
+			nextRetryAt = &retryAt
+		}
+		run.Fail(err, finishedAt, nextRetryAt)
+		log.Error("job run failed",
+			slog.Int64("duration_ms", elapsed.Milliseconds()),
+			slog.Bool("retrying", nextRetryAt != nil),
+			slog.Any("error", err),
+		)
+	}
+
+	// Record the outcome even if ctx was cancelled during the run
+	if err := s.runRepo.Update(context.WithoutCancel(ctx), run); err != nil {
+		log.Error("failed to record job run", slog.Any("error", err))
+	}
+}
+
+// safeRun runs a job, turning a panic into an error
+func safeRun(ctx context.Context, job Job, now time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx, now)
+}
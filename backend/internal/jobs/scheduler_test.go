@@ -0,0 +1,210 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/jobs"
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRunRepository is an in-memory JobRunRepository
+type memoryRunRepository struct {
+	mu   sync.Mutex
+	runs []models.JobRun
+}
+
+func (r *memoryRunRepository) Create(ctx context.Context, run *models.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+func (r *memoryRunRepository) Update(ctx context.Context, run *models.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.runs {
+		if r.runs[i].ID == run.ID {
+			r.runs[i] = *run
+			return nil
+		}
+	}
+	return repository.ErrJobRunNotFound
+}
+
+func (r *memoryRunRepository) GetLatest(ctx context.Context, jobName string) (*models.JobRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *models.JobRun
+	for i := range r.runs {
+		if r.runs[i].JobName == jobName && (latest == nil || !r.runs[i].StartedAt.Before(latest.StartedAt)) {
+			run := r.runs[i]
+			latest = &run
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrJobRunNotFound
+	}
+	return latest, nil
+}
+
+func (r *memoryRunRepository) FailRunning(ctx context.Context, message string, finishedAt time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var failed int64
+	for i := range r.runs {
+		if r.runs[i].Status == models.JobRunStatusRunning {
+			r.runs[i].Fail(errors.New(message), finishedAt, &finishedAt)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (r *memoryRunRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.runs[:0]
+	for _, run := range r.runs {
+		if !run.StartedAt.Before(before) {
+			kept = append(kept, run)
+		}
+	}
+	deleted := int64(len(r.runs) - len(kept))
+	r.runs = kept
+	return deleted, nil
+}
+
+// scriptedJob is a job returning the next of its errors on every run
+type scriptedJob struct {
+	name   string
+	errs   []error
+	panics bool
+	runs   []time.Time
+}
+
+func (j *scriptedJob) Name() string {
+	return j.name
+}
+
+func (j *scriptedJob) Run(ctx context.Context, now time.Time) error {
+	j.runs = append(j.runs, now)
+	if j.panics {
+		panic("boom")
+	}
+	if len(j.errs) == 0 {
+		return nil
+	}
+	err := j.errs[0]
+	j.errs = j.errs[1:]
+	return err
+}
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+
+	t.Run("RunsOnInterval", func(t *testing.T) {
+		runRepo := &memoryRunRepository{}
+		scheduler := jobs.NewScheduler(nil, runRepo, 0)
+		job := &scriptedJob{name: "interval"}
+		scheduler.Register(job, jobs.Config{Interval: time.Hour})
+
+		// The first run is due right away, along with the prune job
+		assert.Equal(t, 2, scheduler.RunDue(ctx, start))
+		assert.Equal(t, 0, scheduler.RunDue(ctx, start.Add(59*time.Minute)))
+		scheduler.RunDue(ctx, start.Add(time.Hour))
+		assert.Equal(t, []time.Time{start, start.Add(time.Hour)}, job.runs)
+
+		latest, err := runRepo.GetLatest(ctx, "interval")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobRunStatusSucceeded, latest.Status)
+		assert.Equal(t, 1, latest.Attempt)
+		assert.NotNil(t, latest.FinishedAt)
+	})
+
+	t.Run("RetriesWithBackoff", func(t *testing.T) {
+		runRepo := &memoryRunRepository{}
+		scheduler := jobs.NewScheduler(nil, runRepo, 0)
+		job := &scriptedJob{name: "flaky", errs: []error{errors.New("first"), errors.New("second"), errors.New("third")}}
+		scheduler.Register(job, jobs.Config{
+			Interval:    time.Hour,
+			MaxAttempts: 3,
+			Backoff:     time.Minute,
+		})
+
+		scheduler.RunDue(ctx, start)
+		latest, err := runRepo.GetLatest(ctx, "flaky")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobRunStatusFailed, latest.Status)
+		require.NotNil(t, latest.Error)
+		assert.Equal(t, "first", *latest.Error)
+		require.NotNil(t, latest.NextRetryAt)
+		assert.WithinDuration(t, start.Add(time.Minute), *latest.NextRetryAt, time.Second)
+
+		// The backoff doubles with every retry
+		scheduler.RunDue(ctx, start.Add(59*time.Second))
+		assert.Len(t, job.runs, 1)
+		scheduler.RunDue(ctx, start.Add(time.Minute+time.Second))
+		assert.Len(t, job.runs, 2)
+		latest, err = runRepo.GetLatest(ctx, "flaky")
+		require.NoError(t, err)
+		assert.Equal(t, 2, latest.Attempt)
+		require.NotNil(t, latest.NextRetryAt)
+		assert.WithinDuration(t, start.Add(3*time.Minute+time.Second), *latest.NextRetryAt, time.Second)
+
+		// The last attempt gives up until the next interval
+		scheduler.RunDue(ctx, start.Add(4*time.Minute))
+		assert.Len(t, job.runs, 3)
+		latest, err = runRepo.GetLatest(ctx, "flaky")
+		require.NoError(t, err)
+		assert.Equal(t, 3, latest.Attempt)
+		assert.Nil(t, latest.NextRetryAt)
+
+		scheduler.RunDue(ctx, start.Add(time.Hour+3*time.Minute))
+		assert.Len(t, job.runs, 3)
+		scheduler.RunDue(ctx, start.Add(time.Hour+4*time.Minute))
+		assert.Len(t, job.runs, 4)
+		latest, err = runRepo.GetLatest(ctx, "flaky")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobRunStatusSucceeded, latest.Status)
+		assert.Equal(t, 1, latest.Attempt)
+	})
+
+	t.Run("RecoversPanics", func(t *testing.T) {
+		runRepo := &memoryRunRepository{}
+		scheduler := jobs.NewScheduler(nil, runRepo, 0)
+		scheduler.Register(&scriptedJob{name: "panicky", panics: true}, jobs.Config{})
+
+		scheduler.RunDue(ctx, start)
+		latest, err := runRepo.GetLatest(ctx, "panicky")
+		require.NoError(t, err)
+		assert.Equal(t, models.JobRunStatusFailed, latest.Status)
+		require.NotNil(t, latest.Error)
+		assert.Contains(t, *latest.Error, "boom")
+	})
+
+	t.Run("PrunesOldRuns", func(t *testing.T) {
+		runRepo := &memoryRunRepository{}
+		scheduler := jobs.NewScheduler(nil, runRepo, 0)
+
+		scheduler.RunDue(ctx, start)
+		scheduler.RunDue(ctx, start.Add(jobs.DefaultRetention+time.Hour))
+		assert.Len(t, runRepo.runs, 1)
+	})
+
+	t.Run("RejectsDuplicateNames", func(t *testing.T) {
+		scheduler := jobs.NewScheduler(nil, &memoryRunRepository{}, 0)
+		scheduler.Register(&scriptedJob{name: "twice"}, jobs.Config{})
+		assert.Panics(t, func() {
+			scheduler.Register(&scriptedJob{name: "twice"}, jobs.Config{})
+		})
+	})
+}
@@ -0,0 +1,483 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// TableName is the table recording the applied migrations
+const TableName = "schema_migration_history"
+
+// lockID is the key of the PostgreSQL advisory lock held while migrating
+const lockID int64 = 7_370_617_224_911_201
+
+var (
+	// ErrDrift is returned when the applied migrations do not match the migration files
+	ErrDrift = errors.New("schema drift detected")
+	// ErrNoDownMigration is returned when a migration cannot be rolled back
+	ErrNoDownMigration = errors.New("migration has no down script")
+	// ErrNothingToRollback is returned when no migration has been applied
+	ErrNothingToRollback = errors.New("no migration to roll back")
+)
+
+// fileNamePattern matches migration file names such as 000001_init_schema.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration represents a single schema migration
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status represents the state of a migration in the database
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Drift describes how the applied migration differs from its file, if it does
+	Drift string `json:"drift,omitempty"`
+}
+
+// appliedMigration represents a row of the migration history table
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Migrator applies and rolls back schema migrations
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// Parse reads the migrations from the root of fsys
+func Parse(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected NNNNNN_name.up.sql or NNNNNN_name.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// New creates a new Migrator for the migrations in fsys
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Parse(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending migrations in fsys to db
+func Up(ctx context.Context, db *sqlx.DB, fsys fs.FS) error {
+	migrator, err := New(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(ctx)
+	return err
+}
+
+// Up applies all pending migrations and returns the ones that were applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn, history map[int64]appliedMigration) error {
+		pending, err := m.pending(history)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations and returns the ones that were rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn, history map[int64]appliedMigration) error {
+		targets, err := m.lastApplied(history, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range targets {
+			if err := m.rollback(ctx, conn, migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Redo rolls back the last applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn, history map[int64]appliedMigration) error {
+		targets, err := m.lastApplied(history, 1)
+		if err != nil {
+			return err
+		}
+
+		migration := targets[0]
+		if err := m.rollback(ctx, conn, migration); err != nil {
+			return err
+		}
+		if err := m.apply(ctx, conn, migration); err != nil {
+			return err
+		}
+		redone = &migration
+		return nil
+	})
+	return redone, err
+}
+
+// Status returns the state of every migration, including applied migrations
+// that no longer have a file. It does not fail on drift.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+
+	history, err := m.history(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := history[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			if row.Checksum != migration.Checksum {
+				status.Drift = "checksum mismatch, the migration file changed after it was applied"
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, row := range history {
+		if known[version] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{
+			Version:   version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Drift:     "applied but the migration file is missing",
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock,
+// after checking the applied migrations for drift
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn, history map[int64]appliedMigration) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Session-level advisory locks belong to the connection, so every statement
+	// below must run on conn
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			logger.FromContext(ctx).Error("failed to release migration lock", slog.Any("error", err))
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	history, err := m.history(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := m.checkDrift(history); err != nil {
+		return err
+	}
+
+	return fn(conn, history)
+}
+
+// execer is implemented by *sqlx.DB and *sqlx.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// ensureTable creates the migration history table if it does not exist
+func (m *Migrator) ensureTable(ctx context.Context, db execer) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ` + TableName + ` (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create migration history table: %w", err)
+	}
+	return nil
+}
+
+// history returns the applied migrations by version
+func (m *Migrator) history(ctx context.Context, db execer) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	query := `SELECT version, name, checksum, applied_at FROM ` + TableName + ` ORDER BY version`
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+
+	history := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		history[row.Version] = row
+	}
+	return history, nil
+}
+
+// checkDrift verifies that every applied migration still exists with the same checksum
+func (m *Migrator) checkDrift(history map[int64]appliedMigration) error {
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	versions := make([]int64, 0, len(history))
+	for version := range history {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	var errs []error
+	for _, version := range versions {
+		row := history[version]
+		migration, ok := byVersion[version]
+		if !ok {
+			errs = append(errs, fmt.Errorf("migration %d_%s is applied but its file is missing", version, row.Name))
+			continue
+		}
+		if row.Checksum != migration.Checksum {
+			errs = append(errs, fmt.Errorf("migration %d_%s changed after it was applied (checksum %s, file %s)",
+				version, migration.Name, row.Checksum, migration.Checksum))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrDrift, errors.Join(errs...))
+	}
+	return nil
+}
+
+// pending returns the migrations that have not been applied, failing if one of
+// them is older than the latest applied migration
+func (m *Migrator) pending(history map[int64]appliedMigration) ([]Migration, error) {
+	var latest int64
+	for version := range history {
+		if version > latest {
+			latest = version
+		}
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := history[migration.Version]; ok {
+			continue
+		}
+		if migration.Version < latest {
+			return nil, fmt.Errorf("%w: migration %d_%s is pending but newer migration %d is already applied",
+				ErrDrift, migration.Version, migration.Name, latest)
+		}
+		pending = append(pending, migration)
+	}
+	return pending, nil
+}
+
+// lastApplied returns up to steps applied migrations, newest first
+func (m *Migrator) lastApplied(history map[int64]appliedMigration, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("invalid number of steps: %d", steps)
+	}
+
+	var targets []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(targets) < steps; i-- {
+		if _, ok := history[m.migrations[i].Version]; ok {
+			targets = append(targets, m.migrations[i])
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, ErrNothingToRollback
+	}
+	return targets, nil
+}
+
+// apply runs the up script of a migration and records it in a single transaction
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	start := time.Now()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query := `INSERT INTO ` + TableName + ` (version, name, checksum) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, query, migration.Version, migration.Name, migration.Checksum); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	logger.FromContext(ctx).Info("applied migration",
+		slog.Int64("version", migration.Version),
+		slog.String("name", migration.Name),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+	)
+	return nil
+}
+
+// rollback runs the down script of a migration and removes its record in a single transaction
+func (m *Migrator) rollback(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+	}
+
+	start := time.Now()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query := `DELETE FROM ` + TableName + ` WHERE version = $1`
+	if _, err := tx.ExecContext(ctx, query, migration.Version); err != nil {
+		return fmt.Errorf("failed to remove migration record %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollback of migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	logger.FromContext(ctx).Info("rolled back migration",
+		slog.Int64("version", migration.Version),
+		slog.String("name", migration.Name),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+	)
+	return nil
+}
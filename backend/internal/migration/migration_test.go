@@ -0,0 +1,80 @@
+package migration_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Jerinji2016/halooid/backend/internal/migration"
+	"github.com/Jerinji2016/halooid/backend/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("OrdersAndPairsFiles", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000010_add_b.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+			"000002_add_a.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+			"000002_add_a.down.sql": {Data: []byte("DROP TABLE a;")},
+			"README.md":             {Data: []byte("ignored")},
+		}
+
+		parsed, err := migration.Parse(fsys)
+		require.NoError(t, err)
+		require.Len(t, parsed, 2)
+
+		assert.Equal(t, int64(2), parsed[0].Version)
+		assert.Equal(t, "add_a", parsed[0].Name)
+		assert.Equal(t, "DROP TABLE a;", parsed[0].Down)
+		assert.Equal(t, int64(10), parsed[1].Version)
+		assert.Empty(t, parsed[1].Down)
+		assert.Len(t, parsed[0].Checksum, 64)
+		assert.NotEqual(t, parsed[0].Checksum, parsed[1].Checksum)
+	})
+
+	t.Run("ChecksumCoversUpScript", func(t *testing.T) {
+		first, err := migration.Parse(fstest.MapFS{"000001_a.up.sql": {Data: []byte("SELECT 1;")}})
+		require.NoError(t, err)
+		second, err := migration.Parse(fstest.MapFS{"000001_a.up.sql": {Data: []byte("SELECT 2;")}})
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first[0].Checksum, second[0].Checksum)
+	})
+
+	t.Run("RejectsInvalidFiles", func(t *testing.T) {
+		_, err := migration.Parse(fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}})
+		assert.Error(t, err)
+
+		_, err = migration.Parse(fstest.MapFS{"000001_a.down.sql": {Data: []byte("SELECT 1;")}})
+		assert.Error(t, err)
+
+		_, err = migration.Parse(fstest.MapFS{
+			"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"000001_b.up.sql": {Data: []byte("SELECT 1;")},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	parsed, err := migration.Parse(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, parsed)
+
+	createTable := regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?([a-z0-9_.]+)`)
+	owners := make(map[string]int64)
+	for _, m := range parsed {
+		assert.NotEmpty(t, strings.TrimSpace(m.Down), "migration %d_%s has no down script", m.Version, m.Name)
+
+		// Every table must be created by exactly one migration
+		for _, match := range createTable.FindAllStringSubmatch(m.Up, -1) {
+			table := strings.ToLower(match[1])
+			if owner, ok := owners[table]; ok {
+				t.Errorf("table %s is created by both migration %d and %d", table, owner, m.Version)
+			}
+			owners[table] = m.Version
+		}
+	}
+}
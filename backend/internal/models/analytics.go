@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults and bounds of the analytics report parameters
+const (
+	DefaultVelocitySprints = 6
+	MaxVelocitySprints     = 50
+	DefaultAnalyticsWeeks  = 12
+	MaxAnalyticsWeeks      = 104
+)
+
+// AnalyticsDateFormat is the format of the days and weeks of reports
+const AnalyticsDateFormat = "2006-01-02"
+
+// TaskTimeline represents a task with the changes of its status and sprint,
+// in the order they were made, from which analytics are derived
+type TaskTimeline struct {
+	TaskID         uuid.UUID  `db:"id"`
+	Status         TaskStatus `db:"status"`
+	SprintID       *uuid.UUID `db:"sprint_id"`
+	EstimatedHours *float64   `db:"estimated_hours"`
+	CreatedAt      time.Time  `db:"created_at"`
+
+	// Changes are the status and sprint changes of the task
+	Changes []TaskHistory `db:"-"`
+}
+
+// TaskTimelineFilter selects task timelines
+type TaskTimelineFilter struct {
+	// ProjectID limits timelines to the tasks of a project
+	ProjectID *uuid.UUID
+
+	// UpdatedSince limits timelines to the tasks changed since a time
+	UpdatedSince *time.Time
+
+	// SprintIDs limits timelines to the tasks that are or have been in one
+	// of the sprints
+	SprintIDs []uuid.UUID
+}
+
+// Burndown represents the progress of a sprint, day by day: the tasks in the
+// sprint (its scope), the completed tasks and the tasks remaining. Charted as
+// remaining tasks it is a burndown, as scope and completed tasks a burnup.
+type Burndown struct {
+	SprintID  uuid.UUID  `json:"sprint_id"`
+	StartedAt time.Time  `json:"started_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+
+	// EndDate is the last planned day of the sprint
+	EndDate string `json:"end_date"`
+
+	// CommittedTasks and CommittedHours are the scope when the sprint started
+	CommittedTasks int     `json:"committed_tasks"`
+	CommittedHours float64 `json:"committed_hours"`
+
+	Days []BurndownDay `json:"days"`
+}
+
+// BurndownDay represents the progress of a sprint at the end of a day, or
+// when the sprint was closed on its last day
+type BurndownDay struct {
+	Date string `json:"date"`
+
+	ScopeTasks     int     `json:"scope_tasks"`
+	CompletedTasks int     `json:"completed_tasks"`
+	RemainingTasks int     `json:"remaining_tasks"`
+	IdealTasks     float64 `json:"ideal_tasks"`
+
+	// Hours are the estimated hours of the tasks
+	ScopeHours     float64 `json:"scope_hours"`
+	CompletedHours float64 `json:"completed_hours"`
+	RemainingHours float64 `json:"remaining_hours"`
+	IdealHours     float64 `json:"ideal_hours"`
+}
+
+// Velocity represents the work completed in the last closed sprints of a
+// project
+type Velocity struct {
+	ProjectID uuid.UUID        `json:"project_id"`
+	Sprints   []SprintVelocity `json:"sprints"`
+
+	AverageCompletedTasks float64 `json:"average_completed_tasks"`
+	AverageCompletedHours float64 `json:"average_completed_hours"`
+}
+
+// SprintVelocity represents the work committed to and completed in a sprint
+type SprintVelocity struct {
+	SprintID  uuid.UUID `json:"sprint_id"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+	ClosedAt  time.Time `json:"closed_at"`
+
+	CommittedTasks int     `json:"committed_tasks"`
+	CompletedTasks int     `json:"completed_tasks"`
+	CommittedHours float64 `json:"committed_hours"`
+	CompletedHours float64 `json:"completed_hours"`
+}
+
+// CycleTime represents how long the tasks of a project completed in a period
+// took, in hours
+type CycleTime struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+
+	// LeadTime is the time from creating a task to completing it
+	LeadTime Distribution `json:"lead_time"`
+
+	// CycleTime is the time from starting work on a task to completing it
+	CycleTime Distribution `json:"cycle_time"`
+
+	// Transitions are the times spent in a status before moving on, by
+	// status transition
+	Transitions []TransitionTime `json:"transitions"`
+}
+
+// TransitionTime represents the time spent in a status before a transition
+type TransitionTime struct {
+	From TaskStatus   `json:"from"`
+	To   TaskStatus   `json:"to"`
+	Time Distribution `json:"time"`
+}
+
+// Distribution summarizes durations, in hours
+type Distribution struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P85    float64 `json:"p85"`
+	P95    float64 `json:"p95"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// Throughput represents the tasks of a project completed each week
+type Throughput struct {
+	ProjectID uuid.UUID        `json:"project_id"`
+	Weeks     []ThroughputWeek `json:"weeks"`
+}
+
+// ThroughputWeek represents the tasks completed in a week, which starts on
+// Monday
+type ThroughputWeek struct {
+	WeekStart      string  `json:"week_start"`
+	CompletedTasks int     `json:"completed_tasks"`
+	CompletedHours float64 `json:"completed_hours"`
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BillingRoundingMode represents how billed time is rounded
+type BillingRoundingMode string
+
+// Billing rounding modes. Time is rounded entry by entry to a multiple of
+// the rounding increment of its project.
+const (
+	BillingRoundingUp      BillingRoundingMode = "up"
+	BillingRoundingDown    BillingRoundingMode = "down"
+	BillingRoundingNearest BillingRoundingMode = "nearest"
+)
+
+// DefaultBillingCurrency is the currency of projects without billing settings
+const DefaultBillingCurrency = "USD"
+
+// BillingSettings represents how the time logged on a project is billed
+type BillingSettings struct {
+	ProjectID       uuid.UUID           `json:"project_id" db:"project_id"`
+	ClientName      string              `json:"client_name" db:"client_name"`
+	Currency        string              `json:"currency" db:"currency"`
+	RoundingMinutes int                 `json:"rounding_minutes" db:"rounding_minutes"`
+	RoundingMode    BillingRoundingMode `json:"rounding_mode" db:"rounding_mode"`
+	UpdatedBy       *uuid.UUID          `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt       *time.Time          `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// DefaultBillingSettings returns the settings of a project that has none:
+// billed in USD, without rounding
+func DefaultBillingSettings(projectID uuid.UUID) *BillingSettings {
+	return &BillingSettings{
+		ProjectID:    projectID,
+		Currency:     DefaultBillingCurrency,
+		RoundingMode: BillingRoundingNearest,
+	}
+}
+
+// Round rounds the minutes of a time entry to the rounding increment of the
+// settings. Increments of 0 or 1 minute leave them unchanged.
+func (s *BillingSettings) Round(minutes int) int {
+	increment := s.RoundingMinutes
+	if increment <= 1 || minutes <= 0 {
+		return minutes
+	}
+
+	switch s.RoundingMode {
+	case BillingRoundingUp:
+		return (minutes + increment - 1) / increment * increment
+	case BillingRoundingDown:
+		return minutes / increment * increment
+	default:
+		return (minutes + increment/2) / increment * increment
+	}
+}
+
+// BillingSettingsRequest represents the data needed to update the billing
+// settings of a project
+type BillingSettingsRequest struct {
+	ClientName      string              `json:"client_name" validate:"max=255"`
+	Currency        string              `json:"currency" validate:"required,len=3,uppercase"`
+	RoundingMinutes int                 `json:"rounding_minutes" validate:"min=0,max=60"`
+	RoundingMode    BillingRoundingMode `json:"rounding_mode" validate:"omitempty,oneof=up down nearest"`
+}
+
+// BillingRate represents an hourly rate of a project's rate card. A rate is
+// for a member of the project when UserID is set, for the members with a
+// role when RoleID is set, and the project's default rate otherwise. It
+// applies from EffectiveFrom to EffectiveTo, both included, or for good
+// when EffectiveTo is nil.
+type BillingRate struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ProjectID     uuid.UUID  `json:"project_id" db:"project_id"`
+	UserID        *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	RoleID        *uuid.UUID `json:"role_id,omitempty" db:"role_id"`
+	HourlyRate    float64    `json:"hourly_rate" db:"hourly_rate"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" db:"effective_to"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AppliesOn reports whether the rate applies on a UTC day
+func (r *BillingRate) AppliesOn(day time.Time) bool {
+	return !day.Before(r.EffectiveFrom) && (r.EffectiveTo == nil || !day.After(*r.EffectiveTo))
+}
+
+// SameScope reports whether two rates are for the same members of a project
+func (r *BillingRate) SameScope(other *BillingRate) bool {
+	return sameID(r.UserID, other.UserID) && sameID(r.RoleID, other.RoleID)
+}
+
+// Overlaps reports whether the periods of two rates share a day
+func (r *BillingRate) Overlaps(other *BillingRate) bool {
+	return (r.EffectiveTo == nil || !other.EffectiveFrom.After(*r.EffectiveTo)) &&
+		(other.EffectiveTo == nil || !r.EffectiveFrom.After(*other.EffectiveTo))
+}
+
+// sameID reports whether two optional IDs are equal
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// BillingRateRequest represents the data needed to create or update a rate.
+// Dates are truncated to their UTC day.
+type BillingRateRequest struct {
+	UserID        *uuid.UUID `json:"user_id,omitempty"`
+	RoleID        *uuid.UUID `json:"role_id,omitempty"`
+	HourlyRate    float64    `json:"hourly_rate" validate:"min=0,max=1000000"`
+	EffectiveFrom time.Time  `json:"effective_from" validate:"required"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+}
+
+// BillingDay returns the UTC day of t, which rates and billing periods are
+// counted in
+func BillingDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// NewBillingRate creates a new BillingRate from a BillingRateRequest
+func NewBillingRate(req BillingRateRequest, projectID, createdBy uuid.UUID) *BillingRate {
+	now := time.Now()
+	rate := &BillingRate{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	rate.Apply(req)
+	return rate
+}
+
+// Apply sets the scope, rate and period of a request on the rate
+func (r *BillingRate) Apply(req BillingRateRequest) {
+	r.UserID = req.UserID
+	r.RoleID = req.RoleID
+	r.HourlyRate = req.HourlyRate
+	r.EffectiveFrom = BillingDay(req.EffectiveFrom)
+	r.EffectiveTo = nil
+	if req.EffectiveTo != nil {
+		to := BillingDay(*req.EffectiveTo)
+		r.EffectiveTo = &to
+	}
+}
+
+// BillingEntry represents a finished time entry on a project of an
+// organization, as billed
+type BillingEntry struct {
+	ID          uuid.UUID `db:"id"`
+	ProjectID   uuid.UUID `db:"project_id"`
+	ProjectName string    `db:"project_name"`
+	UserID      uuid.UUID `db:"user_id"`
+	UserName    string    `db:"user_name"`
+	StartTime   time.Time `db:"start_time"`
+	Minutes     int       `db:"minutes"`
+	Billable    bool      `db:"billable"`
+}
+
+// BillingReportParams represents the parameters of a billing report
+type BillingReportParams struct {
+	OrganizationID uuid.UUID
+	ProjectID      *uuid.UUID
+
+	// From and To are the first and last UTC days of the period
+	From time.Time
+	To   time.Time
+}
+
+// BillingReport represents the amounts to bill for the time logged on the
+// projects of an organization over a period
+type BillingReport struct {
+	OrganizationID uuid.UUID        `json:"organization_id"`
+	From           string           `json:"from"`
+	To             string           `json:"to"`
+	Projects       []BillingProject `json:"projects"`
+
+	// Totals are the amounts of all projects, by currency
+	Totals []BillingTotal `json:"totals"`
+}
+
+// BillingProject represents the amount to bill for the time logged on a
+// project, line by line
+type BillingProject struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ClientName  string    `json:"client_name"`
+	Currency    string    `json:"currency"`
+
+	// BillableMinutes and NonBillableMinutes are the time logged, and
+	// BilledMinutes the billable time once rounded. UnratedMinutes are
+	// billed minutes without a rate, which are not billed any amount.
+	BillableMinutes    int `json:"billable_minutes"`
+	NonBillableMinutes int `json:"non_billable_minutes"`
+	BilledMinutes      int `json:"billed_minutes"`
+	UnratedMinutes     int `json:"unrated_minutes"`
+
+	Amount float64       `json:"amount"`
+	Lines  []BillingLine `json:"lines"`
+}
+
+// BillingLine represents the billable time of a member of a project at a
+// rate
+type BillingLine struct {
+	UserID        uuid.UUID  `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	RateID        *uuid.UUID `json:"rate_id,omitempty"`
+	HourlyRate    float64    `json:"hourly_rate"`
+	Entries       int        `json:"entries"`
+	Minutes       int        `json:"minutes"`
+	BilledMinutes int        `json:"billed_minutes"`
+	Hours         float64    `json:"hours"`
+	Amount        float64    `json:"amount"`
+}
+
+// BillingTotal represents the amount to bill in a currency
+type BillingTotal struct {
+	Currency      string  `json:"currency"`
+	BilledMinutes int     `json:"billed_minutes"`
+	Amount        float64 `json:"amount"`
+}
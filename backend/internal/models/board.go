@@ -0,0 +1,193 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRank is returned when ranks cannot be ordered
+var ErrInvalidRank = errors.New("invalid rank")
+
+// rankDigits are the digits of a rank, in ascending order
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// rankWidth is the number of leading digits counted up or down to rank a task
+// first or last, so that adding tasks at either end does not make ranks longer
+const rankWidth = 6
+
+// DefaultBoardLimit and MaxBoardLimit bound the tasks returned per column
+const (
+	DefaultBoardLimit = 100
+	MaxBoardLimit     = 500
+)
+
+// BoardScope selects the tasks of a project shown on a board
+type BoardScope struct {
+	ProjectID uuid.UUID
+
+	// SprintID limits the board to the tasks of a sprint
+	SprintID *uuid.UUID
+
+	// Backlog limits the board to the tasks without a sprint
+	Backlog bool
+}
+
+// Board represents the tasks of a project grouped into a column per workflow
+// state, in rank order
+type Board struct {
+	ProjectID uuid.UUID     `json:"project_id"`
+	Sprint    *Sprint       `json:"sprint,omitempty"`
+	Backlog   bool          `json:"backlog"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// BoardColumn represents a column of a board
+type BoardColumn struct {
+	State WorkflowState  `json:"state"`
+	Tasks []TaskResponse `json:"tasks"`
+
+	// Total is the number of tasks in the column, which may be more than
+	// the tasks returned
+	Total int `json:"total"`
+}
+
+// TaskMoveRequest represents a task dragged on a board: to a column, between
+// two of its tasks. Without neighbours the task moves to the end of the column.
+type TaskMoveRequest struct {
+	// Status is the column the task moves to (default: its current status)
+	Status TaskStatus `json:"status,omitempty" validate:"omitempty,max=50"`
+
+	// AfterID is the task the moved task is placed after
+	AfterID *uuid.UUID `json:"after_id,omitempty"`
+
+	// BeforeID is the task the moved task is placed before
+	BeforeID *uuid.UUID `json:"before_id,omitempty"`
+
+	// Force closes the task even if it is blocked
+	Force bool `json:"force"`
+}
+
+// RankBetween returns a rank that sorts after a and before b. An empty a
+// sorts before and an empty b after all ranks.
+func RankBetween(a, b string) (string, error) {
+	if !validRank(a) || !validRank(b) || (a != "" && b != "" && a >= b) {
+		return "", ErrInvalidRank
+	}
+
+	switch {
+	case a == "" && b == "":
+		return rankMidpoint("", ""), nil
+	case b == "":
+		return rankAfter(a), nil
+	case a == "":
+		return rankBefore(b), nil
+	}
+	return rankMidpoint(a, b), nil
+}
+
+// validRank reports whether rank consists of rank digits and, as ranks
+// never do, does not end with a zero
+func validRank(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, "0")
+}
+
+// rankAfter returns a rank after a by counting up its leading digits
+func rankAfter(a string) string {
+	digits := rankPrefix(a)
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(rankDigits, digits[i])
+		if digit < len(rankDigits)-1 {
+			digits[i] = rankDigits[digit+1]
+			return strings.TrimRight(string(digits), rankDigits[:1])
+		}
+		digits[i] = rankDigits[0]
+	}
+
+	// The leading digits are all at their highest
+	return a + rankMidpoint("", "")
+}
+
+// rankBefore returns a rank before b by counting down its leading digits
+func rankBefore(b string) string {
+	digits := rankPrefix(b)
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(rankDigits, digits[i])
+		if digit > 0 {
+			digits[i] = rankDigits[digit-1]
+			if rank := strings.TrimRight(string(digits), rankDigits[:1]); rank != "" {
+				return rank
+			}
+			break
+		}
+		digits[i] = rankDigits[len(rankDigits)-1]
+	}
+
+	// The leading digits are all at their lowest
+	return rankMidpoint("", b)
+}
+
+// rankPrefix returns the leading digits of rank, padded with zeros
+func rankPrefix(rank string) []byte {
+	digits := make([]byte, rankWidth)
+	for i := range digits {
+		digits[i] = rankDigitAt(rank, i)
+	}
+	return digits
+}
+
+// rankMidpoint returns a rank between a and b, where a < b and an empty b has
+// no upper bound. A missing digit of a counts as zero.
+func rankMidpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix
+		n := 0
+		for n < len(b) && rankDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + rankMidpoint(rankSuffix(a, n), b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(rankDigits, a[0])
+	}
+	digitB := len(rankDigits)
+	if b != "" {
+		digitB = strings.IndexByte(rankDigits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(rankDigits[(digitA+digitB+1)/2])
+	}
+
+	// The first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(rankDigits[digitA]) + rankMidpoint(rankSuffix(a, 1), "")
+}
+
+// rankDigitAt returns the digit of rank at i, or zero past its end
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return rankDigits[0]
+}
+
+// rankSuffix returns rank without its first n digits
+func rankSuffix(rank string, n int) string {
+	if n >= len(rank) {
+		return ""
+	}
+	return rank[n:]
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChecklistItem represents a lightweight to-do item inside a task
+type ChecklistItem struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	Title       string     `json:"title" db:"title"`
+	IsCompleted bool       `json:"is_completed" db:"is_completed"`
+	Position    int        `json:"position" db:"position"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ChecklistItemRequest represents the data needed to create or update a checklist item
+type ChecklistItemRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	IsCompleted bool   `json:"is_completed"`
+	Position    *int   `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// ChecklistItemResponse represents the checklist item data returned to clients
+type ChecklistItemResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	TaskID      uuid.UUID  `json:"task_id"`
+	Title       string     `json:"title"`
+	IsCompleted bool       `json:"is_completed"`
+	Position    int        `json:"position"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ToResponse converts a ChecklistItem to a ChecklistItemResponse
+func (i *ChecklistItem) ToResponse() ChecklistItemResponse {
+	return ChecklistItemResponse{
+		ID:          i.ID,
+		TaskID:      i.TaskID,
+		Title:       i.Title,
+		IsCompleted: i.IsCompleted,
+		Position:    i.Position,
+		CompletedAt: i.CompletedAt,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+	}
+}
+
+// NewChecklistItem creates a new ChecklistItem from a ChecklistItemRequest.
+// A nil position appends the item to the end of the checklist.
+func NewChecklistItem(taskID uuid.UUID, req ChecklistItemRequest) *ChecklistItem {
+	now := time.Now()
+	item := &ChecklistItem{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		Title:     req.Title,
+		Position:  -1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if req.Position != nil {
+		item.Position = *req.Position
+	}
+
+	item.SetCompleted(req.IsCompleted)
+
+	return item
+}
+
+// SetCompleted marks the item as completed or not, keeping CompletedAt in sync
+func (i *ChecklistItem) SetCompleted(completed bool) {
+	if completed == i.IsCompleted {
+		return
+	}
+
+	i.IsCompleted = completed
+	i.CompletedAt = nil
+	if completed {
+		now := time.Now()
+		i.CompletedAt = &now
+	}
+}
@@ -0,0 +1,325 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomFieldType represents the type of value a custom field holds
+type CustomFieldType string
+
+// Custom field types
+const (
+	CustomFieldTypeText        CustomFieldType = "text"
+	CustomFieldTypeNumber      CustomFieldType = "number"
+	CustomFieldTypeDate        CustomFieldType = "date"
+	CustomFieldTypeSelect      CustomFieldType = "select"
+	CustomFieldTypeMultiSelect CustomFieldType = "multi_select"
+	CustomFieldTypeUser        CustomFieldType = "user"
+)
+
+// CustomFieldEntity represents the kind of record a custom field applies to
+type CustomFieldEntity string
+
+// Custom field entities
+const (
+	CustomFieldEntityTask    CustomFieldEntity = "task"
+	CustomFieldEntityProject CustomFieldEntity = "project"
+)
+
+// CustomFieldDateFormat is the format of date custom field values
+const CustomFieldDateFormat = "2006-01-02"
+
+// maxCustomFieldTextLength is the maximum length of a text custom field value
+const maxCustomFieldTextLength = 1000
+
+// customFieldKeyPattern matches valid custom field keys
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// IsValidCustomFieldKey reports whether key can be used as a custom field key.
+// Keys are lowercase letters, digits and underscores, starting with a letter.
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+// ErrInvalidCustomField is returned when custom field values do not match their definitions
+var ErrInvalidCustomField = errors.New("invalid custom field")
+
+// CustomFieldError describes why the value of a custom field is invalid
+type CustomFieldError struct {
+	Key    string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CustomFieldError) Error() string {
+	return fmt.Sprintf("%s %q: %s", ErrInvalidCustomField, e.Key, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidCustomField
+func (e *CustomFieldError) Is(target error) bool {
+	return target == ErrInvalidCustomField
+}
+
+// CustomFieldDefinition defines a custom field of an organization, or of a
+// single project when ProjectID is set
+type CustomFieldDefinition struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	OrganizationID uuid.UUID         `json:"organization_id" db:"organization_id"`
+	ProjectID      *uuid.UUID        `json:"project_id,omitempty" db:"project_id"`
+	Entity         CustomFieldEntity `json:"entity" db:"entity"`
+	Key            string            `json:"key" db:"key"`
+	Name           string            `json:"name" db:"name"`
+	Type           CustomFieldType   `json:"type" db:"type"`
+	Options        []string          `json:"options,omitempty" db:"-"`
+	Required       bool              `json:"required" db:"required"`
+	Position       int               `json:"position" db:"position"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CustomFieldDefinitionRequest represents the data needed to create or update a custom field definition
+type CustomFieldDefinitionRequest struct {
+	ProjectID *uuid.UUID        `json:"project_id,omitempty" validate:"omitempty,uuid4"`
+	Entity    CustomFieldEntity `json:"entity" validate:"required,oneof=task project"`
+	Key       string            `json:"key" validate:"required,max=50"`
+	Name      string            `json:"name" validate:"required,max=100"`
+	Type      CustomFieldType   `json:"type" validate:"required,oneof=text number date select multi_select user"`
+	Options   []string          `json:"options,omitempty" validate:"required_if=Type select,required_if=Type multi_select,dive,required,max=100"`
+	Required  bool              `json:"required"`
+	Position  int               `json:"position" validate:"min=0"`
+}
+
+// NewCustomFieldDefinition creates a new CustomFieldDefinition from a CustomFieldDefinitionRequest
+func NewCustomFieldDefinition(organizationID uuid.UUID, req CustomFieldDefinitionRequest) *CustomFieldDefinition {
+	now := time.Now()
+	return &CustomFieldDefinition{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		ProjectID:      req.ProjectID,
+		Entity:         req.Entity,
+		Key:            req.Key,
+		Name:           req.Name,
+		Type:           req.Type,
+		Options:        req.Options,
+		Required:       req.Required,
+		Position:       req.Position,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Normalize checks that value is valid for the field and returns it in its
+// stored form: numbers as JSON numbers, dates as YYYY-MM-DD strings, users as
+// UUID strings and multi-select values as lists of options.
+func (d *CustomFieldDefinition) Normalize(value interface{}) (interface{}, error) {
+	invalid := func(reason string) error {
+		return &CustomFieldError{Key: d.Key, Reason: reason}
+	}
+
+	switch d.Type {
+	case CustomFieldTypeText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a string")
+		}
+		if len(text) > maxCustomFieldTextLength {
+			return nil, invalid(fmt.Sprintf("must be at most %d characters", maxCustomFieldTextLength))
+		}
+		return text, nil
+
+	case CustomFieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, invalid("must be a number")
+		}
+		return number, nil
+
+	case CustomFieldTypeDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a date string")
+		}
+		date, err := time.Parse(CustomFieldDateFormat, text)
+		if err != nil {
+			return nil, invalid("must be a date in YYYY-MM-DD format")
+		}
+		return date.Format(CustomFieldDateFormat), nil
+
+	case CustomFieldTypeSelect:
+		option, ok := value.(string)
+		if !ok || !d.hasOption(option) {
+			return nil, invalid("must be one of the field's options")
+		}
+		return option, nil
+
+	case CustomFieldTypeMultiSelect:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, invalid("must be a list of options")
+		}
+		options := make([]string, 0, len(items))
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			option, ok := item.(string)
+			if !ok || !d.hasOption(option) {
+				return nil, invalid("must only contain the field's options")
+			}
+			if !seen[option] {
+				seen[option] = true
+				options = append(options, option)
+			}
+		}
+		return options, nil
+
+	case CustomFieldTypeUser:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a user ID")
+		}
+		id, err := uuid.Parse(text)
+		if err != nil {
+			return nil, invalid("must be a user ID")
+		}
+		return id.String(), nil
+	}
+
+	return nil, invalid(fmt.Sprintf("has unknown type %q", d.Type))
+}
+
+// hasOption reports whether option is one of the field's options
+func (d *CustomFieldDefinition) hasOption(option string) bool {
+	for _, o := range d.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomFieldValues holds the custom field values of a record, keyed by field
+// key. It is stored as a JSONB column.
+type CustomFieldValues map[string]interface{}
+
+// Value implements driver.Valuer
+func (v CustomFieldValues) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan implements sql.Scanner
+func (v *CustomFieldValues) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into CustomFieldValues", src)
+	}
+
+	values := CustomFieldValues{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+
+	*v = values
+	return nil
+}
+
+// ValidateCustomFields checks values against definitions and returns them
+// normalized. Unknown keys and missing required fields are rejected; null
+// values clear a field.
+func ValidateCustomFields(definitions []CustomFieldDefinition, values map[string]interface{}) (CustomFieldValues, error) {
+	byKey := make(map[string]*CustomFieldDefinition, len(definitions))
+	for i := range definitions {
+		byKey[definitions[i].Key] = &definitions[i]
+	}
+
+	normalized := CustomFieldValues{}
+	for key, value := range values {
+		definition, ok := byKey[key]
+		if !ok {
+			return nil, &CustomFieldError{Key: key, Reason: "is not defined"}
+		}
+		if value == nil {
+			continue
+		}
+
+		value, err := definition.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = value
+	}
+
+	for _, definition := range definitions {
+		if _, ok := normalized[definition.Key]; definition.Required && !ok {
+			return nil, &CustomFieldError{Key: definition.Key, Reason: "is required"}
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	return normalized, nil
+}
+
+// CustomFieldOperator represents a comparison used to filter by a custom field
+type CustomFieldOperator string
+
+// Custom field operators. Eq matches any option of a multi-select field.
+const (
+	CustomFieldOperatorEq  CustomFieldOperator = "eq"
+	CustomFieldOperatorGte CustomFieldOperator = "gte"
+	CustomFieldOperatorLte CustomFieldOperator = "lte"
+)
+
+// CustomFieldFilter filters records by the value of a custom field
+type CustomFieldFilter struct {
+	Key      string
+	Operator CustomFieldOperator
+	Value    string
+
+	// Type is resolved from the field's definition before the filter is applied
+	Type CustomFieldType
+}
+
+// CustomFieldSort sorts records by the value of a custom field
+type CustomFieldSort struct {
+	Key string
+}
+
+// FilterValue converts the filter's value to the stored form of its field
+func (f *CustomFieldFilter) FilterValue() (interface{}, error) {
+	switch f.Type {
+	case CustomFieldTypeNumber:
+		number, err := strconv.ParseFloat(f.Value, 64)
+		if err != nil {
+			return nil, &CustomFieldError{Key: f.Key, Reason: "filter value must be a number"}
+		}
+		return number, nil
+	case CustomFieldTypeDate, CustomFieldTypeUser:
+		definition := CustomFieldDefinition{Key: f.Key, Type: f.Type}
+		return definition.Normalize(f.Value)
+	default:
+		return f.Value, nil
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRunStatus represents the state of a background job run
+type JobRunStatus string
+
+// Job run statuses
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRun represents a run of a background job
+type JobRun struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	JobName    string       `json:"job_name" db:"job_name"`
+	Attempt    int          `json:"attempt" db:"attempt"`
+	Status     JobRunStatus `json:"status" db:"status"`
+	Error      *string      `json:"error,omitempty" db:"error"`
+	StartedAt  time.Time    `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty" db:"finished_at"`
+
+	// NextRetryAt is when a failed run is retried. It is unset if the run
+	// succeeded or has used up its attempts.
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty" db:"next_retry_at"`
+}
+
+// NewJobRun creates a new running JobRun
+func NewJobRun(jobName string, attempt int, startedAt time.Time) *JobRun {
+	return &JobRun{
+		ID:        uuid.New(),
+		JobName:   jobName,
+		Attempt:   attempt,
+		Status:    JobRunStatusRunning,
+		StartedAt: startedAt,
+	}
+}
+
+// Succeed marks the run as succeeded
+func (r *JobRun) Succeed(finishedAt time.Time) {
+	r.Status = JobRunStatusSucceeded
+	r.FinishedAt = &finishedAt
+}
+
+// Fail marks the run as failed, to be retried at nextRetryAt if set
+func (r *JobRun) Fail(err error, finishedAt time.Time, nextRetryAt *time.Time) {
+	message := err.Error()
+	r.Status = JobRunStatusFailed
+	r.Error = &message
+	r.FinishedAt = &finishedAt
+	r.NextRetryAt = nextRetryAt
+}
@@ -17,6 +17,9 @@ const (
 	NotificationTypeTaskOverdue      NotificationType = "task_overdue"
 	NotificationTypeTaskComment      NotificationType = "task_comment"
 	NotificationTypeTaskMention      NotificationType = "task_mention"
+	NotificationTypeTaskUnblocked    NotificationType = "task_unblocked"
+	NotificationTypeTaskBulkUpdate   NotificationType = "task_bulk_update"
+	NotificationTypeTimerStopped     NotificationType = "time_entry_timer_stopped"
 )
 
 // Notification represents a notification in the system
@@ -27,6 +27,9 @@ type Project struct {
 	Status         ProjectStatus `json:"status" db:"status"`
 	StartDate      *time.Time    `json:"start_date,omitempty" db:"start_date"`
 	EndDate        *time.Time    `json:"end_date,omitempty" db:"end_date"`
+	CustomFields   CustomFieldValues `json:"custom_fields,omitempty" db:"custom_fields"`
+	ArchivedAt     *time.Time    `json:"archived_at,omitempty" db:"archived_at"`
+	ArchivedBy     *uuid.UUID    `json:"archived_by,omitempty" db:"archived_by"`
 	CreatedBy      uuid.UUID     `json:"created_by" db:"created_by"`
 	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time     `json:"updated_at" db:"updated_at"`
@@ -35,6 +38,12 @@ type Project struct {
 	Creator        *User         `json:"creator,omitempty" db:"-"`
 }
 
+// IsArchived reports whether a project is archived, and so read-only. It is
+// false for a nil project, as tasks without a project are never read-only.
+func (p *Project) IsArchived() bool {
+	return p != nil && p.ArchivedAt != nil
+}
+
 // ProjectRequest represents the data needed to create or update a project
 type ProjectRequest struct {
 	OrganizationID uuid.UUID     `json:"organization_id" validate:"required,uuid4"`
@@ -43,6 +52,7 @@ type ProjectRequest struct {
 	Status         ProjectStatus `json:"status" validate:"required,oneof=planning active on_hold completed cancelled"`
 	StartDate      *time.Time    `json:"start_date,omitempty"`
 	EndDate        *time.Time    `json:"end_date,omitempty"`
+	CustomFields   map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // ProjectResponse represents the project data returned to clients
@@ -54,6 +64,9 @@ type ProjectResponse struct {
 	Status         ProjectStatus `json:"status"`
 	StartDate      *time.Time    `json:"start_date,omitempty"`
 	EndDate        *time.Time    `json:"end_date,omitempty"`
+	CustomFields   CustomFieldValues `json:"custom_fields,omitempty"`
+	ArchivedAt     *time.Time    `json:"archived_at,omitempty"`
+	ArchivedBy     *uuid.UUID    `json:"archived_by,omitempty"`
 	CreatedBy      uuid.UUID     `json:"created_by"`
 	CreatedAt      time.Time     `json:"created_at"`
 	UpdatedAt      time.Time     `json:"updated_at"`
@@ -72,6 +85,9 @@ func (p *Project) ToResponse() ProjectResponse {
 		Status:         p.Status,
 		StartDate:      p.StartDate,
 		EndDate:        p.EndDate,
+		CustomFields:   p.CustomFields,
+		ArchivedAt:     p.ArchivedAt,
+		ArchivedBy:     p.ArchivedBy,
 		CreatedBy:      p.CreatedBy,
 		CreatedAt:      p.CreatedAt,
 		UpdatedAt:      p.UpdatedAt,
@@ -107,6 +123,7 @@ type ProjectListParams struct {
 	OrganizationID uuid.UUID      `query:"organization_id" validate:"required,uuid4"`
 	Status         *ProjectStatus `query:"status"`
 	CreatedBy      *uuid.UUID     `query:"created_by"`
+	Archived       *bool          `query:"archived"`
 	SearchTerm     *string        `query:"search"`
 	SortBy         string         `query:"sort_by" default:"name"`
 	SortOrder      string         `query:"sort_order" default:"asc"`
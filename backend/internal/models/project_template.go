@@ -0,0 +1,166 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxProjectTemplateTasks is the maximum number of tasks a project template
+// or a cloned project can contain
+const MaxProjectTemplateTasks = 2000
+
+// Project copy steps, in the order they are run
+const (
+	ProjectCopyStepProject        = "project"
+	ProjectCopyStepWorkflow       = "workflow"
+	ProjectCopyStepCustomFields   = "custom_fields"
+	ProjectCopyStepTasks          = "tasks"
+	ProjectCopyStepChecklistItems = "checklist_items"
+	ProjectCopyStepTaskLinks      = "task_links"
+)
+
+// ProjectTemplate represents a reusable outline of a project: its workflow
+// and tasks. Projects instantiated from a template get a copy of its tasks,
+// due relative to the start date of the project.
+type ProjectTemplate struct {
+	ID             uuid.UUID                `json:"id" db:"id"`
+	OrganizationID uuid.UUID                `json:"organization_id" db:"organization_id"`
+	Name           string                   `json:"name" db:"name"`
+	Description    string                   `json:"description" db:"description"`
+	Workflow       *ProjectTemplateWorkflow `json:"workflow,omitempty" db:"workflow"`
+	TaskCount      int                      `json:"task_count" db:"task_count"`
+	CreatedBy      uuid.UUID                `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time                `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at" db:"updated_at"`
+
+	// Related entities
+	Tasks []ProjectTemplateTask `json:"tasks,omitempty" db:"-"`
+}
+
+// ProjectTemplateTask represents a task of a project template. Tasks are
+// ordered so that parents come before their subtasks.
+type ProjectTemplateTask struct {
+	ID             uuid.UUID    `json:"id" db:"id"`
+	TemplateID     uuid.UUID    `json:"template_id" db:"template_id"`
+	ParentID       *uuid.UUID   `json:"parent_id,omitempty" db:"parent_id"`
+	Position       int          `json:"position" db:"position"`
+	Title          string       `json:"title" db:"title"`
+	Description    string       `json:"description" db:"description"`
+	Priority       TaskPriority `json:"priority" db:"priority"`
+	EstimatedHours *float64     `json:"estimated_hours,omitempty" db:"estimated_hours"`
+
+	// DueOffsetDays is when the task is due, in days after the start of the project
+	DueOffsetDays *int `json:"due_offset_days,omitempty" db:"due_offset_days"`
+
+	// AssigneeRole names who the task is assigned to when the template is
+	// instantiated, e.g. "designer"
+	AssigneeRole *string `json:"assignee_role,omitempty" db:"assignee_role"`
+
+	Tags      []string `json:"tags" db:"-"`
+	Checklist []string `json:"checklist" db:"-"`
+}
+
+// ProjectTemplateWorkflow is the workflow of a project template, stored as JSON
+type ProjectTemplateWorkflow struct {
+	WorkflowRequest
+}
+
+// Value implements driver.Valuer
+func (w *ProjectTemplateWorkflow) Value() (driver.Value, error) {
+	if w == nil {
+		return nil, nil
+	}
+	return json.Marshal(w)
+}
+
+// Scan implements sql.Scanner
+func (w *ProjectTemplateWorkflow) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, w)
+	case string:
+		return json.Unmarshal([]byte(src), w)
+	default:
+		return fmt.Errorf("cannot scan %T into ProjectTemplateWorkflow", src)
+	}
+}
+
+// ProjectTemplateRequest represents the data needed to save a project as a template
+type ProjectTemplateRequest struct {
+	Name        string `json:"name" validate:"required,min=3,max=255"`
+	Description string `json:"description" validate:"max=5000"`
+
+	// AssigneeRoles maps the users tasks are assigned to to the roles that
+	// stand in for them in the template. Tasks of other users are unassigned.
+	AssigneeRoles map[uuid.UUID]string `json:"assignee_roles,omitempty" validate:"dive,required,max=100"`
+}
+
+// NewProjectTemplate creates a new ProjectTemplate from a ProjectTemplateRequest
+func NewProjectTemplate(organizationID uuid.UUID, req ProjectTemplateRequest, createdBy uuid.UUID) *ProjectTemplate {
+	now := time.Now()
+	return &ProjectTemplate{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// ProjectInstantiateRequest represents the data needed to create a project from a template
+type ProjectInstantiateRequest struct {
+	Name        string        `json:"name" validate:"required,min=3,max=255"`
+	Description *string       `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Status      ProjectStatus `json:"status,omitempty" validate:"omitempty,oneof=planning active on_hold completed cancelled"`
+	StartDate   time.Time     `json:"start_date" validate:"required"`
+
+	// Assignees maps the roles of the template to the users their tasks are
+	// assigned to. Tasks of other roles are unassigned.
+	Assignees map[string]uuid.UUID `json:"assignees,omitempty"`
+}
+
+// ProjectCloneRequest represents the data needed to clone a project
+type ProjectCloneRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=255"`
+
+	// StartDate moves the clone: its dates and the due dates of its tasks are
+	// shifted by the time between the start dates of the projects
+	StartDate *time.Time `json:"start_date,omitempty"`
+}
+
+// ProjectCopyResult represents a project created from a template or a clone
+type ProjectCopyResult struct {
+	Project ProjectResponse   `json:"project"`
+	Report  ProjectCopyReport `json:"report"`
+}
+
+// ProjectCopyReport describes the steps taken to create a project from a
+// template or a clone
+type ProjectCopyReport struct {
+	Steps      []ProjectCopyStep `json:"steps"`
+	Warnings   []string          `json:"warnings"`
+	DurationMS int64             `json:"duration_ms"`
+}
+
+// ProjectCopyStep represents a completed step of a project copy
+type ProjectCopyStep struct {
+	Step       string `json:"step"`
+	Count      int    `json:"count"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Count returns the number of records copied by a step
+func (r *ProjectCopyReport) Count(step string) int {
+	for _, s := range r.Steps {
+		if s.Step == step {
+			return s.Count
+		}
+	}
+	return 0
+}
@@ -0,0 +1,596 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRecurrenceRule is returned when a recurrence rule cannot be parsed
+var ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+
+// RecurrenceFrequency represents how often a recurrence rule repeats
+type RecurrenceFrequency string
+
+// Recurrence frequencies
+const (
+	RecurrenceFrequencyDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceFrequencyWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceFrequencyMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+// maxRecurrencePeriods bounds the number of periods searched for the next
+// occurrence of a rule, so rules that can never match again terminate
+const maxRecurrencePeriods = 1000
+
+// recurrenceWeekdays maps RRULE weekday codes to weekdays
+var recurrenceWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceWeekday is a BYDAY entry of a recurrence rule. In monthly rules
+// a non-zero ordinal selects the nth weekday of the month, counting from the
+// end if negative.
+type RecurrenceWeekday struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// String returns the RRULE form of the weekday, such as MO or -1FR
+func (w RecurrenceWeekday) String() string {
+	code := strings.ToUpper(w.Weekday.String()[:2])
+	if w.Ordinal != 0 {
+		return strconv.Itoa(w.Ordinal) + code
+	}
+	return code
+}
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE: FREQ (DAILY,
+// WEEKLY or MONTHLY), INTERVAL, BYDAY, BYMONTHDAY, UNTIL, COUNT and WKST
+type RecurrenceRule struct {
+	Frequency  RecurrenceFrequency
+	Interval   int
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+	Count      int
+	WeekStart  time.Weekday
+
+	// Until is the last time an occurrence may start. A date-only UNTIL
+	// includes the whole day in the location of the rule's start.
+	Until       *time.Time
+	UntilIsDate bool
+}
+
+// ParseRecurrenceRule parses an RRULE value such as
+// "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=10". An "RRULE:" prefix is accepted.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: rule is empty", ErrInvalidRecurrenceRule)
+	}
+
+	rule := &RecurrenceRule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrenceRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is given more than once", ErrInvalidRecurrenceRule, name)
+		}
+		seen[name] = true
+
+		if err := rule.parsePart(name, val); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRecurrenceRule, err)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRecurrenceRule, err)
+	}
+
+	return rule, nil
+}
+
+// parsePart parses a NAME=VALUE part of a rule
+func (r *RecurrenceRule) parsePart(name, value string) error {
+	switch name {
+	case "FREQ":
+		switch RecurrenceFrequency(value) {
+		case RecurrenceFrequencyDaily, RecurrenceFrequencyWeekly, RecurrenceFrequencyMonthly:
+			r.Frequency = RecurrenceFrequency(value)
+		default:
+			return fmt.Errorf("unsupported frequency %q", value)
+		}
+
+	case "INTERVAL":
+		interval, err := strconv.Atoi(value)
+		if err != nil || interval < 1 || interval > 999 {
+			return fmt.Errorf("interval must be between 1 and 999")
+		}
+		r.Interval = interval
+
+	case "COUNT":
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return fmt.Errorf("count must be a positive number")
+		}
+		r.Count = count
+
+	case "UNTIL":
+		if until, err := time.Parse("20060102T150405Z", value); err == nil {
+			r.Until = &until
+		} else if until, err := time.Parse("20060102", value); err == nil {
+			r.Until = &until
+			r.UntilIsDate = true
+		} else {
+			return fmt.Errorf("until must be a date (YYYYMMDD) or a UTC time (YYYYMMDDTHHMMSSZ)")
+		}
+
+	case "BYDAY":
+		for _, entry := range strings.Split(value, ",") {
+			if len(entry) < 2 {
+				return fmt.Errorf("invalid weekday %q", entry)
+			}
+			weekday, ok := recurrenceWeekdays[entry[len(entry)-2:]]
+			if !ok {
+				return fmt.Errorf("invalid weekday %q", entry)
+			}
+			day := RecurrenceWeekday{Weekday: weekday}
+			if prefix := entry[:len(entry)-2]; prefix != "" {
+				ordinal, err := strconv.Atoi(prefix)
+				if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+					return fmt.Errorf("invalid weekday %q", entry)
+				}
+				day.Ordinal = ordinal
+			}
+			r.ByDay = append(r.ByDay, day)
+		}
+
+	case "BYMONTHDAY":
+		for _, entry := range strings.Split(value, ",") {
+			day, err := strconv.Atoi(entry)
+			if err != nil || day == 0 || day < -31 || day > 31 {
+				return fmt.Errorf("invalid month day %q", entry)
+			}
+			r.ByMonthDay = append(r.ByMonthDay, day)
+		}
+
+	case "WKST":
+		weekday, ok := recurrenceWeekdays[value]
+		if !ok {
+			return fmt.Errorf("invalid week start %q", value)
+		}
+		r.WeekStart = weekday
+
+	default:
+		return fmt.Errorf("unsupported part %s", name)
+	}
+
+	return nil
+}
+
+// validate checks the combination of parts of a rule
+func (r *RecurrenceRule) validate() error {
+	if r.Frequency == "" {
+		return errors.New("FREQ is required")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return errors.New("COUNT and UNTIL cannot both be given")
+	}
+	if r.Frequency == RecurrenceFrequencyWeekly && len(r.ByMonthDay) > 0 {
+		return errors.New("BYMONTHDAY cannot be used with a weekly frequency")
+	}
+	if r.Frequency != RecurrenceFrequencyMonthly {
+		for _, day := range r.ByDay {
+			if day.Ordinal != 0 {
+				return fmt.Errorf("numbered weekdays such as %s require a monthly frequency", day)
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the canonical RRULE form of the rule
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		if r.UntilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+RecurrenceWeekday{Weekday: r.WeekStart}.String())
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of the rule starting at start that is
+// strictly after after. Occurrences keep the wall-clock time of start in its
+// location. ok is false if the rule has no occurrence after after, because of
+// UNTIL or because no later date matches. COUNT is not applied: callers
+// count the occurrences they have handled.
+func (r *RecurrenceRule) Next(start, after time.Time) (time.Time, bool) {
+	loc := start.Location()
+	hour, minute, second := start.Clock()
+	startDate := civilDate(start)
+
+	// Skip the periods that end before after
+	period := 0
+	if after.After(start) {
+		period = r.period(startDate, civilDate(after.In(loc))) - 1
+		if period < 0 {
+			period = 0
+		}
+	}
+
+	for i := 0; i < maxRecurrencePeriods; i++ {
+		days, periodStart := r.days(start, startDate, period+i)
+
+		// A day of margin covers locations ahead of UTC
+		if r.Until != nil && periodStart.After(r.Until.AddDate(0, 0, 1)) {
+			return time.Time{}, false
+		}
+
+		for _, day := range days {
+			occurrence := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, loc)
+			if occurrence.Before(start) || !occurrence.After(after) {
+				continue
+			}
+			if r.pastUntil(occurrence, day) {
+				return time.Time{}, false
+			}
+			return occurrence, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Includes reports whether t is an occurrence of the rule starting at start
+func (r *RecurrenceRule) Includes(start, t time.Time) bool {
+	if t.Equal(start) {
+		return true
+	}
+	next, ok := r.Next(start, t.Add(-time.Nanosecond))
+	return ok && next.Equal(t)
+}
+
+// pastUntil reports whether an occurrence at t, on the civil date day, is
+// after the rule's UNTIL
+func (r *RecurrenceRule) pastUntil(t, day time.Time) bool {
+	if r.Until == nil {
+		return false
+	}
+	if r.UntilIsDate {
+		return day.After(*r.Until)
+	}
+	return t.After(*r.Until)
+}
+
+// period returns the index of the period of the rule that contains date
+func (r *RecurrenceRule) period(startDate, date time.Time) int {
+	switch r.Frequency {
+	case RecurrenceFrequencyWeekly:
+		weeks := daysBetween(weekStart(startDate, r.WeekStart), weekStart(date, r.WeekStart)) / 7
+		return weeks / r.Interval
+	case RecurrenceFrequencyMonthly:
+		months := (date.Year()-startDate.Year())*12 + int(date.Month()) - int(startDate.Month())
+		return months / r.Interval
+	}
+	return daysBetween(startDate, date) / r.Interval
+}
+
+// days returns the civil dates of a period of the rule in ascending order,
+// and the first date of the period
+func (r *RecurrenceRule) days(start, startDate time.Time, period int) ([]time.Time, time.Time) {
+	days := []time.Time{}
+
+	switch r.Frequency {
+	case RecurrenceFrequencyDaily:
+		day := startDate.AddDate(0, 0, period*r.Interval)
+		if r.matchesWeekday(day, start) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+		return days, day
+
+	case RecurrenceFrequencyWeekly:
+		first := weekStart(startDate, r.WeekStart).AddDate(0, 0, period*r.Interval*7)
+		for i := 0; i < 7; i++ {
+			day := first.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(day, start) {
+				days = append(days, day)
+			}
+		}
+		return days, first
+	}
+
+	first := time.Date(startDate.Year(), startDate.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && day.Day() != start.Day() {
+			continue
+		}
+		if r.matchesWeekday(day, start) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days, first
+}
+
+// matchesWeekday reports whether a date matches the rule's BYDAY, if any
+func (r *RecurrenceRule) matchesWeekday(day, start time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case byDay.Ordinal == 0:
+			return true
+		case byDay.Ordinal > 0 && (day.Day()-1)/7+1 == byDay.Ordinal:
+			return true
+		case byDay.Ordinal < 0 && (daysInMonth-day.Day())/7+1 == -byDay.Ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether a date matches the rule's BYMONTHDAY, if any
+func (r *RecurrenceRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || (monthDay < 0 && daysInMonth+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// civilDate returns the calendar date of t, as midnight UTC
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days from one civil date to another
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// weekStart returns the first day of the week containing a civil date
+func weekStart(date time.Time, first time.Weekday) time.Time {
+	offset := (int(date.Weekday()) - int(first) + 7) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+// RecurrenceMode represents when the next instance of a recurring task is
+// generated
+type RecurrenceMode string
+
+// Recurrence modes
+const (
+	// RecurrenceModeSchedule generates an instance for every occurrence of
+	// the rule, lead days before it is due
+	RecurrenceModeSchedule RecurrenceMode = "schedule"
+
+	// RecurrenceModeCompletion generates the next instance once the latest
+	// one is closed, due at the first occurrence after its completion
+	RecurrenceModeCompletion RecurrenceMode = "completion"
+)
+
+// TaskRecurrence repeats a task, its template, following a recurrence rule.
+// The template is the first occurrence; later instances copy its fields,
+// tags and checklist.
+type TaskRecurrence struct {
+	ID       uuid.UUID      `json:"id" db:"id"`
+	TaskID   uuid.UUID      `json:"task_id" db:"task_id"`
+	Rule     string         `json:"rule" db:"rule"`
+	Mode     RecurrenceMode `json:"mode" db:"mode"`
+	StartsAt time.Time      `json:"starts_at" db:"starts_at"`
+	Timezone string         `json:"timezone" db:"timezone"`
+	LeadDays int            `json:"lead_days" db:"lead_days"`
+
+	// NextOccurrence is the next occurrence to generate on a schedule. It is
+	// unset for recurrences on completion and for finished recurrences.
+	NextOccurrence *time.Time `json:"next_occurrence,omitempty" db:"next_occurrence"`
+
+	// OccurrenceCount is the number of occurrences handled so far, including
+	// the template and skipped occurrences
+	OccurrenceCount int        `json:"occurrence_count" db:"occurrence_count"`
+	LastTaskID      *uuid.UUID `json:"last_task_id,omitempty" db:"last_task_id"`
+	Finished        bool       `json:"finished" db:"finished"`
+	CreatedBy       uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TaskRecurrenceRequest represents the data needed to make a task recur
+type TaskRecurrenceRequest struct {
+	Rule string         `json:"rule" validate:"required,max=500"`
+	Mode RecurrenceMode `json:"mode,omitempty" validate:"omitempty,oneof=schedule completion"`
+
+	// StartsAt is the first occurrence. It defaults to the task's due date.
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	Timezone string     `json:"timezone,omitempty" validate:"max=64"`
+	LeadDays int        `json:"lead_days" validate:"min=0,max=365"`
+}
+
+// TaskRecurrenceResponse represents the recurrence data returned to clients
+type TaskRecurrenceResponse struct {
+	TaskRecurrence
+
+	// Upcoming lists the next occurrences that will be generated on a schedule
+	Upcoming []time.Time `json:"upcoming"`
+
+	// Skipped lists the future occurrences that will not be generated
+	Skipped []time.Time `json:"skipped"`
+}
+
+// SkipOccurrenceRequest represents the data needed to skip an occurrence
+type SkipOccurrenceRequest struct {
+	OccursAt time.Time `json:"occurs_at" validate:"required"`
+}
+
+// NewTaskRecurrence creates a new TaskRecurrence for a task. The template
+// counts as the first occurrence.
+func NewTaskRecurrence(taskID uuid.UUID, req TaskRecurrenceRequest, startsAt time.Time, createdBy uuid.UUID) *TaskRecurrence {
+	now := time.Now()
+	recurrence := &TaskRecurrence{
+		ID:              uuid.New(),
+		TaskID:          taskID,
+		OccurrenceCount: 1,
+		LastTaskID:      &taskID,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	recurrence.Apply(req, startsAt)
+	return recurrence
+}
+
+// Apply sets the rule, mode, start, timezone and lead days of a recurrence
+// from a request
+func (r *TaskRecurrence) Apply(req TaskRecurrenceRequest, startsAt time.Time) {
+	r.Rule = req.Rule
+	r.Mode = req.Mode
+	if r.Mode == "" {
+		r.Mode = RecurrenceModeSchedule
+	}
+	r.StartsAt = startsAt.Truncate(time.Second)
+	r.Timezone = req.Timezone
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+	r.LeadDays = req.LeadDays
+}
+
+// Location returns the location occurrences are computed in
+func (r *TaskRecurrence) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Following returns the first occurrence of rule after after, given the
+// occurrences handled so far, or nil if the recurrence has ended
+func (r *TaskRecurrence) Following(rule *RecurrenceRule, after time.Time) *time.Time {
+	return r.following(rule, r.StartsAt, after)
+}
+
+// FollowingCompletion returns the first occurrence after a completion time
+// for a recurrence on completion. The rule is restarted on the day of the
+// completion, at the time of day of the recurrence's start, and that day
+// counts as the completed occurrence.
+func (r *TaskRecurrence) FollowingCompletion(rule *RecurrenceRule, completedAt time.Time) *time.Time {
+	loc := r.Location()
+	start := r.StartsAt.In(loc)
+	day := completedAt.In(loc)
+	anchor := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+	if anchor.Before(start) {
+		anchor = start
+	}
+
+	after := completedAt
+	if anchor.After(after) {
+		after = anchor
+	}
+	return r.following(rule, anchor, after)
+}
+
+// following returns the first occurrence of rule starting at start after
+// after, or nil if the rule's COUNT is reached or it has no more occurrences
+func (r *TaskRecurrence) following(rule *RecurrenceRule, start, after time.Time) *time.Time {
+	if rule.Count > 0 && r.OccurrenceCount >= rule.Count {
+		return nil
+	}
+	next, ok := rule.Next(start.In(r.Location()), after)
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+// GenerateAt returns when the instance of an occurrence is generated: lead
+// days before it, in the recurrence's location
+func (r *TaskRecurrence) GenerateAt(occursAt time.Time) time.Time {
+	return occursAt.In(r.Location()).AddDate(0, 0, -r.LeadDays)
+}
+
+// TaskOccurrenceStatus represents how an occurrence of a recurrence was handled
+type TaskOccurrenceStatus string
+
+// Task occurrence statuses
+const (
+	TaskOccurrenceStatusGenerated TaskOccurrenceStatus = "generated"
+	TaskOccurrenceStatusSkipped   TaskOccurrenceStatus = "skipped"
+)
+
+// TaskOccurrence records a handled occurrence of a recurrence and the task
+// generated for it. The task is unset for skipped occurrences and for
+// generated tasks that were deleted.
+type TaskOccurrence struct {
+	RecurrenceID uuid.UUID            `json:"recurrence_id" db:"recurrence_id"`
+	OccursAt     time.Time            `json:"occurs_at" db:"occurs_at"`
+	TaskID       *uuid.UUID           `json:"task_id,omitempty" db:"task_id"`
+	Status       TaskOccurrenceStatus `json:"status" db:"status"`
+	CreatedAt    time.Time            `json:"created_at" db:"created_at"`
+}
+
+// NewTaskOccurrence creates a new TaskOccurrence
+func NewTaskOccurrence(recurrenceID uuid.UUID, occursAt time.Time, taskID *uuid.UUID, status TaskOccurrenceStatus) *TaskOccurrence {
+	return &TaskOccurrence{
+		RecurrenceID: recurrenceID,
+		OccursAt:     occursAt,
+		TaskID:       taskID,
+		Status:       status,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedFilter represents a named task filter expression. It is visible to
+// its owner or, when shared, to every member of the organization.
+type SavedFilter struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	OrganizationID uuid.UUID `json:"organization_id" db:"organization_id"`
+	OwnerID        uuid.UUID `json:"owner_id" db:"owner_id"`
+	Name           string    `json:"name" db:"name"`
+	Query          string    `json:"query" db:"query"`
+	Shared         bool      `json:"shared" db:"shared"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SavedFilterRequest represents the data needed to create or update a saved filter
+type SavedFilterRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Query  string `json:"query" validate:"required,max=2000"`
+	Shared bool   `json:"shared"`
+}
+
+// NewSavedFilter creates a new SavedFilter from a SavedFilterRequest
+func NewSavedFilter(organizationID, ownerID uuid.UUID, req SavedFilterRequest) *SavedFilter {
+	now := time.Now()
+	return &SavedFilter{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		OwnerID:        ownerID,
+		Name:           req.Name,
+		Query:          req.Query,
+		Shared:         req.Shared,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
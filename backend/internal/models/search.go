@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchResultType represents the kind of a search result
+type SearchResultType string
+
+// Search result types
+const (
+	SearchResultTypeTask    SearchResultType = "task"
+	SearchResultTypeProject SearchResultType = "project"
+	SearchResultTypeComment SearchResultType = "comment"
+)
+
+// SearchResult represents a ranked match of a search
+type SearchResult struct {
+	Type SearchResultType `json:"type" db:"type"`
+	ID   uuid.UUID        `json:"id" db:"id"`
+
+	// ProjectID is the project of a task or of a comment's task, or the
+	// project itself
+	ProjectID *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+
+	// TaskID is the task a comment belongs to
+	TaskID *uuid.UUID `json:"task_id,omitempty" db:"task_id"`
+
+	// Title is the title of a task or of a comment's task, or the name of a project
+	Title string `json:"title" db:"title"`
+
+	// Snippet is an excerpt of the matching text with matches wrapped in
+	// <mark> tags
+	Snippet   string    `json:"snippet" db:"snippet"`
+	Rank      float64   `json:"rank" db:"rank"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SearchParams represents the parameters for a search
+type SearchParams struct {
+	Query          string             `query:"q"`
+	Types          []SearchResultType `query:"-"`
+	OrganizationID uuid.UUID          `query:"-"`
+	UserID         uuid.UUID          `query:"-"`
+	Page           int                `query:"page" default:"1"`
+	PageSize       int                `query:"page_size" default:"20"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SprintState represents the state of a sprint
+type SprintState string
+
+// Sprint states. Sprints are planned, then started and finally closed.
+const (
+	SprintStatePlanned SprintState = "planned"
+	SprintStateActive  SprintState = "active"
+	SprintStateClosed  SprintState = "closed"
+)
+
+// Sprint represents a time box of a project's work. Tasks are planned into a
+// sprint; tasks without a sprint are in the project's backlog.
+type Sprint struct {
+	ID        uuid.UUID   `json:"id" db:"id"`
+	ProjectID uuid.UUID   `json:"project_id" db:"project_id"`
+	Name      string      `json:"name" db:"name"`
+	Goal      string      `json:"goal" db:"goal"`
+	State     SprintState `json:"state" db:"state"`
+	StartDate *time.Time  `json:"start_date,omitempty" db:"start_date"`
+	EndDate   *time.Time  `json:"end_date,omitempty" db:"end_date"`
+	StartedAt *time.Time  `json:"started_at,omitempty" db:"started_at"`
+	ClosedAt  *time.Time  `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedBy uuid.UUID   `json:"created_by" db:"created_by"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+
+	// TaskCount is the number of tasks currently in the sprint
+	TaskCount int `json:"task_count" db:"task_count"`
+
+	// CompletedTasks and CarriedOverTasks record, when the sprint was closed,
+	// the tasks that were done and those moved on because they were not
+	CompletedTasks   int `json:"completed_tasks" db:"completed_tasks"`
+	CarriedOverTasks int `json:"carried_over_tasks" db:"carried_over_tasks"`
+}
+
+// SprintRequest represents the data needed to create or update a sprint
+type SprintRequest struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	Goal      string     `json:"goal" validate:"max=5000"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// SprintCloseRequest represents the data needed to close a sprint
+type SprintCloseRequest struct {
+	// CarryOverTo is the planned sprint unfinished tasks move to. Without it
+	// they return to the backlog.
+	CarryOverTo *uuid.UUID `json:"carry_over_to,omitempty"`
+}
+
+// SprintTasksRequest represents the tasks to plan into a sprint
+type SprintTasksRequest struct {
+	TaskIDs []uuid.UUID `json:"task_ids" validate:"required,min=1,max=500"`
+}
+
+// NewSprint creates a new planned Sprint for a project from a SprintRequest
+func NewSprint(projectID uuid.UUID, req SprintRequest, createdBy uuid.UUID) *Sprint {
+	now := time.Now()
+	return &Sprint{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      req.Name,
+		Goal:      req.Goal,
+		State:     SprintStatePlanned,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -33,16 +33,22 @@ const (
 type Task struct {
 	ID             uuid.UUID    `json:"id" db:"id"`
 	ProjectID      *uuid.UUID   `json:"project_id,omitempty" db:"project_id"`
+	ParentID       *uuid.UUID   `json:"parent_id,omitempty" db:"parent_id"`
+	SprintID       *uuid.UUID   `json:"sprint_id,omitempty" db:"sprint_id"`
+	Rank           string       `json:"rank" db:"rank"`
 	Title          string       `json:"title" db:"title"`
 	Description    string       `json:"description" db:"description"`
 	Status         TaskStatus   `json:"status" db:"status"`
 	Priority       TaskPriority `json:"priority" db:"priority"`
 	DueDate        *time.Time   `json:"due_date,omitempty" db:"due_date"`
+	StartDate      *time.Time   `json:"start_date,omitempty" db:"start_date"`
+	DurationDays   *int         `json:"duration_days,omitempty" db:"duration_days"`
 	CreatedBy      uuid.UUID    `json:"created_by" db:"created_by"`
 	AssignedTo     *uuid.UUID   `json:"assigned_to,omitempty" db:"assigned_to"`
 	EstimatedHours *float64     `json:"estimated_hours,omitempty" db:"estimated_hours"`
 	ActualHours    *float64     `json:"actual_hours,omitempty" db:"actual_hours"`
 	Tags           []string     `json:"tags" db:"-"`
+	CustomFields   CustomFieldValues `json:"custom_fields,omitempty" db:"custom_fields"`
 	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
 	
@@ -50,35 +56,47 @@ type Task struct {
 	Project        *Project     `json:"project,omitempty" db:"-"`
 	Creator        *User        `json:"creator,omitempty" db:"-"`
 	Assignee       *User        `json:"assignee,omitempty" db:"-"`
+	ChecklistItems []ChecklistItem `json:"checklist_items,omitempty" db:"-"`
+	Rollup         *TaskRollup  `json:"rollup,omitempty" db:"-"`
 }
 
 // TaskRequest represents the data needed to create or update a task
 type TaskRequest struct {
 	ProjectID      *uuid.UUID   `json:"project_id,omitempty" validate:"omitempty,uuid4"`
+	ParentID       *uuid.UUID   `json:"parent_id,omitempty" validate:"omitempty,uuid4"`
 	Title          string       `json:"title" validate:"required,min=3,max=255"`
 	Description    string       `json:"description" validate:"max=5000"`
-	Status         TaskStatus   `json:"status" validate:"required,oneof=todo in_progress review done cancelled"`
+	Status         TaskStatus   `json:"status,omitempty" validate:"omitempty,max=50"`
 	Priority       TaskPriority `json:"priority" validate:"required,oneof=low medium high critical"`
 	DueDate        *time.Time   `json:"due_date,omitempty"`
+	StartDate      *time.Time   `json:"start_date,omitempty"`
+	DurationDays   *int         `json:"duration_days,omitempty" validate:"omitempty,min=0,max=3650"`
 	AssignedTo     *uuid.UUID   `json:"assigned_to,omitempty" validate:"omitempty,uuid4"`
 	EstimatedHours *float64     `json:"estimated_hours,omitempty" validate:"omitempty,min=0"`
 	Tags           []string     `json:"tags,omitempty" validate:"dive,max=50"`
+	CustomFields   map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // TaskResponse represents the task data returned to clients
 type TaskResponse struct {
 	ID             uuid.UUID    `json:"id"`
 	ProjectID      *uuid.UUID   `json:"project_id,omitempty"`
+	ParentID       *uuid.UUID   `json:"parent_id,omitempty"`
+	SprintID       *uuid.UUID   `json:"sprint_id,omitempty"`
+	Rank           string       `json:"rank"`
 	Title          string       `json:"title"`
 	Description    string       `json:"description"`
 	Status         TaskStatus   `json:"status"`
 	Priority       TaskPriority `json:"priority"`
 	DueDate        *time.Time   `json:"due_date,omitempty"`
+	StartDate      *time.Time   `json:"start_date,omitempty"`
+	DurationDays   *int         `json:"duration_days,omitempty"`
 	CreatedBy      uuid.UUID    `json:"created_by"`
 	AssignedTo     *uuid.UUID   `json:"assigned_to,omitempty"`
 	EstimatedHours *float64     `json:"estimated_hours,omitempty"`
 	ActualHours    *float64     `json:"actual_hours,omitempty"`
 	Tags           []string     `json:"tags"`
+	CustomFields   CustomFieldValues `json:"custom_fields,omitempty"`
 	CreatedAt      time.Time    `json:"created_at"`
 	UpdatedAt      time.Time    `json:"updated_at"`
 	
@@ -86,6 +104,18 @@ type TaskResponse struct {
 	Project        *ProjectResponse `json:"project,omitempty"`
 	Creator        *UserResponse    `json:"creator,omitempty"`
 	Assignee       *UserResponse    `json:"assignee,omitempty"`
+	ChecklistItems []ChecklistItemResponse `json:"checklist_items,omitempty"`
+	Rollup         *TaskRollup      `json:"rollup,omitempty"`
+}
+
+// HasTag reports whether tags contains tag
+func HasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // ToResponse converts a Task to a TaskResponse
@@ -93,18 +123,25 @@ func (t *Task) ToResponse() TaskResponse {
 	response := TaskResponse{
 		ID:             t.ID,
 		ProjectID:      t.ProjectID,
+		ParentID:       t.ParentID,
+		SprintID:       t.SprintID,
+		Rank:           t.Rank,
 		Title:          t.Title,
 		Description:    t.Description,
 		Status:         t.Status,
 		Priority:       t.Priority,
 		DueDate:        t.DueDate,
+		StartDate:      t.StartDate,
+		DurationDays:   t.DurationDays,
 		CreatedBy:      t.CreatedBy,
 		AssignedTo:     t.AssignedTo,
 		EstimatedHours: t.EstimatedHours,
 		ActualHours:    t.ActualHours,
 		Tags:           t.Tags,
+		CustomFields:   t.CustomFields,
 		CreatedAt:      t.CreatedAt,
 		UpdatedAt:      t.UpdatedAt,
+		Rollup:         t.Rollup,
 	}
 	
 	if t.Project != nil {
@@ -122,6 +159,13 @@ func (t *Task) ToResponse() TaskResponse {
 		response.Assignee = &assigneeResponse
 	}
 	
+	if t.ChecklistItems != nil {
+		response.ChecklistItems = make([]ChecklistItemResponse, 0, len(t.ChecklistItems))
+		for _, item := range t.ChecklistItems {
+			response.ChecklistItems = append(response.ChecklistItems, item.ToResponse())
+		}
+	}
+	
 	return response
 }
 
@@ -131,11 +175,14 @@ func NewTask(req TaskRequest, createdBy uuid.UUID) *Task {
 	return &Task{
 		ID:             uuid.New(),
 		ProjectID:      req.ProjectID,
+		ParentID:       req.ParentID,
 		Title:          req.Title,
 		Description:    req.Description,
 		Status:         req.Status,
 		Priority:       req.Priority,
 		DueDate:        req.DueDate,
+		StartDate:      req.StartDate,
+		DurationDays:   req.DurationDays,
 		CreatedBy:      createdBy,
 		AssignedTo:     req.AssignedTo,
 		EstimatedHours: req.EstimatedHours,
@@ -148,6 +195,8 @@ func NewTask(req TaskRequest, createdBy uuid.UUID) *Task {
 // TaskListParams represents the parameters for listing tasks
 type TaskListParams struct {
 	ProjectID  *uuid.UUID   `query:"project_id"`
+	ParentID   *uuid.UUID   `query:"parent_id"`
+	TopLevel   bool         `query:"-"` // only tasks without a parent
 	Status     *TaskStatus  `query:"status"`
 	Priority   *TaskPriority `query:"priority"`
 	CreatedBy  *uuid.UUID   `query:"created_by"`
@@ -155,6 +204,12 @@ type TaskListParams struct {
 	DueBefore  *time.Time   `query:"due_before"`
 	DueAfter   *time.Time   `query:"due_after"`
 	SearchTerm *string      `query:"search"`
+	OrganizationID *uuid.UUID `query:"-"` // scopes custom fields of tasks without a project
+	ScopeOrganizationID *uuid.UUID `query:"-"` // only tasks of the organization's projects, or without a project and created by CurrentUserID
+	CustomFieldFilters []CustomFieldFilter `query:"-"` // cf.<key>, cf.<key>.gte, cf.<key>.lte
+	CustomFieldSort *CustomFieldSort `query:"-"` // sort_by=cf.<key>
+	Filter     *TaskFilter  `query:"-"` // filter expression, see ParseTaskFilter
+	CurrentUserID *uuid.UUID `query:"-"` // resolves "me" in Filter
 	SortBy     string       `query:"sort_by" default:"created_at"`
 	SortOrder  string       `query:"sort_order" default:"desc"`
 	Page       int          `query:"page" default:"1"`
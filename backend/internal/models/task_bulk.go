@@ -0,0 +1,113 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkTasks is the maximum number of tasks a bulk operation applies to
+const MaxBulkTasks = 500
+
+// BulkTaskAction represents what a bulk operation does to its tasks
+type BulkTaskAction string
+
+// Bulk task actions
+const (
+	BulkTaskActionUpdate BulkTaskAction = "update"
+	BulkTaskActionDelete BulkTaskAction = "delete"
+)
+
+// BulkTaskMode represents how a bulk operation handles failures
+type BulkTaskMode string
+
+// Bulk task modes
+const (
+	// BulkTaskModeAtomic applies the operation to all of the tasks or none
+	BulkTaskModeAtomic BulkTaskMode = "atomic"
+
+	// BulkTaskModeBestEffort applies the operation to every task it can
+	BulkTaskModeBestEffort BulkTaskMode = "best_effort"
+)
+
+// BulkTaskChanges represents the changes a bulk update makes to each task.
+// Unset fields are left as they are.
+type BulkTaskChanges struct {
+	Status       *TaskStatus   `json:"status,omitempty" validate:"omitempty,max=50"`
+	Priority     *TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high critical"`
+	AssignedTo   *uuid.UUID    `json:"assigned_to,omitempty"`
+	Unassign     bool          `json:"unassign,omitempty"`
+	ProjectID    *uuid.UUID    `json:"project_id,omitempty"`
+	DueDate      *time.Time    `json:"due_date,omitempty"`
+	ClearDueDate bool          `json:"clear_due_date,omitempty"`
+	AddTags      []string      `json:"add_tags,omitempty" validate:"dive,max=50"`
+	RemoveTags   []string      `json:"remove_tags,omitempty" validate:"dive,max=50"`
+}
+
+// IsEmpty reports whether the changes leave tasks as they are
+func (c *BulkTaskChanges) IsEmpty() bool {
+	return c.Status == nil && c.Priority == nil && c.AssignedTo == nil && !c.Unassign &&
+		c.ProjectID == nil && c.DueDate == nil && !c.ClearDueDate &&
+		len(c.AddTags) == 0 && len(c.RemoveTags) == 0
+}
+
+// BulkTaskRequest represents a bulk operation on the tasks with the given IDs
+// or matching a filter expression
+type BulkTaskRequest struct {
+	TaskIDs []uuid.UUID `json:"task_ids,omitempty" validate:"max=500"`
+	Filter  string      `json:"filter,omitempty"`
+
+	// ProjectID scopes the tasks matching Filter to a project
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+
+	Action  BulkTaskAction   `json:"action" validate:"required,oneof=update delete"`
+	Mode    BulkTaskMode     `json:"mode,omitempty" validate:"omitempty,oneof=atomic best_effort"`
+	Changes *BulkTaskChanges `json:"changes,omitempty"`
+
+	// Policy is the subtask delete policy of a bulk delete
+	Policy string `json:"policy,omitempty" validate:"omitempty,oneof=reparent cascade"`
+
+	// Force closes tasks even if they are blocked
+	Force bool `json:"force,omitempty"`
+
+	// OrganizationID is where permissions on tasks without a project are
+	// checked
+	OrganizationID *uuid.UUID `json:"-"`
+}
+
+// BulkTaskItemStatus represents the outcome of a bulk operation for a task
+type BulkTaskItemStatus string
+
+// Bulk task item statuses
+const (
+	BulkTaskItemStatusUpdated   BulkTaskItemStatus = "updated"
+	BulkTaskItemStatusUnchanged BulkTaskItemStatus = "unchanged"
+	BulkTaskItemStatusDeleted   BulkTaskItemStatus = "deleted"
+	BulkTaskItemStatusFailed    BulkTaskItemStatus = "failed"
+
+	// BulkTaskItemStatusRolledBack is a task whose change was undone because
+	// another task failed in an atomic operation
+	BulkTaskItemStatusRolledBack BulkTaskItemStatus = "rolled_back"
+
+	// BulkTaskItemStatusSkipped is a task left untouched because another task
+	// failed first in an atomic operation
+	BulkTaskItemStatusSkipped BulkTaskItemStatus = "skipped"
+)
+
+// BulkTaskItemResult represents the outcome of a bulk operation for a task
+type BulkTaskItemResult struct {
+	TaskID uuid.UUID          `json:"task_id"`
+	Status BulkTaskItemStatus `json:"status"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// BulkTaskResponse represents the outcome of a bulk operation
+type BulkTaskResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	Action    BulkTaskAction       `json:"action"`
+	Mode      BulkTaskMode         `json:"mode"`
+	Committed bool                 `json:"committed"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BulkTaskItemResult `json:"results"`
+}
@@ -0,0 +1,594 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTaskFilter is returned when a task filter expression cannot be parsed
+var ErrInvalidTaskFilter = errors.New("invalid task filter")
+
+// TaskFilterError describes where and why a task filter expression is invalid
+type TaskFilterError struct {
+	Pos    int
+	Reason string
+}
+
+// Error implements the error interface
+func (e *TaskFilterError) Error() string {
+	return fmt.Sprintf("%s at position %d: %s", ErrInvalidTaskFilter, e.Pos, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidTaskFilter
+func (e *TaskFilterError) Is(target error) bool {
+	return target == ErrInvalidTaskFilter
+}
+
+// TaskFilterOp combines task filters
+type TaskFilterOp string
+
+// Task filter combinations
+const (
+	TaskFilterOpAnd TaskFilterOp = "and"
+	TaskFilterOpOr  TaskFilterOp = "or"
+	TaskFilterOpNot TaskFilterOp = "not"
+)
+
+// TaskFilterField is a task field that can be filtered on
+type TaskFilterField string
+
+// Task filter fields
+const (
+	TaskFilterFieldStatus   TaskFilterField = "status"
+	TaskFilterFieldPriority TaskFilterField = "priority"
+	TaskFilterFieldAssignee TaskFilterField = "assignee"
+	TaskFilterFieldCreator  TaskFilterField = "creator"
+	TaskFilterFieldProject  TaskFilterField = "project"
+	TaskFilterFieldTag      TaskFilterField = "tag"
+	TaskFilterFieldDue      TaskFilterField = "due"
+	TaskFilterFieldCreated  TaskFilterField = "created"
+	TaskFilterFieldUpdated  TaskFilterField = "updated"
+	TaskFilterFieldText     TaskFilterField = "text"
+	TaskFilterFieldIs       TaskFilterField = "is"
+)
+
+// TaskFilterOperator compares a task field to values
+type TaskFilterOperator string
+
+// Task filter operators
+const (
+	TaskFilterOperatorEq    TaskFilterOperator = "="
+	TaskFilterOperatorNeq   TaskFilterOperator = "!="
+	TaskFilterOperatorLt    TaskFilterOperator = "<"
+	TaskFilterOperatorLte   TaskFilterOperator = "<="
+	TaskFilterOperatorGt    TaskFilterOperator = ">"
+	TaskFilterOperatorGte   TaskFilterOperator = ">="
+	TaskFilterOperatorIn    TaskFilterOperator = "in"
+	TaskFilterOperatorNotIn TaskFilterOperator = "not in"
+)
+
+// Special task filter values
+const (
+	// TaskFilterValueMe is the user running the filter
+	TaskFilterValueMe = "me"
+
+	// TaskFilterValueNone matches an unset field
+	TaskFilterValueNone = "none"
+
+	// TaskFilterValueOpen and TaskFilterValueClosed match tasks by the
+	// category of their workflow state, e.g. is:open
+	TaskFilterValueOpen   = "open"
+	TaskFilterValueClosed = "closed"
+)
+
+// MaxTaskFilterConditions limits the size of a task filter expression
+const MaxTaskFilterConditions = 50
+
+// taskFilterKind groups fields by the operators and values they accept
+type taskFilterKind int
+
+const (
+	taskFilterKindString taskFilterKind = iota
+	taskFilterKindPriority
+	taskFilterKindUser
+	taskFilterKindUUID
+	taskFilterKindDate
+	taskFilterKindText
+	taskFilterKindIs
+)
+
+// taskFilterFields maps field names, including aliases, to their field and kind
+var taskFilterFields = map[string]struct {
+	field TaskFilterField
+	kind  taskFilterKind
+}{
+	"status":      {TaskFilterFieldStatus, taskFilterKindString},
+	"priority":    {TaskFilterFieldPriority, taskFilterKindPriority},
+	"assignee":    {TaskFilterFieldAssignee, taskFilterKindUser},
+	"assigned_to": {TaskFilterFieldAssignee, taskFilterKindUser},
+	"creator":     {TaskFilterFieldCreator, taskFilterKindUser},
+	"created_by":  {TaskFilterFieldCreator, taskFilterKindUser},
+	"project":     {TaskFilterFieldProject, taskFilterKindUUID},
+	"project_id":  {TaskFilterFieldProject, taskFilterKindUUID},
+	"tag":         {TaskFilterFieldTag, taskFilterKindString},
+	"tags":        {TaskFilterFieldTag, taskFilterKindString},
+	"due":         {TaskFilterFieldDue, taskFilterKindDate},
+	"due_date":    {TaskFilterFieldDue, taskFilterKindDate},
+	"created":     {TaskFilterFieldCreated, taskFilterKindDate},
+	"created_at":  {TaskFilterFieldCreated, taskFilterKindDate},
+	"updated":     {TaskFilterFieldUpdated, taskFilterKindDate},
+	"updated_at":  {TaskFilterFieldUpdated, taskFilterKindDate},
+	"text":        {TaskFilterFieldText, taskFilterKindText},
+	"is":          {TaskFilterFieldIs, taskFilterKindIs},
+}
+
+// TaskPriorityRank orders task priorities from low to critical
+var TaskPriorityRank = map[TaskPriority]int{
+	TaskPriorityLow:      1,
+	TaskPriorityMedium:   2,
+	TaskPriorityHigh:     3,
+	TaskPriorityCritical: 4,
+}
+
+// TaskFilter is a parsed task filter expression: either a condition on a
+// task field, or a combination of filters
+type TaskFilter struct {
+	// Op combines the operands; it is empty for a condition
+	Op       TaskFilterOp
+	Operands []*TaskFilter
+
+	Condition *TaskFilterCondition
+}
+
+// TaskFilterCondition compares a task field to one or more values
+type TaskFilterCondition struct {
+	Field    TaskFilterField
+	Operator TaskFilterOperator
+	Values   []string
+}
+
+// AndTaskFilters combines filters so that tasks must match all of them. Nil
+// filters are ignored.
+func AndTaskFilters(filters ...*TaskFilter) *TaskFilter {
+	operands := []*TaskFilter{}
+	for _, filter := range filters {
+		if filter != nil {
+			operands = append(operands, filter)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	}
+	return &TaskFilter{Op: TaskFilterOpAnd, Operands: operands}
+}
+
+// ParseTaskFilter parses a task filter expression, e.g.
+//
+//	status in (todo,review) and priority>=high and tag:backend and due<7d
+//
+// Conditions are combined with and, or, not and parentheses; and binds
+// tighter than or. Keywords and field names are case-insensitive.
+func ParseTaskFilter(expr string) (*TaskFilter, error) {
+	tokens, err := lexTaskFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &taskFilterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != taskFilterTokenEOF {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+
+	return filter, nil
+}
+
+// ResolveTaskFilterDate resolves a date value of a task filter. Dates
+// (YYYY-MM-DD) and today resolve to the start of the day in UTC and are
+// reported as whole days; now, RFC 3339 times and offsets from now such as
+// 7d, -12h or 2w resolve to an instant.
+func ResolveTaskFilterDate(value string, now time.Time) (time.Time, bool, error) {
+	switch strings.ToLower(value) {
+	case "now":
+		return now, false, nil
+	case "today":
+		now = now.UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), true, nil
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, true, nil
+	}
+	if instant, err := time.Parse(time.RFC3339, value); err == nil {
+		return instant, false, nil
+	}
+
+	// Offsets from now
+	if len(value) >= 2 {
+		amount, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil {
+			switch value[len(value)-1] {
+			case 'h':
+				return now.Add(time.Duration(amount) * time.Hour), false, nil
+			case 'd':
+				return now.AddDate(0, 0, amount), false, nil
+			case 'w':
+				return now.AddDate(0, 0, 7*amount), false, nil
+			}
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("%q is not a date", value)
+}
+
+// taskFilterTokenKind is the kind of a task filter token
+type taskFilterTokenKind int
+
+const (
+	taskFilterTokenEOF taskFilterTokenKind = iota
+	taskFilterTokenWord
+	taskFilterTokenString
+	taskFilterTokenOperator
+	taskFilterTokenLParen
+	taskFilterTokenRParen
+	taskFilterTokenComma
+)
+
+// taskFilterToken is a token of a task filter expression
+type taskFilterToken struct {
+	kind taskFilterTokenKind
+	text string
+	pos  int
+}
+
+// isTaskFilterWordRune reports whether r can be part of a bare word
+func isTaskFilterWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.+", r)
+}
+
+// lexTaskFilter splits a task filter expression into tokens
+func lexTaskFilter(expr string) ([]taskFilterToken, error) {
+	runes := []rune(expr)
+	tokens := []taskFilterToken{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenComma, text: ",", pos: i})
+			i++
+		case r == ':' || r == '=':
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenOperator, text: string(TaskFilterOperatorEq), pos: i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return nil, &TaskFilterError{Pos: start, Reason: `expected "!="`}
+			}
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenOperator, text: text, pos: start})
+		case r == '"':
+			start := i
+			i++
+			var value strings.Builder
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &TaskFilterError{Pos: start, Reason: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenString, text: value.String(), pos: start})
+		case isTaskFilterWordRune(r):
+			start := i
+			for i < len(runes) && isTaskFilterWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, taskFilterToken{kind: taskFilterTokenWord, text: string(runes[start:i]), pos: start})
+		default:
+			return nil, &TaskFilterError{Pos: i, Reason: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, taskFilterToken{kind: taskFilterTokenEOF, pos: len(runes)}), nil
+}
+
+// taskFilterParser is a recursive descent parser of task filter expressions
+type taskFilterParser struct {
+	tokens     []taskFilterToken
+	pos        int
+	conditions int
+}
+
+// peek returns the next token
+func (p *taskFilterParser) peek() taskFilterToken {
+	return p.tokens[p.pos]
+}
+
+// next consumes the next token
+func (p *taskFilterParser) next() taskFilterToken {
+	token := p.tokens[p.pos]
+	if token.kind != taskFilterTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// keyword consumes the next token if it is the given keyword
+func (p *taskFilterParser) keyword(keyword string) bool {
+	token := p.peek()
+	if token.kind == taskFilterTokenWord && strings.EqualFold(token.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// errorf returns an error at the position of the next token
+func (p *taskFilterParser) errorf(format string, args ...interface{}) error {
+	return &TaskFilterError{Pos: p.peek().pos, Reason: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses filters combined with or
+func (p *taskFilterParser) parseOr() (*TaskFilter, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*TaskFilter{filter}
+	for p.keyword("or") {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &TaskFilter{Op: TaskFilterOpOr, Operands: operands}, nil
+}
+
+// parseAnd parses filters combined with and
+func (p *taskFilterParser) parseAnd() (*TaskFilter, error) {
+	filter, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*TaskFilter{filter}
+	for p.keyword("and") {
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &TaskFilter{Op: TaskFilterOpAnd, Operands: operands}, nil
+}
+
+// parseUnary parses a negated filter, a parenthesized filter or a condition
+func (p *taskFilterParser) parseUnary() (*TaskFilter, error) {
+	if p.keyword("not") {
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &TaskFilter{Op: TaskFilterOpNot, Operands: []*TaskFilter{filter}}, nil
+	}
+
+	if p.peek().kind == taskFilterTokenLParen {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != taskFilterTokenRParen {
+			return nil, p.errorf(`expected ")"`)
+		}
+		p.next()
+		return filter, nil
+	}
+
+	return p.parseCondition()
+}
+
+// parseCondition parses a comparison of a field to values
+func (p *taskFilterParser) parseCondition() (*TaskFilter, error) {
+	token := p.peek()
+	if token.kind != taskFilterTokenWord {
+		return nil, p.errorf("expected a field")
+	}
+	field, ok := taskFilterFields[strings.ToLower(token.text)]
+	if !ok {
+		return nil, p.errorf("unknown field %q", token.text)
+	}
+	p.next()
+
+	p.conditions++
+	if p.conditions > MaxTaskFilterConditions {
+		return nil, &TaskFilterError{Pos: token.pos, Reason: fmt.Sprintf("at most %d conditions are allowed", MaxTaskFilterConditions)}
+	}
+
+	condition := &TaskFilterCondition{Field: field.field}
+
+	// Operator
+	switch {
+	case p.peek().kind == taskFilterTokenOperator:
+		condition.Operator = TaskFilterOperator(p.next().text)
+	case p.keyword("in"):
+		condition.Operator = TaskFilterOperatorIn
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, p.errorf(`expected "in"`)
+		}
+		condition.Operator = TaskFilterOperatorNotIn
+	default:
+		return nil, p.errorf("expected an operator")
+	}
+
+	// Values
+	if condition.Operator == TaskFilterOperatorIn || condition.Operator == TaskFilterOperatorNotIn {
+		if p.peek().kind != taskFilterTokenLParen {
+			return nil, p.errorf(`expected "("`)
+		}
+		p.next()
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			condition.Values = append(condition.Values, value)
+
+			if p.peek().kind == taskFilterTokenComma {
+				p.next()
+				continue
+			}
+			if p.peek().kind != taskFilterTokenRParen {
+				return nil, p.errorf(`expected "," or ")"`)
+			}
+			p.next()
+			break
+		}
+	} else {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		condition.Values = []string{value}
+	}
+
+	if err := validateTaskFilterCondition(condition, field.kind); err != nil {
+		return nil, &TaskFilterError{Pos: token.pos, Reason: fmt.Sprintf("%s: %s", token.text, err)}
+	}
+
+	return &TaskFilter{Condition: condition}, nil
+}
+
+// parseValue parses a bare word or a quoted string
+func (p *taskFilterParser) parseValue() (string, error) {
+	token := p.peek()
+	if token.kind != taskFilterTokenWord && token.kind != taskFilterTokenString {
+		return "", p.errorf("expected a value")
+	}
+	p.next()
+	return token.text, nil
+}
+
+// validateTaskFilterCondition checks that the operator and values of a
+// condition suit its field
+func validateTaskFilterCondition(condition *TaskFilterCondition, kind taskFilterKind) error {
+	operator := condition.Operator
+	ordered := operator == TaskFilterOperatorLt || operator == TaskFilterOperatorLte ||
+		operator == TaskFilterOperatorGt || operator == TaskFilterOperatorGte
+	list := operator == TaskFilterOperatorIn || operator == TaskFilterOperatorNotIn
+
+	switch kind {
+	case taskFilterKindString:
+		if ordered {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+
+	case taskFilterKindPriority:
+		for i, value := range condition.Values {
+			priority := TaskPriority(strings.ToLower(value))
+			if _, ok := TaskPriorityRank[priority]; !ok {
+				return fmt.Errorf("%q is not a priority", value)
+			}
+			condition.Values[i] = string(priority)
+		}
+
+	case taskFilterKindUser, taskFilterKindUUID:
+		if ordered {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+		for i, value := range condition.Values {
+			lower := strings.ToLower(value)
+			switch {
+			case lower == TaskFilterValueNone:
+				if list {
+					return fmt.Errorf("%q cannot be used in a list", value)
+				}
+				condition.Values[i] = lower
+			case lower == TaskFilterValueMe && kind == taskFilterKindUser:
+				condition.Values[i] = lower
+			default:
+				if _, err := uuid.Parse(value); err != nil {
+					return fmt.Errorf("%q is not an ID", value)
+				}
+			}
+		}
+
+	case taskFilterKindDate:
+		if list {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+		value := condition.Values[0]
+		if strings.ToLower(value) == TaskFilterValueNone {
+			if ordered {
+				return fmt.Errorf("%q can only be compared with = or !=", value)
+			}
+			condition.Values[0] = TaskFilterValueNone
+			break
+		}
+		_, wholeDay, err := ResolveTaskFilterDate(value, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ordered && !wholeDay {
+			return fmt.Errorf("only dates can be compared with = or !=")
+		}
+
+	case taskFilterKindText:
+		if operator != TaskFilterOperatorEq {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+
+	case taskFilterKindIs:
+		if operator != TaskFilterOperatorEq {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+		value := strings.ToLower(condition.Values[0])
+		if value != TaskFilterValueOpen && value != TaskFilterValueClosed {
+			return fmt.Errorf("%q must be open or closed", condition.Values[0])
+		}
+		condition.Values[0] = value
+	}
+
+	return nil
+}
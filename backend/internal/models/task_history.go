@@ -0,0 +1,344 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskHistoryAction represents the kind of change recorded in a task's history
+type TaskHistoryAction string
+
+// Task history actions
+const (
+	TaskHistoryActionCreated       TaskHistoryAction = "created"
+	TaskHistoryActionUpdated       TaskHistoryAction = "updated"
+	TaskHistoryActionStatusChanged TaskHistoryAction = "status_changed"
+	TaskHistoryActionAssigned      TaskHistoryAction = "assigned"
+	TaskHistoryActionUnassigned    TaskHistoryAction = "unassigned"
+	TaskHistoryActionTagAdded      TaskHistoryAction = "tag_added"
+	TaskHistoryActionTagRemoved    TaskHistoryAction = "tag_removed"
+)
+
+// Task fields recorded in history, in addition to the TaskField* fields
+const (
+	TaskFieldProjectID = "project_id"
+	TaskFieldParentID  = "parent_id"
+	TaskFieldSprintID  = "sprint_id"
+	TaskFieldTitle     = "title"
+	TaskFieldStatus    = "status"
+	TaskFieldPriority  = "priority"
+	TaskFieldTags      = "tags"
+
+	// Scheduling fields of a task on its project's timeline
+	TaskFieldStartDate    = "start_date"
+	TaskFieldDurationDays = "duration_days"
+
+	// TaskFieldCustomFieldPrefix prefixes the key of a changed custom field
+	TaskFieldCustomFieldPrefix = "custom_fields."
+)
+
+// TaskHistory represents a recorded change to a task
+type TaskHistory struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	TaskID    uuid.UUID         `json:"task_id" db:"task_id"`
+	UserID    uuid.UUID         `json:"user_id" db:"user_id"`
+	Action    TaskHistoryAction `json:"action" db:"action"`
+	FieldName *string           `json:"field_name,omitempty" db:"field_name"`
+	OldValue  *string           `json:"old_value,omitempty" db:"old_value"`
+	NewValue  *string           `json:"new_value,omitempty" db:"new_value"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+
+	// Related entities
+	User *User `json:"user,omitempty" db:"-"`
+}
+
+// TaskHistoryResponse represents the task history data returned to clients
+type TaskHistoryResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	TaskID    uuid.UUID         `json:"task_id"`
+	UserID    uuid.UUID         `json:"user_id"`
+	Action    TaskHistoryAction `json:"action"`
+	FieldName *string           `json:"field_name,omitempty"`
+	OldValue  *string           `json:"old_value,omitempty"`
+	NewValue  *string           `json:"new_value,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+
+	// Related entities
+	User *UserResponse `json:"user,omitempty"`
+}
+
+// ToResponse converts a TaskHistory to a TaskHistoryResponse
+func (h *TaskHistory) ToResponse() TaskHistoryResponse {
+	response := TaskHistoryResponse{
+		ID:        h.ID,
+		TaskID:    h.TaskID,
+		UserID:    h.UserID,
+		Action:    h.Action,
+		FieldName: h.FieldName,
+		OldValue:  h.OldValue,
+		NewValue:  h.NewValue,
+		CreatedAt: h.CreatedAt,
+	}
+
+	if h.User != nil {
+		userResponse := h.User.ToResponse()
+		response.User = &userResponse
+	}
+
+	return response
+}
+
+// TaskFieldChange describes the change of a single task field. Values are
+// rendered as text; a nil value means the field was unset.
+type TaskFieldChange struct {
+	Field    string
+	OldValue *string
+	NewValue *string
+}
+
+// Action returns the history action that records the change
+func (c TaskFieldChange) Action() TaskHistoryAction {
+	switch c.Field {
+	case TaskFieldStatus:
+		return TaskHistoryActionStatusChanged
+	case TaskFieldAssignedTo:
+		if c.NewValue == nil {
+			return TaskHistoryActionUnassigned
+		}
+		return TaskHistoryActionAssigned
+	case TaskFieldTags:
+		if c.NewValue == nil {
+			return TaskHistoryActionTagRemoved
+		}
+		return TaskHistoryActionTagAdded
+	}
+	return TaskHistoryActionUpdated
+}
+
+// NewTaskHistory creates a new TaskHistory entry for a field change
+func NewTaskHistory(taskID, userID uuid.UUID, change TaskFieldChange) *TaskHistory {
+	field := change.Field
+	return &TaskHistory{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		UserID:    userID,
+		Action:    change.Action(),
+		FieldName: &field,
+		OldValue:  change.OldValue,
+		NewValue:  change.NewValue,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewTaskCreatedHistory creates a new TaskHistory entry for the creation of a task
+func NewTaskCreatedHistory(taskID, userID uuid.UUID) *TaskHistory {
+	return &TaskHistory{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		UserID:    userID,
+		Action:    TaskHistoryActionCreated,
+		CreatedAt: time.Now(),
+	}
+}
+
+// DiffTasks returns the field-level changes from before to after. Tags are
+// reported one change per added or removed tag, and custom fields one change
+// per key.
+func DiffTasks(before, after *Task) []TaskFieldChange {
+	var changes []TaskFieldChange
+	add := func(field string, oldValue, newValue *string) {
+		if !equalValues(oldValue, newValue) {
+			changes = append(changes, TaskFieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	add(TaskFieldProjectID, uuidValue(before.ProjectID), uuidValue(after.ProjectID))
+	add(TaskFieldParentID, uuidValue(before.ParentID), uuidValue(after.ParentID))
+	add(TaskFieldSprintID, uuidValue(before.SprintID), uuidValue(after.SprintID))
+	add(TaskFieldTitle, stringValue(before.Title), stringValue(after.Title))
+	add(TaskFieldDescription, stringValue(before.Description), stringValue(after.Description))
+	add(TaskFieldStatus, stringValue(string(before.Status)), stringValue(string(after.Status)))
+	add(TaskFieldPriority, stringValue(string(before.Priority)), stringValue(string(after.Priority)))
+	add(TaskFieldDueDate, timeValue(before.DueDate), timeValue(after.DueDate))
+	add(TaskFieldStartDate, timeValue(before.StartDate), timeValue(after.StartDate))
+	add(TaskFieldDurationDays, intValue(before.DurationDays), intValue(after.DurationDays))
+	add(TaskFieldAssignedTo, uuidValue(before.AssignedTo), uuidValue(after.AssignedTo))
+	add(TaskFieldEstimatedHours, floatValue(before.EstimatedHours), floatValue(after.EstimatedHours))
+	add(TaskFieldActualHours, floatValue(before.ActualHours), floatValue(after.ActualHours))
+
+	// Tags
+	oldTags := make(map[string]bool, len(before.Tags))
+	for _, tag := range before.Tags {
+		oldTags[tag] = true
+	}
+	newTags := make(map[string]bool, len(after.Tags))
+	for _, tag := range after.Tags {
+		newTags[tag] = true
+	}
+	for _, tag := range sortedKeys(oldTags) {
+		if !newTags[tag] {
+			add(TaskFieldTags, stringValue(tag), nil)
+		}
+	}
+	for _, tag := range sortedKeys(newTags) {
+		if !oldTags[tag] {
+			add(TaskFieldTags, nil, stringValue(tag))
+		}
+	}
+
+	// Custom fields
+	keys := make(map[string]bool, len(before.CustomFields)+len(after.CustomFields))
+	for key := range before.CustomFields {
+		keys[key] = true
+	}
+	for key := range after.CustomFields {
+		keys[key] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		add(TaskFieldCustomFieldPrefix+key, jsonValue(before.CustomFields[key]), jsonValue(after.CustomFields[key]))
+	}
+
+	return changes
+}
+
+// equalValues reports whether two optional values are equal
+func equalValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// stringValue renders a string for history, treating the empty string as unset
+func stringValue(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// uuidValue renders an optional UUID for history
+func uuidValue(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	return stringValue(id.String())
+}
+
+// timeValue renders an optional time for history
+func timeValue(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	return stringValue(t.UTC().Format(time.RFC3339))
+}
+
+// floatValue renders an optional number for history
+func floatValue(f *float64) *string {
+	if f == nil {
+		return nil
+	}
+	return stringValue(strconv.FormatFloat(*f, 'f', -1, 64))
+}
+
+// intValue renders an optional whole number for history
+func intValue(i *int) *string {
+	if i == nil {
+		return nil
+	}
+	return stringValue(strconv.Itoa(*i))
+}
+
+// jsonValue renders a custom field value for history
+func jsonValue(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return stringValue(string(data))
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ActivityType represents the kind of an item in an activity feed
+type ActivityType string
+
+// Activity types
+const (
+	ActivityTypeHistory    ActivityType = "history"
+	ActivityTypeComment    ActivityType = "comment"
+	ActivityTypeAttachment ActivityType = "attachment"
+)
+
+// Activity is an item of a task or project activity feed: a history entry,
+// a comment or a file attachment
+type Activity struct {
+	Type      ActivityType
+	TaskID    uuid.UUID
+	CreatedAt time.Time
+
+	History    *TaskHistory
+	Comment    *Comment
+	Attachment *FileAttachment
+}
+
+// ActivityResponse represents an activity feed item returned to clients
+type ActivityResponse struct {
+	Type      ActivityType `json:"type"`
+	TaskID    uuid.UUID    `json:"task_id"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	History    *TaskHistoryResponse    `json:"history,omitempty"`
+	Comment    *CommentResponse        `json:"comment,omitempty"`
+	Attachment *FileAttachmentResponse `json:"attachment,omitempty"`
+}
+
+// ToResponse converts an Activity to an ActivityResponse. baseURL is used to
+// build attachment download URLs.
+func (a *Activity) ToResponse(baseURL string) ActivityResponse {
+	response := ActivityResponse{
+		Type:      a.Type,
+		TaskID:    a.TaskID,
+		CreatedAt: a.CreatedAt,
+	}
+
+	if a.History != nil {
+		historyResponse := a.History.ToResponse()
+		response.History = &historyResponse
+	}
+
+	if a.Comment != nil {
+		commentResponse := a.Comment.ToResponse()
+		response.Comment = &commentResponse
+	}
+
+	if a.Attachment != nil {
+		attachmentResponse := a.Attachment.ToResponse(baseURL)
+		response.Attachment = &attachmentResponse
+	}
+
+	return response
+}
+
+// ActivityListParams represents the parameters for listing an activity feed
+type ActivityListParams struct {
+	TaskID    *uuid.UUID `query:"-"`
+	ProjectID *uuid.UUID `query:"-"`
+	Page      int        `query:"page" default:"1"`
+	PageSize  int        `query:"page_size" default:"20"`
+}
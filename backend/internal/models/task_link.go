@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskLinkType represents the type of relationship between two tasks
+type TaskLinkType string
+
+// Task link types. Only blocks, relates_to and duplicates are stored;
+// blocked_by and duplicated_by are their inverses as seen from the target task.
+const (
+	TaskLinkTypeBlocks       TaskLinkType = "blocks"
+	TaskLinkTypeBlockedBy    TaskLinkType = "blocked_by"
+	TaskLinkTypeRelatesTo    TaskLinkType = "relates_to"
+	TaskLinkTypeDuplicates   TaskLinkType = "duplicates"
+	TaskLinkTypeDuplicatedBy TaskLinkType = "duplicated_by"
+)
+
+// Inverse returns the link type as seen from the other task
+func (t TaskLinkType) Inverse() TaskLinkType {
+	switch t {
+	case TaskLinkTypeBlocks:
+		return TaskLinkTypeBlockedBy
+	case TaskLinkTypeBlockedBy:
+		return TaskLinkTypeBlocks
+	case TaskLinkTypeDuplicates:
+		return TaskLinkTypeDuplicatedBy
+	case TaskLinkTypeDuplicatedBy:
+		return TaskLinkTypeDuplicates
+	default:
+		return t
+	}
+}
+
+// IsStored reports whether the link type is stored as-is rather than as its inverse
+func (t TaskLinkType) IsStored() bool {
+	return t == TaskLinkTypeBlocks || t == TaskLinkTypeRelatesTo || t == TaskLinkTypeDuplicates
+}
+
+// TaskLink represents a directed relationship between two tasks
+type TaskLink struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	SourceTaskID uuid.UUID    `json:"source_task_id" db:"source_task_id"`
+	TargetTaskID uuid.UUID    `json:"target_task_id" db:"target_task_id"`
+	Type         TaskLinkType `json:"type" db:"type"`
+	CreatedBy    uuid.UUID    `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+
+	// Related entities
+	SourceTask *Task `json:"source_task,omitempty" db:"-"`
+	TargetTask *Task `json:"target_task,omitempty" db:"-"`
+}
+
+// TaskLinkRequest represents the data needed to link a task to another task
+type TaskLinkRequest struct {
+	TaskID uuid.UUID    `json:"task_id" validate:"required"`
+	Type   TaskLinkType `json:"type" validate:"required,oneof=blocks blocked_by relates_to duplicates duplicated_by"`
+}
+
+// TaskLinkResponse represents a task link as seen from one of its tasks
+type TaskLinkResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	Type       TaskLinkType  `json:"type"`
+	TaskID     uuid.UUID     `json:"task_id"`
+	LinkedTask *TaskResponse `json:"linked_task,omitempty"`
+	CreatedBy  uuid.UUID     `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// ToResponse converts a TaskLink to a TaskLinkResponse from the perspective of taskID
+func (l *TaskLink) ToResponse(taskID uuid.UUID) TaskLinkResponse {
+	response := TaskLinkResponse{
+		ID:        l.ID,
+		Type:      l.Type,
+		TaskID:    l.TargetTaskID,
+		CreatedBy: l.CreatedBy,
+		CreatedAt: l.CreatedAt,
+	}
+
+	linked := l.TargetTask
+	if l.TargetTaskID == taskID {
+		response.Type = l.Type.Inverse()
+		response.TaskID = l.SourceTaskID
+		linked = l.SourceTask
+	}
+
+	if linked != nil {
+		linkedResponse := linked.ToResponse()
+		response.LinkedTask = &linkedResponse
+	}
+
+	return response
+}
+
+// NewTaskLink creates a new TaskLink from a TaskLinkRequest made on taskID.
+// Inverse link types are stored with source and target swapped, and
+// relates_to links are stored in a canonical order so that each pair of
+// tasks can be related only once.
+func NewTaskLink(taskID uuid.UUID, req TaskLinkRequest, createdBy uuid.UUID) *TaskLink {
+	link := &TaskLink{
+		ID:           uuid.New(),
+		SourceTaskID: taskID,
+		TargetTaskID: req.TaskID,
+		Type:         req.Type,
+		CreatedBy:    createdBy,
+		CreatedAt:    time.Now(),
+	}
+
+	if !link.Type.IsStored() {
+		link.SourceTaskID, link.TargetTaskID = link.TargetTaskID, link.SourceTaskID
+		link.Type = link.Type.Inverse()
+	}
+
+	if link.Type == TaskLinkTypeRelatesTo && link.TargetTaskID.String() < link.SourceTaskID.String() {
+		link.SourceTaskID, link.TargetTaskID = link.TargetTaskID, link.SourceTaskID
+	}
+
+	return link
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskReminderKind represents the kind of a task reminder
+type TaskReminderKind string
+
+// Task reminder kinds
+const (
+	TaskReminderKindDueSoon TaskReminderKind = "due_soon"
+	TaskReminderKindOverdue TaskReminderKind = "overdue"
+)
+
+// TaskReminder records that an assignee was reminded of a task's due date.
+// A reminder is sent once per threshold, assignee and due date, so changing
+// the due date or the assignee lets the reminders be sent again.
+type TaskReminder struct {
+	TaskID        uuid.UUID        `json:"task_id" db:"task_id"`
+	UserID        uuid.UUID        `json:"user_id" db:"user_id"`
+	Kind          TaskReminderKind `json:"kind" db:"kind"`
+	ThresholdDays int              `json:"threshold_days" db:"threshold_days"`
+	DueDate       time.Time        `json:"due_date" db:"due_date"`
+	SentAt        time.Time        `json:"sent_at" db:"sent_at"`
+}
+
+// NewTaskReminder creates a new TaskReminder for the assignee and due date of
+// a task
+func NewTaskReminder(task *Task, kind TaskReminderKind, thresholdDays int) *TaskReminder {
+	return &TaskReminder{
+		TaskID:        task.ID,
+		UserID:        *task.AssignedTo,
+		Kind:          kind,
+		ThresholdDays: thresholdDays,
+		DueDate:       *task.DueDate,
+		SentAt:        time.Now(),
+	}
+}
+
+// TaskReminderCandidate is an open, assigned task with a due date, along with
+// the time zone of its assignee
+type TaskReminderCandidate struct {
+	Task
+	Timezone string `json:"timezone" db:"timezone"`
+}
+
+// Location returns the assignee's time zone, or UTC if it is unset or unknown
+func (c *TaskReminderCandidate) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"time"
+)
+
+// TaskTransferFormat represents a file format tasks are imported from or
+// exported to
+type TaskTransferFormat string
+
+// Task transfer formats
+const (
+	// TaskTransferFormatCSV is a CSV file with a header row, whose columns are
+	// mapped to task import fields
+	TaskTransferFormatCSV TaskTransferFormat = "csv"
+
+	// TaskTransferFormatJSON is the Taskodex export format
+	TaskTransferFormatJSON TaskTransferFormat = "json"
+
+	// TaskTransferFormatJiraCSV is a Jira issue CSV export
+	TaskTransferFormatJiraCSV TaskTransferFormat = "jira_csv"
+
+	// TaskTransferFormatJiraXML is a Jira issue XML (RSS) export
+	TaskTransferFormatJiraXML TaskTransferFormat = "jira_xml"
+)
+
+// TaskTransferDocumentFormat identifies Taskodex JSON exports
+const TaskTransferDocumentFormat = "taskodex"
+
+// TaskTransferDocumentVersion is the version of the Taskodex JSON export format
+const TaskTransferDocumentVersion = 1
+
+// MaxImportRows is the maximum number of tasks an import can contain
+const MaxImportRows = 5000
+
+// Task import fields, the targets CSV columns are mapped to
+const (
+	TaskImportFieldKey            = "key"
+	TaskImportFieldParent         = "parent"
+	TaskImportFieldTitle          = "title"
+	TaskImportFieldDescription    = "description"
+	TaskImportFieldStatus         = "status"
+	TaskImportFieldPriority       = "priority"
+	TaskImportFieldDueDate        = "due_date"
+	TaskImportFieldAssignee       = "assignee"
+	TaskImportFieldCreator        = "creator"
+	TaskImportFieldEstimatedHours = "estimated_hours"
+	TaskImportFieldTags           = "tags"
+)
+
+// TaskImportFields lists the task import fields in the column order of CSV
+// exports
+var TaskImportFields = []string{
+	TaskImportFieldKey,
+	TaskImportFieldParent,
+	TaskImportFieldTitle,
+	TaskImportFieldDescription,
+	TaskImportFieldStatus,
+	TaskImportFieldPriority,
+	TaskImportFieldDueDate,
+	TaskImportFieldAssignee,
+	TaskImportFieldCreator,
+	TaskImportFieldEstimatedHours,
+	TaskImportFieldTags,
+}
+
+// TaskImportOptions represents how an import file is read
+type TaskImportOptions struct {
+	Format TaskTransferFormat `json:"format" validate:"required,oneof=csv json jira_csv jira_xml"`
+
+	// Mapping maps CSV columns to task import fields. Without a mapping,
+	// columns named after a field are used.
+	Mapping map[string]string `json:"mapping,omitempty"`
+
+	// DryRun validates the file without importing anything
+	DryRun bool `json:"dry_run"`
+}
+
+// TaskTransferDocument represents a Taskodex JSON export
+type TaskTransferDocument struct {
+	Format     string              `json:"format"`
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Project    TaskTransferProject `json:"project"`
+	Tasks      []TaskTransferTask  `json:"tasks"`
+}
+
+// TaskTransferProject represents the project a JSON export was made from
+type TaskTransferProject struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TaskTransferTask represents a task in an import or export. Users are
+// identified by email and tasks by key, so that files can move between
+// installations.
+type TaskTransferTask struct {
+	Key            string                  `json:"key,omitempty"`
+	ParentKey      string                  `json:"parent_key,omitempty"`
+	Title          string                  `json:"title"`
+	Description    string                  `json:"description,omitempty"`
+	Status         string                  `json:"status,omitempty"`
+	Priority       string                  `json:"priority,omitempty"`
+	DueDate        *time.Time              `json:"due_date,omitempty"`
+	AssigneeEmail  string                  `json:"assignee_email,omitempty"`
+	CreatorEmail   string                  `json:"creator_email,omitempty"`
+	EstimatedHours *float64                `json:"estimated_hours,omitempty"`
+	Tags           []string                `json:"tags,omitempty"`
+	CreatedAt      *time.Time              `json:"created_at,omitempty"`
+	Comments       []TaskTransferComment   `json:"comments,omitempty"`
+	TimeEntries    []TaskTransferTimeEntry `json:"time_entries,omitempty"`
+}
+
+// TaskTransferComment represents a comment on a task in an import or export
+type TaskTransferComment struct {
+	AuthorEmail string     `json:"author_email,omitempty"`
+	Content     string     `json:"content"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// TaskTransferTimeEntry represents a time entry of a task in an import or
+// export
+type TaskTransferTimeEntry struct {
+	UserEmail       string     `json:"user_email,omitempty"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	Billable        *bool      `json:"billable,omitempty"`
+}
+
+// TaskImportRecord represents a task read from an import file, before it is
+// checked against the project it is imported into
+type TaskImportRecord struct {
+	TaskTransferTask
+
+	// Row is the row of the task in the file, counting a CSV header as row 1
+	Row int
+
+	// Errors holds the values of the row that could not be read
+	Errors []TaskImportError
+}
+
+// TaskImportError represents a problem with a row of an import file
+type TaskImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// TaskImportResult represents the outcome of an import. Nothing is imported
+// if there are errors.
+type TaskImportResult struct {
+	Format      TaskTransferFormat `json:"format"`
+	DryRun      bool               `json:"dry_run"`
+	Rows        int                `json:"rows"`
+	Imported    int                `json:"imported"`
+	Comments    int                `json:"comments"`
+	TimeEntries int                `json:"time_entries"`
+	Errors      []TaskImportError  `json:"errors"`
+	Warnings    []TaskImportError  `json:"warnings"`
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// TaskRollup summarizes a task together with all of its subtasks
+type TaskRollup struct {
+	// Progress is the completion percentage from 0 to 100
+	Progress                    float64 `json:"progress"`
+	EstimatedHours              float64 `json:"estimated_hours"`
+	ActualHours                 float64 `json:"actual_hours"`
+	SubtaskCount                int     `json:"subtask_count"`
+	CompletedSubtaskCount       int     `json:"completed_subtask_count"`
+	ChecklistItemCount          int     `json:"checklist_item_count"`
+	CompletedChecklistItemCount int     `json:"completed_checklist_item_count"`
+}
+
+// TaskTreeNode is a task with its subtasks
+type TaskTreeNode struct {
+	TaskResponse
+	Children []TaskTreeNode `json:"children"`
+}
+
+// BuildTaskTree links root to its descendants, computes the roll-up of every
+// task in the tree and returns the tree. Descendants whose parent is not part
+// of the tree are ignored. checklists maps task IDs to their checklist items,
+// and closed holds the IDs of tasks in a closed state of their workflow.
+func BuildTaskTree(root *Task, descendants []Task, checklists map[uuid.UUID][]ChecklistItem, closed map[uuid.UUID]bool) TaskTreeNode {
+	children := make(map[uuid.UUID][]*Task)
+	for i := range descendants {
+		if parentID := descendants[i].ParentID; parentID != nil {
+			children[*parentID] = append(children[*parentID], &descendants[i])
+		}
+	}
+
+	visited := make(map[uuid.UUID]bool)
+	node, _ := buildTaskTreeNode(root, children, checklists, closed, visited)
+	return node
+}
+
+// buildTaskTreeNode builds the node for task and returns it with the task's
+// progress as a fraction from 0 to 1
+func buildTaskTreeNode(task *Task, children map[uuid.UUID][]*Task, checklists map[uuid.UUID][]ChecklistItem, closed map[uuid.UUID]bool, visited map[uuid.UUID]bool) (TaskTreeNode, float64) {
+	visited[task.ID] = true
+
+	rollup := &TaskRollup{}
+	if task.EstimatedHours != nil {
+		rollup.EstimatedHours = *task.EstimatedHours
+	}
+	if task.ActualHours != nil {
+		rollup.ActualHours = *task.ActualHours
+	}
+
+	// Every checklist item and every subtask is one unit of work
+	var units []float64
+
+	if items, ok := checklists[task.ID]; ok {
+		task.ChecklistItems = items
+	}
+	for _, item := range task.ChecklistItems {
+		rollup.ChecklistItemCount++
+		if item.IsCompleted {
+			rollup.CompletedChecklistItemCount++
+			units = append(units, 1)
+		} else {
+			units = append(units, 0)
+		}
+	}
+
+	childNodes := make([]TaskTreeNode, 0, len(children[task.ID]))
+	for _, child := range children[task.ID] {
+		// Guard against cycles in inconsistent data
+		if visited[child.ID] {
+			continue
+		}
+
+		childNode, childProgress := buildTaskTreeNode(child, children, checklists, closed, visited)
+		childNodes = append(childNodes, childNode)
+
+		childRollup := childNode.Rollup
+		rollup.EstimatedHours += childRollup.EstimatedHours
+		rollup.ActualHours += childRollup.ActualHours
+		rollup.SubtaskCount += 1 + childRollup.SubtaskCount
+		rollup.CompletedSubtaskCount += childRollup.CompletedSubtaskCount
+		if closed[child.ID] {
+			rollup.CompletedSubtaskCount++
+		}
+
+		units = append(units, childProgress)
+	}
+
+	var progress float64
+	switch {
+	case closed[task.ID]:
+		progress = 1
+	case len(units) > 0:
+		var sum float64
+		for _, unit := range units {
+			sum += unit
+		}
+		progress = sum / float64(len(units))
+	}
+	rollup.Progress = math.Round(progress*1000) / 10
+
+	task.Rollup = rollup
+
+	return TaskTreeNode{
+		TaskResponse: task.ToResponse(),
+		Children:     childNodes,
+	}, progress
+}
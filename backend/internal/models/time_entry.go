@@ -15,6 +15,7 @@ type TimeEntry struct {
 	EndTime         *time.Time `json:"end_time,omitempty" db:"end_time"`
 	DurationMinutes *int       `json:"duration_minutes,omitempty" db:"duration_minutes"`
 	Description     string     `json:"description" db:"description"`
+	Billable        bool       `json:"billable" db:"billable"`
 	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
 	
@@ -30,6 +31,7 @@ type TimeEntryRequest struct {
 	EndTime         *time.Time `json:"end_time,omitempty"`
 	DurationMinutes *int       `json:"duration_minutes,omitempty"`
 	Description     string     `json:"description" validate:"max=1000"`
+	Billable        *bool      `json:"billable,omitempty"` // Defaults to true on create, unchanged on update
 }
 
 // TimeEntryResponse represents the time entry data returned to clients
@@ -41,6 +43,7 @@ type TimeEntryResponse struct {
 	EndTime         *time.Time       `json:"end_time,omitempty"`
 	DurationMinutes *int             `json:"duration_minutes,omitempty"`
 	Description     string           `json:"description"`
+	Billable        bool             `json:"billable"`
 	CreatedAt       time.Time        `json:"created_at"`
 	UpdatedAt       time.Time        `json:"updated_at"`
 	
@@ -59,6 +62,7 @@ func (t *TimeEntry) ToResponse() TimeEntryResponse {
 		EndTime:         t.EndTime,
 		DurationMinutes: t.DurationMinutes,
 		Description:     t.Description,
+		Billable:        t.Billable,
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 	}
@@ -89,6 +93,12 @@ func NewTimeEntry(req TimeEntryRequest, userID uuid.UUID) *TimeEntry {
 		durationMinutes = req.DurationMinutes
 	}
 	
+	// Time is billable unless marked otherwise
+	billable := true
+	if req.Billable != nil {
+		billable = *req.Billable
+	}
+	
 	return &TimeEntry{
 		ID:              uuid.New(),
 		TaskID:          req.TaskID,
@@ -97,6 +107,7 @@ func NewTimeEntry(req TimeEntryRequest, userID uuid.UUID) *TimeEntry {
 		EndTime:         req.EndTime,
 		DurationMinutes: durationMinutes,
 		Description:     req.Description,
+		Billable:        billable,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
@@ -121,14 +132,36 @@ func (t *TimeEntry) IsRunning() bool {
 	return t.EndTime == nil
 }
 
+// IsRunningTimer returns true if the time entry is a timer that is still
+// running: it has neither an end time nor a duration
+func (t *TimeEntry) IsRunningTimer() bool {
+	return t.EndTime == nil && t.DurationMinutes == nil
+}
+
+// EndAt returns when the time entry ends: its end time, the end of its
+// duration, or now while its timer is running
+func (t *TimeEntry) EndAt(now time.Time) time.Time {
+	if t.EndTime != nil {
+		return *t.EndTime
+	}
+	if t.DurationMinutes != nil {
+		return t.StartTime.Add(time.Duration(*t.DurationMinutes) * time.Minute)
+	}
+	return now
+}
+
 // Stop stops the time entry by setting the end time to the current time
 func (t *TimeEntry) Stop() {
-	now := time.Now()
-	t.EndTime = &now
-	t.UpdatedAt = now
+	t.StopAt(time.Now())
+}
+
+// StopAt stops the time entry by setting the end time to end
+func (t *TimeEntry) StopAt(end time.Time) {
+	t.EndTime = &end
+	t.UpdatedAt = time.Now()
 	
 	// Calculate duration
-	duration := int(now.Sub(t.StartTime).Minutes())
+	duration := int(end.Sub(t.StartTime).Minutes())
 	t.DurationMinutes = &duration
 }
 
@@ -139,6 +172,7 @@ type TimeEntryListParams struct {
 	StartAfter *time.Time `query:"start_after"`
 	StartBefore *time.Time `query:"start_before"`
 	IsRunning  *bool      `query:"is_running"`
+	Billable   *bool      `query:"billable"`
 	SortBy     string     `query:"sort_by" default:"start_time"`
 	SortOrder  string     `query:"sort_order" default:"desc"`
 	Page       int        `query:"page" default:"1"`
@@ -148,6 +182,7 @@ type TimeEntryListParams struct {
 // TimeEntryAggregation represents aggregated time entry data
 type TimeEntryAggregation struct {
 	TotalDurationMinutes int       `json:"total_duration_minutes"`
+	BillableMinutes      int       `json:"billable_minutes"`
 	TaskID               *uuid.UUID `json:"task_id,omitempty"`
 	UserID               *uuid.UUID `json:"user_id,omitempty"`
 	Date                 *time.Time `json:"date,omitempty"`
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimeEntryOverlapMode represents what happens to a time entry that overlaps
+// other entries of its user
+type TimeEntryOverlapMode string
+
+// Time entry overlap modes. Overlapping entries are allowed, rejected, or
+// merged into one when they are on the same task.
+const (
+	TimeEntryOverlapAllow  TimeEntryOverlapMode = "allow"
+	TimeEntryOverlapReject TimeEntryOverlapMode = "reject"
+	TimeEntryOverlapMerge  TimeEntryOverlapMode = "merge"
+)
+
+// TimeEntryPolicy represents the rules the time entries logged on the tasks
+// of an organization's projects follow
+type TimeEntryPolicy struct {
+	OrganizationID uuid.UUID `json:"organization_id" db:"organization_id"`
+
+	// SingleRunningTimer stops the running timers of a user when they start
+	// another one
+	SingleRunningTimer bool `json:"single_running_timer" db:"single_running_timer"`
+
+	OverlapMode TimeEntryOverlapMode `json:"overlap_mode" db:"overlap_mode"`
+
+	// MaxEntryMinutes is the longest a time entry can be, and AutoStopMinutes
+	// how long a timer can run before it is stopped automatically. Both are
+	// unlimited when 0.
+	MaxEntryMinutes int `json:"max_entry_minutes" db:"max_entry_minutes"`
+	AutoStopMinutes int `json:"auto_stop_minutes" db:"auto_stop_minutes"`
+
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// DefaultTimeEntryPolicy returns the policy of an organization that has
+// none: a single running timer per user, no overlapping entries, and no
+// limits on their length
+func DefaultTimeEntryPolicy(organizationID uuid.UUID) *TimeEntryPolicy {
+	return &TimeEntryPolicy{
+		OrganizationID:     organizationID,
+		SingleRunningTimer: true,
+		OverlapMode:        TimeEntryOverlapReject,
+	}
+}
+
+// StopTime returns when a timer started at start and stopped at end is
+// stopped under the policy: at end, or once it reaches the maximum length of
+// an entry
+func (p *TimeEntryPolicy) StopTime(start, end time.Time) time.Time {
+	if p.MaxEntryMinutes > 0 {
+		limit := start.Add(time.Duration(p.MaxEntryMinutes) * time.Minute)
+		if end.After(limit) {
+			return limit
+		}
+	}
+	return end
+}
+
+// TimeEntryPolicyRequest represents the data needed to update the time entry
+// policy of an organization
+type TimeEntryPolicyRequest struct {
+	SingleRunningTimer bool                 `json:"single_running_timer"`
+	OverlapMode        TimeEntryOverlapMode `json:"overlap_mode" validate:"required,oneof=allow reject merge"`
+	MaxEntryMinutes    int                  `json:"max_entry_minutes" validate:"min=0,max=10080"`
+	AutoStopMinutes    int                  `json:"auto_stop_minutes" validate:"min=0,max=10080"`
+}
+
+// IdleTimer represents a timer that has run for longer than the policy of
+// its organization allows
+type IdleTimer struct {
+	TimeEntry
+	TaskTitle       string `db:"task_title"`
+	AutoStopMinutes int    `db:"auto_stop_minutes"`
+	MaxEntryMinutes int    `db:"max_entry_minutes"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTaskDurationDays is the duration of tasks that have neither a
+// duration nor both a start and a due date
+const DefaultTaskDurationDays = 1
+
+// Timeline represents the schedule of the tasks of a project, for a Gantt
+// chart. Tasks start as soon as their start dates and the tasks that block
+// them allow. Dates are UTC days; end dates are the last day of a task.
+type Timeline struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	StartDate string    `json:"start_date"`
+
+	// EndDate is the projected end of the project, the last day of its
+	// latest task
+	EndDate      string `json:"end_date"`
+	DurationDays int    `json:"duration_days"`
+
+	// Late reports whether the projected end is after the end date of the
+	// project
+	Late bool `json:"late"`
+
+	Tasks        []TimelineTask       `json:"tasks"`
+	Dependencies []TimelineDependency `json:"dependencies"`
+
+	// CriticalPath is the tasks without slack, in the order they start.
+	// Delaying any of them delays the end of the project.
+	CriticalPath []uuid.UUID `json:"critical_path"`
+}
+
+// TimelineTask represents a task scheduled on a timeline
+type TimelineTask struct {
+	TaskID     uuid.UUID    `json:"task_id"`
+	ParentID   *uuid.UUID   `json:"parent_id,omitempty"`
+	Title      string       `json:"title"`
+	Status     TaskStatus   `json:"status"`
+	Priority   TaskPriority `json:"priority"`
+	AssignedTo *uuid.UUID   `json:"assigned_to,omitempty"`
+	DueDate    *time.Time   `json:"due_date,omitempty"`
+
+	// StartDate and EndDate are the days the task is scheduled on. A task
+	// of zero days is a milestone, which ends the day it starts.
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	DurationDays int    `json:"duration_days"`
+
+	// SlackDays are the days the task can be delayed without delaying the
+	// end of the project
+	SlackDays int  `json:"slack_days"`
+	Critical  bool `json:"critical"`
+
+	// Late reports whether the task is scheduled to end after its due date
+	Late bool `json:"late"`
+}
+
+// TimelineDependency represents a finish-to-start dependency between two
+// tasks of a timeline: a blocking link from the task that must finish to
+// the task that can then start
+type TimelineDependency struct {
+	LinkID     uuid.UUID `json:"link_id"`
+	FromTaskID uuid.UUID `json:"from_task_id"`
+	ToTaskID   uuid.UUID `json:"to_task_id"`
+}
+
+// TaskRescheduleRequest represents the data needed to reschedule a task
+type TaskRescheduleRequest struct {
+	StartDate    time.Time `json:"start_date" validate:"required"`
+	DurationDays *int      `json:"duration_days,omitempty" validate:"omitempty,min=0,max=3650"`
+}
+
+// TaskScheduleChange represents the change of the dates of a task made by
+// rescheduling
+type TaskScheduleChange struct {
+	TaskID uuid.UUID `json:"task_id"`
+	Title  string    `json:"title"`
+
+	OldStartDate    *time.Time `json:"old_start_date,omitempty"`
+	NewStartDate    *time.Time `json:"new_start_date,omitempty"`
+	OldDueDate      *time.Time `json:"old_due_date,omitempty"`
+	NewDueDate      *time.Time `json:"new_due_date,omitempty"`
+	OldDurationDays *int       `json:"old_duration_days,omitempty"`
+	NewDurationDays *int       `json:"new_duration_days,omitempty"`
+}
+
+// TaskRescheduleResult represents the outcome of rescheduling a task: the
+// tasks whose dates change, and the timeline of the project afterwards
+type TaskRescheduleResult struct {
+	DryRun   bool                 `json:"dry_run"`
+	Changes  []TaskScheduleChange `json:"changes"`
+	Timeline *Timeline            `json:"timeline"`
+}
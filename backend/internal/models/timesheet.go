@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimesheetStatus represents the status of a timesheet
+type TimesheetStatus string
+
+// Timesheet statuses. A week is open until its timesheet is submitted; the
+// manager of the user then approves it, or rejects it to be corrected and
+// submitted again. Submitted and approved timesheets lock their entries.
+const (
+	TimesheetStatusOpen      TimesheetStatus = "open"
+	TimesheetStatusSubmitted TimesheetStatus = "submitted"
+	TimesheetStatusApproved  TimesheetStatus = "approved"
+	TimesheetStatusRejected  TimesheetStatus = "rejected"
+)
+
+// IsLocked reports whether the time entries of a timesheet in the status
+// can no longer be changed
+func (s TimesheetStatus) IsLocked() bool {
+	return s == TimesheetStatusSubmitted || s == TimesheetStatusApproved
+}
+
+// TimesheetWeekStart returns the Monday of the UTC week of t
+func TimesheetWeekStart(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+}
+
+// Timesheet represents the submission of a week of time entries by a member
+// of an organization
+type Timesheet struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id" db:"organization_id"`
+	UserID         uuid.UUID       `json:"user_id" db:"user_id"`
+	WeekStart      time.Time       `json:"week_start" db:"week_start"`
+	Status         TimesheetStatus `json:"status" db:"status"`
+
+	// TotalMinutes is the time logged in the week when it was last
+	// submitted
+	TotalMinutes int `json:"total_minutes" db:"total_minutes"`
+
+	SubmittedAt time.Time  `json:"submitted_at" db:"submitted_at"`
+	ReviewedBy  *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Comments are the submissions and reviews of the timesheet, oldest
+	// first
+	Comments []TimesheetComment `json:"comments,omitempty" db:"-"`
+}
+
+// TimesheetComment represents a submission or review of a timesheet, with
+// the comment made
+type TimesheetComment struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	TimesheetID uuid.UUID       `json:"timesheet_id" db:"timesheet_id"`
+	UserID      *uuid.UUID      `json:"user_id,omitempty" db:"user_id"`
+	Action      TimesheetStatus `json:"action" db:"action"`
+	Comment     string          `json:"comment" db:"comment"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// NewTimesheetComment creates a new TimesheetComment
+func NewTimesheetComment(timesheetID, userID uuid.UUID, action TimesheetStatus, comment string) *TimesheetComment {
+	return &TimesheetComment{
+		ID:          uuid.New(),
+		TimesheetID: timesheetID,
+		UserID:      &userID,
+		Action:      action,
+		Comment:     comment,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// TimesheetWeek represents the time a member of an organization logged in a
+// week on the tasks of its projects, and the timesheet of the week if it was
+// submitted
+type TimesheetWeek struct {
+	OrganizationID uuid.UUID       `json:"organization_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	WeekStart      string          `json:"week_start"`
+	WeekEnd        string          `json:"week_end"`
+	Status         TimesheetStatus `json:"status"`
+	TotalMinutes   int             `json:"total_minutes"`
+
+	// Days are the minutes logged on each day of the week, from Monday
+	Days    []TimesheetDay      `json:"days"`
+	Entries []TimeEntryResponse `json:"entries"`
+
+	Timesheet *Timesheet `json:"timesheet,omitempty"`
+}
+
+// TimesheetDay represents the minutes logged on a day of a timesheet
+type TimesheetDay struct {
+	Date    string `json:"date"`
+	Minutes int    `json:"minutes"`
+}
+
+// TimesheetSubmitRequest represents the data needed to submit a week
+type TimesheetSubmitRequest struct {
+	// Week is any day of the week to submit
+	Week    time.Time `json:"week" validate:"required"`
+	Comment string    `json:"comment" validate:"max=2000"`
+}
+
+// TimesheetApproveRequest represents the data needed to approve a timesheet
+type TimesheetApproveRequest struct {
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+// TimesheetRejectRequest represents the data needed to reject a timesheet.
+// Rejections say what to correct.
+type TimesheetRejectRequest struct {
+	Comment string `json:"comment" validate:"required,max=2000"`
+}
+
+// TimesheetListParams represents the parameters for listing timesheets
+type TimesheetListParams struct {
+	OrganizationID uuid.UUID
+	UserID         *uuid.UUID
+	Status         *TimesheetStatus
+
+	// ManagerID limits the timesheets to those of the employees the user
+	// manages
+	ManagerID *uuid.UUID
+
+	From *time.Time
+	To   *time.Time
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTrashRetention is how long deleted projects and tasks are kept in
+// the trash by default before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashItemType represents the kind of an item in the trash
+type TrashItemType string
+
+// Trash item types
+const (
+	TrashItemTypeProject TrashItemType = "project"
+	TrashItemTypeTask    TrashItemType = "task"
+)
+
+// TrashItem represents a deleted project or task. Tasks deleted along with
+// their project or parent task are part of that item, not items of their own.
+type TrashItem struct {
+	Type TrashItemType `json:"type" db:"type"`
+	ID   uuid.UUID     `json:"id" db:"id"`
+
+	// ProjectID is the project of a task, or the project itself
+	ProjectID *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+
+	// OrganizationID is the organization of the project, unset for tasks
+	// without a project
+	OrganizationID *uuid.UUID `json:"-" db:"organization_id"`
+
+	// Name is the name of a project or the title of a task
+	Name string `json:"name" db:"name"`
+
+	// TaskCount is the number of tasks deleted along with the item
+	TaskCount int `json:"task_count" db:"task_count"`
+
+	DeletedAt time.Time  `json:"deleted_at" db:"deleted_at"`
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty" db:"deleted_by"`
+
+	// PurgeAt is when the item is deleted for good
+	PurgeAt time.Time `json:"purge_at" db:"-"`
+}
+
+// TrashListParams represents the parameters for listing the trash of an
+// organization. Tasks without a project are listed for the user who deleted
+// them.
+type TrashListParams struct {
+	OrganizationID uuid.UUID      `query:"-"`
+	UserID         uuid.UUID      `query:"-"`
+	Type           *TrashItemType `query:"type"`
+	Page           int            `query:"page" default:"1"`
+	PageSize       int            `query:"page_size" default:"20"`
+}
+
+// TrashPurgeResult reports what a purge of the trash deleted
+type TrashPurgeResult struct {
+	Projects int `json:"projects"`
+	Tasks    int `json:"tasks"`
+	Files    int `json:"files"`
+}
@@ -15,6 +15,7 @@ type User struct {
 	FirstName    string     `json:"first_name" db:"first_name"`
 	LastName     string     `json:"last_name" db:"last_name"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
+	Timezone     string     `json:"timezone" db:"timezone"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -25,6 +26,7 @@ type UserRegistration struct {
 	Password  string `json:"password" validate:"required,min=8"`
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
+	Timezone  string `json:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
 // UserLogin represents the data needed to log in a user
@@ -40,6 +42,7 @@ type UserResponse struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	IsActive  bool      `json:"is_active"`
+	Timezone  string    `json:"timezone"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -60,6 +63,7 @@ func NewUser(reg UserRegistration) (*User, error) {
 		FirstName:    reg.FirstName,
 		LastName:     reg.LastName,
 		IsActive:     true,
+		Timezone:     reg.Timezone,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}, nil
@@ -79,6 +83,7 @@ func (u *User) ToResponse() UserResponse {
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		IsActive:  u.IsActive,
+		Timezone:  u.Timezone,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
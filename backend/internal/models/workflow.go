@@ -0,0 +1,197 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowStateCategory groups workflow states by how far along a task is
+type WorkflowStateCategory string
+
+// Workflow state categories
+const (
+	WorkflowStateCategoryOpen       WorkflowStateCategory = "open"
+	WorkflowStateCategoryInProgress WorkflowStateCategory = "in_progress"
+	WorkflowStateCategoryClosed     WorkflowStateCategory = "closed"
+)
+
+// Task fields that a workflow transition can require to be set
+const (
+	TaskFieldDescription    = "description"
+	TaskFieldDueDate        = "due_date"
+	TaskFieldAssignedTo     = "assigned_to"
+	TaskFieldEstimatedHours = "estimated_hours"
+	TaskFieldActualHours    = "actual_hours"
+)
+
+// Workflow defines the states a project's tasks can be in and the allowed
+// transitions between them
+type Workflow struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	ProjectID    *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+	Name         string     `json:"name" db:"name"`
+	InitialState TaskStatus `json:"initial_state" db:"initial_state"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Related entities
+	States      []WorkflowState      `json:"states" db:"-"`
+	Transitions []WorkflowTransition `json:"transitions" db:"-"`
+}
+
+// WorkflowState represents a state of a workflow
+type WorkflowState struct {
+	Key      TaskStatus            `json:"key" db:"key" validate:"required,max=50"`
+	Name     string                `json:"name" db:"name" validate:"required,max=100"`
+	Category WorkflowStateCategory `json:"category" db:"category" validate:"required,oneof=open in_progress closed"`
+	Position int                   `json:"position" db:"position"`
+}
+
+// WorkflowTransition represents an allowed move between two workflow states.
+// A transition may require task fields to be set or the user making the move
+// to hold a role in the project's organization.
+type WorkflowTransition struct {
+	FromState      TaskStatus `json:"from_state" db:"from_state" validate:"required,max=50"`
+	ToState        TaskStatus `json:"to_state" db:"to_state" validate:"required,max=50"`
+	RequiredFields []string   `json:"required_fields,omitempty" db:"-" validate:"dive,oneof=description due_date assigned_to estimated_hours actual_hours"`
+	RequiredRole   *string    `json:"required_role,omitempty" db:"required_role" validate:"omitempty,max=100"`
+}
+
+// WorkflowRequest represents the data needed to define a project's workflow
+type WorkflowRequest struct {
+	Name         string               `json:"name" validate:"required,max=255"`
+	InitialState TaskStatus           `json:"initial_state" validate:"required,max=50"`
+	States       []WorkflowState      `json:"states" validate:"required,min=1,dive"`
+	Transitions  []WorkflowTransition `json:"transitions" validate:"dive"`
+}
+
+// NewWorkflow creates a new Workflow for a project from a WorkflowRequest
+func NewWorkflow(projectID uuid.UUID, req WorkflowRequest) *Workflow {
+	now := time.Now()
+	return &Workflow{
+		ID:           uuid.New(),
+		ProjectID:    &projectID,
+		Name:         req.Name,
+		InitialState: req.InitialState,
+		States:       req.States,
+		Transitions:  req.Transitions,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// DefaultWorkflow returns the workflow used by tasks whose project does not
+// define one. It allows every move between the built-in task statuses.
+func DefaultWorkflow() *Workflow {
+	workflow := &Workflow{
+		Name:         "Default",
+		InitialState: TaskStatusTodo,
+		States: []WorkflowState{
+			{Key: TaskStatusTodo, Name: "To Do", Category: WorkflowStateCategoryOpen, Position: 0},
+			{Key: TaskStatusInProgress, Name: "In Progress", Category: WorkflowStateCategoryInProgress, Position: 1},
+			{Key: TaskStatusReview, Name: "Review", Category: WorkflowStateCategoryInProgress, Position: 2},
+			{Key: TaskStatusDone, Name: "Done", Category: WorkflowStateCategoryClosed, Position: 3},
+			{Key: TaskStatusCancelled, Name: "Cancelled", Category: WorkflowStateCategoryClosed, Position: 4},
+		},
+	}
+
+	for _, from := range workflow.States {
+		for _, to := range workflow.States {
+			if from.Key != to.Key {
+				workflow.Transitions = append(workflow.Transitions, WorkflowTransition{FromState: from.Key, ToState: to.Key})
+			}
+		}
+	}
+
+	return workflow
+}
+
+// State returns the state with the given key
+func (w *Workflow) State(key TaskStatus) (*WorkflowState, bool) {
+	for i := range w.States {
+		if w.States[i].Key == key {
+			return &w.States[i], true
+		}
+	}
+	return nil, false
+}
+
+// Transition returns the transition from one state to another, if allowed
+func (w *Workflow) Transition(from, to TaskStatus) (*WorkflowTransition, bool) {
+	for i := range w.Transitions {
+		if w.Transitions[i].FromState == from && w.Transitions[i].ToState == to {
+			return &w.Transitions[i], true
+		}
+	}
+	return nil, false
+}
+
+// IsClosed reports whether status is a state in the closed category
+func (w *Workflow) IsClosed(status TaskStatus) bool {
+	state, ok := w.State(status)
+	return ok && state.Category == WorkflowStateCategoryClosed
+}
+
+// Validate checks that the states and transitions of the workflow are consistent
+func (w *Workflow) Validate() error {
+	if len(w.States) == 0 {
+		return errors.New("workflow must have at least one state")
+	}
+
+	keys := make(map[TaskStatus]bool, len(w.States))
+	for _, state := range w.States {
+		if keys[state.Key] {
+			return fmt.Errorf("duplicate state %q", state.Key)
+		}
+		keys[state.Key] = true
+	}
+
+	if !keys[w.InitialState] {
+		return fmt.Errorf("initial state %q is not a state of the workflow", w.InitialState)
+	}
+
+	type move struct{ from, to TaskStatus }
+	moves := make(map[move]bool, len(w.Transitions))
+	for _, transition := range w.Transitions {
+		if !keys[transition.FromState] || !keys[transition.ToState] {
+			return fmt.Errorf("transition from %q to %q uses an unknown state", transition.FromState, transition.ToState)
+		}
+		if transition.FromState == transition.ToState {
+			return fmt.Errorf("transition from %q to itself is not allowed", transition.FromState)
+		}
+		m := move{transition.FromState, transition.ToState}
+		if moves[m] {
+			return fmt.Errorf("duplicate transition from %q to %q", transition.FromState, transition.ToState)
+		}
+		moves[m] = true
+	}
+
+	return nil
+}
+
+// MissingFields returns the fields required by the transition that are not set on task
+func (t *WorkflowTransition) MissingFields(task *Task) []string {
+	var missing []string
+	for _, field := range t.RequiredFields {
+		var set bool
+		switch field {
+		case TaskFieldDescription:
+			set = task.Description != ""
+		case TaskFieldDueDate:
+			set = task.DueDate != nil
+		case TaskFieldAssignedTo:
+			set = task.AssignedTo != nil
+		case TaskFieldEstimatedHours:
+			set = task.EstimatedHours != nil
+		case TaskFieldActualHours:
+			set = task.ActualHours != nil
+		}
+		if !set {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults and bounds of workloads
+const (
+	DefaultCapacityHoursPerDay = 8
+	DefaultWorkloadDays        = 28
+	MaxWorkloadDays            = 366
+)
+
+// DefaultWorkDays returns the working days of the default capacity, Monday
+// to Friday
+func DefaultWorkDays() []int64 {
+	return []int64{
+		int64(time.Monday),
+		int64(time.Tuesday),
+		int64(time.Wednesday),
+		int64(time.Thursday),
+		int64(time.Friday),
+	}
+}
+
+// WorkloadPeriod represents the length of the periods of a workload
+type WorkloadPeriod string
+
+// Workload periods
+const (
+	WorkloadPeriodDay  WorkloadPeriod = "day"
+	WorkloadPeriodWeek WorkloadPeriod = "week"
+)
+
+// WorkloadCapacity represents the hours a member of an organization can work
+// on each of their working days. Working days are days of the week, from 0
+// (Sunday) to 6 (Saturday).
+type WorkloadCapacity struct {
+	OrganizationID uuid.UUID  `json:"organization_id" db:"organization_id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	HoursPerDay    float64    `json:"hours_per_day" db:"hours_per_day"`
+	WorkDays       []int64    `json:"work_days" db:"-"`
+	UpdatedBy      *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+
+	// Default reports whether the member has the default capacity
+	Default bool `json:"default" db:"-"`
+}
+
+// NewDefaultWorkloadCapacity creates the default capacity of a member
+func NewDefaultWorkloadCapacity(organizationID, userID uuid.UUID) *WorkloadCapacity {
+	return &WorkloadCapacity{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		HoursPerDay:    DefaultCapacityHoursPerDay,
+		WorkDays:       DefaultWorkDays(),
+		Default:        true,
+	}
+}
+
+// IsWorkDay reports whether a day is one of the working days
+func (c *WorkloadCapacity) IsWorkDay(day time.Time) bool {
+	for _, workDay := range c.WorkDays {
+		if time.Weekday(workDay) == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkloadCapacityRequest represents the data needed to set the capacity of
+// a member
+type WorkloadCapacityRequest struct {
+	HoursPerDay float64 `json:"hours_per_day" validate:"min=0,max=24"`
+	WorkDays    []int64 `json:"work_days" validate:"required,min=1,max=7,unique,dive,min=0,max=6"`
+}
+
+// WorkloadTask represents an open task with an estimate, assigned to a
+// member of an organization
+type WorkloadTask struct {
+	TaskID         uuid.UUID    `json:"task_id" db:"id"`
+	ProjectID      uuid.UUID    `json:"project_id" db:"project_id"`
+	Title          string       `json:"title" db:"title"`
+	Priority       TaskPriority `json:"priority" db:"priority"`
+	DueDate        *time.Time   `json:"due_date,omitempty" db:"due_date"`
+	AssignedTo     uuid.UUID    `json:"-" db:"assigned_to"`
+	EstimatedHours float64      `json:"estimated_hours" db:"estimated_hours"`
+	ActualHours    *float64     `json:"actual_hours,omitempty" db:"actual_hours"`
+
+	// RemainingHours are the estimated hours not yet logged
+	RemainingHours float64 `json:"remaining_hours" db:"-"`
+
+	// AllocatedHours are the remaining hours allocated within the workload
+	AllocatedHours float64 `json:"allocated_hours" db:"-"`
+}
+
+// WorkloadTimeOff represents approved time off of a member, from the
+// Qultrix employee records. Dates are inclusive.
+type WorkloadTimeOff struct {
+	UserID    uuid.UUID `db:"user_id"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+}
+
+// WorkloadParams represents the parameters for retrieving a workload
+type WorkloadParams struct {
+	OrganizationID uuid.UUID
+	From           time.Time
+	To             time.Time
+	Period         WorkloadPeriod
+
+	// UserID limits the workload to a member
+	UserID *uuid.UUID
+}
+
+// Workload represents the work allocated to the members of an organization
+// against their capacity, period by period, along with tasks that could be
+// reassigned to relieve the members who are over-allocated
+type Workload struct {
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	Period      WorkloadPeriod       `json:"period"`
+	Members     []WorkloadMember     `json:"members"`
+	Suggestions []WorkloadSuggestion `json:"suggestions"`
+}
+
+// WorkloadMember represents the workload of a member
+type WorkloadMember struct {
+	UserID   uuid.UUID        `json:"user_id"`
+	User     *UserResponse    `json:"user,omitempty"`
+	Capacity WorkloadCapacity `json:"capacity"`
+
+	CapacityHours      float64 `json:"capacity_hours"`
+	AllocatedHours     float64 `json:"allocated_hours"`
+	OverallocatedHours float64 `json:"overallocated_hours"`
+	Overallocated      bool    `json:"overallocated"`
+
+	// UnscheduledHours are the remaining hours of tasks without a due date,
+	// which are not allocated
+	UnscheduledHours float64 `json:"unscheduled_hours"`
+
+	Periods []WorkloadPeriodLoad `json:"periods"`
+	Tasks   []WorkloadTask       `json:"tasks"`
+}
+
+// WorkloadPeriodLoad represents the work allocated to a member in a period
+type WorkloadPeriodLoad struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	CapacityHours  float64 `json:"capacity_hours"`
+	AllocatedHours float64 `json:"allocated_hours"`
+	TimeOffDays    int     `json:"time_off_days"`
+	Overallocated  bool    `json:"overallocated"`
+
+	// Utilization is the allocated hours as a share of the capacity, if the
+	// member has capacity in the period
+	Utilization *float64 `json:"utilization,omitempty"`
+}
+
+// WorkloadSuggestion represents a task that could be reassigned from an
+// over-allocated member to a member with spare capacity
+type WorkloadSuggestion struct {
+	TaskID     uuid.UUID    `json:"task_id"`
+	Title      string       `json:"title"`
+	Priority   TaskPriority `json:"priority"`
+	DueDate    *time.Time   `json:"due_date,omitempty"`
+	FromUserID uuid.UUID    `json:"from_user_id"`
+	ToUserID   uuid.UUID    `json:"to_user_id"`
+
+	// Hours are the hours of the task moved within the workload
+	Hours float64 `json:"hours"`
+}
@@ -3,9 +3,12 @@ package notification
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +17,10 @@ type Service interface {
 	// Create creates a new notification
 	Create(ctx context.Context, notification *models.Notification) error
 	
+	// Send creates a notification as a side effect of another operation,
+	// which a failure to send it does not fail; the failure is logged
+	Send(ctx context.Context, notification *models.Notification)
+	
 	// GetByID retrieves a notification by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.NotificationResponse, error)
 	
@@ -43,6 +50,17 @@ type Service interface {
 	
 	// NotifyTaskOverdue notifies assigned users about overdue tasks
 	NotifyTaskOverdue(ctx context.Context, task *models.Task) error
+	
+	// NotifyTaskUnblocked notifies relevant users that a task is no longer blocked
+	NotifyTaskUnblocked(ctx context.Context, task *models.Task, blocker *models.Task) error
+	
+	// NotifyTasksBulkUpdated notifies a user once about the tasks of a bulk
+	// operation that were assigned to them or whose status changed
+	NotifyTasksBulkUpdated(ctx context.Context, userID, operationID, updatedBy uuid.UUID, assigned, statusChanged []models.Task) error
+	
+	// NotifyTimerStopped notifies a user that their timer on a task was
+	// stopped automatically after running for too long
+	NotifyTimerStopped(ctx context.Context, timeEntry *models.TimeEntry, taskTitle string) error
 }
 
 // serviceImpl implements the Service interface
@@ -189,6 +207,12 @@ func (s *serviceImpl) NotifyTaskDueSoon(ctx context.Context, task *models.Task,
 	// Create notification
 	title := "Task Due Soon"
 	message := fmt.Sprintf("Task '%s' is due in %d days", task.Title, daysUntilDue)
+	switch daysUntilDue {
+	case 0:
+		message = fmt.Sprintf("Task '%s' is due today", task.Title)
+	case 1:
+		message = fmt.Sprintf("Task '%s' is due tomorrow", task.Title)
+	}
 	
 	notification := models.NewNotification(
 		*task.AssignedTo,
@@ -223,3 +247,140 @@ func (s *serviceImpl) NotifyTaskOverdue(ctx context.Context, task *models.Task)
 	
 	return s.notificationRepo.Create(ctx, notification)
 }
+
+// NotifyTaskUnblocked notifies relevant users that a task is no longer blocked
+func (s *serviceImpl) NotifyTaskUnblocked(ctx context.Context, task *models.Task, blocker *models.Task) error {
+	// Notify the creator and the assignee if there is one
+	userIDsToNotify := []uuid.UUID{task.CreatedBy}
+	if task.AssignedTo != nil && *task.AssignedTo != task.CreatedBy {
+		userIDsToNotify = append(userIDsToNotify, *task.AssignedTo)
+	}
+	
+	// Create notifications
+	title := "Task Unblocked"
+	message := fmt.Sprintf("Task '%s' is no longer blocked: '%s' is %s", task.Title, blocker.Title, blocker.Status)
+	
+	for _, userID := range userIDsToNotify {
+		notification := models.NewNotification(
+			userID,
+			models.NotificationTypeTaskUnblocked,
+			title,
+			message,
+			"task",
+			task.ID,
+		)
+		
+		err := s.notificationRepo.Create(ctx, notification)
+		if err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// NotifyTasksBulkUpdated notifies a user once about the tasks of a bulk operation
+func (s *serviceImpl) NotifyTasksBulkUpdated(ctx context.Context, userID, operationID, updatedBy uuid.UUID, assigned, statusChanged []models.Task) error {
+	if len(assigned) == 0 && len(statusChanged) == 0 {
+		return nil
+	}
+	
+	// Get the user who made the changes
+	updater, err := s.userRepo.GetByID(ctx, updatedBy)
+	if err != nil {
+		return err
+	}
+	
+	// Create notification
+	var changes []string
+	if len(assigned) > 0 {
+		changes = append(changes, fmt.Sprintf("assigned you %s", summarizeTasks(assigned)))
+	}
+	if len(statusChanged) > 0 {
+		changes = append(changes, fmt.Sprintf("moved %s to %s", summarizeTasks(statusChanged), statusChanged[0].Status))
+	}
+	
+	title := "Tasks Updated"
+	message := fmt.Sprintf("%s %s %s", updater.FirstName, updater.LastName, strings.Join(changes, " and "))
+	
+	notification := models.NewNotification(
+		userID,
+		models.NotificationTypeTaskBulkUpdate,
+		title,
+		message,
+		"task_bulk_operation",
+		operationID,
+	)
+	
+	return s.notificationRepo.Create(ctx, notification)
+}
+
+// NotifyTimerStopped notifies a user that their timer on a task was stopped
+// automatically
+func (s *serviceImpl) NotifyTimerStopped(ctx context.Context, timeEntry *models.TimeEntry, taskTitle string) error {
+	minutes := 0
+	if timeEntry.DurationMinutes != nil {
+		minutes = *timeEntry.DurationMinutes
+	}
+	
+	// Create notification
+	title := "Timer Stopped"
+	message := fmt.Sprintf("Your timer on task '%s' was stopped after running for %s. Edit the time entry if you worked longer.", taskTitle, formatMinutes(minutes))
+	
+	notification := models.NewNotification(
+		timeEntry.UserID,
+		models.NotificationTypeTimerStopped,
+		title,
+		message,
+		"time_entry",
+		timeEntry.ID,
+	)
+	
+	return s.notificationRepo.Create(ctx, notification)
+}
+
+// formatMinutes describes a number of minutes in hours and minutes
+func formatMinutes(minutes int) string {
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// summarizeTasks describes a list of tasks by count and the first few titles
+func summarizeTasks(tasks []models.Task) string {
+	const maxTitles = 3
+	
+	titles := make([]string, 0, maxTitles)
+	for i, task := range tasks {
+		if i == maxTitles {
+			break
+		}
+		titles = append(titles, fmt.Sprintf("'%s'", task.Title))
+	}
+	summary := strings.Join(titles, ", ")
+	if len(tasks) > maxTitles {
+		summary += fmt.Sprintf(" and %d more", len(tasks)-maxTitles)
+	}
+	
+	if len(tasks) == 1 {
+		return "1 task (" + summary + ")"
+	}
+	return fmt.Sprintf("%d tasks (%s)", len(tasks), summary)
+}
+
+// Send creates a notification, logging a failure rather than returning it
+func (s *serviceImpl) Send(ctx context.Context, notification *models.Notification) {
+	if err := s.Create(ctx, notification); err != nil {
+		logger.FromContext(ctx).Error("failed to send notification",
+			slog.String("resource_type", notification.ResourceType),
+			slog.String("resource_id", notification.ResourceID.String()),
+			slog.String("recipient_id", notification.UserID.String()),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -74,19 +74,22 @@ type Service interface {
 
 // serviceImpl implements the Service interface
 type serviceImpl struct {
-	roleRepo repository.RoleRepository
-	userRepo repository.UserRepository
+	roleRepo  repository.RoleRepository
+	userRepo  repository.UserRepository
+	txManager repository.TxManager
 }
 
 // NewService creates a new RBAC service
-func NewService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) Service {
+func NewService(roleRepo repository.RoleRepository, userRepo repository.UserRepository, txManager repository.TxManager) Service {
 	return &serviceImpl{
-		roleRepo: roleRepo,
-		userRepo: userRepo,
+		roleRepo:  roleRepo,
+		userRepo:  userRepo,
+		txManager: txManager,
 	}
 }
 
-// CreateRole creates a new role
+// CreateRole creates a new role.
+// The role and its permissions are created atomically.
 func (s *serviceImpl) CreateRole(ctx context.Context, req models.RoleRequest) (*models.RoleResponse, error) {
 	// Create role
 	now := time.Now()
@@ -98,29 +101,35 @@ func (s *serviceImpl) CreateRole(ctx context.Context, req models.RoleRequest) (*
 		UpdatedAt:   now,
 	}
 
-	// Create role
-	err := s.roleRepo.CreateRole(ctx, role)
-	if err != nil {
-		if errors.Is(err, repository.ErrRoleNameExists) {
-			return nil, ErrRoleNameExists
-		}
-		return nil, err
-	}
-
-	// Assign permissions to role
-	for _, permissionName := range req.Permissions {
-		permission, err := s.roleRepo.GetPermissionByName(ctx, permissionName)
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		err := s.roleRepo.CreateRole(ctx, role)
 		if err != nil {
-			if errors.Is(err, repository.ErrPermissionNotFound) {
-				return nil, ErrPermissionNotFound
+			if errors.Is(err, repository.ErrRoleNameExists) {
+				return ErrRoleNameExists
 			}
-			return nil, err
+			return err
 		}
 
-		err = s.roleRepo.AssignPermissionToRole(ctx, role.ID, permission.ID)
-		if err != nil {
-			return nil, err
+		// Assign permissions to role
+		for _, permissionName := range req.Permissions {
+			permission, err := s.roleRepo.GetPermissionByName(ctx, permissionName)
+			if err != nil {
+				if errors.Is(err, repository.ErrPermissionNotFound) {
+					return ErrPermissionNotFound
+				}
+				return err
+			}
+
+			err = s.roleRepo.AssignPermissionToRole(ctx, role.ID, permission.ID)
+			if err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Get role with permissions
@@ -177,77 +186,85 @@ func (s *serviceImpl) ListRoles(ctx context.Context) ([]models.RoleResponse, err
 	return responses, nil
 }
 
-// UpdateRole updates a role
+// UpdateRole updates a role.
+// The role and its permissions are updated atomically.
 func (s *serviceImpl) UpdateRole(ctx context.Context, id uuid.UUID, req models.RoleRequest) (*models.RoleResponse, error) {
-	// Get role
-	role, err := s.roleRepo.GetRoleByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, repository.ErrRoleNotFound) {
-			return nil, ErrRoleNotFound
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Get role
+		role, err := s.roleRepo.GetRoleByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	// Update role
-	role.Name = req.Name
-	role.Description = req.Description
-	role.UpdatedAt = time.Now()
+		// Update role
+		role.Name = req.Name
+		role.Description = req.Description
+		role.UpdatedAt = time.Now()
 
-	err = s.roleRepo.UpdateRole(ctx, role)
-	if err != nil {
-		if errors.Is(err, repository.ErrRoleNameExists) {
-			return nil, ErrRoleNameExists
+		err = s.roleRepo.UpdateRole(ctx, role)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoleNameExists) {
+				return ErrRoleNameExists
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	// Get current permissions
-	currentPermissions, err := s.roleRepo.GetRolePermissions(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+		// Get current permissions
+		currentPermissions, err := s.roleRepo.GetRolePermissions(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	// Create maps for easier comparison
-	currentPermissionMap := make(map[string]uuid.UUID)
-	for _, p := range currentPermissions {
-		currentPermissionMap[p.Name] = p.ID
-	}
+		// Create maps for easier comparison
+		currentPermissionMap := make(map[string]uuid.UUID)
+		for _, p := range currentPermissions {
+			currentPermissionMap[p.Name] = p.ID
+		}
 
-	newPermissionMap := make(map[string]bool)
-	for _, p := range req.Permissions {
-		newPermissionMap[p] = true
-	}
+		newPermissionMap := make(map[string]bool)
+		for _, p := range req.Permissions {
+			newPermissionMap[p] = true
+		}
 
-	// Remove permissions that are no longer needed
-	for _, p := range currentPermissions {
-		if !newPermissionMap[p.Name] {
-			err = s.roleRepo.RemovePermissionFromRole(ctx, id, p.ID)
-			if err != nil {
-				return nil, err
+		// Remove permissions that are no longer needed
+		for _, p := range currentPermissions {
+			if !newPermissionMap[p.Name] {
+				err = s.roleRepo.RemovePermissionFromRole(ctx, id, p.ID)
+				if err != nil {
+					return err
+				}
 			}
 		}
-	}
 
-	// Add new permissions
-	for _, permissionName := range req.Permissions {
-		if _, exists := currentPermissionMap[permissionName]; !exists {
-			permission, err := s.roleRepo.GetPermissionByName(ctx, permissionName)
-			if err != nil {
-				if errors.Is(err, repository.ErrPermissionNotFound) {
-					return nil, ErrPermissionNotFound
+		// Add new permissions
+		for _, permissionName := range req.Permissions {
+			if _, exists := currentPermissionMap[permissionName]; !exists {
+				permission, err := s.roleRepo.GetPermissionByName(ctx, permissionName)
+				if err != nil {
+					if errors.Is(err, repository.ErrPermissionNotFound) {
+						return ErrPermissionNotFound
+					}
+					return err
 				}
-				return nil, err
-			}
 
-			err = s.roleRepo.AssignPermissionToRole(ctx, id, permission.ID)
-			if err != nil {
-				return nil, err
+				err = s.roleRepo.AssignPermissionToRole(ctx, id, permission.ID)
+				if err != nil {
+					return err
+				}
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Get updated role
-	role, err = s.roleRepo.GetRoleByID(ctx, id)
+	role, err := s.roleRepo.GetRoleByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AnalyticsRepository defines the interface for the data analytics are
+// derived from
+type AnalyticsRepository interface {
+	// ListTimelines retrieves the tasks matching a filter with their status
+	// and sprint changes
+	ListTimelines(ctx context.Context, filter models.TaskTimelineFilter) ([]models.TaskTimeline, error)
+
+	// Stamp returns a value that changes whenever a task, sprint or the
+	// workflow of a project changes
+	Stamp(ctx context.Context, projectID uuid.UUID) (string, error)
+}
+
+// PostgresAnalyticsRepository implements AnalyticsRepository using PostgreSQL
+type PostgresAnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresAnalyticsRepository creates a new PostgresAnalyticsRepository
+func NewPostgresAnalyticsRepository(db *sqlx.DB) AnalyticsRepository {
+	return &PostgresAnalyticsRepository{db: db}
+}
+
+// ListTimelines retrieves the tasks matching a filter with their status and
+// sprint changes
+func (r *PostgresAnalyticsRepository) ListTimelines(ctx context.Context, filter models.TaskTimelineFilter) ([]models.TaskTimeline, error) {
+	filters := []string{"t.deleted_at IS NULL"}
+	args := []interface{}{}
+
+	if filter.ProjectID != nil {
+		args = append(args, *filter.ProjectID)
+		filters = append(filters, fmt.Sprintf("t.project_id = $%d", len(args)))
+	}
+
+	if filter.UpdatedSince != nil {
+		args = append(args, *filter.UpdatedSince)
+		filters = append(filters, fmt.Sprintf("t.updated_at >= $%d", len(args)))
+	}
+
+	if filter.SprintIDs != nil {
+		ids := make([]string, 0, len(filter.SprintIDs))
+		for _, id := range filter.SprintIDs {
+			ids = append(ids, id.String())
+		}
+		args = append(args, pq.Array(ids))
+		filters = append(filters, fmt.Sprintf(`(t.sprint_id::text = ANY($%d::text[]) OR EXISTS (
+			SELECT 1 FROM taskodex.task_history h
+			WHERE h.task_id = t.id AND h.field_name = '%s'
+				AND (h.old_value = ANY($%d::text[]) OR h.new_value = ANY($%d::text[]))
+		))`, len(args), models.TaskFieldSprintID, len(args), len(args)))
+	}
+
+	query := `
+		SELECT t.id, t.status, t.sprint_id, t.estimated_hours, t.created_at
+		FROM taskodex.tasks t
+	`
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY t.created_at, t.id"
+
+	timelines := []models.TaskTimeline{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timelines, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task timelines: %w", err)
+	}
+	if len(timelines) == 0 {
+		return timelines, nil
+	}
+
+	// Load the status and sprint changes of the tasks
+	ids := make([]uuid.UUID, 0, len(timelines))
+	index := make(map[uuid.UUID]int, len(timelines))
+	for i, timeline := range timelines {
+		ids = append(ids, timeline.TaskID)
+		index[timeline.TaskID] = i
+	}
+
+	changes := []models.TaskHistory{}
+	err = conn(ctx, r.db).SelectContext(
+		ctx,
+		&changes,
+		`SELECT id, task_id, user_id, action, field_name, old_value, new_value, created_at
+		FROM taskodex.task_history
+		WHERE task_id = ANY($1::uuid[]) AND field_name IN ($2, $3)
+		ORDER BY created_at, id`,
+		pq.Array(ids),
+		models.TaskFieldStatus,
+		models.TaskFieldSprintID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task changes: %w", err)
+	}
+
+	for _, change := range changes {
+		timeline := &timelines[index[change.TaskID]]
+		timeline.Changes = append(timeline.Changes, change)
+	}
+
+	return timelines, nil
+}
+
+// Stamp returns a value that changes whenever a task, sprint or the workflow
+// of a project changes. Changes update the time a row was updated, and
+// deletions and tasks moved to another project the number of rows.
+func (r *PostgresAnalyticsRepository) Stamp(ctx context.Context, projectID uuid.UUID) (string, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) || '/' || COALESCE(MAX(updated_at)::text, '')
+				FROM taskodex.tasks WHERE project_id = $1 AND deleted_at IS NULL) || '|' ||
+			(SELECT COUNT(*) || '/' || COALESCE(MAX(updated_at)::text, '')
+				FROM taskodex.sprints WHERE project_id = $1) || '|' ||
+			COALESCE((SELECT updated_at::text FROM taskodex.workflows WHERE project_id = $1), '')
+	`
+
+	var stamp string
+	if err := conn(ctx, r.db).GetContext(ctx, &stamp, query, projectID); err != nil {
+		return "", fmt.Errorf("failed to get analytics stamp: %w", err)
+	}
+
+	return stamp, nil
+}
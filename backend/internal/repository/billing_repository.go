@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for billing repository
+var (
+	ErrBillingRateNotFound = errors.New("billing rate not found")
+	ErrBillingRateScope    = errors.New("user or role of billing rate not found")
+)
+
+// billingRateLockPrefix prefixes the key of the advisory lock that serializes
+// changes to the rate card of a project
+const billingRateLockPrefix = "taskodex.billing_rates:"
+
+// billingRateColumns are the columns of a billing rate
+const billingRateColumns = `
+	id, project_id, user_id, role_id, hourly_rate, effective_from, effective_to,
+	created_by, created_at, updated_at
+`
+
+// BillingRepository defines the interface for billing data access
+type BillingRepository interface {
+	// GetSettings retrieves the billing settings of a project, or the
+	// defaults if it has none
+	GetSettings(ctx context.Context, projectID uuid.UUID) (*models.BillingSettings, error)
+
+	// SaveSettings creates or replaces the billing settings of a project
+	SaveSettings(ctx context.Context, settings *models.BillingSettings) error
+
+	// CreateRate creates a new rate
+	CreateRate(ctx context.Context, rate *models.BillingRate) error
+
+	// GetRate retrieves a rate by ID
+	GetRate(ctx context.Context, id uuid.UUID) (*models.BillingRate, error)
+
+	// ListRates retrieves the rate card of a project, oldest first
+	ListRates(ctx context.Context, projectID uuid.UUID) ([]models.BillingRate, error)
+
+	// UpdateRate updates a rate
+	UpdateRate(ctx context.Context, rate *models.BillingRate) error
+
+	// DeleteRate deletes a rate
+	DeleteRate(ctx context.Context, id uuid.UUID) error
+
+	// LockRates serializes changes to the rate card of a project until the
+	// transaction in ctx ends
+	LockRates(ctx context.Context, projectID uuid.UUID) error
+
+	// ListEntries retrieves the finished time entries of the period of a
+	// report, on the projects of its organization
+	ListEntries(ctx context.Context, params models.BillingReportParams) ([]models.BillingEntry, error)
+
+	// ListMemberRoles retrieves the roles of the members of an organization,
+	// keyed by user ID
+	ListMemberRoles(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+}
+
+// PostgresBillingRepository implements BillingRepository using PostgreSQL
+type PostgresBillingRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresBillingRepository creates a new PostgresBillingRepository
+func NewPostgresBillingRepository(db *sqlx.DB) BillingRepository {
+	return &PostgresBillingRepository{db: db}
+}
+
+// GetSettings retrieves the billing settings of a project
+func (r *PostgresBillingRepository) GetSettings(ctx context.Context, projectID uuid.UUID) (*models.BillingSettings, error) {
+	query := `
+		SELECT project_id, client_name, TRIM(currency) AS currency, rounding_minutes,
+			rounding_mode, updated_by, updated_at
+		FROM taskodex.project_billing_settings
+		WHERE project_id = $1
+	`
+
+	var settings models.BillingSettings
+	err := conn(ctx, r.db).GetContext(ctx, &settings, query, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DefaultBillingSettings(projectID), nil
+		}
+		return nil, fmt.Errorf("failed to get billing settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the billing settings of a project
+func (r *PostgresBillingRepository) SaveSettings(ctx context.Context, settings *models.BillingSettings) error {
+	query := `
+		INSERT INTO taskodex.project_billing_settings (
+			project_id, client_name, currency, rounding_minutes, rounding_mode,
+			updated_by, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (project_id) DO UPDATE
+		SET client_name = EXCLUDED.client_name, currency = EXCLUDED.currency,
+			rounding_minutes = EXCLUDED.rounding_minutes, rounding_mode = EXCLUDED.rounding_mode,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	now := time.Now()
+	settings.UpdatedAt = &now
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		settings.ProjectID,
+		settings.ClientName,
+		settings.Currency,
+		settings.RoundingMinutes,
+		settings.RoundingMode,
+		settings.UpdatedBy,
+		settings.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save billing settings: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRate creates a new rate
+func (r *PostgresBillingRepository) CreateRate(ctx context.Context, rate *models.BillingRate) error {
+	query := `
+		INSERT INTO taskodex.billing_rates (
+			id, project_id, user_id, role_id, hourly_rate, effective_from, effective_to,
+			created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		rate.ID,
+		rate.ProjectID,
+		rate.UserID,
+		rate.RoleID,
+		rate.HourlyRate,
+		rate.EffectiveFrom.Format(models.AnalyticsDateFormat),
+		billingDate(rate.EffectiveTo),
+		rate.CreatedBy,
+		rate.CreatedAt,
+		rate.UpdatedAt,
+	)
+	if err != nil {
+		if isBillingScopeViolation(err) {
+			return ErrBillingRateScope
+		}
+		return fmt.Errorf("failed to insert billing rate: %w", err)
+	}
+
+	return nil
+}
+
+// GetRate retrieves a rate by ID
+func (r *PostgresBillingRepository) GetRate(ctx context.Context, id uuid.UUID) (*models.BillingRate, error) {
+	query := "SELECT " + billingRateColumns + " FROM taskodex.billing_rates WHERE id = $1"
+
+	var rate models.BillingRate
+	err := conn(ctx, r.db).GetContext(ctx, &rate, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBillingRateNotFound
+		}
+		return nil, fmt.Errorf("failed to get billing rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// ListRates retrieves the rate card of a project
+func (r *PostgresBillingRepository) ListRates(ctx context.Context, projectID uuid.UUID) ([]models.BillingRate, error) {
+	query := "SELECT " + billingRateColumns + `
+		FROM taskodex.billing_rates
+		WHERE project_id = $1
+		ORDER BY effective_from, created_at, id
+	`
+
+	rates := []models.BillingRate{}
+	err := conn(ctx, r.db).SelectContext(ctx, &rates, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billing rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// UpdateRate updates a rate
+func (r *PostgresBillingRepository) UpdateRate(ctx context.Context, rate *models.BillingRate) error {
+	query := `
+		UPDATE taskodex.billing_rates
+		SET user_id = $1, role_id = $2, hourly_rate = $3, effective_from = $4,
+			effective_to = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	rate.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		rate.UserID,
+		rate.RoleID,
+		rate.HourlyRate,
+		rate.EffectiveFrom.Format(models.AnalyticsDateFormat),
+		billingDate(rate.EffectiveTo),
+		rate.UpdatedAt,
+		rate.ID,
+	)
+	if err != nil {
+		if isBillingScopeViolation(err) {
+			return ErrBillingRateScope
+		}
+		return fmt.Errorf("failed to update billing rate: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrBillingRateNotFound
+	}
+
+	return nil
+}
+
+// DeleteRate deletes a rate
+func (r *PostgresBillingRepository) DeleteRate(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.billing_rates WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete billing rate: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrBillingRateNotFound
+	}
+
+	return nil
+}
+
+// ListEntries retrieves the finished time entries of the period of a report.
+// Entries belong to the day they start on; those of trashed projects are
+// left out.
+func (r *PostgresBillingRepository) ListEntries(ctx context.Context, params models.BillingReportParams) ([]models.BillingEntry, error) {
+	filters := []string{
+		"p.organization_id = $1",
+		"p.deleted_at IS NULL",
+		"te.start_time >= $2",
+		"te.start_time < $3",
+		"(te.end_time IS NOT NULL OR te.duration_minutes IS NOT NULL)",
+	}
+	args := []interface{}{params.OrganizationID, params.From, params.To.AddDate(0, 0, 1)}
+
+	if params.ProjectID != nil {
+		filters = append(filters, "p.id = $4")
+		args = append(args, *params.ProjectID)
+	}
+
+	query := `
+		SELECT te.id, p.id AS project_id, p.name AS project_name, te.user_id,
+			TRIM(CONCAT_WS(' ', u.first_name, u.last_name)) AS user_name, te.start_time,
+			COALESCE(te.duration_minutes, EXTRACT(EPOCH FROM (te.end_time - te.start_time))/60)::INTEGER AS minutes,
+			te.billable
+		FROM taskodex.task_time_entries te
+		JOIN taskodex.tasks t ON t.id = te.task_id
+		JOIN taskodex.projects p ON p.id = t.project_id
+		JOIN users u ON u.id = te.user_id
+		WHERE ` + strings.Join(filters, " AND ") + `
+		ORDER BY p.name, p.id, te.start_time, te.id
+	`
+
+	entries := []models.BillingEntry{}
+	err := conn(ctx, r.db).SelectContext(ctx, &entries, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billing entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// LockRates serializes changes to the rate card of a project
+func (r *PostgresBillingRepository) LockRates(ctx context.Context, projectID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", billingRateLockPrefix+projectID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock billing rates: %w", err)
+	}
+
+	return nil
+}
+
+// ListMemberRoles retrieves the roles of the members of an organization
+func (r *PostgresBillingRepository) ListMemberRoles(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	query := `
+		SELECT user_id, role_id
+		FROM user_roles
+		WHERE organization_id = $1
+	`
+
+	var rows []struct {
+		UserID uuid.UUID `db:"user_id"`
+		RoleID uuid.UUID `db:"role_id"`
+	}
+	err := conn(ctx, r.db).SelectContext(ctx, &rows, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member roles: %w", err)
+	}
+
+	roles := make(map[uuid.UUID][]uuid.UUID)
+	for _, row := range rows {
+		roles[row.UserID] = append(roles[row.UserID], row.RoleID)
+	}
+
+	return roles, nil
+}
+
+// billingDate formats an optional day as a DATE parameter
+func billingDate(day *time.Time) interface{} {
+	if day == nil {
+		return nil
+	}
+	return day.Format(models.AnalyticsDateFormat)
+}
+
+// isBillingScopeViolation reports whether an error is a rate referring to a
+// user or role that does not exist
+func isBillingScopeViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Constraint == "fk_billing_rates_user" || pqErr.Constraint == "fk_billing_rates_role")
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// boardLockPrefix prefixes the key of the advisory lock that serializes rank
+// changes in a project
+const boardLockPrefix = "taskodex.board:"
+
+// BoardRepository defines the interface for board data access
+type BoardRepository interface {
+	// Lock serializes changes to the ranks of a project's tasks until the
+	// transaction in ctx ends
+	Lock(ctx context.Context, projectID uuid.UUID) error
+
+	// ListTasks retrieves the tasks of a board in rank order, up to limit
+	// tasks per status, and the number of tasks in each status
+	ListTasks(ctx context.Context, scope models.BoardScope, limit int) ([]models.Task, map[models.TaskStatus]int, error)
+
+	// RankAfter retrieves the lowest rank in a project above rank, or an
+	// empty string if there is none
+	RankAfter(ctx context.Context, projectID uuid.UUID, rank string) (string, error)
+
+	// RankBefore retrieves the highest rank in a project below rank, or
+	// below all ranks if rank is empty. It returns an empty string if there
+	// is none.
+	RankBefore(ctx context.Context, projectID uuid.UUID, rank string) (string, error)
+
+	// SetRank sets the rank of a task
+	SetRank(ctx context.Context, taskID uuid.UUID, rank string) error
+}
+
+// PostgresBoardRepository implements BoardRepository using PostgreSQL
+type PostgresBoardRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresBoardRepository creates a new PostgresBoardRepository
+func NewPostgresBoardRepository(db *sqlx.DB) BoardRepository {
+	return &PostgresBoardRepository{db: db}
+}
+
+// Lock serializes changes to the ranks of a project's tasks
+func (r *PostgresBoardRepository) Lock(ctx context.Context, projectID uuid.UUID) error {
+	return lockBoard(ctx, r.db, projectID)
+}
+
+// ListTasks retrieves the tasks of a board in rank order
+func (r *PostgresBoardRepository) ListTasks(ctx context.Context, scope models.BoardScope, limit int) ([]models.Task, map[models.TaskStatus]int, error) {
+	filter := ""
+	args := []interface{}{scope.ProjectID, limit}
+	switch {
+	case scope.SprintID != nil:
+		filter = "AND t.sprint_id = $3"
+		args = append(args, *scope.SprintID)
+	case scope.Backlog:
+		filter = "AND t.sprint_id IS NULL"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT t.id, t.project_id, t.parent_id, t.sprint_id, t.rank, t.title, t.description, t.status, t.priority,
+			t.due_date, t.start_date, t.duration_days, t.created_by, t.assigned_to, t.estimated_hours,
+			t.actual_hours, t.custom_fields, t.created_at, t.updated_at, t.column_total
+		FROM (
+			SELECT t.*,
+				ROW_NUMBER() OVER (PARTITION BY t.status ORDER BY t.rank, t.id) AS column_position,
+				COUNT(*) OVER (PARTITION BY t.status) AS column_total
+			FROM taskodex.tasks t
+			WHERE t.project_id = $1 AND t.deleted_at IS NULL %s
+		) t
+		WHERE t.column_position <= $2
+		ORDER BY t.status, t.rank, t.id
+	`, filter)
+
+	var rows []struct {
+		models.Task
+		ColumnTotal int `db:"column_total"`
+	}
+	err := conn(ctx, r.db).SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query board tasks: %w", err)
+	}
+
+	tasks := make([]models.Task, 0, len(rows))
+	taskIDs := make([]uuid.UUID, 0, len(rows))
+	totals := make(map[models.TaskStatus]int)
+	for _, row := range rows {
+		tasks = append(tasks, row.Task)
+		taskIDs = append(taskIDs, row.ID)
+		totals[row.Status] = row.ColumnTotal
+	}
+
+	// Get the tags of all tasks at once
+	var tags []struct {
+		TaskID uuid.UUID `db:"task_id"`
+		Tag    string    `db:"tag"`
+	}
+	err = conn(ctx, r.db).SelectContext(
+		ctx,
+		&tags,
+		"SELECT task_id, tag FROM taskodex.task_tags WHERE task_id = ANY($1::uuid[]) ORDER BY tag",
+		pq.Array(taskIDs),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tagsByTask := make(map[uuid.UUID][]string)
+	for _, tag := range tags {
+		tagsByTask[tag.TaskID] = append(tagsByTask[tag.TaskID], tag.Tag)
+	}
+	for i := range tasks {
+		tasks[i].Tags = tagsByTask[tasks[i].ID]
+		if tasks[i].Tags == nil {
+			tasks[i].Tags = []string{}
+		}
+	}
+
+	return tasks, totals, nil
+}
+
+// RankAfter retrieves the lowest rank in a project above rank
+func (r *PostgresBoardRepository) RankAfter(ctx context.Context, projectID uuid.UUID, rank string) (string, error) {
+	var next sql.NullString
+	err := conn(ctx, r.db).GetContext(
+		ctx,
+		&next,
+		"SELECT MIN(rank) FROM taskodex.tasks WHERE project_id = $1 AND rank > $2",
+		projectID,
+		rank,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next rank: %w", err)
+	}
+
+	return next.String, nil
+}
+
+// RankBefore retrieves the highest rank in a project below rank
+func (r *PostgresBoardRepository) RankBefore(ctx context.Context, projectID uuid.UUID, rank string) (string, error) {
+	return rankBefore(ctx, r.db, &projectID, rank)
+}
+
+// SetRank sets the rank of a task
+func (r *PostgresBoardRepository) SetRank(ctx context.Context, taskID uuid.UUID, rank string) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE taskodex.tasks SET rank = $1, updated_at = $2 WHERE id = $3",
+		rank,
+		time.Now(),
+		taskID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set task rank: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// lockBoard takes the advisory lock on the ranks of a project's tasks, held
+// until the transaction in ctx ends
+func lockBoard(ctx context.Context, db *sqlx.DB, projectID uuid.UUID) error {
+	_, err := conn(ctx, db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", boardLockPrefix+projectID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock board: %w", err)
+	}
+
+	return nil
+}
+
+// rankBefore retrieves the highest rank below rank among the tasks of a
+// project, or of the tasks without a project if projectID is nil
+func rankBefore(ctx context.Context, db *sqlx.DB, projectID *uuid.UUID, rank string) (string, error) {
+	var previous sql.NullString
+	err := conn(ctx, db).GetContext(
+		ctx,
+		&previous,
+		"SELECT MAX(rank) FROM taskodex.tasks WHERE project_id IS NOT DISTINCT FROM $1 AND ($2 = '' OR rank < $2)",
+		projectID,
+		rank,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get previous rank: %w", err)
+	}
+
+	return previous.String, nil
+}
+
+// nextRank returns the rank of a task added last to a project. It must be
+// called in a transaction, which holds the project's board lock until the
+// task is inserted.
+func nextRank(ctx context.Context, db *sqlx.DB, projectID *uuid.UUID) (string, error) {
+	if projectID != nil {
+		if err := lockBoard(ctx, db, *projectID); err != nil {
+			return "", err
+		}
+	}
+
+	last, err := rankBefore(ctx, db, projectID, "")
+	if err != nil {
+		return "", err
+	}
+
+	return models.RankBetween(last, "")
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for checklist item repository
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+)
+
+// ChecklistItemRepository defines the interface for checklist item data access
+type ChecklistItemRepository interface {
+	// Create creates a new checklist item. A negative position appends the item.
+	Create(ctx context.Context, item *models.ChecklistItem) error
+
+	// GetByID retrieves a checklist item by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ChecklistItem, error)
+
+	// ListByTask retrieves the checklist items of a task in order
+	ListByTask(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error)
+
+	// ListByTasks retrieves the checklist items of several tasks, keyed by task ID
+	ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.ChecklistItem, error)
+
+	// Update updates a checklist item
+	Update(ctx context.Context, item *models.ChecklistItem) error
+
+	// Delete deletes a checklist item
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PostgresChecklistItemRepository implements ChecklistItemRepository using PostgreSQL
+type PostgresChecklistItemRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresChecklistItemRepository creates a new PostgresChecklistItemRepository
+func NewPostgresChecklistItemRepository(db *sqlx.DB) ChecklistItemRepository {
+	return &PostgresChecklistItemRepository{db: db}
+}
+
+// Create creates a new checklist item
+func (r *PostgresChecklistItemRepository) Create(ctx context.Context, item *models.ChecklistItem) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		// Check if task exists
+		var exists bool
+		err := conn(ctx, r.db).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM taskodex.tasks WHERE id = $1 AND deleted_at IS NULL)", item.TaskID)
+		if err != nil {
+			return fmt.Errorf("failed to check if task exists: %w", err)
+		}
+		if !exists {
+			return ErrTaskNotFound
+		}
+
+		// Append to the end of the checklist
+		if item.Position < 0 {
+			err = conn(ctx, r.db).GetContext(
+				ctx,
+				&item.Position,
+				"SELECT COALESCE(MAX(position) + 1, 0) FROM taskodex.task_checklist_items WHERE task_id = $1",
+				item.TaskID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to get checklist position: %w", err)
+			}
+		}
+
+		query := `
+			INSERT INTO taskodex.task_checklist_items (
+				id, task_id, title, is_completed, position, completed_at, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			item.ID,
+			item.TaskID,
+			item.Title,
+			item.IsCompleted,
+			item.Position,
+			item.CompletedAt,
+			item.CreatedAt,
+			item.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert checklist item: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a checklist item by ID
+func (r *PostgresChecklistItemRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ChecklistItem, error) {
+	query := `
+		SELECT id, task_id, title, is_completed, position, completed_at, created_at, updated_at
+		FROM taskodex.task_checklist_items
+		WHERE id = $1
+	`
+
+	var item models.ChecklistItem
+	err := conn(ctx, r.db).GetContext(ctx, &item, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChecklistItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get checklist item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// ListByTask retrieves the checklist items of a task in order
+func (r *PostgresChecklistItemRepository) ListByTask(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	query := `
+		SELECT id, task_id, title, is_completed, position, completed_at, created_at, updated_at
+		FROM taskodex.task_checklist_items
+		WHERE task_id = $1
+		ORDER BY position, created_at
+	`
+
+	items := []models.ChecklistItem{}
+	err := conn(ctx, r.db).SelectContext(ctx, &items, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checklist items: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListByTasks retrieves the checklist items of several tasks, keyed by task ID
+func (r *PostgresChecklistItemRepository) ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.ChecklistItem, error) {
+	result := make(map[uuid.UUID][]models.ChecklistItem, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+		SELECT id, task_id, title, is_completed, position, completed_at, created_at, updated_at
+		FROM taskodex.task_checklist_items
+		WHERE task_id = ANY($1::uuid[])
+		ORDER BY task_id, position, created_at
+	`
+
+	var items []models.ChecklistItem
+	err := conn(ctx, r.db).SelectContext(ctx, &items, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checklist items: %w", err)
+	}
+
+	for _, item := range items {
+		result[item.TaskID] = append(result[item.TaskID], item)
+	}
+
+	return result, nil
+}
+
+// Update updates a checklist item
+func (r *PostgresChecklistItemRepository) Update(ctx context.Context, item *models.ChecklistItem) error {
+	query := `
+		UPDATE taskodex.task_checklist_items
+		SET title = $1, is_completed = $2, position = $3, completed_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	item.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		item.Title,
+		item.IsCompleted,
+		item.Position,
+		item.CompletedAt,
+		item.UpdatedAt,
+		item.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update checklist item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrChecklistItemNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a checklist item
+func (r *PostgresChecklistItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.task_checklist_items WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete checklist item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrChecklistItemNotFound
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Common errors for comment repository
@@ -28,6 +29,13 @@ type CommentRepository interface {
 	
 	// List retrieves comments based on filter parameters
 	List(ctx context.Context, params models.CommentListParams) ([]models.Comment, int, error)
+
+	// ListByTasks retrieves the comments of several tasks, keyed by task ID
+	ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.Comment, error)
+
+	// GetByIDs retrieves comments by ID with their users and mentions, keyed
+	// by ID. Unknown IDs are left out.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Comment, error)
 	
 	// Update updates a comment
 	Update(ctx context.Context, comment *models.Comment) error
@@ -44,49 +52,57 @@ type CommentRepository interface {
 
 // PostgresCommentRepository implements CommentRepository using PostgreSQL
 type PostgresCommentRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	taskRepo TaskRepository
+	userRepo UserRepository
 }
 
 // NewPostgresCommentRepository creates a new PostgresCommentRepository
 func NewPostgresCommentRepository(db *sqlx.DB) CommentRepository {
-	return &PostgresCommentRepository{db: db}
+	return &PostgresCommentRepository{
+		db:       db,
+		taskRepo: NewPostgresTaskRepository(db),
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new comment
 func (r *PostgresCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
-	query := `
-		INSERT INTO taskodex.task_comments (
-			id, task_id, user_id, content, created_at, updated_at
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		query := `
+			INSERT INTO taskodex.task_comments (
+				id, task_id, user_id, content, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+	
+		_, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			comment.ID,
+			comment.TaskID,
+			comment.UserID,
+			comment.Content,
+			comment.CreatedAt,
+			comment.UpdatedAt,
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
-		comment.ID,
-		comment.TaskID,
-		comment.UserID,
-		comment.Content,
-		comment.CreatedAt,
-		comment.UpdatedAt,
-	)
 	
-	if err != nil {
-		return fmt.Errorf("failed to insert comment: %w", err)
-	}
+		if err != nil {
+			return fmt.Errorf("failed to insert comment: %w", err)
+		}
 	
-	// Add mentions if any
-	if len(comment.Mentions) > 0 {
-		for _, userID := range comment.Mentions {
-			err = r.AddMention(ctx, comment.ID, userID)
-			if err != nil {
-				return fmt.Errorf("failed to add mention: %w", err)
+		// Add mentions if any
+		if len(comment.Mentions) > 0 {
+			for _, userID := range comment.Mentions {
+				err = r.AddMention(ctx, comment.ID, userID)
+				if err != nil {
+					return fmt.Errorf("failed to add mention: %w", err)
+				}
 			}
 		}
-	}
 	
-	return nil
+		return nil
+	})
 }
 
 // GetByID retrieves a comment by ID
@@ -98,7 +114,7 @@ func (r *PostgresCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	`
 	
 	var comment models.Comment
-	err := r.db.GetContext(ctx, &comment, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &comment, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrCommentNotFound
@@ -107,8 +123,7 @@ func (r *PostgresCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	}
 	
 	// Get user
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, comment.UserID)
+	user, err := r.userRepo.GetByID(ctx, comment.UserID)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -117,8 +132,7 @@ func (r *PostgresCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	}
 	
 	// Get task
-	taskRepo := NewPostgresTaskRepository(r.db)
-	task, err := taskRepo.GetByID(ctx, comment.TaskID)
+	task, err := r.taskRepo.GetByID(ctx, comment.TaskID)
 	if err != nil && !errors.Is(err, ErrTaskNotFound) {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
@@ -168,7 +182,7 @@ func (r *PostgresCommentRepository) List(ctx context.Context, params models.Comm
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
 	}
@@ -208,7 +222,7 @@ func (r *PostgresCommentRepository) List(ctx context.Context, params models.Comm
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query comments: %w", err)
 	}
@@ -224,8 +238,7 @@ func (r *PostgresCommentRepository) List(ctx context.Context, params models.Comm
 		}
 		
 		// Get user
-		userRepo := NewPostgresUserRepository(r.db)
-		user, err := userRepo.GetByID(ctx, comment.UserID)
+		user, err := r.userRepo.GetByID(ctx, comment.UserID)
 		if err == nil {
 			comment.User = user
 		}
@@ -244,76 +257,80 @@ func (r *PostgresCommentRepository) List(ctx context.Context, params models.Comm
 
 // Update updates a comment
 func (r *PostgresCommentRepository) Update(ctx context.Context, comment *models.Comment) error {
-	query := `
-		UPDATE taskodex.task_comments
-		SET content = $1, updated_at = $2
-		WHERE id = $3
-	`
-	
-	comment.UpdatedAt = time.Now()
-	
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
-		comment.Content,
-		comment.UpdatedAt,
-		comment.ID,
-	)
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		query := `
+			UPDATE taskodex.task_comments
+			SET content = $1, updated_at = $2
+			WHERE id = $3
+		`
+	
+		comment.UpdatedAt = time.Now()
+	
+		_, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			comment.Content,
+			comment.UpdatedAt,
+			comment.ID,
+		)
 	
-	if err != nil {
-		return fmt.Errorf("failed to update comment: %w", err)
-	}
+		if err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
 	
-	// Update mentions
-	// First, delete all existing mentions
-	deleteMentionsQuery := `
-		DELETE FROM taskodex.comment_mentions
-		WHERE comment_id = $1
-	`
+		// Update mentions
+		// First, delete all existing mentions
+		deleteMentionsQuery := `
+			DELETE FROM taskodex.comment_mentions
+			WHERE comment_id = $1
+		`
 	
-	_, err = r.db.ExecContext(ctx, deleteMentionsQuery, comment.ID)
-	if err != nil {
-		return fmt.Errorf("failed to delete existing mentions: %w", err)
-	}
+		_, err = conn(ctx, r.db).ExecContext(ctx, deleteMentionsQuery, comment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete existing mentions: %w", err)
+		}
 	
-	// Then, add new mentions
-	if len(comment.Mentions) > 0 {
-		for _, userID := range comment.Mentions {
-			err = r.AddMention(ctx, comment.ID, userID)
-			if err != nil {
-				return fmt.Errorf("failed to add mention: %w", err)
+		// Then, add new mentions
+		if len(comment.Mentions) > 0 {
+			for _, userID := range comment.Mentions {
+				err = r.AddMention(ctx, comment.ID, userID)
+				if err != nil {
+					return fmt.Errorf("failed to add mention: %w", err)
+				}
 			}
 		}
-	}
 	
-	return nil
+		return nil
+	})
 }
 
 // Delete deletes a comment
 func (r *PostgresCommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	// First, delete all mentions
-	deleteMentionsQuery := `
-		DELETE FROM taskodex.comment_mentions
-		WHERE comment_id = $1
-	`
-	
-	_, err := r.db.ExecContext(ctx, deleteMentionsQuery, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete mentions: %w", err)
-	}
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		// First, delete all mentions
+		deleteMentionsQuery := `
+			DELETE FROM taskodex.comment_mentions
+			WHERE comment_id = $1
+		`
+	
+		_, err := conn(ctx, r.db).ExecContext(ctx, deleteMentionsQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete mentions: %w", err)
+		}
 	
-	// Then, delete the comment
-	query := `
-		DELETE FROM taskodex.task_comments
-		WHERE id = $1
-	`
+		// Then, delete the comment
+		query := `
+			DELETE FROM taskodex.task_comments
+			WHERE id = $1
+		`
 	
-	_, err = r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
-	}
+		_, err = conn(ctx, r.db).ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
 	
-	return nil
+		return nil
+	})
 }
 
 // AddMention adds a mention to a comment
@@ -325,7 +342,7 @@ func (r *PostgresCommentRepository) AddMention(ctx context.Context, commentID, u
 		VALUES ($1, $2, $3)
 	`
 	
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		commentID,
@@ -340,6 +357,100 @@ func (r *PostgresCommentRepository) AddMention(ctx context.Context, commentID, u
 	return nil
 }
 
+// ListByTasks retrieves the comments of several tasks, keyed by task ID
+func (r *PostgresCommentRepository) ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.Comment, error) {
+	result := make(map[uuid.UUID][]models.Comment, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+		SELECT id, task_id, user_id, content, created_at, updated_at
+		FROM taskodex.task_comments
+		WHERE task_id = ANY($1::uuid[])
+		ORDER BY task_id, created_at
+	`
+
+	var comments []models.Comment
+	err := conn(ctx, r.db).SelectContext(ctx, &comments, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	for _, comment := range comments {
+		result[comment.TaskID] = append(result[comment.TaskID], comment)
+	}
+
+	return result, nil
+}
+
+// GetByIDs retrieves comments by ID with their users and mentions, keyed by ID
+func (r *PostgresCommentRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Comment, error) {
+	result := make(map[uuid.UUID]*models.Comment, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	commentIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		commentIDs = append(commentIDs, id.String())
+	}
+
+	query := `
+		SELECT id, task_id, user_id, content, created_at, updated_at
+		FROM taskodex.task_comments
+		WHERE id = ANY($1::uuid[])
+	`
+
+	var comments []models.Comment
+	err := conn(ctx, r.db).SelectContext(ctx, &comments, query, pq.Array(commentIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(comments))
+	for i := range comments {
+		result[comments[i].ID] = &comments[i]
+		userIDs = append(userIDs, comments[i].UserID)
+	}
+
+	users, err := r.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, comment := range result {
+		comment.User = users[comment.UserID]
+		comment.Mentions = []uuid.UUID{}
+	}
+
+	// Get mentions
+	var mentions []struct {
+		CommentID uuid.UUID `db:"comment_id"`
+		UserID    uuid.UUID `db:"user_id"`
+	}
+	err = conn(ctx, r.db).SelectContext(
+		ctx,
+		&mentions,
+		"SELECT comment_id, user_id FROM taskodex.comment_mentions WHERE comment_id = ANY($1::uuid[])",
+		pq.Array(commentIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+	for _, mention := range mentions {
+		if comment, ok := result[mention.CommentID]; ok {
+			comment.Mentions = append(comment.Mentions, mention.UserID)
+		}
+	}
+
+	return result, nil
+}
+
 // GetMentions retrieves all mentions for a comment
 func (r *PostgresCommentRepository) GetMentions(ctx context.Context, commentID uuid.UUID) ([]uuid.UUID, error) {
 	query := `
@@ -349,7 +460,7 @@ func (r *PostgresCommentRepository) GetMentions(ctx context.Context, commentID u
 	`
 	
 	var mentions []uuid.UUID
-	err := r.db.SelectContext(ctx, &mentions, query, commentID)
+	err := conn(ctx, r.db).SelectContext(ctx, &mentions, query, commentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mentions: %w", err)
 	}
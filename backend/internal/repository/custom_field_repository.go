@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for custom field repository
+var (
+	ErrCustomFieldNotFound  = errors.New("custom field not found")
+	ErrCustomFieldKeyExists = errors.New("custom field key already exists")
+)
+
+// CustomFieldRepository defines the interface for custom field definition data access
+type CustomFieldRepository interface {
+	// Create creates a new custom field definition
+	Create(ctx context.Context, definition *models.CustomFieldDefinition) error
+
+	// GetByID retrieves a custom field definition by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.CustomFieldDefinition, error)
+
+	// List retrieves the definitions of an entity that apply to a project: the
+	// organization-wide ones and the project's own. Without a project, only
+	// the organization-wide definitions are returned.
+	List(ctx context.Context, organizationID uuid.UUID, projectID *uuid.UUID, entity models.CustomFieldEntity) ([]models.CustomFieldDefinition, error)
+
+	// Update updates a custom field definition
+	Update(ctx context.Context, definition *models.CustomFieldDefinition) error
+
+	// Delete deletes a custom field definition and removes its values
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PostgresCustomFieldRepository implements CustomFieldRepository using PostgreSQL
+type PostgresCustomFieldRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresCustomFieldRepository creates a new PostgresCustomFieldRepository
+func NewPostgresCustomFieldRepository(db *sqlx.DB) CustomFieldRepository {
+	return &PostgresCustomFieldRepository{db: db}
+}
+
+// customFieldColumns are the columns loaded for custom field definitions
+const customFieldColumns = `
+	id, organization_id, project_id, entity, key, name, type, options,
+	required, position, created_at, updated_at
+`
+
+// Create creates a new custom field definition.
+// A key can be defined once per organization and entity: a project cannot
+// redefine an organization-wide key, and vice versa.
+func (r *PostgresCustomFieldRepository) Create(ctx context.Context, definition *models.CustomFieldDefinition) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		var exists bool
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&exists,
+			`SELECT EXISTS(
+				SELECT 1 FROM taskodex.custom_field_definitions
+				WHERE organization_id = $1 AND entity = $2 AND key = $3
+					AND (project_id IS NULL OR $4::uuid IS NULL OR project_id = $4)
+			)`,
+			definition.OrganizationID,
+			definition.Entity,
+			definition.Key,
+			definition.ProjectID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check if custom field exists: %w", err)
+		}
+		if exists {
+			return ErrCustomFieldKeyExists
+		}
+
+		query := `
+			INSERT INTO taskodex.custom_field_definitions (
+				id, organization_id, project_id, entity, key, name, type, options,
+				required, position, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			definition.ID,
+			definition.OrganizationID,
+			definition.ProjectID,
+			definition.Entity,
+			definition.Key,
+			definition.Name,
+			definition.Type,
+			pq.Array(customFieldOptions(definition.Options)),
+			definition.Required,
+			definition.Position,
+			definition.CreatedAt,
+			definition.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert custom field: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a custom field definition by ID
+func (r *PostgresCustomFieldRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CustomFieldDefinition, error) {
+	row := conn(ctx, r.db).QueryRowxContext(
+		ctx,
+		"SELECT "+customFieldColumns+" FROM taskodex.custom_field_definitions WHERE id = $1",
+		id,
+	)
+
+	definition, err := scanCustomField(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomFieldNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom field: %w", err)
+	}
+
+	return definition, nil
+}
+
+// List retrieves the definitions of an entity that apply to a project
+func (r *PostgresCustomFieldRepository) List(ctx context.Context, organizationID uuid.UUID, projectID *uuid.UUID, entity models.CustomFieldEntity) ([]models.CustomFieldDefinition, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM taskodex.custom_field_definitions
+		WHERE organization_id = $1 AND entity = $2
+			AND (project_id IS NULL OR project_id = $3)
+		ORDER BY position, key
+	`
+
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, organizationID, entity, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom fields: %w", err)
+	}
+	defer rows.Close()
+
+	definitions := []models.CustomFieldDefinition{}
+	for rows.Next() {
+		definition, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %w", err)
+		}
+		definitions = append(definitions, *definition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read custom fields: %w", err)
+	}
+
+	return definitions, nil
+}
+
+// Update updates a custom field definition. The key, type and scope of a
+// definition cannot change.
+func (r *PostgresCustomFieldRepository) Update(ctx context.Context, definition *models.CustomFieldDefinition) error {
+	query := `
+		UPDATE taskodex.custom_field_definitions
+		SET name = $1, options = $2, required = $3, position = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	definition.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		definition.Name,
+		pq.Array(customFieldOptions(definition.Options)),
+		definition.Required,
+		definition.Position,
+		definition.UpdatedAt,
+		definition.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update custom field: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrCustomFieldNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a custom field definition and removes its values
+func (r *PostgresCustomFieldRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		definition, err := r.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Remove the field's values from the records it applied to
+		var query string
+		var scope uuid.UUID
+		switch {
+		case definition.Entity == models.CustomFieldEntityTask && definition.ProjectID != nil:
+			query = "UPDATE taskodex.tasks SET custom_fields = custom_fields - $1 WHERE project_id = $2 AND custom_fields ? $1"
+			scope = *definition.ProjectID
+		case definition.Entity == models.CustomFieldEntityTask:
+			query = `UPDATE taskodex.tasks SET custom_fields = custom_fields - $1
+				WHERE project_id IN (SELECT id FROM taskodex.projects WHERE organization_id = $2) AND custom_fields ? $1`
+			scope = definition.OrganizationID
+		case definition.ProjectID != nil:
+			query = "UPDATE taskodex.projects SET custom_fields = custom_fields - $1 WHERE id = $2 AND custom_fields ? $1"
+			scope = *definition.ProjectID
+		default:
+			query = "UPDATE taskodex.projects SET custom_fields = custom_fields - $1 WHERE organization_id = $2 AND custom_fields ? $1"
+			scope = definition.OrganizationID
+		}
+
+		_, err = conn(ctx, r.db).ExecContext(ctx, query, definition.Key, scope)
+		if err != nil {
+			return fmt.Errorf("failed to remove custom field values: %w", err)
+		}
+
+		_, err = conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.custom_field_definitions WHERE id = $1", id)
+		if err != nil {
+			return fmt.Errorf("failed to delete custom field: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// scanCustomField scans a custom field definition selected with customFieldColumns
+func scanCustomField(row interface{ Scan(...interface{}) error }) (*models.CustomFieldDefinition, error) {
+	var definition models.CustomFieldDefinition
+	err := row.Scan(
+		&definition.ID,
+		&definition.OrganizationID,
+		&definition.ProjectID,
+		&definition.Entity,
+		&definition.Key,
+		&definition.Name,
+		&definition.Type,
+		pq.Array(&definition.Options),
+		&definition.Required,
+		&definition.Position,
+		&definition.CreatedAt,
+		&definition.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &definition, nil
+}
+
+// customFieldOptions returns options as a non-nil slice for storage
+func customFieldOptions(options []string) []string {
+	if options == nil {
+		return []string{}
+	}
+	return options
+}
@@ -50,12 +50,16 @@ type EmployeeRepository interface {
 
 // PostgresEmployeeRepository implements EmployeeRepository using PostgreSQL
 type PostgresEmployeeRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	userRepo UserRepository
 }
 
 // NewPostgresEmployeeRepository creates a new PostgresEmployeeRepository
 func NewPostgresEmployeeRepository(db *sqlx.DB) EmployeeRepository {
-	return &PostgresEmployeeRepository{db: db}
+	return &PostgresEmployeeRepository{
+		db:       db,
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new employee
@@ -97,7 +101,7 @@ func (r *PostgresEmployeeRepository) Create(ctx context.Context, employee *model
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		employee.ID,
@@ -131,7 +135,7 @@ func (r *PostgresEmployeeRepository) GetByID(ctx context.Context, id uuid.UUID)
 	`
 
 	var employee models.Employee
-	err := r.db.GetContext(ctx, &employee, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &employee, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrEmployeeNotFound
@@ -140,8 +144,7 @@ func (r *PostgresEmployeeRepository) GetByID(ctx context.Context, id uuid.UUID)
 	}
 
 	// Get user information
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, employee.UserID)
+	user, err := r.userRepo.GetByID(ctx, employee.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +174,7 @@ func (r *PostgresEmployeeRepository) GetByEmployeeID(ctx context.Context, organi
 	`
 
 	var employee models.Employee
-	err := r.db.GetContext(ctx, &employee, query, organizationID, employeeID)
+	err := conn(ctx, r.db).GetContext(ctx, &employee, query, organizationID, employeeID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrEmployeeNotFound
@@ -180,8 +183,7 @@ func (r *PostgresEmployeeRepository) GetByEmployeeID(ctx context.Context, organi
 	}
 
 	// Get user information
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, employee.UserID)
+	user, err := r.userRepo.GetByID(ctx, employee.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -211,7 +213,7 @@ func (r *PostgresEmployeeRepository) GetByUserID(ctx context.Context, organizati
 	`
 
 	var employee models.Employee
-	err := r.db.GetContext(ctx, &employee, query, organizationID, userID)
+	err := conn(ctx, r.db).GetContext(ctx, &employee, query, organizationID, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrEmployeeNotFound
@@ -220,8 +222,7 @@ func (r *PostgresEmployeeRepository) GetByUserID(ctx context.Context, organizati
 	}
 
 	// Get user information
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, employee.UserID)
+	user, err := r.userRepo.GetByID(ctx, employee.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +278,7 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, params models.Emp
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	countStmt, err := r.db.PrepareNamedContext(ctx, countQuery)
+	countStmt, err := conn(ctx, r.db).PrepareNamedContext(ctx, countQuery)
 	if err != nil {
 		return nil, 0, ErrDatabaseError
 	}
@@ -327,7 +328,7 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, params models.Emp
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 
 	// Execute the query
-	rows, err := r.db.NamedQueryContext(ctx, query, args)
+	rows, err := sqlx.NamedQueryContext(ctx, conn(ctx, r.db), query, args)
 	if err != nil {
 		return nil, 0, ErrDatabaseError
 	}
@@ -345,9 +346,8 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, params models.Emp
 	}
 
 	// Get user information for each employee
-	userRepo := NewPostgresUserRepository(r.db)
 	for i := range employees {
-		user, err := userRepo.GetByID(ctx, employees[i].UserID)
+		user, err := r.userRepo.GetByID(ctx, employees[i].UserID)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -401,7 +401,7 @@ func (r *PostgresEmployeeRepository) Update(ctx context.Context, employee *model
 
 	employee.UpdatedAt = time.Now()
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		employee.EmployeeID,
@@ -430,7 +430,7 @@ func (r *PostgresEmployeeRepository) Delete(ctx context.Context, id uuid.UUID) e
 		WHERE id = $2
 	`
 
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Common errors for file attachment repository
@@ -27,6 +28,10 @@ type FileAttachmentRepository interface {
 	
 	// List retrieves file attachments based on filter parameters
 	List(ctx context.Context, params models.FileAttachmentListParams) ([]models.FileAttachment, int, error)
+
+	// GetByIDs retrieves file attachments by ID with their users, keyed by
+	// ID. Unknown IDs are left out.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.FileAttachment, error)
 	
 	// Delete deletes a file attachment
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -34,12 +39,18 @@ type FileAttachmentRepository interface {
 
 // PostgresFileAttachmentRepository implements FileAttachmentRepository using PostgreSQL
 type PostgresFileAttachmentRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	taskRepo TaskRepository
+	userRepo UserRepository
 }
 
 // NewPostgresFileAttachmentRepository creates a new PostgresFileAttachmentRepository
 func NewPostgresFileAttachmentRepository(db *sqlx.DB) FileAttachmentRepository {
-	return &PostgresFileAttachmentRepository{db: db}
+	return &PostgresFileAttachmentRepository{
+		db:       db,
+		taskRepo: NewPostgresTaskRepository(db),
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new file attachment
@@ -51,7 +62,7 @@ func (r *PostgresFileAttachmentRepository) Create(ctx context.Context, fileAttac
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 	
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		fileAttachment.ID,
@@ -80,7 +91,7 @@ func (r *PostgresFileAttachmentRepository) GetByID(ctx context.Context, id uuid.
 	`
 	
 	var fileAttachment models.FileAttachment
-	err := r.db.GetContext(ctx, &fileAttachment, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &fileAttachment, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrFileAttachmentNotFound
@@ -89,8 +100,7 @@ func (r *PostgresFileAttachmentRepository) GetByID(ctx context.Context, id uuid.
 	}
 	
 	// Get user
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, fileAttachment.UserID)
+	user, err := r.userRepo.GetByID(ctx, fileAttachment.UserID)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -99,8 +109,7 @@ func (r *PostgresFileAttachmentRepository) GetByID(ctx context.Context, id uuid.
 	}
 	
 	// Get task
-	taskRepo := NewPostgresTaskRepository(r.db)
-	task, err := taskRepo.GetByID(ctx, fileAttachment.TaskID)
+	task, err := r.taskRepo.GetByID(ctx, fileAttachment.TaskID)
 	if err != nil && !errors.Is(err, ErrTaskNotFound) {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
@@ -111,6 +120,47 @@ func (r *PostgresFileAttachmentRepository) GetByID(ctx context.Context, id uuid.
 	return &fileAttachment, nil
 }
 
+// GetByIDs retrieves file attachments by ID with their users, keyed by ID
+func (r *PostgresFileAttachmentRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.FileAttachment, error) {
+	result := make(map[uuid.UUID]*models.FileAttachment, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	attachmentIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		attachmentIDs = append(attachmentIDs, id.String())
+	}
+
+	query := `
+		SELECT id, task_id, user_id, file_name, file_size, content_type, storage_path, created_at
+		FROM taskodex.task_file_attachments
+		WHERE id = ANY($1::uuid[])
+	`
+
+	var attachments []models.FileAttachment
+	err := conn(ctx, r.db).SelectContext(ctx, &attachments, query, pq.Array(attachmentIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file attachments: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(attachments))
+	for i := range attachments {
+		result[attachments[i].ID] = &attachments[i]
+		userIDs = append(userIDs, attachments[i].UserID)
+	}
+
+	users, err := r.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, attachment := range result {
+		attachment.User = users[attachment.UserID]
+	}
+
+	return result, nil
+}
+
 // List retrieves file attachments based on filter parameters
 func (r *PostgresFileAttachmentRepository) List(ctx context.Context, params models.FileAttachmentListParams) ([]models.FileAttachment, int, error) {
 	// Build the query
@@ -143,7 +193,7 @@ func (r *PostgresFileAttachmentRepository) List(ctx context.Context, params mode
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count file attachments: %w", err)
 	}
@@ -184,7 +234,7 @@ func (r *PostgresFileAttachmentRepository) List(ctx context.Context, params mode
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query file attachments: %w", err)
 	}
@@ -200,8 +250,7 @@ func (r *PostgresFileAttachmentRepository) List(ctx context.Context, params mode
 		}
 		
 		// Get user
-		userRepo := NewPostgresUserRepository(r.db)
-		user, err := userRepo.GetByID(ctx, fileAttachment.UserID)
+		user, err := r.userRepo.GetByID(ctx, fileAttachment.UserID)
 		if err == nil {
 			fileAttachment.User = user
 		}
@@ -219,7 +268,7 @@ func (r *PostgresFileAttachmentRepository) Delete(ctx context.Context, id uuid.U
 		WHERE id = $1
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete file attachment: %w", err)
 	}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// Common errors for job run repository
+var (
+	ErrJobRunNotFound = errors.New("job run not found")
+)
+
+// JobRunRepository defines the interface for background job run data access
+type JobRunRepository interface {
+	// Create creates a new job run
+	Create(ctx context.Context, run *models.JobRun) error
+
+	// Update updates the status of a job run
+	Update(ctx context.Context, run *models.JobRun) error
+
+	// GetLatest retrieves the latest run of a job
+	GetLatest(ctx context.Context, jobName string) (*models.JobRun, error)
+
+	// FailRunning marks the runs still running as failed with a message, to
+	// be retried at once. It returns the number of runs marked.
+	FailRunning(ctx context.Context, message string, finishedAt time.Time) (int64, error)
+
+	// DeleteBefore deletes the runs started before a time. It returns the
+	// number of runs deleted.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PostgresJobRunRepository implements JobRunRepository using PostgreSQL
+type PostgresJobRunRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresJobRunRepository creates a new PostgresJobRunRepository
+func NewPostgresJobRunRepository(db *sqlx.DB) JobRunRepository {
+	return &PostgresJobRunRepository{db: db}
+}
+
+// Create creates a new job run
+func (r *PostgresJobRunRepository) Create(ctx context.Context, run *models.JobRun) error {
+	query := `
+		INSERT INTO job_runs (id, job_name, attempt, status, error, started_at, finished_at, next_retry_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		run.ID,
+		run.JobName,
+		run.Attempt,
+		run.Status,
+		run.Error,
+		run.StartedAt,
+		run.FinishedAt,
+		run.NextRetryAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job run: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates the status of a job run
+func (r *PostgresJobRunRepository) Update(ctx context.Context, run *models.JobRun) error {
+	query := `
+		UPDATE job_runs
+		SET status = $1, error = $2, finished_at = $3, next_retry_at = $4
+		WHERE id = $5
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		run.Status,
+		run.Error,
+		run.FinishedAt,
+		run.NextRetryAt,
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job run: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrJobRunNotFound
+	}
+
+	return nil
+}
+
+// GetLatest retrieves the latest run of a job
+func (r *PostgresJobRunRepository) GetLatest(ctx context.Context, jobName string) (*models.JobRun, error) {
+	query := `
+		SELECT id, job_name, attempt, status, error, started_at, finished_at, next_retry_at
+		FROM job_runs
+		WHERE job_name = $1
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	var run models.JobRun
+	err := conn(ctx, r.db).GetContext(ctx, &run, query, jobName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJobRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get job run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// FailRunning marks the runs still running as failed
+func (r *PostgresJobRunRepository) FailRunning(ctx context.Context, message string, finishedAt time.Time) (int64, error) {
+	query := `
+		UPDATE job_runs
+		SET status = $1, error = $2, finished_at = $3, next_retry_at = $3
+		WHERE status = $4
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		models.JobRunStatusFailed,
+		message,
+		finishedAt,
+		models.JobRunStatusRunning,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail running job runs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows, nil
+}
+
+// DeleteBefore deletes the runs started before a time
+func (r *PostgresJobRunRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM job_runs WHERE started_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete job runs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows, nil
+}
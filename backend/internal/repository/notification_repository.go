@@ -44,12 +44,16 @@ type NotificationRepository interface {
 
 // PostgresNotificationRepository implements NotificationRepository using PostgreSQL
 type PostgresNotificationRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	userRepo UserRepository
 }
 
 // NewPostgresNotificationRepository creates a new PostgresNotificationRepository
 func NewPostgresNotificationRepository(db *sqlx.DB) NotificationRepository {
-	return &PostgresNotificationRepository{db: db}
+	return &PostgresNotificationRepository{
+		db:       db,
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new notification
@@ -62,7 +66,7 @@ func (r *PostgresNotificationRepository) Create(ctx context.Context, notificatio
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		notification.ID,
@@ -94,7 +98,7 @@ func (r *PostgresNotificationRepository) GetByID(ctx context.Context, id uuid.UU
 	`
 	
 	var notification models.Notification
-	err := r.db.GetContext(ctx, &notification, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &notification, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotificationNotFound
@@ -103,8 +107,7 @@ func (r *PostgresNotificationRepository) GetByID(ctx context.Context, id uuid.UU
 	}
 	
 	// Get user
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, notification.UserID)
+	user, err := r.userRepo.GetByID(ctx, notification.UserID)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -147,7 +150,7 @@ func (r *PostgresNotificationRepository) List(ctx context.Context, params models
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
 	}
@@ -189,7 +192,7 @@ func (r *PostgresNotificationRepository) List(ctx context.Context, params models
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query notifications: %w", err)
 	}
@@ -219,7 +222,7 @@ func (r *PostgresNotificationRepository) MarkAsRead(ctx context.Context, id uuid
 	
 	now := time.Now()
 	
-	_, err := r.db.ExecContext(ctx, query, now, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to mark notification as read: %w", err)
 	}
@@ -237,7 +240,7 @@ func (r *PostgresNotificationRepository) MarkAllAsRead(ctx context.Context, user
 	
 	now := time.Now()
 	
-	_, err := r.db.ExecContext(ctx, query, now, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, now, userID)
 	if err != nil {
 		return fmt.Errorf("failed to mark all notifications as read: %w", err)
 	}
@@ -252,7 +255,7 @@ func (r *PostgresNotificationRepository) Delete(ctx context.Context, id uuid.UUI
 		WHERE id = $1
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete notification: %w", err)
 	}
@@ -267,7 +270,7 @@ func (r *PostgresNotificationRepository) DeleteAllForUser(ctx context.Context, u
 		WHERE user_id = $1
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete all notifications for user: %w", err)
 	}
@@ -55,12 +55,16 @@ type OrganizationRepository interface {
 
 // PostgresOrganizationRepository implements OrganizationRepository using PostgreSQL
 type PostgresOrganizationRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	userRepo UserRepository
 }
 
 // NewPostgresOrganizationRepository creates a new PostgresOrganizationRepository
 func NewPostgresOrganizationRepository(db *sqlx.DB) OrganizationRepository {
-	return &PostgresOrganizationRepository{db: db}
+	return &PostgresOrganizationRepository{
+		db:       db,
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new organization
@@ -79,7 +83,7 @@ func (r *PostgresOrganizationRepository) Create(ctx context.Context, organizatio
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		organization.ID,
@@ -106,7 +110,7 @@ func (r *PostgresOrganizationRepository) GetByID(ctx context.Context, id uuid.UU
 	`
 
 	var organization models.Organization
-	err := r.db.GetContext(ctx, &organization, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &organization, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrOrganizationNotFound
@@ -133,7 +137,7 @@ func (r *PostgresOrganizationRepository) GetByName(ctx context.Context, name str
 	`
 
 	var organization models.Organization
-	err := r.db.GetContext(ctx, &organization, query, name)
+	err := conn(ctx, r.db).GetContext(ctx, &organization, query, name)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrOrganizationNotFound
@@ -161,7 +165,7 @@ func (r *PostgresOrganizationRepository) List(ctx context.Context) ([]models.Org
 	`
 
 	var organizations []models.Organization
-	err := r.db.SelectContext(ctx, &organizations, query)
+	err := conn(ctx, r.db).SelectContext(ctx, &organizations, query)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -203,7 +207,7 @@ func (r *PostgresOrganizationRepository) Update(ctx context.Context, organizatio
 
 	organization.UpdatedAt = time.Now()
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		organization.Name,
@@ -228,7 +232,7 @@ func (r *PostgresOrganizationRepository) Delete(ctx context.Context, id uuid.UUI
 		WHERE id = $2
 	`
 
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -245,8 +249,7 @@ func (r *PostgresOrganizationRepository) AddUser(ctx context.Context, organizati
 	}
 
 	// Check if user exists
-	userRepo := NewPostgresUserRepository(r.db)
-	_, err = userRepo.GetByID(ctx, organizationUser.UserID)
+	_, err = r.userRepo.GetByID(ctx, organizationUser.UserID)
 	if err != nil {
 		return err
 	}
@@ -259,7 +262,7 @@ func (r *PostgresOrganizationRepository) AddUser(ctx context.Context, organizati
 	`
 
 	var count int
-	err = r.db.GetContext(ctx, &count, query, organizationUser.OrganizationID, organizationUser.UserID)
+	err = conn(ctx, r.db).GetContext(ctx, &count, query, organizationUser.OrganizationID, organizationUser.UserID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -275,7 +278,7 @@ func (r *PostgresOrganizationRepository) AddUser(ctx context.Context, organizati
 		VALUES ($1, $2)
 	`
 
-	_, err = r.db.ExecContext(ctx, query, organizationUser.OrganizationID, organizationUser.UserID)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, organizationUser.OrganizationID, organizationUser.UserID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -290,7 +293,7 @@ func (r *PostgresOrganizationRepository) RemoveUser(ctx context.Context, organiz
 		WHERE organization_id = $1 AND user_id = $2
 	`
 
-	_, err := r.db.ExecContext(ctx, query, organizationID, userID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, organizationID, userID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -301,7 +304,7 @@ func (r *PostgresOrganizationRepository) RemoveUser(ctx context.Context, organiz
 // GetUsers retrieves all users in an organization
 func (r *PostgresOrganizationRepository) GetUsers(ctx context.Context, organizationID uuid.UUID) ([]models.User, error) {
 	query := `
-		SELECT u.id, u.email, u.password_hash, u.first_name, u.last_name, u.is_active, u.created_at, u.updated_at
+		SELECT u.id, u.email, u.password_hash, u.first_name, u.last_name, u.is_active, u.timezone, u.created_at, u.updated_at
 		FROM users u
 		JOIN organization_users ou ON u.id = ou.user_id
 		WHERE ou.organization_id = $1 AND u.is_active = true
@@ -309,7 +312,7 @@ func (r *PostgresOrganizationRepository) GetUsers(ctx context.Context, organizat
 	`
 
 	var users []models.User
-	err := r.db.SelectContext(ctx, &users, query, organizationID)
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, organizationID)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -328,7 +331,7 @@ func (r *PostgresOrganizationRepository) GetOrganizations(ctx context.Context, u
 	`
 
 	var organizations []models.Organization
-	err := r.db.SelectContext(ctx, &organizations, query, userID)
+	err := conn(ctx, r.db).SelectContext(ctx, &organizations, query, userID)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -17,6 +17,7 @@ import (
 var (
 	ErrProjectNotFound = errors.New("project not found")
 	ErrProjectNameExists = errors.New("project name already exists in this organization")
+	ErrProjectArchived = errors.New("project is archived")
 )
 
 // ProjectRepository defines the interface for project data access
@@ -36,18 +37,28 @@ type ProjectRepository interface {
 	// Update updates a project
 	Update(ctx context.Context, project *models.Project) error
 	
-	// Delete deletes a project
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete moves a project to the trash, along with its tasks
+	Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error
+	
+	// Archive makes a project read-only
+	Archive(ctx context.Context, id uuid.UUID, archivedBy uuid.UUID) error
+	
+	// Unarchive makes an archived project writable again
+	Unarchive(ctx context.Context, id uuid.UUID) error
 }
 
 // PostgresProjectRepository implements ProjectRepository using PostgreSQL
 type PostgresProjectRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	userRepo UserRepository
 }
 
 // NewPostgresProjectRepository creates a new PostgresProjectRepository
 func NewPostgresProjectRepository(db *sqlx.DB) ProjectRepository {
-	return &PostgresProjectRepository{db: db}
+	return &PostgresProjectRepository{
+		db:       db,
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new project
@@ -65,12 +76,12 @@ func (r *PostgresProjectRepository) Create(ctx context.Context, project *models.
 	query := `
 		INSERT INTO taskodex.projects (
 			id, organization_id, name, description, status, 
-			start_date, end_date, created_by, created_at, updated_at
+			start_date, end_date, custom_fields, created_by, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		project.ID,
@@ -80,6 +91,7 @@ func (r *PostgresProjectRepository) Create(ctx context.Context, project *models.
 		project.Status,
 		project.StartDate,
 		project.EndDate,
+		project.CustomFields,
 		project.CreatedBy,
 		project.CreatedAt,
 		project.UpdatedAt,
@@ -96,13 +108,13 @@ func (r *PostgresProjectRepository) Create(ctx context.Context, project *models.
 func (r *PostgresProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
 	query := `
 		SELECT p.id, p.organization_id, p.name, p.description, p.status, 
-			p.start_date, p.end_date, p.created_by, p.created_at, p.updated_at
+			p.start_date, p.end_date, p.custom_fields, p.archived_at, p.archived_by, p.created_by, p.created_at, p.updated_at
 		FROM taskodex.projects p
-		WHERE p.id = $1
+		WHERE p.id = $1 AND p.deleted_at IS NULL
 	`
 	
 	var project models.Project
-	err := r.db.GetContext(ctx, &project, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &project, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrProjectNotFound
@@ -111,8 +123,7 @@ func (r *PostgresProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (
 	}
 	
 	// Get creator
-	userRepo := NewPostgresUserRepository(r.db)
-	creator, err := userRepo.GetByID(ctx, project.CreatedBy)
+	creator, err := r.userRepo.GetByID(ctx, project.CreatedBy)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get creator: %w", err)
 	}
@@ -127,13 +138,13 @@ func (r *PostgresProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (
 func (r *PostgresProjectRepository) GetByName(ctx context.Context, organizationID uuid.UUID, name string) (*models.Project, error) {
 	query := `
 		SELECT p.id, p.organization_id, p.name, p.description, p.status, 
-			p.start_date, p.end_date, p.created_by, p.created_at, p.updated_at
+			p.start_date, p.end_date, p.custom_fields, p.archived_at, p.archived_by, p.created_by, p.created_at, p.updated_at
 		FROM taskodex.projects p
-		WHERE p.organization_id = $1 AND p.name = $2
+		WHERE p.organization_id = $1 AND p.name = $2 AND p.deleted_at IS NULL
 	`
 	
 	var project models.Project
-	err := r.db.GetContext(ctx, &project, query, organizationID, name)
+	err := conn(ctx, r.db).GetContext(ctx, &project, query, organizationID, name)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrProjectNotFound
@@ -142,8 +153,7 @@ func (r *PostgresProjectRepository) GetByName(ctx context.Context, organizationI
 	}
 	
 	// Get creator
-	userRepo := NewPostgresUserRepository(r.db)
-	creator, err := userRepo.GetByID(ctx, project.CreatedBy)
+	creator, err := r.userRepo.GetByID(ctx, project.CreatedBy)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get creator: %w", err)
 	}
@@ -159,7 +169,7 @@ func (r *PostgresProjectRepository) List(ctx context.Context, params models.Proj
 	// Build the query
 	baseQuery := `
 		FROM taskodex.projects p
-		WHERE p.organization_id = $1
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL
 	`
 	
 	// Add filters
@@ -179,9 +189,18 @@ func (r *PostgresProjectRepository) List(ctx context.Context, params models.Proj
 		argIndex++
 	}
 	
-	if params.SearchTerm != nil && *params.SearchTerm != "" {
-		filters = append(filters, fmt.Sprintf("(p.name ILIKE $%d OR p.description ILIKE $%d)", argIndex, argIndex))
-		args = append(args, "%"+*params.SearchTerm+"%")
+	if params.Archived != nil {
+		if *params.Archived {
+			filters = append(filters, "p.archived_at IS NOT NULL")
+		} else {
+			filters = append(filters, "p.archived_at IS NULL")
+		}
+	}
+	
+	// Search matches every word of the term as a prefix of a name or description word
+	if params.SearchTerm != nil && prefixQuery(*params.SearchTerm) != "" {
+		filters = append(filters, fmt.Sprintf("p.search_vector @@ to_tsquery('english', $%d)", argIndex))
+		args = append(args, prefixQuery(*params.SearchTerm))
 		argIndex++
 	}
 	
@@ -192,7 +211,7 @@ func (r *PostgresProjectRepository) List(ctx context.Context, params models.Proj
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count projects: %w", err)
 	}
@@ -230,14 +249,14 @@ func (r *PostgresProjectRepository) List(ctx context.Context, params models.Proj
 	// Build the final query
 	query := fmt.Sprintf(`
 		SELECT p.id, p.organization_id, p.name, p.description, p.status, 
-			p.start_date, p.end_date, p.created_by, p.created_at, p.updated_at
+			p.start_date, p.end_date, p.custom_fields, p.archived_at, p.archived_by, p.created_by, p.created_at, p.updated_at
 		%s
 		ORDER BY p.%s %s
 		LIMIT %d OFFSET %d
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query projects: %w", err)
 	}
@@ -280,13 +299,13 @@ func (r *PostgresProjectRepository) Update(ctx context.Context, project *models.
 	query := `
 		UPDATE taskodex.projects
 		SET name = $1, description = $2, status = $3, start_date = $4, 
-			end_date = $5, updated_at = $6
-		WHERE id = $7
+			end_date = $5, custom_fields = $6, updated_at = $7
+		WHERE id = $8
 	`
 	
 	project.UpdatedAt = time.Now()
 	
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		project.Name,
@@ -294,6 +313,7 @@ func (r *PostgresProjectRepository) Update(ctx context.Context, project *models.
 		project.Status,
 		project.StartDate,
 		project.EndDate,
+		project.CustomFields,
 		project.UpdatedAt,
 		project.ID,
 	)
@@ -305,29 +325,78 @@ func (r *PostgresProjectRepository) Update(ctx context.Context, project *models.
 	return nil
 }
 
-// Delete deletes a project
-func (r *PostgresProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	// Check if project exists
-	_, err := r.GetByID(ctx, id)
+// Delete moves a project to the trash, along with its tasks. The tasks get
+// the same deletion time as the project, so that they are restored with it;
+// tasks already in the trash keep theirs.
+func (r *PostgresProjectRepository) Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		now := time.Now()
+		
+		result, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			"UPDATE taskodex.projects SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL",
+			now,
+			deletedBy,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+		
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return ErrProjectNotFound
+		}
+		
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			"UPDATE taskodex.tasks SET deleted_at = $1, deleted_by = $2 WHERE project_id = $3 AND deleted_at IS NULL",
+			now,
+			deletedBy,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete project tasks: %w", err)
+		}
+		
+		return nil
+	})
+}
+
+// Archive makes a project read-only
+func (r *PostgresProjectRepository) Archive(ctx context.Context, id uuid.UUID, archivedBy uuid.UUID) error {
+	now := time.Now()
+	return r.setArchived(ctx, id, &now, &archivedBy)
+}
+
+// Unarchive makes an archived project writable again
+func (r *PostgresProjectRepository) Unarchive(ctx context.Context, id uuid.UUID) error {
+	return r.setArchived(ctx, id, nil, nil)
+}
+
+// setArchived sets when and by whom a project was archived
+func (r *PostgresProjectRepository) setArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time, archivedBy *uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE taskodex.projects SET archived_at = $1, archived_by = $2, updated_at = $3 WHERE id = $4 AND deleted_at IS NULL",
+		archivedAt,
+		archivedBy,
+		time.Now(),
+		id,
+	)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to archive project: %w", err)
 	}
 	
-	// Check if project has tasks
-	var taskCount int
-	err = r.db.GetContext(ctx, &taskCount, "SELECT COUNT(*) FROM taskodex.tasks WHERE project_id = $1", id)
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to check if project has tasks: %w", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
 	}
-	
-	if taskCount > 0 {
-		return fmt.Errorf("cannot delete project with tasks")
-	}
-	
-	// Delete project
-	_, err = r.db.ExecContext(ctx, "DELETE FROM taskodex.projects WHERE id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete project: %w", err)
+	if rows == 0 {
+		return ErrProjectNotFound
 	}
 	
 	return nil
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for project template repository
+var (
+	ErrProjectTemplateNotFound   = errors.New("project template not found")
+	ErrProjectTemplateNameExists = errors.New("project template name already exists in this organization")
+)
+
+// projectTemplateColumns are the columns of a project template, with the
+// number of its tasks
+const projectTemplateColumns = `
+	p.id, p.organization_id, p.name, p.description, p.workflow, p.created_by, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM taskodex.project_template_tasks t WHERE t.template_id = p.id) AS task_count
+`
+
+// ProjectTemplateRepository defines the interface for project template data access
+type ProjectTemplateRepository interface {
+	// Create creates a new project template with its tasks. Names are unique
+	// per organization.
+	Create(ctx context.Context, template *models.ProjectTemplate) error
+
+	// GetByID retrieves a project template by ID, with its tasks in order
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ProjectTemplate, error)
+
+	// List retrieves the project templates of an organization, without their tasks
+	List(ctx context.Context, organizationID uuid.UUID) ([]models.ProjectTemplate, error)
+
+	// Delete deletes a project template
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PostgresProjectTemplateRepository implements ProjectTemplateRepository using PostgreSQL
+type PostgresProjectTemplateRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresProjectTemplateRepository creates a new PostgresProjectTemplateRepository
+func NewPostgresProjectTemplateRepository(db *sqlx.DB) ProjectTemplateRepository {
+	return &PostgresProjectTemplateRepository{db: db}
+}
+
+// projectTemplateTaskRow is a project template task as stored
+type projectTemplateTaskRow struct {
+	models.ProjectTemplateTask
+	Tags      pq.StringArray `db:"tags"`
+	Checklist pq.StringArray `db:"checklist"`
+}
+
+// Create creates a new project template with its tasks
+func (r *PostgresProjectTemplateRepository) Create(ctx context.Context, template *models.ProjectTemplate) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		var exists bool
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&exists,
+			"SELECT EXISTS(SELECT 1 FROM taskodex.project_templates WHERE organization_id = $1 AND name = $2)",
+			template.OrganizationID,
+			template.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check if project template exists: %w", err)
+		}
+		if exists {
+			return ErrProjectTemplateNameExists
+		}
+
+		query := `
+			INSERT INTO taskodex.project_templates (
+				id, organization_id, name, description, workflow, created_by, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			template.ID,
+			template.OrganizationID,
+			template.Name,
+			template.Description,
+			template.Workflow,
+			template.CreatedBy,
+			template.CreatedAt,
+			template.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert project template: %w", err)
+		}
+
+		// Parents come first, so they exist when their subtasks are inserted
+		query = `
+			INSERT INTO taskodex.project_template_tasks (
+				id, template_id, parent_id, position, title, description, priority,
+				estimated_hours, due_offset_days, assignee_role, tags, checklist
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`
+
+		for i := range template.Tasks {
+			task := &template.Tasks[i]
+			task.TemplateID = template.ID
+
+			_, err = conn(ctx, r.db).ExecContext(
+				ctx,
+				query,
+				task.ID,
+				task.TemplateID,
+				task.ParentID,
+				task.Position,
+				task.Title,
+				task.Description,
+				task.Priority,
+				task.EstimatedHours,
+				task.DueOffsetDays,
+				task.AssigneeRole,
+				pq.Array(stringsOrEmpty(task.Tags)),
+				pq.Array(stringsOrEmpty(task.Checklist)),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert project template task: %w", err)
+			}
+		}
+		template.TaskCount = len(template.Tasks)
+
+		return nil
+	})
+}
+
+// GetByID retrieves a project template by ID, with its tasks in order
+func (r *PostgresProjectTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ProjectTemplate, error) {
+	var template models.ProjectTemplate
+	err := conn(ctx, r.db).GetContext(
+		ctx,
+		&template,
+		"SELECT "+projectTemplateColumns+" FROM taskodex.project_templates p WHERE p.id = $1",
+		id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProjectTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get project template: %w", err)
+	}
+
+	query := `
+		SELECT id, template_id, parent_id, position, title, description, priority,
+			estimated_hours, due_offset_days, assignee_role, tags, checklist
+		FROM taskodex.project_template_tasks
+		WHERE template_id = $1
+		ORDER BY position
+	`
+
+	var rows []projectTemplateTaskRow
+	err = conn(ctx, r.db).SelectContext(ctx, &rows, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project template tasks: %w", err)
+	}
+
+	template.Tasks = make([]models.ProjectTemplateTask, 0, len(rows))
+	for _, row := range rows {
+		task := row.ProjectTemplateTask
+		task.Tags = []string(row.Tags)
+		task.Checklist = []string(row.Checklist)
+		template.Tasks = append(template.Tasks, task)
+	}
+
+	return &template, nil
+}
+
+// List retrieves the project templates of an organization
+func (r *PostgresProjectTemplateRepository) List(ctx context.Context, organizationID uuid.UUID) ([]models.ProjectTemplate, error) {
+	query := `
+		SELECT ` + projectTemplateColumns + `
+		FROM taskodex.project_templates p
+		WHERE p.organization_id = $1
+		ORDER BY p.name
+	`
+
+	templates := []models.ProjectTemplate{}
+	err := conn(ctx, r.db).SelectContext(ctx, &templates, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Delete deletes a project template
+func (r *PostgresProjectTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.project_templates WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project template: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrProjectTemplateNotFound
+	}
+
+	return nil
+}
+
+// stringsOrEmpty returns values, or an empty slice if values is nil, so that
+// it is stored as an empty array rather than NULL
+func stringsOrEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
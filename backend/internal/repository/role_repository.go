@@ -81,12 +81,16 @@ type RoleRepository interface {
 
 // PostgresRoleRepository implements RoleRepository using PostgreSQL
 type PostgresRoleRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	userRepo UserRepository
 }
 
 // NewPostgresRoleRepository creates a new PostgresRoleRepository
 func NewPostgresRoleRepository(db *sqlx.DB) RoleRepository {
-	return &PostgresRoleRepository{db: db}
+	return &PostgresRoleRepository{
+		db:       db,
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // CreateRole creates a new role
@@ -105,7 +109,7 @@ func (r *PostgresRoleRepository) CreateRole(ctx context.Context, role *models.Ro
 		VALUES ($1, $2, $3, $4, $5)
 	`
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		role.ID,
@@ -131,7 +135,7 @@ func (r *PostgresRoleRepository) GetRoleByID(ctx context.Context, id uuid.UUID)
 	`
 
 	var role models.Role
-	err := r.db.GetContext(ctx, &role, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &role, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrRoleNotFound
@@ -158,7 +162,7 @@ func (r *PostgresRoleRepository) GetRoleByName(ctx context.Context, name string)
 	`
 
 	var role models.Role
-	err := r.db.GetContext(ctx, &role, query, name)
+	err := conn(ctx, r.db).GetContext(ctx, &role, query, name)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrRoleNotFound
@@ -185,7 +189,7 @@ func (r *PostgresRoleRepository) ListRoles(ctx context.Context) ([]models.Role,
 	`
 
 	var roles []models.Role
-	err := r.db.SelectContext(ctx, &roles, query)
+	err := conn(ctx, r.db).SelectContext(ctx, &roles, query)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -227,7 +231,7 @@ func (r *PostgresRoleRepository) UpdateRole(ctx context.Context, role *models.Ro
 
 	role.UpdatedAt = time.Now()
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		role.Name,
@@ -251,36 +255,27 @@ func (r *PostgresRoleRepository) DeleteRole(ctx context.Context, id uuid.UUID) e
 		return err
 	}
 
-	// Start a transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return ErrDatabaseError
-	}
-	defer tx.Rollback()
+	err = withTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
 
-	// Delete role permissions
-	_, err = tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = $1", id)
-	if err != nil {
-		return ErrDatabaseError
-	}
+		// Delete role permissions
+		if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = $1", id); err != nil {
+			return err
+		}
 
-	// Delete user roles
-	_, err = tx.ExecContext(ctx, "DELETE FROM user_roles WHERE role_id = $1", id)
-	if err != nil {
-		return ErrDatabaseError
-	}
+		// Delete user roles
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_roles WHERE role_id = $1", id); err != nil {
+			return err
+		}
 
-	// Delete role
-	_, err = tx.ExecContext(ctx, "DELETE FROM roles WHERE id = $1", id)
+		// Delete role
+		_, err := tx.ExecContext(ctx, "DELETE FROM roles WHERE id = $1", id)
+		return err
+	})
 	if err != nil {
 		return ErrDatabaseError
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return ErrDatabaseError
-	}
-
 	return nil
 }
 
@@ -300,7 +295,7 @@ func (r *PostgresRoleRepository) CreatePermission(ctx context.Context, permissio
 		VALUES ($1, $2, $3, $4, $5)
 	`
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		permission.ID,
@@ -326,7 +321,7 @@ func (r *PostgresRoleRepository) GetPermissionByID(ctx context.Context, id uuid.
 	`
 
 	var permission models.Permission
-	err := r.db.GetContext(ctx, &permission, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &permission, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPermissionNotFound
@@ -346,7 +341,7 @@ func (r *PostgresRoleRepository) GetPermissionByName(ctx context.Context, name s
 	`
 
 	var permission models.Permission
-	err := r.db.GetContext(ctx, &permission, query, name)
+	err := conn(ctx, r.db).GetContext(ctx, &permission, query, name)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPermissionNotFound
@@ -366,7 +361,7 @@ func (r *PostgresRoleRepository) ListPermissions(ctx context.Context) ([]models.
 	`
 
 	var permissions []models.Permission
-	err := r.db.SelectContext(ctx, &permissions, query)
+	err := conn(ctx, r.db).SelectContext(ctx, &permissions, query)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -399,7 +394,7 @@ func (r *PostgresRoleRepository) UpdatePermission(ctx context.Context, permissio
 
 	permission.UpdatedAt = time.Now()
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		permission.Name,
@@ -423,30 +418,22 @@ func (r *PostgresRoleRepository) DeletePermission(ctx context.Context, id uuid.U
 		return err
 	}
 
-	// Start a transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return ErrDatabaseError
-	}
-	defer tx.Rollback()
+	err = withTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
 
-	// Delete role permissions
-	_, err = tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE permission_id = $1", id)
-	if err != nil {
-		return ErrDatabaseError
-	}
+		// Delete role permissions
+		if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE permission_id = $1", id); err != nil {
+			return err
+		}
 
-	// Delete permission
-	_, err = tx.ExecContext(ctx, "DELETE FROM permissions WHERE id = $1", id)
+		// Delete permission
+		_, err := tx.ExecContext(ctx, "DELETE FROM permissions WHERE id = $1", id)
+		return err
+	})
 	if err != nil {
 		return ErrDatabaseError
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return ErrDatabaseError
-	}
-
 	return nil
 }
 
@@ -472,7 +459,7 @@ func (r *PostgresRoleRepository) AssignPermissionToRole(ctx context.Context, rol
 	`
 
 	var count int
-	err = r.db.GetContext(ctx, &count, query, roleID, permissionID)
+	err = conn(ctx, r.db).GetContext(ctx, &count, query, roleID, permissionID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -488,7 +475,7 @@ func (r *PostgresRoleRepository) AssignPermissionToRole(ctx context.Context, rol
 		VALUES ($1, $2)
 	`
 
-	_, err = r.db.ExecContext(ctx, query, roleID, permissionID)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, roleID, permissionID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -516,7 +503,7 @@ func (r *PostgresRoleRepository) RemovePermissionFromRole(ctx context.Context, r
 		WHERE role_id = $1 AND permission_id = $2
 	`
 
-	_, err = r.db.ExecContext(ctx, query, roleID, permissionID)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, roleID, permissionID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -535,7 +522,7 @@ func (r *PostgresRoleRepository) GetRolePermissions(ctx context.Context, roleID
 	`
 
 	var permissions []models.Permission
-	err := r.db.SelectContext(ctx, &permissions, query, roleID)
+	err := conn(ctx, r.db).SelectContext(ctx, &permissions, query, roleID)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -546,8 +533,7 @@ func (r *PostgresRoleRepository) GetRolePermissions(ctx context.Context, roleID
 // AssignRoleToUser assigns a role to a user
 func (r *PostgresRoleRepository) AssignRoleToUser(ctx context.Context, userRole *models.UserRole) error {
 	// Check if user exists
-	userRepo := NewPostgresUserRepository(r.db)
-	_, err := userRepo.GetByID(ctx, userRole.UserID)
+	_, err := r.userRepo.GetByID(ctx, userRole.UserID)
 	if err != nil {
 		return err
 	}
@@ -566,7 +552,7 @@ func (r *PostgresRoleRepository) AssignRoleToUser(ctx context.Context, userRole
 	`
 
 	var count int
-	err = r.db.GetContext(ctx, &count, query, userRole.UserID, userRole.RoleID, userRole.OrganizationID)
+	err = conn(ctx, r.db).GetContext(ctx, &count, query, userRole.UserID, userRole.RoleID, userRole.OrganizationID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -582,7 +568,7 @@ func (r *PostgresRoleRepository) AssignRoleToUser(ctx context.Context, userRole
 		VALUES ($1, $2, $3)
 	`
 
-	_, err = r.db.ExecContext(ctx, query, userRole.UserID, userRole.RoleID, userRole.OrganizationID)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, userRole.UserID, userRole.RoleID, userRole.OrganizationID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -593,8 +579,7 @@ func (r *PostgresRoleRepository) AssignRoleToUser(ctx context.Context, userRole
 // RemoveRoleFromUser removes a role from a user
 func (r *PostgresRoleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID, organizationID uuid.UUID) error {
 	// Check if user exists
-	userRepo := NewPostgresUserRepository(r.db)
-	_, err := userRepo.GetByID(ctx, userID)
+	_, err := r.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -611,7 +596,7 @@ func (r *PostgresRoleRepository) RemoveRoleFromUser(ctx context.Context, userID,
 		WHERE user_id = $1 AND role_id = $2 AND organization_id = $3
 	`
 
-	_, err = r.db.ExecContext(ctx, query, userID, roleID, organizationID)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, userID, roleID, organizationID)
 	if err != nil {
 		return ErrDatabaseError
 	}
@@ -630,7 +615,7 @@ func (r *PostgresRoleRepository) GetUserRoles(ctx context.Context, userID, organ
 	`
 
 	var roles []models.Role
-	err := r.db.SelectContext(ctx, &roles, query, userID, organizationID)
+	err := conn(ctx, r.db).SelectContext(ctx, &roles, query, userID, organizationID)
 	if err != nil {
 		return nil, ErrDatabaseError
 	}
@@ -658,7 +643,7 @@ func (r *PostgresRoleRepository) HasPermission(ctx context.Context, userID, orga
 	`
 
 	var count int
-	err := r.db.GetContext(ctx, &count, query, userID, organizationID, permissionName)
+	err := conn(ctx, r.db).GetContext(ctx, &count, query, userID, organizationID, permissionName)
 	if err != nil {
 		return false, ErrDatabaseError
 	}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Common errors for saved filter repository
+var (
+	ErrSavedFilterNotFound   = errors.New("saved filter not found")
+	ErrSavedFilterNameExists = errors.New("saved filter name already exists")
+)
+
+// SavedFilterRepository defines the interface for saved filter data access
+type SavedFilterRepository interface {
+	// Create creates a new saved filter
+	Create(ctx context.Context, filter *models.SavedFilter) error
+
+	// GetByID retrieves a saved filter by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SavedFilter, error)
+
+	// List retrieves the saved filters of an organization visible to a user:
+	// the user's own and the shared ones
+	List(ctx context.Context, organizationID, userID uuid.UUID) ([]models.SavedFilter, error)
+
+	// Update updates a saved filter
+	Update(ctx context.Context, filter *models.SavedFilter) error
+
+	// Delete deletes a saved filter
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PostgresSavedFilterRepository implements SavedFilterRepository using PostgreSQL
+type PostgresSavedFilterRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSavedFilterRepository creates a new PostgresSavedFilterRepository
+func NewPostgresSavedFilterRepository(db *sqlx.DB) SavedFilterRepository {
+	return &PostgresSavedFilterRepository{db: db}
+}
+
+// Create creates a new saved filter. Names are unique per owner.
+func (r *PostgresSavedFilterRepository) Create(ctx context.Context, filter *models.SavedFilter) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		if err := r.checkName(ctx, filter); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO taskodex.saved_filters (
+				id, organization_id, owner_id, name, query, shared, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+
+		_, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			filter.ID,
+			filter.OrganizationID,
+			filter.OwnerID,
+			filter.Name,
+			filter.Query,
+			filter.Shared,
+			filter.CreatedAt,
+			filter.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert saved filter: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a saved filter by ID
+func (r *PostgresSavedFilterRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SavedFilter, error) {
+	query := `
+		SELECT id, organization_id, owner_id, name, query, shared, created_at, updated_at
+		FROM taskodex.saved_filters
+		WHERE id = $1
+	`
+
+	var filter models.SavedFilter
+	err := conn(ctx, r.db).GetContext(ctx, &filter, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSavedFilterNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved filter: %w", err)
+	}
+
+	return &filter, nil
+}
+
+// List retrieves the saved filters of an organization visible to a user
+func (r *PostgresSavedFilterRepository) List(ctx context.Context, organizationID, userID uuid.UUID) ([]models.SavedFilter, error) {
+	query := `
+		SELECT id, organization_id, owner_id, name, query, shared, created_at, updated_at
+		FROM taskodex.saved_filters
+		WHERE organization_id = $1 AND (owner_id = $2 OR shared)
+		ORDER BY name, created_at
+	`
+
+	filters := []models.SavedFilter{}
+	err := conn(ctx, r.db).SelectContext(ctx, &filters, query, organizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+// Update updates a saved filter
+func (r *PostgresSavedFilterRepository) Update(ctx context.Context, filter *models.SavedFilter) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		if err := r.checkName(ctx, filter); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE taskodex.saved_filters
+			SET name = $1, query = $2, shared = $3, updated_at = $4
+			WHERE id = $5
+		`
+
+		filter.UpdatedAt = time.Now()
+
+		result, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			filter.Name,
+			filter.Query,
+			filter.Shared,
+			filter.UpdatedAt,
+			filter.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update saved filter: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return ErrSavedFilterNotFound
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes a saved filter
+func (r *PostgresSavedFilterRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.saved_filters WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved filter: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSavedFilterNotFound
+	}
+
+	return nil
+}
+
+// checkName returns ErrSavedFilterNameExists if the owner has another saved
+// filter with the same name in the organization
+func (r *PostgresSavedFilterRepository) checkName(ctx context.Context, filter *models.SavedFilter) error {
+	var exists bool
+	err := conn(ctx, r.db).GetContext(
+		ctx,
+		&exists,
+		`SELECT EXISTS(
+			SELECT 1 FROM taskodex.saved_filters
+			WHERE organization_id = $1 AND owner_id = $2 AND name = $3 AND id <> $4
+		)`,
+		filter.OrganizationID,
+		filter.OwnerID,
+		filter.Name,
+		filter.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check if saved filter exists: %w", err)
+	}
+	if exists {
+		return ErrSavedFilterNameExists
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// SearchRepository defines the interface for full-text search
+type SearchRepository interface {
+	// Search retrieves the tasks, projects and comments matching a query,
+	// ranked best first
+	Search(ctx context.Context, params models.SearchParams) ([]models.SearchResult, int, error)
+}
+
+// PostgresSearchRepository implements SearchRepository using PostgreSQL
+type PostgresSearchRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSearchRepository creates a new PostgresSearchRepository
+func NewPostgresSearchRepository(db *sqlx.DB) SearchRepository {
+	return &PostgresSearchRepository{db: db}
+}
+
+// prefixQuery turns a search term into a tsquery matching every word of the
+// term as a prefix. Only letters and digits are kept, so the result is safe
+// to pass to to_tsquery. It returns an empty string if the term has no words.
+func prefixQuery(term string) string {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// searchHeadlineOptions configures the snippets of search results
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30, MaxFragments=2"
+
+// Search retrieves the tasks, projects and comments matching a query. Projects
+// are scoped to the organization; tasks and comments to the organization's
+// projects and to the tasks without a project created by or assigned to the user.
+func (r *PostgresSearchRepository) Search(ctx context.Context, params models.SearchParams) ([]models.SearchResult, int, error) {
+	tsquery := prefixQuery(params.Query)
+	if tsquery == "" || len(params.Types) == 0 {
+		return []models.SearchResult{}, 0, nil
+	}
+
+	// Tasks in the trash are hidden; archived projects and their tasks are not
+	taskVisible := `t.deleted_at IS NULL AND (p.organization_id = query.organization_id
+		OR (t.project_id IS NULL AND (t.created_by = query.user_id OR t.assigned_to = query.user_id)))`
+
+	matches := []string{}
+	for _, resultType := range params.Types {
+		switch resultType {
+		case models.SearchResultTypeTask:
+			matches = append(matches, `
+				SELECT 'task' AS type, t.id, t.project_id, NULL::uuid AS task_id, t.title,
+					t.title || ' ' || coalesce(t.description, '') AS body,
+					ts_rank(t.search_vector, query.q) AS rank, t.created_at
+				FROM taskodex.tasks t
+				LEFT JOIN taskodex.projects p ON p.id = t.project_id
+				CROSS JOIN query
+				WHERE t.search_vector @@ query.q AND `+taskVisible)
+		case models.SearchResultTypeProject:
+			matches = append(matches, `
+				SELECT 'project' AS type, p.id, p.id AS project_id, NULL::uuid AS task_id, p.name AS title,
+					p.name || ' ' || coalesce(p.description, '') AS body,
+					ts_rank(p.search_vector, query.q) AS rank, p.created_at
+				FROM taskodex.projects p
+				CROSS JOIN query
+				WHERE p.search_vector @@ query.q AND p.organization_id = query.organization_id AND p.deleted_at IS NULL`)
+		case models.SearchResultTypeComment:
+			matches = append(matches, `
+				SELECT 'comment' AS type, c.id, t.project_id, t.id AS task_id, t.title,
+					c.content AS body,
+					ts_rank(c.search_vector, query.q) AS rank, c.created_at
+				FROM taskodex.task_comments c
+				JOIN taskodex.tasks t ON t.id = c.task_id
+				LEFT JOIN taskodex.projects p ON p.id = t.project_id
+				CROSS JOIN query
+				WHERE c.search_vector @@ query.q AND `+taskVisible)
+		}
+	}
+
+	baseQuery := `
+		WITH query AS (
+			SELECT to_tsquery('english', $1) AS q, $2::uuid AS organization_id, $3::uuid AS user_id
+		),
+		matches AS (` + strings.Join(matches, "\n\t\t\t\tUNION ALL") + `
+		)
+	`
+	args := []interface{}{tsquery, params.OrganizationID, params.UserID}
+
+	// Count total records
+	var total int
+	err := conn(ctx, r.db).GetContext(ctx, &total, baseQuery+"SELECT COUNT(*) FROM matches", args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	offset := (params.Page - 1) * params.PageSize
+
+	// Snippets are only built for the results of the page
+	query := baseQuery + fmt.Sprintf(`
+		SELECT m.type, m.id, m.project_id, m.task_id, m.title,
+			ts_headline('english', m.body, query.q, '%s') AS snippet,
+			m.rank, m.created_at
+		FROM (
+			SELECT * FROM matches
+			ORDER BY rank DESC, created_at DESC, id
+			LIMIT %d OFFSET %d
+		) m
+		CROSS JOIN query
+		ORDER BY m.rank DESC, m.created_at DESC, m.id
+	`, searchHeadlineOptions, params.PageSize, offset)
+
+	results := []models.SearchResult{}
+	err = conn(ctx, r.db).SelectContext(ctx, &results, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return results, total, nil
+}
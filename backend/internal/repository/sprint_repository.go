@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for sprint repository
+var (
+	ErrSprintNotFound     = errors.New("sprint not found")
+	ErrSprintStateChanged = errors.New("sprint state has changed")
+	ErrActiveSprintExists = errors.New("project already has an active sprint")
+)
+
+// sprintColumns are the columns of a sprint, with the number of its tasks
+const sprintColumns = `
+	s.id, s.project_id, s.name, s.goal, s.state, s.start_date, s.end_date,
+	s.started_at, s.closed_at, s.completed_tasks, s.carried_over_tasks,
+	s.created_by, s.created_at, s.updated_at,
+	(SELECT COUNT(*) FROM taskodex.tasks t WHERE t.sprint_id = s.id AND t.deleted_at IS NULL) AS task_count
+`
+
+// SprintRepository defines the interface for sprint data access
+type SprintRepository interface {
+	// Create creates a new sprint
+	Create(ctx context.Context, sprint *models.Sprint) error
+
+	// GetByID retrieves a sprint by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Sprint, error)
+
+	// GetActive retrieves the active sprint of a project
+	GetActive(ctx context.Context, projectID uuid.UUID) (*models.Sprint, error)
+
+	// List retrieves the sprints of a project, optionally in a state: active
+	// first, then planned, then closed
+	List(ctx context.Context, projectID uuid.UUID, state *models.SprintState) ([]models.Sprint, error)
+
+	// Update updates the name, goal and dates of a sprint
+	Update(ctx context.Context, sprint *models.Sprint) error
+
+	// SetState saves the state of a sprint and what is recorded with it,
+	// provided the sprint is still in state from
+	SetState(ctx context.Context, sprint *models.Sprint, from models.SprintState) error
+
+	// Delete deletes a sprint. Its tasks return to the backlog.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// AddTasks moves tasks of the sprint's project into a sprint, and
+	// returns the number of tasks moved. Changes of sprint are recorded in
+	// the history of the tasks, as are those of RemoveTask and CarryOver.
+	AddTasks(ctx context.Context, sprint *models.Sprint, taskIDs []uuid.UUID, userID uuid.UUID) (int, error)
+
+	// RemoveTask returns a task of a sprint to the backlog
+	RemoveTask(ctx context.Context, sprintID, taskID, userID uuid.UUID) error
+
+	// CarryOver moves the tasks of a sprint that are not in one of the done
+	// statuses to another sprint, or to the backlog if to is nil. It returns
+	// the number of done tasks and of tasks moved.
+	CarryOver(ctx context.Context, sprintID uuid.UUID, done []models.TaskStatus, to *uuid.UUID, userID uuid.UUID, at time.Time) (int, int, error)
+}
+
+// PostgresSprintRepository implements SprintRepository using PostgreSQL
+type PostgresSprintRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresSprintRepository creates a new PostgresSprintRepository
+func NewPostgresSprintRepository(db *sqlx.DB) SprintRepository {
+	return &PostgresSprintRepository{db: db}
+}
+
+// Create creates a new sprint
+func (r *PostgresSprintRepository) Create(ctx context.Context, sprint *models.Sprint) error {
+	query := `
+		INSERT INTO taskodex.sprints (
+			id, project_id, name, goal, state, start_date, end_date,
+			created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		sprint.ID,
+		sprint.ProjectID,
+		sprint.Name,
+		sprint.Goal,
+		sprint.State,
+		sprint.StartDate,
+		sprint.EndDate,
+		sprint.CreatedBy,
+		sprint.CreatedAt,
+		sprint.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert sprint: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a sprint by ID
+func (r *PostgresSprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Sprint, error) {
+	return r.get(ctx, "s.id = $1", id)
+}
+
+// GetActive retrieves the active sprint of a project
+func (r *PostgresSprintRepository) GetActive(ctx context.Context, projectID uuid.UUID) (*models.Sprint, error) {
+	return r.get(ctx, "s.project_id = $1 AND s.state = 'active'", projectID)
+}
+
+// get retrieves the sprint matching a condition
+func (r *PostgresSprintRepository) get(ctx context.Context, condition string, args ...interface{}) (*models.Sprint, error) {
+	query := "SELECT " + sprintColumns + " FROM taskodex.sprints s WHERE " + condition
+
+	var sprint models.Sprint
+	err := conn(ctx, r.db).GetContext(ctx, &sprint, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSprintNotFound
+		}
+		return nil, fmt.Errorf("failed to get sprint: %w", err)
+	}
+
+	return &sprint, nil
+}
+
+// List retrieves the sprints of a project
+func (r *PostgresSprintRepository) List(ctx context.Context, projectID uuid.UUID, state *models.SprintState) ([]models.Sprint, error) {
+	query := `
+		SELECT ` + sprintColumns + `
+		FROM taskodex.sprints s
+		WHERE s.project_id = $1 AND ($2::text IS NULL OR s.state = $2)
+		ORDER BY CASE s.state WHEN 'active' THEN 0 WHEN 'planned' THEN 1 ELSE 2 END,
+			s.start_date NULLS LAST, s.created_at
+	`
+
+	sprints := []models.Sprint{}
+	err := conn(ctx, r.db).SelectContext(ctx, &sprints, query, projectID, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprints: %w", err)
+	}
+
+	return sprints, nil
+}
+
+// Update updates the name, goal and dates of a sprint
+func (r *PostgresSprintRepository) Update(ctx context.Context, sprint *models.Sprint) error {
+	query := `
+		UPDATE taskodex.sprints
+		SET name = $1, goal = $2, start_date = $3, end_date = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	sprint.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		sprint.Name,
+		sprint.Goal,
+		sprint.StartDate,
+		sprint.EndDate,
+		sprint.UpdatedAt,
+		sprint.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sprint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSprintNotFound
+	}
+
+	return nil
+}
+
+// SetState saves the state of a sprint, provided it is still in state from
+func (r *PostgresSprintRepository) SetState(ctx context.Context, sprint *models.Sprint, from models.SprintState) error {
+	query := `
+		UPDATE taskodex.sprints
+		SET state = $1, start_date = $2, end_date = $3, started_at = $4, closed_at = $5,
+			completed_tasks = $6, carried_over_tasks = $7, updated_at = $8
+		WHERE id = $9 AND state = $10
+	`
+
+	sprint.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		sprint.State,
+		sprint.StartDate,
+		sprint.EndDate,
+		sprint.StartedAt,
+		sprint.ClosedAt,
+		sprint.CompletedTasks,
+		sprint.CarriedOverTasks,
+		sprint.UpdatedAt,
+		sprint.ID,
+		from,
+	)
+	if err != nil {
+		// At most one sprint of a project is active
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "uq_sprints_active" {
+			return ErrActiveSprintExists
+		}
+		return fmt.Errorf("failed to update sprint state: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSprintStateChanged
+	}
+
+	return nil
+}
+
+// Delete deletes a sprint
+func (r *PostgresSprintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.sprints WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSprintNotFound
+	}
+
+	return nil
+}
+
+// AddTasks moves tasks of the sprint's project into a sprint
+func (r *PostgresSprintRepository) AddTasks(ctx context.Context, sprint *models.Sprint, taskIDs []uuid.UUID, userID uuid.UUID) (int, error) {
+	moved, err := r.moveTasks(
+		ctx,
+		&sprint.ID,
+		userID,
+		time.Now(),
+		"t.id = ANY($6::uuid[]) AND t.project_id = $7",
+		pq.Array(taskIDs),
+		sprint.ProjectID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add tasks to sprint: %w", err)
+	}
+
+	return moved, nil
+}
+
+// RemoveTask returns a task of a sprint to the backlog
+func (r *PostgresSprintRepository) RemoveTask(ctx context.Context, sprintID, taskID, userID uuid.UUID) error {
+	moved, err := r.moveTasks(ctx, nil, userID, time.Now(), "t.id = $6 AND t.sprint_id = $7", taskID, sprintID)
+	if err != nil {
+		return fmt.Errorf("failed to remove task from sprint: %w", err)
+	}
+	if moved == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// CarryOver moves the unfinished tasks of a sprint on
+func (r *PostgresSprintRepository) CarryOver(ctx context.Context, sprintID uuid.UUID, done []models.TaskStatus, to *uuid.UUID, userID uuid.UUID, at time.Time) (int, int, error) {
+	statuses := make([]string, 0, len(done))
+	for _, status := range done {
+		statuses = append(statuses, string(status))
+	}
+
+	var completed int
+	err := conn(ctx, r.db).GetContext(
+		ctx,
+		&completed,
+		"SELECT COUNT(*) FROM taskodex.tasks WHERE sprint_id = $1 AND status = ANY($2::text[]) AND deleted_at IS NULL",
+		sprintID,
+		pq.Array(statuses),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count completed tasks: %w", err)
+	}
+
+	carriedOver, err := r.moveTasks(
+		ctx,
+		to,
+		userID,
+		at,
+		"t.sprint_id = $6 AND NOT (t.status = ANY($7::text[]))",
+		sprintID,
+		pq.Array(statuses),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to carry over tasks: %w", err)
+	}
+
+	return completed, carriedOver, nil
+}
+
+// moveTasks moves the tasks matching condition to a sprint, or to the backlog
+// if to is nil, and records the change in the history of the tasks that
+// changed sprint. The condition refers to the task as t and to its arguments
+// from $6; tasks in the trash never match. It returns the number of tasks matched.
+func (r *PostgresSprintRepository) moveTasks(ctx context.Context, to *uuid.UUID, userID uuid.UUID, at time.Time, condition string, args ...interface{}) (int, error) {
+	// The joined copy of the row holds the sprint before the update
+	query := `
+		WITH moved AS (
+			UPDATE taskodex.tasks t
+			SET sprint_id = $1, updated_at = $2
+			FROM taskodex.tasks prev
+			WHERE prev.id = t.id AND t.deleted_at IS NULL AND ` + condition + `
+			RETURNING t.id, prev.sprint_id AS prev_sprint_id
+		), history AS (
+			INSERT INTO taskodex.task_history (task_id, user_id, action, field_name, old_value, new_value, created_at)
+			SELECT id, $3, $4, $5, prev_sprint_id::text, $1::text, $2
+			FROM moved
+			WHERE prev_sprint_id IS DISTINCT FROM $1
+		)
+		SELECT COUNT(*) FROM moved
+	`
+
+	args = append([]interface{}{to, at, userID, models.TaskHistoryActionUpdated, models.TaskFieldSprintID}, args...)
+
+	var moved int
+	if err := conn(ctx, r.db).GetContext(ctx, &moved, query, args...); err != nil {
+		return 0, err
+	}
+
+	return moved, nil
+}
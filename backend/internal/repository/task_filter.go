@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// taskPriorityRankSQL orders task priorities from low to critical
+const taskPriorityRankSQL = "(CASE t.priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'critical' THEN 4 END)"
+
+// taskFilterColumns maps the task filter fields stored in a column of the task
+var taskFilterColumns = map[models.TaskFilterField]string{
+	models.TaskFilterFieldStatus:   "t.status",
+	models.TaskFilterFieldAssignee: "t.assigned_to",
+	models.TaskFilterFieldCreator:  "t.created_by",
+	models.TaskFilterFieldProject:  "t.project_id",
+	models.TaskFilterFieldDue:      "t.due_date",
+	models.TaskFilterFieldCreated:  "t.created_at",
+	models.TaskFilterFieldUpdated:  "t.updated_at",
+}
+
+// taskFilterCompiler compiles a parsed task filter into a parameterized SQL
+// condition on the tasks table aliased t
+type taskFilterCompiler struct {
+	args   []interface{}
+	userID *uuid.UUID
+	now    time.Time
+}
+
+// arg adds a query argument and returns its placeholder
+func (c *taskFilterCompiler) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// compile compiles a filter and its operands
+func (c *taskFilterCompiler) compile(filter *models.TaskFilter) (string, error) {
+	if filter.Condition != nil {
+		return c.condition(filter.Condition)
+	}
+
+	operands := make([]string, 0, len(filter.Operands))
+	for _, operand := range filter.Operands {
+		sql, err := c.compile(operand)
+		if err != nil {
+			return "", err
+		}
+		operands = append(operands, sql)
+	}
+
+	switch filter.Op {
+	case models.TaskFilterOpAnd:
+		return "(" + strings.Join(operands, " AND ") + ")", nil
+	case models.TaskFilterOpOr:
+		return "(" + strings.Join(operands, " OR ") + ")", nil
+	case models.TaskFilterOpNot:
+		return "NOT " + operands[0], nil
+	}
+
+	return "", fmt.Errorf("unknown task filter operation %q", filter.Op)
+}
+
+// condition compiles a condition on a task field
+func (c *taskFilterCompiler) condition(condition *models.TaskFilterCondition) (string, error) {
+	switch condition.Field {
+	case models.TaskFilterFieldStatus:
+		return c.compare("t.status", condition.Operator, condition.Values), nil
+
+	case models.TaskFilterFieldPriority:
+		switch condition.Operator {
+		case models.TaskFilterOperatorEq, models.TaskFilterOperatorNeq,
+			models.TaskFilterOperatorIn, models.TaskFilterOperatorNotIn:
+			return c.compare("t.priority", condition.Operator, condition.Values), nil
+		}
+		rank := models.TaskPriorityRank[models.TaskPriority(condition.Values[0])]
+		return fmt.Sprintf("%s %s %s", taskPriorityRankSQL, condition.Operator, c.arg(rank)), nil
+
+	case models.TaskFilterFieldAssignee, models.TaskFilterFieldCreator, models.TaskFilterFieldProject:
+		return c.compareIDs(taskFilterColumns[condition.Field], condition.Operator, condition.Values)
+
+	case models.TaskFilterFieldTag:
+		var sql string
+		if len(condition.Values) == 1 {
+			sql = "EXISTS (SELECT 1 FROM taskodex.task_tags tt WHERE tt.task_id = t.id AND tt.tag = " + c.arg(condition.Values[0]) + ")"
+		} else {
+			sql = "EXISTS (SELECT 1 FROM taskodex.task_tags tt WHERE tt.task_id = t.id AND tt.tag = ANY(" + c.arg(pq.Array(condition.Values)) + "))"
+		}
+		if condition.Operator == models.TaskFilterOperatorNeq || condition.Operator == models.TaskFilterOperatorNotIn {
+			sql = "NOT " + sql
+		}
+		return sql, nil
+
+	case models.TaskFilterFieldDue, models.TaskFilterFieldCreated, models.TaskFilterFieldUpdated:
+		return c.compareDates(taskFilterColumns[condition.Field], condition.Operator, condition.Values[0])
+
+	case models.TaskFilterFieldText:
+		tsquery := prefixQuery(condition.Values[0])
+		if tsquery == "" {
+			return "TRUE", nil
+		}
+		return "t.search_vector @@ to_tsquery('english', " + c.arg(tsquery) + ")", nil
+
+	case models.TaskFilterFieldIs:
+		closed := taskClosedSQL(c.arg(pq.Array(defaultClosedStatuses())))
+		if condition.Values[0] == models.TaskFilterValueOpen {
+			return "NOT " + closed, nil
+		}
+		return closed, nil
+	}
+
+	return "", fmt.Errorf("unknown task filter field %q", condition.Field)
+}
+
+// defaultClosedStatuses returns the statuses of the closed states of the
+// default workflow
+func defaultClosedStatuses() []string {
+	statuses := []string{}
+	for _, state := range models.DefaultWorkflow().States {
+		if state.Category == models.WorkflowStateCategoryClosed {
+			statuses = append(statuses, string(state.Key))
+		}
+	}
+	return statuses
+}
+
+// taskClosedSQL returns a condition that holds if the task aliased t is in a
+// closed state of its workflow. Tasks without a project, or whose project has
+// no workflow, use the default workflow, whose closed statuses are passed as
+// the placeholder defaultClosed.
+func taskClosedSQL(defaultClosed string) string {
+	return `(CASE WHEN EXISTS (SELECT 1 FROM taskodex.workflows w WHERE w.project_id = t.project_id)
+			THEN EXISTS (
+				SELECT 1 FROM taskodex.workflows w
+				JOIN taskodex.workflow_states s ON s.workflow_id = w.id
+				WHERE w.project_id = t.project_id AND s.key = t.status AND s.category = 'closed'
+			)
+			ELSE t.status = ANY(` + defaultClosed + `) END)`
+}
+
+// compare compiles an equality or list comparison of a text column
+func (c *taskFilterCompiler) compare(column string, operator models.TaskFilterOperator, values []string) string {
+	switch operator {
+	case models.TaskFilterOperatorNeq:
+		return column + " <> " + c.arg(values[0])
+	case models.TaskFilterOperatorIn:
+		return column + " = ANY(" + c.arg(pq.Array(values)) + ")"
+	case models.TaskFilterOperatorNotIn:
+		return column + " <> ALL(" + c.arg(pq.Array(values)) + ")"
+	}
+	return column + " = " + c.arg(values[0])
+}
+
+// compareIDs compiles a comparison of an ID column, resolving me and none
+func (c *taskFilterCompiler) compareIDs(column string, operator models.TaskFilterOperator, values []string) (string, error) {
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		switch value {
+		case models.TaskFilterValueNone:
+			if operator == models.TaskFilterOperatorNeq {
+				return column + " IS NOT NULL", nil
+			}
+			return column + " IS NULL", nil
+		case models.TaskFilterValueMe:
+			if c.userID == nil {
+				return "", fmt.Errorf("%w: \"me\" requires a signed-in user", models.ErrInvalidTaskFilter)
+			}
+			ids = append(ids, c.userID.String())
+		default:
+			ids = append(ids, value)
+		}
+	}
+
+	// Unset IDs never equal a value, so they match the negated comparisons
+	switch operator {
+	case models.TaskFilterOperatorNeq:
+		return column + " IS DISTINCT FROM " + c.arg(ids[0]) + "::uuid", nil
+	case models.TaskFilterOperatorIn:
+		return column + " = ANY(" + c.arg(pq.Array(ids)) + "::uuid[])", nil
+	case models.TaskFilterOperatorNotIn:
+		return "(" + column + " IS NULL OR " + column + " <> ALL(" + c.arg(pq.Array(ids)) + "::uuid[]))", nil
+	}
+	return column + " = " + c.arg(ids[0]) + "::uuid", nil
+}
+
+// compareDates compiles a comparison of a timestamp column. A whole day
+// compares as the range from its start to the start of the next day.
+func (c *taskFilterCompiler) compareDates(column string, operator models.TaskFilterOperator, value string) (string, error) {
+	if value == models.TaskFilterValueNone {
+		if operator == models.TaskFilterOperatorNeq {
+			return column + " IS NOT NULL", nil
+		}
+		return column + " IS NULL", nil
+	}
+
+	start, wholeDay, err := models.ResolveTaskFilterDate(value, c.now)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", models.ErrInvalidTaskFilter, err)
+	}
+	if !wholeDay {
+		return fmt.Sprintf("%s %s %s", column, operator, c.arg(start)), nil
+	}
+
+	end := start.AddDate(0, 0, 1)
+	switch operator {
+	case models.TaskFilterOperatorEq:
+		return fmt.Sprintf("(%s >= %s AND %s < %s)", column, c.arg(start), column, c.arg(end)), nil
+	case models.TaskFilterOperatorNeq:
+		return fmt.Sprintf("NOT (%s >= %s AND %s < %s)", column, c.arg(start), column, c.arg(end)), nil
+	case models.TaskFilterOperatorLt:
+		return column + " < " + c.arg(start), nil
+	case models.TaskFilterOperatorLte:
+		return column + " < " + c.arg(end), nil
+	case models.TaskFilterOperatorGt:
+		return column + " >= " + c.arg(end), nil
+	case models.TaskFilterOperatorGte:
+		return column + " >= " + c.arg(start), nil
+	}
+
+	return "", errors.New("unsupported date comparison")
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// TaskHistoryRepository defines the interface for task history data access
+type TaskHistoryRepository interface {
+	// Create records task history entries
+	Create(ctx context.Context, entries ...*models.TaskHistory) error
+
+	// RecordChanges records field changes made by a user to a task, one entry
+	// per change
+	RecordChanges(ctx context.Context, taskID, userID uuid.UUID, changes []models.TaskFieldChange) error
+
+	// ListActivity retrieves the activity feed of a task or project: history
+	// entries, comments and file attachments merged and ordered newest first
+	ListActivity(ctx context.Context, params models.ActivityListParams) ([]models.Activity, int, error)
+}
+
+// PostgresTaskHistoryRepository implements TaskHistoryRepository using PostgreSQL
+type PostgresTaskHistoryRepository struct {
+	db                 *sqlx.DB
+	commentRepo        CommentRepository
+	fileAttachmentRepo FileAttachmentRepository
+	userRepo           UserRepository
+}
+
+// NewPostgresTaskHistoryRepository creates a new PostgresTaskHistoryRepository
+func NewPostgresTaskHistoryRepository(db *sqlx.DB) TaskHistoryRepository {
+	return &PostgresTaskHistoryRepository{
+		db:                 db,
+		commentRepo:        NewPostgresCommentRepository(db),
+		fileAttachmentRepo: NewPostgresFileAttachmentRepository(db),
+		userRepo:           NewPostgresUserRepository(db),
+	}
+}
+
+// Create records task history entries
+func (r *PostgresTaskHistoryRepository) Create(ctx context.Context, entries ...*models.TaskHistory) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		query := `
+			INSERT INTO taskodex.task_history (
+				id, task_id, user_id, action, field_name, old_value, new_value, created_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+
+		for _, entry := range entries {
+			_, err := conn(ctx, r.db).ExecContext(
+				ctx,
+				query,
+				entry.ID,
+				entry.TaskID,
+				entry.UserID,
+				entry.Action,
+				entry.FieldName,
+				entry.OldValue,
+				entry.NewValue,
+				entry.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert task history: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RecordChanges records field changes made by a user to a task
+func (r *PostgresTaskHistoryRepository) RecordChanges(ctx context.Context, taskID, userID uuid.UUID, changes []models.TaskFieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entries := make([]*models.TaskHistory, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, models.NewTaskHistory(taskID, userID, change))
+	}
+
+	return r.Create(ctx, entries...)
+}
+
+// activityRow is an item of the merged activity query
+type activityRow struct {
+	Type models.ActivityType `db:"type"`
+	ID   uuid.UUID           `db:"id"`
+}
+
+// ListActivity retrieves the activity feed of a task or project
+func (r *PostgresTaskHistoryRepository) ListActivity(ctx context.Context, params models.ActivityListParams) ([]models.Activity, int, error) {
+	var scope string
+	var scopeID uuid.UUID
+	switch {
+	case params.TaskID != nil:
+		scope = "t.id = $1"
+		scopeID = *params.TaskID
+	case params.ProjectID != nil:
+		scope = "t.project_id = $1"
+		scopeID = *params.ProjectID
+	default:
+		return nil, 0, errors.New("activity feed requires a task or project")
+	}
+
+	baseQuery := `
+		FROM (
+			SELECT 'history' AS type, h.id, h.task_id, h.created_at FROM taskodex.task_history h
+			UNION ALL
+			SELECT 'comment' AS type, c.id, c.task_id, c.created_at FROM taskodex.task_comments c
+			UNION ALL
+			SELECT 'attachment' AS type, a.id, a.task_id, a.created_at FROM taskodex.task_file_attachments a
+		) activity
+		JOIN taskodex.tasks t ON t.id = activity.task_id
+		WHERE t.deleted_at IS NULL AND ` + scope
+
+	// Count total records
+	var total int
+	err := conn(ctx, r.db).GetContext(ctx, &total, "SELECT COUNT(*) "+baseQuery, scopeID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count activity: %w", err)
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	offset := (params.Page - 1) * params.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT activity.type, activity.id
+		%s
+		ORDER BY activity.created_at DESC, activity.id
+		LIMIT %d OFFSET %d
+	`, baseQuery, params.PageSize, offset)
+
+	rows := []activityRow{}
+	err = conn(ctx, r.db).SelectContext(ctx, &rows, query, scopeID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query activity: %w", err)
+	}
+
+	// Load the items of the page, a batch per type
+	var historyIDs, commentIDs, attachmentIDs []uuid.UUID
+	for _, row := range rows {
+		switch row.Type {
+		case models.ActivityTypeHistory:
+			historyIDs = append(historyIDs, row.ID)
+		case models.ActivityTypeComment:
+			commentIDs = append(commentIDs, row.ID)
+		case models.ActivityTypeAttachment:
+			attachmentIDs = append(attachmentIDs, row.ID)
+		}
+	}
+
+	entries, err := r.getByIDs(ctx, historyIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	comments, err := r.commentRepo.GetByIDs(ctx, commentIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	attachments, err := r.fileAttachmentRepo.GetByIDs(ctx, attachmentIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Items removed since the page was read are left out
+	activities := make([]models.Activity, 0, len(rows))
+	for _, row := range rows {
+		activity := models.Activity{Type: row.Type}
+
+		switch row.Type {
+		case models.ActivityTypeHistory:
+			entry, ok := entries[row.ID]
+			if !ok {
+				continue
+			}
+			activity.History = entry
+			activity.TaskID = entry.TaskID
+			activity.CreatedAt = entry.CreatedAt
+
+		case models.ActivityTypeComment:
+			comment, ok := comments[row.ID]
+			if !ok {
+				continue
+			}
+			activity.Comment = comment
+			activity.TaskID = comment.TaskID
+			activity.CreatedAt = comment.CreatedAt
+
+		case models.ActivityTypeAttachment:
+			attachment, ok := attachments[row.ID]
+			if !ok {
+				continue
+			}
+			activity.Attachment = attachment
+			activity.TaskID = attachment.TaskID
+			activity.CreatedAt = attachment.CreatedAt
+		}
+
+		activities = append(activities, activity)
+	}
+
+	return activities, total, nil
+}
+
+// getByIDs retrieves task history entries by ID with their users, keyed by ID
+func (r *PostgresTaskHistoryRepository) getByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.TaskHistory, error) {
+	result := make(map[uuid.UUID]*models.TaskHistory, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	entryIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		entryIDs = append(entryIDs, id.String())
+	}
+
+	query := `
+		SELECT h.id, h.task_id, h.user_id, h.action, h.field_name, h.old_value, h.new_value, h.created_at
+		FROM taskodex.task_history h
+		WHERE h.id = ANY($1::uuid[])
+	`
+
+	var entries []models.TaskHistory
+	err := conn(ctx, r.db).SelectContext(ctx, &entries, query, pq.Array(entryIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(entries))
+	for i := range entries {
+		result[entries[i].ID] = &entries[i]
+		userIDs = append(userIDs, entries[i].UserID)
+	}
+
+	users, err := r.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, entry := range result {
+		entry.User = users[entry.UserID]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for task link repository
+var (
+	ErrTaskLinkNotFound = errors.New("task link not found")
+	ErrTaskLinkExists   = errors.New("task link already exists")
+	ErrTaskLinkCycle    = errors.New("task link would create a blocking cycle")
+)
+
+// taskLinkLockKey serializes the creation of blocking links so that two
+// concurrent links cannot close a cycle between them
+const taskLinkLockKey = "taskodex.task_links.blocks"
+
+// taskSummaryColumns are the task columns loaded for linked tasks
+const taskSummaryColumns = `
+	t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority,
+	t.due_date, t.created_by, t.assigned_to, t.estimated_hours,
+	t.actual_hours, t.created_at, t.updated_at
+`
+
+// TaskLinkRepository defines the interface for task link data access
+type TaskLinkRepository interface {
+	// Create creates a new task link
+	Create(ctx context.Context, link *models.TaskLink) error
+
+	// GetByID retrieves a task link by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.TaskLink, error)
+
+	// Delete deletes a task link
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByTask retrieves all links from or to a task, with the linked tasks loaded
+	ListByTask(ctx context.Context, taskID uuid.UUID) ([]models.TaskLink, error)
+
+	// ListByProject retrieves the links between tasks of a project
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.TaskLink, error)
+
+	// ListBlockers retrieves the tasks that block a task
+	ListBlockers(ctx context.Context, taskID uuid.UUID) ([]models.Task, error)
+
+	// ListDependents retrieves the tasks that are blocked by a task
+	ListDependents(ctx context.Context, taskID uuid.UUID) ([]models.Task, error)
+}
+
+// PostgresTaskLinkRepository implements TaskLinkRepository using PostgreSQL
+type PostgresTaskLinkRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTaskLinkRepository creates a new PostgresTaskLinkRepository
+func NewPostgresTaskLinkRepository(db *sqlx.DB) TaskLinkRepository {
+	return &PostgresTaskLinkRepository{db: db}
+}
+
+// Create creates a new task link.
+// Blocking links that would make a task (transitively) block itself are
+// rejected with ErrTaskLinkCycle.
+func (r *PostgresTaskLinkRepository) Create(ctx context.Context, link *models.TaskLink) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		// Check that both tasks exist
+		var count int
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&count,
+			"SELECT COUNT(*) FROM taskodex.tasks WHERE id IN ($1, $2) AND deleted_at IS NULL",
+			link.SourceTaskID,
+			link.TargetTaskID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check if tasks exist: %w", err)
+		}
+		if count != 2 {
+			return ErrTaskNotFound
+		}
+
+		if link.Type == models.TaskLinkTypeBlocks {
+			_, err = conn(ctx, r.db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", taskLinkLockKey)
+			if err != nil {
+				return fmt.Errorf("failed to lock task links: %w", err)
+			}
+
+			// Adding source -> target closes a cycle if target already reaches source
+			cycle, err := r.blockingPathExists(ctx, link.TargetTaskID, link.SourceTaskID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return ErrTaskLinkCycle
+			}
+		}
+
+		// Check if link already exists
+		var exists bool
+		err = conn(ctx, r.db).GetContext(
+			ctx,
+			&exists,
+			`SELECT EXISTS(
+				SELECT 1 FROM taskodex.task_links
+				WHERE source_task_id = $1 AND target_task_id = $2 AND type = $3
+			)`,
+			link.SourceTaskID,
+			link.TargetTaskID,
+			link.Type,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check if task link exists: %w", err)
+		}
+		if exists {
+			return ErrTaskLinkExists
+		}
+
+		// Insert link
+		query := `
+			INSERT INTO taskodex.task_links (
+				id, source_task_id, target_task_id, type, created_by, created_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			link.ID,
+			link.SourceTaskID,
+			link.TargetTaskID,
+			link.Type,
+			link.CreatedBy,
+			link.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert task link: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// blockingPathExists reports whether from (transitively) blocks to
+func (r *PostgresTaskLinkRepository) blockingPathExists(ctx context.Context, from, to uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE blocked(task_id) AS (
+			SELECT target_task_id FROM taskodex.task_links
+			WHERE source_task_id = $1 AND type = 'blocks'
+			UNION
+			SELECT l.target_task_id FROM taskodex.task_links l
+			JOIN blocked b ON l.source_task_id = b.task_id
+			WHERE l.type = 'blocks'
+		)
+		SELECT EXISTS(SELECT 1 FROM blocked WHERE task_id = $2)
+	`
+
+	var exists bool
+	err := conn(ctx, r.db).GetContext(ctx, &exists, query, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for blocking cycle: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetByID retrieves a task link by ID
+func (r *PostgresTaskLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskLink, error) {
+	query := `
+		SELECT id, source_task_id, target_task_id, type, created_by, created_at
+		FROM taskodex.task_links
+		WHERE id = $1
+	`
+
+	var link models.TaskLink
+	err := conn(ctx, r.db).GetContext(ctx, &link, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get task link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// Delete deletes a task link
+func (r *PostgresTaskLinkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.task_links WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task link: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskLinkNotFound
+	}
+
+	return nil
+}
+
+// ListByTask retrieves all links from or to a task, with the linked tasks
+// loaded. Links to tasks in the trash are left out.
+func (r *PostgresTaskLinkRepository) ListByTask(ctx context.Context, taskID uuid.UUID) ([]models.TaskLink, error) {
+	query := `
+		SELECT l.id, l.source_task_id, l.target_task_id, l.type, l.created_by, l.created_at
+		FROM taskodex.task_links l
+		JOIN taskodex.tasks s ON s.id = l.source_task_id
+		JOIN taskodex.tasks t ON t.id = l.target_task_id
+		WHERE (l.source_task_id = $1 OR l.target_task_id = $1)
+			AND s.deleted_at IS NULL AND t.deleted_at IS NULL
+		ORDER BY l.created_at
+	`
+
+	var links []models.TaskLink
+	err := conn(ctx, r.db).SelectContext(ctx, &links, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task links: %w", err)
+	}
+
+	if len(links) == 0 {
+		return links, nil
+	}
+
+	// Load the linked tasks in one query
+	ids := make([]string, 0, len(links)*2)
+	for _, link := range links {
+		ids = append(ids, link.SourceTaskID.String(), link.TargetTaskID.String())
+	}
+
+	var tasks []models.Task
+	err = conn(ctx, r.db).SelectContext(
+		ctx,
+		&tasks,
+		"SELECT "+taskSummaryColumns+" FROM taskodex.tasks t WHERE t.id = ANY($1::uuid[])",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked tasks: %w", err)
+	}
+
+	taskMap := make(map[uuid.UUID]*models.Task, len(tasks))
+	for i := range tasks {
+		taskMap[tasks[i].ID] = &tasks[i]
+	}
+
+	for i := range links {
+		links[i].SourceTask = taskMap[links[i].SourceTaskID]
+		links[i].TargetTask = taskMap[links[i].TargetTaskID]
+	}
+
+	return links, nil
+}
+
+// ListByProject retrieves the links between tasks of a project
+func (r *PostgresTaskLinkRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.TaskLink, error) {
+	query := `
+		SELECT l.id, l.source_task_id, l.target_task_id, l.type, l.created_by, l.created_at
+		FROM taskodex.task_links l
+		JOIN taskodex.tasks s ON s.id = l.source_task_id
+		JOIN taskodex.tasks t ON t.id = l.target_task_id
+		WHERE s.project_id = $1 AND t.project_id = $1
+			AND s.deleted_at IS NULL AND t.deleted_at IS NULL
+		ORDER BY l.created_at, l.id
+	`
+
+	links := []models.TaskLink{}
+	err := conn(ctx, r.db).SelectContext(ctx, &links, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task links: %w", err)
+	}
+
+	return links, nil
+}
+
+// ListBlockers retrieves the tasks that block a task
+func (r *PostgresTaskLinkRepository) ListBlockers(ctx context.Context, taskID uuid.UUID) ([]models.Task, error) {
+	query := `
+		SELECT ` + taskSummaryColumns + `
+		FROM taskodex.tasks t
+		JOIN taskodex.task_links l ON l.source_task_id = t.id
+		WHERE l.target_task_id = $1 AND l.type = 'blocks' AND t.deleted_at IS NULL
+		ORDER BY t.created_at
+	`
+
+	var tasks []models.Task
+	err := conn(ctx, r.db).SelectContext(ctx, &tasks, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocking tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListDependents retrieves the tasks that are blocked by a task
+func (r *PostgresTaskLinkRepository) ListDependents(ctx context.Context, taskID uuid.UUID) ([]models.Task, error) {
+	query := `
+		SELECT ` + taskSummaryColumns + `
+		FROM taskodex.tasks t
+		JOIN taskodex.task_links l ON l.target_task_id = t.id
+		WHERE l.source_task_id = $1 AND l.type = 'blocks' AND t.deleted_at IS NULL
+		ORDER BY t.created_at
+	`
+
+	var tasks []models.Task
+	err := conn(ctx, r.db).SelectContext(ctx, &tasks, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependent tasks: %w", err)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for task recurrence repository
+var (
+	ErrTaskRecurrenceNotFound = errors.New("task recurrence not found")
+	ErrTaskOccurrenceNotFound = errors.New("task occurrence not found")
+)
+
+// taskRecurrenceColumns are the columns of a task recurrence aliased r
+const taskRecurrenceColumns = `r.id, r.task_id, r.rule, r.mode, r.starts_at, r.timezone, r.lead_days,
+	r.next_occurrence, r.occurrence_count, r.last_task_id, r.finished, r.created_by,
+	r.created_at, r.updated_at`
+
+// TaskRecurrenceRepository defines the interface for task recurrence data access
+type TaskRecurrenceRepository interface {
+	// Create creates a new task recurrence
+	Create(ctx context.Context, recurrence *models.TaskRecurrence) error
+
+	// GetByTask retrieves the recurrence a task is the template or an
+	// instance of
+	GetByTask(ctx context.Context, taskID uuid.UUID) (*models.TaskRecurrence, error)
+
+	// Update updates a task recurrence
+	Update(ctx context.Context, recurrence *models.TaskRecurrence) error
+
+	// Delete deletes a task recurrence and its occurrence records. Generated
+	// tasks are kept.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ClaimDue locks and returns a recurrence with an instance to generate at
+	// now: a recurrence on a schedule whose next occurrence is within its lead
+	// days, or a recurrence on completion whose latest instance is closed or
+	// deleted. For the latter, it also returns when the instance was closed,
+	// if known. Recurrences locked by another transaction and the excluded
+	// ones are skipped. It must be called inside a transaction, which holds
+	// the lock; it returns a nil recurrence if none is due.
+	ClaimDue(ctx context.Context, now time.Time, exclude []uuid.UUID) (*models.TaskRecurrence, *time.Time, error)
+
+	// CreateOccurrence records a handled occurrence
+	CreateOccurrence(ctx context.Context, occurrence *models.TaskOccurrence) error
+
+	// GetOccurrence retrieves the record of an occurrence
+	GetOccurrence(ctx context.Context, recurrenceID uuid.UUID, occursAt time.Time) (*models.TaskOccurrence, error)
+
+	// ListOccurrences retrieves the records of the occurrences of a
+	// recurrence at or after a time, earliest first
+	ListOccurrences(ctx context.Context, recurrenceID uuid.UUID, from time.Time) ([]models.TaskOccurrence, error)
+}
+
+// PostgresTaskRecurrenceRepository implements TaskRecurrenceRepository using PostgreSQL
+type PostgresTaskRecurrenceRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTaskRecurrenceRepository creates a new PostgresTaskRecurrenceRepository
+func NewPostgresTaskRecurrenceRepository(db *sqlx.DB) TaskRecurrenceRepository {
+	return &PostgresTaskRecurrenceRepository{db: db}
+}
+
+// Create creates a new task recurrence
+func (r *PostgresTaskRecurrenceRepository) Create(ctx context.Context, recurrence *models.TaskRecurrence) error {
+	query := `
+		INSERT INTO taskodex.task_recurrences (
+			id, task_id, rule, mode, starts_at, timezone, lead_days, next_occurrence,
+			occurrence_count, last_task_id, finished, created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		recurrence.ID,
+		recurrence.TaskID,
+		recurrence.Rule,
+		recurrence.Mode,
+		recurrence.StartsAt,
+		recurrence.Timezone,
+		recurrence.LeadDays,
+		recurrence.NextOccurrence,
+		recurrence.OccurrenceCount,
+		recurrence.LastTaskID,
+		recurrence.Finished,
+		recurrence.CreatedBy,
+		recurrence.CreatedAt,
+		recurrence.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert task recurrence: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTask retrieves the recurrence a task is the template or an instance of
+func (r *PostgresTaskRecurrenceRepository) GetByTask(ctx context.Context, taskID uuid.UUID) (*models.TaskRecurrence, error) {
+	query := `
+		SELECT ` + taskRecurrenceColumns + `
+		FROM taskodex.task_recurrences r
+		WHERE r.task_id = $1 OR r.id = (
+			SELECT o.recurrence_id FROM taskodex.task_occurrences o WHERE o.task_id = $1
+		)
+		LIMIT 1
+	`
+
+	var recurrence models.TaskRecurrence
+	err := conn(ctx, r.db).GetContext(ctx, &recurrence, query, taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskRecurrenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get task recurrence: %w", err)
+	}
+
+	return &recurrence, nil
+}
+
+// Update updates a task recurrence
+func (r *PostgresTaskRecurrenceRepository) Update(ctx context.Context, recurrence *models.TaskRecurrence) error {
+	query := `
+		UPDATE taskodex.task_recurrences
+		SET rule = $1, mode = $2, starts_at = $3, timezone = $4, lead_days = $5,
+			next_occurrence = $6, occurrence_count = $7, last_task_id = $8,
+			finished = $9, updated_at = $10
+		WHERE id = $11
+	`
+
+	recurrence.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		recurrence.Rule,
+		recurrence.Mode,
+		recurrence.StartsAt,
+		recurrence.Timezone,
+		recurrence.LeadDays,
+		recurrence.NextOccurrence,
+		recurrence.OccurrenceCount,
+		recurrence.LastTaskID,
+		recurrence.Finished,
+		recurrence.UpdatedAt,
+		recurrence.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update task recurrence: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskRecurrenceNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a task recurrence
+func (r *PostgresTaskRecurrenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.task_recurrences WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task recurrence: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskRecurrenceNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue locks and returns a recurrence with an instance to generate. SKIP
+// LOCKED lets several schedulers claim different recurrences concurrently.
+func (r *PostgresTaskRecurrenceRepository) ClaimDue(ctx context.Context, now time.Time, exclude []uuid.UUID) (*models.TaskRecurrence, *time.Time, error) {
+	if _, ok := TxFromContext(ctx); !ok {
+		return nil, nil, errors.New("claiming a task recurrence requires a transaction")
+	}
+
+	excluded := make([]string, 0, len(exclude))
+	for _, id := range exclude {
+		excluded = append(excluded, id.String())
+	}
+
+	// Lead days are subtracted in the recurrence's time zone, so a day is
+	// always a calendar day. Recurrences of tasks in the trash or in archived
+	// projects are paused, and a latest instance in the trash counts as gone.
+	query := `
+		SELECT ` + taskRecurrenceColumns + `,
+			(SELECT max(h.created_at) FROM taskodex.task_history h
+				WHERE h.task_id = t.id AND h.field_name = 'status') AS completed_at
+		FROM taskodex.task_recurrences r
+		LEFT JOIN taskodex.tasks t ON t.id = r.last_task_id AND t.deleted_at IS NULL
+		WHERE NOT r.finished AND r.id <> ALL($2::uuid[])
+			AND EXISTS (
+				SELECT 1 FROM taskodex.tasks src
+				LEFT JOIN taskodex.projects sp ON sp.id = src.project_id
+				WHERE src.id = r.task_id AND src.deleted_at IS NULL AND sp.archived_at IS NULL
+			)
+			AND (
+				(r.mode = 'schedule'
+					AND ((r.next_occurrence AT TIME ZONE r.timezone) - make_interval(days => r.lead_days)) AT TIME ZONE r.timezone <= $1)
+				OR (r.mode = 'completion' AND (t.id IS NULL OR ` + taskClosedSQL("$3") + `))
+			)
+		ORDER BY r.next_occurrence NULLS FIRST, r.id
+		LIMIT 1
+		FOR UPDATE OF r SKIP LOCKED
+	`
+
+	var claimed struct {
+		models.TaskRecurrence
+		CompletedAt *time.Time `db:"completed_at"`
+	}
+	err := conn(ctx, r.db).GetContext(ctx, &claimed, query, now, pq.Array(excluded), pq.Array(defaultClosedStatuses()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to claim task recurrence: %w", err)
+	}
+
+	return &claimed.TaskRecurrence, claimed.CompletedAt, nil
+}
+
+// CreateOccurrence records a handled occurrence
+func (r *PostgresTaskRecurrenceRepository) CreateOccurrence(ctx context.Context, occurrence *models.TaskOccurrence) error {
+	query := `
+		INSERT INTO taskodex.task_occurrences (recurrence_id, occurs_at, task_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		occurrence.RecurrenceID,
+		occurrence.OccursAt,
+		occurrence.TaskID,
+		occurrence.Status,
+		occurrence.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert task occurrence: %w", err)
+	}
+
+	return nil
+}
+
+// GetOccurrence retrieves the record of an occurrence
+func (r *PostgresTaskRecurrenceRepository) GetOccurrence(ctx context.Context, recurrenceID uuid.UUID, occursAt time.Time) (*models.TaskOccurrence, error) {
+	query := `
+		SELECT recurrence_id, occurs_at, task_id, status, created_at
+		FROM taskodex.task_occurrences
+		WHERE recurrence_id = $1 AND occurs_at = $2
+	`
+
+	var occurrence models.TaskOccurrence
+	err := conn(ctx, r.db).GetContext(ctx, &occurrence, query, recurrenceID, occursAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskOccurrenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get task occurrence: %w", err)
+	}
+
+	return &occurrence, nil
+}
+
+// ListOccurrences retrieves the records of the occurrences of a recurrence
+// at or after a time
+func (r *PostgresTaskRecurrenceRepository) ListOccurrences(ctx context.Context, recurrenceID uuid.UUID, from time.Time) ([]models.TaskOccurrence, error) {
+	query := `
+		SELECT recurrence_id, occurs_at, task_id, status, created_at
+		FROM taskodex.task_occurrences
+		WHERE recurrence_id = $1 AND occurs_at >= $2
+		ORDER BY occurs_at
+	`
+
+	occurrences := []models.TaskOccurrence{}
+	err := conn(ctx, r.db).SelectContext(ctx, &occurrences, query, recurrenceID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task occurrences: %w", err)
+	}
+
+	return occurrences, nil
+}
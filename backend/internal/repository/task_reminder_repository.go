@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// TaskReminderRepository defines the interface for task reminder data access
+type TaskReminderRepository interface {
+	// ListCandidates retrieves the open, assigned tasks due at or after from
+	// and before to, along with the time zones of their assignees
+	ListCandidates(ctx context.Context, from, to time.Time) ([]models.TaskReminderCandidate, error)
+
+	// Create records a reminder. It returns false if the reminder was already
+	// recorded.
+	Create(ctx context.Context, reminder *models.TaskReminder) (bool, error)
+}
+
+// PostgresTaskReminderRepository implements TaskReminderRepository using PostgreSQL
+type PostgresTaskReminderRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTaskReminderRepository creates a new PostgresTaskReminderRepository
+func NewPostgresTaskReminderRepository(db *sqlx.DB) TaskReminderRepository {
+	return &PostgresTaskReminderRepository{db: db}
+}
+
+// ListCandidates retrieves the open, assigned tasks due in a time range
+func (r *PostgresTaskReminderRepository) ListCandidates(ctx context.Context, from, to time.Time) ([]models.TaskReminderCandidate, error) {
+	query := `
+		SELECT t.id, t.project_id, t.parent_id, t.title, t.description, t.status, t.priority,
+			t.due_date, t.created_by, t.assigned_to, t.estimated_hours,
+			t.actual_hours, t.custom_fields, t.created_at, t.updated_at, u.timezone
+		FROM taskodex.tasks t
+		JOIN users u ON u.id = t.assigned_to
+		WHERE t.due_date >= $1 AND t.due_date < $2 AND t.deleted_at IS NULL
+			AND NOT ` + taskClosedSQL("$3") + `
+		ORDER BY t.due_date, t.id
+	`
+
+	candidates := []models.TaskReminderCandidate{}
+	err := conn(ctx, r.db).SelectContext(ctx, &candidates, query, from, to, pq.Array(defaultClosedStatuses()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task reminder candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// Create records a reminder, unless it was already recorded
+func (r *PostgresTaskReminderRepository) Create(ctx context.Context, reminder *models.TaskReminder) (bool, error) {
+	query := `
+		INSERT INTO taskodex.task_reminders (task_id, user_id, kind, threshold_days, due_date, sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		reminder.TaskID,
+		reminder.UserID,
+		reminder.Kind,
+		reminder.ThresholdDays,
+		reminder.DueDate,
+		reminder.SentAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert task reminder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows > 0, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -32,8 +33,17 @@ type TaskRepository interface {
 	// Update updates a task
 	Update(ctx context.Context, task *models.Task) error
 
-	// Delete deletes a task
-	Delete(ctx context.Context, id uuid.UUID) error
+	// UpdateSchedule updates the start date, due date and duration of a
+	// task, leaving the rest of it as it is
+	UpdateSchedule(ctx context.Context, task *models.Task) error
+
+	// LockSchedule locks a task and the other tasks of its project until the
+	// transaction in ctx ends, so that their schedule can be read and written
+	// without changes in between
+	LockSchedule(ctx context.Context, id uuid.UUID) error
+
+	// Delete moves a task to the trash, along with its subtasks
+	Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error
 
 	// AddTag adds a tag to a task
 	AddTag(ctx context.Context, taskID uuid.UUID, tag string) error
@@ -43,93 +53,106 @@ type TaskRepository interface {
 
 	// GetTags retrieves all tags for a task
 	GetTags(ctx context.Context, taskID uuid.UUID) ([]string, error)
+
+	// GetDescendants retrieves all subtasks of a task, at any depth
+	GetDescendants(ctx context.Context, id uuid.UUID) ([]models.Task, error)
+
+	// ReparentChildren moves the direct subtasks of a task to a new parent
+	ReparentChildren(ctx context.Context, parentID uuid.UUID, newParentID *uuid.UUID) error
+
+	// ListStatuses retrieves the distinct statuses of the tasks in a project
+	ListStatuses(ctx context.Context, projectID uuid.UUID) ([]models.TaskStatus, error)
 }
 
 // PostgresTaskRepository implements TaskRepository using PostgreSQL
 type PostgresTaskRepository struct {
-	db *sqlx.DB
+	db          *sqlx.DB
+	projectRepo ProjectRepository
+	userRepo    UserRepository
 }
 
 // NewPostgresTaskRepository creates a new PostgresTaskRepository
 func NewPostgresTaskRepository(db *sqlx.DB) TaskRepository {
-	return &PostgresTaskRepository{db: db}
+	return &PostgresTaskRepository{
+		db:          db,
+		projectRepo: NewPostgresProjectRepository(db),
+		userRepo:    NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new task
 func (r *PostgresTaskRepository) Create(ctx context.Context, task *models.Task) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		// New tasks are ranked last in their project
+		if task.Rank == "" {
+			rank, err := nextRank(ctx, r.db, task.ProjectID)
+			if err != nil {
+				return err
+			}
+			task.Rank = rank
 		}
-	}()
 
-	// Insert task
-	query := `
-		INSERT INTO taskodex.tasks (
-			id, project_id, title, description, status, priority,
-			due_date, created_by, assigned_to, estimated_hours,
-			actual_hours, created_at, updated_at
+		// Insert task
+		query := `
+			INSERT INTO taskodex.tasks (
+				id, project_id, parent_id, sprint_id, rank, title, description, status, priority,
+				due_date, start_date, duration_days, created_by, assigned_to, estimated_hours,
+				actual_hours, custom_fields, created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		`
+
+		_, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			query,
+			task.ID,
+			task.ProjectID,
+			task.ParentID,
+			task.SprintID,
+			task.Rank,
+			task.Title,
+			task.Description,
+			task.Status,
+			task.Priority,
+			task.DueDate,
+			task.StartDate,
+			task.DurationDays,
+			task.CreatedBy,
+			task.AssignedTo,
+			task.EstimatedHours,
+			task.ActualHours,
+			task.CustomFields,
+			task.CreatedAt,
+			task.UpdatedAt,
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-	`
 
-	_, err = tx.ExecContext(
-		ctx,
-		query,
-		task.ID,
-		task.ProjectID,
-		task.Title,
-		task.Description,
-		task.Status,
-		task.Priority,
-		task.DueDate,
-		task.CreatedBy,
-		task.AssignedTo,
-		task.EstimatedHours,
-		task.ActualHours,
-		task.CreatedAt,
-		task.UpdatedAt,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to insert task: %w", err)
-	}
+		if err != nil {
+			return fmt.Errorf("failed to insert task: %w", err)
+		}
 
-	// Insert tags if any
-	if len(task.Tags) > 0 {
+		// Insert tags if any
 		for _, tag := range task.Tags {
-			err = r.addTagTx(ctx, tx, task.ID, tag)
-			if err != nil {
+			if err := r.addTag(ctx, task.ID, tag); err != nil {
 				return fmt.Errorf("failed to add tag: %w", err)
 			}
 		}
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // GetByID retrieves a task by ID
 func (r *PostgresTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	query := `
-		SELECT t.id, t.project_id, t.title, t.description, t.status, t.priority,
-			t.due_date, t.created_by, t.assigned_to, t.estimated_hours,
-			t.actual_hours, t.created_at, t.updated_at
+		SELECT t.id, t.project_id, t.parent_id, t.sprint_id, t.rank, t.title, t.description, t.status, t.priority,
+			t.due_date, t.start_date, t.duration_days, t.created_by, t.assigned_to, t.estimated_hours,
+			t.actual_hours, t.custom_fields, t.created_at, t.updated_at
 		FROM taskodex.tasks t
-		WHERE t.id = $1
+		WHERE t.id = $1 AND t.deleted_at IS NULL
 	`
 
 	var task models.Task
-	err := r.db.GetContext(ctx, &task, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &task, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrTaskNotFound
@@ -146,8 +169,7 @@ func (r *PostgresTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 
 	// Get related entities
 	if task.ProjectID != nil {
-		projectRepo := NewPostgresProjectRepository(r.db)
-		project, err := projectRepo.GetByID(ctx, *task.ProjectID)
+		project, err := r.projectRepo.GetByID(ctx, *task.ProjectID)
 		if err != nil && !errors.Is(err, ErrProjectNotFound) {
 			return nil, fmt.Errorf("failed to get project: %w", err)
 		}
@@ -156,9 +178,7 @@ func (r *PostgresTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 		}
 	}
 
-	userRepo := NewPostgresUserRepository(r.db)
-
-	creator, err := userRepo.GetByID(ctx, task.CreatedBy)
+	creator, err := r.userRepo.GetByID(ctx, task.CreatedBy)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get creator: %w", err)
 	}
@@ -167,7 +187,7 @@ func (r *PostgresTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	}
 
 	if task.AssignedTo != nil {
-		assignee, err := userRepo.GetByID(ctx, *task.AssignedTo)
+		assignee, err := r.userRepo.GetByID(ctx, *task.AssignedTo)
 		if err != nil && !errors.Is(err, ErrUserNotFound) {
 			return nil, fmt.Errorf("failed to get assignee: %w", err)
 		}
@@ -184,7 +204,7 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 	// Build the query
 	baseQuery := `
 		FROM taskodex.tasks t
-		WHERE 1=1
+		WHERE t.deleted_at IS NULL
 	`
 
 	// Add filters
@@ -198,6 +218,26 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 		argIndex++
 	}
 
+	// Tasks without a project belong to no organization, so only those of the current user are kept
+	if params.ScopeOrganizationID != nil {
+		userID := uuid.Nil
+		if params.CurrentUserID != nil {
+			userID = *params.CurrentUserID
+		}
+		filters = append(filters, fmt.Sprintf(`(t.project_id IN (SELECT p.id FROM taskodex.projects p WHERE p.organization_id = $%d)
+			OR (t.project_id IS NULL AND t.created_by = $%d))`, argIndex, argIndex+1))
+		args = append(args, *params.ScopeOrganizationID, userID)
+		argIndex += 2
+	}
+
+	if params.ParentID != nil {
+		filters = append(filters, fmt.Sprintf("t.parent_id = $%d", argIndex))
+		args = append(args, *params.ParentID)
+		argIndex++
+	} else if params.TopLevel {
+		filters = append(filters, "t.parent_id IS NULL")
+	}
+
 	if params.Status != nil {
 		filters = append(filters, fmt.Sprintf("t.status = $%d", argIndex))
 		args = append(args, *params.Status)
@@ -234,12 +274,60 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 		argIndex++
 	}
 
-	if params.SearchTerm != nil && *params.SearchTerm != "" {
-		filters = append(filters, fmt.Sprintf("(t.title ILIKE $%d OR t.description ILIKE $%d)", argIndex, argIndex))
-		args = append(args, "%"+*params.SearchTerm+"%")
+	// Search matches every word of the term as a prefix of a title or description word
+	if params.SearchTerm != nil && prefixQuery(*params.SearchTerm) != "" {
+		filters = append(filters, fmt.Sprintf("t.search_vector @@ to_tsquery('english', $%d)", argIndex))
+		args = append(args, prefixQuery(*params.SearchTerm))
 		argIndex++
 	}
 
+	// Custom field filters compare JSONB values; their types are resolved by the caller
+	for _, filter := range params.CustomFieldFilters {
+		value, err := filter.FilterValue()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch filter.Operator {
+		case models.CustomFieldOperatorGte, models.CustomFieldOperatorLte:
+			operator := ">="
+			if filter.Operator == models.CustomFieldOperatorLte {
+				operator = "<="
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to encode custom field filter: %w", err)
+			}
+			filters = append(filters, fmt.Sprintf("t.custom_fields->$%d::text %s $%d::jsonb", argIndex, operator, argIndex+1))
+			args = append(args, filter.Key, string(encoded))
+			argIndex += 2
+		default:
+			// Containment matches a multi-select field holding the option among others
+			if filter.Type == models.CustomFieldTypeMultiSelect {
+				value = []interface{}{value}
+			}
+			encoded, err := json.Marshal(map[string]interface{}{filter.Key: value})
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to encode custom field filter: %w", err)
+			}
+			filters = append(filters, fmt.Sprintf("t.custom_fields @> $%d::jsonb", argIndex))
+			args = append(args, string(encoded))
+			argIndex++
+		}
+	}
+
+	// Filter expressions compile into parameterized conditions
+	if params.Filter != nil {
+		compiler := &taskFilterCompiler{args: args, userID: params.CurrentUserID, now: time.Now()}
+		filter, err := compiler.compile(params.Filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		filters = append(filters, filter)
+		args = compiler.args
+		argIndex = len(args) + 1
+	}
+
 	if len(filters) > 0 {
 		baseQuery += " AND " + strings.Join(filters, " AND ")
 	}
@@ -247,7 +335,7 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
 	}
@@ -260,6 +348,7 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 		"due_date":   true,
 		"created_at": true,
 		"updated_at": true,
+		"rank":       true,
 	}
 
 	sortBy := "created_at"
@@ -272,6 +361,13 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 		sortOrder = "ASC"
 	}
 
+	orderBy := fmt.Sprintf("t.%s %s", sortBy, sortOrder)
+	if params.CustomFieldSort != nil {
+		// JSONB orders numbers numerically and YYYY-MM-DD dates chronologically
+		orderBy = fmt.Sprintf("t.custom_fields->$%d::text %s NULLS LAST, t.created_at DESC", argIndex, sortOrder)
+		args = append(args, params.CustomFieldSort.Key)
+	}
+
 	// Ensure page and page size are valid
 	if params.Page < 1 {
 		params.Page = 1
@@ -284,16 +380,16 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 
 	// Build the final query
 	query := fmt.Sprintf(`
-		SELECT t.id, t.project_id, t.title, t.description, t.status, t.priority,
-			t.due_date, t.created_by, t.assigned_to, t.estimated_hours,
-			t.actual_hours, t.created_at, t.updated_at
+		SELECT t.id, t.project_id, t.parent_id, t.sprint_id, t.rank, t.title, t.description, t.status, t.priority,
+			t.due_date, t.start_date, t.duration_days, t.created_by, t.assigned_to, t.estimated_hours,
+			t.actual_hours, t.custom_fields, t.created_at, t.updated_at
 		%s
-		ORDER BY t.%s %s
+		ORDER BY %s
 		LIMIT %d OFFSET %d
-	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
+	`, baseQuery, orderBy, params.PageSize, offset)
 
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query tasks: %w", err)
 	}
@@ -324,121 +420,115 @@ func (r *PostgresTaskRepository) List(ctx context.Context, params models.TaskLis
 
 // Update updates a task
 func (r *PostgresTaskRepository) Update(ctx context.Context, task *models.Task) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		// Update task. A task moved to another project leaves its sprint.
+		query := `
+			UPDATE taskodex.tasks
+			SET sprint_id = CASE WHEN project_id IS DISTINCT FROM $1 THEN NULL ELSE sprint_id END,
+				project_id = $1, parent_id = $2, title = $3, description = $4, status = $5,
+				priority = $6, due_date = $7, start_date = $8, duration_days = $9, assigned_to = $10,
+				estimated_hours = $11, actual_hours = $12, custom_fields = $13, updated_at = $14
+			WHERE id = $15
+			RETURNING sprint_id
+		`
+
+		task.UpdatedAt = time.Now()
+
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&task.SprintID,
+			query,
+			task.ProjectID,
+			task.ParentID,
+			task.Title,
+			task.Description,
+			task.Status,
+			task.Priority,
+			task.DueDate,
+			task.StartDate,
+			task.DurationDays,
+			task.AssignedTo,
+			task.EstimatedHours,
+			task.ActualHours,
+			task.CustomFields,
+			task.UpdatedAt,
+			task.ID,
+		)
 
-	defer func() {
 		if err != nil {
-			tx.Rollback()
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTaskNotFound
+			}
+			return fmt.Errorf("failed to update task: %w", err)
 		}
-	}()
-
-	// Update task
-	query := `
-		UPDATE taskodex.tasks
-		SET project_id = $1, title = $2, description = $3, status = $4, priority = $5,
-			due_date = $6, assigned_to = $7, estimated_hours = $8, actual_hours = $9,
-			updated_at = $10
-		WHERE id = $11
-	`
-
-	task.UpdatedAt = time.Now()
-
-	_, err = tx.ExecContext(
-		ctx,
-		query,
-		task.ProjectID,
-		task.Title,
-		task.Description,
-		task.Status,
-		task.Priority,
-		task.DueDate,
-		task.AssignedTo,
-		task.EstimatedHours,
-		task.ActualHours,
-		task.UpdatedAt,
-		task.ID,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
-	}
 
-	// Get existing tags
-	existingTags, err := r.GetTags(ctx, task.ID)
-	if err != nil {
-		return fmt.Errorf("failed to get existing tags: %w", err)
-	}
+		// Get existing tags
+		existingTags, err := r.GetTags(ctx, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get existing tags: %w", err)
+		}
 
-	// Add new tags and remove old ones
-	existingTagMap := make(map[string]bool)
-	for _, tag := range existingTags {
-		existingTagMap[tag] = true
-	}
+		// Add new tags and remove old ones
+		existingTagMap := make(map[string]bool)
+		for _, tag := range existingTags {
+			existingTagMap[tag] = true
+		}
 
-	newTagMap := make(map[string]bool)
-	for _, tag := range task.Tags {
-		newTagMap[tag] = true
-	}
+		newTagMap := make(map[string]bool)
+		for _, tag := range task.Tags {
+			newTagMap[tag] = true
+		}
 
-	// Add new tags
-	for _, tag := range task.Tags {
-		if !existingTagMap[tag] {
-			err = r.addTagTx(ctx, tx, task.ID, tag)
-			if err != nil {
-				return fmt.Errorf("failed to add tag: %w", err)
+		// Add new tags
+		for _, tag := range task.Tags {
+			if !existingTagMap[tag] {
+				if err := r.addTag(ctx, task.ID, tag); err != nil {
+					return fmt.Errorf("failed to add tag: %w", err)
+				}
 			}
 		}
-	}
 
-	// Remove old tags
-	for _, tag := range existingTags {
-		if !newTagMap[tag] {
-			err = r.removeTagTx(ctx, tx, task.ID, tag)
-			if err != nil {
-				return fmt.Errorf("failed to remove tag: %w", err)
+		// Remove old tags
+		for _, tag := range existingTags {
+			if !newTagMap[tag] {
+				if err := r.removeTag(ctx, task.ID, tag); err != nil {
+					return fmt.Errorf("failed to remove tag: %w", err)
+				}
 			}
 		}
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
 
-	return nil
+		return nil
+	})
 }
 
-// Delete deletes a task
-func (r *PostgresTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Delete tags
-	_, err = tx.ExecContext(ctx, "DELETE FROM taskodex.task_tags WHERE task_id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to delete task tags: %w", err)
-	}
+// Delete moves a task to the trash, along with its subtasks. The subtasks
+// get the same deletion time as the task, so that they are restored with it;
+// subtasks already in the trash keep theirs.
+func (r *PostgresTaskRepository) Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT t.id FROM taskodex.tasks t WHERE t.id = $1 AND t.deleted_at IS NULL
+			UNION
+			SELECT t.id FROM taskodex.tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
+		)
+		UPDATE taskodex.tasks
+		SET deleted_at = $2, deleted_by = $3
+		WHERE id IN (SELECT id FROM subtree)
+	`
 
-	// Delete task
-	_, err = tx.ExecContext(ctx, "DELETE FROM taskodex.tasks WHERE id = $1", id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, time.Now(), deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
-	err = tx.Commit()
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskNotFound
 	}
 
 	return nil
@@ -446,35 +536,17 @@ func (r *PostgresTaskRepository) Delete(ctx context.Context, id uuid.UUID) error
 
 // AddTag adds a tag to a task
 func (r *PostgresTaskRepository) AddTag(ctx context.Context, taskID uuid.UUID, tag string) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	err = r.addTagTx(ctx, tx, taskID, tag)
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		return r.addTag(ctx, taskID, tag)
+	})
 }
 
-// addTagTx adds a tag to a task within a transaction
-func (r *PostgresTaskRepository) addTagTx(ctx context.Context, tx *sqlx.Tx, taskID uuid.UUID, tag string) error {
+// addTag adds a tag to a task, checking that the task exists first.
+// It must be called within a transaction.
+func (r *PostgresTaskRepository) addTag(ctx context.Context, taskID uuid.UUID, tag string) error {
 	// Check if task exists
 	var exists bool
-	err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM taskodex.tasks WHERE id = $1)", taskID)
+	err := conn(ctx, r.db).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM taskodex.tasks WHERE id = $1 AND deleted_at IS NULL)", taskID)
 	if err != nil {
 		return fmt.Errorf("failed to check if task exists: %w", err)
 	}
@@ -484,7 +556,7 @@ func (r *PostgresTaskRepository) addTagTx(ctx context.Context, tx *sqlx.Tx, task
 	}
 
 	// Insert tag
-	_, err = tx.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		"INSERT INTO taskodex.task_tags (task_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING",
 		taskID,
@@ -500,35 +572,17 @@ func (r *PostgresTaskRepository) addTagTx(ctx context.Context, tx *sqlx.Tx, task
 
 // RemoveTag removes a tag from a task
 func (r *PostgresTaskRepository) RemoveTag(ctx context.Context, taskID uuid.UUID, tag string) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	err = r.removeTagTx(ctx, tx, taskID, tag)
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		return r.removeTag(ctx, taskID, tag)
+	})
 }
 
-// removeTagTx removes a tag from a task within a transaction
-func (r *PostgresTaskRepository) removeTagTx(ctx context.Context, tx *sqlx.Tx, taskID uuid.UUID, tag string) error {
+// removeTag removes a tag from a task, checking that the task exists first.
+// It must be called within a transaction.
+func (r *PostgresTaskRepository) removeTag(ctx context.Context, taskID uuid.UUID, tag string) error {
 	// Check if task exists
 	var exists bool
-	err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM taskodex.tasks WHERE id = $1)", taskID)
+	err := conn(ctx, r.db).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM taskodex.tasks WHERE id = $1 AND deleted_at IS NULL)", taskID)
 	if err != nil {
 		return fmt.Errorf("failed to check if task exists: %w", err)
 	}
@@ -538,7 +592,7 @@ func (r *PostgresTaskRepository) removeTagTx(ctx context.Context, tx *sqlx.Tx, t
 	}
 
 	// Delete tag
-	_, err = tx.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		"DELETE FROM taskodex.task_tags WHERE task_id = $1 AND tag = $2",
 		taskID,
@@ -557,10 +611,132 @@ func (r *PostgresTaskRepository) GetTags(ctx context.Context, taskID uuid.UUID)
 	query := "SELECT tag FROM taskodex.task_tags WHERE task_id = $1"
 
 	var tags []string
-	err := r.db.SelectContext(ctx, &tags, query, taskID)
+	err := conn(ctx, r.db).SelectContext(ctx, &tags, query, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tags: %w", err)
 	}
 
 	return tags, nil
 }
+
+// GetDescendants retrieves all subtasks of a task, at any depth
+func (r *PostgresTaskRepository) GetDescendants(ctx context.Context, id uuid.UUID) ([]models.Task, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT t.* FROM taskodex.tasks t WHERE t.parent_id = $1 AND t.deleted_at IS NULL
+			UNION
+			SELECT t.* FROM taskodex.tasks t
+			JOIN descendants d ON t.parent_id = d.id
+			WHERE t.deleted_at IS NULL
+		)
+		SELECT t.id, t.project_id, t.parent_id, t.sprint_id, t.rank, t.title, t.description, t.status, t.priority,
+			t.due_date, t.start_date, t.duration_days, t.created_by, t.assigned_to, t.estimated_hours,
+			t.actual_hours, t.custom_fields, t.created_at, t.updated_at
+		FROM descendants t
+		ORDER BY t.created_at
+	`
+
+	tasks := []models.Task{}
+	err := conn(ctx, r.db).SelectContext(ctx, &tasks, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	for i := range tasks {
+		tags, err := r.GetTags(ctx, tasks[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+		tasks[i].Tags = tags
+	}
+
+	return tasks, nil
+}
+
+// LockSchedule locks a task and the other tasks of its project. The task is
+// locked first, so that it cannot move to another project meanwhile.
+func (r *PostgresTaskRepository) LockSchedule(ctx context.Context, id uuid.UUID) error {
+	query := `
+		SELECT t.id
+		FROM taskodex.tasks t
+		WHERE t.project_id = (SELECT project_id FROM taskodex.tasks WHERE id = $1 FOR UPDATE)
+		ORDER BY t.id
+		FOR UPDATE
+	`
+
+	var ids []uuid.UUID
+	err := conn(ctx, r.db).SelectContext(ctx, &ids, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to lock task schedule: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSchedule updates the start date, due date and duration of a task
+func (r *PostgresTaskRepository) UpdateSchedule(ctx context.Context, task *models.Task) error {
+	query := `
+		UPDATE taskodex.tasks
+		SET start_date = $1, due_date = $2, duration_days = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
+	`
+
+	task.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		task.StartDate,
+		task.DueDate,
+		task.DurationDays,
+		task.UpdatedAt,
+		task.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update task schedule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// ReparentChildren moves the direct subtasks of a task to a new parent
+func (r *PostgresTaskRepository) ReparentChildren(ctx context.Context, parentID uuid.UUID, newParentID *uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE taskodex.tasks SET parent_id = $1, updated_at = $2 WHERE parent_id = $3",
+		newParentID,
+		time.Now(),
+		parentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reparent subtasks: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatuses retrieves the distinct statuses of the tasks in a project
+func (r *PostgresTaskRepository) ListStatuses(ctx context.Context, projectID uuid.UUID) ([]models.TaskStatus, error) {
+	query := `
+		SELECT DISTINCT status
+		FROM taskodex.tasks
+		WHERE project_id = $1
+		ORDER BY status
+	`
+
+	var statuses []models.TaskStatus
+	err := conn(ctx, r.db).SelectContext(ctx, &statuses, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task statuses: %w", err)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// TimeEntryPolicyRepository defines the interface for time entry policy data
+// access
+type TimeEntryPolicyRepository interface {
+	// GetPolicy retrieves the time entry policy of an organization, or the
+	// default policy if it has none
+	GetPolicy(ctx context.Context, organizationID uuid.UUID) (*models.TimeEntryPolicy, error)
+
+	// SavePolicy creates or replaces the time entry policy of an organization
+	SavePolicy(ctx context.Context, policy *models.TimeEntryPolicy) error
+
+	// ListIdleTimers retrieves the timers that, at now, have run for longer
+	// than the policy of their organization allows, oldest first
+	ListIdleTimers(ctx context.Context, now time.Time) ([]models.IdleTimer, error)
+}
+
+// PostgresTimeEntryPolicyRepository implements TimeEntryPolicyRepository
+// using PostgreSQL
+type PostgresTimeEntryPolicyRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTimeEntryPolicyRepository creates a new
+// PostgresTimeEntryPolicyRepository
+func NewPostgresTimeEntryPolicyRepository(db *sqlx.DB) TimeEntryPolicyRepository {
+	return &PostgresTimeEntryPolicyRepository{db: db}
+}
+
+// GetPolicy retrieves the time entry policy of an organization
+func (r *PostgresTimeEntryPolicyRepository) GetPolicy(ctx context.Context, organizationID uuid.UUID) (*models.TimeEntryPolicy, error) {
+	query := `
+		SELECT organization_id, single_running_timer, overlap_mode, max_entry_minutes,
+			auto_stop_minutes, updated_by, updated_at
+		FROM taskodex.time_entry_policies
+		WHERE organization_id = $1
+	`
+
+	var policy models.TimeEntryPolicy
+	err := conn(ctx, r.db).GetContext(ctx, &policy, query, organizationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DefaultTimeEntryPolicy(organizationID), nil
+		}
+		return nil, fmt.Errorf("failed to get time entry policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// SavePolicy creates or replaces the time entry policy of an organization
+func (r *PostgresTimeEntryPolicyRepository) SavePolicy(ctx context.Context, policy *models.TimeEntryPolicy) error {
+	query := `
+		INSERT INTO taskodex.time_entry_policies (
+			organization_id, single_running_timer, overlap_mode, max_entry_minutes,
+			auto_stop_minutes, updated_by, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET single_running_timer = EXCLUDED.single_running_timer, overlap_mode = EXCLUDED.overlap_mode,
+			max_entry_minutes = EXCLUDED.max_entry_minutes, auto_stop_minutes = EXCLUDED.auto_stop_minutes,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	now := time.Now()
+	policy.UpdatedAt = &now
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		policy.OrganizationID,
+		policy.SingleRunningTimer,
+		policy.OverlapMode,
+		policy.MaxEntryMinutes,
+		policy.AutoStopMinutes,
+		policy.UpdatedBy,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save time entry policy: %w", err)
+	}
+
+	return nil
+}
+
+// ListIdleTimers retrieves the timers that have run for longer than the
+// policy of their organization allows
+func (r *PostgresTimeEntryPolicyRepository) ListIdleTimers(ctx context.Context, now time.Time) ([]models.IdleTimer, error) {
+	query := `
+		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time,
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at,
+			t.title AS task_title, tp.auto_stop_minutes, tp.max_entry_minutes
+		FROM taskodex.task_time_entries te
+		JOIN taskodex.tasks t ON t.id = te.task_id
+		JOIN taskodex.projects p ON p.id = t.project_id
+		JOIN taskodex.time_entry_policies tp ON tp.organization_id = p.organization_id
+		WHERE te.end_time IS NULL AND te.duration_minutes IS NULL
+			AND tp.auto_stop_minutes > 0
+			AND te.start_time <= $1::timestamptz - tp.auto_stop_minutes * INTERVAL '1 minute'
+		ORDER BY te.start_time
+	`
+
+	timers := []models.IdleTimer{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timers, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list idle timers: %w", err)
+	}
+
+	return timers, nil
+}
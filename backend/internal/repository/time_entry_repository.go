@@ -11,14 +11,20 @@ import (
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Common errors for time entry repository
 var (
 	ErrTimeEntryNotFound = errors.New("time entry not found")
 	ErrRunningTimeEntry  = errors.New("user already has a running time entry for this task")
+	ErrTimerNotRunning   = errors.New("timer is not running")
 )
 
+// timeEntryLockPrefix prefixes the key of the advisory lock that serializes
+// changes to the time entries of a user
+const timeEntryLockPrefix = "taskodex.time_entries:"
+
 // TimeEntryRepository defines the interface for time entry data access
 type TimeEntryRepository interface {
 	// Create creates a new time entry
@@ -29,6 +35,9 @@ type TimeEntryRepository interface {
 	
 	// List retrieves time entries based on filter parameters
 	List(ctx context.Context, params models.TimeEntryListParams) ([]models.TimeEntry, int, error)
+
+	// ListByTasks retrieves the time entries of several tasks, keyed by task ID
+	ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.TimeEntry, error)
 	
 	// Update updates a time entry
 	Update(ctx context.Context, timeEntry *models.TimeEntry) error
@@ -42,18 +51,37 @@ type TimeEntryRepository interface {
 	// GetRunningTimeEntries retrieves all running time entries for a user
 	GetRunningTimeEntries(ctx context.Context, userID uuid.UUID) ([]models.TimeEntry, error)
 	
+	// StopTimer stops a running timer at its end time, or returns
+	// ErrTimerNotRunning if it was stopped in the meantime
+	StopTimer(ctx context.Context, timeEntry *models.TimeEntry) error
+	
+	// LockUser serializes changes to the time entries of a user until the
+	// transaction in ctx ends
+	LockUser(ctx context.Context, userID uuid.UUID) error
+	
+	// ListOverlapping retrieves the time entries of a user, other than
+	// excludeID, that overlap the period from start to end by a minute or
+	// more
+	ListOverlapping(ctx context.Context, userID uuid.UUID, start, end time.Time, excludeID uuid.UUID) ([]models.TimeEntry, error)
+	
 	// Aggregate aggregates time entries based on parameters
 	Aggregate(ctx context.Context, params models.TimeEntryAggregationParams) ([]models.TimeEntryAggregation, error)
 }
 
 // PostgresTimeEntryRepository implements TimeEntryRepository using PostgreSQL
 type PostgresTimeEntryRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	taskRepo TaskRepository
+	userRepo UserRepository
 }
 
 // NewPostgresTimeEntryRepository creates a new PostgresTimeEntryRepository
 func NewPostgresTimeEntryRepository(db *sqlx.DB) TimeEntryRepository {
-	return &PostgresTimeEntryRepository{db: db}
+	return &PostgresTimeEntryRepository{
+		db:       db,
+		taskRepo: NewPostgresTaskRepository(db),
+		userRepo: NewPostgresUserRepository(db),
+	}
 }
 
 // Create creates a new time entry
@@ -72,12 +100,12 @@ func (r *PostgresTimeEntryRepository) Create(ctx context.Context, timeEntry *mod
 	query := `
 		INSERT INTO taskodex.task_time_entries (
 			id, task_id, user_id, start_time, end_time, 
-			duration_minutes, description, created_at, updated_at
+			duration_minutes, description, billable, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		timeEntry.ID,
@@ -87,6 +115,7 @@ func (r *PostgresTimeEntryRepository) Create(ctx context.Context, timeEntry *mod
 		timeEntry.EndTime,
 		timeEntry.DurationMinutes,
 		timeEntry.Description,
+		timeEntry.Billable,
 		timeEntry.CreatedAt,
 		timeEntry.UpdatedAt,
 	)
@@ -102,13 +131,13 @@ func (r *PostgresTimeEntryRepository) Create(ctx context.Context, timeEntry *mod
 func (r *PostgresTimeEntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TimeEntry, error) {
 	query := `
 		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time, 
-			te.duration_minutes, te.description, te.created_at, te.updated_at
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
 		FROM taskodex.task_time_entries te
 		WHERE te.id = $1
 	`
 	
 	var timeEntry models.TimeEntry
-	err := r.db.GetContext(ctx, &timeEntry, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &timeEntry, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrTimeEntryNotFound
@@ -117,8 +146,7 @@ func (r *PostgresTimeEntryRepository) GetByID(ctx context.Context, id uuid.UUID)
 	}
 	
 	// Get task
-	taskRepo := NewPostgresTaskRepository(r.db)
-	task, err := taskRepo.GetByID(ctx, timeEntry.TaskID)
+	task, err := r.taskRepo.GetByID(ctx, timeEntry.TaskID)
 	if err != nil && !errors.Is(err, ErrTaskNotFound) {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
@@ -127,8 +155,7 @@ func (r *PostgresTimeEntryRepository) GetByID(ctx context.Context, id uuid.UUID)
 	}
 	
 	// Get user
-	userRepo := NewPostgresUserRepository(r.db)
-	user, err := userRepo.GetByID(ctx, timeEntry.UserID)
+	user, err := r.userRepo.GetByID(ctx, timeEntry.UserID)
 	if err != nil && !errors.Is(err, ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -184,6 +211,12 @@ func (r *PostgresTimeEntryRepository) List(ctx context.Context, params models.Ti
 		}
 	}
 	
+	if params.Billable != nil {
+		filters = append(filters, fmt.Sprintf("te.billable = $%d", argIndex))
+		args = append(args, *params.Billable)
+		argIndex++
+	}
+	
 	if len(filters) > 0 {
 		baseQuery += " AND " + strings.Join(filters, " AND ")
 	}
@@ -191,7 +224,7 @@ func (r *PostgresTimeEntryRepository) List(ctx context.Context, params models.Ti
 	// Count total records
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err := conn(ctx, r.db).GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count time entries: %w", err)
 	}
@@ -227,14 +260,14 @@ func (r *PostgresTimeEntryRepository) List(ctx context.Context, params models.Ti
 	// Build the final query
 	query := fmt.Sprintf(`
 		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time, 
-			te.duration_minutes, te.description, te.created_at, te.updated_at
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
 		%s
 		ORDER BY te.%s %s
 		LIMIT %d OFFSET %d
 	`, baseQuery, sortBy, sortOrder, params.PageSize, offset)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query time entries: %w", err)
 	}
@@ -259,19 +292,20 @@ func (r *PostgresTimeEntryRepository) Update(ctx context.Context, timeEntry *mod
 	query := `
 		UPDATE taskodex.task_time_entries
 		SET start_time = $1, end_time = $2, duration_minutes = $3, 
-			description = $4, updated_at = $5
-		WHERE id = $6
+			description = $4, billable = $5, updated_at = $6
+		WHERE id = $7
 	`
 	
 	timeEntry.UpdatedAt = time.Now()
 	
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		timeEntry.StartTime,
 		timeEntry.EndTime,
 		timeEntry.DurationMinutes,
 		timeEntry.Description,
+		timeEntry.Billable,
 		timeEntry.UpdatedAt,
 		timeEntry.ID,
 	)
@@ -290,7 +324,7 @@ func (r *PostgresTimeEntryRepository) Delete(ctx context.Context, id uuid.UUID)
 		WHERE id = $1
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete time entry: %w", err)
 	}
@@ -302,7 +336,7 @@ func (r *PostgresTimeEntryRepository) Delete(ctx context.Context, id uuid.UUID)
 func (r *PostgresTimeEntryRepository) GetRunningTimeEntry(ctx context.Context, userID, taskID uuid.UUID) (*models.TimeEntry, error) {
 	query := `
 		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time, 
-			te.duration_minutes, te.description, te.created_at, te.updated_at
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
 		FROM taskodex.task_time_entries te
 		WHERE te.user_id = $1 AND te.task_id = $2 AND te.end_time IS NULL
 		ORDER BY te.start_time DESC
@@ -310,7 +344,7 @@ func (r *PostgresTimeEntryRepository) GetRunningTimeEntry(ctx context.Context, u
 	`
 	
 	var timeEntry models.TimeEntry
-	err := r.db.GetContext(ctx, &timeEntry, query, userID, taskID)
+	err := conn(ctx, r.db).GetContext(ctx, &timeEntry, query, userID, taskID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrTimeEntryNotFound
@@ -321,18 +355,51 @@ func (r *PostgresTimeEntryRepository) GetRunningTimeEntry(ctx context.Context, u
 	return &timeEntry, nil
 }
 
+// ListByTasks retrieves the time entries of several tasks, keyed by task ID
+func (r *PostgresTimeEntryRepository) ListByTasks(ctx context.Context, taskIDs []uuid.UUID) (map[uuid.UUID][]models.TimeEntry, error) {
+	result := make(map[uuid.UUID][]models.TimeEntry, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time,
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
+		FROM taskodex.task_time_entries te
+		WHERE te.task_id = ANY($1::uuid[])
+		ORDER BY te.task_id, te.start_time
+	`
+
+	var timeEntries []models.TimeEntry
+	err := conn(ctx, r.db).SelectContext(ctx, &timeEntries, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list time entries: %w", err)
+	}
+
+	for _, timeEntry := range timeEntries {
+		result[timeEntry.TaskID] = append(result[timeEntry.TaskID], timeEntry)
+	}
+
+	return result, nil
+}
+
 // GetRunningTimeEntries retrieves all running time entries for a user
 func (r *PostgresTimeEntryRepository) GetRunningTimeEntries(ctx context.Context, userID uuid.UUID) ([]models.TimeEntry, error) {
 	query := `
 		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time, 
-			te.duration_minutes, te.description, te.created_at, te.updated_at
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
 		FROM taskodex.task_time_entries te
 		WHERE te.user_id = $1 AND te.end_time IS NULL
 		ORDER BY te.start_time DESC
 	`
 	
 	timeEntries := []models.TimeEntry{}
-	err := r.db.SelectContext(ctx, &timeEntries, query, userID)
+	err := conn(ctx, r.db).SelectContext(ctx, &timeEntries, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get running time entries: %w", err)
 	}
@@ -340,6 +407,79 @@ func (r *PostgresTimeEntryRepository) GetRunningTimeEntries(ctx context.Context,
 	return timeEntries, nil
 }
 
+// StopTimer stops a running timer at its end time
+func (r *PostgresTimeEntryRepository) StopTimer(ctx context.Context, timeEntry *models.TimeEntry) error {
+	query := `
+		UPDATE taskodex.task_time_entries
+		SET end_time = $1, duration_minutes = $2, updated_at = $3
+		WHERE id = $4 AND end_time IS NULL AND duration_minutes IS NULL
+	`
+	
+	timeEntry.UpdatedAt = time.Now()
+	
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		timeEntry.EndTime,
+		timeEntry.DurationMinutes,
+		timeEntry.UpdatedAt,
+		timeEntry.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to stop timer: %w", err)
+	}
+	
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to stop timer: %w", err)
+	}
+	if rows == 0 {
+		return ErrTimerNotRunning
+	}
+	
+	return nil
+}
+
+// LockUser serializes changes to the time entries of a user
+func (r *PostgresTimeEntryRepository) LockUser(ctx context.Context, userID uuid.UUID) error {
+	return lockTimeEntries(ctx, r.db, userID)
+}
+
+// lockTimeEntries takes the advisory lock on the time entries of a user,
+// held until the transaction in ctx ends
+func lockTimeEntries(ctx context.Context, db *sqlx.DB, userID uuid.UUID) error {
+	_, err := conn(ctx, db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", timeEntryLockPrefix+userID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock time entries: %w", err)
+	}
+	
+	return nil
+}
+
+// ListOverlapping retrieves the time entries of a user that overlap a
+// period. Entries end at their end time, at the end of their duration, or
+// now while their timer is running. Time is logged by the minute, so
+// entries that overlap by less than a minute do not count.
+func (r *PostgresTimeEntryRepository) ListOverlapping(ctx context.Context, userID uuid.UUID, start, end time.Time, excludeID uuid.UUID) ([]models.TimeEntry, error) {
+	query := `
+		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time,
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
+		FROM taskodex.task_time_entries te
+		WHERE te.user_id = $1 AND te.id <> $2 AND te.start_time < $4
+			AND LEAST(COALESCE(te.end_time, te.start_time + te.duration_minutes * INTERVAL '1 minute', NOW()), $4)
+				- GREATEST(te.start_time, $3) >= INTERVAL '1 minute'
+		ORDER BY te.start_time
+	`
+	
+	timeEntries := []models.TimeEntry{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timeEntries, query, userID, excludeID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overlapping time entries: %w", err)
+	}
+	
+	return timeEntries, nil
+}
+
 // Aggregate aggregates time entries based on parameters
 func (r *PostgresTimeEntryRepository) Aggregate(ctx context.Context, params models.TimeEntryAggregationParams) ([]models.TimeEntryAggregation, error) {
 	// Build the base query
@@ -416,21 +556,23 @@ func (r *PostgresTimeEntryRepository) Aggregate(ctx context.Context, params mode
 	}
 	
 	// Build the final query
+	duration := `COALESCE(te.duration_minutes, 
+		CASE 
+			WHEN te.end_time IS NOT NULL THEN EXTRACT(EPOCH FROM (te.end_time - te.start_time))/60 
+			ELSE EXTRACT(EPOCH FROM (NOW() - te.start_time))/60 
+		END
+	)`
 	query := fmt.Sprintf(`
 		SELECT %s, 
-			SUM(COALESCE(te.duration_minutes, 
-				CASE 
-					WHEN te.end_time IS NOT NULL THEN EXTRACT(EPOCH FROM (te.end_time - te.start_time))/60 
-					ELSE EXTRACT(EPOCH FROM (NOW() - te.start_time))/60 
-				END
-			))::INTEGER AS total_duration_minutes
+			SUM(%s)::INTEGER AS total_duration_minutes,
+			COALESCE(SUM(%s) FILTER (WHERE te.billable), 0)::INTEGER AS billable_minutes
 		%s
 		GROUP BY %s
 		ORDER BY %s
-	`, selectFields, baseQuery, groupBy, groupBy)
+	`, selectFields, duration, duration, baseQuery, groupBy, groupBy)
 	
 	// Execute the query
-	rows, err := r.db.QueryxContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate time entries: %w", err)
 	}
@@ -445,29 +587,29 @@ func (r *PostgresTimeEntryRepository) Aggregate(ctx context.Context, params mode
 		switch params.GroupBy {
 		case "day":
 			var date time.Time
-			err = rows.Scan(&date, &agg.TotalDurationMinutes)
+			err = rows.Scan(&date, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.Date = &date
 		case "week":
 			var year, week int
-			err = rows.Scan(&year, &week, &agg.TotalDurationMinutes)
+			err = rows.Scan(&year, &week, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.Year = &year
 			agg.Week = &week
 		case "month":
 			var year, month int
-			err = rows.Scan(&year, &month, &agg.TotalDurationMinutes)
+			err = rows.Scan(&year, &month, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.Year = &year
 			agg.Month = &month
 		case "year":
 			var year int
-			err = rows.Scan(&year, &agg.TotalDurationMinutes)
+			err = rows.Scan(&year, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.Year = &year
 		case "task":
 			var taskID uuid.UUID
-			err = rows.Scan(&taskID, &agg.TotalDurationMinutes)
+			err = rows.Scan(&taskID, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.TaskID = &taskID
 		case "user":
 			var userID uuid.UUID
-			err = rows.Scan(&userID, &agg.TotalDurationMinutes)
+			err = rows.Scan(&userID, &agg.TotalDurationMinutes, &agg.BillableMinutes)
 			agg.UserID = &userID
 		}
 		
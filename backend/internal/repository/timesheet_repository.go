@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for timesheet repository
+var (
+	ErrTimesheetNotFound      = errors.New("timesheet not found")
+	ErrTimesheetExists        = errors.New("week already has a timesheet")
+	ErrTimesheetStatusChanged = errors.New("timesheet status has changed")
+	ErrTimesheetLocked        = errors.New("time entry is in a submitted or approved timesheet")
+)
+
+// timesheetColumns are the columns of a timesheet
+const timesheetColumns = `
+	ts.id, ts.organization_id, ts.user_id, ts.week_start, ts.status, ts.total_minutes,
+	ts.submitted_at, ts.reviewed_by, ts.reviewed_at, ts.created_at, ts.updated_at
+`
+
+// TimesheetRepository defines the interface for timesheet data access
+type TimesheetRepository interface {
+	// Create creates a new timesheet. A week has at most one timesheet.
+	Create(ctx context.Context, timesheet *models.Timesheet) error
+
+	// GetByID retrieves a timesheet by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Timesheet, error)
+
+	// GetByWeek retrieves the timesheet of a member for the week starting on
+	// weekStart
+	GetByWeek(ctx context.Context, organizationID, userID uuid.UUID, weekStart time.Time) (*models.Timesheet, error)
+
+	// List retrieves the timesheets of an organization, latest week first
+	List(ctx context.Context, params models.TimesheetListParams) ([]models.Timesheet, error)
+
+	// SetStatus saves the status of a timesheet and what is recorded with
+	// it, provided the timesheet is still in status from
+	SetStatus(ctx context.Context, timesheet *models.Timesheet, from models.TimesheetStatus) error
+
+	// AddComment records a submission or review of a timesheet
+	AddComment(ctx context.Context, comment *models.TimesheetComment) error
+
+	// ListComments retrieves the submissions and reviews of a timesheet,
+	// oldest first
+	ListComments(ctx context.Context, timesheetID uuid.UUID) ([]models.TimesheetComment, error)
+
+	// LockEntries keeps the time entries of a user from changing until the
+	// transaction in ctx ends
+	LockEntries(ctx context.Context, userID uuid.UUID) error
+
+	// ListEntries retrieves the time entries of a member that start between
+	// from and to, on the tasks of the organization's projects
+	ListEntries(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time) ([]models.TimeEntry, error)
+
+	// IsLocked reports whether the week of a member starting on weekStart
+	// has a submitted or approved timesheet
+	IsLocked(ctx context.Context, organizationID, userID uuid.UUID, weekStart time.Time) (bool, error)
+}
+
+// PostgresTimesheetRepository implements TimesheetRepository using PostgreSQL
+type PostgresTimesheetRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTimesheetRepository creates a new PostgresTimesheetRepository
+func NewPostgresTimesheetRepository(db *sqlx.DB) TimesheetRepository {
+	return &PostgresTimesheetRepository{db: db}
+}
+
+// Create creates a new timesheet
+func (r *PostgresTimesheetRepository) Create(ctx context.Context, timesheet *models.Timesheet) error {
+	query := `
+		INSERT INTO taskodex.timesheets (
+			id, organization_id, user_id, week_start, status, total_minutes,
+			submitted_at, reviewed_by, reviewed_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		timesheet.ID,
+		timesheet.OrganizationID,
+		timesheet.UserID,
+		timesheet.WeekStart.Format(models.AnalyticsDateFormat),
+		timesheet.Status,
+		timesheet.TotalMinutes,
+		timesheet.SubmittedAt,
+		timesheet.ReviewedBy,
+		timesheet.ReviewedAt,
+		timesheet.CreatedAt,
+		timesheet.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "uq_timesheets_week" {
+			return ErrTimesheetExists
+		}
+		return fmt.Errorf("failed to insert timesheet: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a timesheet by ID
+func (r *PostgresTimesheetRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Timesheet, error) {
+	query := "SELECT " + timesheetColumns + " FROM taskodex.timesheets ts WHERE ts.id = $1"
+
+	var timesheet models.Timesheet
+	err := conn(ctx, r.db).GetContext(ctx, &timesheet, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+		return nil, fmt.Errorf("failed to get timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// GetByWeek retrieves the timesheet of a member for a week
+func (r *PostgresTimesheetRepository) GetByWeek(ctx context.Context, organizationID, userID uuid.UUID, weekStart time.Time) (*models.Timesheet, error) {
+	query := "SELECT " + timesheetColumns + `
+		FROM taskodex.timesheets ts
+		WHERE ts.organization_id = $1 AND ts.user_id = $2 AND ts.week_start = $3
+	`
+
+	var timesheet models.Timesheet
+	err := conn(ctx, r.db).GetContext(ctx, &timesheet, query, organizationID, userID, weekStart.Format(models.AnalyticsDateFormat))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+		return nil, fmt.Errorf("failed to get timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// List retrieves the timesheets of an organization
+func (r *PostgresTimesheetRepository) List(ctx context.Context, params models.TimesheetListParams) ([]models.Timesheet, error) {
+	filters := []string{"ts.organization_id = $1"}
+	args := []interface{}{params.OrganizationID}
+	argIndex := 2
+
+	if params.UserID != nil {
+		filters = append(filters, fmt.Sprintf("ts.user_id = $%d", argIndex))
+		args = append(args, *params.UserID)
+		argIndex++
+	}
+
+	if params.Status != nil {
+		filters = append(filters, fmt.Sprintf("ts.status = $%d", argIndex))
+		args = append(args, *params.Status)
+		argIndex++
+	}
+
+	// The employees a user manages are those whose manager is the user's
+	// employee record in the organization
+	if params.ManagerID != nil {
+		filters = append(filters, fmt.Sprintf(`EXISTS (
+			SELECT 1
+			FROM qultrix.employees e
+			JOIN qultrix.employees m ON m.id = e.manager_id
+			WHERE e.organization_id = ts.organization_id AND e.user_id = ts.user_id AND m.user_id = $%d
+		)`, argIndex))
+		args = append(args, *params.ManagerID)
+		argIndex++
+	}
+
+	if params.From != nil {
+		filters = append(filters, fmt.Sprintf("ts.week_start >= $%d", argIndex))
+		args = append(args, params.From.Format(models.AnalyticsDateFormat))
+		argIndex++
+	}
+
+	if params.To != nil {
+		filters = append(filters, fmt.Sprintf("ts.week_start <= $%d", argIndex))
+		args = append(args, params.To.Format(models.AnalyticsDateFormat))
+		argIndex++
+	}
+
+	query := "SELECT " + timesheetColumns + `
+		FROM taskodex.timesheets ts
+		WHERE ` + strings.Join(filters, " AND ") + `
+		ORDER BY ts.week_start DESC, ts.submitted_at DESC
+	`
+
+	timesheets := []models.Timesheet{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timesheets, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timesheets: %w", err)
+	}
+
+	return timesheets, nil
+}
+
+// SetStatus saves the status of a timesheet, provided it is still in
+// status from
+func (r *PostgresTimesheetRepository) SetStatus(ctx context.Context, timesheet *models.Timesheet, from models.TimesheetStatus) error {
+	query := `
+		UPDATE taskodex.timesheets
+		SET status = $1, total_minutes = $2, submitted_at = $3, reviewed_by = $4,
+			reviewed_at = $5, updated_at = $6
+		WHERE id = $7 AND status = $8
+	`
+
+	timesheet.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		timesheet.Status,
+		timesheet.TotalMinutes,
+		timesheet.SubmittedAt,
+		timesheet.ReviewedBy,
+		timesheet.ReviewedAt,
+		timesheet.UpdatedAt,
+		timesheet.ID,
+		from,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update timesheet status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrTimesheetStatusChanged
+	}
+
+	return nil
+}
+
+// AddComment records a submission or review of a timesheet
+func (r *PostgresTimesheetRepository) AddComment(ctx context.Context, comment *models.TimesheetComment) error {
+	query := `
+		INSERT INTO taskodex.timesheet_comments (id, timesheet_id, user_id, action, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		comment.ID,
+		comment.TimesheetID,
+		comment.UserID,
+		comment.Action,
+		comment.Comment,
+		comment.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert timesheet comment: %w", err)
+	}
+
+	return nil
+}
+
+// ListComments retrieves the submissions and reviews of a timesheet
+func (r *PostgresTimesheetRepository) ListComments(ctx context.Context, timesheetID uuid.UUID) ([]models.TimesheetComment, error) {
+	query := `
+		SELECT id, timesheet_id, user_id, action, comment, created_at
+		FROM taskodex.timesheet_comments
+		WHERE timesheet_id = $1
+		ORDER BY created_at, id
+	`
+
+	comments := []models.TimesheetComment{}
+	err := conn(ctx, r.db).SelectContext(ctx, &comments, query, timesheetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timesheet comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// LockEntries takes the lock that serializes changes to the time entries of
+// a user
+func (r *PostgresTimesheetRepository) LockEntries(ctx context.Context, userID uuid.UUID) error {
+	return lockTimeEntries(ctx, r.db, userID)
+}
+
+// ListEntries retrieves the time entries of a member on the tasks of an
+// organization's projects
+func (r *PostgresTimesheetRepository) ListEntries(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time) ([]models.TimeEntry, error) {
+	query := `
+		SELECT te.id, te.task_id, te.user_id, te.start_time, te.end_time,
+			te.duration_minutes, te.description, te.billable, te.created_at, te.updated_at
+		FROM taskodex.task_time_entries te
+		JOIN taskodex.tasks t ON t.id = te.task_id
+		JOIN taskodex.projects p ON p.id = t.project_id
+		WHERE p.organization_id = $1 AND te.user_id = $2
+			AND te.start_time >= $3 AND te.start_time < $4
+		ORDER BY te.start_time, te.id
+	`
+
+	timeEntries := []models.TimeEntry{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timeEntries, query, organizationID, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timesheet entries: %w", err)
+	}
+
+	return timeEntries, nil
+}
+
+// IsLocked reports whether a week of a member has a submitted or approved
+// timesheet
+func (r *PostgresTimesheetRepository) IsLocked(ctx context.Context, organizationID, userID uuid.UUID, weekStart time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM taskodex.timesheets
+			WHERE organization_id = $1 AND user_id = $2 AND week_start = $3
+				AND status IN ('submitted', 'approved')
+		)
+	`
+
+	var locked bool
+	err := conn(ctx, r.db).GetContext(ctx, &locked, query, organizationID, userID, weekStart.Format(models.AnalyticsDateFormat))
+	if err != nil {
+		return false, fmt.Errorf("failed to check timesheet lock: %w", err)
+	}
+
+	return locked, nil
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Common errors for trash repository
+var (
+	ErrTrashItemNotFound = errors.New("item not found in the trash")
+)
+
+// trashProjectsQuery selects the projects in the trash, with the number of
+// tasks deleted along with them
+const trashProjectsQuery = `
+	SELECT 'project' AS type, p.id, p.id AS project_id, p.organization_id, p.name,
+		(SELECT COUNT(*) FROM taskodex.tasks pt WHERE pt.project_id = p.id AND pt.deleted_at = p.deleted_at) AS task_count,
+		p.deleted_at, p.deleted_by
+	FROM taskodex.projects p
+	WHERE p.deleted_at IS NOT NULL
+`
+
+// trashTasksQuery selects the tasks in the trash, with the number of subtasks
+// deleted along with them
+const trashTasksQuery = `
+	SELECT 'task' AS type, t.id, t.project_id, p.organization_id, t.title AS name,
+		(WITH RECURSIVE subtree AS (
+			SELECT c.id FROM taskodex.tasks c WHERE c.parent_id = t.id AND c.deleted_at = t.deleted_at
+			UNION
+			SELECT c.id FROM taskodex.tasks c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_at = t.deleted_at
+		) SELECT COUNT(*) FROM subtree) AS task_count,
+		t.deleted_at, t.deleted_by
+	FROM taskodex.tasks t
+	LEFT JOIN taskodex.projects p ON p.id = t.project_id
+	WHERE t.deleted_at IS NOT NULL
+`
+
+// trashTaskIsItem limits trashTasksQuery to the tasks that are items of their
+// own, rather than deleted along with their project or parent task
+const trashTaskIsItem = `
+	p.deleted_at IS DISTINCT FROM t.deleted_at
+	AND NOT EXISTS (SELECT 1 FROM taskodex.tasks pt WHERE pt.id = t.parent_id AND pt.deleted_at = t.deleted_at)
+`
+
+// TrashRepository defines the interface for access to deleted projects and tasks
+type TrashRepository interface {
+	// List retrieves the items in the trash of an organization, most
+	// recently deleted first
+	List(ctx context.Context, params models.TrashListParams) ([]models.TrashItem, int, error)
+
+	// Get retrieves a project or task in the trash
+	Get(ctx context.Context, itemType models.TrashItemType, id uuid.UUID) (*models.TrashItem, error)
+
+	// ListExpired retrieves up to limit items deleted before a time, oldest first
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]models.TrashItem, error)
+
+	// RestoreProject restores a project from the trash, along with the tasks
+	// deleted with it
+	RestoreProject(ctx context.Context, id uuid.UUID) error
+
+	// RestoreTask restores a task from the trash, along with the subtasks
+	// deleted with it. A task whose parent is still in the trash is restored
+	// without a parent.
+	RestoreTask(ctx context.Context, id uuid.UUID) error
+
+	// Purge deletes an item deleted before a time for good, along with
+	// everything that belongs to it, and returns the storage paths of its
+	// file attachments
+	Purge(ctx context.Context, itemType models.TrashItemType, id uuid.UUID, before time.Time) ([]string, error)
+}
+
+// PostgresTrashRepository implements TrashRepository using PostgreSQL
+type PostgresTrashRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresTrashRepository creates a new PostgresTrashRepository
+func NewPostgresTrashRepository(db *sqlx.DB) TrashRepository {
+	return &PostgresTrashRepository{db: db}
+}
+
+// List retrieves the items in the trash of an organization. Tasks without a
+// project are listed for the user who deleted them.
+func (r *PostgresTrashRepository) List(ctx context.Context, params models.TrashListParams) ([]models.TrashItem, int, error) {
+	items := []string{}
+	if params.Type == nil || *params.Type == models.TrashItemTypeProject {
+		items = append(items, trashProjectsQuery+" AND p.organization_id = $1")
+	}
+	if params.Type == nil || *params.Type == models.TrashItemTypeTask {
+		items = append(items, trashTasksQuery+" AND "+trashTaskIsItem+`
+			AND (p.organization_id = $1 OR (t.project_id IS NULL AND t.deleted_by = $2))`)
+	}
+	if len(items) == 0 {
+		return []models.TrashItem{}, 0, nil
+	}
+
+	baseQuery := "FROM (" + strings.Join(items, " UNION ALL ") + ") trash"
+	args := []interface{}{params.OrganizationID, params.UserID}
+
+	// Count total records
+	var total int
+	err := conn(ctx, r.db).GetContext(ctx, &total, "SELECT COUNT(*) "+baseQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count trash items: %w", err)
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	offset := (params.Page - 1) * params.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT trash.*
+		%s
+		ORDER BY trash.deleted_at DESC, trash.id
+		LIMIT %d OFFSET %d
+	`, baseQuery, params.PageSize, offset)
+
+	result := []models.TrashItem{}
+	err = conn(ctx, r.db).SelectContext(ctx, &result, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query trash items: %w", err)
+	}
+
+	return result, total, nil
+}
+
+// Get retrieves a project or task in the trash
+func (r *PostgresTrashRepository) Get(ctx context.Context, itemType models.TrashItemType, id uuid.UUID) (*models.TrashItem, error) {
+	var query string
+	switch itemType {
+	case models.TrashItemTypeProject:
+		query = trashProjectsQuery + " AND p.id = $1"
+	case models.TrashItemTypeTask:
+		query = trashTasksQuery + " AND t.id = $1"
+	default:
+		return nil, ErrTrashItemNotFound
+	}
+
+	var item models.TrashItem
+	err := conn(ctx, r.db).GetContext(ctx, &item, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTrashItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get trash item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// ListExpired retrieves up to limit items deleted before a time. Projects
+// come first, so that their tasks are purged with them.
+func (r *PostgresTrashRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]models.TrashItem, error) {
+	query := `
+		SELECT trash.* FROM (
+			` + trashProjectsQuery + ` AND p.deleted_at < $1
+			UNION ALL
+			` + trashTasksQuery + ` AND ` + trashTaskIsItem + ` AND t.deleted_at < $1
+		) trash
+		ORDER BY trash.type, trash.deleted_at, trash.id
+		LIMIT $2
+	`
+
+	items := []models.TrashItem{}
+	err := conn(ctx, r.db).SelectContext(ctx, &items, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired trash items: %w", err)
+	}
+
+	return items, nil
+}
+
+// RestoreProject restores a project from the trash, along with the tasks
+// deleted with it. Another project may have taken its name in the meantime.
+func (r *PostgresTrashRepository) RestoreProject(ctx context.Context, id uuid.UUID) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		var project struct {
+			OrganizationID uuid.UUID `db:"organization_id"`
+			Name           string    `db:"name"`
+			DeletedAt      time.Time `db:"deleted_at"`
+		}
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&project,
+			"SELECT organization_id, name, deleted_at FROM taskodex.projects WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE",
+			id,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTrashItemNotFound
+			}
+			return fmt.Errorf("failed to get deleted project: %w", err)
+		}
+
+		var exists bool
+		err = conn(ctx, r.db).GetContext(
+			ctx,
+			&exists,
+			"SELECT EXISTS(SELECT 1 FROM taskodex.projects WHERE organization_id = $1 AND name = $2 AND deleted_at IS NULL)",
+			project.OrganizationID,
+			project.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check if project name exists: %w", err)
+		}
+		if exists {
+			return ErrProjectNameExists
+		}
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			"UPDATE taskodex.tasks SET deleted_at = NULL, deleted_by = NULL WHERE project_id = $1 AND deleted_at = $2",
+			id,
+			project.DeletedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore project tasks: %w", err)
+		}
+
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			"UPDATE taskodex.projects SET deleted_at = NULL, deleted_by = NULL, updated_at = $1 WHERE id = $2",
+			time.Now(),
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore project: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// RestoreTask restores a task from the trash, along with the subtasks
+// deleted with it
+func (r *PostgresTrashRepository) RestoreTask(ctx context.Context, id uuid.UUID) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		var deletedAt time.Time
+		err := conn(ctx, r.db).GetContext(
+			ctx,
+			&deletedAt,
+			"SELECT deleted_at FROM taskodex.tasks WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE",
+			id,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTrashItemNotFound
+			}
+			return fmt.Errorf("failed to get deleted task: %w", err)
+		}
+
+		// A task cannot be restored under a parent that is still in the trash
+		_, err = conn(ctx, r.db).ExecContext(
+			ctx,
+			`UPDATE taskodex.tasks SET parent_id = NULL
+			WHERE id = $1 AND parent_id IN (SELECT id FROM taskodex.tasks WHERE deleted_at IS NOT NULL)`,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to detach task from deleted parent: %w", err)
+		}
+
+		query := `
+			WITH RECURSIVE subtree AS (
+				SELECT t.id FROM taskodex.tasks t WHERE t.id = $1
+				UNION
+				SELECT t.id FROM taskodex.tasks t
+				JOIN subtree s ON t.parent_id = s.id
+				WHERE t.deleted_at = $2
+			)
+			UPDATE taskodex.tasks
+			SET deleted_at = NULL, deleted_by = NULL
+			WHERE id IN (SELECT id FROM subtree)
+		`
+
+		_, err = conn(ctx, r.db).ExecContext(ctx, query, id, deletedAt)
+		if err != nil {
+			return fmt.Errorf("failed to restore task: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Purge deletes an item deleted before a time for good. Comments, file
+// attachments, time entries and the rest of a task's records are deleted
+// with it, and a project's tasks with the project. It returns
+// ErrTrashItemNotFound if the item was restored or purged meanwhile.
+func (r *PostgresTrashRepository) Purge(ctx context.Context, itemType models.TrashItemType, id uuid.UUID, before time.Time) ([]string, error) {
+	var paths []string
+	err := withTx(ctx, r.db, func(ctx context.Context) error {
+		// Lock the item, so that it cannot be restored while it is purged
+		var lockQuery, pathsQuery string
+		switch itemType {
+		case models.TrashItemTypeProject:
+			lockQuery = "SELECT id FROM taskodex.projects WHERE id = $1 AND deleted_at < $2 FOR UPDATE"
+			pathsQuery = `
+				SELECT a.storage_path
+				FROM taskodex.task_file_attachments a
+				JOIN taskodex.tasks t ON t.id = a.task_id
+				WHERE t.project_id = $1
+			`
+		case models.TrashItemTypeTask:
+			lockQuery = "SELECT id FROM taskodex.tasks WHERE id = $1 AND deleted_at < $2 FOR UPDATE"
+			pathsQuery = `
+				WITH RECURSIVE subtree AS (
+					SELECT t.id FROM taskodex.tasks t WHERE t.id = $1
+					UNION
+					SELECT t.id FROM taskodex.tasks t
+					JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT a.storage_path
+				FROM taskodex.task_file_attachments a
+				WHERE a.task_id IN (SELECT id FROM subtree)
+			`
+		default:
+			return ErrTrashItemNotFound
+		}
+
+		var locked uuid.UUID
+		err := conn(ctx, r.db).GetContext(ctx, &locked, lockQuery, id, before)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTrashItemNotFound
+			}
+			return fmt.Errorf("failed to lock trash item: %w", err)
+		}
+
+		paths = []string{}
+		err = conn(ctx, r.db).SelectContext(ctx, &paths, pathsQuery, id)
+		if err != nil {
+			return fmt.Errorf("failed to list file attachments: %w", err)
+		}
+
+		// Subtasks are deleted with their parent, and every other record of
+		// a task with the task
+		if itemType == models.TrashItemTypeProject {
+			_, err = conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.tasks WHERE project_id = $1", id)
+			if err != nil {
+				return fmt.Errorf("failed to purge project tasks: %w", err)
+			}
+
+			_, err = conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.projects WHERE id = $1", id)
+			if err != nil {
+				return fmt.Errorf("failed to purge project: %w", err)
+			}
+			return nil
+		}
+
+		_, err = conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.tasks WHERE id = $1", id)
+		if err != nil {
+			return fmt.Errorf("failed to purge task: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is the set of query methods shared by *sqlx.DB and *sqlx.Tx
+type DBTX interface {
+	sqlx.ExtContext
+
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error)
+}
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// TxManager runs functions inside a database transaction
+type TxManager interface {
+	// WithTx runs fn inside a transaction. Repository calls made with the
+	// context passed to fn participate in that transaction. The transaction is
+	// committed if fn returns nil and rolled back otherwise. If ctx already
+	// carries a transaction, fn joins it instead of starting a new one.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SQLTxManager implements TxManager using a sqlx database
+type SQLTxManager struct {
+	db *sqlx.DB
+}
+
+// NewTxManager creates a new SQLTxManager
+func NewTxManager(db *sqlx.DB) TxManager {
+	return &SQLTxManager{db: db}
+}
+
+// WithTx runs fn inside a transaction
+func (m *SQLTxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, m.db, fn)
+}
+
+// TxFromContext returns the transaction stored in ctx, if any
+func TxFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// conn returns the transaction stored in ctx, or db if there is none
+func conn(ctx context.Context, db *sqlx.DB) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// withTx runs fn inside a transaction on db, joining the transaction in ctx if present
+func withTx(ctx context.Context, db *sqlx.DB, fn func(ctx context.Context) error) (err error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+	txManager := repository.NewTxManager(tdb.DB)
+	ctx := context.Background()
+
+	newRole := func(name string) *models.Role {
+		now := time.Now()
+		return &models.Role{
+			ID:        uuid.New(),
+			Name:      prefix + name,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		role := newRole("committed")
+		defer roleRepo.DeleteRole(ctx, role.ID)
+
+		err := txManager.WithTx(ctx, func(ctx context.Context) error {
+			_, ok := repository.TxFromContext(ctx)
+			assert.True(t, ok)
+			return roleRepo.CreateRole(ctx, role)
+		})
+		require.NoError(t, err)
+
+		_, err = roleRepo.GetRoleByID(ctx, role.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		role := newRole("rolled_back")
+		errAbort := errors.New("abort")
+
+		err := txManager.WithTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, roleRepo.CreateRole(ctx, role))
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		_, err = roleRepo.GetRoleByID(ctx, role.ID)
+		assert.ErrorIs(t, err, repository.ErrRoleNotFound)
+	})
+
+	t.Run("RollbackOnPanic", func(t *testing.T) {
+		role := newRole("panicked")
+
+		assert.Panics(t, func() {
+			_ = txManager.WithTx(ctx, func(ctx context.Context) error {
+				require.NoError(t, roleRepo.CreateRole(ctx, role))
+				panic("boom")
+			})
+		})
+
+		_, err := roleRepo.GetRoleByID(ctx, role.ID)
+		assert.ErrorIs(t, err, repository.ErrRoleNotFound)
+	})
+
+	t.Run("NestedJoinsOuterTransaction", func(t *testing.T) {
+		role := newRole("nested")
+		errAbort := errors.New("abort")
+
+		err := txManager.WithTx(ctx, func(ctx context.Context) error {
+			outer, _ := repository.TxFromContext(ctx)
+
+			err := txManager.WithTx(ctx, func(ctx context.Context) error {
+				inner, _ := repository.TxFromContext(ctx)
+				assert.Same(t, outer, inner)
+				return roleRepo.CreateRole(ctx, role)
+			})
+			require.NoError(t, err)
+
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		// The inner call must not have committed on its own
+		_, err = roleRepo.GetRoleByID(ctx, role.ID)
+		assert.ErrorIs(t, err, repository.ErrRoleNotFound)
+	})
+}
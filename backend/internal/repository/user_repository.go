@@ -9,6 +9,7 @@ import (
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Common errors
@@ -26,6 +27,9 @@ type UserRepository interface {
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 
+	// GetByIDs retrieves active users by ID, keyed by ID. Unknown IDs are left out.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error)
+
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 
@@ -60,12 +64,17 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 		return ErrEmailAlreadyExists
 	}
 
+	// Users without a time zone use UTC
+	if user.Timezone == "" {
+		user.Timezone = "UTC"
+	}
+
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (id, email, password_hash, first_name, last_name, is_active, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err = r.db.ExecContext(
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		user.ID,
@@ -74,6 +83,7 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 		user.FirstName,
 		user.LastName,
 		user.IsActive,
+		user.Timezone,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -88,13 +98,13 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 // GetByID retrieves a user by ID
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, is_active, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, is_active, timezone, created_at, updated_at
 		FROM users
 		WHERE id = $1 AND is_active = true
 	`
 
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -105,16 +115,47 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	return &user, nil
 }
 
+// GetByIDs retrieves active users by ID, keyed by ID
+func (r *PostgresUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error) {
+	result := make(map[uuid.UUID]*models.User, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	userIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		userIDs = append(userIDs, id.String())
+	}
+
+	query := `
+		SELECT id, email, password_hash, first_name, last_name, is_active, timezone, created_at, updated_at
+		FROM users
+		WHERE id = ANY($1::uuid[]) AND is_active = true
+	`
+
+	var users []models.User
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	for i := range users {
+		result[users[i].ID] = &users[i]
+	}
+
+	return result, nil
+}
+
 // GetByEmail retrieves a user by email
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, is_active, created_at, updated_at
+		SELECT id, email, password_hash, first_name, last_name, is_active, timezone, created_at, updated_at
 		FROM users
 		WHERE email = $1 AND is_active = true
 	`
 
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, email)
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -129,13 +170,14 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, password_hash = $2, first_name = $3, last_name = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		SET email = $1, password_hash = $2, first_name = $3, last_name = $4, is_active = $5,
+			timezone = $6, updated_at = $7
+		WHERE id = $8
 	`
 
 	user.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		user.Email,
@@ -143,6 +185,7 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User)
 		user.FirstName,
 		user.LastName,
 		user.IsActive,
+		user.Timezone,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -162,7 +205,7 @@ func (r *PostgresUserRepository) Delete(ctx context.Context, id uuid.UUID) error
 		WHERE id = $2
 	`
 
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return ErrDatabaseError
 	}
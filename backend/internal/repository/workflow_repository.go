@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for workflow repository
+var (
+	ErrWorkflowNotFound = errors.New("workflow not found")
+)
+
+// workflowLockPrefix prefixes the key of the advisory lock that serializes
+// changes to the workflow of a project with the tasks that follow it
+const workflowLockPrefix = "taskodex.workflow:"
+
+// WorkflowRepository defines the interface for workflow data access
+type WorkflowRepository interface {
+	// GetByProject retrieves the workflow of a project, with its states and transitions
+	GetByProject(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error)
+
+	// Save creates or replaces the workflow of a project
+	Save(ctx context.Context, workflow *models.Workflow) error
+
+	// DeleteByProject deletes the workflow of a project
+	DeleteByProject(ctx context.Context, projectID uuid.UUID) error
+
+	// Lock keeps tasks from entering or changing state in a project while its
+	// workflow is replaced, until the transaction in ctx ends
+	Lock(ctx context.Context, projectID uuid.UUID) error
+
+	// LockShared keeps the workflow of a project from being replaced while a
+	// task is checked against it and saved, until the transaction in ctx ends
+	LockShared(ctx context.Context, projectID uuid.UUID) error
+}
+
+// PostgresWorkflowRepository implements WorkflowRepository using PostgreSQL
+type PostgresWorkflowRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresWorkflowRepository creates a new PostgresWorkflowRepository
+func NewPostgresWorkflowRepository(db *sqlx.DB) WorkflowRepository {
+	return &PostgresWorkflowRepository{db: db}
+}
+
+// GetByProject retrieves the workflow of a project, with its states and transitions
+func (r *PostgresWorkflowRepository) GetByProject(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error) {
+	query := `
+		SELECT id, project_id, name, initial_state, created_at, updated_at
+		FROM taskodex.workflows
+		WHERE project_id = $1
+	`
+
+	var workflow models.Workflow
+	err := conn(ctx, r.db).GetContext(ctx, &workflow, query, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkflowNotFound
+		}
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	// Get states
+	workflow.States = []models.WorkflowState{}
+	err = conn(ctx, r.db).SelectContext(
+		ctx,
+		&workflow.States,
+		"SELECT key, name, category, position FROM taskodex.workflow_states WHERE workflow_id = $1 ORDER BY position, key",
+		workflow.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow states: %w", err)
+	}
+
+	// Get transitions
+	rows, err := conn(ctx, r.db).QueryxContext(
+		ctx,
+		"SELECT from_state, to_state, required_fields, required_role FROM taskodex.workflow_transitions WHERE workflow_id = $1 ORDER BY from_state, to_state",
+		workflow.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow transitions: %w", err)
+	}
+	defer rows.Close()
+
+	workflow.Transitions = []models.WorkflowTransition{}
+	for rows.Next() {
+		var transition models.WorkflowTransition
+		err := rows.Scan(
+			&transition.FromState,
+			&transition.ToState,
+			pq.Array(&transition.RequiredFields),
+			&transition.RequiredRole,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workflow transition: %w", err)
+		}
+		workflow.Transitions = append(workflow.Transitions, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workflow transitions: %w", err)
+	}
+
+	return &workflow, nil
+}
+
+// Save creates or replaces the workflow of a project.
+// The states and transitions of an existing workflow are replaced as a whole.
+func (r *PostgresWorkflowRepository) Save(ctx context.Context, workflow *models.Workflow) error {
+	return withTx(ctx, r.db, func(ctx context.Context) error {
+		workflow.UpdatedAt = time.Now()
+
+		// Keep the ID and creation time of an existing workflow
+		query := `
+			INSERT INTO taskodex.workflows (id, project_id, name, initial_state, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (project_id) DO UPDATE
+			SET name = EXCLUDED.name, initial_state = EXCLUDED.initial_state, updated_at = EXCLUDED.updated_at
+			RETURNING id, created_at
+		`
+
+		err := conn(ctx, r.db).QueryRowxContext(
+			ctx,
+			query,
+			workflow.ID,
+			workflow.ProjectID,
+			workflow.Name,
+			workflow.InitialState,
+			workflow.CreatedAt,
+			workflow.UpdatedAt,
+		).Scan(&workflow.ID, &workflow.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save workflow: %w", err)
+		}
+
+		// Replace states and transitions. Transitions are removed with their states.
+		_, err = conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.workflow_states WHERE workflow_id = $1", workflow.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete workflow states: %w", err)
+		}
+
+		for _, state := range workflow.States {
+			_, err = conn(ctx, r.db).ExecContext(
+				ctx,
+				"INSERT INTO taskodex.workflow_states (workflow_id, key, name, category, position) VALUES ($1, $2, $3, $4, $5)",
+				workflow.ID,
+				state.Key,
+				state.Name,
+				state.Category,
+				state.Position,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert workflow state: %w", err)
+			}
+		}
+
+		for _, transition := range workflow.Transitions {
+			requiredFields := transition.RequiredFields
+			if requiredFields == nil {
+				requiredFields = []string{}
+			}
+
+			_, err = conn(ctx, r.db).ExecContext(
+				ctx,
+				"INSERT INTO taskodex.workflow_transitions (workflow_id, from_state, to_state, required_fields, required_role) VALUES ($1, $2, $3, $4, $5)",
+				workflow.ID,
+				transition.FromState,
+				transition.ToState,
+				pq.Array(requiredFields),
+				transition.RequiredRole,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert workflow transition: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteByProject deletes the workflow of a project
+func (r *PostgresWorkflowRepository) DeleteByProject(ctx context.Context, projectID uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM taskodex.workflows WHERE project_id = $1", projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrWorkflowNotFound
+	}
+
+	return nil
+}
+
+// Lock takes the project's workflow lock exclusively
+func (r *PostgresWorkflowRepository) Lock(ctx context.Context, projectID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", workflowLockPrefix+projectID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock workflow: %w", err)
+	}
+
+	return nil
+}
+
+// LockShared takes the project's workflow lock in shared mode, so that
+// tasks of the project can still be saved concurrently
+func (r *PostgresWorkflowRepository) LockShared(ctx context.Context, projectID uuid.UUID) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "SELECT pg_advisory_xact_lock_shared(hashtext($1))", workflowLockPrefix+projectID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock workflow: %w", err)
+	}
+
+	return nil
+}
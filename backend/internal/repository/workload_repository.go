@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Common errors for workload repository
+var (
+	ErrWorkloadCapacityNotFound = errors.New("workload capacity not found")
+)
+
+// workloadCapacityColumns are the columns of a workload capacity
+const workloadCapacityColumns = `organization_id, user_id, hours_per_day, work_days, updated_by, updated_at`
+
+// WorkloadRepository defines the interface for workload data access
+type WorkloadRepository interface {
+	// GetCapacity retrieves the capacity set for a member of an organization
+	GetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error)
+
+	// ListCapacities retrieves the capacities set for the members of an
+	// organization
+	ListCapacities(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadCapacity, error)
+
+	// SetCapacity creates or replaces the capacity of a member
+	SetCapacity(ctx context.Context, capacity *models.WorkloadCapacity) error
+
+	// DeleteCapacity deletes the capacity of a member, who then has the
+	// default capacity
+	DeleteCapacity(ctx context.Context, organizationID, userID uuid.UUID) error
+
+	// ListTasks retrieves the open, assigned tasks with an estimate in the
+	// projects of an organization that are neither deleted nor archived
+	ListTasks(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadTask, error)
+
+	// ListTimeOff retrieves the approved time off of the employees of an
+	// organization that overlaps the days from and to
+	ListTimeOff(ctx context.Context, organizationID uuid.UUID, from, to time.Time) ([]models.WorkloadTimeOff, error)
+}
+
+// PostgresWorkloadRepository implements WorkloadRepository using PostgreSQL
+type PostgresWorkloadRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresWorkloadRepository creates a new PostgresWorkloadRepository
+func NewPostgresWorkloadRepository(db *sqlx.DB) WorkloadRepository {
+	return &PostgresWorkloadRepository{db: db}
+}
+
+// GetCapacity retrieves the capacity set for a member
+func (r *PostgresWorkloadRepository) GetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error) {
+	row := conn(ctx, r.db).QueryRowxContext(
+		ctx,
+		"SELECT "+workloadCapacityColumns+" FROM taskodex.workload_capacities WHERE organization_id = $1 AND user_id = $2",
+		organizationID,
+		userID,
+	)
+
+	capacity, err := scanWorkloadCapacity(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkloadCapacityNotFound
+		}
+		return nil, fmt.Errorf("failed to get workload capacity: %w", err)
+	}
+
+	return capacity, nil
+}
+
+// ListCapacities retrieves the capacities set for the members of an
+// organization
+func (r *PostgresWorkloadRepository) ListCapacities(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadCapacity, error) {
+	rows, err := conn(ctx, r.db).QueryxContext(
+		ctx,
+		"SELECT "+workloadCapacityColumns+" FROM taskodex.workload_capacities WHERE organization_id = $1 ORDER BY user_id",
+		organizationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workload capacities: %w", err)
+	}
+	defer rows.Close()
+
+	capacities := []models.WorkloadCapacity{}
+	for rows.Next() {
+		capacity, err := scanWorkloadCapacity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workload capacity: %w", err)
+		}
+		capacities = append(capacities, *capacity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workload capacities: %w", err)
+	}
+
+	return capacities, nil
+}
+
+// SetCapacity creates or replaces the capacity of a member
+func (r *PostgresWorkloadRepository) SetCapacity(ctx context.Context, capacity *models.WorkloadCapacity) error {
+	query := `
+		INSERT INTO taskodex.workload_capacities (
+			organization_id, user_id, hours_per_day, work_days, updated_by, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET
+			hours_per_day = EXCLUDED.hours_per_day,
+			work_days = EXCLUDED.work_days,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		capacity.OrganizationID,
+		capacity.UserID,
+		capacity.HoursPerDay,
+		pq.Array(capacity.WorkDays),
+		capacity.UpdatedBy,
+		capacity.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set workload capacity: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCapacity deletes the capacity of a member
+func (r *PostgresWorkloadRepository) DeleteCapacity(ctx context.Context, organizationID, userID uuid.UUID) error {
+	query := `
+		DELETE FROM taskodex.workload_capacities
+		WHERE organization_id = $1 AND user_id = $2
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete workload capacity: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrWorkloadCapacityNotFound
+	}
+
+	return nil
+}
+
+// ListTasks retrieves the open, assigned tasks with an estimate in the
+// active projects of an organization
+func (r *PostgresWorkloadRepository) ListTasks(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadTask, error) {
+	query := `
+		SELECT t.id, t.project_id, t.title, t.priority, t.due_date, t.assigned_to,
+			t.estimated_hours, t.actual_hours
+		FROM taskodex.tasks t
+		JOIN taskodex.projects p ON p.id = t.project_id
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND p.archived_at IS NULL
+			AND t.deleted_at IS NULL AND t.assigned_to IS NOT NULL AND t.estimated_hours > 0
+			AND NOT ` + taskClosedSQL("$2") + `
+		ORDER BY t.due_date NULLS LAST, t.id
+	`
+
+	tasks := []models.WorkloadTask{}
+	err := conn(ctx, r.db).SelectContext(ctx, &tasks, query, organizationID, pq.Array(defaultClosedStatuses()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workload tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListTimeOff retrieves the approved time off of the employees of an
+// organization that overlaps a range of days
+func (r *PostgresWorkloadRepository) ListTimeOff(ctx context.Context, organizationID uuid.UUID, from, to time.Time) ([]models.WorkloadTimeOff, error) {
+	query := `
+		SELECT e.user_id, o.start_date, o.end_date
+		FROM qultrix.time_off_requests o
+		JOIN qultrix.employees e ON e.id = o.employee_id
+		WHERE e.organization_id = $1 AND o.status = 'approved'
+			AND o.start_date <= $3 AND o.end_date >= $2
+		ORDER BY e.user_id, o.start_date
+	`
+
+	timeOff := []models.WorkloadTimeOff{}
+	err := conn(ctx, r.db).SelectContext(ctx, &timeOff, query, organizationID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time off: %w", err)
+	}
+
+	return timeOff, nil
+}
+
+// scanWorkloadCapacity scans a workload capacity selected with
+// workloadCapacityColumns
+func scanWorkloadCapacity(row interface{ Scan(...interface{}) error }) (*models.WorkloadCapacity, error) {
+	var capacity models.WorkloadCapacity
+	err := row.Scan(
+		&capacity.OrganizationID,
+		&capacity.UserID,
+		&capacity.HoursPerDay,
+		pq.Array(&capacity.WorkDays),
+		&capacity.UpdatedBy,
+		&capacity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &capacity, nil
+}
@@ -0,0 +1,138 @@
+package activity_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/activity"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/comment"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityAPI(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	taskHistoryRepo := repository.NewPostgresTaskHistoryRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	commentRepo := repository.NewPostgresCommentRepository(tdb.DB)
+	notificationRepo := repository.NewPostgresNotificationRepository(tdb.DB)
+
+	// Create services
+	notificationService := notification.NewService(notificationRepo, userRepo)
+	taskService := task.NewService(
+		taskRepo,
+		taskHistoryRepo,
+		repository.NewPostgresTaskLinkRepository(tdb.DB),
+		repository.NewPostgresChecklistItemRepository(tdb.DB),
+		projectRepo,
+		userRepo,
+		repository.NewPostgresRoleRepository(tdb.DB),
+		notificationService,
+		workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB)),
+		customfield.NewService(repository.NewPostgresCustomFieldRepository(tdb.DB), projectRepo, userRepo, repository.NewPostgresRoleRepository(tdb.DB)),
+		repository.NewTxManager(tdb.DB),
+	)
+	commentService := comment.NewService(commentRepo, taskRepo, userRepo, notificationService)
+	activityService := activity.NewService(taskHistoryRepo, taskRepo, projectRepo, "http://localhost")
+	activityHandlers := activity.NewHandlers(activityService)
+
+	// Setup Echo
+	e := echo.New()
+
+	// Create a task and change it
+	ctx := context.Background()
+	created, err := taskService.Create(ctx, models.TaskRequest{
+		ProjectID: &testProject.ID,
+		Title:     prefix + "Activity Task",
+		Priority:  models.TaskPriorityLow,
+	}, testUser.ID)
+	require.NoError(t, err)
+
+	_, err = taskService.Update(ctx, created.ID, models.TaskRequest{
+		ProjectID: &testProject.ID,
+		Title:     prefix + "Renamed Task",
+		Priority:  models.TaskPriorityHigh,
+	}, testUser.ID)
+	require.NoError(t, err)
+
+	_, err = taskService.AssignTask(ctx, created.ID, testUser.ID, testUser.ID)
+	require.NoError(t, err)
+
+	err = taskService.AddTag(ctx, created.ID, "backend", testUser.ID)
+	require.NoError(t, err)
+
+	_, err = commentService.Create(ctx, models.CommentRequest{
+		TaskID:  created.ID,
+		Content: "Looks good",
+	}, testUser.ID)
+	require.NoError(t, err)
+
+	t.Run("TaskActivity", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/activity")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), created.ID.String())
+
+		err := activityHandlers.ListForTask(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Activity []models.ActivityResponse `json:"activity"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		// created, title, priority, assigned, tag_added and the comment
+		require.Len(t, response.Activity, 6)
+
+		actions := map[models.TaskHistoryAction]bool{}
+		comments := 0
+		for _, item := range response.Activity {
+			switch item.Type {
+			case models.ActivityTypeHistory:
+				actions[item.History.Action] = true
+				assert.Equal(t, testUser.ID, item.History.UserID)
+			case models.ActivityTypeComment:
+				comments++
+			}
+		}
+		assert.Equal(t, 1, comments)
+		assert.True(t, actions[models.TaskHistoryActionCreated])
+		assert.True(t, actions[models.TaskHistoryActionUpdated])
+		assert.True(t, actions[models.TaskHistoryActionAssigned])
+		assert.True(t, actions[models.TaskHistoryActionTagAdded])
+	})
+
+	t.Run("ProjectActivity", func(t *testing.T) {
+		activity, total, err := activityService.ListForProject(ctx, testProject.ID, models.ActivityListParams{Page: 1, PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 6, total)
+		assert.Len(t, activity, 2)
+	})
+}
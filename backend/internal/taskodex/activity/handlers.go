@@ -0,0 +1,136 @@
+package activity
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for activity feeds
+type Handlers struct {
+	service Service
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+// ListForTask handles retrieving the activity feed of a task
+func (h *Handlers) ListForTask(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse pagination parameters
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Get activity
+	activity, total, err := h.service.ListForTask(c.Request().Context(), id, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve activity")
+	}
+
+	return c.JSON(http.StatusOK, listResponse(activity, total, params))
+}
+
+// ListForProject handles retrieving the activity feed of a project
+func (h *Handlers) ListForProject(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse pagination parameters
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Get activity
+	activity, total, err := h.service.ListForProject(c.Request().Context(), id, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve activity")
+	}
+
+	return c.JSON(http.StatusOK, listResponse(activity, total, params))
+}
+
+// parseListParams parses the page and page_size query parameters
+func parseListParams(c echo.Context) (models.ActivityListParams, error) {
+	params := models.ActivityListParams{Page: 1, PageSize: 20}
+
+	// Parse page parameter
+	pageParam := c.QueryParam("page")
+	if pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil {
+			return params, echo.NewHTTPError(http.StatusBadRequest, "Invalid page parameter")
+		}
+		params.Page = page
+	}
+
+	// Parse page_size parameter
+	pageSizeParam := c.QueryParam("page_size")
+	if pageSizeParam != "" {
+		pageSize, err := strconv.Atoi(pageSizeParam)
+		if err != nil {
+			return params, echo.NewHTTPError(http.StatusBadRequest, "Invalid page_size parameter")
+		}
+		params.PageSize = pageSize
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	return params, nil
+}
+
+// listResponse builds a paginated activity feed response
+func listResponse(activity []models.ActivityResponse, total int, params models.ActivityListParams) map[string]interface{} {
+	return map[string]interface{}{
+		"activity": activity,
+		"pagination": map[string]interface{}{
+			"total":       total,
+			"page":        params.Page,
+			"page_size":   params.PageSize,
+			"total_pages": (total + params.PageSize - 1) / params.PageSize,
+		},
+	}
+}
+
+// RegisterRoutes registers the activity routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require task:read permission
+	g.GET("/tasks/:id/activity", h.ListForTask, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+
+	// Routes that require project:read permission
+	g.GET("/projects/:id/activity", h.ListForProject, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+}
@@ -0,0 +1,84 @@
+package activity
+
+import (
+	"context"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Service provides task and project activity feeds
+type Service interface {
+	// ListForTask retrieves the activity feed of a task: its history,
+	// comments and file attachments, newest first
+	ListForTask(ctx context.Context, taskID uuid.UUID, params models.ActivityListParams) ([]models.ActivityResponse, int, error)
+
+	// ListForProject retrieves the activity feed of all tasks of a project
+	ListForProject(ctx context.Context, projectID uuid.UUID, params models.ActivityListParams) ([]models.ActivityResponse, int, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	taskHistoryRepo repository.TaskHistoryRepository
+	taskRepo        repository.TaskRepository
+	projectRepo     repository.ProjectRepository
+	baseURL         string
+}
+
+// NewService creates a new activity service. baseURL is used to build
+// attachment download URLs.
+func NewService(
+	taskHistoryRepo repository.TaskHistoryRepository,
+	taskRepo repository.TaskRepository,
+	projectRepo repository.ProjectRepository,
+	baseURL string,
+) Service {
+	return &serviceImpl{
+		taskHistoryRepo: taskHistoryRepo,
+		taskRepo:        taskRepo,
+		projectRepo:     projectRepo,
+		baseURL:         baseURL,
+	}
+}
+
+// ListForTask retrieves the activity feed of a task
+func (s *serviceImpl) ListForTask(ctx context.Context, taskID uuid.UUID, params models.ActivityListParams) ([]models.ActivityResponse, int, error) {
+	// Check if task exists
+	_, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params.TaskID = &taskID
+	params.ProjectID = nil
+	return s.list(ctx, params)
+}
+
+// ListForProject retrieves the activity feed of all tasks of a project
+func (s *serviceImpl) ListForProject(ctx context.Context, projectID uuid.UUID, params models.ActivityListParams) ([]models.ActivityResponse, int, error) {
+	// Check if project exists
+	_, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params.TaskID = nil
+	params.ProjectID = &projectID
+	return s.list(ctx, params)
+}
+
+// list retrieves an activity feed and converts it to responses
+func (s *serviceImpl) list(ctx context.Context, params models.ActivityListParams) ([]models.ActivityResponse, int, error) {
+	activities, total, err := s.taskHistoryRepo.ListActivity(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.ActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		responses = append(responses, activity.ToResponse(s.baseURL))
+	}
+
+	return responses, total, nil
+}
@@ -0,0 +1,242 @@
+package analytics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/analytics"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/sprint"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalytics(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	historyRepo := repository.NewPostgresTaskHistoryRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	sprintRepo := repository.NewPostgresSprintRepository(tdb.DB)
+
+	// Create services and handlers
+	workflowService := workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB))
+	sprintService := sprint.NewService(sprintRepo, projectRepo, workflowService, repository.NewTxManager(tdb.DB))
+	analyticsService := analytics.NewService(
+		repository.NewPostgresAnalyticsRepository(tdb.DB),
+		sprintRepo,
+		projectRepo,
+		workflowService,
+	)
+	analyticsHandlers := analytics.NewHandlers(analyticsService)
+	e := echo.New()
+
+	// The sprint started three days ago, at 09:00 UTC
+	now := time.Now().UTC()
+	d0 := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -3)
+	at := func(days, hours int) time.Time {
+		return d0.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour)
+	}
+
+	testSprint := models.NewSprint(testProject.ID, models.SprintRequest{Name: "Sprint 1"}, testUser.ID)
+	require.NoError(t, sprintRepo.Create(ctx, testSprint))
+	startedAt := at(0, 9)
+	testSprint.State = models.SprintStateActive
+	testSprint.StartedAt = &startedAt
+	require.NoError(t, sprintRepo.SetState(ctx, testSprint, models.SprintStatePlanned))
+
+	// Create tasks in the sprint with their history
+	sprintID := testSprint.ID.String()
+	change := func(task *models.Task, field string, oldValue, newValue string, when time.Time) {
+		entry := models.NewTaskHistory(task.ID, testUser.ID, models.TaskFieldChange{
+			Field:    field,
+			OldValue: &oldValue,
+			NewValue: &newValue,
+		})
+		if oldValue == "" {
+			entry.OldValue = nil
+		}
+		entry.CreatedAt = when
+		require.NoError(t, historyRepo.Create(ctx, entry))
+	}
+	newTask := func(title string, status models.TaskStatus, hours float64, createdAt time.Time) *models.Task {
+		task := models.NewTask(models.TaskRequest{
+			ProjectID:      &testProject.ID,
+			Title:          prefix + title,
+			Status:         status,
+			Priority:       models.TaskPriorityMedium,
+			EstimatedHours: &hours,
+		}, testUser.ID)
+		task.SprintID = &testSprint.ID
+		task.CreatedAt = createdAt
+		task.UpdatedAt = createdAt
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+
+	// a is committed and done on the third day
+	a := newTask("A", models.TaskStatusDone, 3, at(-2, 0))
+	change(a, models.TaskFieldSprintID, "", sprintID, at(-1, 0))
+	change(a, models.TaskFieldStatus, "todo", "in_progress", at(1, 10))
+	change(a, models.TaskFieldStatus, "in_progress", "done", at(2, 10))
+
+	// b is committed and not started
+	b := newTask("B", models.TaskStatusTodo, 5, at(-2, 0))
+	change(b, models.TaskFieldSprintID, "", sprintID, at(-1, 0))
+
+	// added joins on the second day and is done straight away
+	added := newTask("C", models.TaskStatusDone, 2, at(1, 9))
+	change(added, models.TaskFieldSprintID, "", sprintID, at(1, 12))
+	change(added, models.TaskFieldStatus, "todo", "done", at(1, 15))
+
+	t.Run("Burndown", func(t *testing.T) {
+		burndown, err := analyticsService.Burndown(ctx, testSprint.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, burndown.CommittedTasks)
+		assert.Equal(t, 8.0, burndown.CommittedHours)
+		require.Len(t, burndown.Days, 4)
+
+		scope := []int{2, 3, 3, 3}
+		completed := []int{0, 1, 2, 2}
+		for i, day := range burndown.Days {
+			assert.Equal(t, at(i, 0).Format(models.AnalyticsDateFormat), day.Date)
+			assert.Equal(t, scope[i], day.ScopeTasks, day.Date)
+			assert.Equal(t, completed[i], day.CompletedTasks, day.Date)
+			assert.Equal(t, scope[i]-completed[i], day.RemainingTasks, day.Date)
+		}
+		assert.Equal(t, 5.0, burndown.Days[2].CompletedHours)
+		assert.Equal(t, 5.0, burndown.Days[2].RemainingHours)
+
+		// The ideal burndown reaches zero on the last day
+		assert.Equal(t, 1.5, burndown.Days[0].IdealTasks)
+		assert.Equal(t, 0.0, burndown.Days[3].IdealTasks)
+
+		// Planned sprints have no burndown
+		planned, err := sprintService.Create(ctx, testProject.ID, models.SprintRequest{Name: "Sprint 2"}, testUser.ID)
+		require.NoError(t, err)
+		_, err = analyticsService.Burndown(ctx, planned.ID)
+		assert.ErrorIs(t, err, analytics.ErrSprintNotStarted)
+	})
+
+	t.Run("Velocity", func(t *testing.T) {
+		velocity, err := analyticsService.Velocity(ctx, testProject.ID, models.DefaultVelocitySprints)
+		require.NoError(t, err)
+		assert.Empty(t, velocity.Sprints)
+
+		// b is carried over to the backlog
+		_, err = sprintService.Close(ctx, testSprint.ID, models.SprintCloseRequest{}, testUser.ID)
+		require.NoError(t, err)
+
+		velocity, err = analyticsService.Velocity(ctx, testProject.ID, models.DefaultVelocitySprints)
+		require.NoError(t, err)
+		require.Len(t, velocity.Sprints, 1)
+		assert.Equal(t, testSprint.ID, velocity.Sprints[0].SprintID)
+		assert.Equal(t, 2, velocity.Sprints[0].CommittedTasks)
+		assert.Equal(t, 8.0, velocity.Sprints[0].CommittedHours)
+		assert.Equal(t, 2, velocity.Sprints[0].CompletedTasks)
+		assert.Equal(t, 5.0, velocity.Sprints[0].CompletedHours)
+		assert.Equal(t, 2.0, velocity.AverageCompletedTasks)
+
+		// The burndown of the closed sprint ends before b was carried over
+		burndown, err := analyticsService.Burndown(ctx, testSprint.ID)
+		require.NoError(t, err)
+		require.NotNil(t, burndown.ClosedAt)
+		last := burndown.Days[len(burndown.Days)-1]
+		assert.Equal(t, 3, last.ScopeTasks)
+		assert.Equal(t, 1, last.RemainingTasks)
+	})
+
+	t.Run("CycleTime", func(t *testing.T) {
+		cycleTime, err := analyticsService.CycleTime(ctx, testProject.ID, 2)
+		require.NoError(t, err)
+
+		// a took 106 hours from creation, added 6 hours
+		assert.Equal(t, models.Distribution{Count: 2, Mean: 56, Median: 6, P85: 106, P95: 106, Min: 6, Max: 106}, cycleTime.LeadTime)
+
+		// Only a was in progress
+		assert.Equal(t, 1, cycleTime.CycleTime.Count)
+		assert.Equal(t, 24.0, cycleTime.CycleTime.Mean)
+
+		require.Len(t, cycleTime.Transitions, 3)
+		assert.Equal(t, models.TaskStatusTodo, cycleTime.Transitions[0].From)
+		assert.Equal(t, models.TaskStatusInProgress, cycleTime.Transitions[0].To)
+		assert.Equal(t, 82.0, cycleTime.Transitions[0].Time.Mean)
+		assert.Equal(t, models.TaskStatusDone, cycleTime.Transitions[1].To)
+		assert.Equal(t, 6.0, cycleTime.Transitions[1].Time.Mean)
+		assert.Equal(t, models.TaskStatusInProgress, cycleTime.Transitions[2].From)
+		assert.Equal(t, 24.0, cycleTime.Transitions[2].Time.Mean)
+	})
+
+	t.Run("Throughput", func(t *testing.T) {
+		completed := func() (int, float64) {
+			throughput, err := analyticsService.Throughput(ctx, testProject.ID, 2)
+			require.NoError(t, err)
+			require.Len(t, throughput.Weeks, 2)
+			tasks, hours := 0, 0.0
+			for _, week := range throughput.Weeks {
+				tasks += week.CompletedTasks
+				hours += week.CompletedHours
+			}
+			return tasks, hours
+		}
+
+		tasks, hours := completed()
+		assert.Equal(t, 2, tasks)
+		assert.Equal(t, 5.0, hours)
+
+		// Completing b invalidates the cached report
+		b.Status = models.TaskStatusDone
+		require.NoError(t, taskRepo.Update(ctx, b))
+		change(b, models.TaskFieldStatus, "todo", "done", time.Now())
+
+		tasks, hours = completed()
+		assert.Equal(t, 3, tasks)
+		assert.Equal(t, 10.0, hours)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv&weeks=2", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id/analytics/throughput")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+
+		require.NoError(t, analyticsHandlers.Throughput(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get(echo.HeaderContentType))
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "week_start,completed_tasks,completed_hours", lines[0])
+
+		// Unknown projects are not found
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(uuid.New().String())
+		err := analyticsHandlers.Velocity(c)
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+		}
+	})
+}
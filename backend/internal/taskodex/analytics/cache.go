@@ -0,0 +1,80 @@
+package analytics
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheSize is the number of reports kept in the cache
+const cacheSize = 512
+
+// cache keeps the most recently used reports. Each report is stored with the
+// stamp of the project data it was computed from, and is only returned for
+// the same stamp, so a report is invalidated by any change to the tasks of
+// its project.
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// cacheEntry is a report in the cache
+type cacheEntry struct {
+	key    string
+	stamp  string
+	report interface{}
+}
+
+// newCache creates a cache of up to size reports
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the report stored for key if it was computed from data with
+// the given stamp
+func (c *cache) get(key, stamp string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if entry.stamp != stamp {
+		// The data has changed since
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.report, true
+}
+
+// set stores the report for key, computed from data with the given stamp
+func (c *cache) set(key, stamp string, report interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = &cacheEntry{key: key, stamp: stamp, report: report}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, stamp: stamp, report: report})
+
+	// Evict the least recently used report
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
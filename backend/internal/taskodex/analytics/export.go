@@ -0,0 +1,110 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+)
+
+// writeBurndownCSV writes a burndown as CSV, a row per day
+func writeBurndownCSV(w io.Writer, burndown *models.Burndown) error {
+	rows := [][]string{{
+		"date", "scope_tasks", "completed_tasks", "remaining_tasks", "ideal_tasks",
+		"scope_hours", "completed_hours", "remaining_hours", "ideal_hours",
+	}}
+	for _, day := range burndown.Days {
+		rows = append(rows, []string{
+			day.Date,
+			strconv.Itoa(day.ScopeTasks),
+			strconv.Itoa(day.CompletedTasks),
+			strconv.Itoa(day.RemainingTasks),
+			formatFloat(day.IdealTasks),
+			formatFloat(day.ScopeHours),
+			formatFloat(day.CompletedHours),
+			formatFloat(day.RemainingHours),
+			formatFloat(day.IdealHours),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// writeVelocityCSV writes a velocity report as CSV, a row per sprint
+func writeVelocityCSV(w io.Writer, velocity *models.Velocity) error {
+	rows := [][]string{{
+		"sprint_id", "name", "started_at", "closed_at",
+		"committed_tasks", "completed_tasks", "committed_hours", "completed_hours",
+	}}
+	for _, sprint := range velocity.Sprints {
+		rows = append(rows, []string{
+			sprint.SprintID.String(),
+			sprint.Name,
+			sprint.StartedAt.UTC().Format(time.RFC3339),
+			sprint.ClosedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(sprint.CommittedTasks),
+			strconv.Itoa(sprint.CompletedTasks),
+			formatFloat(sprint.CommittedHours),
+			formatFloat(sprint.CompletedHours),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// writeCycleTimeCSV writes a cycle time report as CSV: a row for lead time,
+// one for cycle time and one per status transition
+func writeCycleTimeCSV(w io.Writer, cycleTime *models.CycleTime) error {
+	rows := [][]string{
+		{"metric", "from_status", "to_status", "count", "mean", "median", "p85", "p95", "min", "max"},
+		distributionRow("lead_time", "", "", cycleTime.LeadTime),
+		distributionRow("cycle_time", "", "", cycleTime.CycleTime),
+	}
+	for _, transition := range cycleTime.Transitions {
+		rows = append(rows, distributionRow("transition", string(transition.From), string(transition.To), transition.Time))
+	}
+	return writeCSV(w, rows)
+}
+
+// writeThroughputCSV writes a throughput report as CSV, a row per week
+func writeThroughputCSV(w io.Writer, throughput *models.Throughput) error {
+	rows := [][]string{{"week_start", "completed_tasks", "completed_hours"}}
+	for _, week := range throughput.Weeks {
+		rows = append(rows, []string{
+			week.WeekStart,
+			strconv.Itoa(week.CompletedTasks),
+			formatFloat(week.CompletedHours),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// distributionRow renders a distribution as a CSV row
+func distributionRow(metric, from, to string, d models.Distribution) []string {
+	return []string{
+		metric,
+		from,
+		to,
+		strconv.Itoa(d.Count),
+		formatFloat(d.Mean),
+		formatFloat(d.Median),
+		formatFloat(d.P85),
+		formatFloat(d.P95),
+		formatFloat(d.Min),
+		formatFloat(d.Max),
+	}
+}
+
+// writeCSV writes rows as CSV
+func writeCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// formatFloat renders a number without trailing zeros
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,210 @@
+package analytics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for project analytics
+type Handlers struct {
+	service Service
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+// Burndown handles retrieving the burndown of a sprint
+func (h *Handlers) Burndown(c echo.Context) error {
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	csv, err := parseFormat(c)
+	if err != nil {
+		return err
+	}
+
+	// Get burndown
+	burndown, err := h.service.Burndown(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve burndown")
+	}
+
+	if csv {
+		return respondCSV(c, fmt.Sprintf("burndown-%s.csv", id), func(w io.Writer) error {
+			return writeBurndownCSV(w, burndown)
+		})
+	}
+	return c.JSON(http.StatusOK, burndown)
+}
+
+// Velocity handles retrieving the velocity of a project
+func (h *Handlers) Velocity(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	sprints, err := parseCount(c, "sprints", models.DefaultVelocitySprints, models.MaxVelocitySprints)
+	if err != nil {
+		return err
+	}
+
+	csv, err := parseFormat(c)
+	if err != nil {
+		return err
+	}
+
+	// Get velocity
+	velocity, err := h.service.Velocity(c.Request().Context(), projectID, sprints)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve velocity")
+	}
+
+	if csv {
+		return respondCSV(c, fmt.Sprintf("velocity-%s.csv", projectID), func(w io.Writer) error {
+			return writeVelocityCSV(w, velocity)
+		})
+	}
+	return c.JSON(http.StatusOK, velocity)
+}
+
+// CycleTime handles retrieving the cycle times of a project
+func (h *Handlers) CycleTime(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	weeks, err := parseCount(c, "weeks", models.DefaultAnalyticsWeeks, models.MaxAnalyticsWeeks)
+	if err != nil {
+		return err
+	}
+
+	csv, err := parseFormat(c)
+	if err != nil {
+		return err
+	}
+
+	// Get cycle times
+	cycleTime, err := h.service.CycleTime(c.Request().Context(), projectID, weeks)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve cycle time")
+	}
+
+	if csv {
+		return respondCSV(c, fmt.Sprintf("cycle-time-%s.csv", projectID), func(w io.Writer) error {
+			return writeCycleTimeCSV(w, cycleTime)
+		})
+	}
+	return c.JSON(http.StatusOK, cycleTime)
+}
+
+// Throughput handles retrieving the weekly throughput of a project
+func (h *Handlers) Throughput(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	weeks, err := parseCount(c, "weeks", models.DefaultAnalyticsWeeks, models.MaxAnalyticsWeeks)
+	if err != nil {
+		return err
+	}
+
+	csv, err := parseFormat(c)
+	if err != nil {
+		return err
+	}
+
+	// Get throughput
+	throughput, err := h.service.Throughput(c.Request().Context(), projectID, weeks)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve throughput")
+	}
+
+	if csv {
+		return respondCSV(c, fmt.Sprintf("throughput-%s.csv", projectID), func(w io.Writer) error {
+			return writeThroughputCSV(w, throughput)
+		})
+	}
+	return c.JSON(http.StatusOK, throughput)
+}
+
+// handleError maps errors from retrieving reports to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	case errors.Is(err, repository.ErrSprintNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+	case errors.Is(err, ErrSprintNotStarted):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// parseFormat parses the format parameter, and reports whether the report
+// is requested as CSV rather than JSON
+func parseFormat(c echo.Context) (bool, error) {
+	switch c.QueryParam("format") {
+	case "", "json":
+		return false, nil
+	case "csv":
+		return true, nil
+	}
+	return false, echo.NewHTTPError(http.StatusBadRequest, "Invalid format parameter")
+}
+
+// parseCount parses a count parameter between 1 and max
+func parseCount(c echo.Context, name string, defaultValue, max int) (int, error) {
+	param := c.QueryParam(name)
+	if param == "" {
+		return defaultValue, nil
+	}
+
+	count, err := strconv.Atoi(param)
+	if err != nil || count < 1 || count > max {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter", name))
+	}
+	return count, nil
+}
+
+// respondCSV responds with a report written as CSV
+func respondCSV(c echo.Context, filename string, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to write report")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+filename)
+	return c.Blob(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// RegisterRoutes registers the analytics routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require project:read permission
+	g.GET("/sprints/:id/burndown", h.Burndown, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	g.GET("/projects/:id/analytics/velocity", h.Velocity, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	g.GET("/projects/:id/analytics/cycle-time", h.CycleTime, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	g.GET("/projects/:id/analytics/throughput", h.Throughput, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+}
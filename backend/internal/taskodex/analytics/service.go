@@ -0,0 +1,512 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/google/uuid"
+)
+
+// maxBurndownDays bounds the days of a burndown
+const maxBurndownDays = 366
+
+// Common errors
+var (
+	ErrSprintNotStarted = errors.New("sprint has not started")
+)
+
+// Service provides reports on the progress of projects, derived from the
+// status and sprint changes recorded in the history of their tasks. Tasks
+// are completed when they enter a closed state of their project's workflow,
+// and started when they enter an in progress state. Days and weeks are in
+// UTC.
+//
+// Reports are cached until a task, sprint or the workflow of their project
+// changes.
+type Service interface {
+	// Burndown retrieves the burndown of a sprint that has started
+	Burndown(ctx context.Context, sprintID uuid.UUID) (*models.Burndown, error)
+
+	// Velocity retrieves the work completed in the last closed sprints of
+	// a project
+	Velocity(ctx context.Context, projectID uuid.UUID, sprints int) (*models.Velocity, error)
+
+	// CycleTime retrieves the lead, cycle and transition times of the tasks
+	// of a project completed in the last weeks, including the current week
+	CycleTime(ctx context.Context, projectID uuid.UUID, weeks int) (*models.CycleTime, error)
+
+	// Throughput retrieves the tasks of a project completed in each of the
+	// last weeks, including the current week. Tasks that have since been
+	// reopened are not counted.
+	Throughput(ctx context.Context, projectID uuid.UUID, weeks int) (*models.Throughput, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	analyticsRepo repository.AnalyticsRepository
+	sprintRepo    repository.SprintRepository
+	projectRepo   repository.ProjectRepository
+	workflowSvc   workflow.Service
+	cache         *cache
+}
+
+// NewService creates a new analytics service
+func NewService(
+	analyticsRepo repository.AnalyticsRepository,
+	sprintRepo repository.SprintRepository,
+	projectRepo repository.ProjectRepository,
+	workflowSvc workflow.Service,
+) Service {
+	return &serviceImpl{
+		analyticsRepo: analyticsRepo,
+		sprintRepo:    sprintRepo,
+		projectRepo:   projectRepo,
+		workflowSvc:   workflowSvc,
+		cache:         newCache(cacheSize),
+	}
+}
+
+// Burndown retrieves the burndown of a sprint
+func (s *serviceImpl) Burndown(ctx context.Context, sprintID uuid.UUID) (*models.Burndown, error) {
+	sprint, err := s.sprintRepo.GetByID(ctx, sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sprint.StartedAt == nil {
+		return nil, ErrSprintNotStarted
+	}
+
+	today := day(time.Now())
+	key := fmt.Sprintf("burndown:%s:%s", sprintID, today.Format(models.AnalyticsDateFormat))
+	report, err := s.cached(ctx, sprint.ProjectID, key, func() (interface{}, error) {
+		return s.burndown(ctx, sprint, today)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.(*models.Burndown), nil
+}
+
+// burndown computes the burndown of a sprint up to today
+func (s *serviceImpl) burndown(ctx context.Context, sprint *models.Sprint, today time.Time) (*models.Burndown, error) {
+	categories, err := s.categories(ctx, sprint.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines, err := s.timelines(ctx, models.TaskTimelineFilter{SprintIDs: []uuid.UUID{sprint.ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	// The progress of the sprint just before a time
+	sprintID := sprint.ID.String()
+	progress := func(at time.Time) (point models.BurndownDay) {
+		for _, t := range timelines {
+			if !t.inSprint(sprintID, at) {
+				continue
+			}
+			point.ScopeTasks++
+			point.ScopeHours += t.hours()
+			if _, ok := t.completedAt(at, categories); ok {
+				point.CompletedTasks++
+				point.CompletedHours += t.hours()
+			}
+		}
+		point.RemainingTasks = point.ScopeTasks - point.CompletedTasks
+		point.RemainingHours = round(point.ScopeHours - point.CompletedHours)
+		point.ScopeHours = round(point.ScopeHours)
+		point.CompletedHours = round(point.CompletedHours)
+		return point
+	}
+
+	// Days run from the start of the sprint to today, or the day it was
+	// closed. It is planned to end on its end date, or else on the last day.
+	first := day(*sprint.StartedAt)
+	last := today
+	if sprint.ClosedAt != nil {
+		last = day(*sprint.ClosedAt)
+	}
+	if last.Before(first) {
+		last = first
+	}
+	if last.After(first.AddDate(0, 0, maxBurndownDays-1)) {
+		last = first.AddDate(0, 0, maxBurndownDays-1)
+	}
+	end := last
+	if sprint.EndDate != nil && !day(*sprint.EndDate).Before(first) {
+		end = day(*sprint.EndDate)
+	}
+	plannedDays := float64(days(first, end) + 1)
+
+	committed := progress(*sprint.StartedAt)
+	burndown := &models.Burndown{
+		SprintID:       sprint.ID,
+		StartedAt:      *sprint.StartedAt,
+		ClosedAt:       sprint.ClosedAt,
+		EndDate:        end.Format(models.AnalyticsDateFormat),
+		CommittedTasks: committed.ScopeTasks,
+		CommittedHours: committed.ScopeHours,
+		Days:           []models.BurndownDay{},
+	}
+
+	for i, d := 0, first; !d.After(last); i, d = i+1, d.AddDate(0, 0, 1) {
+		// A closed sprint ends when it was closed, before its unfinished
+		// tasks were carried over
+		at := d.AddDate(0, 0, 1)
+		if sprint.ClosedAt != nil && sprint.ClosedAt.Before(at) {
+			at = *sprint.ClosedAt
+		}
+
+		point := progress(at)
+		point.Date = d.Format(models.AnalyticsDateFormat)
+
+		// The ideal burndown falls evenly to zero at the end of the last
+		// planned day
+		ideal := 1 - float64(i+1)/plannedDays
+		if ideal < 0 {
+			ideal = 0
+		}
+		point.IdealTasks = round(float64(burndown.CommittedTasks) * ideal)
+		point.IdealHours = round(burndown.CommittedHours * ideal)
+
+		burndown.Days = append(burndown.Days, point)
+	}
+
+	return burndown, nil
+}
+
+// Velocity retrieves the work completed in the last closed sprints of a
+// project
+func (s *serviceImpl) Velocity(ctx context.Context, projectID uuid.UUID, sprints int) (*models.Velocity, error) {
+	// Check if project exists
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("velocity:%s:%d", projectID, sprints)
+	report, err := s.cached(ctx, projectID, key, func() (interface{}, error) {
+		return s.velocity(ctx, projectID, sprints)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.(*models.Velocity), nil
+}
+
+// velocity computes the velocity of a project over its last closed sprints
+func (s *serviceImpl) velocity(ctx context.Context, projectID uuid.UUID, n int) (*models.Velocity, error) {
+	velocity := &models.Velocity{
+		ProjectID: projectID,
+		Sprints:   []models.SprintVelocity{},
+	}
+
+	// The last n closed sprints, oldest first
+	closed := models.SprintStateClosed
+	all, err := s.sprintRepo.List(ctx, projectID, &closed)
+	if err != nil {
+		return nil, err
+	}
+	sprints := []models.Sprint{}
+	for _, sprint := range all {
+		if sprint.StartedAt != nil && sprint.ClosedAt != nil {
+			sprints = append(sprints, sprint)
+		}
+	}
+	sort.SliceStable(sprints, func(i, j int) bool { return sprints[i].ClosedAt.After(*sprints[j].ClosedAt) })
+	if len(sprints) > n {
+		sprints = sprints[:n]
+	}
+	if len(sprints) == 0 {
+		return velocity, nil
+	}
+	sort.SliceStable(sprints, func(i, j int) bool { return sprints[i].ClosedAt.Before(*sprints[j].ClosedAt) })
+
+	categories, err := s.categories(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(sprints))
+	for _, sprint := range sprints {
+		ids = append(ids, sprint.ID)
+	}
+	timelines, err := s.timelines(ctx, models.TaskTimelineFilter{SprintIDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sprint := range sprints {
+		v := models.SprintVelocity{
+			SprintID:  sprint.ID,
+			Name:      sprint.Name,
+			StartedAt: *sprint.StartedAt,
+			ClosedAt:  *sprint.ClosedAt,
+		}
+
+		// Committed when the sprint started, and completed when it was
+		// closed, before its unfinished tasks were carried over
+		sprintID := sprint.ID.String()
+		for _, t := range timelines {
+			if t.inSprint(sprintID, *sprint.StartedAt) {
+				v.CommittedTasks++
+				v.CommittedHours += t.hours()
+			}
+			if t.inSprint(sprintID, *sprint.ClosedAt) {
+				if _, ok := t.completedAt(*sprint.ClosedAt, categories); ok {
+					v.CompletedTasks++
+					v.CompletedHours += t.hours()
+				}
+			}
+		}
+		v.CommittedHours = round(v.CommittedHours)
+		v.CompletedHours = round(v.CompletedHours)
+
+		velocity.AverageCompletedTasks += float64(v.CompletedTasks)
+		velocity.AverageCompletedHours += v.CompletedHours
+		velocity.Sprints = append(velocity.Sprints, v)
+	}
+
+	velocity.AverageCompletedTasks = round(velocity.AverageCompletedTasks / float64(len(sprints)))
+	velocity.AverageCompletedHours = round(velocity.AverageCompletedHours / float64(len(sprints)))
+
+	return velocity, nil
+}
+
+// CycleTime retrieves the lead, cycle and transition times of the tasks of a
+// project completed in the last weeks
+func (s *serviceImpl) CycleTime(ctx context.Context, projectID uuid.UUID, weeks int) (*models.CycleTime, error) {
+	// Check if project exists
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	today := day(time.Now())
+	key := fmt.Sprintf("cycle-time:%s:%d:%s", projectID, weeks, today.Format(models.AnalyticsDateFormat))
+	report, err := s.cached(ctx, projectID, key, func() (interface{}, error) {
+		return s.cycleTime(ctx, projectID, weeks, today)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.(*models.CycleTime), nil
+}
+
+// cycleTime computes the cycle times of a project over the last weeks up to
+// today
+func (s *serviceImpl) cycleTime(ctx context.Context, projectID uuid.UUID, weeks int, today time.Time) (*models.CycleTime, error) {
+	from, to := period(today, weeks)
+
+	categories, err := s.categories(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines, err := s.timelines(ctx, models.TaskTimelineFilter{ProjectID: &projectID, UpdatedSince: &from})
+	if err != nil {
+		return nil, err
+	}
+
+	type transition struct {
+		from, to models.TaskStatus
+	}
+	var leadTimes, cycleTimes []time.Duration
+	transitions := make(map[transition][]time.Duration)
+
+	for _, t := range timelines {
+		if completedAt, ok := t.completedAt(to, categories); ok && !completedAt.Before(from) {
+			leadTimes = append(leadTimes, completedAt.Sub(t.task.CreatedAt))
+			if startedAt, ok := t.startedAt(completedAt, categories); ok {
+				cycleTimes = append(cycleTimes, completedAt.Sub(startedAt))
+			}
+		}
+
+		// The time spent in a status, for each transition in the period
+		for i := 1; i < len(t.statuses); i++ {
+			prev, next := t.statuses[i-1], t.statuses[i]
+			if next.from.Before(from) || !next.from.Before(to) {
+				continue
+			}
+			key := transition{from: models.TaskStatus(prev.value), to: models.TaskStatus(next.value)}
+			transitions[key] = append(transitions[key], next.from.Sub(prev.from))
+		}
+	}
+
+	cycleTime := &models.CycleTime{
+		ProjectID:   projectID,
+		From:        from.Format(models.AnalyticsDateFormat),
+		To:          to.AddDate(0, 0, -1).Format(models.AnalyticsDateFormat),
+		LeadTime:    distribution(leadTimes),
+		CycleTime:   distribution(cycleTimes),
+		Transitions: make([]models.TransitionTime, 0, len(transitions)),
+	}
+	for key, durations := range transitions {
+		cycleTime.Transitions = append(cycleTime.Transitions, models.TransitionTime{
+			From: key.from,
+			To:   key.to,
+			Time: distribution(durations),
+		})
+	}
+
+	// Transitions in the order of the workflow's states
+	position := func(status models.TaskStatus) int {
+		if state, ok := categories.workflow.State(status); ok {
+			return state.Position
+		}
+		return len(categories.workflow.States)
+	}
+	sort.Slice(cycleTime.Transitions, func(i, j int) bool {
+		a, b := cycleTime.Transitions[i], cycleTime.Transitions[j]
+		if position(a.From) != position(b.From) {
+			return position(a.From) < position(b.From)
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if position(a.To) != position(b.To) {
+			return position(a.To) < position(b.To)
+		}
+		return a.To < b.To
+	})
+
+	return cycleTime, nil
+}
+
+// Throughput retrieves the tasks of a project completed in each of the last
+// weeks
+func (s *serviceImpl) Throughput(ctx context.Context, projectID uuid.UUID, weeks int) (*models.Throughput, error) {
+	// Check if project exists
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	today := day(time.Now())
+	key := fmt.Sprintf("throughput:%s:%d:%s", projectID, weeks, today.Format(models.AnalyticsDateFormat))
+	report, err := s.cached(ctx, projectID, key, func() (interface{}, error) {
+		return s.throughput(ctx, projectID, weeks, today)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.(*models.Throughput), nil
+}
+
+// throughput computes the throughput of a project over the last weeks up to
+// today
+func (s *serviceImpl) throughput(ctx context.Context, projectID uuid.UUID, weeks int, today time.Time) (*models.Throughput, error) {
+	from, to := period(today, weeks)
+
+	categories, err := s.categories(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines, err := s.timelines(ctx, models.TaskTimelineFilter{ProjectID: &projectID, UpdatedSince: &from})
+	if err != nil {
+		return nil, err
+	}
+
+	throughput := &models.Throughput{
+		ProjectID: projectID,
+		Weeks:     make([]models.ThroughputWeek, weeks),
+	}
+	for i := range throughput.Weeks {
+		throughput.Weeks[i].WeekStart = from.AddDate(0, 0, 7*i).Format(models.AnalyticsDateFormat)
+	}
+
+	for _, t := range timelines {
+		completedAt, ok := t.completedAt(to, categories)
+		if !ok || completedAt.Before(from) {
+			continue
+		}
+		week := &throughput.Weeks[days(from, completedAt)/7]
+		week.CompletedTasks++
+		week.CompletedHours += t.hours()
+	}
+	for i := range throughput.Weeks {
+		throughput.Weeks[i].CompletedHours = round(throughput.Weeks[i].CompletedHours)
+	}
+
+	return throughput, nil
+}
+
+// cached returns the report stored for key if the data of the project has
+// not changed since it was computed, or computes and stores it
+func (s *serviceImpl) cached(ctx context.Context, projectID uuid.UUID, key string, compute func() (interface{}, error)) (interface{}, error) {
+	stamp, err := s.analyticsRepo.Stamp(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report, ok := s.cache.get(key, stamp); ok {
+		return report, nil
+	}
+
+	report, err := compute()
+	if err != nil {
+		return nil, err
+	}
+
+	// A change made while computing gives a new stamp, so a report that
+	// includes it is computed again on the next request
+	s.cache.set(key, stamp, report)
+
+	return report, nil
+}
+
+// categories retrieves the categories of the states of a project's workflow
+func (s *serviceImpl) categories(ctx context.Context, projectID uuid.UUID) (*categories, error) {
+	workflow, err := s.workflowSvc.Get(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return &categories{workflow: workflow}, nil
+}
+
+// timelines retrieves the timelines of the tasks matching a filter
+func (s *serviceImpl) timelines(ctx context.Context, filter models.TaskTimelineFilter) ([]*timeline, error) {
+	tasks, err := s.analyticsRepo.ListTimelines(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines := make([]*timeline, 0, len(tasks))
+	for i := range tasks {
+		timelines = append(timelines, newTimeline(&tasks[i]))
+	}
+	return timelines, nil
+}
+
+// period returns the start of the week weeks-1 weeks before today, and the
+// end of today
+func period(today time.Time, weeks int) (time.Time, time.Time) {
+	return weekStart(today).AddDate(0, 0, -7*(weeks-1)), today.AddDate(0, 0, 1)
+}
+
+// day returns the start of the UTC day of t
+func day(t time.Time) time.Time {
+	year, month, d := t.UTC().Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart returns the start of the week of t, on Monday
+func weekStart(t time.Time) time.Time {
+	d := day(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+// days returns the number of whole days from the start of day from to t
+func days(from, t time.Time) int {
+	return int(t.Sub(from).Hours() / 24)
+}
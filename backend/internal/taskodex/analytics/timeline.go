@@ -0,0 +1,190 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+)
+
+// segment is a value a task held from a time until the next segment
+type segment struct {
+	value string
+	from  time.Time
+}
+
+// timeline replays the status and sprint changes of a task. Values are read
+// as they were just before a time, so changes made at that very time, such
+// as the tasks carried over when a sprint is closed, are not included.
+type timeline struct {
+	task     *models.TaskTimeline
+	statuses []segment
+	sprints  []segment
+}
+
+// newTimeline builds the timeline of a task. A task holds the old value of
+// its first change from when it was created, or its current value if it never
+// changed.
+func newTimeline(task *models.TaskTimeline) *timeline {
+	sprintID := ""
+	if task.SprintID != nil {
+		sprintID = task.SprintID.String()
+	}
+
+	t := &timeline{task: task}
+	for _, change := range task.Changes {
+		switch *change.FieldName {
+		case models.TaskFieldStatus:
+			t.statuses = appendSegment(t.statuses, task.CreatedAt, change)
+		case models.TaskFieldSprintID:
+			t.sprints = appendSegment(t.sprints, task.CreatedAt, change)
+		}
+	}
+	if len(t.statuses) == 0 {
+		t.statuses = []segment{{value: string(task.Status), from: task.CreatedAt}}
+	}
+	if len(t.sprints) == 0 {
+		t.sprints = []segment{{value: sprintID, from: task.CreatedAt}}
+	}
+
+	return t
+}
+
+// appendSegment appends the segment a change starts, after the segment of
+// the value before it if it is the first change
+func appendSegment(segments []segment, createdAt time.Time, change models.TaskHistory) []segment {
+	if len(segments) == 0 {
+		segments = append(segments, segment{value: valueOf(change.OldValue), from: createdAt})
+	}
+	return append(segments, segment{value: valueOf(change.NewValue), from: change.CreatedAt})
+}
+
+// valueOf returns a value recorded in history, or "" if it was unset
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// before returns the value of segments just before t, and false if the task
+// did not exist yet
+func before(segments []segment, t time.Time) (string, bool) {
+	value, ok := "", false
+	for _, segment := range segments {
+		if !segment.from.Before(t) {
+			break
+		}
+		value, ok = segment.value, true
+	}
+	return value, ok
+}
+
+// inSprint reports whether the task was in a sprint just before t
+func (t *timeline) inSprint(sprintID string, at time.Time) bool {
+	value, ok := before(t.sprints, at)
+	return ok && value == sprintID
+}
+
+// statusBefore returns the status of the task just before t
+func (t *timeline) statusBefore(at time.Time) (models.TaskStatus, bool) {
+	value, ok := before(t.statuses, at)
+	return models.TaskStatus(value), ok
+}
+
+// completedAt returns when the task was completed, if it was done just before
+// t: when it last entered a closed state, having stayed closed since
+func (t *timeline) completedAt(at time.Time, categories *categories) (time.Time, bool) {
+	var since time.Time
+	closed := false
+	for _, segment := range t.statuses {
+		if !segment.from.Before(at) {
+			break
+		}
+		isClosed := categories.closed(models.TaskStatus(segment.value))
+		if isClosed && !closed {
+			since = segment.from
+		}
+		closed = isClosed
+	}
+	return since, closed
+}
+
+// startedAt returns when work on the task first started, entering a state of
+// the in progress category, if it did before t
+func (t *timeline) startedAt(at time.Time, categories *categories) (time.Time, bool) {
+	for _, segment := range t.statuses {
+		if !segment.from.Before(at) {
+			break
+		}
+		if categories.inProgress(models.TaskStatus(segment.value)) {
+			return segment.from, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// hours returns the estimated hours of the task
+func (t *timeline) hours() float64 {
+	if t.task.EstimatedHours == nil {
+		return 0
+	}
+	return *t.task.EstimatedHours
+}
+
+// categories classifies statuses by the category of their state in a
+// workflow. Statuses the workflow does not have are open.
+type categories struct {
+	workflow *models.Workflow
+}
+
+// closed reports whether a status is in the closed category
+func (c *categories) closed(status models.TaskStatus) bool {
+	return c.workflow.IsClosed(status)
+}
+
+// inProgress reports whether a status is in the in progress category
+func (c *categories) inProgress(status models.TaskStatus) bool {
+	state, ok := c.workflow.State(status)
+	return ok && state.Category == models.WorkflowStateCategoryInProgress
+}
+
+// distribution summarizes durations in hours
+func distribution(durations []time.Duration) models.Distribution {
+	if len(durations) == 0 {
+		return models.Distribution{}
+	}
+
+	hours := make([]float64, 0, len(durations))
+	sum := 0.0
+	for _, d := range durations {
+		hours = append(hours, d.Hours())
+		sum += d.Hours()
+	}
+	sort.Float64s(hours)
+
+	return models.Distribution{
+		Count:  len(hours),
+		Mean:   round(sum / float64(len(hours))),
+		Median: round(percentile(hours, 50)),
+		P85:    round(percentile(hours, 85)),
+		P95:    round(percentile(hours, 95)),
+		Min:    round(hours[0]),
+		Max:    round(hours[len(hours)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// round rounds hours to two decimals
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,280 @@
+package billing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/billing"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBilling(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test users, organization, project and task
+	ann := tdb.CreateTestUser(t, prefix)
+	bob := tdb.CreateTestUser(t, prefix+"2")
+	testOrg := tdb.CreateTestOrganization(t, prefix, ann.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, ann.ID)
+	testTask := tdb.CreateTestTask(t, prefix, testProject.ID, ann.ID)
+
+	// Make Ann a member of the organization
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+	memberRole := &models.Role{
+		ID:        uuid.New(),
+		Name:      prefix + " member",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, roleRepo.CreateRole(ctx, memberRole))
+	require.NoError(t, roleRepo.AssignRoleToUser(ctx, &models.UserRole{
+		UserID:         ann.ID,
+		RoleID:         memberRole.ID,
+		OrganizationID: testOrg.ID,
+	}))
+
+	// Create services and handlers
+	billingService := billing.NewService(
+		repository.NewPostgresBillingRepository(tdb.DB),
+		repository.NewPostgresProjectRepository(tdb.DB),
+		repository.NewTxManager(tdb.DB),
+	)
+	billingHandlers := billing.NewHandlers(billingService)
+	timeEntryService := timeentry.NewService(
+		repository.NewPostgresTimeEntryRepository(tdb.DB),
+		repository.NewPostgresTaskRepository(tdb.DB),
+		repository.NewPostgresUserRepository(tdb.DB),
+		repository.NewPostgresTimesheetRepository(tdb.DB),
+		repository.NewPostgresTimeEntryPolicyRepository(tdb.DB),
+		notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), repository.NewPostgresUserRepository(tdb.DB)),
+		repository.NewTxManager(tdb.DB),
+	)
+	e := echo.New()
+
+	day := func(value string) time.Time {
+		parsed, err := time.Parse(models.AnalyticsDateFormat, value)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	t.Run("DefaultSettings", func(t *testing.T) {
+		settings, err := billingService.GetSettings(ctx, testOrg.ID, testProject.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DefaultBillingCurrency, settings.Currency)
+		assert.Equal(t, 0, settings.RoundingMinutes)
+		assert.Equal(t, 61, settings.Round(61))
+	})
+
+	t.Run("UpdateSettings", func(t *testing.T) {
+		body, _ := json.Marshal(models.BillingSettingsRequest{
+			ClientName:      "Acme",
+			Currency:        "EUR",
+			RoundingMinutes: 15,
+			RoundingMode:    models.BillingRoundingUp,
+		})
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+		c.Set("user_id", ann.ID.String())
+
+		require.NoError(t, billingHandlers.UpdateSettings(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		settings, err := billingService.GetSettings(ctx, testOrg.ID, testProject.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", settings.ClientName)
+		assert.Equal(t, "EUR", settings.Currency)
+		assert.Equal(t, 30, settings.Round(16))
+	})
+
+	t.Run("RateCard", func(t *testing.T) {
+		endOfRate := day("2024-03-10")
+		rates := []models.BillingRateRequest{
+			{HourlyRate: 100, EffectiveFrom: day("2024-01-01")},
+			{UserID: &ann.ID, HourlyRate: 150, EffectiveFrom: day("2024-01-01"), EffectiveTo: &endOfRate},
+			{UserID: &ann.ID, HourlyRate: 175, EffectiveFrom: day("2024-03-11")},
+		}
+		for _, req := range rates {
+			_, err := billingService.CreateRate(ctx, testOrg.ID, testProject.ID, req, ann.ID)
+			require.NoError(t, err)
+		}
+
+		// Rates for the same members cannot overlap
+		_, err := billingService.CreateRate(ctx, testOrg.ID, testProject.ID, models.BillingRateRequest{
+			UserID:        &ann.ID,
+			HourlyRate:    160,
+			EffectiveFrom: day("2024-03-01"),
+		}, ann.ID)
+		assert.ErrorIs(t, err, billing.ErrRateOverlap)
+
+		// A rate is for a user or a role
+		roleID := uuid.New()
+		_, err = billingService.CreateRate(ctx, testOrg.ID, testProject.ID, models.BillingRateRequest{
+			UserID:        &ann.ID,
+			RoleID:        &roleID,
+			HourlyRate:    160,
+			EffectiveFrom: day("2025-01-01"),
+		}, ann.ID)
+		assert.ErrorIs(t, err, billing.ErrRateScope)
+
+		card, err := billingService.ListRates(ctx, testOrg.ID, testProject.ID)
+		require.NoError(t, err)
+		assert.Len(t, card, 3)
+	})
+
+	t.Run("RateScope", func(t *testing.T) {
+		// Rates are for the members and roles of the organization
+		_, err := billingService.CreateRate(ctx, testOrg.ID, testProject.ID, models.BillingRateRequest{
+			UserID:        &bob.ID,
+			HourlyRate:    120,
+			EffectiveFrom: day("2024-01-01"),
+		}, ann.ID)
+		assert.ErrorIs(t, err, repository.ErrBillingRateScope)
+
+		roleID := uuid.New()
+		_, err = billingService.CreateRate(ctx, testOrg.ID, testProject.ID, models.BillingRateRequest{
+			RoleID:        &roleID,
+			HourlyRate:    120,
+			EffectiveFrom: day("2024-01-01"),
+		}, ann.ID)
+		assert.ErrorIs(t, err, repository.ErrBillingRateScope)
+
+		// Projects of other organizations are not found
+		_, err = billingService.CreateRate(ctx, uuid.New(), testProject.ID, models.BillingRateRequest{
+			HourlyRate:    120,
+			EffectiveFrom: day("2025-01-01"),
+		}, ann.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+
+		_, err = billingService.UpdateSettings(ctx, uuid.New(), testProject.ID, models.BillingSettingsRequest{Currency: "USD"}, ann.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+
+		card, err := billingService.ListRates(ctx, testOrg.ID, testProject.ID)
+		require.NoError(t, err)
+		assert.Len(t, card, 3)
+	})
+
+	// Ann logs time before and after her rate changes; Bob is billed at the
+	// project's default rate and logs some time that is not billable
+	notBillable := false
+	logTime := func(userID uuid.UUID, start string, minutes int, billable *bool) {
+		_, err := timeEntryService.Create(ctx, models.TimeEntryRequest{
+			TaskID:          testTask.ID,
+			StartTime:       day(start).Add(9 * time.Hour),
+			DurationMinutes: &minutes,
+			Billable:        billable,
+		}, userID)
+		require.NoError(t, err)
+	}
+	logTime(ann.ID, "2024-03-08", 50, nil)
+	logTime(ann.ID, "2024-03-12", 60, nil)
+	logTime(bob.ID, "2024-03-12", 20, nil)
+	logTime(bob.ID, "2024-03-13", 30, &notBillable)
+
+	t.Run("Report", func(t *testing.T) {
+		report, err := billingService.Report(ctx, models.BillingReportParams{
+			OrganizationID: testOrg.ID,
+			From:           day("2024-03-01"),
+			To:             day("2024-03-31"),
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Projects, 1)
+
+		project := report.Projects[0]
+		assert.Equal(t, "Acme", project.ClientName)
+		assert.Equal(t, "EUR", project.Currency)
+		assert.Equal(t, 130, project.BillableMinutes)
+		assert.Equal(t, 30, project.NonBillableMinutes)
+		assert.Equal(t, 150, project.BilledMinutes)
+		assert.Equal(t, 0, project.UnratedMinutes)
+		assert.Equal(t, 375.0, project.Amount)
+
+		// 50 minutes round up to an hour at 150, an hour at 175, and 20
+		// minutes round up to half an hour at 100
+		amounts := map[float64]float64{}
+		for _, line := range project.Lines {
+			amounts[line.HourlyRate] = line.Amount
+		}
+		assert.Equal(t, map[float64]float64{150: 150, 175: 175, 100: 50}, amounts)
+
+		require.Len(t, report.Totals, 1)
+		assert.Equal(t, models.BillingTotal{Currency: "EUR", BilledMinutes: 150, Amount: 375}, report.Totals[0])
+	})
+
+	t.Run("ReportPeriod", func(t *testing.T) {
+		report, err := billingService.Report(ctx, models.BillingReportParams{
+			OrganizationID: testOrg.ID,
+			ProjectID:      &testProject.ID,
+			From:           day("2024-03-12"),
+			To:             day("2024-03-12"),
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Projects, 1)
+		assert.Equal(t, 225.0, report.Projects[0].Amount)
+
+		_, err = billingService.Report(ctx, models.BillingReportParams{
+			OrganizationID: testOrg.ID,
+			From:           day("2024-03-12"),
+			To:             day("2024-03-11"),
+		})
+		assert.ErrorIs(t, err, billing.ErrInvalidPeriod)
+
+		_, err = billingService.Report(ctx, models.BillingReportParams{
+			OrganizationID: uuid.New(),
+			ProjectID:      &testProject.ID,
+			From:           day("2024-03-01"),
+			To:             day("2024-03-31"),
+		})
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+	})
+
+	report := func(format string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/?from=2024-03-01&to=2024-03-31&format="+format, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("org_id")
+		c.SetParamValues(testOrg.ID.String())
+		require.NoError(t, billingHandlers.Report(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	t.Run("ExportCSV", func(t *testing.T) {
+		rec := report("csv")
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "billing-2024-03-01-2024-03-31.csv")
+
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, "amount", rows[0][len(rows[0])-1])
+	})
+
+	t.Run("ExportInvoice", func(t *testing.T) {
+		rec := report("html")
+		assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML))
+		assert.Contains(t, rec.Body.String(), "Client: Acme")
+		assert.Contains(t, rec.Body.String(), "EUR 375.00")
+	})
+}
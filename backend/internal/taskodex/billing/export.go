@@ -0,0 +1,115 @@
+package billing
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+)
+
+// writeReportCSV writes a billing report as CSV, a row per line
+func writeReportCSV(w io.Writer, report *models.BillingReport) error {
+	rows := [][]string{{
+		"from", "to", "project_id", "project", "client", "currency", "user_id", "user",
+		"hourly_rate", "entries", "minutes", "billed_minutes", "billed_hours", "amount",
+	}}
+	for _, project := range report.Projects {
+		for _, line := range project.Lines {
+			// Lines without a rate have no hourly rate
+			rate := ""
+			if line.RateID != nil {
+				rate = formatAmount(line.HourlyRate)
+			}
+			rows = append(rows, []string{
+				report.From,
+				report.To,
+				project.ProjectID.String(),
+				project.ProjectName,
+				project.ClientName,
+				project.Currency,
+				line.UserID.String(),
+				line.UserName,
+				rate,
+				strconv.Itoa(line.Entries),
+				strconv.Itoa(line.Minutes),
+				strconv.Itoa(line.BilledMinutes),
+				formatAmount(line.Hours),
+				formatAmount(line.Amount),
+			})
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// formatAmount renders an amount with two decimals
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// invoiceTemplate renders a billing report as a printable invoice, a page
+// per project
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": formatAmount,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.From}} to {{.To}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 2em; }
+section { page-break-after: always; margin-bottom: 3em; }
+section:last-of-type { page-break-after: auto; }
+h1 { font-size: 1.6em; margin: 0 0 .2em; }
+.meta { color: #555; margin-bottom: 1.5em; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: .4em .6em; border-bottom: 1px solid #ddd; text-align: left; }
+th.number, td.number { text-align: right; }
+tfoot td { font-weight: bold; border-bottom: none; }
+.note { color: #a33; margin-top: 1em; }
+</style>
+</head>
+<body>
+{{- $report := . }}
+{{- range .Projects}}
+<section>
+<h1>Invoice</h1>
+<div class="meta">
+{{- if .ClientName}}<div>Client: {{.ClientName}}</div>{{end}}
+<div>Project: {{.ProjectName}}</div>
+<div>Period: {{$report.From}} to {{$report.To}}</div>
+</div>
+<table>
+<thead>
+<tr><th>Member</th><th class="number">Hours</th><th class="number">Rate ({{.Currency}}/h)</th><th class="number">Amount ({{.Currency}})</th></tr>
+</thead>
+<tbody>
+{{- range .Lines}}
+<tr><td>{{.UserName}}</td><td class="number">{{amount .Hours}}</td><td class="number">{{if .RateID}}{{amount .HourlyRate}}{{else}}-{{end}}</td><td class="number">{{amount .Amount}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><td>Total</td><td></td><td></td><td class="number">{{.Currency}} {{amount .Amount}}</td></tr>
+</tfoot>
+</table>
+{{- if .UnratedMinutes}}
+<p class="note">{{.UnratedMinutes}} billed minutes have no rate and are not charged.</p>
+{{- end}}
+</section>
+{{- else}}
+<p>No billable time from {{.From}} to {{.To}}.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeInvoiceHTML writes a billing report as a printable HTML invoice
+func writeInvoiceHTML(w io.Writer, report *models.BillingReport) error {
+	return invoiceTemplate.Execute(w, report)
+}
@@ -0,0 +1,309 @@
+package billing
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for billing
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetSettings handles retrieving the billing settings of a project
+func (h *Handlers) GetSettings(c echo.Context) error {
+	// Get organization and project IDs
+	orgID, projectID, err := parseProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Get settings
+	settings, err := h.service.GetSettings(c.Request().Context(), orgID, projectID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve billing settings")
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles updating the billing settings of a project
+func (h *Handlers) UpdateSettings(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization and project IDs
+	orgID, projectID, err := parseProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.BillingSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update settings
+	settings, err := h.service.UpdateSettings(c.Request().Context(), orgID, projectID, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to update billing settings")
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// ListRates handles retrieving the rate card of a project
+func (h *Handlers) ListRates(c echo.Context) error {
+	// Get organization and project IDs
+	orgID, projectID, err := parseProject(c)
+	if err != nil {
+		return err
+	}
+
+	// List rates
+	rates, err := h.service.ListRates(c.Request().Context(), orgID, projectID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve billing rates")
+	}
+
+	return c.JSON(http.StatusOK, rates)
+}
+
+// CreateRate handles adding a rate to the rate card of a project
+func (h *Handlers) CreateRate(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization and project IDs
+	orgID, projectID, err := parseProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.BillingRateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create rate
+	rate, err := h.service.CreateRate(c.Request().Context(), orgID, projectID, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to create billing rate")
+	}
+
+	return c.JSON(http.StatusCreated, rate)
+}
+
+// UpdateRate handles updating a rate of a project
+func (h *Handlers) UpdateRate(c echo.Context) error {
+	// Get organization, project and rate IDs
+	orgID, projectID, rateID, err := parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.BillingRateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update rate
+	rate, err := h.service.UpdateRate(c.Request().Context(), orgID, projectID, rateID, req)
+	if err != nil {
+		return h.handleError(err, "Failed to update billing rate")
+	}
+
+	return c.JSON(http.StatusOK, rate)
+}
+
+// DeleteRate handles deleting a rate of a project
+func (h *Handlers) DeleteRate(c echo.Context) error {
+	// Get organization, project and rate IDs
+	orgID, projectID, rateID, err := parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Delete rate
+	if err := h.service.DeleteRate(c.Request().Context(), orgID, projectID, rateID); err != nil {
+		return h.handleError(err, "Failed to delete billing rate")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Report handles computing the amounts to bill over a period, as JSON, CSV
+// or a printable HTML invoice
+func (h *Handlers) Report(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	params := models.BillingReportParams{OrganizationID: orgID}
+
+	// Parse from and to parameters
+	params.From, err = time.Parse(models.AnalyticsDateFormat, c.QueryParam("from"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid from parameter")
+	}
+	params.To, err = time.Parse(models.AnalyticsDateFormat, c.QueryParam("to"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid to parameter")
+	}
+
+	// Parse project_id parameter
+	projectIDParam := c.QueryParam("project_id")
+	if projectIDParam != "" {
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid project_id parameter")
+		}
+		params.ProjectID = &projectID
+	}
+
+	// Parse format parameter
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" && format != "html" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid format parameter")
+	}
+
+	// Compute report
+	report, err := h.service.Report(c.Request().Context(), params)
+	if err != nil {
+		return h.handleError(err, "Failed to compute billing report")
+	}
+
+	filename := "billing-" + report.From + "-" + report.To
+	switch format {
+	case "csv":
+		return respond(c, filename+".csv", "text/csv", func(w io.Writer) error {
+			return writeReportCSV(w, report)
+		})
+	case "html":
+		return respond(c, "", echo.MIMETextHTMLCharsetUTF8, func(w io.Writer) error {
+			return writeInvoiceHTML(w, report)
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// respond responds with a report written in a content type, as an
+// attachment when it has a filename
+func respond(c echo.Context, filename, contentType string, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to write report")
+	}
+
+	if filename != "" {
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+filename)
+	}
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+// parseProject parses the organization and project IDs from the path
+// parameters
+func parseProject(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	return orgID, projectID, nil
+}
+
+// parseIDs parses the organization, project and rate IDs from the path
+// parameters
+func parseIDs(c echo.Context) (uuid.UUID, uuid.UUID, uuid.UUID, error) {
+	orgID, projectID, err := parseProject(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+
+	rateID, err := uuid.Parse(c.Param("rate_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid rate ID")
+	}
+
+	return orgID, projectID, rateID, nil
+}
+
+// handleError maps errors from billing operations to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	case errors.Is(err, repository.ErrBillingRateNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Billing rate not found")
+	case errors.Is(err, repository.ErrBillingRateScope), errors.Is(err, ErrRateScope), errors.Is(err, ErrRatePeriod), errors.Is(err, ErrInvalidPeriod):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRateOverlap):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the billing routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require project:read permission
+	g.GET("/projects/:id/billing", h.GetSettings, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	g.GET("/projects/:id/billing/rates", h.ListRates, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	g.GET("/billing/report", h.Report, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	g.PUT("/projects/:id/billing", h.UpdateSettings, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	g.POST("/projects/:id/billing/rates", h.CreateRate, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	g.PUT("/projects/:id/billing/rates/:rate_id", h.UpdateRate, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	g.DELETE("/projects/:id/billing/rates/:rate_id", h.DeleteRate, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+}
@@ -0,0 +1,171 @@
+package billing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// rateCard resolves the rates of the members of a project
+type rateCard struct {
+	rates []models.BillingRate
+	roles map[uuid.UUID][]uuid.UUID
+}
+
+// rateFor returns the rate of a member on a UTC day, or nil if none
+// applies. A rate for the member comes first, then the highest rate of
+// their roles, then the project's default rate.
+func (c rateCard) rateFor(userID uuid.UUID, day time.Time) *models.BillingRate {
+	var user, role, fallback *models.BillingRate
+	for i := range c.rates {
+		rate := &c.rates[i]
+		if !rate.AppliesOn(day) {
+			continue
+		}
+
+		switch {
+		case rate.UserID != nil:
+			if *rate.UserID == userID {
+				user = rate
+			}
+		case rate.RoleID != nil:
+			if hasRole(c.roles[userID], *rate.RoleID) && (role == nil || rate.HourlyRate > role.HourlyRate) {
+				role = rate
+			}
+		default:
+			fallback = rate
+		}
+	}
+
+	switch {
+	case user != nil:
+		return user
+	case role != nil:
+		return role
+	}
+	return fallback
+}
+
+// hasRole reports whether roles contains a role
+func hasRole(roles []uuid.UUID, roleID uuid.UUID) bool {
+	for _, id := range roles {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// lineKey identifies the line of a member at a rate
+type lineKey struct {
+	userID uuid.UUID
+	rateID uuid.UUID
+}
+
+// bill computes the amount to bill for the entries of a project
+func bill(entries []models.BillingEntry, settings *models.BillingSettings, card rateCard) models.BillingProject {
+	project := models.BillingProject{
+		ProjectID:   entries[0].ProjectID,
+		ProjectName: entries[0].ProjectName,
+		ClientName:  settings.ClientName,
+		Currency:    settings.Currency,
+		Lines:       []models.BillingLine{},
+	}
+
+	lines := map[lineKey]*models.BillingLine{}
+	for _, entry := range entries {
+		if !entry.Billable {
+			project.NonBillableMinutes += entry.Minutes
+			continue
+		}
+
+		billed := settings.Round(entry.Minutes)
+		project.BillableMinutes += entry.Minutes
+		project.BilledMinutes += billed
+
+		key := lineKey{userID: entry.UserID}
+		rate := card.rateFor(entry.UserID, models.BillingDay(entry.StartTime))
+		if rate != nil {
+			key.rateID = rate.ID
+		} else {
+			project.UnratedMinutes += billed
+		}
+
+		line, ok := lines[key]
+		if !ok {
+			line = &models.BillingLine{UserID: entry.UserID, UserName: entry.UserName}
+			if rate != nil {
+				line.RateID = &rate.ID
+				line.HourlyRate = rate.HourlyRate
+			}
+			lines[key] = line
+		}
+		line.Entries++
+		line.Minutes += entry.Minutes
+		line.BilledMinutes += billed
+	}
+
+	// Amounts are computed in cents, line by line
+	cents := int64(0)
+	for _, line := range lines {
+		lineCents := int64(math.Round(line.HourlyRate * 100 * float64(line.BilledMinutes) / 60))
+		line.Hours = math.Round(float64(line.BilledMinutes)/60*100) / 100
+		line.Amount = float64(lineCents) / 100
+		cents += lineCents
+		project.Lines = append(project.Lines, *line)
+	}
+	project.Amount = float64(cents) / 100
+
+	sort.Slice(project.Lines, func(i, j int) bool {
+		a, b := project.Lines[i], project.Lines[j]
+		if a.UserName != b.UserName {
+			return a.UserName < b.UserName
+		}
+		if a.UserID != b.UserID {
+			return a.UserID.String() < b.UserID.String()
+		}
+		if a.HourlyRate != b.HourlyRate {
+			return a.HourlyRate < b.HourlyRate
+		}
+		return rateKey(a.RateID) < rateKey(b.RateID)
+	})
+
+	return project
+}
+
+// rateKey orders the lines of a member at the same hourly rate
+func rateKey(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// totals sums the amounts of projects by currency
+func totals(projects []models.BillingProject) []models.BillingTotal {
+	byCurrency := map[string]*models.BillingTotal{}
+	cents := map[string]int64{}
+	for _, project := range projects {
+		total, ok := byCurrency[project.Currency]
+		if !ok {
+			total = &models.BillingTotal{Currency: project.Currency}
+			byCurrency[project.Currency] = total
+		}
+		total.BilledMinutes += project.BilledMinutes
+		cents[project.Currency] += int64(math.Round(project.Amount * 100))
+	}
+
+	result := make([]models.BillingTotal, 0, len(byCurrency))
+	for currency, total := range byCurrency {
+		total.Amount = float64(cents[currency]) / 100
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Currency < result[j].Currency
+	})
+
+	return result
+}
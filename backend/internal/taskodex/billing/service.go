@@ -0,0 +1,315 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrRateScope     = errors.New("a rate is for a user or a role, not both")
+	ErrRatePeriod    = errors.New("a rate cannot end before it starts")
+	ErrRateOverlap   = errors.New("the project already has a rate for the same members over part of this period")
+	ErrInvalidPeriod = errors.New("the period of a report cannot end before it starts")
+)
+
+// Service provides billing rates, settings and reports
+type Service interface {
+	// GetSettings retrieves the billing settings of a project of an
+	// organization
+	GetSettings(ctx context.Context, organizationID, projectID uuid.UUID) (*models.BillingSettings, error)
+
+	// UpdateSettings updates the billing settings of a project of an
+	// organization
+	UpdateSettings(ctx context.Context, organizationID, projectID uuid.UUID, req models.BillingSettingsRequest, userID uuid.UUID) (*models.BillingSettings, error)
+
+	// ListRates retrieves the rate card of a project of an organization
+	ListRates(ctx context.Context, organizationID, projectID uuid.UUID) ([]models.BillingRate, error)
+
+	// CreateRate adds a rate to the rate card of a project of an
+	// organization. Rates are for the members and roles of the
+	// organization, and rates for the same members cannot overlap.
+	CreateRate(ctx context.Context, organizationID, projectID uuid.UUID, req models.BillingRateRequest, userID uuid.UUID) (*models.BillingRate, error)
+
+	// UpdateRate updates a rate of a project of an organization
+	UpdateRate(ctx context.Context, organizationID, projectID, id uuid.UUID, req models.BillingRateRequest) (*models.BillingRate, error)
+
+	// DeleteRate deletes a rate of a project of an organization
+	DeleteRate(ctx context.Context, organizationID, projectID, id uuid.UUID) error
+
+	// Report computes the amounts to bill for the time logged on the
+	// projects of an organization over a period. Billable time is rounded
+	// entry by entry, then billed at the rate of its member on the day it
+	// was logged.
+	Report(ctx context.Context, params models.BillingReportParams) (*models.BillingReport, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	billingRepo repository.BillingRepository
+	projectRepo repository.ProjectRepository
+	txManager   repository.TxManager
+}
+
+// NewService creates a new billing service
+func NewService(billingRepo repository.BillingRepository, projectRepo repository.ProjectRepository, txManager repository.TxManager) Service {
+	return &serviceImpl{
+		billingRepo: billingRepo,
+		projectRepo: projectRepo,
+		txManager:   txManager,
+	}
+}
+
+// GetSettings retrieves the billing settings of a project
+func (s *serviceImpl) GetSettings(ctx context.Context, organizationID, projectID uuid.UUID) (*models.BillingSettings, error) {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.billingRepo.GetSettings(ctx, projectID)
+}
+
+// UpdateSettings updates the billing settings of a project
+func (s *serviceImpl) UpdateSettings(ctx context.Context, organizationID, projectID uuid.UUID, req models.BillingSettingsRequest, userID uuid.UUID) (*models.BillingSettings, error) {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return nil, err
+	}
+
+	settings := &models.BillingSettings{
+		ProjectID:       projectID,
+		ClientName:      req.ClientName,
+		Currency:        req.Currency,
+		RoundingMinutes: req.RoundingMinutes,
+		RoundingMode:    req.RoundingMode,
+		UpdatedBy:       &userID,
+	}
+	if settings.RoundingMode == "" {
+		settings.RoundingMode = models.BillingRoundingNearest
+	}
+
+	if err := s.billingRepo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// ListRates retrieves the rate card of a project
+func (s *serviceImpl) ListRates(ctx context.Context, organizationID, projectID uuid.UUID) ([]models.BillingRate, error) {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.billingRepo.ListRates(ctx, projectID)
+}
+
+// CreateRate adds a rate to the rate card of a project
+func (s *serviceImpl) CreateRate(ctx context.Context, organizationID, projectID uuid.UUID, req models.BillingRateRequest, userID uuid.UUID) (*models.BillingRate, error) {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return nil, err
+	}
+
+	rate := models.NewBillingRate(req, projectID, userID)
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.billingRepo.LockRates(ctx, projectID); err != nil {
+			return err
+		}
+		if err := s.checkRate(ctx, organizationID, rate); err != nil {
+			return err
+		}
+		return s.billingRepo.CreateRate(ctx, rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// UpdateRate updates a rate of a project
+func (s *serviceImpl) UpdateRate(ctx context.Context, organizationID, projectID, id uuid.UUID, req models.BillingRateRequest) (*models.BillingRate, error) {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return nil, err
+	}
+
+	var rate *models.BillingRate
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.billingRepo.LockRates(ctx, projectID); err != nil {
+			return err
+		}
+
+		var err error
+		rate, err = s.getRate(ctx, projectID, id)
+		if err != nil {
+			return err
+		}
+
+		rate.Apply(req)
+		if err := s.checkRate(ctx, organizationID, rate); err != nil {
+			return err
+		}
+		return s.billingRepo.UpdateRate(ctx, rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// DeleteRate deletes a rate of a project
+func (s *serviceImpl) DeleteRate(ctx context.Context, organizationID, projectID, id uuid.UUID) error {
+	if err := s.checkProject(ctx, organizationID, projectID); err != nil {
+		return err
+	}
+
+	if _, err := s.getRate(ctx, projectID, id); err != nil {
+		return err
+	}
+
+	return s.billingRepo.DeleteRate(ctx, id)
+}
+
+// Report computes the amounts to bill over a period
+func (s *serviceImpl) Report(ctx context.Context, params models.BillingReportParams) (*models.BillingReport, error) {
+	params.From = models.BillingDay(params.From)
+	params.To = models.BillingDay(params.To)
+	if params.To.Before(params.From) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if params.ProjectID != nil {
+		if err := s.checkProject(ctx, params.OrganizationID, *params.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := s.billingRepo.ListEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.billingRepo.ListMemberRoles(ctx, params.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BillingReport{
+		OrganizationID: params.OrganizationID,
+		From:           params.From.Format(models.AnalyticsDateFormat),
+		To:             params.To.Format(models.AnalyticsDateFormat),
+		Projects:       []models.BillingProject{},
+	}
+
+	// Entries come grouped by project
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].ProjectID == entries[start].ProjectID {
+			end++
+		}
+
+		projectID := entries[start].ProjectID
+		settings, err := s.billingRepo.GetSettings(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		rates, err := s.billingRepo.ListRates(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Projects = append(report.Projects, bill(entries[start:end], settings, rateCard{rates: rates, roles: roles}))
+		start = end
+	}
+
+	report.Totals = totals(report.Projects)
+	return report, nil
+}
+
+// checkProject returns ErrProjectNotFound unless a project belongs to an
+// organization
+func (s *serviceImpl) checkProject(ctx context.Context, organizationID, projectID uuid.UUID) error {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	if project.OrganizationID != organizationID {
+		return repository.ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// getRate retrieves a rate of a project
+func (s *serviceImpl) getRate(ctx context.Context, projectID, id uuid.UUID) (*models.BillingRate, error) {
+	rate, err := s.billingRepo.GetRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate.ProjectID != projectID {
+		return nil, repository.ErrBillingRateNotFound
+	}
+
+	return rate, nil
+}
+
+// checkRate checks the scope and period of a rate, and that it does not
+// overlap the other rates of its project for the same members. A rate is
+// for a member of the organization, or a role its members hold. The rate
+// card of the project must be locked.
+func (s *serviceImpl) checkRate(ctx context.Context, organizationID uuid.UUID, rate *models.BillingRate) error {
+	if rate.UserID != nil && rate.RoleID != nil {
+		return ErrRateScope
+	}
+	if rate.EffectiveTo != nil && rate.EffectiveTo.Before(rate.EffectiveFrom) {
+		return ErrRatePeriod
+	}
+
+	if rate.UserID != nil || rate.RoleID != nil {
+		roles, err := s.billingRepo.ListMemberRoles(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+		if !inScope(roles, rate) {
+			return repository.ErrBillingRateScope
+		}
+	}
+
+	rates, err := s.billingRepo.ListRates(ctx, rate.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for i := range rates {
+		other := &rates[i]
+		if other.ID != rate.ID && other.SameScope(rate) && other.Overlaps(rate) {
+			return ErrRateOverlap
+		}
+	}
+
+	return nil
+}
+
+// inScope reports whether the user or role of a rate is a member or a role
+// of an organization, given the roles of its members
+func inScope(roles map[uuid.UUID][]uuid.UUID, rate *models.BillingRate) bool {
+	if rate.UserID != nil {
+		_, ok := roles[*rate.UserID]
+		return ok
+	}
+
+	for _, memberRoles := range roles {
+		for _, roleID := range memberRoles {
+			if roleID == *rate.RoleID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package board_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/board"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoard(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	sprintRepo := repository.NewPostgresSprintRepository(tdb.DB)
+	txManager := repository.NewTxManager(tdb.DB)
+
+	// Create services and handlers
+	workflowService := workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB))
+	taskService := task.NewService(
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		repository.NewPostgresTaskLinkRepository(tdb.DB),
+		repository.NewPostgresChecklistItemRepository(tdb.DB),
+		projectRepo,
+		userRepo,
+		repository.NewPostgresRoleRepository(tdb.DB),
+		notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), userRepo),
+		workflowService,
+		customfield.NewService(repository.NewPostgresCustomFieldRepository(tdb.DB), projectRepo, userRepo, repository.NewPostgresRoleRepository(tdb.DB)),
+		txManager,
+	)
+	boardService := board.NewService(
+		repository.NewPostgresBoardRepository(tdb.DB),
+		taskRepo,
+		sprintRepo,
+		projectRepo,
+		taskService,
+		workflowService,
+		txManager,
+	)
+	boardHandlers := board.NewHandlers(boardService)
+	e := echo.New()
+
+	// Create tasks, which are ranked in the order they are created
+	newTask := func(title string) *models.Task {
+		task := models.NewTask(models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + title,
+			Status:    models.TaskStatusTodo,
+			Priority:  models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	a, b, c := newTask("A"), newTask("B"), newTask("C")
+
+	getBoard := func(scope models.BoardScope) *models.Board {
+		board, err := boardService.Get(ctx, testProject.ID, scope, false, models.DefaultBoardLimit)
+		require.NoError(t, err)
+		return board
+	}
+	column := func(board *models.Board, status models.TaskStatus) []uuid.UUID {
+		for _, column := range board.Columns {
+			if column.State.Key == status {
+				ids := []uuid.UUID{}
+				for _, task := range column.Tasks {
+					ids = append(ids, task.ID)
+				}
+				return ids
+			}
+		}
+		t.Fatalf("no column %s", status)
+		return nil
+	}
+	move := func(id uuid.UUID, req models.TaskMoveRequest) error {
+		_, err := boardService.Move(ctx, id, req, testUser.ID)
+		return err
+	}
+
+	t.Run("GetBoard", func(t *testing.T) {
+		board := getBoard(models.BoardScope{})
+
+		// A column for each state of the workflow, in order
+		require.Len(t, board.Columns, 5)
+		assert.Equal(t, models.TaskStatusTodo, board.Columns[0].State.Key)
+		assert.Equal(t, models.TaskStatusCancelled, board.Columns[4].State.Key)
+		assert.Equal(t, []uuid.UUID{a.ID, b.ID, c.ID}, column(board, models.TaskStatusTodo))
+		assert.Equal(t, 3, board.Columns[0].Total)
+		assert.Empty(t, column(board, models.TaskStatusDone))
+	})
+
+	t.Run("Reorder", func(t *testing.T) {
+		require.NoError(t, move(c.ID, models.TaskMoveRequest{BeforeID: &a.ID}))
+		assert.Equal(t, []uuid.UUID{c.ID, a.ID, b.ID}, column(getBoard(models.BoardScope{}), models.TaskStatusTodo))
+
+		require.NoError(t, move(c.ID, models.TaskMoveRequest{AfterID: &a.ID, BeforeID: &b.ID}))
+		assert.Equal(t, []uuid.UUID{a.ID, c.ID, b.ID}, column(getBoard(models.BoardScope{}), models.TaskStatusTodo))
+
+		// Without neighbours a task moves to the end of its column
+		require.NoError(t, move(a.ID, models.TaskMoveRequest{}))
+		assert.Equal(t, []uuid.UUID{c.ID, b.ID, a.ID}, column(getBoard(models.BoardScope{}), models.TaskStatusTodo))
+	})
+
+	t.Run("MoveToColumn", func(t *testing.T) {
+		require.NoError(t, move(a.ID, models.TaskMoveRequest{Status: models.TaskStatusInProgress}))
+		require.NoError(t, move(b.ID, models.TaskMoveRequest{Status: models.TaskStatusInProgress, BeforeID: &a.ID}))
+
+		board := getBoard(models.BoardScope{})
+		assert.Equal(t, []uuid.UUID{c.ID}, column(board, models.TaskStatusTodo))
+		assert.Equal(t, []uuid.UUID{b.ID, a.ID}, column(board, models.TaskStatusInProgress))
+
+		// The status change is recorded like any other
+		moved, err := taskService.GetByID(ctx, a.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusInProgress, moved.Status)
+
+		// Invalid states are rejected
+		err = move(c.ID, models.TaskMoveRequest{Status: "nowhere"})
+		assert.ErrorIs(t, err, task.ErrInvalidTaskStatus)
+	})
+
+	t.Run("StaleNeighbours", func(t *testing.T) {
+		// b has moved to another column since the board was loaded
+		err := move(c.ID, models.TaskMoveRequest{AfterID: &b.ID})
+		assert.ErrorIs(t, err, board.ErrBoardChanged)
+
+		// Neighbours out of order
+		err = move(c.ID, models.TaskMoveRequest{Status: models.TaskStatusInProgress, AfterID: &a.ID, BeforeID: &b.ID})
+		assert.ErrorIs(t, err, board.ErrBoardChanged)
+
+		err = move(c.ID, models.TaskMoveRequest{AfterID: &c.ID})
+		assert.ErrorIs(t, err, board.ErrInvalidMove)
+
+		// Nothing changed
+		task, err := taskRepo.GetByID(ctx, c.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, task.Status)
+	})
+
+	t.Run("ConcurrentMoves", func(t *testing.T) {
+		tasks := []*models.Task{}
+		for i := 0; i < 8; i++ {
+			tasks = append(tasks, newTask("Concurrent"))
+		}
+
+		// Every task is dropped right after c at once
+		var wg sync.WaitGroup
+		errs := make([]error, len(tasks))
+		for i, task := range tasks {
+			wg.Add(1)
+			go func(i int, id uuid.UUID) {
+				defer wg.Done()
+				errs[i] = move(id, models.TaskMoveRequest{AfterID: &c.ID})
+			}(i, task.ID)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		// Each move got a rank of its own, between c and the tasks after it
+		todo := column(getBoard(models.BoardScope{}), models.TaskStatusTodo)
+		require.Len(t, todo, len(tasks)+1)
+		assert.Equal(t, c.ID, todo[0])
+
+		ranks := map[string]bool{}
+		for _, id := range todo {
+			task, err := taskRepo.GetByID(ctx, id)
+			require.NoError(t, err)
+			assert.False(t, ranks[task.Rank], "duplicate rank %s", task.Rank)
+			ranks[task.Rank] = true
+		}
+	})
+
+	t.Run("SprintBoard", func(t *testing.T) {
+		_, err := boardService.Get(ctx, testProject.ID, models.BoardScope{}, true, models.DefaultBoardLimit)
+		assert.ErrorIs(t, err, board.ErrNoActiveSprint)
+
+		sprint := models.NewSprint(testProject.ID, models.SprintRequest{Name: "Sprint"}, testUser.ID)
+		require.NoError(t, sprintRepo.Create(ctx, sprint))
+		_, err = sprintRepo.AddTasks(ctx, sprint, []uuid.UUID{a.ID, c.ID}, testUser.ID)
+		require.NoError(t, err)
+
+		sprintBoard := getBoard(models.BoardScope{SprintID: &sprint.ID})
+		require.NotNil(t, sprintBoard.Sprint)
+		assert.Equal(t, []uuid.UUID{c.ID}, column(sprintBoard, models.TaskStatusTodo))
+		assert.Equal(t, []uuid.UUID{a.ID}, column(sprintBoard, models.TaskStatusInProgress))
+
+		// The backlog holds the tasks without a sprint
+		req := httptest.NewRequest(http.MethodGet, "/?sprint=backlog&limit=2", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id/board")
+		ctx.SetParamNames("org_id", "id")
+		ctx.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+
+		require.NoError(t, boardHandlers.Get(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var backlog models.Board
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &backlog))
+		assert.True(t, backlog.Backlog)
+		assert.Len(t, column(&backlog, models.TaskStatusTodo), 2)
+		assert.Equal(t, 8, backlog.Columns[0].Total)
+		assert.Equal(t, []uuid.UUID{b.ID}, column(&backlog, models.TaskStatusInProgress))
+	})
+}
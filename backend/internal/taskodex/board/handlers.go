@@ -0,0 +1,164 @@
+package board
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for project boards
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get handles retrieving the board of a project
+func (h *Handlers) Get(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse sprint parameter: a sprint ID, the active sprint or the backlog
+	var scope models.BoardScope
+	activeSprint := false
+	switch sprintParam := c.QueryParam("sprint"); sprintParam {
+	case "":
+	case "active":
+		activeSprint = true
+	case "backlog":
+		scope.Backlog = true
+	default:
+		sprintID, err := uuid.Parse(sprintParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint parameter")
+		}
+		scope.SprintID = &sprintID
+	}
+
+	// Parse limit parameter
+	limit := models.DefaultBoardLimit
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > models.MaxBoardLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit parameter")
+		}
+	}
+
+	// Get board
+	board, err := h.service.Get(c.Request().Context(), projectID, scope, activeSprint, limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if errors.Is(err, repository.ErrSprintNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+		}
+		if errors.Is(err, ErrNoActiveSprint) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project has no active sprint")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve board")
+	}
+
+	return c.JSON(http.StatusOK, board)
+}
+
+// Move handles moving a task on its project's board
+func (h *Handlers) Move(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse request body
+	var req models.TaskMoveRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Move task
+	response, err := h.service.Move(c.Request().Context(), id, req, userID)
+	if err != nil {
+		return moveError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// moveError maps errors from moving a task to HTTP errors. Status changes
+// fail as they do when a task's status is updated.
+func moveError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	case errors.Is(err, ErrTaskNoProject), errors.Is(err, ErrInvalidMove):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrBoardChanged), errors.Is(err, repository.ErrProjectArchived):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, task.ErrInvalidTaskStatus):
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task status")
+	case errors.Is(err, task.ErrTransitionNotAllowed):
+		return echo.NewHTTPError(http.StatusConflict, "Status transition is not allowed by the workflow")
+	}
+
+	var guardErr *task.TransitionGuardError
+	if errors.As(err, &guardErr) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+			"message":        "Status transition requirements are not met",
+			"missing_fields": guardErr.MissingFields,
+			"required_role":  guardErr.RequiredRole,
+		})
+	}
+
+	var blockedErr *task.BlockedError
+	if errors.As(err, &blockedErr) {
+		blockers := make([]models.TaskResponse, 0, len(blockedErr.Blockers))
+		for _, blocker := range blockedErr.Blockers {
+			blockers = append(blockers, blocker.ToResponse())
+		}
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"message":  "Task is blocked by open tasks",
+			"blockers": blockers,
+		})
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, "Failed to move task")
+}
+
+// RegisterRoutes registers the board routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require task:read permission
+	g.GET("/projects/:id/board", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+
+	// Routes that require task:write permission
+	g.POST("/tasks/:id/move", h.Move, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
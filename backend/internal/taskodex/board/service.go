@@ -0,0 +1,276 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrNoActiveSprint = errors.New("project has no active sprint")
+	ErrTaskNoProject  = errors.New("task is not in a project")
+	ErrInvalidMove    = errors.New("invalid move")
+	ErrBoardChanged   = errors.New("the board has changed; reload it and try again")
+)
+
+// Service provides project boards
+type Service interface {
+	// Get retrieves the board of a project, with up to limit tasks per
+	// column. A sprint scope limits it to the tasks of the sprint, or to
+	// those without a sprint for the backlog.
+	Get(ctx context.Context, projectID uuid.UUID, scope models.BoardScope, activeSprint bool, limit int) (*models.Board, error)
+
+	// Move moves a task to a column of its project's board, between two of
+	// its tasks. Changing the column changes the task's status, following
+	// its workflow. Moves in a project are serialized, and a move whose
+	// neighbours have since moved elsewhere fails with ErrBoardChanged.
+	Move(ctx context.Context, taskID uuid.UUID, req models.TaskMoveRequest, userID uuid.UUID) (*models.TaskResponse, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	boardRepo   repository.BoardRepository
+	taskRepo    repository.TaskRepository
+	sprintRepo  repository.SprintRepository
+	projectRepo repository.ProjectRepository
+	taskSvc     task.Service
+	workflowSvc workflow.Service
+	txManager   repository.TxManager
+}
+
+// NewService creates a new board service
+func NewService(
+	boardRepo repository.BoardRepository,
+	taskRepo repository.TaskRepository,
+	sprintRepo repository.SprintRepository,
+	projectRepo repository.ProjectRepository,
+	taskSvc task.Service,
+	workflowSvc workflow.Service,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		boardRepo:   boardRepo,
+		taskRepo:    taskRepo,
+		sprintRepo:  sprintRepo,
+		projectRepo: projectRepo,
+		taskSvc:     taskSvc,
+		workflowSvc: workflowSvc,
+		txManager:   txManager,
+	}
+}
+
+// Get retrieves the board of a project
+func (s *serviceImpl) Get(ctx context.Context, projectID uuid.UUID, scope models.BoardScope, activeSprint bool, limit int) (*models.Board, error) {
+	// Check if project exists
+	_, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	scope.ProjectID = projectID
+
+	board := &models.Board{
+		ProjectID: projectID,
+		Backlog:   scope.Backlog,
+		Columns:   []models.BoardColumn{},
+	}
+
+	// Resolve the sprint of the board
+	switch {
+	case activeSprint:
+		board.Sprint, err = s.sprintRepo.GetActive(ctx, projectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrSprintNotFound) {
+				return nil, ErrNoActiveSprint
+			}
+			return nil, err
+		}
+		scope.SprintID = &board.Sprint.ID
+	case scope.SprintID != nil:
+		board.Sprint, err = s.sprintRepo.GetByID(ctx, *scope.SprintID)
+		if err != nil {
+			return nil, err
+		}
+		if board.Sprint.ProjectID != projectID {
+			return nil, repository.ErrSprintNotFound
+		}
+	}
+
+	workflow, err := s.workflowSvc.Get(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, totals, err := s.boardRepo.ListTasks(ctx, scope, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// A column for each state of the workflow, in order
+	states := append([]models.WorkflowState(nil), workflow.States...)
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Position < states[j].Position })
+
+	// Tasks that came from another workflow get a column of their own
+	for _, t := range tasks {
+		if !hasState(states, t.Status) {
+			states = append(states, models.WorkflowState{
+				Key:      t.Status,
+				Name:     string(t.Status),
+				Category: models.WorkflowStateCategoryOpen,
+				Position: len(states),
+			})
+		}
+	}
+
+	columns := make(map[models.TaskStatus]int, len(states))
+	for i, state := range states {
+		columns[state.Key] = i
+		board.Columns = append(board.Columns, models.BoardColumn{
+			State: state,
+			Tasks: []models.TaskResponse{},
+			Total: totals[state.Key],
+		})
+	}
+
+	for _, t := range tasks {
+		column := &board.Columns[columns[t.Status]]
+		column.Tasks = append(column.Tasks, t.ToResponse())
+	}
+
+	return board, nil
+}
+
+// Move moves a task on its project's board
+func (s *serviceImpl) Move(ctx context.Context, taskID uuid.UUID, req models.TaskMoveRequest, userID uuid.UUID) (*models.TaskResponse, error) {
+	t, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.ProjectID == nil {
+		return nil, ErrTaskNoProject
+	}
+	if t.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+	projectID := *t.ProjectID
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Moves in a project are serialized, so ranks are read and written
+		// without another move in between
+		if err := s.boardRepo.Lock(ctx, projectID); err != nil {
+			return err
+		}
+
+		// Read the task again in case it moved while waiting for the lock
+		t, err := s.taskRepo.GetByID(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if t.ProjectID == nil || *t.ProjectID != projectID {
+			return ErrBoardChanged
+		}
+
+		status := req.Status
+		if status == "" {
+			status = t.Status
+		}
+
+		after, err := s.neighbour(ctx, taskID, projectID, status, req.AfterID)
+		if err != nil {
+			return err
+		}
+		before, err := s.neighbour(ctx, taskID, projectID, status, req.BeforeID)
+		if err != nil {
+			return err
+		}
+
+		// The task goes between its neighbours, or next to the one given
+		lower, upper := "", ""
+		switch {
+		case after != nil && before != nil:
+			lower, upper = after.Rank, before.Rank
+			if lower >= upper {
+				return ErrBoardChanged
+			}
+		case after != nil:
+			lower = after.Rank
+			upper, err = s.boardRepo.RankAfter(ctx, projectID, lower)
+		case before != nil:
+			upper = before.Rank
+			lower, err = s.boardRepo.RankBefore(ctx, projectID, upper)
+		default:
+			lower, err = s.boardRepo.RankBefore(ctx, projectID, "")
+		}
+		if err != nil {
+			return err
+		}
+
+		rank, err := models.RankBetween(lower, upper)
+		if err != nil {
+			return err
+		}
+
+		// Changing the column changes the status, following the workflow
+		if status != t.Status {
+			if _, err := s.taskSvc.UpdateTaskStatus(ctx, taskID, status, userID, req.Force); err != nil {
+				return err
+			}
+		}
+
+		return s.boardRepo.SetRank(ctx, taskID, rank)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.taskSvc.GetByID(ctx, taskID)
+}
+
+// neighbour retrieves a task a moved task is placed next to, which must be
+// another task in the column it moves to
+func (s *serviceImpl) neighbour(ctx context.Context, taskID, projectID uuid.UUID, status models.TaskStatus, id *uuid.UUID) (*models.Task, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	if *id == taskID {
+		return nil, ErrInvalidMove
+	}
+
+	neighbour, err := s.taskRepo.GetByID(ctx, *id)
+	if err != nil {
+		// A neighbour deleted since the board was loaded
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return nil, ErrBoardChanged
+		}
+		return nil, err
+	}
+
+	if neighbour.ProjectID == nil || *neighbour.ProjectID != projectID {
+		return nil, ErrInvalidMove
+	}
+
+	// A neighbour moved to another column since the board was loaded
+	if neighbour.Status != status {
+		return nil, ErrBoardChanged
+	}
+
+	return neighbour, nil
+}
+
+// hasState reports whether states include a state with the given key
+func hasState(states []models.WorkflowState, key models.TaskStatus) bool {
+	for _, state := range states {
+		if state.Key == key {
+			return true
+		}
+	}
+	return false
+}
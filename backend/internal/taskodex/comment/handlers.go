@@ -55,6 +55,9 @@ func (h *Handlers) Create(c echo.Context) error {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create comment")
 	}
 
@@ -211,6 +214,9 @@ func (h *Handlers) Update(c echo.Context) error {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update comment")
 	}
 	
@@ -251,6 +257,9 @@ func (h *Handlers) Delete(c echo.Context) error {
 		if errors.Is(err, repository.ErrCommentNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Comment not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete comment")
 	}
 	
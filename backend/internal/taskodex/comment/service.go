@@ -54,7 +54,7 @@ func NewService(
 
 // Create creates a new comment
 func (s *serviceImpl) Create(ctx context.Context, req models.CommentRequest, userID uuid.UUID) (*models.CommentResponse, error) {
-	// Validate task
+	// Validate task. Tasks of archived projects are read-only.
 	task, err := s.taskRepo.GetByID(ctx, req.TaskID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
@@ -62,6 +62,9 @@ func (s *serviceImpl) Create(ctx context.Context, req models.CommentRequest, use
 		}
 		return nil, err
 	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
 	
 	// Validate user
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -102,11 +105,7 @@ func (s *serviceImpl) Create(ctx context.Context, req models.CommentRequest, use
 			task.ID,
 		)
 		
-		err = s.notificationSvc.Create(ctx, notification)
-		if err != nil {
-			// Log the error but don't fail the operation
-			// TODO: Add proper logging
-		}
+		s.notificationSvc.Send(ctx, notification)
 	}
 	
 	// Send notification to task creator if they're not the commenter
@@ -120,11 +119,7 @@ func (s *serviceImpl) Create(ctx context.Context, req models.CommentRequest, use
 			task.ID,
 		)
 		
-		err = s.notificationSvc.Create(ctx, notification)
-		if err != nil {
-			// Log the error but don't fail the operation
-			// TODO: Add proper logging
-		}
+		s.notificationSvc.Send(ctx, notification)
 	}
 	
 	// Send notification to task assignee if they're not the commenter and not the creator
@@ -138,11 +133,7 @@ func (s *serviceImpl) Create(ctx context.Context, req models.CommentRequest, use
 			task.ID,
 		)
 		
-		err = s.notificationSvc.Create(ctx, notification)
-		if err != nil {
-			// Log the error but don't fail the operation
-			// TODO: Add proper logging
-		}
+		s.notificationSvc.Send(ctx, notification)
 	}
 	
 	response := createdComment.ToResponse()
@@ -183,14 +174,17 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.Comme
 		return nil, err
 	}
 	
-	// Validate task
-	_, err = s.taskRepo.GetByID(ctx, req.TaskID)
+	// Validate task. Tasks of archived projects are read-only.
+	task, err := s.taskRepo.GetByID(ctx, req.TaskID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return nil, repository.ErrTaskNotFound
 		}
 		return nil, err
 	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
 	
 	// Update comment fields
 	comment.Content = req.Content
@@ -217,10 +211,19 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.Comme
 // Delete deletes a comment
 func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	// Check if comment exists
-	_, err := s.commentRepo.GetByID(ctx, id)
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	
+	// Tasks of archived projects are read-only
+	task, err := s.taskRepo.GetByID(ctx, comment.TaskID)
 	if err != nil {
 		return err
 	}
+	if task.Project.IsArchived() {
+		return repository.ErrProjectArchived
+	}
 	
 	return s.commentRepo.Delete(ctx, id)
 }
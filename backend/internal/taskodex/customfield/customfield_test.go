@@ -0,0 +1,228 @@
+package customfield_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFieldAPI(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	customFieldRepo := repository.NewPostgresCustomFieldRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+
+	// Create service and handlers
+	customFieldService := customfield.NewService(customFieldRepo, projectRepo, userRepo, roleRepo)
+	customFieldHandlers := customfield.NewHandlers(customFieldService)
+
+	// Setup Echo
+	e := echo.New()
+
+	newContext := func(method string, target string, body interface{}) (echo.Context, *httptest.ResponseRecorder) {
+		var reqBody bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&reqBody).Encode(body)
+		}
+
+		req := httptest.NewRequest(method, target, &reqBody)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/custom-fields")
+		c.SetParamNames("org_id")
+		c.SetParamValues(testOrg.ID.String())
+		return c, rec
+	}
+
+	severity := models.CustomFieldDefinitionRequest{
+		Entity:  models.CustomFieldEntityTask,
+		Key:     "severity",
+		Name:    "Severity",
+		Type:    models.CustomFieldTypeSelect,
+		Options: []string{"minor", "major", "critical"},
+	}
+
+	var created models.CustomFieldDefinition
+
+	t.Run("CreateCustomField", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/", severity)
+
+		err := customFieldHandlers.Create(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		err = json.Unmarshal(rec.Body.Bytes(), &created)
+		assert.NoError(t, err)
+		assert.Equal(t, "severity", created.Key)
+		assert.Equal(t, severity.Options, created.Options)
+		assert.Nil(t, created.ProjectID)
+	})
+
+	t.Run("DuplicateKey", func(t *testing.T) {
+		// Projects cannot redefine an organization-wide key
+		duplicate := severity
+		duplicate.ProjectID = &testProject.ID
+
+		c, _ := newContext(http.MethodPost, "/", duplicate)
+		err := customFieldHandlers.Create(c)
+		if httpErr, ok := err.(*echo.HTTPError); assert.True(t, ok) {
+			assert.Equal(t, http.StatusConflict, httpErr.Code)
+		}
+	})
+
+	t.Run("InvalidDefinition", func(t *testing.T) {
+		invalid := severity
+		invalid.Key = "Bad Key"
+
+		_, err := customFieldService.Create(context.Background(), testOrg.ID, invalid)
+		assert.ErrorIs(t, err, customfield.ErrInvalidDefinition)
+
+		invalid = severity
+		invalid.Key = "estimate"
+		invalid.Type = models.CustomFieldTypeNumber
+		_, err = customFieldService.Create(context.Background(), testOrg.ID, invalid)
+		assert.ErrorIs(t, err, customfield.ErrInvalidDefinition)
+	})
+
+	t.Run("ListCustomFields", func(t *testing.T) {
+		_, err := customFieldService.Create(context.Background(), testOrg.ID, models.CustomFieldDefinitionRequest{
+			ProjectID: &testProject.ID,
+			Entity:    models.CustomFieldEntityTask,
+			Key:       "due_review",
+			Name:      "Review Date",
+			Type:      models.CustomFieldTypeDate,
+		})
+		require.NoError(t, err)
+
+		// Organization-wide fields only
+		c, rec := newContext(http.MethodGet, "/?entity=task", nil)
+		err = customFieldHandlers.List(c)
+		assert.NoError(t, err)
+
+		var definitions []models.CustomFieldDefinition
+		err = json.Unmarshal(rec.Body.Bytes(), &definitions)
+		assert.NoError(t, err)
+		assert.Len(t, definitions, 1)
+
+		// Organization-wide and project fields
+		c, rec = newContext(http.MethodGet, "/?entity=task&project_id="+testProject.ID.String(), nil)
+		err = customFieldHandlers.List(c)
+		assert.NoError(t, err)
+
+		err = json.Unmarshal(rec.Body.Bytes(), &definitions)
+		assert.NoError(t, err)
+		assert.Len(t, definitions, 2)
+	})
+
+	t.Run("ValidateValues", func(t *testing.T) {
+		ctx := context.Background()
+
+		values, err := customFieldService.Validate(ctx, models.CustomFieldEntityTask, testOrg.ID, &testProject.ID, map[string]interface{}{
+			"severity":   "major",
+			"due_review": "2026-03-01",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "major", values["severity"])
+
+		_, err = customFieldService.Validate(ctx, models.CustomFieldEntityTask, testOrg.ID, &testProject.ID, map[string]interface{}{
+			"due_review": "March 1st",
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+
+		// Project fields do not apply outside the project
+		_, err = customFieldService.Validate(ctx, models.CustomFieldEntityTask, testOrg.ID, nil, map[string]interface{}{
+			"due_review": "2026-03-01",
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+	})
+
+	t.Run("ValidateUserValues", func(t *testing.T) {
+		ctx := context.Background()
+
+		_, err := customFieldService.Create(ctx, testOrg.ID, models.CustomFieldDefinitionRequest{
+			ProjectID: &testProject.ID,
+			Entity:    models.CustomFieldEntityTask,
+			Key:       "reviewer",
+			Name:      "Reviewer",
+			Type:      models.CustomFieldTypeUser,
+		})
+		require.NoError(t, err)
+
+		// Make the test user a member of the organization
+		role := &models.Role{
+			ID:        uuid.New(),
+			Name:      prefix + " member",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(t, roleRepo.CreateRole(ctx, role))
+		require.NoError(t, roleRepo.AssignRoleToUser(ctx, &models.UserRole{
+			UserID:         testUser.ID,
+			RoleID:         role.ID,
+			OrganizationID: testOrg.ID,
+		}))
+
+		values, err := customFieldService.Validate(ctx, models.CustomFieldEntityTask, testOrg.ID, &testProject.ID, map[string]interface{}{
+			"reviewer": testUser.ID.String(),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testUser.ID.String(), values["reviewer"])
+
+		// Users outside the organization cannot be referenced
+		outsider := tdb.CreateTestUser(t, prefix+"2")
+		_, err = customFieldService.Validate(ctx, models.CustomFieldEntityTask, testOrg.ID, &testProject.ID, map[string]interface{}{
+			"reviewer": outsider.ID.String(),
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+	})
+
+	t.Run("UpdateCustomField", func(t *testing.T) {
+		update := severity
+		update.Name = "Impact"
+		update.Options = append(update.Options, "blocker")
+
+		updated, err := customFieldService.Update(context.Background(), testOrg.ID, created.ID, update)
+		require.NoError(t, err)
+		assert.Equal(t, "Impact", updated.Name)
+		assert.Len(t, updated.Options, 4)
+
+		// The type of a field cannot change
+		update.Type = models.CustomFieldTypeText
+		update.Options = nil
+		_, err = customFieldService.Update(context.Background(), testOrg.ID, created.ID, update)
+		assert.ErrorIs(t, err, customfield.ErrInvalidDefinition)
+	})
+
+	t.Run("DeleteCustomField", func(t *testing.T) {
+		err := customFieldService.Delete(context.Background(), testOrg.ID, created.ID)
+		assert.NoError(t, err)
+
+		_, err = customFieldService.GetByID(context.Background(), testOrg.ID, created.ID)
+		assert.ErrorIs(t, err, repository.ErrCustomFieldNotFound)
+	})
+}
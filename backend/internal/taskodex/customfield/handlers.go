@@ -0,0 +1,202 @@
+package customfield
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for custom field management
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create handles the creation of a new custom field definition
+func (h *Handlers) Create(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgIDParam := c.Param("org_id")
+	orgID, err := uuid.Parse(orgIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Parse request body
+	var req models.CustomFieldDefinitionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create custom field
+	response, err := h.service.Create(c.Request().Context(), orgID, req)
+	if err != nil {
+		return h.handleError(err, "Failed to create custom field")
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// Get handles retrieving a custom field definition by ID
+func (h *Handlers) Get(c echo.Context) error {
+	// Get organization and custom field IDs from path parameters
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Get custom field
+	response, err := h.service.GetByID(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve custom field")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// List handles listing custom field definitions
+func (h *Handlers) List(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgIDParam := c.Param("org_id")
+	orgID, err := uuid.Parse(orgIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Parse entity parameter
+	entity := models.CustomFieldEntity(c.QueryParam("entity"))
+	if entity == "" {
+		entity = models.CustomFieldEntityTask
+	}
+	if entity != models.CustomFieldEntityTask && entity != models.CustomFieldEntityProject {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid entity parameter")
+	}
+
+	// Parse project_id parameter
+	var projectID *uuid.UUID
+	projectIDParam := c.QueryParam("project_id")
+	if projectIDParam != "" {
+		id, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid project_id parameter")
+		}
+		projectID = &id
+	}
+
+	// List custom fields
+	definitions, err := h.service.List(c.Request().Context(), orgID, projectID, entity)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to list custom fields")
+	}
+
+	return c.JSON(http.StatusOK, definitions)
+}
+
+// Update handles updating a custom field definition
+func (h *Handlers) Update(c echo.Context) error {
+	// Get organization and custom field IDs from path parameters
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.CustomFieldDefinitionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update custom field
+	response, err := h.service.Update(c.Request().Context(), orgID, id, req)
+	if err != nil {
+		return h.handleError(err, "Failed to update custom field")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Delete handles deleting a custom field definition
+func (h *Handlers) Delete(c echo.Context) error {
+	// Get organization and custom field IDs from path parameters
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Delete custom field
+	err = h.service.Delete(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to delete custom field")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseIDs parses the organization and custom field IDs from path parameters
+func (h *Handlers) parseIDs(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid custom field ID")
+	}
+
+	return orgID, id, nil
+}
+
+// handleError maps errors from managing custom fields to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	if errors.Is(err, repository.ErrCustomFieldNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Custom field not found")
+	}
+	if errors.Is(err, repository.ErrProjectNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	if errors.Is(err, repository.ErrCustomFieldKeyExists) {
+		return echo.NewHTTPError(http.StatusConflict, "Custom field key already exists")
+	}
+	if errors.Is(err, ErrInvalidDefinition) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the custom field routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	customFieldGroup := g.Group("/custom-fields")
+
+	// Routes that require project:read permission
+	customFieldGroup.GET("", h.List, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	customFieldGroup.GET("/:id", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	customFieldGroup.POST("", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	customFieldGroup.PUT("/:id", h.Update, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	customFieldGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+}
@@ -0,0 +1,219 @@
+package customfield
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrInvalidDefinition = errors.New("invalid custom field definition")
+)
+
+// Service provides custom field management functionality
+type Service interface {
+	// Create creates a custom field definition in an organization
+	Create(ctx context.Context, organizationID uuid.UUID, req models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error)
+
+	// GetByID retrieves a custom field definition of an organization
+	GetByID(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) (*models.CustomFieldDefinition, error)
+
+	// List retrieves the definitions of an entity that apply to a project, or
+	// the organization-wide definitions when projectID is nil
+	List(ctx context.Context, organizationID uuid.UUID, projectID *uuid.UUID, entity models.CustomFieldEntity) ([]models.CustomFieldDefinition, error)
+
+	// Update updates a custom field definition. Its key, type, entity and
+	// project cannot change.
+	Update(ctx context.Context, organizationID uuid.UUID, id uuid.UUID, req models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error)
+
+	// Delete deletes a custom field definition and the values stored for it
+	Delete(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) error
+
+	// Validate checks custom field values of a record against the definitions
+	// that apply to it and returns them normalized
+	Validate(ctx context.Context, entity models.CustomFieldEntity, organizationID uuid.UUID, projectID *uuid.UUID, values map[string]interface{}) (models.CustomFieldValues, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	customFieldRepo repository.CustomFieldRepository
+	projectRepo     repository.ProjectRepository
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+}
+
+// NewService creates a new custom field service
+func NewService(
+	customFieldRepo repository.CustomFieldRepository,
+	projectRepo repository.ProjectRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+) Service {
+	return &serviceImpl{
+		customFieldRepo: customFieldRepo,
+		projectRepo:     projectRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+	}
+}
+
+// Create creates a custom field definition in an organization
+func (s *serviceImpl) Create(ctx context.Context, organizationID uuid.UUID, req models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error) {
+	if err := s.validateRequest(ctx, organizationID, req); err != nil {
+		return nil, err
+	}
+
+	definition := models.NewCustomFieldDefinition(organizationID, req)
+	err := s.customFieldRepo.Create(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customFieldRepo.GetByID(ctx, definition.ID)
+}
+
+// GetByID retrieves a custom field definition of an organization
+func (s *serviceImpl) GetByID(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) (*models.CustomFieldDefinition, error) {
+	definition, err := s.customFieldRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Definitions of other organizations are not visible
+	if definition.OrganizationID != organizationID {
+		return nil, repository.ErrCustomFieldNotFound
+	}
+
+	return definition, nil
+}
+
+// List retrieves the definitions of an entity that apply to a project
+func (s *serviceImpl) List(ctx context.Context, organizationID uuid.UUID, projectID *uuid.UUID, entity models.CustomFieldEntity) ([]models.CustomFieldDefinition, error) {
+	return s.customFieldRepo.List(ctx, organizationID, projectID, entity)
+}
+
+// Update updates a custom field definition
+func (s *serviceImpl) Update(ctx context.Context, organizationID uuid.UUID, id uuid.UUID, req models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error) {
+	definition, err := s.GetByID(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Key != definition.Key || req.Type != definition.Type || req.Entity != definition.Entity ||
+		!sameProject(req.ProjectID, definition.ProjectID) {
+		return nil, fmt.Errorf("%w: key, type, entity and project cannot change", ErrInvalidDefinition)
+	}
+
+	if err := s.validateRequest(ctx, organizationID, req); err != nil {
+		return nil, err
+	}
+
+	definition.Name = req.Name
+	definition.Options = req.Options
+	definition.Required = req.Required
+	definition.Position = req.Position
+
+	err = s.customFieldRepo.Update(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customFieldRepo.GetByID(ctx, id)
+}
+
+// Delete deletes a custom field definition and the values stored for it
+func (s *serviceImpl) Delete(ctx context.Context, organizationID uuid.UUID, id uuid.UUID) error {
+	_, err := s.GetByID(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.customFieldRepo.Delete(ctx, id)
+}
+
+// Validate checks custom field values of a record against its definitions
+func (s *serviceImpl) Validate(ctx context.Context, entity models.CustomFieldEntity, organizationID uuid.UUID, projectID *uuid.UUID, values map[string]interface{}) (models.CustomFieldValues, error) {
+	definitions, err := s.customFieldRepo.List(ctx, organizationID, projectID, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := models.ValidateCustomFields(definitions, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// User fields must reference members of the organization
+	for _, definition := range definitions {
+		value, ok := normalized[definition.Key]
+		if !ok || definition.Type != models.CustomFieldTypeUser {
+			continue
+		}
+
+		userID, err := uuid.Parse(value.(string))
+		if err != nil {
+			return nil, &models.CustomFieldError{Key: definition.Key, Reason: "must be a user ID"}
+		}
+		_, err = s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, &models.CustomFieldError{Key: definition.Key, Reason: "must reference an existing user"}
+			}
+			return nil, err
+		}
+		roles, err := s.roleRepo.GetUserRoles(ctx, userID, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		if len(roles) == 0 {
+			return nil, &models.CustomFieldError{Key: definition.Key, Reason: "must reference a member of the organization"}
+		}
+	}
+
+	return normalized, nil
+}
+
+// validateRequest checks a definition request beyond its struct validation
+func (s *serviceImpl) validateRequest(ctx context.Context, organizationID uuid.UUID, req models.CustomFieldDefinitionRequest) error {
+	if !models.IsValidCustomFieldKey(req.Key) {
+		return fmt.Errorf("%w: key must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidDefinition)
+	}
+
+	hasOptions := req.Type == models.CustomFieldTypeSelect || req.Type == models.CustomFieldTypeMultiSelect
+	if !hasOptions && len(req.Options) > 0 {
+		return fmt.Errorf("%w: only select fields have options", ErrInvalidDefinition)
+	}
+	seen := make(map[string]bool, len(req.Options))
+	for _, option := range req.Options {
+		if seen[option] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidDefinition, option)
+		}
+		seen[option] = true
+	}
+
+	// Project-level fields must belong to a project of the organization
+	if req.ProjectID != nil {
+		project, err := s.projectRepo.GetByID(ctx, *req.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project.OrganizationID != organizationID {
+			return repository.ErrProjectNotFound
+		}
+	}
+
+	return nil
+}
+
+// sameProject reports whether two optional project IDs are equal
+func sameProject(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -66,6 +66,9 @@ func (h *Handlers) Upload(c echo.Context) error {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to upload file")
 	}
 
@@ -246,6 +249,9 @@ func (h *Handlers) Delete(c echo.Context) error {
 		if errors.Is(err, repository.ErrFileAttachmentNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "File attachment not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete file attachment")
 	}
 	
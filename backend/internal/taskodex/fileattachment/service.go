@@ -61,7 +61,7 @@ func NewService(
 
 // Upload uploads a file and creates a file attachment
 func (s *serviceImpl) Upload(ctx context.Context, taskID, userID uuid.UUID, file *multipart.FileHeader) (*models.FileAttachmentResponse, error) {
-	// Validate task
+	// Validate task. Tasks of archived projects are read-only.
 	task, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
@@ -69,6 +69,9 @@ func (s *serviceImpl) Upload(ctx context.Context, taskID, userID uuid.UUID, file
 		}
 		return nil, err
 	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
 	
 	// Validate user
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -119,11 +122,7 @@ func (s *serviceImpl) Upload(ctx context.Context, taskID, userID uuid.UUID, file
 			task.ID,
 		)
 		
-		err = s.notificationSvc.Create(ctx, notification)
-		if err != nil {
-			// Log the error but don't fail the operation
-			// TODO: Add proper logging
-		}
+		s.notificationSvc.Send(ctx, notification)
 	}
 	
 	// Send notification to task assignee if they're not the uploader and not the creator
@@ -137,11 +136,7 @@ func (s *serviceImpl) Upload(ctx context.Context, taskID, userID uuid.UUID, file
 			task.ID,
 		)
 		
-		err = s.notificationSvc.Create(ctx, notification)
-		if err != nil {
-			// Log the error but don't fail the operation
-			// TODO: Add proper logging
-		}
+		s.notificationSvc.Send(ctx, notification)
 	}
 	
 	response := createdFileAttachment.ToResponse(s.baseURL)
@@ -192,6 +187,15 @@ func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
 		return err
 	}
 	
+	// Tasks of archived projects are read-only
+	task, err := s.taskRepo.GetByID(ctx, fileAttachment.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.Project.IsArchived() {
+		return repository.ErrProjectArchived
+	}
+	
 	// Delete file from storage
 	err = s.fileStorage.DeleteFile(fileAttachment.StoragePath)
 	if err != nil {
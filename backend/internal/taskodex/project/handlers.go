@@ -63,6 +63,9 @@ func (h *Handlers) Create(c echo.Context) error {
 		if errors.Is(err, repository.ErrProjectNameExists) {
 			return echo.NewHTTPError(http.StatusConflict, "Project name already exists in this organization")
 		}
+		if errors.Is(err, models.ErrInvalidCustomField) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create project")
 	}
 
@@ -121,6 +124,16 @@ func (h *Handlers) List(c echo.Context) error {
 		params.CreatedBy = &createdBy
 	}
 	
+	// Parse archived parameter
+	archivedParam := c.QueryParam("archived")
+	if archivedParam != "" {
+		archived, err := strconv.ParseBool(archivedParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid archived parameter")
+		}
+		params.Archived = &archived
+	}
+	
 	// Parse search parameter
 	searchParam := c.QueryParam("search")
 	if searchParam != "" {
@@ -222,6 +235,12 @@ func (h *Handlers) Update(c echo.Context) error {
 		if errors.Is(err, repository.ErrProjectNameExists) {
 			return echo.NewHTTPError(http.StatusConflict, "Project name already exists in this organization")
 		}
+		if errors.Is(err, models.ErrInvalidCustomField) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update project")
 	}
 	
@@ -237,21 +256,72 @@ func (h *Handlers) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
 	}
 	
-	// Delete project
-	err = h.service.Delete(c.Request().Context(), id)
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+	
+	// Move project to the trash
+	err = h.service.Delete(c.Request().Context(), id, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrProjectNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
 		}
-		if errors.Is(err, errors.New("cannot delete project with tasks")) {
-			return echo.NewHTTPError(http.StatusBadRequest, "Cannot delete project with tasks")
-		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete project")
 	}
 	
 	return c.NoContent(http.StatusNoContent)
 }
 
+// Archive handles making a project read-only
+func (h *Handlers) Archive(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+	
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+	
+	// Archive project
+	response, err := h.service.Archive(c.Request().Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to archive project")
+	}
+	
+	return c.JSON(http.StatusOK, response)
+}
+
+// Unarchive handles making an archived project writable again
+func (h *Handlers) Unarchive(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+	
+	// Unarchive project
+	response, err := h.service.Unarchive(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unarchive project")
+	}
+	
+	return c.JSON(http.StatusOK, response)
+}
+
 // GetTasks handles retrieving all tasks for a project
 func (h *Handlers) GetTasks(c echo.Context) error {
 	// Get project ID from path parameter
@@ -404,6 +474,9 @@ func (h *Handlers) AddTask(c echo.Context) error {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add task to project")
 	}
 	
@@ -438,6 +511,9 @@ func (h *Handlers) RemoveTask(c echo.Context) error {
 		if errors.Is(err, errors.New("task does not belong to the project")) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Task does not belong to the project")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove task from project")
 	}
 	
@@ -458,6 +534,8 @@ func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBAC
 	projectGroup.PUT("/:id", h.Update, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
 	projectGroup.POST("/:id/tasks", h.AddTask, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
 	projectGroup.DELETE("/:id/tasks/:task_id", h.RemoveTask, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	projectGroup.POST("/:id/archive", h.Archive, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	projectGroup.POST("/:id/unarchive", h.Unarchive, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
 	
 	// Routes that require project:delete permission
 	projectGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionProjectDelete))
@@ -10,6 +10,7 @@ import (
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
 	"github.com/Jerinji2016/halooid/backend/internal/taskodex/project"
 	"github.com/Jerinji2016/halooid/backend/internal/test"
 	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
@@ -29,10 +30,13 @@ func TestProjectAPI(t *testing.T) {
 
 	// Create repositories
 	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
 	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	customFieldRepo := repository.NewPostgresCustomFieldRepository(tdb.DB)
 
 	// Create service and handlers
-	projectService := project.NewService(projectRepo, userRepo)
+	customFieldService := customfield.NewService(customFieldRepo, projectRepo, userRepo, repository.NewPostgresRoleRepository(tdb.DB))
+	projectService := project.NewService(projectRepo, taskRepo, userRepo, customFieldService)
 	projectHandlers := project.NewHandlers(projectService)
 
 	// Setup Echo
@@ -253,6 +257,7 @@ func TestProjectAPI(t *testing.T) {
 		c.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id")
 		c.SetParamNames("org_id", "id")
 		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+		c.Set("user_id", testUser.ID.String())
 
 		// Handle request
 		err := projectHandlers.Delete(c)
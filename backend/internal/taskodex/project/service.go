@@ -6,6 +6,7 @@ import (
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
 	"github.com/google/uuid"
 )
 
@@ -26,8 +27,16 @@ type Service interface {
 	// Update updates a project
 	Update(ctx context.Context, id uuid.UUID, req models.ProjectRequest) (*models.ProjectResponse, error)
 	
-	// Delete deletes a project
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete moves a project to the trash along with its tasks. Projects in
+	// the trash can be restored until they are purged.
+	Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error
+	
+	// Archive makes a project read-only. Archived projects and their tasks can
+	// still be listed and searched.
+	Archive(ctx context.Context, id uuid.UUID, archivedBy uuid.UUID) (*models.ProjectResponse, error)
+	
+	// Unarchive makes an archived project writable again
+	Unarchive(ctx context.Context, id uuid.UUID) (*models.ProjectResponse, error)
 	
 	// GetTasks retrieves all tasks for a project
 	GetTasks(ctx context.Context, projectID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error)
@@ -41,17 +50,19 @@ type Service interface {
 
 // serviceImpl implements the Service interface
 type serviceImpl struct {
-	projectRepo repository.ProjectRepository
-	taskRepo    repository.TaskRepository
-	userRepo    repository.UserRepository
+	projectRepo    repository.ProjectRepository
+	taskRepo       repository.TaskRepository
+	userRepo       repository.UserRepository
+	customFieldSvc customfield.Service
 }
 
 // NewService creates a new project service
-func NewService(projectRepo repository.ProjectRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository) Service {
+func NewService(projectRepo repository.ProjectRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository, customFieldSvc customfield.Service) Service {
 	return &serviceImpl{
-		projectRepo: projectRepo,
-		taskRepo:    taskRepo,
-		userRepo:    userRepo,
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		userRepo:       userRepo,
+		customFieldSvc: customFieldSvc,
 	}
 }
 
@@ -60,7 +71,14 @@ func (s *serviceImpl) Create(ctx context.Context, req models.ProjectRequest, cre
 	// Create project
 	project := models.NewProject(req, createdBy)
 	
-	err := s.projectRepo.Create(ctx, project)
+	// New projects only have the organization's custom fields
+	customFields, err := s.customFieldSvc.Validate(ctx, models.CustomFieldEntityProject, req.OrganizationID, nil, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	project.CustomFields = customFields
+	
+	err = s.projectRepo.Create(ctx, project)
 	if err != nil {
 		if errors.Is(err, repository.ErrProjectNameExists) {
 			return nil, repository.ErrProjectNameExists
@@ -118,7 +136,7 @@ func (s *serviceImpl) List(ctx context.Context, params models.ProjectListParams)
 // Update updates a project
 func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.ProjectRequest) (*models.ProjectResponse, error) {
 	// Check if project exists
-	project, err := s.projectRepo.GetByID(ctx, id)
+	project, err := s.getWritableProject(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +149,19 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.Proje
 	project.StartDate = req.StartDate
 	project.EndDate = req.EndDate
 	
+	// Custom fields in the request are merged into the project's values; null clears a field
+	values := make(map[string]interface{}, len(project.CustomFields)+len(req.CustomFields))
+	for key, value := range project.CustomFields {
+		values[key] = value
+	}
+	for key, value := range req.CustomFields {
+		values[key] = value
+	}
+	project.CustomFields, err = s.customFieldSvc.Validate(ctx, models.CustomFieldEntityProject, project.OrganizationID, &project.ID, values)
+	if err != nil {
+		return nil, err
+	}
+	
 	err = s.projectRepo.Update(ctx, project)
 	if err != nil {
 		if errors.Is(err, repository.ErrProjectNameExists) {
@@ -149,15 +180,58 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.Proje
 	return &response, nil
 }
 
-// Delete deletes a project
-func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete moves a project to the trash along with its tasks. Archived
+// projects can be deleted too.
+func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID, deletedBy uuid.UUID) error {
 	// Check if project exists
 	_, err := s.projectRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 	
-	return s.projectRepo.Delete(ctx, id)
+	return s.projectRepo.Delete(ctx, id, deletedBy)
+}
+
+// Archive makes a project read-only
+func (s *serviceImpl) Archive(ctx context.Context, id uuid.UUID, archivedBy uuid.UUID) (*models.ProjectResponse, error) {
+	// Check if project exists
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Archiving an archived project keeps its original archival
+	if !project.IsArchived() {
+		if err := s.projectRepo.Archive(ctx, id, archivedBy); err != nil {
+			return nil, err
+		}
+	}
+	
+	return s.GetByID(ctx, id)
+}
+
+// Unarchive makes an archived project writable again
+func (s *serviceImpl) Unarchive(ctx context.Context, id uuid.UUID) (*models.ProjectResponse, error) {
+	if err := s.projectRepo.Unarchive(ctx, id); err != nil {
+		return nil, err
+	}
+	
+	return s.GetByID(ctx, id)
+}
+
+// getWritableProject retrieves a project to change. Archived projects are
+// read-only.
+func (s *serviceImpl) getWritableProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+	
+	return project, nil
 }
 
 // GetTasks retrieves all tasks for a project
@@ -188,16 +262,19 @@ func (s *serviceImpl) GetTasks(ctx context.Context, projectID uuid.UUID, params
 // AddTask adds a task to a project
 func (s *serviceImpl) AddTask(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID) error {
 	// Check if project exists
-	_, err := s.projectRepo.GetByID(ctx, projectID)
+	_, err := s.getWritableProject(ctx, projectID)
 	if err != nil {
 		return err
 	}
 	
-	// Check if task exists
+	// Check if task exists, and can be moved out of its current project
 	task, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
 		return err
 	}
+	if task.Project.IsArchived() {
+		return repository.ErrProjectArchived
+	}
 	
 	// Update task's project ID
 	task.ProjectID = &projectID
@@ -208,7 +285,7 @@ func (s *serviceImpl) AddTask(ctx context.Context, projectID uuid.UUID, taskID u
 // RemoveTask removes a task from a project
 func (s *serviceImpl) RemoveTask(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID) error {
 	// Check if project exists
-	_, err := s.projectRepo.GetByID(ctx, projectID)
+	_, err := s.getWritableProject(ctx, projectID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,229 @@
+package projecttemplate
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for project templates and project cloning
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create handles saving a project as a template
+func (h *Handlers) Create(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse request body
+	var req models.ProjectTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create template
+	template, err := h.service.Create(c.Request().Context(), projectID, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to create project template")
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+// Get handles retrieving a project template by ID
+func (h *Handlers) Get(c echo.Context) error {
+	// Get organization and template IDs
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Get template
+	template, err := h.service.GetByID(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve project template")
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+// List handles listing the project templates of an organization
+func (h *Handlers) List(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// List templates
+	templates, err := h.service.List(c.Request().Context(), orgID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to list project templates")
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+// Delete handles deleting a project template
+func (h *Handlers) Delete(c echo.Context) error {
+	// Get organization and template IDs
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Delete template
+	err = h.service.Delete(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to delete project template")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Instantiate handles creating a project from a template
+func (h *Handlers) Instantiate(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization and template IDs
+	orgID, id, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.ProjectInstantiateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create project
+	result, err := h.service.Instantiate(c.Request().Context(), orgID, id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to create project from template")
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// Clone handles cloning a project
+func (h *Handlers) Clone(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse request body
+	var req models.ProjectCloneRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Clone project
+	result, err := h.service.Clone(c.Request().Context(), projectID, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to clone project")
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// parseIDs parses the organization and template IDs from path parameters
+func (h *Handlers) parseIDs(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project template ID")
+	}
+
+	return orgID, id, nil
+}
+
+// handleError maps errors from managing project templates to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	case errors.Is(err, repository.ErrProjectTemplateNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project template not found")
+	case errors.Is(err, repository.ErrProjectNameExists):
+		return echo.NewHTTPError(http.StatusConflict, "Project name already exists in this organization")
+	case errors.Is(err, repository.ErrProjectTemplateNameExists):
+		return echo.NewHTTPError(http.StatusConflict, "Project template name already exists in this organization")
+	case errors.Is(err, ErrTooManyTasks), errors.Is(err, ErrInvalidAssignee), errors.Is(err, ErrInvalidWorkflow):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the project template routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	templateGroup := g.Group("/project-templates")
+
+	// Routes that require project:read permission
+	templateGroup.GET("", h.List, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	templateGroup.GET("/:id", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	g.POST("/projects/:id/template", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	g.POST("/projects/:id/clone", h.Clone, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	templateGroup.POST("/:id/projects", h.Instantiate, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+
+	// Routes that require project:delete permission
+	templateGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionProjectDelete))
+}
@@ -0,0 +1,315 @@
+package projecttemplate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/projecttemplate"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTemplates(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test users, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	designer := tdb.CreateTestUser(t, prefix+"2")
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	checklistRepo := repository.NewPostgresChecklistItemRepository(tdb.DB)
+	taskLinkRepo := repository.NewPostgresTaskLinkRepository(tdb.DB)
+	customFieldRepo := repository.NewPostgresCustomFieldRepository(tdb.DB)
+	workflowRepo := repository.NewPostgresWorkflowRepository(tdb.DB)
+
+	// Create service and handlers
+	templateService := projecttemplate.NewService(
+		repository.NewPostgresProjectTemplateRepository(tdb.DB),
+		projectRepo,
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		checklistRepo,
+		taskLinkRepo,
+		customFieldRepo,
+		workflowRepo,
+		repository.NewPostgresUserRepository(tdb.DB),
+		repository.NewTxManager(tdb.DB),
+	)
+	templateHandlers := projecttemplate.NewHandlers(templateService)
+	e := echo.New()
+
+	// The project starts today and has its own workflow and custom field
+	start := time.Now().UTC().Truncate(time.Second)
+	testProject.StartDate = &start
+	require.NoError(t, projectRepo.Update(ctx, testProject))
+
+	require.NoError(t, workflowRepo.Save(ctx, models.NewWorkflow(testProject.ID, models.WorkflowRequest{
+		Name:         "Engagement",
+		InitialState: models.TaskStatusTodo,
+		States: []models.WorkflowState{
+			{Key: models.TaskStatusTodo, Name: "To Do", Category: models.WorkflowStateCategoryOpen, Position: 0},
+			{Key: models.TaskStatusDone, Name: "Done", Category: models.WorkflowStateCategoryClosed, Position: 1},
+		},
+		Transitions: []models.WorkflowTransition{
+			{FromState: models.TaskStatusTodo, ToState: models.TaskStatusDone},
+		},
+	})))
+
+	require.NoError(t, customFieldRepo.Create(ctx, models.NewCustomFieldDefinition(testOrg.ID, models.CustomFieldDefinitionRequest{
+		ProjectID: &testProject.ID,
+		Entity:    models.CustomFieldEntityTask,
+		Key:       "client_code",
+		Name:      "Client code",
+		Type:      models.CustomFieldTypeText,
+	})))
+
+	// Create tasks: kickoff has a subtask and blocks the report
+	newTask := func(title string, status models.TaskStatus, parent *models.Task, dueDays *int) *models.Task {
+		req := models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + title,
+			Status:    status,
+			Priority:  models.TaskPriorityHigh,
+			Tags:      []string{"engagement"},
+		}
+		if parent != nil {
+			req.ParentID = &parent.ID
+		}
+		if dueDays != nil {
+			dueDate := start.AddDate(0, 0, *dueDays)
+			req.DueDate = &dueDate
+		}
+		task := models.NewTask(req, testUser.ID)
+		task.CustomFields = models.CustomFieldValues{"client_code": "ACME"}
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	three, five := 3, 5
+
+	kickoff := newTask("Kickoff", models.TaskStatusDone, nil, &three)
+	kickoff.AssignedTo = &designer.ID
+	require.NoError(t, taskRepo.Update(ctx, kickoff))
+	for _, title := range []string{"Book room", "Send agenda"} {
+		require.NoError(t, checklistRepo.Create(ctx, models.NewChecklistItem(kickoff.ID, models.ChecklistItemRequest{Title: title})))
+	}
+
+	newTask("Minutes", models.TaskStatusTodo, kickoff, &five)
+	report := newTask("Report", models.TaskStatusTodo, nil, nil)
+	require.NoError(t, taskLinkRepo.Create(ctx, models.NewTaskLink(kickoff.ID, models.TaskLinkRequest{
+		TaskID: report.ID,
+		Type:   models.TaskLinkTypeBlocks,
+	}, testUser.ID)))
+
+	listTasks := func(projectID uuid.UUID) []models.Task {
+		tasks, _, err := taskRepo.List(ctx, models.TaskListParams{
+			ProjectID: &projectID,
+			SortBy:    "rank",
+			SortOrder: "asc",
+			PageSize:  100,
+		})
+		require.NoError(t, err)
+		require.Len(t, tasks, 3)
+		return tasks
+	}
+
+	var template *models.ProjectTemplate
+
+	t.Run("CreateTemplate", func(t *testing.T) {
+		var err error
+		template, err = templateService.Create(ctx, testProject.ID, models.ProjectTemplateRequest{
+			Name:          prefix + " Engagement",
+			AssigneeRoles: map[uuid.UUID]string{designer.ID: "designer"},
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, testOrg.ID, template.OrganizationID)
+		require.NotNil(t, template.Workflow)
+		assert.Equal(t, "Engagement", template.Workflow.Name)
+		assert.Equal(t, 3, template.TaskCount)
+		require.Len(t, template.Tasks, 3)
+
+		// Parents come before their subtasks
+		first, sub, last := template.Tasks[0], template.Tasks[1], template.Tasks[2]
+		assert.Equal(t, prefix+"Kickoff", first.Title)
+		assert.Nil(t, first.ParentID)
+		assert.Equal(t, &first.ID, sub.ParentID)
+		assert.Equal(t, prefix+"Report", last.Title)
+
+		assert.Equal(t, &three, first.DueOffsetDays)
+		assert.Equal(t, &five, sub.DueOffsetDays)
+		assert.Nil(t, last.DueOffsetDays)
+		require.NotNil(t, first.AssigneeRole)
+		assert.Equal(t, "designer", *first.AssigneeRole)
+		assert.Equal(t, []string{"engagement"}, first.Tags)
+		assert.Equal(t, []string{"Book room", "Send agenda"}, first.Checklist)
+
+		// Names are unique in the organization
+		_, err = templateService.Create(ctx, testProject.ID, models.ProjectTemplateRequest{Name: prefix + " Engagement"}, testUser.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectTemplateNameExists)
+
+		templates, err := templateService.List(ctx, testOrg.ID)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, 3, templates[0].TaskCount)
+		assert.Empty(t, templates[0].Tasks)
+	})
+
+	t.Run("InstantiateTemplate", func(t *testing.T) {
+		require.NotNil(t, template)
+		startDate := start.AddDate(0, 1, 0)
+
+		body, _ := json.Marshal(models.ProjectInstantiateRequest{
+			Name:      prefix + " Client A",
+			StartDate: startDate,
+			Assignees: map[string]uuid.UUID{"designer": testUser.ID},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/project-templates/:id/projects")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), template.ID.String())
+		c.Set("user_id", testUser.ID.String())
+
+		require.NoError(t, templateHandlers.Instantiate(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var result models.ProjectCopyResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, models.ProjectStatusPlanning, result.Project.Status)
+		assert.Equal(t, 1, result.Report.Count(models.ProjectCopyStepProject))
+		assert.Equal(t, 1, result.Report.Count(models.ProjectCopyStepWorkflow))
+		assert.Equal(t, 3, result.Report.Count(models.ProjectCopyStepTasks))
+		assert.Equal(t, 2, result.Report.Count(models.ProjectCopyStepChecklistItems))
+		assert.Empty(t, result.Report.Warnings)
+
+		tasks := listTasks(result.Project.ID)
+		first, sub := tasks[0], tasks[1]
+		assert.Equal(t, models.TaskStatusTodo, first.Status)
+		assert.Equal(t, &testUser.ID, first.AssignedTo)
+		assert.Equal(t, &first.ID, sub.ParentID)
+		require.NotNil(t, first.DueDate)
+		assert.True(t, startDate.AddDate(0, 0, 3).Equal(*first.DueDate))
+		assert.Nil(t, tasks[2].DueDate)
+
+		checklist, err := checklistRepo.ListByTask(ctx, first.ID)
+		require.NoError(t, err)
+		require.Len(t, checklist, 2)
+		assert.False(t, checklist[0].IsCompleted)
+
+		workflow, err := workflowRepo.GetByProject(ctx, result.Project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Engagement", workflow.Name)
+
+		// Roles without an assignee leave their tasks unassigned
+		unassigned, err := templateService.Instantiate(ctx, testOrg.ID, template.ID, models.ProjectInstantiateRequest{
+			Name:      prefix + " Client B",
+			StartDate: startDate,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Len(t, unassigned.Report.Warnings, 1)
+		assert.Nil(t, listTasks(unassigned.Project.ID)[0].AssignedTo)
+
+		// Nothing is created for an existing name or an unknown assignee
+		_, err = templateService.Instantiate(ctx, testOrg.ID, template.ID, models.ProjectInstantiateRequest{
+			Name:      prefix + " Client A",
+			StartDate: startDate,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNameExists)
+
+		_, err = templateService.Instantiate(ctx, testOrg.ID, template.ID, models.ProjectInstantiateRequest{
+			Name:      prefix + " Client C",
+			StartDate: startDate,
+			Assignees: map[string]uuid.UUID{"designer": uuid.New()},
+		}, testUser.ID)
+		assert.ErrorIs(t, err, projecttemplate.ErrInvalidAssignee)
+		_, err = projectRepo.GetByName(ctx, testOrg.ID, prefix+" Client C")
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+
+		// Templates of other organizations are not found
+		_, err = templateService.Instantiate(ctx, uuid.New(), template.ID, models.ProjectInstantiateRequest{
+			Name:      prefix + " Client D",
+			StartDate: startDate,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectTemplateNotFound)
+	})
+
+	t.Run("CloneProject", func(t *testing.T) {
+		startDate := start.AddDate(0, 0, 7)
+		result, err := templateService.Clone(ctx, testProject.ID, models.ProjectCloneRequest{
+			Name:      prefix + " Clone",
+			StartDate: &startDate,
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Report.Count(models.ProjectCopyStepWorkflow))
+		assert.Equal(t, 1, result.Report.Count(models.ProjectCopyStepCustomFields))
+		assert.Equal(t, 3, result.Report.Count(models.ProjectCopyStepTasks))
+		assert.Equal(t, 2, result.Report.Count(models.ProjectCopyStepChecklistItems))
+		assert.Equal(t, 1, result.Report.Count(models.ProjectCopyStepTaskLinks))
+		require.NotNil(t, result.Project.StartDate)
+		assert.True(t, startDate.Equal(*result.Project.StartDate))
+
+		// Clones keep their state and assignee, and move with the start date
+		tasks := listTasks(result.Project.ID)
+		first := tasks[0]
+		assert.Equal(t, models.TaskStatusDone, first.Status)
+		assert.Equal(t, &designer.ID, first.AssignedTo)
+		assert.Equal(t, "ACME", first.CustomFields["client_code"])
+		require.NotNil(t, first.DueDate)
+		assert.True(t, kickoff.DueDate.AddDate(0, 0, 7).Equal(*first.DueDate))
+
+		dependents, err := taskLinkRepo.ListDependents(ctx, first.ID)
+		require.NoError(t, err)
+		require.Len(t, dependents, 1)
+		assert.Equal(t, tasks[2].ID, dependents[0].ID)
+
+		definitions, err := customFieldRepo.List(ctx, testOrg.ID, &result.Project.ID, models.CustomFieldEntityTask)
+		require.NoError(t, err)
+		require.Len(t, definitions, 1)
+		assert.Equal(t, &result.Project.ID, definitions[0].ProjectID)
+
+		// The source project is unchanged
+		links, err := taskLinkRepo.ListByProject(ctx, testProject.ID)
+		require.NoError(t, err)
+		assert.Len(t, links, 1)
+	})
+
+	t.Run("DeleteTemplate", func(t *testing.T) {
+		require.NotNil(t, template)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), template.ID.String())
+		require.NoError(t, templateHandlers.Delete(c))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), template.ID.String())
+		err := templateHandlers.Get(c)
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+		}
+	})
+}
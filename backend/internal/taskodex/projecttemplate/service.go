@@ -0,0 +1,643 @@
+package projecttemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrTooManyTasks    = fmt.Errorf("projects can be copied with at most %d tasks", models.MaxProjectTemplateTasks)
+	ErrInvalidAssignee = errors.New("invalid assignee")
+	ErrInvalidWorkflow = errors.New("invalid template workflow")
+)
+
+// taskPageSize is the number of tasks read at once while copying a project
+const taskPageSize = 100
+
+// Service provides project templates and project cloning
+type Service interface {
+	// Create saves a project as a template of its organization: its workflow
+	// and its tasks, with their subtasks, tags, checklists, due dates
+	// relative to the start of the project and assignees replaced by roles
+	Create(ctx context.Context, projectID uuid.UUID, req models.ProjectTemplateRequest, userID uuid.UUID) (*models.ProjectTemplate, error)
+
+	// GetByID retrieves a project template of an organization, with its tasks
+	GetByID(ctx context.Context, organizationID, id uuid.UUID) (*models.ProjectTemplate, error)
+
+	// List retrieves the project templates of an organization
+	List(ctx context.Context, organizationID uuid.UUID) ([]models.ProjectTemplate, error)
+
+	// Delete deletes a project template. Projects created from it are kept.
+	Delete(ctx context.Context, organizationID, id uuid.UUID) error
+
+	// Instantiate creates a project from a template. Its tasks start in the
+	// initial state of the workflow and are due relative to the start date.
+	// Nothing is created if any step fails.
+	Instantiate(ctx context.Context, organizationID, id uuid.UUID, req models.ProjectInstantiateRequest, userID uuid.UUID) (*models.ProjectCopyResult, error)
+
+	// Clone creates a copy of a project with its workflow, project custom
+	// fields, tasks, checklists and task links. Nothing is created if any
+	// step fails.
+	Clone(ctx context.Context, projectID uuid.UUID, req models.ProjectCloneRequest, userID uuid.UUID) (*models.ProjectCopyResult, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	templateRepo    repository.ProjectTemplateRepository
+	projectRepo     repository.ProjectRepository
+	taskRepo        repository.TaskRepository
+	taskHistoryRepo repository.TaskHistoryRepository
+	checklistRepo   repository.ChecklistItemRepository
+	taskLinkRepo    repository.TaskLinkRepository
+	customFieldRepo repository.CustomFieldRepository
+	workflowRepo    repository.WorkflowRepository
+	userRepo        repository.UserRepository
+	txManager       repository.TxManager
+}
+
+// NewService creates a new project template service
+func NewService(
+	templateRepo repository.ProjectTemplateRepository,
+	projectRepo repository.ProjectRepository,
+	taskRepo repository.TaskRepository,
+	taskHistoryRepo repository.TaskHistoryRepository,
+	checklistRepo repository.ChecklistItemRepository,
+	taskLinkRepo repository.TaskLinkRepository,
+	customFieldRepo repository.CustomFieldRepository,
+	workflowRepo repository.WorkflowRepository,
+	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		templateRepo:    templateRepo,
+		projectRepo:     projectRepo,
+		taskRepo:        taskRepo,
+		taskHistoryRepo: taskHistoryRepo,
+		checklistRepo:   checklistRepo,
+		taskLinkRepo:    taskLinkRepo,
+		customFieldRepo: customFieldRepo,
+		workflowRepo:    workflowRepo,
+		userRepo:        userRepo,
+		txManager:       txManager,
+	}
+}
+
+// Create saves a project as a template
+func (s *serviceImpl) Create(ctx context.Context, projectID uuid.UUID, req models.ProjectTemplateRequest, userID uuid.UUID) (*models.ProjectTemplate, error) {
+	// Check if project exists
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.listTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.projectWorkflow(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	checklists, err := s.checklistRepo.ListByTasks(ctx, taskIDs(tasks))
+	if err != nil {
+		return nil, err
+	}
+
+	template := models.NewProjectTemplate(project.OrganizationID, req, userID)
+	if workflow != nil {
+		template.Workflow = &models.ProjectTemplateWorkflow{WorkflowRequest: models.WorkflowRequest{
+			Name:         workflow.Name,
+			InitialState: workflow.InitialState,
+			States:       workflow.States,
+			Transitions:  workflow.Transitions,
+		}}
+	}
+
+	start := day(projectStart(project))
+	templateIDs := make(map[uuid.UUID]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		templateTask := models.ProjectTemplateTask{
+			ID:             uuid.New(),
+			Position:       i,
+			Title:          task.Title,
+			Description:    task.Description,
+			Priority:       task.Priority,
+			EstimatedHours: task.EstimatedHours,
+			Tags:           task.Tags,
+			Checklist:      []string{},
+		}
+		templateIDs[task.ID] = templateTask.ID
+
+		if task.ParentID != nil {
+			if parentID, ok := templateIDs[*task.ParentID]; ok {
+				templateTask.ParentID = &parentID
+			}
+		}
+		if task.DueDate != nil {
+			offset := int(day(*task.DueDate).Sub(start).Hours() / 24)
+			templateTask.DueOffsetDays = &offset
+		}
+		if task.AssignedTo != nil {
+			if role, ok := req.AssigneeRoles[*task.AssignedTo]; ok {
+				templateTask.AssigneeRole = &role
+			}
+		}
+		for _, item := range checklists[task.ID] {
+			templateTask.Checklist = append(templateTask.Checklist, item.Title)
+		}
+
+		template.Tasks = append(template.Tasks, templateTask)
+	}
+
+	err = s.templateRepo.Create(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.templateRepo.GetByID(ctx, template.ID)
+}
+
+// GetByID retrieves a project template of an organization
+func (s *serviceImpl) GetByID(ctx context.Context, organizationID, id uuid.UUID) (*models.ProjectTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Templates of other organizations are not visible
+	if template.OrganizationID != organizationID {
+		return nil, repository.ErrProjectTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// List retrieves the project templates of an organization
+func (s *serviceImpl) List(ctx context.Context, organizationID uuid.UUID) ([]models.ProjectTemplate, error) {
+	return s.templateRepo.List(ctx, organizationID)
+}
+
+// Delete deletes a project template
+func (s *serviceImpl) Delete(ctx context.Context, organizationID, id uuid.UUID) error {
+	if _, err := s.GetByID(ctx, organizationID, id); err != nil {
+		return err
+	}
+
+	return s.templateRepo.Delete(ctx, id)
+}
+
+// Instantiate creates a project from a template
+func (s *serviceImpl) Instantiate(ctx context.Context, organizationID, id uuid.UUID, req models.ProjectInstantiateRequest, userID uuid.UUID) (*models.ProjectCopyResult, error) {
+	template, err := s.GetByID(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the assignees exist
+	for role, assignee := range req.Assignees {
+		if _, err := s.userRepo.GetByID(ctx, assignee); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, fmt.Errorf("%w: no user %s for role %q", ErrInvalidAssignee, assignee, role)
+			}
+			return nil, err
+		}
+	}
+
+	workflow := models.DefaultWorkflow()
+	if template.Workflow != nil {
+		workflow = models.NewWorkflow(uuid.Nil, template.Workflow.WorkflowRequest)
+		if err := workflow.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+		}
+	}
+
+	projectReq := models.ProjectRequest{
+		OrganizationID: organizationID,
+		Name:           req.Name,
+		Description:    template.Description,
+		Status:         req.Status,
+		StartDate:      &req.StartDate,
+	}
+	if req.Description != nil {
+		projectReq.Description = *req.Description
+	}
+	if projectReq.Status == "" {
+		projectReq.Status = models.ProjectStatusPlanning
+	}
+	project := models.NewProject(projectReq, userID)
+
+	progress := newProgress()
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.projectRepo.Create(ctx, project); err != nil {
+			return err
+		}
+		progress.done(models.ProjectCopyStepProject, 1)
+
+		if template.Workflow != nil {
+			workflow.ProjectID = &project.ID
+			if err := s.workflowRepo.Save(ctx, workflow); err != nil {
+				return err
+			}
+			progress.done(models.ProjectCopyStepWorkflow, 1)
+		}
+
+		taskIDs := make(map[uuid.UUID]uuid.UUID, len(template.Tasks))
+		unassigned := map[string]bool{}
+		for _, templateTask := range template.Tasks {
+			task := models.NewTask(models.TaskRequest{
+				ProjectID:      &project.ID,
+				Title:          templateTask.Title,
+				Description:    templateTask.Description,
+				Status:         workflow.InitialState,
+				Priority:       templateTask.Priority,
+				EstimatedHours: templateTask.EstimatedHours,
+				Tags:           templateTask.Tags,
+			}, userID)
+			if templateTask.ParentID != nil {
+				parentID := taskIDs[*templateTask.ParentID]
+				task.ParentID = &parentID
+			}
+			if templateTask.DueOffsetDays != nil {
+				dueDate := req.StartDate.AddDate(0, 0, *templateTask.DueOffsetDays)
+				task.DueDate = &dueDate
+			}
+			if templateTask.AssigneeRole != nil {
+				if assignee, ok := req.Assignees[*templateTask.AssigneeRole]; ok {
+					task.AssignedTo = &assignee
+				} else if !unassigned[*templateTask.AssigneeRole] {
+					unassigned[*templateTask.AssigneeRole] = true
+					progress.warn("no assignee for role %q; its tasks are unassigned", *templateTask.AssigneeRole)
+				}
+			}
+
+			if err := s.createTask(ctx, task, userID); err != nil {
+				return err
+			}
+			taskIDs[templateTask.ID] = task.ID
+		}
+		progress.done(models.ProjectCopyStepTasks, len(template.Tasks))
+
+		count := 0
+		for _, templateTask := range template.Tasks {
+			for position, title := range templateTask.Checklist {
+				position := position
+				item := models.NewChecklistItem(taskIDs[templateTask.ID], models.ChecklistItemRequest{
+					Title:    title,
+					Position: &position,
+				})
+				if err := s.checklistRepo.Create(ctx, item); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		progress.done(models.ProjectCopyStepChecklistItems, count)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.result(ctx, project.ID, progress)
+}
+
+// Clone creates a copy of a project
+func (s *serviceImpl) Clone(ctx context.Context, projectID uuid.UUID, req models.ProjectCloneRequest, userID uuid.UUID) (*models.ProjectCopyResult, error) {
+	// Check if project exists
+	source, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.listTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.projectWorkflow(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	checklists, err := s.checklistRepo.ListByTasks(ctx, taskIDs(tasks))
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.taskLinkRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the project's own custom fields are copied; the organization's
+	// apply to the clone as well
+	var customFields []models.CustomFieldDefinition
+	for _, entity := range []models.CustomFieldEntity{models.CustomFieldEntityProject, models.CustomFieldEntityTask} {
+		definitions, err := s.customFieldRepo.List(ctx, source.OrganizationID, &projectID, entity)
+		if err != nil {
+			return nil, err
+		}
+		for _, definition := range definitions {
+			if definition.ProjectID != nil {
+				customFields = append(customFields, definition)
+			}
+		}
+	}
+
+	// Dates move with the start date of the clone
+	var shift time.Duration
+	if req.StartDate != nil {
+		shift = req.StartDate.Sub(projectStart(source))
+	}
+	shifted := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		moved := t.Add(shift)
+		return &moved
+	}
+
+	project := models.NewProject(models.ProjectRequest{
+		OrganizationID: source.OrganizationID,
+		Name:           req.Name,
+		Description:    source.Description,
+		Status:         source.Status,
+		StartDate:      shifted(source.StartDate),
+		EndDate:        shifted(source.EndDate),
+	}, userID)
+	project.CustomFields = source.CustomFields
+
+	progress := newProgress()
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.projectRepo.Create(ctx, project); err != nil {
+			return err
+		}
+		progress.done(models.ProjectCopyStepProject, 1)
+
+		if workflow != nil {
+			clone := models.NewWorkflow(project.ID, models.WorkflowRequest{
+				Name:         workflow.Name,
+				InitialState: workflow.InitialState,
+				States:       workflow.States,
+				Transitions:  workflow.Transitions,
+			})
+			if err := s.workflowRepo.Save(ctx, clone); err != nil {
+				return err
+			}
+			progress.done(models.ProjectCopyStepWorkflow, 1)
+		}
+
+		for _, definition := range customFields {
+			clone := definition
+			clone.ID = uuid.New()
+			clone.ProjectID = &project.ID
+			clone.CreatedAt, clone.UpdatedAt = project.CreatedAt, project.CreatedAt
+			if err := s.customFieldRepo.Create(ctx, &clone); err != nil {
+				return err
+			}
+		}
+		progress.done(models.ProjectCopyStepCustomFields, len(customFields))
+
+		// Clones keep their state, ranks and assignees; their activity, time
+		// entries and sprints are not copied
+		cloneIDs := make(map[uuid.UUID]uuid.UUID, len(tasks))
+		for _, original := range tasks {
+			task := models.NewTask(models.TaskRequest{
+				ProjectID:      &project.ID,
+				Title:          original.Title,
+				Description:    original.Description,
+				Status:         original.Status,
+				Priority:       original.Priority,
+				DueDate:        shifted(original.DueDate),
+				StartDate:      shifted(original.StartDate),
+				DurationDays:   original.DurationDays,
+				AssignedTo:     original.AssignedTo,
+				EstimatedHours: original.EstimatedHours,
+				Tags:           original.Tags,
+			}, userID)
+			task.Rank = original.Rank
+			task.CustomFields = original.CustomFields
+			if original.ParentID != nil {
+				if parentID, ok := cloneIDs[*original.ParentID]; ok {
+					task.ParentID = &parentID
+				}
+			}
+
+			if err := s.createTask(ctx, task, userID); err != nil {
+				return err
+			}
+			cloneIDs[original.ID] = task.ID
+		}
+		progress.done(models.ProjectCopyStepTasks, len(tasks))
+
+		count := 0
+		for _, original := range tasks {
+			for _, item := range checklists[original.ID] {
+				position := item.Position
+				clone := models.NewChecklistItem(cloneIDs[original.ID], models.ChecklistItemRequest{
+					Title:       item.Title,
+					IsCompleted: item.IsCompleted,
+					Position:    &position,
+				})
+				if err := s.checklistRepo.Create(ctx, clone); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		progress.done(models.ProjectCopyStepChecklistItems, count)
+
+		for _, link := range links {
+			clone := models.NewTaskLink(cloneIDs[link.SourceTaskID], models.TaskLinkRequest{
+				TaskID: cloneIDs[link.TargetTaskID],
+				Type:   link.Type,
+			}, userID)
+			if err := s.taskLinkRepo.Create(ctx, clone); err != nil {
+				return err
+			}
+		}
+		progress.done(models.ProjectCopyStepTaskLinks, len(links))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.result(ctx, project.ID, progress)
+}
+
+// createTask creates a copied task and records its creation
+func (s *serviceImpl) createTask(ctx context.Context, task *models.Task, userID uuid.UUID) error {
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return err
+	}
+	return s.taskHistoryRepo.Create(ctx, models.NewTaskCreatedHistory(task.ID, userID))
+}
+
+// result retrieves the created project and completes the report
+func (s *serviceImpl) result(ctx context.Context, projectID uuid.UUID, progress *progress) (*models.ProjectCopyResult, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProjectCopyResult{
+		Project: project.ToResponse(),
+		Report:  progress.finish(),
+	}, nil
+}
+
+// listTasks retrieves the tasks of a project in rank order, each parent
+// before its subtasks
+func (s *serviceImpl) listTasks(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
+	params := models.TaskListParams{
+		ProjectID: &projectID,
+		SortBy:    "rank",
+		SortOrder: "asc",
+		PageSize:  taskPageSize,
+	}
+
+	var tasks []models.Task
+	for params.Page = 1; ; params.Page++ {
+		page, _, err := s.taskRepo.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(tasks) > models.MaxProjectTemplateTasks {
+			return nil, ErrTooManyTasks
+		}
+		if len(page) < taskPageSize {
+			break
+		}
+	}
+
+	return orderTasks(tasks), nil
+}
+
+// projectWorkflow retrieves the workflow a project defines, or nil if it
+// uses the default workflow
+func (s *serviceImpl) projectWorkflow(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error) {
+	workflow, err := s.workflowRepo.GetByProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkflowNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return workflow, nil
+}
+
+// orderTasks orders tasks depth first, so that each parent comes before its
+// subtasks. Siblings keep their order. Tasks whose parent is not among the
+// tasks are treated as top-level tasks.
+func orderTasks(tasks []models.Task) []models.Task {
+	present := make(map[uuid.UUID]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.ID] = true
+	}
+
+	children := make(map[uuid.UUID][]int)
+	var roots []int
+	for i, task := range tasks {
+		if task.ParentID != nil && present[*task.ParentID] {
+			children[*task.ParentID] = append(children[*task.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	ordered := make([]models.Task, 0, len(tasks))
+	var visit func(i int)
+	visit = func(i int) {
+		ordered = append(ordered, tasks[i])
+		for _, child := range children[tasks[i].ID] {
+			visit(child)
+		}
+	}
+	for _, i := range roots {
+		visit(i)
+	}
+
+	return ordered
+}
+
+// taskIDs returns the IDs of tasks
+func taskIDs(tasks []models.Task) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// projectStart returns the start date of a project, or when it was created
+// if it has none
+func projectStart(project *models.Project) time.Time {
+	if project.StartDate != nil {
+		return *project.StartDate
+	}
+	return project.CreatedAt
+}
+
+// day returns the start of the UTC day of t
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// progress records the steps of a project copy as they complete
+type progress struct {
+	report    models.ProjectCopyReport
+	started   time.Time
+	stepStart time.Time
+}
+
+// newProgress starts recording a project copy
+func newProgress() *progress {
+	now := time.Now()
+	return &progress{
+		report: models.ProjectCopyReport{
+			Steps:    []models.ProjectCopyStep{},
+			Warnings: []string{},
+		},
+		started:   now,
+		stepStart: now,
+	}
+}
+
+// done records that a step has completed, having copied count records
+func (p *progress) done(step string, count int) {
+	now := time.Now()
+	p.report.Steps = append(p.report.Steps, models.ProjectCopyStep{
+		Step:       step,
+		Count:      count,
+		DurationMS: now.Sub(p.stepStart).Milliseconds(),
+	})
+	p.stepStart = now
+}
+
+// warn records a problem that did not stop the copy
+func (p *progress) warn(format string, args ...interface{}) {
+	p.report.Warnings = append(p.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// finish returns the report of the copy. Warnings are sorted so that reports
+// are stable.
+func (p *progress) finish() models.ProjectCopyReport {
+	sort.Strings(p.report.Warnings)
+	p.report.DurationMS = time.Since(p.started).Milliseconds()
+	return p.report
+}
@@ -0,0 +1,153 @@
+package recurrence
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for recurring tasks
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get handles retrieving the recurrence of a task
+func (h *Handlers) Get(c echo.Context) error {
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get recurrence
+	response, err := h.service.Get(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve recurrence")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Set handles making a task recur or changing its recurrence
+func (h *Handlers) Set(c echo.Context) error {
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse request body
+	var req models.TaskRecurrenceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Set recurrence
+	response, err := h.service.Set(c.Request().Context(), id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to update recurrence")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Delete handles stopping the recurrence of a task
+func (h *Handlers) Delete(c echo.Context) error {
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Delete recurrence
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		return h.handleError(err, "Failed to delete recurrence")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Skip handles skipping an occurrence of a task's recurrence
+func (h *Handlers) Skip(c echo.Context) error {
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse request body
+	var req models.SkipOccurrenceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Skip occurrence
+	response, err := h.service.Skip(c.Request().Context(), id, req.OccursAt)
+	if err != nil {
+		return h.handleError(err, "Failed to skip occurrence")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// handleError maps errors from recurrence operations to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	}
+	if errors.Is(err, repository.ErrTaskRecurrenceNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Task does not recur")
+	}
+	if errors.Is(err, models.ErrInvalidRecurrenceRule) || errors.Is(err, ErrInvalidRecurrence) ||
+		errors.Is(err, ErrNotAnOccurrence) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, ErrOccurrenceHandled) || errors.Is(err, repository.ErrProjectArchived) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the recurrence routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	recurrenceGroup := g.Group("/tasks/:id/recurrence")
+
+	// Routes that require task:read permission
+	recurrenceGroup.GET("", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+
+	// Routes that require task:write permission
+	recurrenceGroup.PUT("", h.Set, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	recurrenceGroup.DELETE("", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	recurrenceGroup.POST("/skip", h.Skip, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
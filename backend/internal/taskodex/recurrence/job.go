@@ -0,0 +1,38 @@
+package recurrence
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+)
+
+// Job generates the due instances of recurring tasks when run by a
+// jobs.Scheduler. Recurrences are claimed with row locks, so a run that is
+// interrupted and retried never generates an instance twice.
+type Job struct {
+	service Service
+}
+
+// NewJob creates a new Job
+func NewJob(service Service) *Job {
+	return &Job{service: service}
+}
+
+// Name returns the name of the job
+func (j *Job) Name() string {
+	return "task_recurrences"
+}
+
+// Run generates the instances due at now
+func (j *Job) Run(ctx context.Context, now time.Time) error {
+	generated, err := j.service.RunDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	if generated > 0 {
+		logger.FromContext(ctx).Info("generated recurring tasks", slog.Int("count", generated))
+	}
+	return nil
+}
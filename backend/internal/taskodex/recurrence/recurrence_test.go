@@ -0,0 +1,266 @@
+package recurrence_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/recurrence"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringTasks(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	checklistRepo := repository.NewPostgresChecklistItemRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+
+	// Create service
+	recurrenceService := recurrence.NewService(
+		repository.NewPostgresTaskRecurrenceRepository(tdb.DB),
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		checklistRepo,
+		workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB)),
+		notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), userRepo),
+		repository.NewTxManager(tdb.DB),
+	)
+
+	// Every Monday and Thursday at 09:00, four times, starting on a Monday
+	monday := time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+	occurrence := func(days int) time.Time {
+		return monday.AddDate(0, 0, days)
+	}
+
+	t.Run("Schedule", func(t *testing.T) {
+		template := models.NewTask(models.TaskRequest{
+			ProjectID:  &testProject.ID,
+			Title:      prefix + "Release checklist",
+			Status:     models.TaskStatusDone,
+			Priority:   models.TaskPriorityHigh,
+			DueDate:    &monday,
+			AssignedTo: &testUser.ID,
+			Tags:       []string{"release", "chore"},
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, template))
+		require.NoError(t, checklistRepo.Create(ctx, models.NewChecklistItem(template.ID, models.ChecklistItemRequest{
+			Title:       "Tag the release",
+			IsCompleted: true,
+		})))
+
+		response, err := recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule: "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4",
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RecurrenceModeSchedule, response.Mode)
+		assert.Equal(t, 1, response.OccurrenceCount)
+		require.Len(t, response.Upcoming, 3)
+		assert.True(t, occurrence(3).Equal(response.Upcoming[0]))
+		assert.True(t, occurrence(7).Equal(response.Upcoming[1]))
+		assert.True(t, occurrence(10).Equal(response.Upcoming[2]))
+
+		// Nothing is due before the first occurrence
+		generated, err := recurrenceService.RunDue(ctx, occurrence(3).Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, generated)
+
+		// Concurrent schedulers generate the occurrence once
+		var wg sync.WaitGroup
+		results := make([]int, 3)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = recurrenceService.RunDue(ctx, occurrence(3))
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 1, results[0]+results[1]+results[2])
+
+		// The instance copies the template and starts open
+		response, err = recurrenceService.Get(ctx, template.ID)
+		require.NoError(t, err)
+		require.NotNil(t, response.LastTaskID)
+		instance, err := taskRepo.GetByID(ctx, *response.LastTaskID)
+		require.NoError(t, err)
+		assert.Equal(t, template.Title, instance.Title)
+		assert.Equal(t, models.TaskStatusTodo, instance.Status)
+		assert.Equal(t, testUser.ID, *instance.AssignedTo)
+		assert.ElementsMatch(t, []string{"release", "chore"}, instance.Tags)
+		assert.True(t, occurrence(3).Equal(*instance.DueDate))
+
+		items, err := checklistRepo.ListByTask(ctx, instance.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "Tag the release", items[0].Title)
+		assert.False(t, items[0].IsCompleted)
+
+		// Editing the instance does not affect the series, which can be read
+		// from any of its tasks
+		instance.Title = prefix + "Release 1.2 checklist"
+		require.NoError(t, taskRepo.Update(ctx, instance))
+		response, err = recurrenceService.Get(ctx, instance.ID)
+		require.NoError(t, err)
+		assert.Equal(t, template.ID, response.TaskID)
+
+		// Skip the next Monday
+		response, err = recurrenceService.Skip(ctx, instance.ID, occurrence(7))
+		require.NoError(t, err)
+		require.Len(t, response.Skipped, 1)
+		assert.True(t, occurrence(7).Equal(response.Skipped[0]))
+		require.Len(t, response.Upcoming, 1)
+		assert.True(t, occurrence(10).Equal(response.Upcoming[0]))
+
+		_, err = recurrenceService.Skip(ctx, template.ID, occurrence(7))
+		assert.ErrorIs(t, err, recurrence.ErrOccurrenceHandled)
+		_, err = recurrenceService.Skip(ctx, template.ID, occurrence(3))
+		assert.ErrorIs(t, err, recurrence.ErrOccurrenceHandled)
+		_, err = recurrenceService.Skip(ctx, template.ID, occurrence(8))
+		assert.ErrorIs(t, err, recurrence.ErrNotAnOccurrence)
+
+		// The skipped occurrence is passed over and the series ends at COUNT
+		generated, err = recurrenceService.RunDue(ctx, occurrence(10))
+		require.NoError(t, err)
+		assert.Equal(t, 1, generated)
+
+		response, err = recurrenceService.Get(ctx, template.ID)
+		require.NoError(t, err)
+		assert.True(t, response.Finished)
+		assert.Nil(t, response.NextOccurrence)
+		assert.Equal(t, 4, response.OccurrenceCount)
+	})
+
+	t.Run("LeadDaysAndMissedOccurrences", func(t *testing.T) {
+		template := models.NewTask(models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + "On-call handover",
+			Status:    models.TaskStatusTodo,
+			Priority:  models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, template))
+
+		_, err := recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule:     "FREQ=DAILY",
+			StartsAt: &monday,
+			LeadDays: 1,
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		// The instance due on Tuesday is generated a day ahead
+		generated, err := recurrenceService.RunDue(ctx, occurrence(0))
+		require.NoError(t, err)
+		assert.Equal(t, 1, generated)
+
+		// After an outage, only the latest due occurrence is generated
+		generated, err = recurrenceService.RunDue(ctx, occurrence(5))
+		require.NoError(t, err)
+		assert.Equal(t, 1, generated)
+
+		response, err := recurrenceService.Get(ctx, template.ID)
+		require.NoError(t, err)
+		instance, err := taskRepo.GetByID(ctx, *response.LastTaskID)
+		require.NoError(t, err)
+		assert.True(t, occurrence(6).Equal(*instance.DueDate))
+		assert.True(t, occurrence(7).Equal(*response.NextOccurrence))
+	})
+
+	t.Run("Completion", func(t *testing.T) {
+		template := models.NewTask(models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + "Water the plants",
+			Status:    models.TaskStatusTodo,
+			Priority:  models.TaskPriorityLow,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, template))
+
+		start := time.Date(2020, time.January, 1, 9, 0, 0, 0, time.UTC)
+		response, err := recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule:     "FREQ=DAILY;INTERVAL=3",
+			Mode:     models.RecurrenceModeCompletion,
+			StartsAt: &start,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Nil(t, response.NextOccurrence)
+
+		// Nothing is generated while the latest instance is open
+		now := time.Now().UTC()
+		generated, err := recurrenceService.RunDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, generated)
+
+		// Closing it generates the next instance, due three days after the
+		// day it was closed
+		template.Status = models.TaskStatusDone
+		require.NoError(t, taskRepo.Update(ctx, template))
+
+		generated, err = recurrenceService.RunDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, generated)
+
+		response, err = recurrenceService.Get(ctx, template.ID)
+		require.NoError(t, err)
+		instance, err := taskRepo.GetByID(ctx, *response.LastTaskID)
+		require.NoError(t, err)
+		expected := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC).AddDate(0, 0, 3)
+		assert.True(t, expected.Equal(*instance.DueDate), "due %s, expected %s", instance.DueDate, expected)
+
+		generated, err = recurrenceService.RunDue(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, generated)
+	})
+
+	t.Run("InvalidRecurrence", func(t *testing.T) {
+		template := models.NewTask(models.TaskRequest{
+			Title:    prefix + "Invalid",
+			Status:   models.TaskStatusTodo,
+			Priority: models.TaskPriorityLow,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, template))
+
+		_, err := recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule:     "FREQ=YEARLY",
+			StartsAt: &monday,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, models.ErrInvalidRecurrenceRule)
+
+		_, err = recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule:     "FREQ=DAILY;COUNT=2;UNTIL=20300201",
+			StartsAt: &monday,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, models.ErrInvalidRecurrenceRule)
+
+		_, err = recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule: "FREQ=DAILY",
+		}, testUser.ID)
+		assert.ErrorIs(t, err, recurrence.ErrInvalidRecurrence)
+
+		_, err = recurrenceService.Set(ctx, template.ID, models.TaskRecurrenceRequest{
+			Rule:     "FREQ=DAILY",
+			StartsAt: &monday,
+			Timezone: "Mars/Olympus_Mons",
+		}, testUser.ID)
+		assert.ErrorIs(t, err, recurrence.ErrInvalidRecurrence)
+
+		_, err = recurrenceService.Get(ctx, template.ID)
+		assert.ErrorIs(t, err, repository.ErrTaskRecurrenceNotFound)
+	})
+}
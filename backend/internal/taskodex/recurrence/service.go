@@ -0,0 +1,451 @@
+package recurrence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+	ErrNotAnOccurrence   = errors.New("time is not an occurrence of the recurrence")
+	ErrOccurrenceHandled = errors.New("occurrence has already been generated or skipped")
+)
+
+// upcomingOccurrences is the number of upcoming occurrences listed in a
+// recurrence response
+const upcomingOccurrences = 5
+
+// Service provides recurring task functionality
+type Service interface {
+	// Get retrieves the recurrence a task is the template or an instance of
+	Get(ctx context.Context, taskID uuid.UUID) (*models.TaskRecurrenceResponse, error)
+
+	// Set makes a task recur, or changes the recurrence it is the template or
+	// an instance of. Changes apply to the occurrences not generated yet.
+	Set(ctx context.Context, taskID uuid.UUID, req models.TaskRecurrenceRequest, userID uuid.UUID) (*models.TaskRecurrenceResponse, error)
+
+	// Delete stops the recurrence a task is the template or an instance of.
+	// Generated tasks are kept.
+	Delete(ctx context.Context, taskID uuid.UUID) error
+
+	// Skip skips a future occurrence of a recurrence on a schedule, so no
+	// task is generated for it
+	Skip(ctx context.Context, taskID uuid.UUID, occursAt time.Time) (*models.TaskRecurrenceResponse, error)
+
+	// RunDue generates the instances due at now and returns how many were
+	// generated. It is safe to run concurrently from several service instances.
+	RunDue(ctx context.Context, now time.Time) (int, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	recurrenceRepo  repository.TaskRecurrenceRepository
+	taskRepo        repository.TaskRepository
+	taskHistoryRepo repository.TaskHistoryRepository
+	checklistRepo   repository.ChecklistItemRepository
+	workflowSvc     workflow.Service
+	notificationSvc notification.Service
+	txManager       repository.TxManager
+}
+
+// NewService creates a new recurrence service
+func NewService(
+	recurrenceRepo repository.TaskRecurrenceRepository,
+	taskRepo repository.TaskRepository,
+	taskHistoryRepo repository.TaskHistoryRepository,
+	checklistRepo repository.ChecklistItemRepository,
+	workflowSvc workflow.Service,
+	notificationSvc notification.Service,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		recurrenceRepo:  recurrenceRepo,
+		taskRepo:        taskRepo,
+		taskHistoryRepo: taskHistoryRepo,
+		checklistRepo:   checklistRepo,
+		workflowSvc:     workflowSvc,
+		notificationSvc: notificationSvc,
+		txManager:       txManager,
+	}
+}
+
+// Get retrieves the recurrence of a task
+func (s *serviceImpl) Get(ctx context.Context, taskID uuid.UUID) (*models.TaskRecurrenceResponse, error) {
+	recurrence, err := s.recurrenceRepo.GetByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(ctx, recurrence)
+}
+
+// Set makes a task recur or changes its recurrence
+func (s *serviceImpl) Set(ctx context.Context, taskID uuid.UUID, req models.TaskRecurrenceRequest, userID uuid.UUID) (*models.TaskRecurrenceResponse, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tasks of archived projects are read-only, and do not recur until the
+	// project is unarchived
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	// Validate the rule and store it in canonical form
+	rule, err := models.ParseRecurrenceRule(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	req.Rule = rule.String()
+
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, req.Timezone)
+		}
+	}
+
+	recurrence, err := s.recurrenceRepo.GetByTask(ctx, taskID)
+	switch {
+	case err == nil:
+		// Occurrences are rescheduled from now on; those already generated or
+		// skipped are recorded and not generated again
+		startsAt := recurrence.StartsAt
+		if req.StartsAt != nil {
+			startsAt = *req.StartsAt
+		}
+		recurrence.Apply(req, startsAt)
+		schedule(recurrence, rule, time.Now())
+
+		if err := s.recurrenceRepo.Update(ctx, recurrence); err != nil {
+			return nil, err
+		}
+
+	case errors.Is(err, repository.ErrTaskRecurrenceNotFound):
+		// The task is the first occurrence, at its due date by default
+		startsAt := req.StartsAt
+		if startsAt == nil {
+			startsAt = task.DueDate
+		}
+		if startsAt == nil {
+			return nil, fmt.Errorf("%w: starts_at is required for a task without a due date", ErrInvalidRecurrence)
+		}
+
+		recurrence = models.NewTaskRecurrence(task.ID, req, *startsAt, userID)
+		schedule(recurrence, rule, recurrence.StartsAt)
+
+		err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+			if err := s.recurrenceRepo.Create(ctx, recurrence); err != nil {
+				return err
+			}
+			return s.recurrenceRepo.CreateOccurrence(ctx, models.NewTaskOccurrence(
+				recurrence.ID, recurrence.StartsAt, &task.ID, models.TaskOccurrenceStatusGenerated,
+			))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, err
+	}
+
+	return s.toResponse(ctx, recurrence)
+}
+
+// schedule sets the next occurrence of a recurrence on a schedule to the
+// first one after a time. Recurrences on completion wait for their latest
+// instance to be closed instead.
+func schedule(recurrence *models.TaskRecurrence, rule *models.RecurrenceRule, after time.Time) {
+	recurrence.NextOccurrence = nil
+	recurrence.Finished = false
+	if recurrence.Mode == models.RecurrenceModeSchedule {
+		recurrence.NextOccurrence = recurrence.Following(rule, after)
+		recurrence.Finished = recurrence.NextOccurrence == nil
+	}
+}
+
+// Delete stops the recurrence of a task
+func (s *serviceImpl) Delete(ctx context.Context, taskID uuid.UUID) error {
+	recurrence, err := s.recurrenceRepo.GetByTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	return s.recurrenceRepo.Delete(ctx, recurrence.ID)
+}
+
+// Skip skips a future occurrence of a recurrence
+func (s *serviceImpl) Skip(ctx context.Context, taskID uuid.UUID, occursAt time.Time) (*models.TaskRecurrenceResponse, error) {
+	recurrence, err := s.recurrenceRepo.GetByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if recurrence.Mode != models.RecurrenceModeSchedule {
+		return nil, fmt.Errorf("%w: only occurrences on a schedule can be skipped", ErrInvalidRecurrence)
+	}
+
+	rule, err := models.ParseRecurrenceRule(recurrence.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Includes(recurrence.StartsAt.In(recurrence.Location()), occursAt) {
+		return nil, ErrNotAnOccurrence
+	}
+
+	// Occurrences before the next one have been generated or missed
+	if recurrence.NextOccurrence == nil || occursAt.Before(*recurrence.NextOccurrence) {
+		return nil, ErrOccurrenceHandled
+	}
+	_, err = s.recurrenceRepo.GetOccurrence(ctx, recurrence.ID, occursAt)
+	if err == nil {
+		return nil, ErrOccurrenceHandled
+	}
+	if !errors.Is(err, repository.ErrTaskOccurrenceNotFound) {
+		return nil, err
+	}
+
+	err = s.recurrenceRepo.CreateOccurrence(ctx, models.NewTaskOccurrence(
+		recurrence.ID, occursAt, nil, models.TaskOccurrenceStatusSkipped,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(ctx, recurrence)
+}
+
+// toResponse converts a recurrence to a response listing its upcoming and
+// skipped occurrences
+func (s *serviceImpl) toResponse(ctx context.Context, recurrence *models.TaskRecurrence) (*models.TaskRecurrenceResponse, error) {
+	response := &models.TaskRecurrenceResponse{
+		TaskRecurrence: *recurrence,
+		Upcoming:       []time.Time{},
+		Skipped:        []time.Time{},
+	}
+	if recurrence.NextOccurrence == nil {
+		return response, nil
+	}
+
+	occurrences, err := s.recurrenceRepo.ListOccurrences(ctx, recurrence.ID, *recurrence.NextOccurrence)
+	if err != nil {
+		return nil, err
+	}
+	handled := make(map[int64]bool, len(occurrences))
+	for _, occurrence := range occurrences {
+		handled[occurrence.OccursAt.Unix()] = true
+		if occurrence.Status == models.TaskOccurrenceStatusSkipped {
+			response.Skipped = append(response.Skipped, occurrence.OccursAt)
+		}
+	}
+
+	rule, err := models.ParseRecurrenceRule(recurrence.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the occurrences on a copy, so the count is not changed
+	walk := *recurrence
+	next := walk.NextOccurrence
+	for i := 0; next != nil && len(response.Upcoming) < upcomingOccurrences && i < len(occurrences)+upcomingOccurrences; i++ {
+		if !handled[next.Unix()] {
+			response.Upcoming = append(response.Upcoming, *next)
+		}
+		walk.OccurrenceCount++
+		next = walk.Following(rule, *next)
+	}
+
+	return response, nil
+}
+
+// RunDue generates the instances due at now. Each recurrence is claimed and
+// handled in its own transaction; a recurrence that fails is logged and
+// retried on the next run.
+func (s *serviceImpl) RunDue(ctx context.Context, now time.Time) (int, error) {
+	log := logger.FromContext(ctx)
+
+	generated := 0
+	handled := []uuid.UUID{}
+	for {
+		var recurrenceID *uuid.UUID
+		var tasks []*models.Task
+		err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+			recurrence, completedAt, err := s.recurrenceRepo.ClaimDue(ctx, now, handled)
+			if err != nil || recurrence == nil {
+				return err
+			}
+			recurrenceID = &recurrence.ID
+
+			tasks, err = s.process(ctx, recurrence, completedAt, now)
+			return err
+		})
+		if recurrenceID == nil {
+			return generated, err
+		}
+
+		// Each recurrence is handled at most once per run
+		handled = append(handled, *recurrenceID)
+		if err != nil {
+			log.Error("failed to generate recurring task",
+				slog.String("recurrence_id", recurrenceID.String()),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		generated += len(tasks)
+		for _, task := range tasks {
+			s.notifyAssignee(ctx, task)
+		}
+	}
+}
+
+// process generates the due instances of a claimed recurrence and advances it
+func (s *serviceImpl) process(ctx context.Context, recurrence *models.TaskRecurrence, completedAt *time.Time, now time.Time) ([]*models.Task, error) {
+	rule, err := models.ParseRecurrenceRule(recurrence.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*models.Task{}
+
+	// On completion, the next instance is generated right away, due at the
+	// first occurrence after the latest instance was closed
+	if recurrence.Mode == models.RecurrenceModeCompletion {
+		if completedAt == nil {
+			completedAt = &now
+		}
+		occursAt := recurrence.FollowingCompletion(rule, *completedAt)
+		if occursAt == nil {
+			recurrence.Finished = true
+			recurrence.LastTaskID = nil
+			return tasks, s.recurrenceRepo.Update(ctx, recurrence)
+		}
+
+		task, err := s.generate(ctx, recurrence, *occursAt)
+		if err != nil {
+			return nil, err
+		}
+		recurrence.OccurrenceCount++
+		recurrence.LastTaskID = &task.ID
+		return append(tasks, task), s.recurrenceRepo.Update(ctx, recurrence)
+	}
+
+	for recurrence.NextOccurrence != nil && !recurrence.GenerateAt(*recurrence.NextOccurrence).After(now) {
+		occursAt := *recurrence.NextOccurrence
+		recurrence.OccurrenceCount++
+		recurrence.NextOccurrence = recurrence.Following(rule, occursAt)
+		recurrence.Finished = recurrence.NextOccurrence == nil
+
+		// Skipped occurrences, and those generated before the rule changed,
+		// are not generated again
+		_, err := s.recurrenceRepo.GetOccurrence(ctx, recurrence.ID, occursAt)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrTaskOccurrenceNotFound) {
+			return nil, err
+		}
+
+		// After an outage, only the latest due occurrence is generated
+		if recurrence.NextOccurrence != nil && !recurrence.GenerateAt(*recurrence.NextOccurrence).After(now) {
+			continue
+		}
+
+		task, err := s.generate(ctx, recurrence, occursAt)
+		if err != nil {
+			return nil, err
+		}
+		recurrence.LastTaskID = &task.ID
+		tasks = append(tasks, task)
+	}
+
+	return tasks, s.recurrenceRepo.Update(ctx, recurrence)
+}
+
+// generate creates the instance of an occurrence from the recurrence's
+// template: its fields, tags and checklist are copied, it starts in the
+// initial state of its workflow and is due at the occurrence
+func (s *serviceImpl) generate(ctx context.Context, recurrence *models.TaskRecurrence, occursAt time.Time) (*models.Task, error) {
+	template, err := s.taskRepo.GetByID(ctx, recurrence.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	checklist, err := s.checklistRepo.ListByTask(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	task := models.NewTask(models.TaskRequest{
+		ProjectID:      template.ProjectID,
+		ParentID:       template.ParentID,
+		Title:          template.Title,
+		Description:    template.Description,
+		Priority:       template.Priority,
+		DueDate:        &occursAt,
+		AssignedTo:     template.AssignedTo,
+		EstimatedHours: template.EstimatedHours,
+		Tags:           template.Tags,
+	}, recurrence.CreatedBy)
+	task.CustomFields = template.CustomFields
+
+	workflow, err := s.workflowSvc.ForTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	task.Status = workflow.InitialState
+
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := s.taskHistoryRepo.Create(ctx, models.NewTaskCreatedHistory(task.ID, recurrence.CreatedBy)); err != nil {
+		return nil, err
+	}
+
+	for _, item := range checklist {
+		position := item.Position
+		err := s.checklistRepo.Create(ctx, models.NewChecklistItem(task.ID, models.ChecklistItemRequest{
+			Title:    item.Title,
+			Position: &position,
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.recurrenceRepo.CreateOccurrence(ctx, models.NewTaskOccurrence(
+		recurrence.ID, occursAt, &task.ID, models.TaskOccurrenceStatusGenerated,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// notifyAssignee notifies the assignee of a generated task
+func (s *serviceImpl) notifyAssignee(ctx context.Context, task *models.Task) {
+	if task.AssignedTo == nil {
+		return
+	}
+
+	if err := s.notificationSvc.NotifyTaskAssigned(ctx, task); err != nil {
+		// Log the error but don't fail the operation
+		logger.FromContext(ctx).Error("failed to send task assignment notification",
+			slog.String("task_id", task.ID.String()),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,190 @@
+// Package reminder notifies the assignees of tasks that are due soon or
+// overdue. Reminders are sent from a fixed hour of the assignee's day, in
+// their time zone, and recorded so each is sent once.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+)
+
+// NotifyHour is the hour of the assignee's day from which reminders are sent
+const NotifyHour = 9
+
+// DefaultThresholds are the numbers of days before the due date at which a
+// task is due soon by default
+var DefaultThresholds = []int{1, 3}
+
+// OverdueLookback is how long after the due date an overdue task is still
+// reminded of, so that old tasks are not reminded of when the job first runs
+const OverdueLookback = 7 * 24 * time.Hour
+
+// DueSoonJob notifies assignees that their tasks are due soon. A task is
+// reminded of once per threshold, when it is due within that many days and
+// no smaller threshold.
+type DueSoonJob struct {
+	reminderRepo    repository.TaskReminderRepository
+	notificationSvc notification.Service
+	txManager       repository.TxManager
+	thresholds      []int
+}
+
+// NewDueSoonJob creates a new DueSoonJob reminding of tasks due within the
+// given numbers of days, or DefaultThresholds if none are given
+func NewDueSoonJob(
+	reminderRepo repository.TaskReminderRepository,
+	notificationSvc notification.Service,
+	txManager repository.TxManager,
+	thresholds []int,
+) *DueSoonJob {
+	if len(thresholds) == 0 {
+		thresholds = DefaultThresholds
+	}
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+
+	return &DueSoonJob{
+		reminderRepo:    reminderRepo,
+		notificationSvc: notificationSvc,
+		txManager:       txManager,
+		thresholds:      sorted,
+	}
+}
+
+// Name returns the name of the job
+func (j *DueSoonJob) Name() string {
+	return "task_due_soon"
+}
+
+// Run sends the due soon reminders due at now
+func (j *DueSoonJob) Run(ctx context.Context, now time.Time) error {
+	// Days are counted in the assignee's time zone, so the window is widened
+	// by a day to cover every zone
+	maxThreshold := j.thresholds[len(j.thresholds)-1]
+	candidates, err := j.reminderRepo.ListCandidates(ctx, now, now.AddDate(0, 0, maxThreshold+2))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range candidates {
+		candidate := &candidates[i]
+		loc := candidate.Location()
+		if now.In(loc).Hour() < NotifyHour {
+			continue
+		}
+
+		days := daysBetween(now, *candidate.DueDate, loc)
+		threshold, ok := j.threshold(days)
+		if !ok {
+			continue
+		}
+
+		reminder := models.NewTaskReminder(&candidate.Task, models.TaskReminderKindDueSoon, threshold)
+		err := remind(ctx, j.txManager, j.reminderRepo, reminder, func(ctx context.Context) error {
+			return j.notificationSvc.NotifyTaskDueSoon(ctx, &candidate.Task, days)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remind of task %s: %w", candidate.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// threshold returns the smallest threshold a task due in a number of days is
+// within
+func (j *DueSoonJob) threshold(days int) (int, bool) {
+	for _, threshold := range j.thresholds {
+		if days <= threshold {
+			return threshold, true
+		}
+	}
+	return 0, false
+}
+
+// OverdueJob notifies assignees that their tasks are overdue, once per due
+// date
+type OverdueJob struct {
+	reminderRepo    repository.TaskReminderRepository
+	notificationSvc notification.Service
+	txManager       repository.TxManager
+}
+
+// NewOverdueJob creates a new OverdueJob
+func NewOverdueJob(
+	reminderRepo repository.TaskReminderRepository,
+	notificationSvc notification.Service,
+	txManager repository.TxManager,
+) *OverdueJob {
+	return &OverdueJob{
+		reminderRepo:    reminderRepo,
+		notificationSvc: notificationSvc,
+		txManager:       txManager,
+	}
+}
+
+// Name returns the name of the job
+func (j *OverdueJob) Name() string {
+	return "task_overdue"
+}
+
+// Run sends the overdue reminders due at now
+func (j *OverdueJob) Run(ctx context.Context, now time.Time) error {
+	candidates, err := j.reminderRepo.ListCandidates(ctx, now.Add(-OverdueLookback), now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range candidates {
+		candidate := &candidates[i]
+		if now.In(candidate.Location()).Hour() < NotifyHour {
+			continue
+		}
+
+		reminder := models.NewTaskReminder(&candidate.Task, models.TaskReminderKindOverdue, 0)
+		err := remind(ctx, j.txManager, j.reminderRepo, reminder, func(ctx context.Context) error {
+			return j.notificationSvc.NotifyTaskOverdue(ctx, &candidate.Task)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remind of task %s: %w", candidate.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// remind records a reminder and sends it in the same transaction, so that it
+// is sent exactly once. Nothing is sent if the reminder was already recorded.
+func remind(
+	ctx context.Context,
+	txManager repository.TxManager,
+	reminderRepo repository.TaskReminderRepository,
+	reminder *models.TaskReminder,
+	notify func(ctx context.Context) error,
+) error {
+	return txManager.WithTx(ctx, func(ctx context.Context) error {
+		created, err := reminderRepo.Create(ctx, reminder)
+		if err != nil || !created {
+			return err
+		}
+		return notify(ctx)
+	})
+}
+
+// daysBetween returns the number of calendar days from one time to another in
+// a time zone
+func daysBetween(from, to time.Time, loc *time.Location) int {
+	from, to = from.In(loc), to.In(loc)
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
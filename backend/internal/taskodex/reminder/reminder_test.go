@@ -0,0 +1,154 @@
+package reminder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/reminder"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminders(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// The assignee lives in Tokyo, nine hours ahead of UTC
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	assignee, err := userRepo.GetByID(ctx, testUser.ID)
+	require.NoError(t, err)
+	assignee.Timezone = "Asia/Tokyo"
+	require.NoError(t, userRepo.Update(ctx, assignee))
+
+	// Create repositories and jobs
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	reminderRepo := repository.NewPostgresTaskReminderRepository(tdb.DB)
+	notificationRepo := repository.NewPostgresNotificationRepository(tdb.DB)
+	notificationService := notification.NewService(notificationRepo, userRepo)
+	txManager := repository.NewTxManager(tdb.DB)
+
+	dueSoonJob := reminder.NewDueSoonJob(reminderRepo, notificationService, txManager, []int{3, 1})
+	overdueJob := reminder.NewOverdueJob(reminderRepo, notificationService, txManager)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Due on a Friday at 18:00 in Tokyo
+	dueDate := time.Date(2030, time.January, 11, 18, 0, 0, 0, tokyo)
+	task := models.NewTask(models.TaskRequest{
+		ProjectID:  &testProject.ID,
+		Title:      prefix + "Quarterly report",
+		Status:     models.TaskStatusTodo,
+		Priority:   models.TaskPriorityHigh,
+		DueDate:    &dueDate,
+		AssignedTo: &testUser.ID,
+	}, testUser.ID)
+	require.NoError(t, taskRepo.Create(ctx, task))
+
+	notifications := func(notificationType models.NotificationType) []models.Notification {
+		list, _, err := notificationRepo.List(ctx, models.NotificationListParams{
+			UserID:    testUser.ID,
+			Type:      &notificationType,
+			SortBy:    "created_at",
+			SortOrder: "asc",
+			Page:      1,
+			PageSize:  100,
+		})
+		require.NoError(t, err)
+
+		forTask := []models.Notification{}
+		for _, n := range list {
+			if n.ResourceID == task.ID {
+				forTask = append(forTask, n)
+			}
+		}
+		return forTask
+	}
+
+	t.Run("DueSoon", func(t *testing.T) {
+		// Tuesday at 08:00 in Tokyo is before the notify hour
+		require.NoError(t, dueSoonJob.Run(ctx, time.Date(2030, time.January, 8, 8, 0, 0, 0, tokyo)))
+		assert.Empty(t, notifications(models.NotificationTypeTaskDueSoon))
+
+		// Tuesday at 10:00 in Tokyo is three days before the due date
+		now := time.Date(2030, time.January, 8, 10, 0, 0, 0, tokyo)
+		require.NoError(t, dueSoonJob.Run(ctx, now))
+		require.NoError(t, dueSoonJob.Run(ctx, now.Add(time.Hour)))
+		sent := notifications(models.NotificationTypeTaskDueSoon)
+		require.Len(t, sent, 1)
+		assert.Contains(t, sent[0].Message, "is due in 3 days")
+
+		// Wednesday is still within three days, Thursday is the day before and
+		// Friday within the same day
+		require.NoError(t, dueSoonJob.Run(ctx, now.AddDate(0, 0, 1)))
+		assert.Len(t, notifications(models.NotificationTypeTaskDueSoon), 1)
+
+		require.NoError(t, dueSoonJob.Run(ctx, now.AddDate(0, 0, 2)))
+		require.NoError(t, dueSoonJob.Run(ctx, now.AddDate(0, 0, 3)))
+		sent = notifications(models.NotificationTypeTaskDueSoon)
+		require.Len(t, sent, 2)
+		assert.Contains(t, sent[1].Message, "is due tomorrow")
+	})
+
+	t.Run("Overdue", func(t *testing.T) {
+		// Not overdue before the due date
+		require.NoError(t, overdueJob.Run(ctx, dueDate.Add(-time.Minute)))
+		assert.Empty(t, notifications(models.NotificationTypeTaskOverdue))
+
+		require.NoError(t, overdueJob.Run(ctx, dueDate.Add(time.Minute)))
+		require.NoError(t, overdueJob.Run(ctx, dueDate.Add(time.Hour)))
+		assert.Len(t, notifications(models.NotificationTypeTaskOverdue), 1)
+
+		// Moving the due date lets the task be reminded of again
+		newDueDate := dueDate.AddDate(0, 0, 3)
+		task.DueDate = &newDueDate
+		require.NoError(t, taskRepo.Update(ctx, task))
+		require.NoError(t, overdueJob.Run(ctx, newDueDate.Add(time.Minute)))
+		assert.Len(t, notifications(models.NotificationTypeTaskOverdue), 2)
+	})
+
+	t.Run("ClosedAndUnassignedTasks", func(t *testing.T) {
+		closed := models.NewTask(models.TaskRequest{
+			ProjectID:  &testProject.ID,
+			Title:      prefix + "Closed",
+			Status:     models.TaskStatusDone,
+			Priority:   models.TaskPriorityLow,
+			DueDate:    &dueDate,
+			AssignedTo: &testUser.ID,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, closed))
+
+		unassigned := models.NewTask(models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + "Unassigned",
+			Status:    models.TaskStatusTodo,
+			Priority:  models.TaskPriorityLow,
+			DueDate:   &dueDate,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, unassigned))
+
+		candidates, err := reminderRepo.ListCandidates(ctx, dueDate.Add(-time.Hour), dueDate.Add(time.Hour))
+		require.NoError(t, err)
+		ids := []uuid.UUID{}
+		for _, candidate := range candidates {
+			ids = append(ids, candidate.ID)
+			assert.Equal(t, "Asia/Tokyo", candidate.Timezone)
+		}
+		assert.NotContains(t, ids, closed.ID)
+		assert.NotContains(t, ids, unassigned.ID)
+	})
+}
@@ -0,0 +1,263 @@
+package savedfilter
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for saved filter management
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create handles the creation of a new saved filter
+func (h *Handlers) Create(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse request body
+	var req models.SavedFilterRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create saved filter
+	response, err := h.service.Create(c.Request().Context(), orgID, userID, req)
+	if err != nil {
+		return h.handleError(err, "Failed to create saved filter")
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// Get handles retrieving a saved filter by ID
+func (h *Handlers) Get(c echo.Context) error {
+	// Get organization, saved filter and user IDs
+	orgID, id, userID, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Get saved filter
+	response, err := h.service.GetByID(c.Request().Context(), orgID, id, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve saved filter")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// List handles listing the saved filters visible to the user
+func (h *Handlers) List(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// List saved filters
+	filters, err := h.service.List(c.Request().Context(), orgID, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to list saved filters")
+	}
+
+	return c.JSON(http.StatusOK, filters)
+}
+
+// Update handles updating a saved filter
+func (h *Handlers) Update(c echo.Context) error {
+	// Get organization, saved filter and user IDs
+	orgID, id, userID, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.SavedFilterRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update saved filter
+	response, err := h.service.Update(c.Request().Context(), orgID, id, userID, req)
+	if err != nil {
+		return h.handleError(err, "Failed to update saved filter")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Delete handles deleting a saved filter
+func (h *Handlers) Delete(c echo.Context) error {
+	// Get organization, saved filter and user IDs
+	orgID, id, userID, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Delete saved filter
+	err = h.service.Delete(c.Request().Context(), orgID, id, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to delete saved filter")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListTasks handles retrieving the tasks matching a saved filter
+func (h *Handlers) ListTasks(c echo.Context) error {
+	// Get organization, saved filter and user IDs
+	orgID, id, userID, err := h.parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	params := models.TaskListParams{
+		SortBy:    c.QueryParam("sort_by"),
+		SortOrder: c.QueryParam("sort_order"),
+		Page:      1,
+		PageSize:  20,
+	}
+
+	// Parse page parameter
+	pageParam := c.QueryParam("page")
+	if pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page parameter")
+		}
+		params.Page = page
+	}
+
+	// Parse page_size parameter
+	pageSizeParam := c.QueryParam("page_size")
+	if pageSizeParam != "" {
+		pageSize, err := strconv.Atoi(pageSizeParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page_size parameter")
+		}
+		params.PageSize = pageSize
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	// Get tasks
+	tasks, total, err := h.service.ListTasks(c.Request().Context(), orgID, id, userID, params)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve tasks")
+	}
+
+	// Build response
+	response := map[string]interface{}{
+		"tasks": tasks,
+		"pagination": map[string]interface{}{
+			"total":       total,
+			"page":        params.Page,
+			"page_size":   params.PageSize,
+			"total_pages": (total + params.PageSize - 1) / params.PageSize,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// parseIDs parses the organization and saved filter IDs from path parameters
+// and the user ID from the context
+func (h *Handlers) parseIDs(c echo.Context) (uuid.UUID, uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid saved filter ID")
+	}
+
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	return orgID, id, userID, nil
+}
+
+// handleError maps errors from managing saved filters to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	if errors.Is(err, repository.ErrSavedFilterNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Saved filter not found")
+	}
+	if errors.Is(err, repository.ErrSavedFilterNameExists) {
+		return echo.NewHTTPError(http.StatusConflict, "Saved filter name already exists")
+	}
+	if errors.Is(err, ErrNotOwner) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+	if errors.Is(err, models.ErrInvalidTaskFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the saved filter routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	filterGroup := g.Group("/filters")
+
+	// Routes that require task:read permission
+	filterGroup.GET("", h.List, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+	filterGroup.GET("/:id", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+	filterGroup.GET("/:id/tasks", h.ListTasks, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+
+	// Routes that require task:write permission
+	filterGroup.POST("", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	filterGroup.PUT("/:id", h.Update, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	filterGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
@@ -0,0 +1,171 @@
+package savedfilter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/savedfilter"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedFilters(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test users, organization and project
+	owner := tdb.CreateTestUser(t, prefix)
+	other := tdb.CreateTestUser(t, prefix+"2")
+	testOrg := tdb.CreateTestOrganization(t, prefix, owner.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, owner.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+
+	// Create services
+	taskService := task.NewService(
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		repository.NewPostgresTaskLinkRepository(tdb.DB),
+		repository.NewPostgresChecklistItemRepository(tdb.DB),
+		projectRepo,
+		userRepo,
+		repository.NewPostgresRoleRepository(tdb.DB),
+		notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), userRepo),
+		workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB)),
+		customfield.NewService(repository.NewPostgresCustomFieldRepository(tdb.DB), projectRepo, userRepo, repository.NewPostgresRoleRepository(tdb.DB)),
+		repository.NewTxManager(tdb.DB),
+	)
+	savedFilterService := savedfilter.NewService(repository.NewPostgresSavedFilterRepository(tdb.DB), taskService)
+
+	// Create tasks
+	dueDate := time.Now().AddDate(0, 0, 3)
+	newTask := func(title string, priority models.TaskPriority, assignee *models.User) *models.Task {
+		req := models.TaskRequest{
+			ProjectID: &testProject.ID,
+			Title:     prefix + title,
+			Status:    models.TaskStatusTodo,
+			Priority:  priority,
+			DueDate:   &dueDate,
+			Tags:      []string{"backend"},
+		}
+		if assignee != nil {
+			req.AssignedTo = &assignee.ID
+		}
+		task := models.NewTask(req, owner.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	mine := newTask("Mine", models.TaskPriorityHigh, owner)
+	theirs := newTask("Theirs", models.TaskPriorityCritical, other)
+	newTask("Minor", models.TaskPriorityLow, owner)
+
+	var filter *models.SavedFilter
+
+	t.Run("CreateSavedFilter", func(t *testing.T) {
+		var err error
+		filter, err = savedFilterService.Create(ctx, testOrg.ID, owner.ID, models.SavedFilterRequest{
+			Name:   "My urgent backend work",
+			Query:  "assignee = me and priority >= high and tag:backend and due < 7d",
+			Shared: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID, filter.OwnerID)
+
+		// Names are unique per owner
+		_, err = savedFilterService.Create(ctx, testOrg.ID, owner.ID, models.SavedFilterRequest{
+			Name:  "My urgent backend work",
+			Query: "tag:backend",
+		})
+		assert.ErrorIs(t, err, repository.ErrSavedFilterNameExists)
+
+		// Expressions are validated when saved
+		_, err = savedFilterService.Create(ctx, testOrg.ID, owner.ID, models.SavedFilterRequest{
+			Name:  "Broken",
+			Query: "priority >> high",
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidTaskFilter)
+	})
+
+	t.Run("ListTasks", func(t *testing.T) {
+		// "me" is the user running the filter
+		tasks, total, err := savedFilterService.ListTasks(ctx, testOrg.ID, filter.ID, owner.ID, models.TaskListParams{Page: 1, PageSize: 20})
+		require.NoError(t, err)
+		if assert.Equal(t, 1, total) {
+			assert.Equal(t, mine.ID, tasks[0].ID)
+		}
+
+		tasks, total, err = savedFilterService.ListTasks(ctx, testOrg.ID, filter.ID, other.ID, models.TaskListParams{Page: 1, PageSize: 20})
+		require.NoError(t, err)
+		if assert.Equal(t, 1, total) {
+			assert.Equal(t, theirs.ID, tasks[0].ID)
+		}
+	})
+
+	t.Run("Sharing", func(t *testing.T) {
+		private, err := savedFilterService.Create(ctx, testOrg.ID, owner.ID, models.SavedFilterRequest{
+			Name:  "Private",
+			Query: "tag:backend",
+		})
+		require.NoError(t, err)
+
+		// Shared filters are visible to other users, private ones are not
+		filters, err := savedFilterService.List(ctx, testOrg.ID, other.ID)
+		require.NoError(t, err)
+		if assert.Len(t, filters, 1) {
+			assert.Equal(t, filter.ID, filters[0].ID)
+		}
+
+		_, err = savedFilterService.GetByID(ctx, testOrg.ID, private.ID, other.ID)
+		assert.ErrorIs(t, err, repository.ErrSavedFilterNotFound)
+
+		filters, err = savedFilterService.List(ctx, testOrg.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, filters, 2)
+
+		// Only the owner can change a shared filter
+		_, err = savedFilterService.Update(ctx, testOrg.ID, filter.ID, other.ID, models.SavedFilterRequest{
+			Name:  "Hijacked",
+			Query: "tag:frontend",
+		})
+		assert.ErrorIs(t, err, savedfilter.ErrNotOwner)
+
+		err = savedFilterService.Delete(ctx, testOrg.ID, filter.ID, other.ID)
+		assert.ErrorIs(t, err, savedfilter.ErrNotOwner)
+	})
+
+	t.Run("UpdateSavedFilter", func(t *testing.T) {
+		updated, err := savedFilterService.Update(ctx, testOrg.ID, filter.ID, owner.ID, models.SavedFilterRequest{
+			Name:  "Backend work",
+			Query: "tag:backend and priority != low",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Backend work", updated.Name)
+		assert.False(t, updated.Shared)
+
+		_, total, err := savedFilterService.ListTasks(ctx, testOrg.ID, filter.ID, owner.ID, models.TaskListParams{Page: 1, PageSize: 20})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("DeleteSavedFilter", func(t *testing.T) {
+		err := savedFilterService.Delete(ctx, testOrg.ID, filter.ID, owner.ID)
+		require.NoError(t, err)
+
+		_, err = savedFilterService.GetByID(ctx, testOrg.ID, filter.ID, owner.ID)
+		assert.ErrorIs(t, err, repository.ErrSavedFilterNotFound)
+	})
+}
@@ -0,0 +1,150 @@
+package savedfilter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrNotOwner = errors.New("only the owner can change a saved filter")
+)
+
+// Service provides saved filter management functionality
+type Service interface {
+	// Create saves a task filter expression for a user of an organization
+	Create(ctx context.Context, organizationID, userID uuid.UUID, req models.SavedFilterRequest) (*models.SavedFilter, error)
+
+	// GetByID retrieves a saved filter visible to a user
+	GetByID(ctx context.Context, organizationID, id, userID uuid.UUID) (*models.SavedFilter, error)
+
+	// List retrieves the saved filters visible to a user: their own and the
+	// shared ones
+	List(ctx context.Context, organizationID, userID uuid.UUID) ([]models.SavedFilter, error)
+
+	// Update updates a saved filter. Only its owner can update it.
+	Update(ctx context.Context, organizationID, id, userID uuid.UUID, req models.SavedFilterRequest) (*models.SavedFilter, error)
+
+	// Delete deletes a saved filter. Only its owner can delete it.
+	Delete(ctx context.Context, organizationID, id, userID uuid.UUID) error
+
+	// ListTasks retrieves the tasks matching a saved filter. "me" in the
+	// filter is the user running it.
+	ListTasks(ctx context.Context, organizationID, id, userID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	savedFilterRepo repository.SavedFilterRepository
+	taskSvc         task.Service
+}
+
+// NewService creates a new saved filter service
+func NewService(savedFilterRepo repository.SavedFilterRepository, taskSvc task.Service) Service {
+	return &serviceImpl{
+		savedFilterRepo: savedFilterRepo,
+		taskSvc:         taskSvc,
+	}
+}
+
+// Create saves a task filter expression
+func (s *serviceImpl) Create(ctx context.Context, organizationID, userID uuid.UUID, req models.SavedFilterRequest) (*models.SavedFilter, error) {
+	// Validate the filter expression
+	if _, err := models.ParseTaskFilter(req.Query); err != nil {
+		return nil, err
+	}
+
+	filter := models.NewSavedFilter(organizationID, userID, req)
+	err := s.savedFilterRepo.Create(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.savedFilterRepo.GetByID(ctx, filter.ID)
+}
+
+// GetByID retrieves a saved filter visible to a user
+func (s *serviceImpl) GetByID(ctx context.Context, organizationID, id, userID uuid.UUID) (*models.SavedFilter, error) {
+	filter, err := s.savedFilterRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filters of other organizations, and private filters of other users, are not visible
+	if filter.OrganizationID != organizationID || (filter.OwnerID != userID && !filter.Shared) {
+		return nil, repository.ErrSavedFilterNotFound
+	}
+
+	return filter, nil
+}
+
+// List retrieves the saved filters visible to a user
+func (s *serviceImpl) List(ctx context.Context, organizationID, userID uuid.UUID) ([]models.SavedFilter, error) {
+	return s.savedFilterRepo.List(ctx, organizationID, userID)
+}
+
+// Update updates a saved filter
+func (s *serviceImpl) Update(ctx context.Context, organizationID, id, userID uuid.UUID, req models.SavedFilterRequest) (*models.SavedFilter, error) {
+	filter, err := s.GetByID(ctx, organizationID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.OwnerID != userID {
+		return nil, ErrNotOwner
+	}
+
+	// Validate the filter expression
+	if _, err := models.ParseTaskFilter(req.Query); err != nil {
+		return nil, err
+	}
+
+	filter.Name = req.Name
+	filter.Query = req.Query
+	filter.Shared = req.Shared
+
+	err = s.savedFilterRepo.Update(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.savedFilterRepo.GetByID(ctx, id)
+}
+
+// Delete deletes a saved filter
+func (s *serviceImpl) Delete(ctx context.Context, organizationID, id, userID uuid.UUID) error {
+	filter, err := s.GetByID(ctx, organizationID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if filter.OwnerID != userID {
+		return ErrNotOwner
+	}
+
+	return s.savedFilterRepo.Delete(ctx, id)
+}
+
+// ListTasks retrieves the tasks matching a saved filter
+func (s *serviceImpl) ListTasks(ctx context.Context, organizationID, id, userID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error) {
+	filter, err := s.GetByID(ctx, organizationID, id, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	expr, err := models.ParseTaskFilter(filter.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params.Filter = models.AndTaskFilters(params.Filter, expr)
+	params.CurrentUserID = &userID
+	params.OrganizationID = &organizationID
+
+	return s.taskSvc.List(ctx, params)
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for search
+type Handlers struct {
+	service Service
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+// Search handles searching tasks, projects and comments
+func (h *Handlers) Search(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgIDParam := c.Param("org_id")
+	orgID, err := uuid.Parse(orgIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	params := models.SearchParams{
+		Query:          c.QueryParam("q"),
+		OrganizationID: orgID,
+		UserID:         userID,
+		Page:           1,
+		PageSize:       20,
+	}
+
+	// Parse type parameter, a comma-separated list of result types
+	typeParam := c.QueryParam("type")
+	if typeParam != "" {
+		for _, resultType := range strings.Split(typeParam, ",") {
+			params.Types = append(params.Types, models.SearchResultType(strings.TrimSpace(resultType)))
+		}
+	}
+
+	// Parse page parameter
+	pageParam := c.QueryParam("page")
+	if pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page parameter")
+		}
+		params.Page = page
+	}
+
+	// Parse page_size parameter
+	pageSizeParam := c.QueryParam("page_size")
+	if pageSizeParam != "" {
+		pageSize, err := strconv.Atoi(pageSizeParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page_size parameter")
+		}
+		params.PageSize = pageSize
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	// Search
+	results, total, err := h.service.Search(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, ErrInvalidQuery) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid search query")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to search")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"results": results,
+		"pagination": map[string]interface{}{
+			"total":       total,
+			"page":        params.Page,
+			"page_size":   params.PageSize,
+			"total_pages": (total + params.PageSize - 1) / params.PageSize,
+		},
+	})
+}
+
+// RegisterRoutes registers the search routes. Results are further limited to
+// the types the user has read permission for.
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	g.GET("/search", h.Search, rbacMiddleware.RequirePermission(middleware.PermissionTaskodexAccess))
+}
@@ -0,0 +1,181 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/search"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	commentRepo := repository.NewPostgresCommentRepository(tdb.DB)
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+
+	// Create service
+	searchService := search.NewService(repository.NewPostgresSearchRepository(tdb.DB), roleRepo)
+
+	// Grant the user a role that can read tasks and comments, but not projects
+	role := &models.Role{
+		ID:        uuid.New(),
+		Name:      prefix + " searcher",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, roleRepo.CreateRole(ctx, role))
+
+	grant := func(name string) {
+		permission, err := roleRepo.GetPermissionByName(ctx, name)
+		if errors.Is(err, repository.ErrPermissionNotFound) {
+			permission = &models.Permission{ID: uuid.New(), Name: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+			err = roleRepo.CreatePermission(ctx, permission)
+		}
+		require.NoError(t, err)
+		require.NoError(t, roleRepo.AssignPermissionToRole(ctx, role.ID, permission.ID))
+	}
+	grant(middleware.PermissionTaskRead)
+	grant(middleware.PermissionCommentRead)
+
+	require.NoError(t, roleRepo.AssignRoleToUser(ctx, &models.UserRole{
+		UserID:         testUser.ID,
+		RoleID:         role.ID,
+		OrganizationID: testOrg.ID,
+	}))
+
+	// Create searchable tasks, a comment and a project
+	newTask := func(title, description string) *models.Task {
+		task := models.NewTask(models.TaskRequest{
+			ProjectID:   &testProject.ID,
+			Title:       title,
+			Description: description,
+			Status:      models.TaskStatusTodo,
+			Priority:    models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	budgetTask := newTask("Quarterly budget review", "Collect the marketing spend")
+	partyTask := newTask("Office party", "Plan the budget for snacks")
+
+	comment := models.NewComment(models.CommentRequest{
+		TaskID:  partyTask.ID,
+		Content: "Budgeting spreadsheet attached",
+	}, testUser.ID)
+	require.NoError(t, commentRepo.Create(ctx, comment))
+
+	require.NoError(t, projectRepo.Create(ctx, &models.Project{
+		ID:             uuid.New(),
+		OrganizationID: testOrg.ID,
+		Name:           prefix + " Budget planning",
+		Status:         models.ProjectStatusPlanning,
+		CreatedBy:      testUser.ID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}))
+
+	params := models.SearchParams{
+		Query:          "budg",
+		OrganizationID: testOrg.ID,
+		UserID:         testUser.ID,
+		Page:           1,
+		PageSize:       20,
+	}
+
+	t.Run("RankedResults", func(t *testing.T) {
+		results, total, err := searchService.Search(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, results, 3)
+
+		// Title matches rank above description matches, which rank above comments
+		assert.Equal(t, budgetTask.ID, results[0].ID)
+		assert.Equal(t, partyTask.ID, results[1].ID)
+		assert.Equal(t, models.SearchResultTypeComment, results[2].Type)
+		assert.Equal(t, comment.ID, results[2].ID)
+		assert.Equal(t, partyTask.ID, *results[2].TaskID)
+		assert.Equal(t, "Office party", results[2].Title)
+
+		assert.Contains(t, results[0].Snippet, "<mark>budget</mark>")
+		assert.Contains(t, results[2].Snippet, "<mark>Budgeting</mark>")
+	})
+
+	t.Run("PermissionScope", func(t *testing.T) {
+		grant(middleware.PermissionProjectRead)
+
+		results, total, err := searchService.Search(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+
+		projects := 0
+		for _, result := range results {
+			if result.Type == models.SearchResultTypeProject {
+				projects++
+			}
+		}
+		assert.Equal(t, 1, projects)
+
+		// Other organizations see nothing
+		other := params
+		other.OrganizationID = uuid.New()
+		_, total, err = searchService.Search(ctx, other)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("TypeFilter", func(t *testing.T) {
+		filtered := params
+		filtered.Types = []models.SearchResultType{models.SearchResultTypeComment}
+
+		results, total, err := searchService.Search(ctx, filtered)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, comment.ID, results[0].ID)
+
+		filtered.Types = []models.SearchResultType{"user"}
+		_, _, err = searchService.Search(ctx, filtered)
+		assert.ErrorIs(t, err, search.ErrInvalidQuery)
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		empty := params
+		empty.Query = "  "
+		_, _, err := searchService.Search(ctx, empty)
+		assert.ErrorIs(t, err, search.ErrInvalidQuery)
+	})
+
+	t.Run("TaskListSearch", func(t *testing.T) {
+		// Every word must match as a prefix
+		term := "quart budg"
+		tasks, total, err := taskRepo.List(ctx, models.TaskListParams{
+			ProjectID:  &testProject.ID,
+			SearchTerm: &term,
+			Page:       1,
+			PageSize:   20,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, budgetTask.ID, tasks[0].ID)
+	})
+}
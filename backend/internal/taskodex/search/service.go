@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+)
+
+var (
+	// ErrInvalidQuery is returned when a search query is empty or has an unknown result type
+	ErrInvalidQuery = errors.New("invalid search query")
+)
+
+// typePermissions maps each result type to the permission needed to see it
+var typePermissions = map[models.SearchResultType]string{
+	models.SearchResultTypeTask:    middleware.PermissionTaskRead,
+	models.SearchResultTypeProject: middleware.PermissionProjectRead,
+	models.SearchResultTypeComment: middleware.PermissionCommentRead,
+}
+
+// Service provides full-text search across tasks, projects and comments
+type Service interface {
+	// Search retrieves the tasks, projects and comments of an organization
+	// matching a query, ranked best first. Only the result types the user
+	// has read permission for are searched; no types means all of them.
+	Search(ctx context.Context, params models.SearchParams) ([]models.SearchResult, int, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	searchRepo repository.SearchRepository
+	roleRepo   repository.RoleRepository
+}
+
+// NewService creates a new search service
+func NewService(searchRepo repository.SearchRepository, roleRepo repository.RoleRepository) Service {
+	return &serviceImpl{
+		searchRepo: searchRepo,
+		roleRepo:   roleRepo,
+	}
+}
+
+// Search retrieves the tasks, projects and comments matching a query
+func (s *serviceImpl) Search(ctx context.Context, params models.SearchParams) ([]models.SearchResult, int, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return nil, 0, ErrInvalidQuery
+	}
+
+	types := params.Types
+	if len(types) == 0 {
+		types = []models.SearchResultType{
+			models.SearchResultTypeTask,
+			models.SearchResultTypeProject,
+			models.SearchResultTypeComment,
+		}
+	}
+
+	// Keep the types the user can see
+	params.Types = nil
+	for _, resultType := range types {
+		permission, ok := typePermissions[resultType]
+		if !ok {
+			return nil, 0, ErrInvalidQuery
+		}
+
+		hasPermission, err := s.roleRepo.HasPermission(ctx, params.UserID, params.OrganizationID, permission)
+		if err != nil {
+			return nil, 0, err
+		}
+		if hasPermission {
+			params.Types = append(params.Types, resultType)
+		}
+	}
+
+	return s.searchRepo.Search(ctx, params)
+}
@@ -0,0 +1,300 @@
+package sprint
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for sprint management
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create handles planning a new sprint in a project
+func (h *Handlers) Create(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse request body
+	var req models.SprintRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Create sprint
+	response, err := h.service.Create(c.Request().Context(), projectID, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to create sprint")
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// List handles listing the sprints of a project
+func (h *Handlers) List(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse state parameter
+	var state *models.SprintState
+	if stateParam := c.QueryParam("state"); stateParam != "" {
+		s := models.SprintState(stateParam)
+		switch s {
+		case models.SprintStatePlanned, models.SprintStateActive, models.SprintStateClosed:
+			state = &s
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid state parameter")
+		}
+	}
+
+	// List sprints
+	sprints, err := h.service.List(c.Request().Context(), projectID, state)
+	if err != nil {
+		return h.handleError(err, "Failed to list sprints")
+	}
+
+	return c.JSON(http.StatusOK, sprints)
+}
+
+// Get handles retrieving a sprint by ID
+func (h *Handlers) Get(c echo.Context) error {
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Get sprint
+	response, err := h.service.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve sprint")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Update handles updating a sprint
+func (h *Handlers) Update(c echo.Context) error {
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Parse request body
+	var req models.SprintRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update sprint
+	response, err := h.service.Update(c.Request().Context(), id, req)
+	if err != nil {
+		return h.handleError(err, "Failed to update sprint")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Delete handles deleting a sprint
+func (h *Handlers) Delete(c echo.Context) error {
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Delete sprint
+	err = h.service.Delete(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to delete sprint")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Start handles starting a sprint
+func (h *Handlers) Start(c echo.Context) error {
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Start sprint
+	response, err := h.service.Start(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to start sprint")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Close handles closing a sprint
+func (h *Handlers) Close(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Parse request body
+	var req models.SprintCloseRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Close sprint
+	response, err := h.service.Close(c.Request().Context(), id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to close sprint")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// AddTasks handles planning tasks into a sprint
+func (h *Handlers) AddTasks(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Parse request body
+	var req models.SprintTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Add tasks
+	response, err := h.service.AddTasks(c.Request().Context(), id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to add tasks to sprint")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RemoveTask handles returning a task of a sprint to the backlog
+func (h *Handlers) RemoveTask(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get sprint ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID")
+	}
+
+	// Get task ID from path parameter
+	taskID, err := uuid.Parse(c.Param("task_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Remove task
+	err = h.service.RemoveTask(c.Request().Context(), id, taskID, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to remove task from sprint")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// handleError maps errors from managing sprints to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	case errors.Is(err, repository.ErrSprintNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found in sprint")
+	case errors.Is(err, ErrInvalidDates), errors.Is(err, ErrInvalidCarryOver), errors.Is(err, ErrTaskNotInProject):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSprintClosed), errors.Is(err, ErrSprintActive), errors.Is(err, ErrSprintNotPlanned),
+		errors.Is(err, ErrSprintNotActive), errors.Is(err, repository.ErrActiveSprintExists),
+		errors.Is(err, repository.ErrSprintStateChanged), errors.Is(err, repository.ErrProjectArchived):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the sprint routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	sprintGroup := g.Group("/sprints")
+
+	// Routes that require project:read permission
+	g.GET("/projects/:id/sprints", h.List, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	sprintGroup.GET("/:id", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	g.POST("/projects/:id/sprints", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	sprintGroup.PUT("/:id", h.Update, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	sprintGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	sprintGroup.POST("/:id/start", h.Start, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	sprintGroup.POST("/:id/close", h.Close, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+
+	// Routes that require task:write permission
+	sprintGroup.POST("/:id/tasks", h.AddTasks, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	sprintGroup.DELETE("/:id/tasks/:task_id", h.RemoveTask, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
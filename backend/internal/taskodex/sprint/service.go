@@ -0,0 +1,336 @@
+package sprint
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrInvalidDates     = errors.New("end date must not be before start date")
+	ErrSprintClosed     = errors.New("sprint is closed")
+	ErrSprintActive     = errors.New("an active sprint cannot be deleted")
+	ErrSprintNotPlanned = errors.New("only planned sprints can be started")
+	ErrSprintNotActive  = errors.New("only active sprints can be closed")
+	ErrInvalidCarryOver = errors.New("unfinished tasks can only be carried over to another planned sprint of the project")
+	ErrTaskNotInProject = errors.New("tasks must belong to the sprint's project")
+)
+
+// Service provides sprint management functionality
+type Service interface {
+	// Create plans a new sprint in a project
+	Create(ctx context.Context, projectID uuid.UUID, req models.SprintRequest, userID uuid.UUID) (*models.Sprint, error)
+
+	// GetByID retrieves a sprint by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Sprint, error)
+
+	// List retrieves the sprints of a project, optionally in a state
+	List(ctx context.Context, projectID uuid.UUID, state *models.SprintState) ([]models.Sprint, error)
+
+	// Update updates the name, goal and dates of a sprint that is not closed
+	Update(ctx context.Context, id uuid.UUID, req models.SprintRequest) (*models.Sprint, error)
+
+	// Delete deletes a sprint that is not active. Its tasks return to the
+	// backlog.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Start starts a planned sprint. A project has at most one active sprint.
+	Start(ctx context.Context, id uuid.UUID) (*models.Sprint, error)
+
+	// Close closes the active sprint. Its unfinished tasks, those not in a
+	// closed state of the project's workflow, move to the given planned
+	// sprint or to the backlog.
+	Close(ctx context.Context, id uuid.UUID, req models.SprintCloseRequest, userID uuid.UUID) (*models.Sprint, error)
+
+	// AddTasks plans tasks of the sprint's project into a sprint that is not
+	// closed, moving them from the backlog or another sprint
+	AddTasks(ctx context.Context, id uuid.UUID, req models.SprintTasksRequest, userID uuid.UUID) (*models.Sprint, error)
+
+	// RemoveTask returns a task of a sprint that is not closed to the backlog
+	RemoveTask(ctx context.Context, id, taskID, userID uuid.UUID) error
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	sprintRepo  repository.SprintRepository
+	projectRepo repository.ProjectRepository
+	workflowSvc workflow.Service
+	txManager   repository.TxManager
+}
+
+// NewService creates a new sprint service
+func NewService(
+	sprintRepo repository.SprintRepository,
+	projectRepo repository.ProjectRepository,
+	workflowSvc workflow.Service,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		sprintRepo:  sprintRepo,
+		projectRepo: projectRepo,
+		workflowSvc: workflowSvc,
+		txManager:   txManager,
+	}
+}
+
+// Create plans a new sprint in a project
+func (s *serviceImpl) Create(ctx context.Context, projectID uuid.UUID, req models.SprintRequest, userID uuid.UUID) (*models.Sprint, error) {
+	// Check if project exists. Archived projects are read-only.
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	if err := checkDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	sprint := models.NewSprint(projectID, req, userID)
+	err = s.sprintRepo.Create(ctx, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.GetByID(ctx, sprint.ID)
+}
+
+// GetByID retrieves a sprint by ID
+func (s *serviceImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Sprint, error) {
+	return s.sprintRepo.GetByID(ctx, id)
+}
+
+// List retrieves the sprints of a project
+func (s *serviceImpl) List(ctx context.Context, projectID uuid.UUID, state *models.SprintState) ([]models.Sprint, error) {
+	// Check if project exists
+	_, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.List(ctx, projectID, state)
+}
+
+// Update updates the name, goal and dates of a sprint
+func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.SprintRequest) (*models.Sprint, error) {
+	sprint, err := s.getWritableSprint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sprint.State == models.SprintStateClosed {
+		return nil, ErrSprintClosed
+	}
+
+	if err := checkDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	sprint.Name = req.Name
+	sprint.Goal = req.Goal
+	sprint.StartDate = req.StartDate
+	sprint.EndDate = req.EndDate
+
+	err = s.sprintRepo.Update(ctx, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.GetByID(ctx, id)
+}
+
+// Delete deletes a sprint that is not active
+func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	sprint, err := s.getWritableSprint(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if sprint.State == models.SprintStateActive {
+		return ErrSprintActive
+	}
+
+	return s.sprintRepo.Delete(ctx, id)
+}
+
+// Start starts a planned sprint
+func (s *serviceImpl) Start(ctx context.Context, id uuid.UUID) (*models.Sprint, error) {
+	sprint, err := s.getWritableSprint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sprint.State != models.SprintStatePlanned {
+		return nil, ErrSprintNotPlanned
+	}
+
+	// A project has at most one active sprint
+	_, err = s.sprintRepo.GetActive(ctx, sprint.ProjectID)
+	if err == nil {
+		return nil, repository.ErrActiveSprintExists
+	}
+	if !errors.Is(err, repository.ErrSprintNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	sprint.State = models.SprintStateActive
+	sprint.StartedAt = &now
+	if sprint.StartDate == nil {
+		sprint.StartDate = &now
+	}
+	if err := checkDates(sprint.StartDate, sprint.EndDate); err != nil {
+		return nil, err
+	}
+
+	err = s.sprintRepo.SetState(ctx, sprint, models.SprintStatePlanned)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.GetByID(ctx, id)
+}
+
+// Close closes the active sprint and carries over its unfinished tasks
+func (s *serviceImpl) Close(ctx context.Context, id uuid.UUID, req models.SprintCloseRequest, userID uuid.UUID) (*models.Sprint, error) {
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		sprint, err := s.getWritableSprint(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if sprint.State != models.SprintStateActive {
+			return ErrSprintNotActive
+		}
+
+		// Unfinished tasks move to a planned sprint of the same project
+		if req.CarryOverTo != nil {
+			next, err := s.sprintRepo.GetByID(ctx, *req.CarryOverTo)
+			if err != nil {
+				if errors.Is(err, repository.ErrSprintNotFound) {
+					return ErrInvalidCarryOver
+				}
+				return err
+			}
+			if next.ProjectID != sprint.ProjectID || next.State != models.SprintStatePlanned {
+				return ErrInvalidCarryOver
+			}
+		}
+
+		// Tasks in closed states of the workflow are done
+		workflow, err := s.workflowSvc.Get(ctx, sprint.ProjectID)
+		if err != nil {
+			return err
+		}
+		var done []models.TaskStatus
+		for _, state := range workflow.States {
+			if state.Category == models.WorkflowStateCategoryClosed {
+				done = append(done, state.Key)
+			}
+		}
+
+		now := time.Now()
+		sprint.CompletedTasks, sprint.CarriedOverTasks, err = s.sprintRepo.CarryOver(ctx, id, done, req.CarryOverTo, userID, now)
+		if err != nil {
+			return err
+		}
+
+		sprint.State = models.SprintStateClosed
+		sprint.ClosedAt = &now
+
+		return s.sprintRepo.SetState(ctx, sprint, models.SprintStateActive)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.GetByID(ctx, id)
+}
+
+// AddTasks plans tasks into a sprint
+func (s *serviceImpl) AddTasks(ctx context.Context, id uuid.UUID, req models.SprintTasksRequest, userID uuid.UUID) (*models.Sprint, error) {
+	sprint, err := s.getWritableSprint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sprint.State == models.SprintStateClosed {
+		return nil, ErrSprintClosed
+	}
+
+	// Remove duplicate task IDs
+	seen := make(map[uuid.UUID]bool, len(req.TaskIDs))
+	taskIDs := make([]uuid.UUID, 0, len(req.TaskIDs))
+	for _, taskID := range req.TaskIDs {
+		if !seen[taskID] {
+			seen[taskID] = true
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+
+	// Either all tasks are added or none
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		added, err := s.sprintRepo.AddTasks(ctx, sprint, taskIDs, userID)
+		if err != nil {
+			return err
+		}
+		if added != len(taskIDs) {
+			return ErrTaskNotInProject
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepo.GetByID(ctx, id)
+}
+
+// RemoveTask returns a task of a sprint to the backlog
+func (s *serviceImpl) RemoveTask(ctx context.Context, id, taskID, userID uuid.UUID) error {
+	sprint, err := s.getWritableSprint(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if sprint.State == models.SprintStateClosed {
+		return ErrSprintClosed
+	}
+
+	return s.sprintRepo.RemoveTask(ctx, id, taskID, userID)
+}
+
+// getWritableSprint retrieves a sprint to change. Sprints of archived
+// projects are read-only.
+func (s *serviceImpl) getWritableSprint(ctx context.Context, id uuid.UUID) (*models.Sprint, error) {
+	sprint, err := s.sprintRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, sprint.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	return sprint, nil
+}
+
+// checkDates returns ErrInvalidDates if a sprint would end before it starts
+func checkDates(start, end *time.Time) error {
+	if start != nil && end != nil && end.Before(*start) {
+		return ErrInvalidDates
+	}
+	return nil
+}
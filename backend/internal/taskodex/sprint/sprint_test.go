@@ -0,0 +1,199 @@
+package sprint_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/sprint"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSprints(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and projects
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+	otherProject := tdb.CreateTestProject(t, prefix+"other", testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	sprintRepo := repository.NewPostgresSprintRepository(tdb.DB)
+
+	// Create service and handlers
+	sprintService := sprint.NewService(
+		sprintRepo,
+		projectRepo,
+		workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB)),
+		repository.NewTxManager(tdb.DB),
+	)
+	sprintHandlers := sprint.NewHandlers(sprintService)
+	e := echo.New()
+
+	// Create tasks
+	newTask := func(project *models.Project, title string, status models.TaskStatus) *models.Task {
+		task := models.NewTask(models.TaskRequest{
+			ProjectID: &project.ID,
+			Title:     prefix + title,
+			Status:    status,
+			Priority:  models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	design := newTask(testProject, "Design", models.TaskStatusDone)
+	build := newTask(testProject, "Build", models.TaskStatusInProgress)
+	release := newTask(testProject, "Release", models.TaskStatusTodo)
+	elsewhere := newTask(otherProject, "Elsewhere", models.TaskStatusTodo)
+
+	start := time.Now().Truncate(time.Second)
+	end := start.AddDate(0, 0, 14)
+
+	var first, second *models.Sprint
+
+	t.Run("CreateSprint", func(t *testing.T) {
+		body, _ := json.Marshal(models.SprintRequest{
+			Name:      "Sprint 1",
+			Goal:      "Ship the beta",
+			StartDate: &start,
+			EndDate:   &end,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id/sprints")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+		c.Set("user_id", testUser.ID)
+
+		require.NoError(t, sprintHandlers.Create(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		first = &models.Sprint{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), first))
+		assert.Equal(t, "Sprint 1", first.Name)
+		assert.Equal(t, models.SprintStatePlanned, first.State)
+
+		second, _ = sprintService.Create(ctx, testProject.ID, models.SprintRequest{Name: "Sprint 2"}, testUser.ID)
+		require.NotNil(t, second)
+
+		// Sprints cannot end before they start
+		before := start.AddDate(0, 0, -1)
+		_, err := sprintService.Create(ctx, testProject.ID, models.SprintRequest{Name: "Backwards", StartDate: &start, EndDate: &before}, testUser.ID)
+		assert.ErrorIs(t, err, sprint.ErrInvalidDates)
+	})
+
+	t.Run("AddTasks", func(t *testing.T) {
+		updated, err := sprintService.AddTasks(ctx, first.ID, models.SprintTasksRequest{
+			TaskIDs: []uuid.UUID{design.ID, build.ID, release.ID, build.ID},
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated.TaskCount)
+
+		// Tasks of other projects are rejected, and nothing is added
+		_, err = sprintService.AddTasks(ctx, second.ID, models.SprintTasksRequest{
+			TaskIDs: []uuid.UUID{release.ID, elsewhere.ID},
+		}, testUser.ID)
+		assert.ErrorIs(t, err, sprint.ErrTaskNotInProject)
+		task, err := taskRepo.GetByID(ctx, release.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &first.ID, task.SprintID)
+
+		// Tasks return to the backlog
+		require.NoError(t, sprintService.RemoveTask(ctx, first.ID, release.ID, testUser.ID))
+		assert.ErrorIs(t, sprintService.RemoveTask(ctx, first.ID, release.ID, testUser.ID), repository.ErrTaskNotFound)
+		_, err = sprintService.AddTasks(ctx, first.ID, models.SprintTasksRequest{TaskIDs: []uuid.UUID{release.ID}}, testUser.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("StartSprint", func(t *testing.T) {
+		started, err := sprintService.Start(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SprintStateActive, started.State)
+		assert.NotNil(t, started.StartedAt)
+
+		// A project has one active sprint at a time
+		_, err = sprintService.Start(ctx, second.ID)
+		assert.ErrorIs(t, err, repository.ErrActiveSprintExists)
+
+		_, err = sprintService.Start(ctx, first.ID)
+		assert.ErrorIs(t, err, sprint.ErrSprintNotPlanned)
+
+		assert.ErrorIs(t, sprintService.Delete(ctx, first.ID), sprint.ErrSprintActive)
+
+		active := models.SprintStateActive
+		sprints, err := sprintService.List(ctx, testProject.ID, &active)
+		require.NoError(t, err)
+		if assert.Len(t, sprints, 1) {
+			assert.Equal(t, first.ID, sprints[0].ID)
+		}
+	})
+
+	t.Run("CloseSprint", func(t *testing.T) {
+		// Unfinished work only carries over to a planned sprint
+		_, err := sprintService.Close(ctx, first.ID, models.SprintCloseRequest{CarryOverTo: &first.ID}, testUser.ID)
+		assert.ErrorIs(t, err, sprint.ErrInvalidCarryOver)
+
+		body, _ := json.Marshal(models.SprintCloseRequest{CarryOverTo: &second.ID})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/sprints/:id/close")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), first.ID.String())
+		c.Set("user_id", testUser.ID)
+
+		require.NoError(t, sprintHandlers.Close(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var closed models.Sprint
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &closed))
+		assert.Equal(t, models.SprintStateClosed, closed.State)
+		assert.NotNil(t, closed.ClosedAt)
+		assert.Equal(t, 1, closed.CompletedTasks)
+		assert.Equal(t, 2, closed.CarriedOverTasks)
+		assert.Equal(t, 1, closed.TaskCount)
+
+		next, err := sprintService.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, next.TaskCount)
+
+		// Closed sprints cannot change
+		_, err = sprintService.Update(ctx, first.ID, models.SprintRequest{Name: "Renamed"})
+		assert.ErrorIs(t, err, sprint.ErrSprintClosed)
+		_, err = sprintService.Close(ctx, first.ID, models.SprintCloseRequest{}, testUser.ID)
+		assert.ErrorIs(t, err, sprint.ErrSprintNotActive)
+	})
+
+	t.Run("DeleteSprint", func(t *testing.T) {
+		require.NoError(t, sprintService.Delete(ctx, second.ID))
+
+		// Its tasks return to the backlog
+		task, err := taskRepo.GetByID(ctx, build.ID)
+		require.NoError(t, err)
+		assert.Nil(t, task.SprintID)
+
+		_, err = sprintService.GetByID(ctx, second.ID)
+		assert.ErrorIs(t, err, repository.ErrSprintNotFound)
+	})
+}
@@ -0,0 +1,472 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+)
+
+// bulkPageSize is the page size used to list the tasks matching a filter
+const bulkPageSize = 100
+
+// bulkChange is a task changed by a bulk update, with what to notify about
+type bulkChange struct {
+	task          *models.Task
+	assigned      bool
+	statusChanged bool
+	closing       bool
+}
+
+// bulkRun holds the state of a bulk operation while it runs
+type bulkRun struct {
+	req    models.BulkTaskRequest
+	userID uuid.UUID
+
+	// deleted holds the tasks deleted so far, including cascaded subtasks
+	deleted map[uuid.UUID]bool
+
+	// permissions caches permission checks by organization and permission
+	permissions map[string]bool
+}
+
+// Bulk applies an update or delete to a set of tasks
+func (s *serviceImpl) Bulk(ctx context.Context, req models.BulkTaskRequest, userID uuid.UUID) (*models.BulkTaskResponse, error) {
+	if err := s.validateBulk(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	taskIDs, err := s.bulkTaskIDs(ctx, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &bulkRun{
+		req:         req,
+		userID:      userID,
+		deleted:     make(map[uuid.UUID]bool),
+		permissions: make(map[string]bool),
+	}
+	response := &models.BulkTaskResponse{
+		ID:      uuid.New(),
+		Action:  req.Action,
+		Mode:    req.Mode,
+		Results: make([]models.BulkTaskItemResult, len(taskIDs)),
+	}
+	changes := []bulkChange{}
+
+	if req.Mode == models.BulkTaskModeAtomic {
+		// Stop at the first failure; the changes made so far are rolled back
+		failedAt := -1
+		err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+			for i, taskID := range taskIDs {
+				status, change, err := s.applyBulk(ctx, run, taskID)
+				if err != nil {
+					failedAt = i
+					response.Results[i] = failedBulkResult(taskID, err)
+					return err
+				}
+				response.Results[i] = models.BulkTaskItemResult{TaskID: taskID, Status: status}
+				if change != nil {
+					changes = append(changes, *change)
+				}
+			}
+			return nil
+		})
+
+		if failedAt >= 0 {
+			for i, taskID := range taskIDs {
+				switch {
+				case i < failedAt:
+					response.Results[i] = models.BulkTaskItemResult{TaskID: taskID, Status: models.BulkTaskItemStatusRolledBack}
+				case i > failedAt:
+					response.Results[i] = models.BulkTaskItemResult{TaskID: taskID, Status: models.BulkTaskItemStatusSkipped}
+				}
+			}
+			response.Failed = 1
+			return response, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		response.Committed = true
+		response.Succeeded = len(taskIDs)
+	} else {
+		// Apply each task in its own transaction
+		for i, taskID := range taskIDs {
+			var status models.BulkTaskItemStatus
+			var change *bulkChange
+			err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+				var err error
+				status, change, err = s.applyBulk(ctx, run, taskID)
+				return err
+			})
+			if err != nil {
+				response.Results[i] = failedBulkResult(taskID, err)
+				response.Failed++
+				continue
+			}
+
+			response.Results[i] = models.BulkTaskItemResult{TaskID: taskID, Status: status}
+			response.Succeeded++
+			if change != nil {
+				changes = append(changes, *change)
+			}
+		}
+
+		response.Committed = response.Succeeded > 0
+	}
+
+	s.notifyBulk(ctx, response.ID, userID, changes)
+
+	return response, nil
+}
+
+// validateBulk checks a bulk request and fills in its defaults
+func (s *serviceImpl) validateBulk(ctx context.Context, req *models.BulkTaskRequest) error {
+	if (len(req.TaskIDs) == 0) == (req.Filter == "") {
+		return fmt.Errorf("%w: either task_ids or filter is required", ErrInvalidBulkOperation)
+	}
+	if len(req.TaskIDs) > models.MaxBulkTasks {
+		return fmt.Errorf("%w: at most %d tasks", ErrBulkLimitExceeded, models.MaxBulkTasks)
+	}
+	if req.Mode == "" {
+		req.Mode = models.BulkTaskModeAtomic
+	}
+
+	switch req.Action {
+	case models.BulkTaskActionDelete:
+		if req.Policy == "" {
+			req.Policy = string(ChildPolicyReparent)
+		}
+		if ChildPolicy(req.Policy) != ChildPolicyReparent && ChildPolicy(req.Policy) != ChildPolicyCascade {
+			return ErrInvalidPolicy
+		}
+
+	case models.BulkTaskActionUpdate:
+		changes := req.Changes
+		if changes == nil || changes.IsEmpty() {
+			return fmt.Errorf("%w: changes are required", ErrInvalidBulkOperation)
+		}
+		if changes.AssignedTo != nil && changes.Unassign {
+			return fmt.Errorf("%w: assigned_to and unassign are exclusive", ErrInvalidBulkOperation)
+		}
+		if changes.DueDate != nil && changes.ClearDueDate {
+			return fmt.Errorf("%w: due_date and clear_due_date are exclusive", ErrInvalidBulkOperation)
+		}
+
+		// The assignee and project are the same for every task, so they are
+		// checked once
+		if changes.AssignedTo != nil {
+			if _, err := s.userRepo.GetByID(ctx, *changes.AssignedTo); err != nil {
+				return err
+			}
+		}
+		if changes.ProjectID != nil {
+			if _, err := s.projectRepo.GetByID(ctx, *changes.ProjectID); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidBulkOperation, req.Action)
+	}
+
+	return nil
+}
+
+// bulkTaskIDs returns the IDs of the tasks a bulk request applies to, without
+// duplicates
+func (s *serviceImpl) bulkTaskIDs(ctx context.Context, req models.BulkTaskRequest, userID uuid.UUID) ([]uuid.UUID, error) {
+	if len(req.TaskIDs) > 0 {
+		seen := make(map[uuid.UUID]bool, len(req.TaskIDs))
+		taskIDs := make([]uuid.UUID, 0, len(req.TaskIDs))
+		for _, taskID := range req.TaskIDs {
+			if !seen[taskID] {
+				seen[taskID] = true
+				taskIDs = append(taskIDs, taskID)
+			}
+		}
+		return taskIDs, nil
+	}
+
+	filter, err := models.ParseTaskFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params := models.TaskListParams{
+		ProjectID:      req.ProjectID,
+		Filter:         filter,
+		CurrentUserID:  &userID,
+		OrganizationID: req.OrganizationID,
+		SortBy:         "created_at",
+		SortOrder:      "asc",
+		PageSize:       bulkPageSize,
+	}
+
+	// The filter matches the tasks of the organization's projects and the
+	// user's own tasks without a project, never those of other organizations
+	if req.OrganizationID != nil {
+		params.ScopeOrganizationID = req.OrganizationID
+	} else if req.ProjectID == nil {
+		params.ScopeOrganizationID = &uuid.UUID{}
+	}
+
+	taskIDs := []uuid.UUID{}
+	for params.Page = 1; ; params.Page++ {
+		tasks, total, err := s.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if total > models.MaxBulkTasks {
+			return nil, fmt.Errorf("%w: filter matches %d tasks, at most %d are allowed", ErrBulkLimitExceeded, total, models.MaxBulkTasks)
+		}
+
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		if len(tasks) < bulkPageSize || len(taskIDs) >= total {
+			return taskIDs, nil
+		}
+	}
+}
+
+// applyBulk applies a bulk operation to a task and returns its outcome, along
+// with the change to notify about if any
+func (s *serviceImpl) applyBulk(ctx context.Context, run *bulkRun, taskID uuid.UUID) (models.BulkTaskItemStatus, *bulkChange, error) {
+	// Subtasks deleted with their parent earlier in the operation
+	if run.deleted[taskID] {
+		return models.BulkTaskItemStatusDeleted, nil, nil
+	}
+
+	task, err := s.getWritableTask(ctx, taskID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Tasks without a project belong to no organization, so only their
+	// creator may change them, as with the tasks a filter selects
+	if task.ProjectID == nil && task.CreatedBy != run.userID {
+		return "", nil, ErrPermissionDenied
+	}
+
+	if run.req.Action == models.BulkTaskActionDelete {
+		if err := s.checkBulkPermission(ctx, run, task.ProjectID, middleware.PermissionTaskDelete); err != nil {
+			return "", nil, err
+		}
+
+		// Subtasks are deleted with their parent unless they are moved away first
+		var descendants []models.Task
+		if ChildPolicy(run.req.Policy) == ChildPolicyReparent {
+			if err := s.taskRepo.ReparentChildren(ctx, taskID, task.ParentID); err != nil {
+				return "", nil, err
+			}
+		} else {
+			descendants, err = s.taskRepo.GetDescendants(ctx, taskID)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		if err := s.taskRepo.Delete(ctx, taskID, run.userID); err != nil {
+			return "", nil, err
+		}
+		run.deleted[taskID] = true
+		for _, descendant := range descendants {
+			run.deleted[descendant.ID] = true
+		}
+
+		return models.BulkTaskItemStatusDeleted, nil, nil
+	}
+
+	if err := s.checkBulkPermission(ctx, run, task.ProjectID, middleware.PermissionTaskWrite); err != nil {
+		return "", nil, err
+	}
+
+	before := *task
+	oldStatus := task.Status
+	changes := run.req.Changes
+
+	// Moving a task needs permission in the target project too, and its custom
+	// fields must be defined there
+	if changes.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *changes.ProjectID) {
+		if err := s.checkBulkPermission(ctx, run, changes.ProjectID, middleware.PermissionTaskWrite); err != nil {
+			return "", nil, err
+		}
+		if err := s.checkProjectWritable(ctx, *changes.ProjectID); err != nil {
+			return "", nil, err
+		}
+		task.ProjectID = changes.ProjectID
+		task.CustomFields, err = s.validateCustomFields(ctx, task.ProjectID, task.CustomFields)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if changes.Status != nil {
+		task.Status = *changes.Status
+	}
+	if changes.Priority != nil {
+		task.Priority = *changes.Priority
+	}
+	if changes.AssignedTo != nil {
+		task.AssignedTo = changes.AssignedTo
+	}
+	if changes.Unassign {
+		task.AssignedTo = nil
+	}
+	if changes.DueDate != nil {
+		task.DueDate = changes.DueDate
+	}
+	if changes.ClearDueDate {
+		task.DueDate = nil
+	}
+
+	tags := make([]string, 0, len(task.Tags)+len(changes.AddTags))
+	for _, tag := range task.Tags {
+		if !models.HasTag(changes.RemoveTags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range changes.AddTags {
+		if !models.HasTag(tags, tag) && !models.HasTag(changes.RemoveTags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	task.Tags = tags
+
+	if len(models.DiffTasks(&before, task)) == 0 {
+		return models.BulkTaskItemStatusUnchanged, nil, nil
+	}
+
+	// The status change must be allowed by the workflow of the task's project
+	workflow, err := s.workflowSvc.ForTask(ctx, task)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.checkTransition(ctx, workflow, task, oldStatus, run.userID); err != nil {
+		return "", nil, err
+	}
+
+	// A task cannot be closed while it is blocked, unless forced
+	closing := workflow.IsClosed(task.Status) && !workflow.IsClosed(oldStatus)
+	if closing && !run.req.Force {
+		if err := s.checkBlockers(ctx, taskID); err != nil {
+			return "", nil, err
+		}
+	}
+
+	task.UpdatedAt = time.Now()
+	if err := s.updateWithHistory(ctx, &before, task, run.userID); err != nil {
+		return "", nil, err
+	}
+
+	assigned := task.AssignedTo != nil && (before.AssignedTo == nil || *before.AssignedTo != *task.AssignedTo)
+	return models.BulkTaskItemStatusUpdated, &bulkChange{
+		task:          task,
+		assigned:      assigned,
+		statusChanged: task.Status != oldStatus,
+		closing:       closing,
+	}, nil
+}
+
+// checkBulkPermission checks that the user running a bulk operation holds a
+// permission in the organization of a project, or in the organization of the
+// request for tasks without a project
+func (s *serviceImpl) checkBulkPermission(ctx context.Context, run *bulkRun, projectID *uuid.UUID, permission string) error {
+	organizationID := run.req.OrganizationID
+	if projectID != nil {
+		project, err := s.projectRepo.GetByID(ctx, *projectID)
+		if err != nil {
+			return err
+		}
+		organizationID = &project.OrganizationID
+	}
+	if organizationID == nil {
+		return ErrPermissionDenied
+	}
+
+	key := organizationID.String() + "/" + permission
+	hasPermission, ok := run.permissions[key]
+	if !ok {
+		var err error
+		hasPermission, err = s.roleRepo.HasPermission(ctx, run.userID, *organizationID, permission)
+		if err != nil {
+			return err
+		}
+		run.permissions[key] = hasPermission
+	}
+
+	if !hasPermission {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// notifyBulk sends one notification to each user with tasks of a bulk
+// operation assigned to them or whose status changed, once it is committed
+func (s *serviceImpl) notifyBulk(ctx context.Context, operationID, updatedBy uuid.UUID, changes []bulkChange) {
+	users := []uuid.UUID{}
+	assigned := make(map[uuid.UUID][]models.Task)
+	statusChanged := make(map[uuid.UUID][]models.Task)
+	addUser := func(userID uuid.UUID) {
+		if _, ok := assigned[userID]; ok {
+			return
+		}
+		if _, ok := statusChanged[userID]; ok {
+			return
+		}
+		users = append(users, userID)
+	}
+
+	for _, change := range changes {
+		task := change.task
+		if change.assigned {
+			addUser(*task.AssignedTo)
+			assigned[*task.AssignedTo] = append(assigned[*task.AssignedTo], *task)
+		}
+
+		// Status updates go to the creator and the assignee, as for a single task
+		if change.statusChanged {
+			addUser(task.CreatedBy)
+			statusChanged[task.CreatedBy] = append(statusChanged[task.CreatedBy], *task)
+			if task.AssignedTo != nil && *task.AssignedTo != task.CreatedBy {
+				addUser(*task.AssignedTo)
+				statusChanged[*task.AssignedTo] = append(statusChanged[*task.AssignedTo], *task)
+			}
+		}
+	}
+
+	for _, userID := range users {
+		err := s.notificationSvc.NotifyTasksBulkUpdated(ctx, userID, operationID, updatedBy, assigned[userID], statusChanged[userID])
+		if err != nil {
+			// Log the error but don't fail the operation
+			logger.FromContext(ctx).Error("failed to send bulk task notification",
+				slog.String("user_id", userID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	for _, change := range changes {
+		if change.closing {
+			s.notifyUnblockedDependents(ctx, change.task)
+		}
+	}
+}
+
+// failedBulkResult returns the result of a task a bulk operation failed on
+func failedBulkResult(taskID uuid.UUID, err error) models.BulkTaskItemResult {
+	return models.BulkTaskItemResult{
+		TaskID: taskID,
+		Status: models.BulkTaskItemStatusFailed,
+		Error:  err.Error(),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
@@ -56,6 +57,18 @@ func (h *Handlers) Create(c echo.Context) error {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
+		if errors.Is(err, ErrInvalidParent) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid parent task")
+		}
+		if errors.Is(err, ErrInvalidTaskStatus) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid task status")
+		}
+		if errors.Is(err, models.ErrInvalidCustomField) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create task")
 	}
 
@@ -98,6 +111,18 @@ func (h *Handlers) List(c echo.Context) error {
 		params.ProjectID = &projectID
 	}
 
+	// Parse parent_id parameter. "root" selects tasks without a parent.
+	parentIDParam := c.QueryParam("parent_id")
+	if parentIDParam == "root" {
+		params.TopLevel = true
+	} else if parentIDParam != "" {
+		parentID, err := uuid.Parse(parentIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid parent_id parameter")
+		}
+		params.ParentID = &parentID
+	}
+
 	// Parse status parameter
 	statusParam := c.QueryParam("status")
 	if statusParam != "" {
@@ -158,12 +183,37 @@ func (h *Handlers) List(c echo.Context) error {
 		params.SearchTerm = &searchParam
 	}
 
-	// Parse sort_by parameter
+	// Parse filter parameter, a filter expression such as "status in (todo,review) and due<7d"
+	filterParam := c.QueryParam("filter")
+	if filterParam != "" {
+		filter, err := models.ParseTaskFilter(filterParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		params.Filter = filter
+	}
+
+	// "me" in filter expressions is the current user
+	if userID, err := middleware.GetUserIDFromContext(c); err == nil {
+		params.CurrentUserID = &userID
+	}
+
+	// Parse sort_by parameter. "cf.<key>" sorts by a custom field.
 	sortByParam := c.QueryParam("sort_by")
-	if sortByParam != "" {
+	if strings.HasPrefix(sortByParam, customFieldParamPrefix) {
+		params.CustomFieldSort = &models.CustomFieldSort{Key: strings.TrimPrefix(sortByParam, customFieldParamPrefix)}
+	} else if sortByParam != "" {
 		params.SortBy = sortByParam
 	}
 
+	// Parse custom field filters
+	params.CustomFieldFilters = parseCustomFieldFilters(c)
+
+	// Custom fields of tasks outside a project are resolved in the organization
+	if orgID, err := uuid.Parse(c.Param("org_id")); err == nil {
+		params.OrganizationID = &orgID
+	}
+
 	// Parse sort_order parameter
 	sortOrderParam := c.QueryParam("sort_order")
 	if sortOrderParam != "" {
@@ -197,6 +247,12 @@ func (h *Handlers) List(c echo.Context) error {
 	// Get tasks
 	tasks, total, err := h.service.List(c.Request().Context(), params)
 	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if errors.Is(err, models.ErrInvalidCustomField) || errors.Is(err, models.ErrInvalidTaskFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve tasks")
 	}
 
@@ -216,6 +272,12 @@ func (h *Handlers) List(c echo.Context) error {
 
 // Update handles updating a task
 func (h *Handlers) Update(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
 	// Get task ID from path parameter
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -235,7 +297,7 @@ func (h *Handlers) Update(c echo.Context) error {
 	}
 
 	// Update task
-	response, err := h.service.Update(c.Request().Context(), id, req)
+	response, err := h.service.Update(c.Request().Context(), id, req, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
@@ -246,6 +308,22 @@ func (h *Handlers) Update(c echo.Context) error {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
+		if errors.Is(err, ErrInvalidParent) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid parent task")
+		}
+		if errors.Is(err, models.ErrInvalidCustomField) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if httpErr := transitionError(err); httpErr != nil {
+			return httpErr
+		}
+		var blockedErr *BlockedError
+		if errors.As(err, &blockedErr) {
+			return blockedResponse(c, blockedErr)
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update task")
 	}
 
@@ -261,20 +339,112 @@ func (h *Handlers) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
 	}
 
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse children parameter
+	policy := ChildPolicyReparent
+	childrenParam := c.QueryParam("children")
+	if childrenParam != "" {
+		policy = ChildPolicy(childrenParam)
+	}
+
 	// Delete task
-	err = h.service.Delete(c.Request().Context(), id)
+	err = h.service.Delete(c.Request().Context(), id, policy, userID)
 	if err != nil {
+		if errors.Is(err, ErrInvalidPolicy) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid children parameter")
+		}
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete task")
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// Bulk handles applying an update or delete to a set of tasks
+func (h *Handlers) Bulk(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse request body
+	var req models.BulkTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Permissions on tasks without a project are checked in the organization
+	if orgID, err := uuid.Parse(c.Param("org_id")); err == nil {
+		req.OrganizationID = &orgID
+	}
+
+	// Apply the operation
+	response, err := h.service.Bulk(c.Request().Context(), req, userID)
+	if err != nil {
+		if errors.Is(err, ErrInvalidBulkOperation) || errors.Is(err, ErrBulkLimitExceeded) ||
+			errors.Is(err, models.ErrInvalidTaskFilter) || errors.Is(err, models.ErrInvalidCustomField) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, ErrInvalidPolicy) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid subtask delete policy")
+		}
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to apply bulk operation")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetTree handles retrieving a task with all of its subtasks
+func (h *Handlers) GetTree(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get task tree
+	response, err := h.service.GetTree(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve task tree")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 // AddTag handles adding a tag to a task
 func (h *Handlers) AddTag(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
 	// Get task ID from path parameter
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -296,11 +466,14 @@ func (h *Handlers) AddTag(c echo.Context) error {
 	}
 
 	// Add tag
-	err = h.service.AddTag(c.Request().Context(), id, req.Tag)
+	err = h.service.AddTag(c.Request().Context(), id, req.Tag, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add tag")
 	}
 
@@ -309,6 +482,12 @@ func (h *Handlers) AddTag(c echo.Context) error {
 
 // RemoveTag handles removing a tag from a task
 func (h *Handlers) RemoveTag(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
 	// Get task ID from path parameter
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -323,11 +502,14 @@ func (h *Handlers) RemoveTag(c echo.Context) error {
 	}
 
 	// Remove tag
-	err = h.service.RemoveTag(c.Request().Context(), id, tag)
+	err = h.service.RemoveTag(c.Request().Context(), id, tag, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove tag")
 	}
 
@@ -371,6 +553,9 @@ func (h *Handlers) AssignTask(c echo.Context) error {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "User not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to assign task")
 	}
 
@@ -401,6 +586,9 @@ func (h *Handlers) UnassignTask(c echo.Context) error {
 		if errors.Is(err, errors.New("task is not assigned to anyone")) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Task is not assigned to anyone")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to unassign task")
 	}
 
@@ -425,6 +613,7 @@ func (h *Handlers) UpdateTaskStatus(c echo.Context) error {
 	// Parse request body
 	var req struct {
 		Status models.TaskStatus `json:"status" validate:"required"`
+		Force  bool              `json:"force"`
 	}
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
@@ -436,13 +625,20 @@ func (h *Handlers) UpdateTaskStatus(c echo.Context) error {
 	}
 
 	// Update task status
-	response, err := h.service.UpdateTaskStatus(c.Request().Context(), id, req.Status, userID)
+	response, err := h.service.UpdateTaskStatus(c.Request().Context(), id, req.Status, userID, req.Force)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
 		}
-		if errors.Is(err, errors.New("invalid task status")) {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid task status")
+		if httpErr := transitionError(err); httpErr != nil {
+			return httpErr
+		}
+		var blockedErr *BlockedError
+		if errors.As(err, &blockedErr) {
+			return blockedResponse(c, blockedErr)
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update task status")
 	}
@@ -742,6 +938,314 @@ func (h *Handlers) GetTasksDueSoon(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// ListLinks handles retrieving the links of a task
+func (h *Handlers) ListLinks(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get links
+	links, err := h.service.ListLinks(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve task links")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"links": links,
+	})
+}
+
+// AddLink handles linking a task to another task
+func (h *Handlers) AddLink(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse request body
+	var req models.TaskLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Add link
+	response, err := h.service.AddLink(c.Request().Context(), id, req, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if errors.Is(err, ErrSelfLink) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Task cannot be linked to itself")
+		}
+		if errors.Is(err, repository.ErrTaskLinkExists) {
+			return echo.NewHTTPError(http.StatusConflict, "Task link already exists")
+		}
+		if errors.Is(err, repository.ErrTaskLinkCycle) {
+			return echo.NewHTTPError(http.StatusConflict, "Task link would create a blocking cycle")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add task link")
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// RemoveLink handles removing a link from a task
+func (h *Handlers) RemoveLink(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get link ID from path parameter
+	linkIDParam := c.Param("link_id")
+	linkID, err := uuid.Parse(linkIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid link ID")
+	}
+
+	// Remove link
+	err = h.service.RemoveLink(c.Request().Context(), id, linkID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if errors.Is(err, repository.ErrTaskLinkNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task link not found")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove task link")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListChecklistItems handles retrieving the checklist of a task
+func (h *Handlers) ListChecklistItems(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get checklist items
+	items, err := h.service.ListChecklistItems(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve checklist")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"items": items,
+	})
+}
+
+// AddChecklistItem handles adding an item to the checklist of a task
+func (h *Handlers) AddChecklistItem(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse request body
+	var req models.ChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Add checklist item
+	response, err := h.service.AddChecklistItem(c.Request().Context(), id, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add checklist item")
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+// UpdateChecklistItem handles updating an item of the checklist of a task
+func (h *Handlers) UpdateChecklistItem(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get item ID from path parameter
+	itemIDParam := c.Param("item_id")
+	itemID, err := uuid.Parse(itemIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid checklist item ID")
+	}
+
+	// Parse request body
+	var req models.ChecklistItemRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Update checklist item
+	response, err := h.service.UpdateChecklistItem(c.Request().Context(), id, itemID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if errors.Is(err, repository.ErrChecklistItemNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Checklist item not found")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update checklist item")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// DeleteChecklistItem handles removing an item from the checklist of a task
+func (h *Handlers) DeleteChecklistItem(c echo.Context) error {
+	// Get task ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get item ID from path parameter
+	itemIDParam := c.Param("item_id")
+	itemID, err := uuid.Parse(itemIDParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid checklist item ID")
+	}
+
+	// Delete checklist item
+	err = h.service.DeleteChecklistItem(c.Request().Context(), id, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		if errors.Is(err, repository.ErrChecklistItemNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Checklist item not found")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete checklist item")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// customFieldParamPrefix prefixes query parameters that refer to custom fields
+const customFieldParamPrefix = "cf."
+
+// parseCustomFieldFilters parses custom field filters from query parameters:
+// cf.<key>=value matches a value, cf.<key>.gte and cf.<key>.lte bound it
+func parseCustomFieldFilters(c echo.Context) []models.CustomFieldFilter {
+	var filters []models.CustomFieldFilter
+	for name, values := range c.QueryParams() {
+		if !strings.HasPrefix(name, customFieldParamPrefix) || len(values) == 0 {
+			continue
+		}
+
+		filter := models.CustomFieldFilter{
+			Key:      strings.TrimPrefix(name, customFieldParamPrefix),
+			Operator: models.CustomFieldOperatorEq,
+			Value:    values[len(values)-1],
+		}
+		for _, operator := range []models.CustomFieldOperator{models.CustomFieldOperatorGte, models.CustomFieldOperatorLte} {
+			if suffix := "." + string(operator); strings.HasSuffix(filter.Key, suffix) {
+				filter.Key = strings.TrimSuffix(filter.Key, suffix)
+				filter.Operator = operator
+			}
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
+
+// transitionError maps errors from moving a task between workflow states to
+// an HTTP error. It returns nil if err is not such an error.
+func transitionError(err error) *echo.HTTPError {
+	if errors.Is(err, ErrInvalidTaskStatus) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task status")
+	}
+	if errors.Is(err, ErrTransitionNotAllowed) {
+		return echo.NewHTTPError(http.StatusConflict, "Status transition is not allowed by the workflow")
+	}
+
+	var guardErr *TransitionGuardError
+	if errors.As(err, &guardErr) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+			"message":        "Status transition requirements are not met",
+			"missing_fields": guardErr.MissingFields,
+			"required_role":  guardErr.RequiredRole,
+		})
+	}
+
+	return nil
+}
+
+// blockedResponse responds with the open tasks that block a task
+func blockedResponse(c echo.Context, err *BlockedError) error {
+	blockers := make([]models.TaskResponse, 0, len(err.Blockers))
+	for _, blocker := range err.Blockers {
+		blockers = append(blockers, blocker.ToResponse())
+	}
+
+	return c.JSON(http.StatusConflict, map[string]interface{}{
+		"message":  "Task is blocked by open tasks",
+		"blockers": blockers,
+	})
+}
+
 // RegisterRoutes registers the task routes
 func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
 	taskGroup := g.Group("/tasks")
@@ -752,15 +1256,24 @@ func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBAC
 	taskGroup.GET("/assignee/:user_id", h.GetTasksByAssignee, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
 	taskGroup.GET("/overdue", h.GetOverdueTasks, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
 	taskGroup.GET("/due-soon/:days", h.GetTasksDueSoon, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+	taskGroup.GET("/:id/links", h.ListLinks, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+	taskGroup.GET("/:id/tree", h.GetTree, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+	taskGroup.GET("/:id/checklist", h.ListChecklistItems, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
 
 	// Routes that require task:write permission
 	taskGroup.POST("", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.POST("/bulk", h.Bulk, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.PUT("/:id", h.Update, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.POST("/:id/tags", h.AddTag, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.DELETE("/:id/tags/:tag", h.RemoveTag, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.POST("/:id/assign", h.AssignTask, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.POST("/:id/unassign", h.UnassignTask, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 	taskGroup.PUT("/:id/status", h.UpdateTaskStatus, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.POST("/:id/links", h.AddLink, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.DELETE("/:id/links/:link_id", h.RemoveLink, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.POST("/:id/checklist", h.AddChecklistItem, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.PUT("/:id/checklist/:item_id", h.UpdateChecklistItem, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+	taskGroup.DELETE("/:id/checklist/:item_id", h.DeleteChecklistItem, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
 
 	// Routes that require task:delete permission
 	taskGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionTaskDelete))
@@ -4,14 +4,84 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/Jerinji2016/halooid/backend/internal/notification"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 	"github.com/google/uuid"
 )
 
+// Common errors
+var (
+	ErrInvalidTaskStatus    = errors.New("invalid task status")
+	ErrTransitionNotAllowed = errors.New("status transition is not allowed by the workflow")
+	ErrTransitionGuard      = errors.New("status transition requirements are not met")
+	ErrTaskBlocked          = errors.New("task is blocked by open tasks")
+	ErrSelfLink             = errors.New("task cannot be linked to itself")
+	ErrInvalidParent        = errors.New("invalid parent task")
+	ErrInvalidPolicy        = errors.New("invalid subtask delete policy")
+	ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+	ErrBulkLimitExceeded    = errors.New("too many tasks for a bulk operation")
+	ErrPermissionDenied     = errors.New("permission denied")
+)
+
+// ChildPolicy defines what happens to the subtasks of a deleted task
+type ChildPolicy string
+
+// Child policies
+const (
+	// ChildPolicyReparent moves the subtasks to the parent of the deleted task
+	ChildPolicyReparent ChildPolicy = "reparent"
+
+	// ChildPolicyCascade deletes the subtasks along with the task
+	ChildPolicyCascade ChildPolicy = "cascade"
+)
+
+// BlockedError is returned when a task cannot be completed because of open blockers
+type BlockedError struct {
+	Blockers []models.Task
+}
+
+// Error implements the error interface
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s: %d open blocker(s)", ErrTaskBlocked, len(e.Blockers))
+}
+
+// Is reports whether target is ErrTaskBlocked
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrTaskBlocked
+}
+
+// TransitionGuardError is returned when a workflow transition is allowed but
+// its guards are not met
+type TransitionGuardError struct {
+	MissingFields []string
+	RequiredRole  string
+}
+
+// Error implements the error interface
+func (e *TransitionGuardError) Error() string {
+	var reasons []string
+	if len(e.MissingFields) > 0 {
+		reasons = append(reasons, "missing fields "+strings.Join(e.MissingFields, ", "))
+	}
+	if e.RequiredRole != "" {
+		reasons = append(reasons, "requires role "+e.RequiredRole)
+	}
+	return fmt.Sprintf("%s: %s", ErrTransitionGuard, strings.Join(reasons, "; "))
+}
+
+// Is reports whether target is ErrTransitionGuard
+func (e *TransitionGuardError) Is(target error) bool {
+	return target == ErrTransitionGuard
+}
+
 // Service provides task management functionality
 type Service interface {
 	// Create creates a new task
@@ -23,17 +93,21 @@ type Service interface {
 	// List retrieves tasks based on filter parameters
 	List(ctx context.Context, params models.TaskListParams) ([]models.TaskResponse, int, error)
 
-	// Update updates a task
-	Update(ctx context.Context, id uuid.UUID, req models.TaskRequest) (*models.TaskResponse, error)
+	// Update updates a task. Status changes must be allowed by the task's workflow.
+	Update(ctx context.Context, id uuid.UUID, req models.TaskRequest, updatedBy uuid.UUID) (*models.TaskResponse, error)
+
+	// Delete moves a task to the trash, handling its subtasks according to
+	// policy. Tasks in the trash can be restored until they are purged.
+	Delete(ctx context.Context, id uuid.UUID, policy ChildPolicy, deletedBy uuid.UUID) error
 
-	// Delete deletes a task
-	Delete(ctx context.Context, id uuid.UUID) error
+	// GetTree retrieves a task with all of its subtasks and their roll-ups
+	GetTree(ctx context.Context, id uuid.UUID) (*models.TaskTreeNode, error)
 
 	// AddTag adds a tag to a task
-	AddTag(ctx context.Context, taskID uuid.UUID, tag string) error
+	AddTag(ctx context.Context, taskID uuid.UUID, tag string, userID uuid.UUID) error
 
 	// RemoveTag removes a tag from a task
-	RemoveTag(ctx context.Context, taskID uuid.UUID, tag string) error
+	RemoveTag(ctx context.Context, taskID uuid.UUID, tag string, userID uuid.UUID) error
 
 	// AssignTask assigns a task to a user
 	AssignTask(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, assignedBy uuid.UUID) (*models.TaskResponse, error)
@@ -41,8 +115,30 @@ type Service interface {
 	// UnassignTask removes the assignment of a task
 	UnassignTask(ctx context.Context, taskID uuid.UUID, unassignedBy uuid.UUID) (*models.TaskResponse, error)
 
-	// UpdateTaskStatus updates the status of a task
-	UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, status models.TaskStatus, updatedBy uuid.UUID) (*models.TaskResponse, error)
+	// UpdateTaskStatus moves a task to another state of its workflow. Unless
+	// force is set, a task cannot be closed while any of its blockers are still open.
+	UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, status models.TaskStatus, updatedBy uuid.UUID, force bool) (*models.TaskResponse, error)
+
+	// AddLink links a task to another task
+	AddLink(ctx context.Context, taskID uuid.UUID, req models.TaskLinkRequest, createdBy uuid.UUID) (*models.TaskLinkResponse, error)
+
+	// RemoveLink removes a link from a task
+	RemoveLink(ctx context.Context, taskID uuid.UUID, linkID uuid.UUID) error
+
+	// ListLinks retrieves all links of a task
+	ListLinks(ctx context.Context, taskID uuid.UUID) ([]models.TaskLinkResponse, error)
+
+	// ListChecklistItems retrieves the checklist of a task
+	ListChecklistItems(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItemResponse, error)
+
+	// AddChecklistItem adds an item to the checklist of a task
+	AddChecklistItem(ctx context.Context, taskID uuid.UUID, req models.ChecklistItemRequest) (*models.ChecklistItemResponse, error)
+
+	// UpdateChecklistItem updates an item of the checklist of a task
+	UpdateChecklistItem(ctx context.Context, taskID uuid.UUID, itemID uuid.UUID, req models.ChecklistItemRequest) (*models.ChecklistItemResponse, error)
+
+	// DeleteChecklistItem removes an item from the checklist of a task
+	DeleteChecklistItem(ctx context.Context, taskID uuid.UUID, itemID uuid.UUID) error
 
 	// GetTasksByAssignee retrieves tasks assigned to a user
 	GetTasksByAssignee(ctx context.Context, userID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error)
@@ -52,28 +148,53 @@ type Service interface {
 
 	// GetTasksDueSoon retrieves tasks due within a specified number of days
 	GetTasksDueSoon(ctx context.Context, days int, params models.TaskListParams) ([]models.TaskResponse, int, error)
+
+	// Bulk applies an update or delete to a set of tasks, either all or
+	// nothing or to every task it can, and reports the outcome for each task
+	Bulk(ctx context.Context, req models.BulkTaskRequest, userID uuid.UUID) (*models.BulkTaskResponse, error)
 }
 
 // serviceImpl implements the Service interface
 type serviceImpl struct {
 	taskRepo        repository.TaskRepository
+	taskHistoryRepo repository.TaskHistoryRepository
+	taskLinkRepo    repository.TaskLinkRepository
+	checklistRepo   repository.ChecklistItemRepository
 	projectRepo     repository.ProjectRepository
 	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
 	notificationSvc notification.Service
+	workflowSvc     workflow.Service
+	customFieldSvc  customfield.Service
+	txManager       repository.TxManager
 }
 
 // NewService creates a new task service
 func NewService(
 	taskRepo repository.TaskRepository,
+	taskHistoryRepo repository.TaskHistoryRepository,
+	taskLinkRepo repository.TaskLinkRepository,
+	checklistRepo repository.ChecklistItemRepository,
 	projectRepo repository.ProjectRepository,
 	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
 	notificationSvc notification.Service,
+	workflowSvc workflow.Service,
+	customFieldSvc customfield.Service,
+	txManager repository.TxManager,
 ) Service {
 	return &serviceImpl{
 		taskRepo:        taskRepo,
+		taskHistoryRepo: taskHistoryRepo,
+		taskLinkRepo:    taskLinkRepo,
+		checklistRepo:   checklistRepo,
 		projectRepo:     projectRepo,
 		userRepo:        userRepo,
+		roleRepo:        roleRepo,
 		notificationSvc: notificationSvc,
+		workflowSvc:     workflowSvc,
+		customFieldSvc:  customFieldSvc,
+		txManager:       txManager,
 	}
 }
 
@@ -81,11 +202,7 @@ func NewService(
 func (s *serviceImpl) Create(ctx context.Context, req models.TaskRequest, createdBy uuid.UUID) (*models.TaskResponse, error) {
 	// Validate project if provided
 	if req.ProjectID != nil {
-		_, err := s.projectRepo.GetByID(ctx, *req.ProjectID)
-		if err != nil {
-			if errors.Is(err, repository.ErrProjectNotFound) {
-				return nil, repository.ErrProjectNotFound
-			}
+		if err := s.checkProjectWritable(ctx, *req.ProjectID); err != nil {
 			return nil, err
 		}
 	}
@@ -101,10 +218,50 @@ func (s *serviceImpl) Create(ctx context.Context, req models.TaskRequest, create
 		}
 	}
 
+	// Validate parent if provided. Subtasks inherit the project of their parent.
+	if req.ParentID != nil {
+		parent, err := s.taskRepo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				return nil, ErrInvalidParent
+			}
+			return nil, err
+		}
+		if req.ProjectID == nil {
+			if parent.Project.IsArchived() {
+				return nil, repository.ErrProjectArchived
+			}
+			req.ProjectID = parent.ProjectID
+		}
+	}
+
 	// Create task
 	task := models.NewTask(req, createdBy)
 
-	err := s.taskRepo.Create(ctx, task)
+	customFields, err := s.validateCustomFields(ctx, task.ProjectID, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	task.CustomFields = customFields
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// New tasks start in the initial state of their workflow unless a
+		// state is given. The workflow stays in place until the task is saved.
+		workflow, err := s.workflowSvc.ForTask(ctx, task)
+		if err != nil {
+			return err
+		}
+		if task.Status == "" {
+			task.Status = workflow.InitialState
+		} else if _, ok := workflow.State(task.Status); !ok {
+			return ErrInvalidTaskStatus
+		}
+
+		if err := s.taskRepo.Create(ctx, task); err != nil {
+			return err
+		}
+		return s.taskHistoryRepo.Create(ctx, models.NewTaskCreatedHistory(task.ID, createdBy))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -126,12 +283,23 @@ func (s *serviceImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.TaskRe
 		return nil, err
 	}
 
-	response := task.ToResponse()
-	return &response, nil
+	// Roll up progress and hours from subtasks and checklist items
+	tree, err := s.buildTree(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tree.TaskResponse, nil
 }
 
 // List retrieves tasks based on filter parameters
 func (s *serviceImpl) List(ctx context.Context, params models.TaskListParams) ([]models.TaskResponse, int, error) {
+	if len(params.CustomFieldFilters) > 0 || params.CustomFieldSort != nil {
+		if err := s.resolveCustomFieldParams(ctx, &params); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	tasks, total, err := s.taskRepo.List(ctx, params)
 	if err != nil {
 		return nil, 0, err
@@ -146,20 +314,17 @@ func (s *serviceImpl) List(ctx context.Context, params models.TaskListParams) ([
 }
 
 // Update updates a task
-func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TaskRequest) (*models.TaskResponse, error) {
+func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TaskRequest, updatedBy uuid.UUID) (*models.TaskResponse, error) {
 	// Check if task exists
-	task, err := s.taskRepo.GetByID(ctx, id)
+	task, err := s.getWritableTask(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *task
 
 	// Validate project if provided
 	if req.ProjectID != nil {
-		_, err := s.projectRepo.GetByID(ctx, *req.ProjectID)
-		if err != nil {
-			if errors.Is(err, repository.ErrProjectNotFound) {
-				return nil, repository.ErrProjectNotFound
-			}
+		if err := s.checkProjectWritable(ctx, *req.ProjectID); err != nil {
 			return nil, err
 		}
 	}
@@ -175,18 +340,72 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TaskR
 		}
 	}
 
+	// Validate parent if provided
+	if req.ParentID != nil {
+		if err := s.validateParent(ctx, id, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save old status for notifying dependents
+	oldStatus := task.Status
+	if req.Status == "" {
+		req.Status = oldStatus
+	}
+
 	// Update task fields
 	task.ProjectID = req.ProjectID
+	task.ParentID = req.ParentID
 	task.Title = req.Title
 	task.Description = req.Description
 	task.Status = req.Status
 	task.Priority = req.Priority
 	task.DueDate = req.DueDate
+	task.StartDate = req.StartDate
+	task.DurationDays = req.DurationDays
 	task.AssignedTo = req.AssignedTo
 	task.EstimatedHours = req.EstimatedHours
 	task.Tags = req.Tags
 
-	err = s.taskRepo.Update(ctx, task)
+	// Custom fields in the request are merged into the task's values; null
+	// clears a field. Tasks moved out of a project drop their values.
+	values := req.CustomFields
+	if task.ProjectID != nil {
+		values = make(map[string]interface{}, len(task.CustomFields)+len(req.CustomFields))
+		for key, value := range task.CustomFields {
+			values[key] = value
+		}
+		for key, value := range req.CustomFields {
+			values[key] = value
+		}
+	}
+	task.CustomFields, err = s.validateCustomFields(ctx, task.ProjectID, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status change must be allowed by the workflow of the task's
+	// project, which stays in place until the task is saved
+	var closing bool
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		workflow, err := s.workflowSvc.ForTask(ctx, task)
+		if err != nil {
+			return err
+		}
+		if err := s.checkTransition(ctx, workflow, task, oldStatus, updatedBy); err != nil {
+			return err
+		}
+
+		// A task cannot be closed while it is blocked
+		closing = workflow.IsClosed(task.Status) && !workflow.IsClosed(oldStatus)
+		if closing {
+			if err := s.checkBlockers(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		return s.updateWithHistory(ctx, &before, task, updatedBy)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -197,47 +416,303 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TaskR
 		return nil, err
 	}
 
+	if closing {
+		s.notifyUnblockedDependents(ctx, updatedTask)
+	}
+
 	response := updatedTask.ToResponse()
 	return &response, nil
 }
 
-// Delete deletes a task
-func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
+// validateCustomFields checks custom field values against the definitions
+// that apply to tasks of a project and returns them normalized
+func (s *serviceImpl) validateCustomFields(ctx context.Context, projectID *uuid.UUID, values map[string]interface{}) (models.CustomFieldValues, error) {
+	if projectID == nil {
+		for key, value := range values {
+			if value != nil {
+				return nil, &models.CustomFieldError{Key: key, Reason: "tasks without a project cannot have custom fields"}
+			}
+		}
+		return nil, nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, *projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customFieldSvc.Validate(ctx, models.CustomFieldEntityTask, project.OrganizationID, projectID, values)
+}
+
+// resolveCustomFieldParams sets the types of custom field filters from their
+// definitions and checks that filtered and sorted fields exist
+func (s *serviceImpl) resolveCustomFieldParams(ctx context.Context, params *models.TaskListParams) error {
+	organizationID := params.OrganizationID
+	if params.ProjectID != nil {
+		project, err := s.projectRepo.GetByID(ctx, *params.ProjectID)
+		if err != nil {
+			return err
+		}
+		organizationID = &project.OrganizationID
+	}
+	if organizationID == nil {
+		return fmt.Errorf("%w: filtering by custom fields requires an organization or project", models.ErrInvalidCustomField)
+	}
+
+	definitions, err := s.customFieldSvc.List(ctx, *organizationID, params.ProjectID, models.CustomFieldEntityTask)
+	if err != nil {
+		return err
+	}
+	types := make(map[string]models.CustomFieldType, len(definitions))
+	for _, definition := range definitions {
+		types[definition.Key] = definition.Type
+	}
+
+	filters := make([]models.CustomFieldFilter, 0, len(params.CustomFieldFilters))
+	for _, filter := range params.CustomFieldFilters {
+		fieldType, ok := types[filter.Key]
+		if !ok {
+			return &models.CustomFieldError{Key: filter.Key, Reason: "is not defined"}
+		}
+		if filter.Operator != models.CustomFieldOperatorEq &&
+			fieldType != models.CustomFieldTypeNumber && fieldType != models.CustomFieldTypeDate {
+			return &models.CustomFieldError{Key: filter.Key, Reason: "only number and date fields support range filters"}
+		}
+		filter.Type = fieldType
+		filters = append(filters, filter)
+	}
+	params.CustomFieldFilters = filters
+
+	if params.CustomFieldSort != nil {
+		if _, ok := types[params.CustomFieldSort.Key]; !ok {
+			return &models.CustomFieldError{Key: params.CustomFieldSort.Key, Reason: "is not defined"}
+		}
+	}
+
+	return nil
+}
+
+// Delete moves a task to the trash, handling its subtasks according to policy
+func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID, policy ChildPolicy, deletedBy uuid.UUID) error {
+	if policy != ChildPolicyReparent && policy != ChildPolicyCascade {
+		return ErrInvalidPolicy
+	}
+
 	// Check if task exists
-	_, err := s.taskRepo.GetByID(ctx, id)
+	task, err := s.getWritableTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Subtasks go to the trash with their parent unless they are moved away first
+		if policy == ChildPolicyReparent {
+			if err := s.taskRepo.ReparentChildren(ctx, id, task.ParentID); err != nil {
+				return err
+			}
+		}
+
+		return s.taskRepo.Delete(ctx, id, deletedBy)
+	})
+}
+
+// GetTree retrieves a task with all of its subtasks and their roll-ups
+func (s *serviceImpl) GetTree(ctx context.Context, id uuid.UUID) (*models.TaskTreeNode, error) {
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.buildTree(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+// buildTree loads the subtasks and checklists below task and builds its tree
+func (s *serviceImpl) buildTree(ctx context.Context, task *models.Task) (models.TaskTreeNode, error) {
+	descendants, err := s.taskRepo.GetDescendants(ctx, task.ID)
+	if err != nil {
+		return models.TaskTreeNode{}, err
+	}
+
+	taskIDs := make([]uuid.UUID, 0, len(descendants)+1)
+	taskIDs = append(taskIDs, task.ID)
+	for _, descendant := range descendants {
+		taskIDs = append(taskIDs, descendant.ID)
+	}
+
+	checklists, err := s.checklistRepo.ListByTasks(ctx, taskIDs)
+	if err != nil {
+		return models.TaskTreeNode{}, err
+	}
+
+	// Always include the checklist of the requested task, even if empty
+	if _, ok := checklists[task.ID]; !ok {
+		checklists[task.ID] = []models.ChecklistItem{}
+	}
+
+	closed, err := s.closedTasks(ctx, task, descendants)
+	if err != nil {
+		return models.TaskTreeNode{}, err
+	}
+
+	return models.BuildTaskTree(task, descendants, checklists, closed), nil
+}
+
+// closedTasks returns the IDs of the tasks that are in a closed state of the
+// workflow of their project
+func (s *serviceImpl) closedTasks(ctx context.Context, task *models.Task, descendants []models.Task) (map[uuid.UUID]bool, error) {
+	tasks := make([]*models.Task, 0, len(descendants)+1)
+	tasks = append(tasks, task)
+	for i := range descendants {
+		tasks = append(tasks, &descendants[i])
+	}
+
+	// Subtasks may belong to other projects, so load each workflow once
+	workflows := make(map[uuid.UUID]*models.Workflow)
+	closed := make(map[uuid.UUID]bool)
+	for _, t := range tasks {
+		var projectID uuid.UUID
+		if t.ProjectID != nil {
+			projectID = *t.ProjectID
+		}
+
+		workflow, ok := workflows[projectID]
+		if !ok {
+			var err error
+			workflow, err = s.workflowSvc.ForTask(ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			workflows[projectID] = workflow
+		}
+
+		if workflow.IsClosed(t.Status) {
+			closed[t.ID] = true
+		}
+	}
+
+	return closed, nil
+}
+
+// validateParent checks that parentID can become the parent of taskID
+func (s *serviceImpl) validateParent(ctx context.Context, taskID, parentID uuid.UUID) error {
+	if parentID == taskID {
+		return ErrInvalidParent
+	}
+
+	_, err := s.taskRepo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			return ErrInvalidParent
+		}
+		return err
+	}
+
+	// A task cannot be moved below one of its own subtasks
+	descendants, err := s.taskRepo.GetDescendants(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	for _, descendant := range descendants {
+		if descendant.ID == parentID {
+			return ErrInvalidParent
+		}
+	}
+
+	return nil
+}
+
+// getWritableTask retrieves a task to change. Tasks of archived projects are
+// read-only.
+func (s *serviceImpl) getWritableTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	return task, nil
+}
+
+// checkProjectWritable checks that a project exists and is not archived, so
+// that tasks can be added to it
+func (s *serviceImpl) checkProjectWritable(ctx context.Context, projectID uuid.UUID) error {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
 		return err
 	}
 
-	return s.taskRepo.Delete(ctx, id)
+	if project.IsArchived() {
+		return repository.ErrProjectArchived
+	}
+
+	return nil
 }
 
 // AddTag adds a tag to a task
-func (s *serviceImpl) AddTag(ctx context.Context, taskID uuid.UUID, tag string) error {
+func (s *serviceImpl) AddTag(ctx context.Context, taskID uuid.UUID, tag string, userID uuid.UUID) error {
 	// Check if task exists
-	_, err := s.taskRepo.GetByID(ctx, taskID)
+	task, err := s.getWritableTask(ctx, taskID)
 	if err != nil {
 		return err
 	}
 
-	return s.taskRepo.AddTag(ctx, taskID, tag)
+	return s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.taskRepo.AddTag(ctx, taskID, tag); err != nil {
+			return err
+		}
+		if models.HasTag(task.Tags, tag) {
+			return nil
+		}
+		return s.taskHistoryRepo.RecordChanges(ctx, taskID, userID, []models.TaskFieldChange{
+			{Field: models.TaskFieldTags, NewValue: &tag},
+		})
+	})
 }
 
 // RemoveTag removes a tag from a task
-func (s *serviceImpl) RemoveTag(ctx context.Context, taskID uuid.UUID, tag string) error {
+func (s *serviceImpl) RemoveTag(ctx context.Context, taskID uuid.UUID, tag string, userID uuid.UUID) error {
 	// Check if task exists
-	_, err := s.taskRepo.GetByID(ctx, taskID)
+	task, err := s.getWritableTask(ctx, taskID)
 	if err != nil {
 		return err
 	}
 
-	return s.taskRepo.RemoveTag(ctx, taskID, tag)
+	return s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.taskRepo.RemoveTag(ctx, taskID, tag); err != nil {
+			return err
+		}
+		if !models.HasTag(task.Tags, tag) {
+			return nil
+		}
+		return s.taskHistoryRepo.RecordChanges(ctx, taskID, userID, []models.TaskFieldChange{
+			{Field: models.TaskFieldTags, OldValue: &tag},
+		})
+	})
+}
+
+// updateWithHistory saves a task and records how it differs from before,
+// attributed to userID
+func (s *serviceImpl) updateWithHistory(ctx context.Context, before, task *models.Task, userID uuid.UUID) error {
+	return s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.taskRepo.Update(ctx, task); err != nil {
+			return err
+		}
+		return s.taskHistoryRepo.RecordChanges(ctx, task.ID, userID, models.DiffTasks(before, task))
+	})
 }
 
 // AssignTask assigns a task to a user
 func (s *serviceImpl) AssignTask(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, assignedBy uuid.UUID) (*models.TaskResponse, error) {
 	// Check if task exists
-	task, err := s.taskRepo.GetByID(ctx, taskID)
+	task, err := s.getWritableTask(ctx, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -252,10 +727,11 @@ func (s *serviceImpl) AssignTask(ctx context.Context, taskID uuid.UUID, userID u
 	}
 
 	// Update task's assignee
+	before := *task
 	task.AssignedTo = &userID
 	task.UpdatedAt = time.Now()
 
-	err = s.taskRepo.Update(ctx, task)
+	err = s.updateWithHistory(ctx, &before, task, assignedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -270,8 +746,10 @@ func (s *serviceImpl) AssignTask(ctx context.Context, taskID uuid.UUID, userID u
 	err = s.notificationSvc.NotifyTaskAssigned(ctx, updatedTask)
 	if err != nil {
 		// Log the error but don't fail the operation
-		// TODO: Add proper logging
-		fmt.Println("Failed to send task assignment notification:", err)
+		logger.FromContext(ctx).Error("failed to send task assignment notification",
+			slog.String("task_id", updatedTask.ID.String()),
+			slog.Any("error", err),
+		)
 	}
 
 	response := updatedTask.ToResponse()
@@ -281,7 +759,7 @@ func (s *serviceImpl) AssignTask(ctx context.Context, taskID uuid.UUID, userID u
 // UnassignTask removes the assignment of a task
 func (s *serviceImpl) UnassignTask(ctx context.Context, taskID uuid.UUID, unassignedBy uuid.UUID) (*models.TaskResponse, error) {
 	// Check if task exists
-	task, err := s.taskRepo.GetByID(ctx, taskID)
+	task, err := s.getWritableTask(ctx, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -292,10 +770,11 @@ func (s *serviceImpl) UnassignTask(ctx context.Context, taskID uuid.UUID, unassi
 	}
 
 	// Update task's assignee
+	before := *task
 	task.AssignedTo = nil
 	task.UpdatedAt = time.Now()
 
-	err = s.taskRepo.Update(ctx, task)
+	err = s.updateWithHistory(ctx, &before, task, unassignedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -311,34 +790,44 @@ func (s *serviceImpl) UnassignTask(ctx context.Context, taskID uuid.UUID, unassi
 }
 
 // UpdateTaskStatus updates the status of a task
-func (s *serviceImpl) UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, status models.TaskStatus, updatedBy uuid.UUID) (*models.TaskResponse, error) {
+func (s *serviceImpl) UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, status models.TaskStatus, updatedBy uuid.UUID, force bool) (*models.TaskResponse, error) {
 	// Check if task exists
-	task, err := s.taskRepo.GetByID(ctx, taskID)
+	task, err := s.getWritableTask(ctx, taskID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if status is valid
-	validStatuses := map[models.TaskStatus]bool{
-		models.TaskStatusTodo:       true,
-		models.TaskStatusInProgress: true,
-		models.TaskStatusReview:     true,
-		models.TaskStatusDone:       true,
-		models.TaskStatusCancelled:  true,
-	}
-
-	if !validStatuses[status] {
-		return nil, errors.New("invalid task status")
-	}
-
 	// Save old status for notification
+	before := *task
 	oldStatus := task.Status
 
-	// Update task's status
-	task.Status = status
-	task.UpdatedAt = time.Now()
+	// Check the move against the task's workflow, which stays in place until
+	// the task is saved
+	var closing bool
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		workflow, err := s.workflowSvc.ForTask(ctx, task)
+		if err != nil {
+			return err
+		}
+
+		task.Status = status
+		if err := s.checkTransition(ctx, workflow, task, oldStatus, updatedBy); err != nil {
+			return err
+		}
+
+		// A task cannot be closed while it is blocked, unless forced
+		closing = workflow.IsClosed(status) && !workflow.IsClosed(oldStatus)
+		if closing && !force {
+			if err := s.checkBlockers(ctx, taskID); err != nil {
+				return err
+			}
+		}
+
+		// Update task's status
+		task.UpdatedAt = time.Now()
 
-	err = s.taskRepo.Update(ctx, task)
+		return s.updateWithHistory(ctx, &before, task, updatedBy)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -353,154 +842,366 @@ func (s *serviceImpl) UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, st
 	err = s.notificationSvc.NotifyTaskStatusUpdate(ctx, updatedTask, oldStatus)
 	if err != nil {
 		// Log the error but don't fail the operation
-		// TODO: Add proper logging
-		fmt.Println("Failed to send task status update notification:", err)
+		logger.FromContext(ctx).Error("failed to send task status update notification",
+			slog.String("task_id", updatedTask.ID.String()),
+			slog.Any("error", err),
+		)
+	}
+
+	if closing {
+		s.notifyUnblockedDependents(ctx, updatedTask)
 	}
 
 	response := updatedTask.ToResponse()
 	return &response, nil
 }
 
-// GetTasksByAssignee retrieves tasks assigned to a user
-func (s *serviceImpl) GetTasksByAssignee(ctx context.Context, userID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error) {
-	// Check if user exists
-	_, err := s.userRepo.GetByID(ctx, userID)
+// checkTransition checks that task, whose fields have already been updated,
+// may move from one state of its workflow to its current status
+func (s *serviceImpl) checkTransition(ctx context.Context, workflow *models.Workflow, task *models.Task, from models.TaskStatus, userID uuid.UUID) error {
+	if _, ok := workflow.State(task.Status); !ok {
+		return ErrInvalidTaskStatus
+	}
+
+	// Tasks that come from another workflow may enter any state
+	if _, ok := workflow.State(from); !ok || from == task.Status {
+		return nil
+	}
+
+	transition, ok := workflow.Transition(from, task.Status)
+	if !ok {
+		return ErrTransitionNotAllowed
+	}
+
+	guardErr := &TransitionGuardError{MissingFields: transition.MissingFields(task)}
+	if transition.RequiredRole != nil {
+		hasRole, err := s.hasRole(ctx, task, userID, *transition.RequiredRole)
+		if err != nil {
+			return err
+		}
+		if !hasRole {
+			guardErr.RequiredRole = *transition.RequiredRole
+		}
+	}
+
+	if len(guardErr.MissingFields) > 0 || guardErr.RequiredRole != "" {
+		return guardErr
+	}
+
+	return nil
+}
+
+// hasRole reports whether a user holds a role in the organization of a task's project
+func (s *serviceImpl) hasRole(ctx context.Context, task *models.Task, userID uuid.UUID, roleName string) (bool, error) {
+	if task.ProjectID == nil {
+		return false, nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, *task.ProjectID)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, 0, repository.ErrUserNotFound
+		return false, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID, project.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		if role.Name == roleName {
+			return true, nil
 		}
-		return nil, 0, err
 	}
 
-	// Set assignee in params
-	params.AssignedTo = &userID
+	return false, nil
+}
 
-	// Get tasks
-	tasks, total, err := s.taskRepo.List(ctx, params)
+// isClosed reports whether a task is in a closed state of its workflow
+func (s *serviceImpl) isClosed(ctx context.Context, task *models.Task) (bool, error) {
+	workflow, err := s.workflowSvc.ForTask(ctx, task)
 	if err != nil {
-		return nil, 0, err
+		return false, err
 	}
 
-	responses := make([]models.TaskResponse, 0, len(tasks))
-	for _, task := range tasks {
-		responses = append(responses, task.ToResponse())
+	return workflow.IsClosed(task.Status), nil
+}
+
+// AddLink links a task to another task
+func (s *serviceImpl) AddLink(ctx context.Context, taskID uuid.UUID, req models.TaskLinkRequest, createdBy uuid.UUID) (*models.TaskLinkResponse, error) {
+	if req.TaskID == taskID {
+		return nil, ErrSelfLink
 	}
 
-	return responses, total, nil
+	// Check if the task can be changed and the linked task exists
+	if _, err := s.getWritableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	linkedTask, err := s.taskRepo.GetByID(ctx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create link
+	link := models.NewTaskLink(taskID, req, createdBy)
+
+	err = s.taskLinkRepo.Create(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.SourceTaskID == req.TaskID {
+		link.SourceTask = linkedTask
+	} else {
+		link.TargetTask = linkedTask
+	}
+
+	response := link.ToResponse(taskID)
+	return &response, nil
 }
 
-// GetOverdueTasks retrieves overdue tasks
-func (s *serviceImpl) GetOverdueTasks(ctx context.Context, params models.TaskListParams) ([]models.TaskResponse, int, error) {
-	// Set due date filter to find overdue tasks
-	now := time.Now()
-	params.DueBefore = &now
+// RemoveLink removes a link from a task
+func (s *serviceImpl) RemoveLink(ctx context.Context, taskID uuid.UUID, linkID uuid.UUID) error {
+	// Check if the task can be changed
+	if _, err := s.getWritableTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	// Check if the link belongs to the task
+	link, err := s.taskLinkRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
 
-	// Exclude completed and cancelled tasks
-	todoStatus := models.TaskStatusTodo
-	inProgressStatus := models.TaskStatusInProgress
-	reviewStatus := models.TaskStatusReview
+	if link.SourceTaskID != taskID && link.TargetTaskID != taskID {
+		return repository.ErrTaskLinkNotFound
+	}
 
-	// We need to get tasks with these statuses separately and combine them
-	// since we can only set one status filter at a time
+	return s.taskLinkRepo.Delete(ctx, linkID)
+}
 
-	// Get todo tasks
-	params.Status = &todoStatus
-	todoTasks, _, err := s.taskRepo.List(ctx, params)
+// ListLinks retrieves all links of a task
+func (s *serviceImpl) ListLinks(ctx context.Context, taskID uuid.UUID) ([]models.TaskLinkResponse, error) {
+	// Check if task exists
+	_, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// Get in progress tasks
-	params.Status = &inProgressStatus
-	inProgressTasks, _, err := s.taskRepo.List(ctx, params)
+	links, err := s.taskLinkRepo.ListByTask(ctx, taskID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	responses := make([]models.TaskLinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, link.ToResponse(taskID))
 	}
 
-	// Get review tasks
-	params.Status = &reviewStatus
-	reviewTasks, _, err := s.taskRepo.List(ctx, params)
+	return responses, nil
+}
+
+// ListChecklistItems retrieves the checklist of a task
+func (s *serviceImpl) ListChecklistItems(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItemResponse, error) {
+	// Check if task exists
+	_, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// Combine tasks
-	allTasks := append(todoTasks, inProgressTasks...)
-	allTasks = append(allTasks, reviewTasks...)
+	items, err := s.checklistRepo.ListByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Filter out tasks without due dates
-	overdueTasks := make([]models.Task, 0)
-	for _, task := range allTasks {
-		if task.DueDate != nil {
-			overdueTasks = append(overdueTasks, task)
-		}
+	responses := make([]models.ChecklistItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, item.ToResponse())
 	}
 
-	// Convert to responses
-	responses := make([]models.TaskResponse, 0, len(overdueTasks))
-	for _, task := range overdueTasks {
-		responses = append(responses, task.ToResponse())
+	return responses, nil
+}
+
+// AddChecklistItem adds an item to the checklist of a task
+func (s *serviceImpl) AddChecklistItem(ctx context.Context, taskID uuid.UUID, req models.ChecklistItemRequest) (*models.ChecklistItemResponse, error) {
+	// Check if the task can be changed
+	if _, err := s.getWritableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	item := models.NewChecklistItem(taskID, req)
+
+	err := s.checklistRepo.Create(ctx, item)
+	if err != nil {
+		return nil, err
 	}
 
-	return responses, len(responses), nil
+	response := item.ToResponse()
+	return &response, nil
 }
 
-// GetTasksDueSoon retrieves tasks due within a specified number of days
-func (s *serviceImpl) GetTasksDueSoon(ctx context.Context, days int, params models.TaskListParams) ([]models.TaskResponse, int, error) {
-	// Set due date filter to find tasks due soon
-	now := time.Now()
-	dueAfter := now
-	dueBefore := now.AddDate(0, 0, days)
+// UpdateChecklistItem updates an item of the checklist of a task
+func (s *serviceImpl) UpdateChecklistItem(ctx context.Context, taskID uuid.UUID, itemID uuid.UUID, req models.ChecklistItemRequest) (*models.ChecklistItemResponse, error) {
+	item, err := s.getChecklistItem(ctx, taskID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Title = req.Title
+	item.SetCompleted(req.IsCompleted)
+	if req.Position != nil {
+		item.Position = *req.Position
+	}
+
+	err = s.checklistRepo.Update(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	response := item.ToResponse()
+	return &response, nil
+}
 
-	params.DueAfter = &dueAfter
-	params.DueBefore = &dueBefore
+// DeleteChecklistItem removes an item from the checklist of a task
+func (s *serviceImpl) DeleteChecklistItem(ctx context.Context, taskID uuid.UUID, itemID uuid.UUID) error {
+	if _, err := s.getChecklistItem(ctx, taskID, itemID); err != nil {
+		return err
+	}
 
-	// Exclude completed and cancelled tasks
-	todoStatus := models.TaskStatusTodo
-	inProgressStatus := models.TaskStatusInProgress
-	reviewStatus := models.TaskStatusReview
+	return s.checklistRepo.Delete(ctx, itemID)
+}
 
-	// We need to get tasks with these statuses separately and combine them
-	// since we can only set one status filter at a time
+// getChecklistItem retrieves a checklist item to change, checking that it
+// belongs to the task and that the task can be changed
+func (s *serviceImpl) getChecklistItem(ctx context.Context, taskID uuid.UUID, itemID uuid.UUID) (*models.ChecklistItem, error) {
+	if _, err := s.getWritableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
 
-	// Get todo tasks
-	params.Status = &todoStatus
-	todoTasks, _, err := s.taskRepo.List(ctx, params)
+	item, err := s.checklistRepo.GetByID(ctx, itemID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	if item.TaskID != taskID {
+		return nil, repository.ErrChecklistItemNotFound
 	}
 
-	// Get in progress tasks
-	params.Status = &inProgressStatus
-	inProgressTasks, _, err := s.taskRepo.List(ctx, params)
+	return item, nil
+}
+
+// checkBlockers returns a BlockedError if any task blocking taskID is still open
+func (s *serviceImpl) checkBlockers(ctx context.Context, taskID uuid.UUID) error {
+	blockers, err := s.taskLinkRepo.ListBlockers(ctx, taskID)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+
+	openBlockers := make([]models.Task, 0, len(blockers))
+	for i := range blockers {
+		closed, err := s.isClosed(ctx, &blockers[i])
+		if err != nil {
+			return err
+		}
+		if !closed {
+			openBlockers = append(openBlockers, blockers[i])
+		}
+	}
+
+	if len(openBlockers) > 0 {
+		return &BlockedError{Blockers: openBlockers}
 	}
 
-	// Get review tasks
-	params.Status = &reviewStatus
-	reviewTasks, _, err := s.taskRepo.List(ctx, params)
+	return nil
+}
+
+// notifyUnblockedDependents notifies the open dependents of a closed task
+// that no longer have any open blockers
+func (s *serviceImpl) notifyUnblockedDependents(ctx context.Context, task *models.Task) {
+	log := logger.FromContext(ctx)
+
+	dependents, err := s.taskLinkRepo.ListDependents(ctx, task.ID)
 	if err != nil {
-		return nil, 0, err
+		log.Error("failed to list dependent tasks",
+			slog.String("task_id", task.ID.String()),
+			slog.Any("error", err),
+		)
+		return
 	}
 
-	// Combine tasks
-	allTasks := append(todoTasks, inProgressTasks...)
-	allTasks = append(allTasks, reviewTasks...)
+	for i := range dependents {
+		dependent := &dependents[i]
+		closed, err := s.isClosed(ctx, dependent)
+		if err == nil {
+			if closed {
+				continue
+			}
+			err = s.checkBlockers(ctx, dependent.ID)
+		}
+		if errors.Is(err, ErrTaskBlocked) {
+			continue
+		}
+		if err == nil {
+			err = s.notificationSvc.NotifyTaskUnblocked(ctx, dependent, task)
+		}
+		if err != nil {
+			// Log the error but don't fail the operation
+			log.Error("failed to send task unblocked notification",
+				slog.String("task_id", dependent.ID.String()),
+				slog.String("blocker_id", task.ID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
 
-	// Filter out tasks without due dates
-	dueSoonTasks := make([]models.Task, 0)
-	for _, task := range allTasks {
-		if task.DueDate != nil {
-			dueSoonTasks = append(dueSoonTasks, task)
+// GetTasksByAssignee retrieves tasks assigned to a user
+func (s *serviceImpl) GetTasksByAssignee(ctx context.Context, userID uuid.UUID, params models.TaskListParams) ([]models.TaskResponse, int, error) {
+	// Check if user exists
+	_, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, 0, repository.ErrUserNotFound
 		}
+		return nil, 0, err
+	}
+
+	// Set assignee in params
+	params.AssignedTo = &userID
+
+	// Get tasks
+	tasks, total, err := s.taskRepo.List(ctx, params)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Convert to responses
-	responses := make([]models.TaskResponse, 0, len(dueSoonTasks))
-	for _, task := range dueSoonTasks {
+	responses := make([]models.TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
 		responses = append(responses, task.ToResponse())
 	}
 
-	return responses, len(responses), nil
+	return responses, total, nil
+}
+
+// GetOverdueTasks retrieves overdue tasks
+func (s *serviceImpl) GetOverdueTasks(ctx context.Context, params models.TaskListParams) ([]models.TaskResponse, int, error) {
+	// Overdue tasks are open tasks due before now
+	overdue, err := models.ParseTaskFilter("due < now and is:open")
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Filter = models.AndTaskFilters(params.Filter, overdue)
+
+	return s.List(ctx, params)
+}
+
+// GetTasksDueSoon retrieves tasks due within a specified number of days
+func (s *serviceImpl) GetTasksDueSoon(ctx context.Context, days int, params models.TaskListParams) ([]models.TaskResponse, int, error) {
+	// Tasks due soon are open tasks due between now and the given number of days
+	dueSoon, err := models.ParseTaskFilter(fmt.Sprintf("due >= now and due <= %dd and is:open", days))
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Filter = models.AndTaskFilters(params.Filter, dueSoon)
+
+	return s.List(ctx, params)
 }
@@ -2,7 +2,9 @@ package task_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,12 +14,15 @@ import (
 	"github.com/Jerinji2016/halooid/backend/internal/models"
 	"github.com/Jerinji2016/halooid/backend/internal/notification"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
 	"github.com/Jerinji2016/halooid/backend/internal/taskodex/task"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
 	"github.com/Jerinji2016/halooid/backend/internal/test"
 	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTaskAPI(t *testing.T) {
@@ -32,13 +37,33 @@ func TestTaskAPI(t *testing.T) {
 
 	// Create repositories
 	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	taskHistoryRepo := repository.NewPostgresTaskHistoryRepository(tdb.DB)
+	taskLinkRepo := repository.NewPostgresTaskLinkRepository(tdb.DB)
+	checklistRepo := repository.NewPostgresChecklistItemRepository(tdb.DB)
 	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
 	userRepo := repository.NewPostgresUserRepository(tdb.DB)
 	notificationRepo := repository.NewPostgresNotificationRepository(tdb.DB)
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+	workflowRepo := repository.NewPostgresWorkflowRepository(tdb.DB)
+	customFieldRepo := repository.NewPostgresCustomFieldRepository(tdb.DB)
 
 	// Create services
 	notificationService := notification.NewService(notificationRepo, userRepo)
-	taskService := task.NewService(taskRepo, projectRepo, userRepo, notificationService)
+	workflowService := workflow.NewService(workflowRepo, projectRepo, taskRepo, repository.NewTxManager(tdb.DB))
+	customFieldService := customfield.NewService(customFieldRepo, projectRepo, userRepo, roleRepo)
+	taskService := task.NewService(
+		taskRepo,
+		taskHistoryRepo,
+		taskLinkRepo,
+		checklistRepo,
+		projectRepo,
+		userRepo,
+		roleRepo,
+		notificationService,
+		workflowService,
+		customFieldService,
+		repository.NewTxManager(tdb.DB),
+	)
 	taskHandlers := task.NewHandlers(taskService)
 
 	// Setup Echo
@@ -353,6 +378,632 @@ func TestTaskAPI(t *testing.T) {
 		assert.Nil(t, response.AssignedTo)
 	})
 
+	t.Run("TaskLinks", func(t *testing.T) {
+		blocker := tdb.CreateTestTask(t, prefix, testProject.ID, testUser.ID)
+		dependent := tdb.CreateTestTask(t, prefix, testProject.ID, testUser.ID)
+
+		addLink := func(taskID uuid.UUID, reqBody models.TaskLinkRequest) *httptest.ResponseRecorder {
+			reqJSON, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqJSON))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/links")
+			c.SetParamNames("org_id", "id")
+			c.SetParamValues(testOrg.ID.String(), taskID.String())
+
+			if err := taskHandlers.AddLink(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+			return rec
+		}
+
+		updateStatus := func(taskID uuid.UUID, status models.TaskStatus, force bool) *httptest.ResponseRecorder {
+			reqJSON, _ := json.Marshal(map[string]interface{}{"status": status, "force": force})
+			req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(reqJSON))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/status")
+			c.SetParamNames("org_id", "id")
+			c.SetParamValues(testOrg.ID.String(), taskID.String())
+
+			if err := taskHandlers.UpdateTaskStatus(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+			return rec
+		}
+
+		// Link the dependent as blocked by the blocker
+		rec := addLink(dependent.ID, models.TaskLinkRequest{TaskID: blocker.ID, Type: models.TaskLinkTypeBlockedBy})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var link models.TaskLinkResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &link)
+		assert.NoError(t, err)
+		assert.Equal(t, models.TaskLinkTypeBlockedBy, link.Type)
+		assert.Equal(t, blocker.ID, link.TaskID)
+
+		// The same link from the other side already exists
+		rec = addLink(blocker.ID, models.TaskLinkRequest{TaskID: dependent.ID, Type: models.TaskLinkTypeBlocks})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		// The reverse blocking link would create a cycle
+		rec = addLink(dependent.ID, models.TaskLinkRequest{TaskID: blocker.ID, Type: models.TaskLinkTypeBlocks})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		// The dependent cannot be completed while the blocker is open
+		rec = updateStatus(dependent.ID, models.TaskStatusDone, false)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		// Completing the blocker unblocks the dependent
+		rec = updateStatus(blocker.ID, models.TaskStatusDone, false)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = updateStatus(dependent.ID, models.TaskStatusDone, false)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// List links from the blocker's side
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec = httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/links")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), blocker.ID.String())
+
+		err = taskHandlers.ListLinks(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var listResponse struct {
+			Links []models.TaskLinkResponse `json:"links"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &listResponse)
+		assert.NoError(t, err)
+		if assert.Len(t, listResponse.Links, 1) {
+			assert.Equal(t, models.TaskLinkTypeBlocks, listResponse.Links[0].Type)
+			assert.Equal(t, dependent.ID, listResponse.Links[0].TaskID)
+		}
+
+		// Remove the link
+		req = httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/links/:link_id")
+		c.SetParamNames("org_id", "id", "link_id")
+		c.SetParamValues(testOrg.ID.String(), blocker.ID.String(), link.ID.String())
+
+		err = taskHandlers.RemoveLink(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("Subtasks", func(t *testing.T) {
+		ctx := context.Background()
+		parent := tdb.CreateTestTask(t, prefix, testProject.ID, testUser.ID)
+
+		createSubtask := func(parentID uuid.UUID, status models.TaskStatus) *models.TaskResponse {
+			subtask, err := taskService.Create(ctx, models.TaskRequest{
+				ParentID: &parentID,
+				Title:    prefix + "Subtask",
+				Status:   status,
+				Priority: models.TaskPriorityMedium,
+			}, testUser.ID)
+			require.NoError(t, err)
+			return subtask
+		}
+
+		done := createSubtask(parent.ID, models.TaskStatusDone)
+		open := createSubtask(parent.ID, models.TaskStatusInProgress)
+		assert.Equal(t, &parent.ID, open.ParentID)
+
+		// Half of the open subtask's checklist is completed
+		_, err := taskService.AddChecklistItem(ctx, open.ID, models.ChecklistItemRequest{Title: "First", IsCompleted: true})
+		require.NoError(t, err)
+		_, err = taskService.AddChecklistItem(ctx, open.ID, models.ChecklistItemRequest{Title: "Second"})
+		require.NoError(t, err)
+
+		// A task cannot become a subtask of its own subtask
+		_, err = taskService.Update(ctx, parent.ID, models.TaskRequest{
+			ParentID: &open.ID,
+			Title:    parent.Title,
+			Status:   parent.Status,
+			Priority: parent.Priority,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, task.ErrInvalidParent)
+
+		// Get the tree
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id/tree")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), parent.ID.String())
+
+		err = taskHandlers.GetTree(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var tree models.TaskTreeNode
+		err = json.Unmarshal(rec.Body.Bytes(), &tree)
+		assert.NoError(t, err)
+		assert.Len(t, tree.Children, 2)
+		if assert.NotNil(t, tree.Rollup) {
+			assert.Equal(t, 2, tree.Rollup.SubtaskCount)
+			assert.Equal(t, 1, tree.Rollup.CompletedSubtaskCount)
+			assert.InDelta(t, 75, tree.Rollup.Progress, 0.01)
+		}
+
+		// Deleting the parent moves its subtasks up a level by default
+		req = httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), parent.ID.String())
+		c.Set("user_id", testUser.ID.String())
+
+		err = taskHandlers.Delete(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		reparented, err := taskRepo.GetByID(ctx, done.ID)
+		require.NoError(t, err)
+		assert.Nil(t, reparented.ParentID)
+
+		// Cascading deletes the whole subtree
+		child := createSubtask(open.ID, models.TaskStatusTodo)
+		err = taskService.Delete(ctx, open.ID, task.ChildPolicyCascade, testUser.ID)
+		require.NoError(t, err)
+
+		_, err = taskRepo.GetByID(ctx, child.ID)
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	})
+
+	t.Run("Workflow", func(t *testing.T) {
+		ctx := context.Background()
+		workflowProject := tdb.CreateTestProject(t, prefix+"wf", testOrg.ID, testUser.ID)
+
+		reviewer := "reviewer"
+		_, err := workflowService.Set(ctx, workflowProject.ID, models.WorkflowRequest{
+			Name:         "QA",
+			InitialState: "triage",
+			States: []models.WorkflowState{
+				{Key: "triage", Name: "Triage", Category: models.WorkflowStateCategoryOpen},
+				{Key: "in_test", Name: "In Test", Category: models.WorkflowStateCategoryInProgress},
+				{Key: "verified", Name: "Verified", Category: models.WorkflowStateCategoryClosed},
+			},
+			Transitions: []models.WorkflowTransition{
+				{FromState: "triage", ToState: "in_test", RequiredFields: []string{models.TaskFieldAssignedTo}},
+				{FromState: "in_test", ToState: "verified", RequiredRole: &reviewer},
+			},
+		})
+		require.NoError(t, err)
+
+		// New tasks start in the initial state
+		created, err := taskService.Create(ctx, models.TaskRequest{
+			ProjectID: &workflowProject.ID,
+			Title:     prefix + "QA Task",
+			Priority:  models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatus("triage"), created.Status)
+
+		// States outside the workflow are rejected
+		_, err = taskService.UpdateTaskStatus(ctx, created.ID, models.TaskStatusDone, testUser.ID, false)
+		assert.ErrorIs(t, err, task.ErrInvalidTaskStatus)
+
+		// Moves without a transition are rejected
+		_, err = taskService.UpdateTaskStatus(ctx, created.ID, "verified", testUser.ID, false)
+		assert.ErrorIs(t, err, task.ErrTransitionNotAllowed)
+
+		// Guards must be met
+		_, err = taskService.UpdateTaskStatus(ctx, created.ID, "in_test", testUser.ID, false)
+		var guardErr *task.TransitionGuardError
+		if assert.ErrorAs(t, err, &guardErr) {
+			assert.Equal(t, []string{models.TaskFieldAssignedTo}, guardErr.MissingFields)
+		}
+
+		_, err = taskService.AssignTask(ctx, created.ID, testUser.ID, testUser.ID)
+		require.NoError(t, err)
+
+		moved, err := taskService.UpdateTaskStatus(ctx, created.ID, "in_test", testUser.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatus("in_test"), moved.Status)
+
+		// The test user does not hold the reviewer role
+		_, err = taskService.UpdateTaskStatus(ctx, created.ID, "verified", testUser.ID, false)
+		if assert.ErrorAs(t, err, &guardErr) {
+			assert.Equal(t, reviewer, guardErr.RequiredRole)
+		}
+	})
+
+	t.Run("CustomFields", func(t *testing.T) {
+		ctx := context.Background()
+		fieldProject := tdb.CreateTestProject(t, prefix+"cf", testOrg.ID, testUser.ID)
+
+		_, err := customFieldService.Create(ctx, testOrg.ID, models.CustomFieldDefinitionRequest{
+			ProjectID: &fieldProject.ID,
+			Entity:    models.CustomFieldEntityTask,
+			Key:       "points",
+			Name:      "Story Points",
+			Type:      models.CustomFieldTypeNumber,
+			Required:  true,
+		})
+		require.NoError(t, err)
+		_, err = customFieldService.Create(ctx, testOrg.ID, models.CustomFieldDefinitionRequest{
+			ProjectID: &fieldProject.ID,
+			Entity:    models.CustomFieldEntityTask,
+			Key:       "platforms",
+			Name:      "Platforms",
+			Type:      models.CustomFieldTypeMultiSelect,
+			Options:   []string{"web", "ios", "android"},
+		})
+		require.NoError(t, err)
+
+		createWithFields := func(title string, fields map[string]interface{}) (*models.TaskResponse, error) {
+			return taskService.Create(ctx, models.TaskRequest{
+				ProjectID:    &fieldProject.ID,
+				Title:        prefix + title,
+				Priority:     models.TaskPriorityMedium,
+				CustomFields: fields,
+			}, testUser.ID)
+		}
+
+		// Required fields and field types are enforced
+		_, err = createWithFields("No Points", nil)
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+		_, err = createWithFields("Bad Points", map[string]interface{}{"points": "five"})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+		_, err = createWithFields("Bad Platform", map[string]interface{}{"points": 1.0, "platforms": []interface{}{"desktop"}})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+
+		small, err := createWithFields("Small", map[string]interface{}{"points": 2.0, "platforms": []interface{}{"web"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, small.CustomFields["points"])
+		large, err := createWithFields("Large", map[string]interface{}{"points": 13.0, "platforms": []interface{}{"web", "ios"}})
+		require.NoError(t, err)
+
+		// Filter by range and by multi-select option
+		tasks, total, err := taskService.List(ctx, models.TaskListParams{
+			ProjectID:          &fieldProject.ID,
+			CustomFieldFilters: []models.CustomFieldFilter{{Key: "points", Operator: models.CustomFieldOperatorGte, Value: "5"}},
+		})
+		require.NoError(t, err)
+		if assert.Equal(t, 1, total) {
+			assert.Equal(t, large.ID, tasks[0].ID)
+		}
+
+		tasks, total, err = taskService.List(ctx, models.TaskListParams{
+			ProjectID:          &fieldProject.ID,
+			CustomFieldFilters: []models.CustomFieldFilter{{Key: "platforms", Operator: models.CustomFieldOperatorEq, Value: "web"}},
+			CustomFieldSort:    &models.CustomFieldSort{Key: "points"},
+			SortOrder:          "asc",
+		})
+		require.NoError(t, err)
+		if assert.Equal(t, 2, total) {
+			assert.Equal(t, small.ID, tasks[0].ID)
+			assert.Equal(t, large.ID, tasks[1].ID)
+		}
+
+		// Unknown fields cannot be filtered
+		_, _, err = taskService.List(ctx, models.TaskListParams{
+			ProjectID:          &fieldProject.ID,
+			CustomFieldFilters: []models.CustomFieldFilter{{Key: "unknown", Operator: models.CustomFieldOperatorEq, Value: "x"}},
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidCustomField)
+
+		// Updates merge values; null clears an optional field
+		updated, err := taskService.Update(ctx, small.ID, models.TaskRequest{
+			ProjectID:    &fieldProject.ID,
+			Title:        small.Title,
+			Priority:     small.Priority,
+			CustomFields: map[string]interface{}{"platforms": nil},
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, updated.CustomFields["points"])
+		assert.NotContains(t, updated.CustomFields, "platforms")
+	})
+
+	t.Run("FilterExpression", func(t *testing.T) {
+		ctx := context.Background()
+		filterProject := tdb.CreateTestProject(t, prefix+"fx", testOrg.ID, testUser.ID)
+
+		now := time.Now()
+		newTask := func(title string, status models.TaskStatus, priority models.TaskPriority, dueInDays int, tags ...string) *models.Task {
+			dueDate := now.AddDate(0, 0, dueInDays)
+			task := models.NewTask(models.TaskRequest{
+				ProjectID: &filterProject.ID,
+				Title:     prefix + title,
+				Status:    status,
+				Priority:  priority,
+				DueDate:   &dueDate,
+				Tags:      tags,
+			}, testUser.ID)
+			require.NoError(t, taskRepo.Create(ctx, task))
+			return task
+		}
+
+		soon := newTask("Soon", models.TaskStatusTodo, models.TaskPriorityHigh, 2, "backend")
+		review := newTask("Review", models.TaskStatusReview, models.TaskPriorityCritical, 3, "backend")
+		newTask("Done", models.TaskStatusDone, models.TaskPriorityHigh, -1, "backend")
+		late := newTask("Late", models.TaskStatusInProgress, models.TaskPriorityLow, -2, "frontend")
+		later := newTask("Later", models.TaskStatusTodo, models.TaskPriorityMedium, -5)
+
+		_, err := taskService.AssignTask(ctx, review.ID, testUser.ID, testUser.ID)
+		require.NoError(t, err)
+
+		list := func(expr string, pageSize int) ([]models.TaskResponse, int) {
+			filter, err := models.ParseTaskFilter(expr)
+			require.NoError(t, err)
+
+			tasks, total, err := taskService.List(ctx, models.TaskListParams{
+				ProjectID:     &filterProject.ID,
+				Filter:        filter,
+				CurrentUserID: &testUser.ID,
+				SortBy:        "due_date",
+				SortOrder:     "asc",
+				Page:          1,
+				PageSize:      pageSize,
+			})
+			require.NoError(t, err)
+			return tasks, total
+		}
+
+		tasks, total := list("status in (todo,review) and priority>=high and tag:backend and due<7d", 20)
+		if assert.Equal(t, 2, total) {
+			assert.Equal(t, soon.ID, tasks[0].ID)
+			assert.Equal(t, review.ID, tasks[1].ID)
+		}
+
+		// Totals count every match, not only the page
+		tasks, total = list("status in (todo,review) and priority>=high and tag:backend and due<7d", 1)
+		assert.Equal(t, 2, total)
+		assert.Len(t, tasks, 1)
+
+		tasks, total = list("assignee = me or (not tag:backend and priority < medium)", 20)
+		if assert.Equal(t, 2, total) {
+			assert.Equal(t, late.ID, tasks[0].ID)
+			assert.Equal(t, review.ID, tasks[1].ID)
+		}
+
+		// Overdue and due soon tasks exclude closed tasks and paginate in the database
+		tasks, total, err = taskService.GetOverdueTasks(ctx, models.TaskListParams{
+			ProjectID: &filterProject.ID,
+			SortBy:    "due_date",
+			SortOrder: "asc",
+			Page:      1,
+			PageSize:  1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, later.ID, tasks[0].ID)
+		}
+
+		_, total, err = taskService.GetTasksDueSoon(ctx, 7, models.TaskListParams{ProjectID: &filterProject.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+
+		// Invalid expressions are rejected with their position
+		_, err = models.ParseTaskFilter("priority >= urgent")
+		assert.ErrorIs(t, err, models.ErrInvalidTaskFilter)
+		_, err = models.ParseTaskFilter("status in (todo")
+		var filterErr *models.TaskFilterError
+		if assert.ErrorAs(t, err, &filterErr) {
+			assert.Equal(t, 15, filterErr.Pos)
+		}
+	})
+
+	t.Run("BulkOperations", func(t *testing.T) {
+		ctx := context.Background()
+		bulkProject := tdb.CreateTestProject(t, prefix+"bulk", testOrg.ID, testUser.ID)
+		assignee := tdb.CreateTestUser(t, prefix+"bulk")
+
+		// The user may change tasks in the test organization only
+		otherOrg := tdb.CreateTestOrganization(t, prefix+"other", testUser.ID)
+		otherProject := tdb.CreateTestProject(t, prefix+"other", otherOrg.ID, testUser.ID)
+
+		role := &models.Role{
+			ID:        uuid.New(),
+			Name:      prefix + " bulk editor",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(t, roleRepo.CreateRole(ctx, role))
+		for _, name := range []string{middleware.PermissionTaskWrite, middleware.PermissionTaskDelete} {
+			permission, err := roleRepo.GetPermissionByName(ctx, name)
+			if errors.Is(err, repository.ErrPermissionNotFound) {
+				permission = &models.Permission{ID: uuid.New(), Name: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+				err = roleRepo.CreatePermission(ctx, permission)
+			}
+			require.NoError(t, err)
+			require.NoError(t, roleRepo.AssignPermissionToRole(ctx, role.ID, permission.ID))
+		}
+		require.NoError(t, roleRepo.AssignRoleToUser(ctx, &models.UserRole{
+			UserID:         testUser.ID,
+			RoleID:         role.ID,
+			OrganizationID: testOrg.ID,
+		}))
+
+		newTask := func(title string, projectID uuid.UUID) *models.Task {
+			task := models.NewTask(models.TaskRequest{
+				ProjectID: &projectID,
+				Title:     prefix + title,
+				Status:    models.TaskStatusTodo,
+				Priority:  models.TaskPriorityLow,
+			}, testUser.ID)
+			require.NoError(t, taskRepo.Create(ctx, task))
+			return task
+		}
+		first := newTask("Bulk first", bulkProject.ID)
+		second := newTask("Bulk second", bulkProject.ID)
+		foreign := newTask("Bulk foreign", otherProject.ID)
+
+		status := models.TaskStatusInProgress
+		changes := &models.BulkTaskChanges{
+			Status:     &status,
+			AssignedTo: &assignee.ID,
+			AddTags:    []string{"bulk"},
+		}
+
+		// All or nothing: the task in the other organization undoes the others
+		response, err := taskService.Bulk(ctx, models.BulkTaskRequest{
+			TaskIDs: []uuid.UUID{first.ID, foreign.ID, second.ID},
+			Action:  models.BulkTaskActionUpdate,
+			Changes: changes,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.False(t, response.Committed)
+		if assert.Len(t, response.Results, 3) {
+			assert.Equal(t, models.BulkTaskItemStatusRolledBack, response.Results[0].Status)
+			assert.Equal(t, models.BulkTaskItemStatusFailed, response.Results[1].Status)
+			assert.Equal(t, models.BulkTaskItemStatusSkipped, response.Results[2].Status)
+		}
+		unchanged, err := taskRepo.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, unchanged.Status)
+		assert.Nil(t, unchanged.AssignedTo)
+
+		// Best effort: every task the user may change is updated
+		reqJSON, _ := json.Marshal(models.BulkTaskRequest{
+			TaskIDs: []uuid.UUID{first.ID, foreign.ID, second.ID},
+			Action:  models.BulkTaskActionUpdate,
+			Mode:    models.BulkTaskModeBestEffort,
+			Changes: changes,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqJSON))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/bulk")
+		c.SetParamNames("org_id")
+		c.SetParamValues(testOrg.ID.String())
+		c.Set("user_id", testUser.ID.String())
+
+		require.NoError(t, taskHandlers.Bulk(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		response = &models.BulkTaskResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+		assert.True(t, response.Committed)
+		assert.Equal(t, 2, response.Succeeded)
+		assert.Equal(t, 1, response.Failed)
+		if assert.Len(t, response.Results, 3) {
+			assert.Equal(t, models.BulkTaskItemStatusUpdated, response.Results[0].Status)
+			assert.Equal(t, models.BulkTaskItemStatusFailed, response.Results[1].Status)
+			assert.Equal(t, "permission denied", response.Results[1].Error)
+			assert.Equal(t, models.BulkTaskItemStatusUpdated, response.Results[2].Status)
+		}
+		updated, err := taskRepo.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusInProgress, updated.Status)
+		assert.Equal(t, &assignee.ID, updated.AssignedTo)
+		assert.Contains(t, updated.Tags, "bulk")
+
+		// The assignee and the creator get one notification each for the operation
+		notificationType := models.NotificationTypeTaskBulkUpdate
+		for _, userID := range []uuid.UUID{assignee.ID, testUser.ID} {
+			notifications, _, err := notificationRepo.List(ctx, models.NotificationListParams{
+				UserID:   userID,
+				Type:     &notificationType,
+				Page:     1,
+				PageSize: 100,
+			})
+			require.NoError(t, err)
+			sent := 0
+			for _, n := range notifications {
+				if n.ResourceID == response.ID {
+					sent++
+				}
+			}
+			assert.Equal(t, 1, sent)
+		}
+
+		// Applying the same changes again leaves the tasks as they are
+		response, err = taskService.Bulk(ctx, models.BulkTaskRequest{
+			TaskIDs: []uuid.UUID{first.ID, second.ID},
+			Action:  models.BulkTaskActionUpdate,
+			Changes: changes,
+		}, testUser.ID)
+		require.NoError(t, err)
+		for _, result := range response.Results {
+			assert.Equal(t, models.BulkTaskItemStatusUnchanged, result.Status)
+		}
+
+		// Tasks can be selected with a filter expression
+		response, err = taskService.Bulk(ctx, models.BulkTaskRequest{
+			Filter:    "tag:bulk",
+			ProjectID: &bulkProject.ID,
+			Action:    models.BulkTaskActionDelete,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.True(t, response.Committed)
+		assert.Equal(t, 2, response.Succeeded)
+		_, err = taskRepo.GetByID(ctx, first.ID)
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+		// Without a project, a filter only matches the tasks of the organization
+		scopedAssignee := tdb.CreateTestUser(t, prefix+"scoped")
+		newAssignedTask := func(title string, projectID uuid.UUID) *models.Task {
+			task := newTask(title, projectID)
+			task.AssignedTo = &scopedAssignee.ID
+			require.NoError(t, taskRepo.Update(ctx, task))
+			return task
+		}
+		mine := newAssignedTask("Bulk scoped", bulkProject.ID)
+		theirs := newAssignedTask("Bulk scoped foreign", otherProject.ID)
+
+		response, err = taskService.Bulk(ctx, models.BulkTaskRequest{
+			Filter:         "assignee:" + scopedAssignee.ID.String(),
+			Action:         models.BulkTaskActionUpdate,
+			Changes:        &models.BulkTaskChanges{Status: &status},
+			OrganizationID: &testOrg.ID,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.True(t, response.Committed)
+		if assert.Len(t, response.Results, 1) {
+			assert.Equal(t, mine.ID, response.Results[0].TaskID)
+			assert.Equal(t, models.BulkTaskItemStatusUpdated, response.Results[0].Status)
+		}
+		untouched, err := taskRepo.GetByID(ctx, theirs.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, untouched.Status)
+
+		// Another user's task without a project cannot be changed by ID
+		private := models.NewTask(models.TaskRequest{
+			Title:    prefix + "Bulk private",
+			Status:   models.TaskStatusTodo,
+			Priority: models.TaskPriorityLow,
+		}, assignee.ID)
+		require.NoError(t, taskRepo.Create(ctx, private))
+
+		response, err = taskService.Bulk(ctx, models.BulkTaskRequest{
+			TaskIDs:        []uuid.UUID{private.ID},
+			Action:         models.BulkTaskActionUpdate,
+			Changes:        &models.BulkTaskChanges{Status: &status},
+			OrganizationID: &testOrg.ID,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.False(t, response.Committed)
+		if assert.Len(t, response.Results, 1) {
+			assert.Equal(t, models.BulkTaskItemStatusFailed, response.Results[0].Status)
+			assert.Equal(t, task.ErrPermissionDenied.Error(), response.Results[0].Error)
+		}
+		untouched, err = taskRepo.GetByID(ctx, private.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, untouched.Status)
+
+		// Task IDs and a filter cannot be combined
+		_, err = taskService.Bulk(ctx, models.BulkTaskRequest{
+			TaskIDs: []uuid.UUID{foreign.ID},
+			Filter:  "tag:bulk",
+			Action:  models.BulkTaskActionDelete,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, task.ErrInvalidBulkOperation)
+	})
+
 	t.Run("DeleteTask", func(t *testing.T) {
 		// Create request
 		req := httptest.NewRequest(http.MethodDelete, "/", nil)
@@ -363,6 +1014,7 @@ func TestTaskAPI(t *testing.T) {
 		c.SetPath("/api/v1/organizations/:org_id/taskodex/tasks/:id")
 		c.SetParamNames("org_id", "id")
 		c.SetParamValues(testOrg.ID.String(), testTask.ID.String())
+		c.Set("user_id", testUser.ID.String())
 
 		// Handle request
 		err := taskHandlers.Delete(c)
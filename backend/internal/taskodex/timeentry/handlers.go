@@ -62,6 +62,18 @@ func (h *Handlers) Create(c echo.Context) error {
 		if errors.Is(err, errors.New("end time cannot be before start time")) {
 			return echo.NewHTTPError(http.StatusBadRequest, "End time cannot be before start time")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		if errors.Is(err, repository.ErrTimesheetLocked) {
+			return echo.NewHTTPError(http.StatusConflict, "Time entry is in a submitted or approved timesheet")
+		}
+		if errors.Is(err, ErrTimeEntryOverlap) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, ErrTimeEntryTooLong) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create time entry")
 	}
 
@@ -144,6 +156,16 @@ func (h *Handlers) List(c echo.Context) error {
 		params.IsRunning = &isRunning
 	}
 	
+	// Parse billable parameter
+	billableParam := c.QueryParam("billable")
+	if billableParam != "" {
+		billable, err := strconv.ParseBool(billableParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid billable parameter")
+		}
+		params.Billable = &billable
+	}
+	
 	// Parse sort_by parameter
 	sortByParam := c.QueryParam("sort_by")
 	if sortByParam != "" {
@@ -232,6 +254,18 @@ func (h *Handlers) Update(c echo.Context) error {
 		if errors.Is(err, errors.New("end time cannot be before start time")) {
 			return echo.NewHTTPError(http.StatusBadRequest, "End time cannot be before start time")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		if errors.Is(err, repository.ErrTimesheetLocked) {
+			return echo.NewHTTPError(http.StatusConflict, "Time entry is in a submitted or approved timesheet")
+		}
+		if errors.Is(err, ErrTimeEntryOverlap) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, ErrTimeEntryTooLong) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update time entry")
 	}
 	
@@ -253,6 +287,12 @@ func (h *Handlers) Delete(c echo.Context) error {
 		if errors.Is(err, repository.ErrTimeEntryNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Time entry not found")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		if errors.Is(err, repository.ErrTimesheetLocked) {
+			return echo.NewHTTPError(http.StatusConflict, "Time entry is in a submitted or approved timesheet")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete time entry")
 	}
 	
@@ -293,6 +333,12 @@ func (h *Handlers) StartTimer(c echo.Context) error {
 		if errors.Is(err, repository.ErrRunningTimeEntry) {
 			return echo.NewHTTPError(http.StatusConflict, "You already have a running timer for this task")
 		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		if errors.Is(err, repository.ErrTimesheetLocked) {
+			return echo.NewHTTPError(http.StatusConflict, "Time entry is in a submitted or approved timesheet")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start timer")
 	}
 	
@@ -323,7 +369,7 @@ func (h *Handlers) StopTimer(c echo.Context) error {
 	// Stop timer
 	response, err := h.service.StopTimer(c.Request().Context(), req.TaskID, userID)
 	if err != nil {
-		if errors.Is(err, errors.New("no running timer found for this task")) {
+		if errors.Is(err, errors.New("no running timer found for this task")) || errors.Is(err, repository.ErrTimerNotRunning) {
 			return echo.NewHTTPError(http.StatusNotFound, "No running timer found for this task")
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to stop timer")
@@ -437,6 +483,57 @@ func (h *Handlers) Aggregate(c echo.Context) error {
 	return c.JSON(http.StatusOK, aggregations)
 }
 
+// GetPolicy handles retrieving the time entry policy of an organization
+func (h *Handlers) GetPolicy(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+	
+	// Get policy
+	policy, err := h.service.GetPolicy(c.Request().Context(), orgID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve time entry policy")
+	}
+	
+	return c.JSON(http.StatusOK, policy)
+}
+
+// UpdatePolicy handles updating the time entry policy of an organization
+func (h *Handlers) UpdatePolicy(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+	
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+	
+	// Parse request body
+	var req models.TimeEntryPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	
+	// Update policy
+	policy, err := h.service.UpdatePolicy(c.Request().Context(), orgID, req, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update time entry policy")
+	}
+	
+	return c.JSON(http.StatusOK, policy)
+}
+
 // RegisterRoutes registers the time entry routes
 func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
 	timeEntryGroup := g.Group("/time-entries")
@@ -446,6 +543,7 @@ func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBAC
 	timeEntryGroup.GET("/:id", h.GetByID, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
 	timeEntryGroup.GET("/running", h.GetRunningTimers, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
 	timeEntryGroup.GET("/aggregate", h.Aggregate, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
+	timeEntryGroup.GET("/policy", h.GetPolicy, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
 	
 	// Routes that require time_entry:write permission
 	timeEntryGroup.POST("", h.Create, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryWrite))
@@ -455,4 +553,7 @@ func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBAC
 	
 	// Routes that require time_entry:delete permission
 	timeEntryGroup.DELETE("/:id", h.Delete, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryDelete))
+	
+	// Routes that require write:organizations permission
+	timeEntryGroup.PUT("/policy", h.UpdatePolicy, rbacMiddleware.RequirePermission(middleware.PermissionWriteOrganizations))
 }
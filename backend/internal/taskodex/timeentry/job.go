@@ -0,0 +1,35 @@
+package timeentry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+)
+
+// AutoStopJob stops the timers left running for longer than the time entry
+// policy of their organization allows when run by a jobs.Scheduler, and
+// notifies their users
+type AutoStopJob struct {
+	service Service
+}
+
+// NewAutoStopJob creates a new AutoStopJob
+func NewAutoStopJob(service Service) *AutoStopJob {
+	return &AutoStopJob{service: service}
+}
+
+// Name returns the name of the job
+func (j *AutoStopJob) Name() string {
+	return "time_entry_auto_stop"
+}
+
+// Run stops the timers that have run for too long at now
+func (j *AutoStopJob) Run(ctx context.Context, now time.Time) error {
+	stopped, err := j.service.AutoStopTimers(ctx, now)
+	if stopped > 0 {
+		logger.FromContext(ctx).Info("stopped idle timers", slog.Int("count", stopped))
+	}
+	return err
+}
@@ -0,0 +1,231 @@
+package timeentry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeEntryPolicy(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization, project and tasks
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+	testTask := tdb.CreateTestTask(t, prefix, testProject.ID, testUser.ID)
+	otherTask := tdb.CreateTestTask(t, prefix+"_other", testProject.ID, testUser.ID)
+
+	// Create service and handlers
+	timeEntryRepo := repository.NewPostgresTimeEntryRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	notificationRepo := repository.NewPostgresNotificationRepository(tdb.DB)
+	timeEntryService := timeentry.NewService(
+		timeEntryRepo,
+		repository.NewPostgresTaskRepository(tdb.DB),
+		userRepo,
+		repository.NewPostgresTimesheetRepository(tdb.DB),
+		repository.NewPostgresTimeEntryPolicyRepository(tdb.DB),
+		notification.NewService(notificationRepo, userRepo),
+		repository.NewTxManager(tdb.DB),
+	)
+	timeEntryHandlers := timeentry.NewHandlers(timeEntryService)
+	e := echo.New()
+
+	day := time.Date(2024, time.May, 6, 0, 0, 0, 0, time.UTC)
+	logTime := func(taskID uuid.UUID, from, to time.Duration) (*models.TimeEntryResponse, error) {
+		end := day.Add(to)
+		return timeEntryService.Create(ctx, models.TimeEntryRequest{
+			TaskID:    taskID,
+			StartTime: day.Add(from),
+			EndTime:   &end,
+		}, testUser.ID)
+	}
+	updatePolicy := func(req models.TimeEntryPolicyRequest) {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(body))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httpReq, rec)
+		c.SetParamNames("org_id")
+		c.SetParamValues(testOrg.ID.String())
+		c.Set("user_id", testUser.ID.String())
+
+		require.NoError(t, timeEntryHandlers.UpdatePolicy(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	t.Run("DefaultPolicy", func(t *testing.T) {
+		policy, err := timeEntryService.GetPolicy(ctx, testOrg.ID)
+		require.NoError(t, err)
+		assert.True(t, policy.SingleRunningTimer)
+		assert.Equal(t, models.TimeEntryOverlapReject, policy.OverlapMode)
+		assert.Equal(t, 0, policy.MaxEntryMinutes)
+		assert.Equal(t, 0, policy.AutoStopMinutes)
+	})
+
+	var second *models.TimeEntryResponse
+
+	t.Run("RejectOverlap", func(t *testing.T) {
+		_, err := logTime(testTask.ID, 9*time.Hour, 10*time.Hour)
+		require.NoError(t, err)
+
+		// Entries can follow each other, but not overlap
+		second, err = logTime(testTask.ID, 10*time.Hour, 11*time.Hour)
+		require.NoError(t, err)
+
+		_, err = logTime(otherTask.ID, 9*time.Hour+30*time.Minute, 10*time.Hour+30*time.Minute)
+		assert.ErrorIs(t, err, timeentry.ErrTimeEntryOverlap)
+
+		// An entry does not overlap itself when it is updated
+		end := day.Add(11*time.Hour + 30*time.Minute)
+		_, err = timeEntryService.Update(ctx, second.ID, models.TimeEntryRequest{
+			TaskID:    testTask.ID,
+			StartTime: second.StartTime,
+			EndTime:   &end,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("MergeOverlap", func(t *testing.T) {
+		updatePolicy(models.TimeEntryPolicyRequest{
+			SingleRunningTimer: true,
+			OverlapMode:        models.TimeEntryOverlapMerge,
+		})
+
+		// Entries of other tasks are still rejected
+		_, err := logTime(otherTask.ID, 11*time.Hour, 12*time.Hour)
+		assert.ErrorIs(t, err, timeentry.ErrTimeEntryOverlap)
+
+		merged, err := logTime(testTask.ID, 11*time.Hour, 12*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, day.Add(10*time.Hour), merged.StartTime.UTC())
+		require.NotNil(t, merged.DurationMinutes)
+		assert.Equal(t, 120, *merged.DurationMinutes)
+
+		_, err = timeEntryRepo.GetByID(ctx, second.ID)
+		assert.ErrorIs(t, err, repository.ErrTimeEntryNotFound)
+	})
+
+	t.Run("MaxEntryLength", func(t *testing.T) {
+		updatePolicy(models.TimeEntryPolicyRequest{
+			SingleRunningTimer: true,
+			OverlapMode:        models.TimeEntryOverlapReject,
+			MaxEntryMinutes:    240,
+			AutoStopMinutes:    60,
+		})
+
+		_, err := logTime(testTask.ID, 13*time.Hour, 18*time.Hour)
+		assert.ErrorIs(t, err, timeentry.ErrTimeEntryTooLong)
+
+		_, err = logTime(testTask.ID, 13*time.Hour, 17*time.Hour)
+		require.NoError(t, err)
+	})
+
+	t.Run("SingleRunningTimer", func(t *testing.T) {
+		first, err := timeEntryService.StartTimer(ctx, testTask.ID, "", testUser.ID)
+		require.NoError(t, err)
+		_, err = timeEntryService.StartTimer(ctx, otherTask.ID, "", testUser.ID)
+		require.NoError(t, err)
+
+		running, err := timeEntryService.GetRunningTimers(ctx, testUser.ID)
+		require.NoError(t, err)
+		require.Len(t, running, 1)
+		assert.Equal(t, otherTask.ID, running[0].TaskID)
+
+		stopped, err := timeEntryService.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stopped.EndTime)
+
+		// A running entry created directly stops the other timer too
+		created, err := timeEntryService.Create(ctx, models.TimeEntryRequest{
+			TaskID:    testTask.ID,
+			StartTime: time.Now(),
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		running, err = timeEntryService.GetRunningTimers(ctx, testUser.ID)
+		require.NoError(t, err)
+		require.Len(t, running, 1)
+		assert.Equal(t, created.ID, running[0].ID)
+
+		_, err = timeEntryService.StopTimer(ctx, testTask.ID, testUser.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("ConcurrentOverlaps", func(t *testing.T) {
+		// Of two overlapping entries saved at once, only one is accepted
+		errs := make(chan error, 2)
+		for _, taskID := range []uuid.UUID{testTask.ID, otherTask.ID} {
+			go func(taskID uuid.UUID) {
+				_, err := logTime(taskID, 20*time.Hour, 21*time.Hour)
+				errs <- err
+			}(taskID)
+		}
+
+		rejected := 0
+		for i := 0; i < 2; i++ {
+			if err := <-errs; err != nil {
+				assert.ErrorIs(t, err, timeentry.ErrTimeEntryOverlap)
+				rejected++
+			}
+		}
+		assert.Equal(t, 1, rejected)
+	})
+
+	t.Run("AutoStop", func(t *testing.T) {
+		// A timer was left running for three hours
+		now := time.Now()
+		timer := &models.TimeEntry{
+			ID:        uuid.New(),
+			TaskID:    testTask.ID,
+			UserID:    testUser.ID,
+			StartTime: now.Add(-3 * time.Hour),
+			Billable:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, timeEntryRepo.Create(ctx, timer))
+
+		job := timeentry.NewAutoStopJob(timeEntryService)
+		require.NoError(t, job.Run(ctx, now))
+
+		// It is stopped after the hour the policy allows
+		stopped, err := timeEntryService.GetByID(ctx, timer.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stopped.EndTime)
+		assert.WithinDuration(t, timer.StartTime.Add(time.Hour), *stopped.EndTime, time.Second)
+		require.NotNil(t, stopped.DurationMinutes)
+		assert.Equal(t, 60, *stopped.DurationMinutes)
+
+		// Its user is notified, once
+		require.NoError(t, job.Run(ctx, now.Add(5*time.Minute)))
+
+		notificationType := models.NotificationTypeTimerStopped
+		notifications, _, err := notificationRepo.List(ctx, models.NotificationListParams{
+			UserID: testUser.ID,
+			Type:   &notificationType,
+		})
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, timer.ID, notifications[0].ResourceID)
+	})
+}
@@ -3,13 +3,22 @@ package timeentry
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
 	"github.com/google/uuid"
 )
 
+// Common errors
+var (
+	ErrTimeEntryOverlap = errors.New("time entry overlaps another time entry")
+	ErrTimeEntryTooLong = errors.New("time entry is longer than the organization allows")
+)
+
 // Service provides time entry management functionality
 type Service interface {
 	// Create creates a new time entry
@@ -38,34 +47,64 @@ type Service interface {
 	
 	// Aggregate aggregates time entries based on parameters
 	Aggregate(ctx context.Context, params models.TimeEntryAggregationParams) ([]models.TimeEntryAggregation, error)
+	
+	// GetPolicy retrieves the time entry policy of an organization
+	GetPolicy(ctx context.Context, organizationID uuid.UUID) (*models.TimeEntryPolicy, error)
+	
+	// UpdatePolicy updates the time entry policy of an organization
+	UpdatePolicy(ctx context.Context, organizationID uuid.UUID, req models.TimeEntryPolicyRequest, userID uuid.UUID) (*models.TimeEntryPolicy, error)
+	
+	// AutoStopTimers stops the timers that, at now, have run for longer than
+	// the policy of their organization allows, and notifies their users. It
+	// returns the number of timers stopped.
+	AutoStopTimers(ctx context.Context, now time.Time) (int, error)
 }
 
 // serviceImpl implements the Service interface
 type serviceImpl struct {
-	timeEntryRepo repository.TimeEntryRepository
-	taskRepo      repository.TaskRepository
-	userRepo      repository.UserRepository
+	timeEntryRepo   repository.TimeEntryRepository
+	taskRepo        repository.TaskRepository
+	userRepo        repository.UserRepository
+	timesheetRepo   repository.TimesheetRepository
+	policyRepo      repository.TimeEntryPolicyRepository
+	notificationSvc notification.Service
+	txManager       repository.TxManager
 }
 
 // NewService creates a new time entry service
-func NewService(timeEntryRepo repository.TimeEntryRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository) Service {
+func NewService(
+	timeEntryRepo repository.TimeEntryRepository,
+	taskRepo repository.TaskRepository,
+	userRepo repository.UserRepository,
+	timesheetRepo repository.TimesheetRepository,
+	policyRepo repository.TimeEntryPolicyRepository,
+	notificationSvc notification.Service,
+	txManager repository.TxManager,
+) Service {
 	return &serviceImpl{
-		timeEntryRepo: timeEntryRepo,
-		taskRepo:      taskRepo,
-		userRepo:      userRepo,
+		timeEntryRepo:   timeEntryRepo,
+		taskRepo:        taskRepo,
+		userRepo:        userRepo,
+		timesheetRepo:   timesheetRepo,
+		policyRepo:      policyRepo,
+		notificationSvc: notificationSvc,
+		txManager:       txManager,
 	}
 }
 
 // Create creates a new time entry
 func (s *serviceImpl) Create(ctx context.Context, req models.TimeEntryRequest, userID uuid.UUID) (*models.TimeEntryResponse, error) {
-	// Validate task
-	_, err := s.taskRepo.GetByID(ctx, req.TaskID)
+	// Validate task. Tasks of archived projects are read-only.
+	task, err := s.taskRepo.GetByID(ctx, req.TaskID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return nil, repository.ErrTaskNotFound
 		}
 		return nil, err
 	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
 	
 	// Validate user
 	_, err = s.userRepo.GetByID(ctx, userID)
@@ -81,10 +120,10 @@ func (s *serviceImpl) Create(ctx context.Context, req models.TimeEntryRequest, u
 		return nil, errors.New("end time cannot be before start time")
 	}
 	
-	// Create time entry
+	// Create time entry, following the policy of the task's organization
 	timeEntry := models.NewTimeEntry(req, userID)
 	
-	err = s.timeEntryRepo.Create(ctx, timeEntry)
+	err = s.save(ctx, task, timeEntry, s.timeEntryRepo.Create)
 	if err != nil {
 		if errors.Is(err, repository.ErrRunningTimeEntry) {
 			return nil, repository.ErrRunningTimeEntry
@@ -136,14 +175,24 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TimeE
 		return nil, err
 	}
 	
-	// Validate task
-	_, err = s.taskRepo.GetByID(ctx, req.TaskID)
-	if err != nil {
-		if errors.Is(err, repository.ErrTaskNotFound) {
-			return nil, repository.ErrTaskNotFound
+	// Validate the entry's task, and the task it moves to. Tasks of archived
+	// projects are read-only.
+	tasks := make([]*models.Task, 0, 2)
+	for _, taskID := range []uuid.UUID{timeEntry.TaskID, req.TaskID} {
+		task, err := s.taskRepo.GetByID(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				return nil, repository.ErrTaskNotFound
+			}
+			return nil, err
 		}
-		return nil, err
+		if task.Project.IsArchived() {
+			return nil, repository.ErrProjectArchived
+		}
+		tasks = append(tasks, task)
 	}
+	oldTask, task := tasks[0], tasks[1]
+	oldStart := timeEntry.StartTime
 	
 	// Validate time entry
 	if req.EndTime != nil && req.EndTime.Before(req.StartTime) {
@@ -155,6 +204,9 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TimeE
 	timeEntry.StartTime = req.StartTime
 	timeEntry.EndTime = req.EndTime
 	timeEntry.Description = req.Description
+	if req.Billable != nil {
+		timeEntry.Billable = *req.Billable
+	}
 	
 	// Calculate duration if end time is provided
 	if req.EndTime != nil && req.DurationMinutes == nil {
@@ -164,7 +216,14 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TimeE
 		timeEntry.DurationMinutes = req.DurationMinutes
 	}
 	
-	err = s.timeEntryRepo.Update(ctx, timeEntry)
+	// Save time entry, following the policy of its task's organization. The
+	// week it leaves must not be submitted or approved either.
+	err = s.withUserLock(ctx, timeEntry.UserID, func(ctx context.Context) error {
+		if err := s.checkUnlocked(ctx, oldTask, timeEntry.UserID, oldStart); err != nil {
+			return err
+		}
+		return s.save(ctx, task, timeEntry, s.timeEntryRepo.Update)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -182,24 +241,43 @@ func (s *serviceImpl) Update(ctx context.Context, id uuid.UUID, req models.TimeE
 // Delete deletes a time entry
 func (s *serviceImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	// Check if time entry exists
-	_, err := s.timeEntryRepo.GetByID(ctx, id)
+	timeEntry, err := s.timeEntryRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 	
-	return s.timeEntryRepo.Delete(ctx, id)
+	// Tasks of archived projects are read-only
+	task, err := s.taskRepo.GetByID(ctx, timeEntry.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.Project.IsArchived() {
+		return repository.ErrProjectArchived
+	}
+	
+	// Entries of submitted and approved weeks are locked
+	return s.withUserLock(ctx, timeEntry.UserID, func(ctx context.Context) error {
+		if err := s.checkUnlocked(ctx, task, timeEntry.UserID, timeEntry.StartTime); err != nil {
+			return err
+		}
+		return s.timeEntryRepo.Delete(ctx, id)
+	})
 }
 
 // StartTimer starts a timer for a task
 func (s *serviceImpl) StartTimer(ctx context.Context, taskID uuid.UUID, description string, userID uuid.UUID) (*models.TimeEntryResponse, error) {
-	// Validate task
-	_, err := s.taskRepo.GetByID(ctx, taskID)
+	// Validate task. Timers cannot be started on tasks of archived projects,
+	// but running timers can still be stopped.
+	task, err := s.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
 		if errors.Is(err, repository.ErrTaskNotFound) {
 			return nil, repository.ErrTaskNotFound
 		}
 		return nil, err
 	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
 	
 	// Validate user
 	_, err = s.userRepo.GetByID(ctx, userID)
@@ -210,16 +288,7 @@ func (s *serviceImpl) StartTimer(ctx context.Context, taskID uuid.UUID, descript
 		return nil, err
 	}
 	
-	// Check if user already has a running timer for this task
-	existingTimer, err := s.timeEntryRepo.GetRunningTimeEntry(ctx, userID, taskID)
-	if err != nil && !errors.Is(err, repository.ErrTimeEntryNotFound) {
-		return nil, err
-	}
-	if existingTimer != nil {
-		return nil, repository.ErrRunningTimeEntry
-	}
-	
-	// Create time entry
+	// Create time entry, following the policy of the task's organization
 	now := time.Now()
 	timeEntry := &models.TimeEntry{
 		ID:          uuid.New(),
@@ -227,11 +296,12 @@ func (s *serviceImpl) StartTimer(ctx context.Context, taskID uuid.UUID, descript
 		UserID:      userID,
 		StartTime:   now,
 		Description: description,
+		Billable:    true,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 	
-	err = s.timeEntryRepo.Create(ctx, timeEntry)
+	err = s.save(ctx, task, timeEntry, s.timeEntryRepo.Create)
 	if err != nil {
 		return nil, err
 	}
@@ -258,9 +328,7 @@ func (s *serviceImpl) StopTimer(ctx context.Context, taskID uuid.UUID, userID uu
 	}
 	
 	// Stop timer
-	timeEntry.Stop()
-	
-	err = s.timeEntryRepo.Update(ctx, timeEntry)
+	err = s.stopTimer(ctx, timeEntry, time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -312,3 +380,288 @@ func (s *serviceImpl) GetRunningTimers(ctx context.Context, userID uuid.UUID) ([
 func (s *serviceImpl) Aggregate(ctx context.Context, params models.TimeEntryAggregationParams) ([]models.TimeEntryAggregation, error) {
 	return s.timeEntryRepo.Aggregate(ctx, params)
 }
+
+// GetPolicy retrieves the time entry policy of an organization
+func (s *serviceImpl) GetPolicy(ctx context.Context, organizationID uuid.UUID) (*models.TimeEntryPolicy, error) {
+	return s.policyRepo.GetPolicy(ctx, organizationID)
+}
+
+// UpdatePolicy updates the time entry policy of an organization
+func (s *serviceImpl) UpdatePolicy(ctx context.Context, organizationID uuid.UUID, req models.TimeEntryPolicyRequest, userID uuid.UUID) (*models.TimeEntryPolicy, error) {
+	policy := &models.TimeEntryPolicy{
+		OrganizationID:     organizationID,
+		SingleRunningTimer: req.SingleRunningTimer,
+		OverlapMode:        req.OverlapMode,
+		MaxEntryMinutes:    req.MaxEntryMinutes,
+		AutoStopMinutes:    req.AutoStopMinutes,
+		UpdatedBy:          &userID,
+	}
+	
+	if err := s.policyRepo.SavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	
+	return policy, nil
+}
+
+// AutoStopTimers stops the timers that have run for longer than the policy
+// of their organization allows. Timers are stopped when they reached the
+// limit, not when they are found, and each is stopped and notified in its
+// own transaction.
+func (s *serviceImpl) AutoStopTimers(ctx context.Context, now time.Time) (int, error) {
+	timers, err := s.policyRepo.ListIdleTimers(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	
+	stopped := 0
+	var errs []error
+	for i := range timers {
+		timer := &timers[i]
+		policy := &models.TimeEntryPolicy{MaxEntryMinutes: timer.MaxEntryMinutes}
+		timer.StopAt(policy.StopTime(timer.StartTime, timer.StartTime.Add(time.Duration(timer.AutoStopMinutes)*time.Minute)))
+		
+		err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+			if err := s.timeEntryRepo.StopTimer(ctx, &timer.TimeEntry); err != nil {
+				return err
+			}
+			return s.notificationSvc.NotifyTimerStopped(ctx, &timer.TimeEntry, timer.TaskTitle)
+		})
+		if err != nil {
+			// Timers stopped by their user in the meantime are left as they are
+			if !errors.Is(err, repository.ErrTimerNotRunning) {
+				errs = append(errs, fmt.Errorf("failed to stop timer %s: %w", timer.ID, err))
+			}
+			continue
+		}
+		stopped++
+	}
+	
+	return stopped, errors.Join(errs...)
+}
+
+// policy returns the time entry policy for the entries of a task: the
+// policy of its project's organization, or the default policy
+func (s *serviceImpl) policy(ctx context.Context, task *models.Task) (*models.TimeEntryPolicy, error) {
+	if task.Project == nil {
+		return models.DefaultTimeEntryPolicy(uuid.Nil), nil
+	}
+	return s.policyRepo.GetPolicy(ctx, task.Project.OrganizationID)
+}
+
+// save creates or updates a time entry on a task, as write does, following
+// the time entry policy. Entries cannot be longer than the policy allows,
+// nor overlap other entries of their user unless the policy allows it or
+// merges them; merged entries are deleted once their time is added to the
+// time entry. Entries cannot be written into submitted or approved weeks.
+// Running timers are only subject to the running timer rules.
+// The entries of the user are locked while they are checked and written,
+// so concurrent writes cannot both pass the checks.
+func (s *serviceImpl) save(ctx context.Context, task *models.Task, timeEntry *models.TimeEntry, write func(ctx context.Context, timeEntry *models.TimeEntry) error) error {
+	policy, err := s.policy(ctx, task)
+	if err != nil {
+		return err
+	}
+	
+	return s.withUserLock(ctx, timeEntry.UserID, func(ctx context.Context) error {
+		if err := s.checkUnlocked(ctx, task, timeEntry.UserID, timeEntry.StartTime); err != nil {
+			return err
+		}
+		
+		if timeEntry.IsRunningTimer() {
+			return s.saveTimer(ctx, policy, timeEntry, write)
+		}
+		
+		if err := checkLength(policy, timeEntry); err != nil {
+			return err
+		}
+		
+		var merged []models.TimeEntry
+		if policy.OverlapMode != models.TimeEntryOverlapAllow {
+			overlapping, err := s.timeEntryRepo.ListOverlapping(ctx, timeEntry.UserID, timeEntry.StartTime, timeEntry.EndAt(time.Now()), timeEntry.ID)
+			if err != nil {
+				return err
+			}
+			if len(overlapping) > 0 {
+				if policy.OverlapMode != models.TimeEntryOverlapMerge {
+					return ErrTimeEntryOverlap
+				}
+				if err := s.merge(ctx, task, timeEntry, overlapping); err != nil {
+					return err
+				}
+				if err := checkLength(policy, timeEntry); err != nil {
+					return err
+				}
+				merged = overlapping
+			}
+		}
+		
+		if err := write(ctx, timeEntry); err != nil {
+			return err
+		}
+		for _, entry := range merged {
+			if err := s.timeEntryRepo.Delete(ctx, entry.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// withUserLock runs fn in a transaction that holds the lock on the time
+// entries of a user. Timesheets take the same lock when they are submitted.
+func (s *serviceImpl) withUserLock(ctx context.Context, userID uuid.UUID, fn func(ctx context.Context) error) error {
+	return s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.timeEntryRepo.LockUser(ctx, userID); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+}
+
+// saveTimer writes a running timer, as write does, with the entries of its
+// user locked. A user runs at most one timer per task; users with a single
+// running timer have their other timers stopped.
+func (s *serviceImpl) saveTimer(ctx context.Context, policy *models.TimeEntryPolicy, timeEntry *models.TimeEntry, write func(ctx context.Context, timeEntry *models.TimeEntry) error) error {
+	existing, err := s.timeEntryRepo.GetRunningTimeEntry(ctx, timeEntry.UserID, timeEntry.TaskID)
+	if err != nil && !errors.Is(err, repository.ErrTimeEntryNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != timeEntry.ID {
+		return repository.ErrRunningTimeEntry
+	}
+	
+	if policy.SingleRunningTimer {
+		if err := s.stopRunningTimers(ctx, timeEntry.UserID, timeEntry.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	
+	return write(ctx, timeEntry)
+}
+
+// merge adds the time of overlapping entries to a time entry, which then
+// spans them all. Only the finished entries of the same task and billable
+// flag are merged; entries of submitted or approved weeks are locked.
+func (s *serviceImpl) merge(ctx context.Context, task *models.Task, timeEntry *models.TimeEntry, overlapping []models.TimeEntry) error {
+	now := time.Now()
+	start, end := timeEntry.StartTime, timeEntry.EndAt(now)
+	descriptions := []string{}
+	if timeEntry.Description != "" {
+		descriptions = append(descriptions, timeEntry.Description)
+	}
+	
+	for _, entry := range overlapping {
+		if entry.TaskID != timeEntry.TaskID || entry.Billable != timeEntry.Billable || entry.IsRunningTimer() {
+			return fmt.Errorf("%w: only finished entries of the same task and billable flag can be merged", ErrTimeEntryOverlap)
+		}
+		if err := s.checkUnlocked(ctx, task, entry.UserID, entry.StartTime); err != nil {
+			return err
+		}
+		
+		if entry.StartTime.Before(start) {
+			start = entry.StartTime
+		}
+		if entryEnd := entry.EndAt(now); entryEnd.After(end) {
+			end = entryEnd
+		}
+		if entry.Description != "" && !containsString(descriptions, entry.Description) {
+			descriptions = append(descriptions, entry.Description)
+		}
+	}
+	
+	// The merged entry starts in the week of its earliest entry
+	if err := s.checkUnlocked(ctx, task, timeEntry.UserID, start); err != nil {
+		return err
+	}
+	
+	duration := int(end.Sub(start).Minutes())
+	timeEntry.StartTime = start
+	timeEntry.EndTime = &end
+	timeEntry.DurationMinutes = &duration
+	timeEntry.Description = strings.Join(descriptions, "; ")
+	
+	return nil
+}
+
+// stopRunningTimers stops the running timers of a user, other than exceptID,
+// at now
+func (s *serviceImpl) stopRunningTimers(ctx context.Context, userID, exceptID uuid.UUID, now time.Time) error {
+	timeEntries, err := s.timeEntryRepo.GetRunningTimeEntries(ctx, userID)
+	if err != nil {
+		return err
+	}
+	
+	for i := range timeEntries {
+		if !timeEntries[i].IsRunningTimer() || timeEntries[i].ID == exceptID {
+			continue
+		}
+		if err := s.stopTimer(ctx, &timeEntries[i], now); err != nil && !errors.Is(err, repository.ErrTimerNotRunning) {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// stopTimer stops a running timer at now, or once it reached the maximum
+// length of an entry in the policy of its task's organization
+func (s *serviceImpl) stopTimer(ctx context.Context, timeEntry *models.TimeEntry, now time.Time) error {
+	policy := models.DefaultTimeEntryPolicy(uuid.Nil)
+	task, err := s.taskRepo.GetByID(ctx, timeEntry.TaskID)
+	if err != nil && !errors.Is(err, repository.ErrTaskNotFound) {
+		return err
+	}
+	if task != nil {
+		if policy, err = s.policy(ctx, task); err != nil {
+			return err
+		}
+	}
+	
+	timeEntry.StopAt(policy.StopTime(timeEntry.StartTime, now))
+	return s.timeEntryRepo.StopTimer(ctx, timeEntry)
+}
+
+// checkLength returns ErrTimeEntryTooLong if a finished time entry is longer
+// than a policy allows
+func checkLength(policy *models.TimeEntryPolicy, timeEntry *models.TimeEntry) error {
+	if policy.MaxEntryMinutes == 0 {
+		return nil
+	}
+	
+	end := timeEntry.EndAt(time.Now())
+	if end.Sub(timeEntry.StartTime) > time.Duration(policy.MaxEntryMinutes)*time.Minute {
+		return ErrTimeEntryTooLong
+	}
+	
+	return nil
+}
+
+// containsString returns true if values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnlocked returns ErrTimesheetLocked if an entry of a user on a task,
+// starting at start, is in the week of a submitted or approved timesheet.
+// Timesheets cover the tasks of an organization's projects.
+func (s *serviceImpl) checkUnlocked(ctx context.Context, task *models.Task, userID uuid.UUID, start time.Time) error {
+	if task.Project == nil {
+		return nil
+	}
+	
+	locked, err := s.timesheetRepo.IsLocked(ctx, task.Project.OrganizationID, userID, models.TimesheetWeekStart(start))
+	if err != nil {
+		return err
+	}
+	if locked {
+		return repository.ErrTimesheetLocked
+	}
+	
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
 	"github.com/Jerinji2016/halooid/backend/internal/repository"
 	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
 	"github.com/Jerinji2016/halooid/backend/internal/test"
@@ -33,9 +34,12 @@ func TestTimeEntryAPI(t *testing.T) {
 	timeEntryRepo := repository.NewPostgresTimeEntryRepository(tdb.DB)
 	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
 	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	timesheetRepo := repository.NewPostgresTimesheetRepository(tdb.DB)
+	policyRepo := repository.NewPostgresTimeEntryPolicyRepository(tdb.DB)
+	notificationService := notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), userRepo)
 
 	// Create service and handlers
-	timeEntryService := timeentry.NewService(timeEntryRepo, taskRepo, userRepo)
+	timeEntryService := timeentry.NewService(timeEntryRepo, taskRepo, userRepo, timesheetRepo, policyRepo, notificationService, repository.NewTxManager(tdb.DB))
 	timeEntryHandlers := timeentry.NewHandlers(timeEntryService)
 
 	// Setup Echo
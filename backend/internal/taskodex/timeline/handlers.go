@@ -0,0 +1,113 @@
+package timeline
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for project timelines
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get handles retrieving the timeline of a project
+func (h *Handlers) Get(c echo.Context) error {
+	// Get project ID from path parameter
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Get timeline
+	timeline, err := h.service.Get(c.Request().Context(), projectID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve timeline")
+	}
+
+	return c.JSON(http.StatusOK, timeline)
+}
+
+// Reschedule handles moving a task and the tasks that depend on it
+func (h *Handlers) Reschedule(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get task ID from path parameter
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Parse dry_run parameter
+	dryRun := false
+	dryRunParam := c.QueryParam("dry_run")
+	if dryRunParam != "" {
+		dryRun, err = strconv.ParseBool(dryRunParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid dry_run parameter")
+		}
+	}
+
+	// Parse request body
+	var req models.TaskRescheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Reschedule task
+	result, err := h.service.Reschedule(c.Request().Context(), taskID, req, userID, dryRun)
+	if err != nil {
+		return h.handleError(err, "Failed to reschedule task")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// handleError maps errors from timeline operations to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+	case errors.Is(err, ErrTaskNoProject):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDependencyCycle), errors.Is(err, repository.ErrProjectArchived):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the timeline routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require project:read permission
+	g.GET("/projects/:id/timeline", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require task:write permission
+	g.POST("/tasks/:id/reschedule", h.Reschedule, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
@@ -0,0 +1,282 @@
+package timeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// scheduler schedules the tasks of a project by the critical path method.
+// Days are counted from the start of the timeline; a task runs from its
+// early start for its duration, and ends at its early finish, the day after
+// its last day.
+type scheduler struct {
+	project *models.Project
+	start   time.Time
+
+	// tasks are in topological order: every task after the tasks that
+	// block it, and otherwise in rank order
+	tasks []*models.Task
+	preds map[uuid.UUID][]uuid.UUID
+	succs map[uuid.UUID][]uuid.UUID
+	links []models.TaskLink
+
+	earlyStart  map[uuid.UUID]int
+	earlyFinish map[uuid.UUID]int
+}
+
+// newScheduler creates the scheduler of the tasks of a project, in rank
+// order, and the links between them. Only blocking links are dependencies.
+func newScheduler(project *models.Project, tasks []models.Task, links []models.TaskLink, today time.Time) (*scheduler, error) {
+	s := &scheduler{
+		project:     project,
+		preds:       map[uuid.UUID][]uuid.UUID{},
+		succs:       map[uuid.UUID][]uuid.UUID{},
+		earlyStart:  map[uuid.UUID]int{},
+		earlyFinish: map[uuid.UUID]int{},
+	}
+
+	byID := make(map[uuid.UUID]*models.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	for _, link := range links {
+		if link.Type != models.TaskLinkTypeBlocks {
+			continue
+		}
+		if byID[link.SourceTaskID] == nil || byID[link.TargetTaskID] == nil {
+			continue
+		}
+		s.preds[link.TargetTaskID] = append(s.preds[link.TargetTaskID], link.SourceTaskID)
+		s.succs[link.SourceTaskID] = append(s.succs[link.SourceTaskID], link.TargetTaskID)
+		s.links = append(s.links, link)
+	}
+
+	// Order the tasks topologically, keeping the rank order among the tasks
+	// that are ready
+	waiting := make(map[uuid.UUID]int, len(tasks))
+	for i := range tasks {
+		waiting[tasks[i].ID] = len(s.preds[tasks[i].ID])
+	}
+	for len(s.tasks) < len(tasks) {
+		progressed := false
+		for i := range tasks {
+			task := &tasks[i]
+			if waiting[task.ID] != 0 {
+				continue
+			}
+			waiting[task.ID] = -1
+			s.tasks = append(s.tasks, task)
+			for _, succ := range s.succs[task.ID] {
+				waiting[succ]--
+			}
+			progressed = true
+		}
+		if !progressed {
+			return nil, ErrDependencyCycle
+		}
+	}
+
+	s.start = s.timelineStart(today)
+	return s, nil
+}
+
+// timelineStart returns the first day of the timeline: the start date of
+// the project or of its earliest task, whichever is first, or else today
+func (s *scheduler) timelineStart(today time.Time) time.Time {
+	var start *time.Time
+	consider := func(t *time.Time) {
+		if t != nil && (start == nil || day(*t).Before(*start)) {
+			d := day(*t)
+			start = &d
+		}
+	}
+
+	consider(s.project.StartDate)
+	for _, task := range s.tasks {
+		consider(task.StartDate)
+	}
+
+	if start == nil {
+		return day(today)
+	}
+	return *start
+}
+
+// duration returns the days a task takes: its duration, or else the days
+// from its start date to its due date, or else the default duration
+func duration(task *models.Task) int {
+	if task.DurationDays != nil {
+		return *task.DurationDays
+	}
+	if task.StartDate != nil && task.DueDate != nil {
+		n := days(day(*task.StartDate), day(*task.DueDate)) + 1
+		if n > 0 {
+			return n
+		}
+	}
+	return models.DefaultTaskDurationDays
+}
+
+// forward computes the early start and finish of every task: the later of
+// its start date and the finish of the tasks that block it. The tasks to
+// cascade that would start later than their start date are moved to their
+// early start, along with their due date.
+func (s *scheduler) forward(cascade map[uuid.UUID]bool) {
+	for _, task := range s.tasks {
+		constraint := 0
+		if task.StartDate != nil {
+			constraint = days(s.start, day(*task.StartDate))
+		}
+
+		start := constraint
+		for _, pred := range s.preds[task.ID] {
+			if s.earlyFinish[pred] > start {
+				start = s.earlyFinish[pred]
+			}
+		}
+
+		if cascade[task.ID] && task.StartDate != nil && start > constraint {
+			task.StartDate = addDays(task.StartDate, start-constraint)
+			task.DueDate = addDays(task.DueDate, start-constraint)
+		}
+
+		s.earlyStart[task.ID] = start
+		s.earlyFinish[task.ID] = start + duration(task)
+	}
+}
+
+// dependents returns the tasks a task blocks, directly or transitively
+func (s *scheduler) dependents(taskID uuid.UUID) map[uuid.UUID]bool {
+	dependents := map[uuid.UUID]bool{}
+	queue := []uuid.UUID{taskID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, succ := range s.succs[id] {
+			if !dependents[succ] {
+				dependents[succ] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+	return dependents
+}
+
+// timeline computes the late start of every task from the finish of the
+// project, backwards, and reports the schedule. forward must have been
+// called.
+func (s *scheduler) timeline() *models.Timeline {
+	finish := 0
+	for _, task := range s.tasks {
+		if s.earlyFinish[task.ID] > finish {
+			finish = s.earlyFinish[task.ID]
+		}
+	}
+
+	lateStart := make(map[uuid.UUID]int, len(s.tasks))
+	for i := len(s.tasks) - 1; i >= 0; i-- {
+		task := s.tasks[i]
+		lateFinish := finish
+		for _, succ := range s.succs[task.ID] {
+			if lateStart[succ] < lateFinish {
+				lateFinish = lateStart[succ]
+			}
+		}
+		lateStart[task.ID] = lateFinish - duration(task)
+	}
+
+	timeline := &models.Timeline{
+		ProjectID:    s.project.ID,
+		StartDate:    formatDay(s.start, 0),
+		EndDate:      formatDay(s.start, 0),
+		DurationDays: finish,
+		Tasks:        make([]models.TimelineTask, 0, len(s.tasks)),
+		Dependencies: make([]models.TimelineDependency, 0, len(s.links)),
+		CriticalPath: []uuid.UUID{},
+	}
+
+	last := 0
+	critical := []*models.Task{}
+	for _, task := range s.tasks {
+		// A milestone ends the day it starts, but takes no day of the
+		// project
+		start := s.earlyStart[task.ID]
+		end := s.earlyFinish[task.ID] - 1
+		if end > last {
+			last = end
+		}
+		if end < start {
+			end = start
+		}
+
+		slack := lateStart[task.ID] - start
+		item := models.TimelineTask{
+			TaskID:       task.ID,
+			ParentID:     task.ParentID,
+			Title:        task.Title,
+			Status:       task.Status,
+			Priority:     task.Priority,
+			AssignedTo:   task.AssignedTo,
+			DueDate:      task.DueDate,
+			StartDate:    formatDay(s.start, start),
+			EndDate:      formatDay(s.start, end),
+			DurationDays: duration(task),
+			SlackDays:    slack,
+			Critical:     slack == 0,
+			Late:         task.DueDate != nil && s.start.AddDate(0, 0, end).After(day(*task.DueDate)),
+		}
+		timeline.Tasks = append(timeline.Tasks, item)
+		if item.Critical {
+			critical = append(critical, task)
+		}
+	}
+
+	timeline.EndDate = formatDay(s.start, last)
+	timeline.Late = s.project.EndDate != nil && s.start.AddDate(0, 0, last).After(day(*s.project.EndDate))
+
+	sort.SliceStable(critical, func(i, j int) bool {
+		return s.earlyStart[critical[i].ID] < s.earlyStart[critical[j].ID]
+	})
+	for _, task := range critical {
+		timeline.CriticalPath = append(timeline.CriticalPath, task.ID)
+	}
+
+	for _, link := range s.links {
+		timeline.Dependencies = append(timeline.Dependencies, models.TimelineDependency{
+			LinkID:     link.ID,
+			FromTaskID: link.SourceTaskID,
+			ToTaskID:   link.TargetTaskID,
+		})
+	}
+
+	return timeline
+}
+
+// day returns the start of the UTC day of t
+func day(t time.Time) time.Time {
+	year, month, d := t.UTC().Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// days returns the number of days from the day from to the day to
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// addDays returns t moved by a number of days, or nil if t is nil
+func addDays(t *time.Time, n int) *time.Time {
+	if t == nil {
+		return nil
+	}
+	moved := t.AddDate(0, 0, n)
+	return &moved
+}
+
+// formatDay formats the day a number of days after start
+func formatDay(start time.Time, n int) string {
+	return start.AddDate(0, 0, n).Format(models.AnalyticsDateFormat)
+}
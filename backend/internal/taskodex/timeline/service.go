@@ -0,0 +1,247 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrTaskNoProject   = errors.New("task is not in a project")
+	ErrDependencyCycle = errors.New("the blocking links of the project form a cycle")
+)
+
+// taskPageSize is the number of tasks read at once while scheduling a
+// project
+const taskPageSize = 100
+
+// Service provides project timelines
+type Service interface {
+	// Get retrieves the timeline of a project: its tasks scheduled after the
+	// tasks that block them, with their slack, the critical path and the
+	// projected end of the project
+	Get(ctx context.Context, projectID uuid.UUID) (*models.Timeline, error)
+
+	// Reschedule moves the start date of a task, keeping the days to its due
+	// date, and optionally changes its duration. The dependents of the task
+	// with a start date are pushed back, with their due dates, until they
+	// start after the tasks that block them; they are never brought
+	// forward. A dry run reports the changes without saving them.
+	Reschedule(ctx context.Context, taskID uuid.UUID, req models.TaskRescheduleRequest, userID uuid.UUID, dryRun bool) (*models.TaskRescheduleResult, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	projectRepo     repository.ProjectRepository
+	taskRepo        repository.TaskRepository
+	taskLinkRepo    repository.TaskLinkRepository
+	taskHistoryRepo repository.TaskHistoryRepository
+	txManager       repository.TxManager
+}
+
+// NewService creates a new timeline service
+func NewService(
+	projectRepo repository.ProjectRepository,
+	taskRepo repository.TaskRepository,
+	taskLinkRepo repository.TaskLinkRepository,
+	taskHistoryRepo repository.TaskHistoryRepository,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		projectRepo:     projectRepo,
+		taskRepo:        taskRepo,
+		taskLinkRepo:    taskLinkRepo,
+		taskHistoryRepo: taskHistoryRepo,
+		txManager:       txManager,
+	}
+}
+
+// Get retrieves the timeline of a project
+func (s *serviceImpl) Get(ctx context.Context, projectID uuid.UUID) (*models.Timeline, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, _, err := s.load(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler.forward(nil)
+	return scheduler.timeline(), nil
+}
+
+// Reschedule moves a task and the tasks that depend on it
+func (s *serviceImpl) Reschedule(ctx context.Context, taskID uuid.UUID, req models.TaskRescheduleRequest, userID uuid.UUID, dryRun bool) (*models.TaskRescheduleResult, error) {
+	var result *models.TaskRescheduleResult
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// The tasks of the project keep their schedule from when it is read
+		// until the changes are saved
+		if !dryRun {
+			if err := s.taskRepo.LockSchedule(ctx, taskID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		result, err = s.reschedule(ctx, taskID, req, userID, dryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// reschedule moves a task and the tasks that depend on it, in the
+// transaction in ctx
+func (s *serviceImpl) reschedule(ctx context.Context, taskID uuid.UUID, req models.TaskRescheduleRequest, userID uuid.UUID, dryRun bool) (*models.TaskRescheduleResult, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.ProjectID == nil {
+		return nil, ErrTaskNoProject
+	}
+	if task.Project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, *task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, tasks, err := s.load(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the tasks as they were, to report and record the changes
+	before := make(map[uuid.UUID]models.Task, len(tasks))
+	for _, t := range tasks {
+		before[t.ID] = t
+	}
+
+	// Move the task, keeping the days from its start date to its due date
+	for _, t := range scheduler.tasks {
+		if t.ID != taskID {
+			continue
+		}
+		start := day(req.StartDate)
+		if t.StartDate != nil {
+			t.DueDate = addDays(t.DueDate, days(day(*t.StartDate), start))
+		}
+		t.StartDate = &start
+		if req.DurationDays != nil {
+			durationDays := *req.DurationDays
+			t.DurationDays = &durationDays
+		}
+	}
+
+	// The timeline may now start earlier
+	scheduler.start = scheduler.timelineStart(time.Now())
+	scheduler.forward(scheduler.dependents(taskID))
+
+	result := &models.TaskRescheduleResult{
+		DryRun:  dryRun,
+		Changes: []models.TaskScheduleChange{},
+	}
+
+	var changed []*models.Task
+	for _, t := range scheduler.tasks {
+		old := before[t.ID]
+		if sameTime(old.StartDate, t.StartDate) && sameTime(old.DueDate, t.DueDate) && sameInt(old.DurationDays, t.DurationDays) {
+			continue
+		}
+		changed = append(changed, t)
+		result.Changes = append(result.Changes, models.TaskScheduleChange{
+			TaskID:          t.ID,
+			Title:           t.Title,
+			OldStartDate:    old.StartDate,
+			NewStartDate:    t.StartDate,
+			OldDueDate:      old.DueDate,
+			NewDueDate:      t.DueDate,
+			OldDurationDays: old.DurationDays,
+			NewDurationDays: t.DurationDays,
+		})
+	}
+
+	if !dryRun {
+		for _, t := range changed {
+			old := before[t.ID]
+			if err := s.taskRepo.UpdateSchedule(ctx, t); err != nil {
+				return nil, err
+			}
+			if err := s.taskHistoryRepo.RecordChanges(ctx, t.ID, userID, models.DiffTasks(&old, t)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result.Timeline = scheduler.timeline()
+	return result, nil
+}
+
+// load reads the tasks of a project and the links between them, and
+// creates their scheduler. The tasks are returned in rank order.
+func (s *serviceImpl) load(ctx context.Context, project *models.Project) (*scheduler, []models.Task, error) {
+	params := models.TaskListParams{
+		ProjectID: &project.ID,
+		SortBy:    "rank",
+		SortOrder: "asc",
+		PageSize:  taskPageSize,
+	}
+
+	var tasks []models.Task
+	for params.Page = 1; ; params.Page++ {
+		page, _, err := s.taskRepo.List(ctx, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < taskPageSize {
+			break
+		}
+	}
+
+	links, err := s.taskLinkRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The scheduler moves the tasks it is given, so it gets its own copy
+	scheduled := make([]models.Task, len(tasks))
+	copy(scheduled, tasks)
+
+	scheduler, err := newScheduler(project, scheduled, links, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return scheduler, tasks, nil
+}
+
+// sameTime reports whether two optional times are the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// sameInt reports whether two optional integers are equal
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
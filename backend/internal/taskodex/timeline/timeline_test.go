@@ -0,0 +1,212 @@
+package timeline_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeline"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	taskLinkRepo := repository.NewPostgresTaskLinkRepository(tdb.DB)
+
+	// Create service and handlers
+	timelineService := timeline.NewService(
+		repository.NewPostgresProjectRepository(tdb.DB),
+		taskRepo,
+		taskLinkRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		repository.NewTxManager(tdb.DB),
+	)
+	timelineHandlers := timeline.NewHandlers(timelineService)
+	e := echo.New()
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	date := func(days int) string {
+		return today.AddDate(0, 0, days).Format(models.AnalyticsDateFormat)
+	}
+
+	newTask := func(title string, startDays *int, durationDays int, dueDays *int) *models.Task {
+		req := models.TaskRequest{
+			ProjectID:    &testProject.ID,
+			Title:        prefix + title,
+			Status:       models.TaskStatusTodo,
+			Priority:     models.TaskPriorityMedium,
+			DurationDays: &durationDays,
+		}
+		if startDays != nil {
+			start := today.AddDate(0, 0, *startDays)
+			req.StartDate = &start
+		}
+		if dueDays != nil {
+			due := today.AddDate(0, 0, *dueDays)
+			req.DueDate = &due
+		}
+		task := models.NewTask(req, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	block := func(from, to *models.Task) {
+		require.NoError(t, taskLinkRepo.Create(ctx, &models.TaskLink{
+			ID:           uuid.New(),
+			SourceTaskID: from.ID,
+			TargetTaskID: to.ID,
+			Type:         models.TaskLinkTypeBlocks,
+			CreatedBy:    testUser.ID,
+			CreatedAt:    time.Now(),
+		}))
+	}
+	day0, day1 := 0, 1
+
+	// design blocks build, which blocks launch; docs can be done any time
+	design := newTask("Design", &day0, 3, nil)
+	build := newTask("Build", &day0, 2, &day1)
+	launch := newTask("Launch", nil, 4, nil)
+	docs := newTask("Docs", &day0, 2, nil)
+	block(design, build)
+	block(build, launch)
+
+	find := func(tl *models.Timeline, id uuid.UUID) models.TimelineTask {
+		for _, task := range tl.Tasks {
+			if task.TaskID == id {
+				return task
+			}
+		}
+		t.Fatalf("task %s is not on the timeline", id)
+		return models.TimelineTask{}
+	}
+
+	t.Run("GetTimeline", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(testProject.ID.String())
+
+		require.NoError(t, timelineHandlers.Get(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.Timeline
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, date(0), result.StartDate)
+		assert.Equal(t, date(8), result.EndDate)
+		assert.Equal(t, 9, result.DurationDays)
+		assert.Len(t, result.Tasks, 4)
+		assert.Len(t, result.Dependencies, 2)
+		assert.Equal(t, []uuid.UUID{design.ID, build.ID, launch.ID}, result.CriticalPath)
+
+		// build waits for design, and ends after it is due
+		b := find(&result, build.ID)
+		assert.Equal(t, date(3), b.StartDate)
+		assert.Equal(t, date(4), b.EndDate)
+		assert.True(t, b.Critical)
+		assert.True(t, b.Late)
+
+		// docs can slip until launch ends
+		d := find(&result, docs.ID)
+		assert.Equal(t, date(0), d.StartDate)
+		assert.Equal(t, date(1), d.EndDate)
+		assert.Equal(t, 7, d.SlackDays)
+		assert.False(t, d.Critical)
+	})
+
+	reschedule := func(taskID uuid.UUID, req models.TaskRescheduleRequest, query string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/"+query, bytes.NewReader(body))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httpReq, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(taskID.String())
+		c.Set("user_id", testUser.ID.String())
+
+		require.NoError(t, timelineHandlers.Reschedule(c))
+		return rec
+	}
+
+	t.Run("PreviewReschedule", func(t *testing.T) {
+		rec := reschedule(design.ID, models.TaskRescheduleRequest{StartDate: today.AddDate(0, 0, 2)}, "?dry_run=true")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.TaskRescheduleResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.True(t, result.DryRun)
+
+		// build is pushed back after design; launch has no start date to move
+		require.Len(t, result.Changes, 2)
+		assert.Equal(t, design.ID, result.Changes[0].TaskID)
+		assert.Equal(t, build.ID, result.Changes[1].TaskID)
+		assert.True(t, today.AddDate(0, 0, 5).Equal(*result.Changes[1].NewStartDate))
+		assert.True(t, today.AddDate(0, 0, 6).Equal(*result.Changes[1].NewDueDate))
+		assert.Equal(t, date(10), result.Timeline.EndDate)
+
+		// Nothing is saved
+		stored, err := taskRepo.GetByID(ctx, build.ID)
+		require.NoError(t, err)
+		assert.True(t, today.Equal(*stored.StartDate))
+	})
+
+	t.Run("Reschedule", func(t *testing.T) {
+		durationDays := 1
+		rec := reschedule(design.ID, models.TaskRescheduleRequest{StartDate: today.AddDate(0, 0, 2), DurationDays: &durationDays}, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.TaskRescheduleResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.False(t, result.DryRun)
+		assert.Equal(t, date(8), result.Timeline.EndDate)
+
+		stored, err := taskRepo.GetByID(ctx, design.ID)
+		require.NoError(t, err)
+		assert.True(t, today.AddDate(0, 0, 2).Equal(*stored.StartDate))
+		assert.Equal(t, 1, *stored.DurationDays)
+
+		stored, err = taskRepo.GetByID(ctx, build.ID)
+		require.NoError(t, err)
+		assert.True(t, today.AddDate(0, 0, 3).Equal(*stored.StartDate))
+		assert.True(t, today.AddDate(0, 0, 4).Equal(*stored.DueDate))
+
+		// Rescheduling again changes nothing
+		rec = reschedule(design.ID, models.TaskRescheduleRequest{StartDate: today.AddDate(0, 0, 2)}, "")
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Empty(t, result.Changes)
+	})
+
+	t.Run("TaskWithoutProject", func(t *testing.T) {
+		task := models.NewTask(models.TaskRequest{
+			Title:    prefix + "Loose",
+			Status:   models.TaskStatusTodo,
+			Priority: models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+
+		_, err := timelineService.Reschedule(ctx, task.ID, models.TaskRescheduleRequest{StartDate: today}, testUser.ID, true)
+		assert.ErrorIs(t, err, timeline.ErrTaskNoProject)
+	})
+}
@@ -0,0 +1,314 @@
+package timesheet
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for timesheets
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetWeek handles retrieving the week of a member
+func (h *Handlers) GetWeek(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Parse user_id parameter, the current user by default
+	userIDParam := c.QueryParam("user_id")
+	if userIDParam != "" {
+		userID, err = uuid.Parse(userIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid user_id parameter")
+		}
+	}
+
+	// Parse week parameter, the current week by default
+	week := time.Now().UTC()
+	weekParam := c.QueryParam("week")
+	if weekParam != "" {
+		week, err = time.Parse(models.AnalyticsDateFormat, weekParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid week parameter")
+		}
+	}
+
+	// Get week
+	result, err := h.service.GetWeek(c.Request().Context(), orgID, userID, week)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve timesheet")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Get handles retrieving a timesheet by ID
+func (h *Handlers) Get(c echo.Context) error {
+	// Get organization and timesheet IDs
+	orgID, id, err := parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Get timesheet
+	result, err := h.service.GetByID(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve timesheet")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// List handles listing the timesheets of an organization
+func (h *Handlers) List(c echo.Context) error {
+	// Get user ID from context
+	currentUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	params := models.TimesheetListParams{OrganizationID: orgID}
+
+	// Parse user_id parameter
+	userIDParam := c.QueryParam("user_id")
+	if userIDParam != "" {
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid user_id parameter")
+		}
+		params.UserID = &userID
+	}
+
+	// Parse status parameter
+	statusParam := c.QueryParam("status")
+	if statusParam != "" {
+		status := models.TimesheetStatus(statusParam)
+		if status != models.TimesheetStatusSubmitted && status != models.TimesheetStatusApproved && status != models.TimesheetStatusRejected {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid status parameter")
+		}
+		params.Status = &status
+	}
+
+	// Parse managed parameter, to list the timesheets of the current user's
+	// reports
+	managedParam := c.QueryParam("managed")
+	if managedParam != "" {
+		managed, err := strconv.ParseBool(managedParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid managed parameter")
+		}
+		if managed {
+			params.ManagerID = &currentUserID
+		}
+	}
+
+	// Parse from parameter
+	fromParam := c.QueryParam("from")
+	if fromParam != "" {
+		from, err := time.Parse(models.AnalyticsDateFormat, fromParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid from parameter")
+		}
+		params.From = &from
+	}
+
+	// Parse to parameter
+	toParam := c.QueryParam("to")
+	if toParam != "" {
+		to, err := time.Parse(models.AnalyticsDateFormat, toParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid to parameter")
+		}
+		params.To = &to
+	}
+
+	// List timesheets
+	timesheets, err := h.service.List(c.Request().Context(), params)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve timesheets")
+	}
+
+	return c.JSON(http.StatusOK, timesheets)
+}
+
+// Submit handles submitting a week of the current user for approval
+func (h *Handlers) Submit(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Parse request body
+	var req models.TimesheetSubmitRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Submit week
+	result, err := h.service.Submit(c.Request().Context(), orgID, userID, req)
+	if err != nil {
+		return h.handleError(err, "Failed to submit timesheet")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Approve handles approving a submitted timesheet
+func (h *Handlers) Approve(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization and timesheet IDs
+	orgID, id, err := parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.TimesheetApproveRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Approve timesheet
+	result, err := h.service.Approve(c.Request().Context(), orgID, id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to approve timesheet")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Reject handles rejecting a submitted timesheet
+func (h *Handlers) Reject(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization and timesheet IDs
+	orgID, id, err := parseIDs(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse request body
+	var req models.TimesheetRejectRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Reject timesheet
+	result, err := h.service.Reject(c.Request().Context(), orgID, id, req, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to reject timesheet")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// parseIDs parses the organization and timesheet IDs from the path
+// parameters
+func parseIDs(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid timesheet ID")
+	}
+
+	return orgID, id, nil
+}
+
+// handleError maps errors from timesheet operations to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrTimesheetNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Timesheet not found")
+	case errors.Is(err, ErrNoManager), errors.Is(err, ErrWeekNotStarted):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotManager):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrRunningEntries), errors.Is(err, ErrAlreadySubmitted), errors.Is(err, ErrNotAwaitingApproval):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the timesheet routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	timesheetGroup := g.Group("/timesheets")
+
+	// Routes that require time_entry:read permission
+	timesheetGroup.GET("", h.List, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
+	timesheetGroup.GET("/week", h.GetWeek, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
+	timesheetGroup.GET("/:id", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryRead))
+
+	// Routes that require time_entry:write permission
+	timesheetGroup.POST("/submit", h.Submit, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryWrite))
+	timesheetGroup.POST("/:id/approve", h.Approve, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryWrite))
+	timesheetGroup.POST("/:id/reject", h.Reject, rbacMiddleware.RequirePermission(middleware.PermissionTimeEntryWrite))
+}
@@ -0,0 +1,311 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrNoManager           = errors.New("user has no manager in this organization to approve their timesheets")
+	ErrNotManager          = errors.New("only the user's manager can review their timesheets")
+	ErrWeekNotStarted      = errors.New("weeks can only be submitted once they have started")
+	ErrRunningEntries      = errors.New("the week has running timers; stop them before submitting it")
+	ErrAlreadySubmitted    = errors.New("timesheet has already been submitted")
+	ErrNotAwaitingApproval = errors.New("timesheet is not awaiting approval")
+)
+
+// Service provides weekly timesheets and their approval
+type Service interface {
+	// GetWeek retrieves the time a member logged in the week of a day, on
+	// the tasks of the organization's projects, with its timesheet if it was
+	// submitted
+	GetWeek(ctx context.Context, organizationID, userID uuid.UUID, week time.Time) (*models.TimesheetWeek, error)
+
+	// GetByID retrieves a timesheet of an organization, with its week
+	GetByID(ctx context.Context, organizationID, id uuid.UUID) (*models.TimesheetWeek, error)
+
+	// List retrieves the timesheets of an organization
+	List(ctx context.Context, params models.TimesheetListParams) ([]models.Timesheet, error)
+
+	// Submit submits the week of a day for approval by the user's manager,
+	// which is the manager of their Qultrix employee record. A rejected week
+	// can be submitted again. The entries of the week are locked until it
+	// is rejected.
+	Submit(ctx context.Context, organizationID, userID uuid.UUID, req models.TimesheetSubmitRequest) (*models.TimesheetWeek, error)
+
+	// Approve approves a submitted timesheet. Only the manager of its user
+	// can approve it, and its entries stay locked.
+	Approve(ctx context.Context, organizationID, id uuid.UUID, req models.TimesheetApproveRequest, reviewerID uuid.UUID) (*models.TimesheetWeek, error)
+
+	// Reject rejects a submitted timesheet with a comment, unlocking its
+	// entries to be corrected. Only the manager of its user can reject it.
+	Reject(ctx context.Context, organizationID, id uuid.UUID, req models.TimesheetRejectRequest, reviewerID uuid.UUID) (*models.TimesheetWeek, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	timesheetRepo repository.TimesheetRepository
+	employeeRepo  repository.EmployeeRepository
+	txManager     repository.TxManager
+}
+
+// NewService creates a new timesheet service
+func NewService(timesheetRepo repository.TimesheetRepository, employeeRepo repository.EmployeeRepository, txManager repository.TxManager) Service {
+	return &serviceImpl{
+		timesheetRepo: timesheetRepo,
+		employeeRepo:  employeeRepo,
+		txManager:     txManager,
+	}
+}
+
+// GetWeek retrieves the time a member logged in a week
+func (s *serviceImpl) GetWeek(ctx context.Context, organizationID, userID uuid.UUID, week time.Time) (*models.TimesheetWeek, error) {
+	weekStart := models.TimesheetWeekStart(week)
+
+	timesheet, err := s.timesheetRepo.GetByWeek(ctx, organizationID, userID, weekStart)
+	if err != nil && !errors.Is(err, repository.ErrTimesheetNotFound) {
+		return nil, err
+	}
+
+	return s.week(ctx, organizationID, userID, weekStart, timesheet)
+}
+
+// GetByID retrieves a timesheet of an organization
+func (s *serviceImpl) GetByID(ctx context.Context, organizationID, id uuid.UUID) (*models.TimesheetWeek, error) {
+	timesheet, err := s.get(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.week(ctx, organizationID, timesheet.UserID, timesheet.WeekStart, timesheet)
+}
+
+// List retrieves the timesheets of an organization
+func (s *serviceImpl) List(ctx context.Context, params models.TimesheetListParams) ([]models.Timesheet, error) {
+	return s.timesheetRepo.List(ctx, params)
+}
+
+// Submit submits a week for approval
+func (s *serviceImpl) Submit(ctx context.Context, organizationID, userID uuid.UUID, req models.TimesheetSubmitRequest) (*models.TimesheetWeek, error) {
+	weekStart := models.TimesheetWeekStart(req.Week)
+	now := time.Now()
+	if weekStart.After(now) {
+		return nil, ErrWeekNotStarted
+	}
+
+	// Timesheets go to the user's manager
+	if _, err := s.manager(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	var timesheet *models.Timesheet
+	err := s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// No entry can be added to the week between totalling and locking it
+		if err := s.timesheetRepo.LockEntries(ctx, userID); err != nil {
+			return err
+		}
+
+		entries, err := s.timesheetRepo.ListEntries(ctx, organizationID, userID, weekStart, weekStart.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+
+		total := 0
+		for _, entry := range entries {
+			if entry.IsRunning() {
+				return ErrRunningEntries
+			}
+			total += entry.CalculateDuration()
+		}
+
+		timesheet, err = s.timesheetRepo.GetByWeek(ctx, organizationID, userID, weekStart)
+		switch {
+		case errors.Is(err, repository.ErrTimesheetNotFound):
+			timesheet = &models.Timesheet{
+				ID:             uuid.New(),
+				OrganizationID: organizationID,
+				UserID:         userID,
+				WeekStart:      weekStart,
+				Status:         models.TimesheetStatusSubmitted,
+				TotalMinutes:   total,
+				SubmittedAt:    now,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}
+			if err := s.timesheetRepo.Create(ctx, timesheet); err != nil {
+				if errors.Is(err, repository.ErrTimesheetExists) {
+					return ErrAlreadySubmitted
+				}
+				return err
+			}
+		case err != nil:
+			return err
+		case timesheet.Status != models.TimesheetStatusRejected:
+			return ErrAlreadySubmitted
+		default:
+			// Submit the rejected week again
+			timesheet.Status = models.TimesheetStatusSubmitted
+			timesheet.TotalMinutes = total
+			timesheet.SubmittedAt = now
+			timesheet.ReviewedBy = nil
+			timesheet.ReviewedAt = nil
+			if err := s.timesheetRepo.SetStatus(ctx, timesheet, models.TimesheetStatusRejected); err != nil {
+				if errors.Is(err, repository.ErrTimesheetStatusChanged) {
+					return ErrAlreadySubmitted
+				}
+				return err
+			}
+		}
+
+		return s.timesheetRepo.AddComment(ctx, models.NewTimesheetComment(timesheet.ID, userID, models.TimesheetStatusSubmitted, req.Comment))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.week(ctx, organizationID, userID, weekStart, timesheet)
+}
+
+// Approve approves a submitted timesheet
+func (s *serviceImpl) Approve(ctx context.Context, organizationID, id uuid.UUID, req models.TimesheetApproveRequest, reviewerID uuid.UUID) (*models.TimesheetWeek, error) {
+	return s.review(ctx, organizationID, id, models.TimesheetStatusApproved, req.Comment, reviewerID)
+}
+
+// Reject rejects a submitted timesheet
+func (s *serviceImpl) Reject(ctx context.Context, organizationID, id uuid.UUID, req models.TimesheetRejectRequest, reviewerID uuid.UUID) (*models.TimesheetWeek, error) {
+	return s.review(ctx, organizationID, id, models.TimesheetStatusRejected, req.Comment, reviewerID)
+}
+
+// review approves or rejects a submitted timesheet on behalf of the manager
+// of its user
+func (s *serviceImpl) review(ctx context.Context, organizationID, id uuid.UUID, status models.TimesheetStatus, comment string, reviewerID uuid.UUID) (*models.TimesheetWeek, error) {
+	timesheet, err := s.get(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if timesheet.Status != models.TimesheetStatusSubmitted {
+		return nil, ErrNotAwaitingApproval
+	}
+
+	// The manager is resolved when the timesheet is reviewed, so it goes to
+	// whoever manages the user by then
+	managerID, err := s.manager(ctx, organizationID, timesheet.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if managerID != reviewerID {
+		return nil, ErrNotManager
+	}
+
+	now := time.Now()
+	timesheet.Status = status
+	timesheet.ReviewedBy = &reviewerID
+	timesheet.ReviewedAt = &now
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.timesheetRepo.SetStatus(ctx, timesheet, models.TimesheetStatusSubmitted); err != nil {
+			if errors.Is(err, repository.ErrTimesheetStatusChanged) {
+				return ErrNotAwaitingApproval
+			}
+			return err
+		}
+		return s.timesheetRepo.AddComment(ctx, models.NewTimesheetComment(timesheet.ID, reviewerID, status, comment))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.week(ctx, organizationID, timesheet.UserID, timesheet.WeekStart, timesheet)
+}
+
+// get retrieves a timesheet of an organization
+func (s *serviceImpl) get(ctx context.Context, organizationID, id uuid.UUID) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if timesheet.OrganizationID != organizationID {
+		return nil, repository.ErrTimesheetNotFound
+	}
+
+	return timesheet, nil
+}
+
+// manager returns the ID of the user who manages a member of an
+// organization: the user of the manager of their employee record
+func (s *serviceImpl) manager(ctx context.Context, organizationID, userID uuid.UUID) (uuid.UUID, error) {
+	employee, err := s.employeeRepo.GetByUserID(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return uuid.Nil, ErrNoManager
+		}
+		return uuid.Nil, err
+	}
+
+	if employee.ManagerID == nil {
+		return uuid.Nil, ErrNoManager
+	}
+
+	manager, err := s.employeeRepo.GetByID(ctx, *employee.ManagerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return uuid.Nil, ErrNoManager
+		}
+		return uuid.Nil, err
+	}
+
+	return manager.UserID, nil
+}
+
+// week reports the time a member logged in the week starting on weekStart,
+// with its timesheet if any
+func (s *serviceImpl) week(ctx context.Context, organizationID, userID uuid.UUID, weekStart time.Time, timesheet *models.Timesheet) (*models.TimesheetWeek, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	entries, err := s.timesheetRepo.ListEntries(ctx, organizationID, userID, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	week := &models.TimesheetWeek{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		WeekStart:      weekStart.Format(models.AnalyticsDateFormat),
+		WeekEnd:        weekEnd.AddDate(0, 0, -1).Format(models.AnalyticsDateFormat),
+		Status:         models.TimesheetStatusOpen,
+		Days:           make([]models.TimesheetDay, 7),
+		Entries:        make([]models.TimeEntryResponse, 0, len(entries)),
+	}
+
+	for i := range week.Days {
+		week.Days[i].Date = weekStart.AddDate(0, 0, i).Format(models.AnalyticsDateFormat)
+	}
+
+	for _, entry := range entries {
+		minutes := entry.CalculateDuration()
+		day := int(entry.StartTime.UTC().Sub(weekStart).Hours() / 24)
+		week.Days[day].Minutes += minutes
+		week.TotalMinutes += minutes
+		week.Entries = append(week.Entries, entry.ToResponse())
+	}
+
+	if timesheet != nil {
+		comments, err := s.timesheetRepo.ListComments(ctx, timesheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		timesheet.Comments = comments
+		week.Timesheet = timesheet
+		week.Status = timesheet.Status
+	}
+
+	return week, nil
+}
@@ -0,0 +1,245 @@
+package timesheet_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timesheet"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimesheet(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test users, organization, project and task
+	worker := tdb.CreateTestUser(t, prefix)
+	manager := tdb.CreateTestUser(t, prefix+"2")
+	outsider := tdb.CreateTestUser(t, prefix+"3")
+	testOrg := tdb.CreateTestOrganization(t, prefix, worker.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, worker.ID)
+	testTask := tdb.CreateTestTask(t, prefix, testProject.ID, worker.ID)
+
+	// Create repositories
+	employeeRepo := repository.NewPostgresEmployeeRepository(tdb.DB)
+	timesheetRepo := repository.NewPostgresTimesheetRepository(tdb.DB)
+
+	// Create services and handlers
+	timeEntryService := timeentry.NewService(
+		repository.NewPostgresTimeEntryRepository(tdb.DB),
+		repository.NewPostgresTaskRepository(tdb.DB),
+		repository.NewPostgresUserRepository(tdb.DB),
+		timesheetRepo,
+		repository.NewPostgresTimeEntryPolicyRepository(tdb.DB),
+		notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), repository.NewPostgresUserRepository(tdb.DB)),
+		repository.NewTxManager(tdb.DB),
+	)
+	timesheetService := timesheet.NewService(timesheetRepo, employeeRepo, repository.NewTxManager(tdb.DB))
+	timesheetHandlers := timesheet.NewHandlers(timesheetService)
+	e := echo.New()
+
+	// worker reports to manager in Qultrix
+	newEmployee := func(user *models.User, employeeID string, managerID *uuid.UUID) *models.Employee {
+		employee := models.NewEmployee(models.EmployeeRequest{
+			UserID:     user.ID,
+			EmployeeID: prefix + employeeID,
+			Department: "Engineering",
+			Position:   "Engineer",
+			HireDate:   time.Now().AddDate(-1, 0, 0),
+			ManagerID:  managerID,
+			Salary:     1,
+		}, testOrg.ID)
+		require.NoError(t, employeeRepo.Create(ctx, employee))
+		return employee
+	}
+	lead := newEmployee(manager, "E1", nil)
+	newEmployee(worker, "E2", &lead.ID)
+
+	// worker logged two hours on Monday and one on Wednesday last week
+	lastWeek := models.TimesheetWeekStart(time.Now()).AddDate(0, 0, -7)
+	logTime := func(day, minutes int) *models.TimeEntryResponse {
+		entry, err := timeEntryService.Create(ctx, models.TimeEntryRequest{
+			TaskID:          testTask.ID,
+			StartTime:       lastWeek.AddDate(0, 0, day).Add(9 * time.Hour),
+			DurationMinutes: &minutes,
+		}, worker.ID)
+		require.NoError(t, err)
+		return entry
+	}
+	monday := logTime(0, 120)
+	logTime(2, 60)
+
+	post := func(path string, id uuid.UUID, userID uuid.UUID, body interface{}, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), id.String())
+		c.Set("user_id", userID.String())
+		return rec, handler(c)
+	}
+
+	var timesheetID uuid.UUID
+
+	t.Run("GetOpenWeek", func(t *testing.T) {
+		// Any day of the week gets the whole week
+		week, err := timesheetService.GetWeek(ctx, testOrg.ID, worker.ID, lastWeek.AddDate(0, 0, 4))
+		require.NoError(t, err)
+		assert.Equal(t, models.TimesheetStatusOpen, week.Status)
+		assert.Equal(t, lastWeek.Format(models.AnalyticsDateFormat), week.WeekStart)
+		assert.Equal(t, lastWeek.AddDate(0, 0, 6).Format(models.AnalyticsDateFormat), week.WeekEnd)
+		assert.Equal(t, 180, week.TotalMinutes)
+		require.Len(t, week.Days, 7)
+		assert.Equal(t, 120, week.Days[0].Minutes)
+		assert.Equal(t, 60, week.Days[2].Minutes)
+		assert.Len(t, week.Entries, 2)
+		assert.Nil(t, week.Timesheet)
+	})
+
+	t.Run("SubmitWithoutManager", func(t *testing.T) {
+		_, err := timesheetService.Submit(ctx, testOrg.ID, outsider.ID, models.TimesheetSubmitRequest{Week: lastWeek})
+		assert.ErrorIs(t, err, timesheet.ErrNoManager)
+
+		_, err = timesheetService.Submit(ctx, testOrg.ID, worker.ID, models.TimesheetSubmitRequest{Week: lastWeek.AddDate(0, 0, 14)})
+		assert.ErrorIs(t, err, timesheet.ErrWeekNotStarted)
+	})
+
+	t.Run("Submit", func(t *testing.T) {
+		rec, err := post("/", uuid.Nil, worker.ID, models.TimesheetSubmitRequest{Week: lastWeek, Comment: "All done"}, timesheetHandlers.Submit)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var week models.TimesheetWeek
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &week))
+		assert.Equal(t, models.TimesheetStatusSubmitted, week.Status)
+		require.NotNil(t, week.Timesheet)
+		assert.Equal(t, 180, week.Timesheet.TotalMinutes)
+		require.Len(t, week.Timesheet.Comments, 1)
+		assert.Equal(t, "All done", week.Timesheet.Comments[0].Comment)
+		timesheetID = week.Timesheet.ID
+
+		_, err = timesheetService.Submit(ctx, testOrg.ID, worker.ID, models.TimesheetSubmitRequest{Week: lastWeek})
+		assert.ErrorIs(t, err, timesheet.ErrAlreadySubmitted)
+	})
+
+	t.Run("SubmittedWeekIsLocked", func(t *testing.T) {
+		minutes := 90
+		_, err := timeEntryService.Update(ctx, monday.ID, models.TimeEntryRequest{
+			TaskID:          testTask.ID,
+			StartTime:       monday.StartTime,
+			DurationMinutes: &minutes,
+		})
+		assert.ErrorIs(t, err, repository.ErrTimesheetLocked)
+
+		err = timeEntryService.Delete(ctx, monday.ID)
+		assert.ErrorIs(t, err, repository.ErrTimesheetLocked)
+
+		_, err = timeEntryService.Create(ctx, models.TimeEntryRequest{
+			TaskID:          testTask.ID,
+			StartTime:       lastWeek.AddDate(0, 0, 4),
+			DurationMinutes: &minutes,
+		}, worker.ID)
+		assert.ErrorIs(t, err, repository.ErrTimesheetLocked)
+	})
+
+	t.Run("OnlyManagerReviews", func(t *testing.T) {
+		_, err := timesheetService.Approve(ctx, testOrg.ID, timesheetID, models.TimesheetApproveRequest{}, outsider.ID)
+		assert.ErrorIs(t, err, timesheet.ErrNotManager)
+
+		_, err = timesheetService.Approve(ctx, testOrg.ID, timesheetID, models.TimesheetApproveRequest{}, worker.ID)
+		assert.ErrorIs(t, err, timesheet.ErrNotManager)
+
+		// The manager sees the timesheet awaiting approval
+		status := models.TimesheetStatusSubmitted
+		pending, err := timesheetService.List(ctx, models.TimesheetListParams{OrganizationID: testOrg.ID, ManagerID: &manager.ID, Status: &status})
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, timesheetID, pending[0].ID)
+
+		pending, err = timesheetService.List(ctx, models.TimesheetListParams{OrganizationID: testOrg.ID, ManagerID: &outsider.ID})
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("RejectAndResubmit", func(t *testing.T) {
+		// Rejections need a comment
+		_, err := post("/", timesheetID, manager.ID, models.TimesheetRejectRequest{}, timesheetHandlers.Reject)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+
+		rec, err := post("/", timesheetID, manager.ID, models.TimesheetRejectRequest{Comment: "Monday was 90 minutes"}, timesheetHandlers.Reject)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var week models.TimesheetWeek
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &week))
+		assert.Equal(t, models.TimesheetStatusRejected, week.Status)
+		require.NotNil(t, week.Timesheet.ReviewedBy)
+		assert.Equal(t, manager.ID, *week.Timesheet.ReviewedBy)
+
+		// The entries can be corrected once rejected
+		minutes := 90
+		_, err = timeEntryService.Update(ctx, monday.ID, models.TimeEntryRequest{
+			TaskID:          testTask.ID,
+			StartTime:       monday.StartTime,
+			DurationMinutes: &minutes,
+		})
+		require.NoError(t, err)
+
+		resubmitted, err := timesheetService.Submit(ctx, testOrg.ID, worker.ID, models.TimesheetSubmitRequest{Week: lastWeek, Comment: "Fixed Monday"})
+		require.NoError(t, err)
+		assert.Equal(t, models.TimesheetStatusSubmitted, resubmitted.Status)
+		assert.Equal(t, timesheetID, resubmitted.Timesheet.ID)
+		assert.Equal(t, 150, resubmitted.Timesheet.TotalMinutes)
+		assert.Nil(t, resubmitted.Timesheet.ReviewedBy)
+
+		// The whole exchange is kept
+		require.Len(t, resubmitted.Timesheet.Comments, 3)
+		assert.Equal(t, models.TimesheetStatusSubmitted, resubmitted.Timesheet.Comments[0].Action)
+		assert.Equal(t, models.TimesheetStatusRejected, resubmitted.Timesheet.Comments[1].Action)
+		assert.Equal(t, "Monday was 90 minutes", resubmitted.Timesheet.Comments[1].Comment)
+		assert.Equal(t, "Fixed Monday", resubmitted.Timesheet.Comments[2].Comment)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		rec, err := post("/", timesheetID, manager.ID, models.TimesheetApproveRequest{Comment: "Thanks"}, timesheetHandlers.Approve)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		week, err := timesheetService.GetByID(ctx, testOrg.ID, timesheetID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TimesheetStatusApproved, week.Status)
+		assert.Equal(t, 150, week.TotalMinutes)
+
+		// Approved weeks stay locked and cannot be reviewed again
+		err = timeEntryService.Delete(ctx, monday.ID)
+		assert.ErrorIs(t, err, repository.ErrTimesheetLocked)
+
+		_, err = timesheetService.Reject(ctx, testOrg.ID, timesheetID, models.TimesheetRejectRequest{Comment: "Too late"}, manager.ID)
+		assert.ErrorIs(t, err, timesheet.ErrNotAwaitingApproval)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		_, err := timesheetService.GetByID(ctx, uuid.New(), timesheetID)
+		assert.ErrorIs(t, err, repository.ErrTimesheetNotFound)
+	})
+}
@@ -0,0 +1,293 @@
+package transfer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// taskWriter writes the tasks of an export
+type taskWriter interface {
+	// Write writes a task
+	Write(task models.TaskTransferTask) error
+
+	// Close finishes the export
+	Close() error
+}
+
+// Export writes the tasks of a project to w as they are read
+func (s *serviceImpl) Export(ctx context.Context, organizationID, projectID uuid.UUID, format models.TaskTransferFormat, w io.Writer) error {
+	// Check if project exists in the organization
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if project.OrganizationID != organizationID {
+		return repository.ErrProjectNotFound
+	}
+
+	var writer taskWriter
+	switch format {
+	case models.TaskTransferFormatJSON:
+		writer, err = newJSONWriter(w, project)
+	case models.TaskTransferFormatCSV:
+		writer, err = newCSVWriter(w)
+	default:
+		return ErrUnsupportedFormat
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only JSON exports include comments and time entries
+	withActivity := format == models.TaskTransferFormatJSON
+	emails := make(map[uuid.UUID]string)
+
+	params := models.TaskListParams{
+		ProjectID: &projectID,
+		SortBy:    "created_at",
+		SortOrder: "asc",
+		PageSize:  exportPageSize,
+	}
+	for params.Page = 1; ; params.Page++ {
+		tasks, _, err := s.taskRepo.List(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		taskIDs := make([]uuid.UUID, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+
+		comments := map[uuid.UUID][]models.Comment{}
+		timeEntries := map[uuid.UUID][]models.TimeEntry{}
+		if withActivity {
+			comments, err = s.commentRepo.ListByTasks(ctx, taskIDs)
+			if err != nil {
+				return err
+			}
+			timeEntries, err = s.timeEntryRepo.ListByTasks(ctx, taskIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, task := range tasks {
+			exported, err := s.exportTask(ctx, emails, &task, comments[task.ID], timeEntries[task.ID])
+			if err != nil {
+				return err
+			}
+			if err := writer.Write(exported); err != nil {
+				return err
+			}
+		}
+
+		if len(tasks) < exportPageSize {
+			return writer.Close()
+		}
+	}
+}
+
+// exportTask converts a task to its exported form
+func (s *serviceImpl) exportTask(ctx context.Context, emails map[uuid.UUID]string, task *models.Task, comments []models.Comment, timeEntries []models.TimeEntry) (models.TaskTransferTask, error) {
+	createdAt := task.CreatedAt
+	exported := models.TaskTransferTask{
+		Key:            task.ID.String(),
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         string(task.Status),
+		Priority:       string(task.Priority),
+		DueDate:        task.DueDate,
+		EstimatedHours: task.EstimatedHours,
+		Tags:           task.Tags,
+		CreatedAt:      &createdAt,
+	}
+	if task.ParentID != nil {
+		exported.ParentKey = task.ParentID.String()
+	}
+
+	var err error
+	if exported.CreatorEmail, err = s.email(ctx, emails, task.CreatedBy); err != nil {
+		return exported, err
+	}
+	if task.AssignedTo != nil {
+		if exported.AssigneeEmail, err = s.email(ctx, emails, *task.AssignedTo); err != nil {
+			return exported, err
+		}
+	}
+
+	for _, comment := range comments {
+		createdAt := comment.CreatedAt
+		author, err := s.email(ctx, emails, comment.UserID)
+		if err != nil {
+			return exported, err
+		}
+		exported.Comments = append(exported.Comments, models.TaskTransferComment{
+			AuthorEmail: author,
+			Content:     comment.Content,
+			CreatedAt:   &createdAt,
+		})
+	}
+
+	for _, timeEntry := range timeEntries {
+		user, err := s.email(ctx, emails, timeEntry.UserID)
+		if err != nil {
+			return exported, err
+		}
+		billable := timeEntry.Billable
+		exported.TimeEntries = append(exported.TimeEntries, models.TaskTransferTimeEntry{
+			UserEmail:       user,
+			StartTime:       timeEntry.StartTime,
+			EndTime:         timeEntry.EndTime,
+			DurationMinutes: timeEntry.DurationMinutes,
+			Description:     timeEntry.Description,
+			Billable:        &billable,
+		})
+	}
+
+	return exported, nil
+}
+
+// email returns the email of a user, or an empty string if the user no longer
+// exists
+func (s *serviceImpl) email(ctx context.Context, emails map[uuid.UUID]string, userID uuid.UUID) (string, error) {
+	if email, ok := emails[userID]; ok {
+		return email, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return "", err
+		}
+		emails[userID] = ""
+		return "", nil
+	}
+
+	emails[userID] = user.Email
+	return user.Email, nil
+}
+
+// jsonWriter writes a Taskodex JSON export one task at a time
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+// newJSONWriter starts a JSON export of a project
+func newJSONWriter(w io.Writer, project *models.Project) (*jsonWriter, error) {
+	header, err := json.Marshal(models.TaskTransferDocument{
+		Format:     models.TaskTransferDocumentFormat,
+		Version:    models.TaskTransferDocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Project: models.TaskTransferProject{
+			ID:   project.ID.String(),
+			Name: project.Name,
+		},
+		Tasks: []models.TaskTransferTask{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The tasks are written into the empty list that ends the header
+	header = header[:len(header)-len("]}")]
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w}, nil
+}
+
+// Write writes a task
+func (j *jsonWriter) Write(task models.TaskTransferTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		data = append([]byte(","), data...)
+	}
+	j.count++
+
+	_, err = j.w.Write(data)
+	return err
+}
+
+// Close finishes the export
+func (j *jsonWriter) Close() error {
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+// csvWriter writes a CSV export with a column for each task import field, so
+// that it can be imported without a mapping
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// newCSVWriter starts a CSV export
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(models.TaskImportFields); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: writer}, nil
+}
+
+// Write writes a task
+func (c *csvWriter) Write(task models.TaskTransferTask) error {
+	row := make([]string, 0, len(models.TaskImportFields))
+	for _, field := range models.TaskImportFields {
+		value := ""
+		switch field {
+		case models.TaskImportFieldKey:
+			value = task.Key
+		case models.TaskImportFieldParent:
+			value = task.ParentKey
+		case models.TaskImportFieldTitle:
+			value = task.Title
+		case models.TaskImportFieldDescription:
+			value = task.Description
+		case models.TaskImportFieldStatus:
+			value = task.Status
+		case models.TaskImportFieldPriority:
+			value = task.Priority
+		case models.TaskImportFieldDueDate:
+			if task.DueDate != nil {
+				value = task.DueDate.Format(time.RFC3339)
+			}
+		case models.TaskImportFieldAssignee:
+			value = task.AssigneeEmail
+		case models.TaskImportFieldCreator:
+			value = task.CreatorEmail
+		case models.TaskImportFieldEstimatedHours:
+			if task.EstimatedHours != nil {
+				value = strconv.FormatFloat(*task.EstimatedHours, 'f', -1, 64)
+			}
+		case models.TaskImportFieldTags:
+			value = strings.Join(task.Tags, ", ")
+		default:
+			return fmt.Errorf("unknown field %q", field)
+		}
+		row = append(row, value)
+	}
+
+	return c.w.Write(row)
+}
+
+// Close finishes the export
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,194 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportSize is the maximum size of an import file
+const maxImportSize = 10 * 1024 * 1024
+
+// Handlers provides HTTP handlers for task import and export
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Import handles importing tasks from a file into a project
+func (h *Handlers) Import(c echo.Context) error {
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Get file from form
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid file")
+	}
+
+	// Check file size (limit to 10MB)
+	if file.Size > maxImportSize {
+		return echo.NewHTTPError(http.StatusBadRequest, "File size exceeds the limit (10MB)")
+	}
+
+	// Parse import options
+	opts := models.TaskImportOptions{
+		Format: models.TaskTransferFormat(c.FormValue("format")),
+	}
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid mapping")
+		}
+	}
+	if dryRun := c.FormValue("dry_run"); dryRun != "" {
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid dry_run parameter")
+		}
+	}
+
+	// Validate options
+	if err := h.validate.Struct(opts); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid file")
+	}
+	defer src.Close()
+
+	// Import tasks
+	result, err := h.service.Import(c.Request().Context(), orgID, id, src, opts, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		if errors.Is(err, repository.ErrProjectArchived) {
+			return echo.NewHTTPError(http.StatusConflict, "Project is archived")
+		}
+		if errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrInvalidImport) || errors.Is(err, ErrTooManyRows) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to import tasks")
+	}
+
+	switch {
+	case result.DryRun:
+		return c.JSON(http.StatusOK, result)
+	case len(result.Errors) > 0:
+		// Rows with errors block the import
+		return c.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		return c.JSON(http.StatusCreated, result)
+	}
+}
+
+// Export handles exporting the tasks of a project
+func (h *Handlers) Export(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse format parameter
+	format := models.TaskTransferFormatJSON
+	contentType := echo.MIMEApplicationJSON
+	switch c.QueryParam("format") {
+	case "", string(models.TaskTransferFormatJSON):
+	case string(models.TaskTransferFormatCSV):
+		format = models.TaskTransferFormatCSV
+		contentType = "text/csv"
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid format parameter")
+	}
+
+	// The response starts with the first task written, so that errors before
+	// then can still be reported
+	w := &exportWriter{
+		c:           c,
+		contentType: contentType,
+		filename:    fmt.Sprintf("tasks-%s.%s", id, format),
+	}
+	err = h.service.Export(c.Request().Context(), orgID, id, format, w)
+	if err != nil {
+		if c.Response().Committed {
+			return err
+		}
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to export tasks")
+	}
+
+	return nil
+}
+
+// exportWriter streams an export to the response, writing the headers on the
+// first write
+type exportWriter struct {
+	c           echo.Context
+	contentType string
+	filename    string
+}
+
+// Write writes export data to the response
+func (w *exportWriter) Write(p []byte) (int, error) {
+	response := w.c.Response()
+	if !response.Committed {
+		response.Header().Set(echo.HeaderContentType, w.contentType)
+		response.Header().Set(echo.HeaderContentDisposition, "attachment; filename="+w.filename)
+		response.WriteHeader(http.StatusOK)
+	}
+	return response.Write(p)
+}
+
+// RegisterRoutes registers the import and export routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	// Routes that require task:read permission
+	g.GET("/projects/:id/export", h.Export, rbacMiddleware.RequirePermission(middleware.PermissionTaskRead))
+
+	// Routes that require task:write permission
+	g.POST("/projects/:id/import", h.Import, rbacMiddleware.RequirePermission(middleware.PermissionTaskWrite))
+}
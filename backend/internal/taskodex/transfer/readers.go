@@ -0,0 +1,515 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+)
+
+// dateLayouts are the date formats accepted in import files, including the
+// ones Jira exports use
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM",
+	"2/Jan/06 3:04 PM",
+	"02/Jan/06",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// readRecords reads the tasks of an import file
+func readRecords(r io.Reader, opts models.TaskImportOptions) ([]models.TaskImportRecord, error) {
+	var records []models.TaskImportRecord
+	var err error
+
+	switch opts.Format {
+	case models.TaskTransferFormatCSV:
+		records, err = readCSV(r, opts.Mapping)
+	case models.TaskTransferFormatJSON:
+		records, err = readJSON(r)
+	case models.TaskTransferFormatJiraCSV:
+		records, err = readJiraCSV(r)
+	case models.TaskTransferFormatJiraXML:
+		records, err = readJiraXML(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file contains no tasks", ErrInvalidImport)
+	}
+	if len(records) > models.MaxImportRows {
+		return nil, ErrTooManyRows
+	}
+	return records, nil
+}
+
+// readCSV reads a CSV file whose columns are mapped to task import fields.
+// Without a mapping, columns named after a field are used.
+func readCSV(r io.Reader, mapping map[string]string) ([]models.TaskImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file contains no tasks", ErrInvalidImport)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	// fields holds the field each column is mapped to
+	fields := make([]string, len(header))
+	for column, target := range mapping {
+		if !isImportField(target) {
+			return nil, fmt.Errorf("%w: column %q is mapped to unknown field %q", ErrInvalidImport, column, target)
+		}
+	}
+	mapped := make(map[string]bool)
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+
+		field := strings.ToLower(column)
+		if mapping != nil {
+			field = mapping[column]
+		}
+		if isImportField(field) {
+			fields[i] = field
+			mapped[field] = true
+		}
+	}
+	if !mapped[models.TaskImportFieldTitle] {
+		return nil, fmt.Errorf("%w: no column is mapped to %q", ErrInvalidImport, models.TaskImportFieldTitle)
+	}
+
+	records := []models.TaskImportRecord{}
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if isBlank(values) {
+			continue
+		}
+		if len(records) == models.MaxImportRows {
+			return nil, ErrTooManyRows
+		}
+
+		record := models.TaskImportRecord{Row: row}
+		for i, value := range values {
+			if i < len(fields) && fields[i] != "" {
+				setField(&record, fields[i], strings.TrimSpace(value))
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// setField sets a field of a record from a CSV value, recording values that
+// cannot be read
+func setField(record *models.TaskImportRecord, field, value string) {
+	if value == "" {
+		return
+	}
+
+	switch field {
+	case models.TaskImportFieldKey:
+		record.Key = value
+	case models.TaskImportFieldParent:
+		record.ParentKey = value
+	case models.TaskImportFieldTitle:
+		record.Title = value
+	case models.TaskImportFieldDescription:
+		record.Description = value
+	case models.TaskImportFieldStatus:
+		record.Status = value
+	case models.TaskImportFieldPriority:
+		record.Priority = value
+	case models.TaskImportFieldAssignee:
+		record.AssigneeEmail = value
+	case models.TaskImportFieldCreator:
+		record.CreatorEmail = value
+	case models.TaskImportFieldDueDate:
+		dueDate, err := parseDate(value)
+		if err != nil {
+			addError(record, field, "invalid date %q", value)
+			return
+		}
+		record.DueDate = &dueDate
+	case models.TaskImportFieldEstimatedHours:
+		hours, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			addError(record, field, "invalid number %q", value)
+			return
+		}
+		record.EstimatedHours = &hours
+	case models.TaskImportFieldTags:
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+	}
+}
+
+// readJSON reads a Taskodex JSON export
+func readJSON(r io.Reader) ([]models.TaskImportRecord, error) {
+	var document models.TaskTransferDocument
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if document.Format != models.TaskTransferDocumentFormat {
+		return nil, fmt.Errorf("%w: not a Taskodex export", ErrInvalidImport)
+	}
+	if document.Version > models.TaskTransferDocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported export version %d", ErrInvalidImport, document.Version)
+	}
+	if len(document.Tasks) > models.MaxImportRows {
+		return nil, ErrTooManyRows
+	}
+
+	records := make([]models.TaskImportRecord, 0, len(document.Tasks))
+	for i, task := range document.Tasks {
+		records = append(records, models.TaskImportRecord{TaskTransferTask: task, Row: i + 1})
+	}
+	return records, nil
+}
+
+// readJiraCSV reads a Jira issue CSV export. Jira repeats columns such as
+// Labels, Comment and Log Work for each of their values.
+func readJiraCSV(r io.Reader) ([]models.TaskImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file contains no tasks", ErrInvalidImport)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	columns := make([]string, len(header))
+	hasSummary := false
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		columns[i] = column
+		hasSummary = hasSummary || column == "summary"
+	}
+	if !hasSummary {
+		return nil, fmt.Errorf("%w: not a Jira export, the Summary column is missing", ErrInvalidImport)
+	}
+
+	// Parents may be referenced by issue ID, which is resolved to the issue
+	// key once every row is read
+	keys := make(map[string]string)
+
+	records := []models.TaskImportRecord{}
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if isBlank(values) {
+			continue
+		}
+		if len(records) == models.MaxImportRows {
+			return nil, ErrTooManyRows
+		}
+
+		record := models.TaskImportRecord{Row: row}
+		issueID := ""
+		for i, value := range values {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			switch columns[i] {
+			case "issue key":
+				record.Key = value
+			case "issue id":
+				issueID = value
+			case "parent", "parent id", "parent key":
+				record.ParentKey = value
+			case "summary":
+				record.Title = value
+			case "description":
+				record.Description = value
+			case "status":
+				record.Status = value
+			case "priority":
+				record.Priority = value
+			case "assignee":
+				record.AssigneeEmail = value
+			case "reporter":
+				record.CreatorEmail = value
+			case "due date":
+				setField(&record, models.TaskImportFieldDueDate, value)
+			case "labels":
+				record.Tags = append(record.Tags, value)
+			case "original estimate":
+				seconds, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					addError(&record, models.TaskImportFieldEstimatedHours, "invalid estimate %q", value)
+					continue
+				}
+				hours := seconds / 3600
+				record.EstimatedHours = &hours
+			case "comment":
+				readJiraComment(&record, value)
+			case "log work":
+				readJiraWorklog(&record, value)
+			}
+		}
+
+		if issueID != "" {
+			if record.Key == "" {
+				record.Key = issueID
+			}
+			keys[issueID] = record.Key
+		}
+		records = append(records, record)
+	}
+
+	for i := range records {
+		if key, ok := keys[records[i].ParentKey]; ok {
+			records[i].ParentKey = key
+		}
+	}
+
+	return records, nil
+}
+
+// readJiraComment reads a Jira CSV comment, formatted as "date;author;body"
+func readJiraComment(record *models.TaskImportRecord, value string) {
+	parts := strings.SplitN(value, ";", 3)
+	if len(parts) < 3 {
+		record.Comments = append(record.Comments, models.TaskTransferComment{Content: value})
+		return
+	}
+
+	comment := models.TaskTransferComment{
+		AuthorEmail: strings.TrimSpace(parts[1]),
+		Content:     strings.TrimSpace(parts[2]),
+	}
+	if createdAt, err := parseDate(strings.TrimSpace(parts[0])); err == nil {
+		comment.CreatedAt = &createdAt
+	}
+	record.Comments = append(record.Comments, comment)
+}
+
+// readJiraWorklog reads a Jira CSV work log, formatted as
+// "description;started;author;seconds". The description may contain
+// semicolons.
+func readJiraWorklog(record *models.TaskImportRecord, value string) {
+	parts := strings.Split(value, ";")
+	if len(parts) < 4 {
+		addError(record, "time_entries", "invalid work log %q", value)
+		return
+	}
+
+	n := len(parts)
+	startTime, err := parseDate(strings.TrimSpace(parts[n-3]))
+	if err != nil {
+		addError(record, "time_entries", "invalid work log date %q", parts[n-3])
+		return
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(parts[n-1]))
+	if err != nil {
+		addError(record, "time_entries", "invalid work log duration %q", parts[n-1])
+		return
+	}
+
+	minutes := seconds / 60
+	record.TimeEntries = append(record.TimeEntries, models.TaskTransferTimeEntry{
+		UserEmail:       strings.TrimSpace(parts[n-2]),
+		StartTime:       startTime,
+		DurationMinutes: &minutes,
+		Description:     strings.TrimSpace(strings.Join(parts[:n-3], ";")),
+	})
+}
+
+// jiraRSS is a Jira issue XML export
+type jiraRSS struct {
+	Items []jiraItem `xml:"channel>item"`
+}
+
+// jiraItem is an issue of a Jira XML export
+type jiraItem struct {
+	Key         string        `xml:"key"`
+	Parent      string        `xml:"parent"`
+	Summary     string        `xml:"summary"`
+	Description string        `xml:"description"`
+	Status      string        `xml:"status"`
+	Priority    string        `xml:"priority"`
+	Assignee    jiraUser      `xml:"assignee"`
+	Reporter    jiraUser      `xml:"reporter"`
+	Due         string        `xml:"due"`
+	Labels      []string      `xml:"labels>label"`
+	Comments    []jiraComment `xml:"comments>comment"`
+	Estimate    struct {
+		Seconds string `xml:"seconds,attr"`
+	} `xml:"timeoriginalestimate"`
+}
+
+// jiraUser is a user of a Jira XML export. Depending on the Jira version, the
+// user is identified by username or account ID.
+type jiraUser struct {
+	Name      string `xml:",chardata"`
+	Username  string `xml:"username,attr"`
+	AccountID string `xml:"accountid,attr"`
+}
+
+// email returns the email of a user, or its name if the export has no email
+func (u jiraUser) email() string {
+	for _, value := range []string{u.Username, u.Name} {
+		if strings.Contains(value, "@") {
+			return strings.TrimSpace(value)
+		}
+	}
+	if u.Name == "Unassigned" {
+		return ""
+	}
+	return strings.TrimSpace(u.Name)
+}
+
+// jiraComment is a comment of a Jira XML export
+type jiraComment struct {
+	Author  string `xml:"author,attr"`
+	Created string `xml:"created,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// htmlTags matches the tags of the HTML Jira XML exports use for text
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// readJiraXML reads a Jira issue XML (RSS) export
+func readJiraXML(r io.Reader) ([]models.TaskImportRecord, error) {
+	var rss jiraRSS
+	if err := xml.NewDecoder(r).Decode(&rss); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if len(rss.Items) > models.MaxImportRows {
+		return nil, ErrTooManyRows
+	}
+
+	records := make([]models.TaskImportRecord, 0, len(rss.Items))
+	for i, item := range rss.Items {
+		record := models.TaskImportRecord{Row: i + 1}
+		record.Key = strings.TrimSpace(item.Key)
+		record.ParentKey = strings.TrimSpace(item.Parent)
+		record.Title = strings.TrimSpace(item.Summary)
+		record.Description = htmlText(item.Description)
+		record.Status = strings.TrimSpace(item.Status)
+		record.Priority = strings.TrimSpace(item.Priority)
+		record.AssigneeEmail = item.Assignee.email()
+		record.CreatorEmail = item.Reporter.email()
+		setField(&record, models.TaskImportFieldDueDate, strings.TrimSpace(item.Due))
+
+		for _, label := range item.Labels {
+			if label = strings.TrimSpace(label); label != "" {
+				record.Tags = append(record.Tags, label)
+			}
+		}
+
+		if item.Estimate.Seconds != "" {
+			seconds, err := strconv.ParseFloat(item.Estimate.Seconds, 64)
+			if err != nil {
+				addError(&record, models.TaskImportFieldEstimatedHours, "invalid estimate %q", item.Estimate.Seconds)
+			} else {
+				hours := seconds / 3600
+				record.EstimatedHours = &hours
+			}
+		}
+
+		for _, item := range item.Comments {
+			comment := models.TaskTransferComment{
+				AuthorEmail: strings.TrimSpace(item.Author),
+				Content:     htmlText(item.Body),
+			}
+			if createdAt, err := parseDate(strings.TrimSpace(item.Created)); err == nil {
+				comment.CreatedAt = &createdAt
+			}
+			record.Comments = append(record.Comments, comment)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// htmlText returns the text of an HTML fragment
+func htmlText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTags.ReplaceAllString(s, "")))
+}
+
+// parseDate parses a date in one of the accepted layouts
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// addError records a value of a row that could not be read
+func addError(record *models.TaskImportRecord, field, format string, args ...interface{}) {
+	record.Errors = append(record.Errors, models.TaskImportError{
+		Row:     record.Row,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// isImportField reports whether field is a task import field
+func isImportField(field string) bool {
+	for _, f := range models.TaskImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlank reports whether every value of a CSV row is empty
+func isBlank(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,570 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrInvalidImport     = errors.New("invalid import file")
+	ErrTooManyRows       = fmt.Errorf("import files can contain at most %d tasks", models.MaxImportRows)
+)
+
+// errRowsRejected rolls back an import whose rows were rejected while they
+// were written
+var errRowsRejected = errors.New("import rows rejected")
+
+// exportPageSize is the number of tasks read at once while exporting
+const exportPageSize = 100
+
+// statusAliases maps common status names of other tools to task statuses
+var statusAliases = map[string]models.TaskStatus{
+	"open":        models.TaskStatusTodo,
+	"to_do":       models.TaskStatusTodo,
+	"in_review":   models.TaskStatusReview,
+	"code_review": models.TaskStatusReview,
+	"resolved":    models.TaskStatusDone,
+	"closed":      models.TaskStatusDone,
+	"canceled":    models.TaskStatusCancelled,
+	"won't_do":    models.TaskStatusCancelled,
+}
+
+// priorityAliases maps common priority names of other tools to task priorities
+var priorityAliases = map[string]models.TaskPriority{
+	"highest": models.TaskPriorityCritical,
+	"blocker": models.TaskPriorityCritical,
+	"urgent":  models.TaskPriorityCritical,
+	"major":   models.TaskPriorityHigh,
+	"normal":  models.TaskPriorityMedium,
+	"minor":   models.TaskPriorityLow,
+	"lowest":  models.TaskPriorityLow,
+	"trivial": models.TaskPriorityLow,
+}
+
+// Service provides task import and export
+type Service interface {
+	// Import imports the tasks of a file into a project of an organization,
+	// along with their comments and time entries. Nothing is imported if any
+	// row is invalid, or if opts.DryRun is set.
+	Import(ctx context.Context, organizationID, projectID uuid.UUID, r io.Reader, opts models.TaskImportOptions, userID uuid.UUID) (*models.TaskImportResult, error)
+
+	// Export writes the tasks of a project of an organization to w as they
+	// are read. JSON exports include comments and time entries.
+	Export(ctx context.Context, organizationID, projectID uuid.UUID, format models.TaskTransferFormat, w io.Writer) error
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	taskRepo        repository.TaskRepository
+	taskHistoryRepo repository.TaskHistoryRepository
+	commentRepo     repository.CommentRepository
+	timeEntryRepo   repository.TimeEntryRepository
+	projectRepo     repository.ProjectRepository
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+	timeEntrySvc    timeentry.Service
+	workflowSvc     workflow.Service
+	customFieldSvc  customfield.Service
+	txManager       repository.TxManager
+}
+
+// NewService creates a new import and export service
+func NewService(
+	taskRepo repository.TaskRepository,
+	taskHistoryRepo repository.TaskHistoryRepository,
+	commentRepo repository.CommentRepository,
+	timeEntryRepo repository.TimeEntryRepository,
+	projectRepo repository.ProjectRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	timeEntrySvc timeentry.Service,
+	workflowSvc workflow.Service,
+	customFieldSvc customfield.Service,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		taskRepo:        taskRepo,
+		taskHistoryRepo: taskHistoryRepo,
+		commentRepo:     commentRepo,
+		timeEntryRepo:   timeEntryRepo,
+		projectRepo:     projectRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		timeEntrySvc:    timeEntrySvc,
+		workflowSvc:     workflowSvc,
+		customFieldSvc:  customFieldSvc,
+		txManager:       txManager,
+	}
+}
+
+// importTask is a task to import, with its comments and time entries
+type importTask struct {
+	row         int
+	parentKey   string
+	task        *models.Task
+	comments    []models.Comment
+	timeEntries []importTimeEntry
+}
+
+// importTimeEntry is a time entry to import, with the user who logged it
+type importTimeEntry struct {
+	userID uuid.UUID
+	req    models.TimeEntryRequest
+}
+
+// importPlan holds the state of an import while its rows are checked
+type importPlan struct {
+	result         *models.TaskImportResult
+	userID         uuid.UUID
+	organizationID uuid.UUID
+	users          map[string]*uuid.UUID
+	tasks          []*importTask
+	byKey          map[string]*importTask
+	ordered        []*importTask
+}
+
+// Import imports the tasks of a file into a project
+func (s *serviceImpl) Import(ctx context.Context, organizationID, projectID uuid.UUID, r io.Reader, opts models.TaskImportOptions, userID uuid.UUID) (*models.TaskImportResult, error) {
+	// Check if project exists in the organization and is not archived
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.OrganizationID != organizationID {
+		return nil, repository.ErrProjectNotFound
+	}
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	records, err := readRecords(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.workflowSvc.ForTask(ctx, &models.Task{ProjectID: &projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &importPlan{
+		result: &models.TaskImportResult{
+			Format:   opts.Format,
+			DryRun:   opts.DryRun,
+			Rows:     len(records),
+			Errors:   []models.TaskImportError{},
+			Warnings: []models.TaskImportError{},
+		},
+		userID:         userID,
+		organizationID: project.OrganizationID,
+		users:          make(map[string]*uuid.UUID),
+		byKey:          make(map[string]*importTask),
+	}
+	for i := range records {
+		if err := s.planTask(ctx, plan, &records[i], projectID, workflow); err != nil {
+			return nil, err
+		}
+	}
+	plan.order()
+
+	result := plan.result
+	if len(result.Errors) > 0 || opts.DryRun {
+		return result, nil
+	}
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// The workflow may have been replaced since the rows were checked.
+		// From here it stays in place until the tasks are saved.
+		workflow, err := s.workflowSvc.ForTask(ctx, &models.Task{ProjectID: &projectID})
+		if err != nil {
+			return err
+		}
+		for _, item := range plan.ordered {
+			if _, ok := workflow.State(item.task.Status); !ok {
+				result.Errors = append(result.Errors, models.TaskImportError{
+					Row:     item.row,
+					Field:   models.TaskImportFieldStatus,
+					Message: fmt.Sprintf("unknown status %q", item.task.Status),
+				})
+			}
+		}
+		if len(result.Errors) > 0 {
+			return errRowsRejected
+		}
+
+		for _, item := range plan.ordered {
+			if item.parentKey != "" {
+				item.task.ParentID = &plan.byKey[item.parentKey].task.ID
+			}
+			if err := s.taskRepo.Create(ctx, item.task); err != nil {
+				return err
+			}
+			if err := s.taskHistoryRepo.Create(ctx, models.NewTaskCreatedHistory(item.task.ID, userID)); err != nil {
+				return err
+			}
+
+			for i := range item.comments {
+				if err := s.commentRepo.Create(ctx, &item.comments[i]); err != nil {
+					return err
+				}
+			}
+			// Time entries follow the rules of entries logged in the
+			// organization; those they break are reported on their row
+			for _, entry := range item.timeEntries {
+				_, err := s.timeEntrySvc.Create(ctx, entry.req, entry.userID)
+				if errors.Is(err, repository.ErrTimesheetLocked) || errors.Is(err, timeentry.ErrTimeEntryOverlap) ||
+					errors.Is(err, timeentry.ErrTimeEntryTooLong) {
+					result.Errors = append(result.Errors, models.TaskImportError{
+						Row:     item.row,
+						Field:   "time_entries",
+						Message: err.Error(),
+					})
+					continue
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			result.Imported++
+			result.Comments += len(item.comments)
+			result.TimeEntries += len(item.timeEntries)
+		}
+		if len(result.Errors) > 0 {
+			return errRowsRejected
+		}
+		return nil
+	})
+	if errors.Is(err, errRowsRejected) {
+		result.Imported, result.Comments, result.TimeEntries = 0, 0, 0
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// planTask checks a record against the project and adds the task to import
+// to the plan. Problems with the record are added to the result; only
+// failures to check it are returned.
+func (s *serviceImpl) planTask(ctx context.Context, plan *importPlan, record *models.TaskImportRecord, projectID uuid.UUID, workflow *models.Workflow) error {
+	result := plan.result
+	result.Errors = append(result.Errors, record.Errors...)
+	fail := func(field, format string, args ...interface{}) {
+		result.Errors = append(result.Errors, models.TaskImportError{
+			Row:     record.Row,
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	title := strings.TrimSpace(record.Title)
+	if len(title) < 3 || len(title) > 255 {
+		fail(models.TaskImportFieldTitle, "title must be between 3 and 255 characters")
+	}
+	if len(record.Description) > 5000 {
+		fail(models.TaskImportFieldDescription, "description must be at most 5000 characters")
+	}
+
+	status, ok := resolveStatus(workflow, record.Status)
+	if !ok {
+		fail(models.TaskImportFieldStatus, "unknown status %q", record.Status)
+	}
+	priority, ok := resolvePriority(record.Priority)
+	if !ok {
+		fail(models.TaskImportFieldPriority, "unknown priority %q", record.Priority)
+	}
+	if record.EstimatedHours != nil && *record.EstimatedHours < 0 {
+		fail(models.TaskImportFieldEstimatedHours, "estimated hours cannot be negative")
+	}
+
+	tags := []string{}
+	for _, tag := range record.Tags {
+		if len(tag) > 50 {
+			fail(models.TaskImportFieldTags, "tag %q is longer than 50 characters", tag)
+			continue
+		}
+		if !models.HasTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	key := strings.TrimSpace(record.Key)
+	if key != "" {
+		if _, ok := plan.byKey[key]; ok {
+			fail(models.TaskImportFieldKey, "duplicate key %q", key)
+			key = ""
+		}
+	}
+
+	// Users missing from this installation or from the organization are left
+	// out; the importing user stands in for creators and authors
+	assignee, err := s.lookupUser(ctx, plan, record.Row, models.TaskImportFieldAssignee, record.AssigneeEmail)
+	if err != nil {
+		return err
+	}
+	creator, err := s.userOrImporter(ctx, plan, record.Row, models.TaskImportFieldCreator, record.CreatorEmail)
+	if err != nil {
+		return err
+	}
+
+	task := models.NewTask(models.TaskRequest{
+		ProjectID:      &projectID,
+		Title:          title,
+		Description:    record.Description,
+		Status:         status,
+		Priority:       priority,
+		DueDate:        record.DueDate,
+		AssignedTo:     assignee,
+		EstimatedHours: record.EstimatedHours,
+		Tags:           tags,
+	}, creator)
+	if record.CreatedAt != nil {
+		task.CreatedAt = *record.CreatedAt
+	}
+
+	// Files carry no custom field values, so the project must not require any
+	customFields, err := s.customFieldSvc.Validate(ctx, models.CustomFieldEntityTask, plan.organizationID, &projectID, nil)
+	if errors.Is(err, models.ErrInvalidCustomField) {
+		fail("custom_fields", "%s", err.Error())
+	} else if err != nil {
+		return err
+	}
+	task.CustomFields = customFields
+
+	item := &importTask{
+		row:       record.Row,
+		parentKey: strings.TrimSpace(record.ParentKey),
+		task:      task,
+	}
+
+	for _, c := range record.Comments {
+		content := strings.TrimSpace(c.Content)
+		if content == "" {
+			continue
+		}
+		if len(content) > 5000 {
+			fail("comments", "comment must be at most 5000 characters")
+			continue
+		}
+
+		author, err := s.userOrImporter(ctx, plan, record.Row, "comments", c.AuthorEmail)
+		if err != nil {
+			return err
+		}
+		comment := models.NewComment(models.CommentRequest{TaskID: task.ID, Content: content}, author)
+		if c.CreatedAt != nil {
+			comment.CreatedAt = *c.CreatedAt
+			comment.UpdatedAt = *c.CreatedAt
+		}
+		item.comments = append(item.comments, *comment)
+	}
+
+	for _, e := range record.TimeEntries {
+		if e.StartTime.IsZero() {
+			fail("time_entries", "time entry has no start time")
+			continue
+		}
+
+		// Imported time entries are finished; running timers are not imported
+		endTime, duration := e.EndTime, e.DurationMinutes
+		switch {
+		case endTime == nil && duration == nil:
+			fail("time_entries", "time entry has neither an end time nor a duration")
+			continue
+		case endTime == nil:
+			end := e.StartTime.Add(time.Duration(*duration) * time.Minute)
+			endTime = &end
+		case duration == nil:
+			minutes := int(endTime.Sub(e.StartTime).Minutes())
+			duration = &minutes
+		}
+		if *duration < 0 {
+			fail("time_entries", "time entry ends before it starts")
+			continue
+		}
+
+		user, err := s.userOrImporter(ctx, plan, record.Row, "time_entries", e.UserEmail)
+		if err != nil {
+			return err
+		}
+		billable := e.Billable == nil || *e.Billable
+		item.timeEntries = append(item.timeEntries, importTimeEntry{
+			userID: user,
+			req: models.TimeEntryRequest{
+				TaskID:          task.ID,
+				StartTime:       e.StartTime,
+				EndTime:         endTime,
+				DurationMinutes: duration,
+				Description:     e.Description,
+				Billable:        &billable,
+			},
+		})
+	}
+
+	plan.tasks = append(plan.tasks, item)
+	if key != "" {
+		plan.byKey[key] = item
+	}
+	return nil
+}
+
+// lookupUser returns the ID of the member of the organization with an email,
+// or nil if there is no such member. Missing users are warned about once.
+func (s *serviceImpl) lookupUser(ctx context.Context, plan *importPlan, row int, field, email string) (*uuid.UUID, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, nil
+	}
+
+	if userID, ok := plan.users[email]; ok {
+		return userID, nil
+	}
+
+	message := fmt.Sprintf("no user with email %q", email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		// Members hold a role in the organization
+		roles, err := s.roleRepo.GetUserRoles(ctx, user.ID, plan.organizationID)
+		if err != nil {
+			return nil, err
+		}
+		if len(roles) > 0 {
+			plan.users[email] = &user.ID
+			return &user.ID, nil
+		}
+		message = fmt.Sprintf("user with email %q is not a member of the organization", email)
+	}
+
+	plan.users[email] = nil
+	plan.result.Warnings = append(plan.result.Warnings, models.TaskImportError{
+		Row:     row,
+		Field:   field,
+		Message: message,
+	})
+	return nil, nil
+}
+
+// userOrImporter returns the ID of the member with an email, or the ID of the
+// importing user if there is no such member
+func (s *serviceImpl) userOrImporter(ctx context.Context, plan *importPlan, row int, field, email string) (uuid.UUID, error) {
+	userID, err := s.lookupUser(ctx, plan, row, field, email)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if userID == nil {
+		return plan.userID, nil
+	}
+	return *userID, nil
+}
+
+// order orders the tasks of the plan so that parents are created before
+// their subtasks. Parents missing from the file are dropped and cycles are
+// reported.
+func (p *importPlan) order() {
+	for _, item := range p.tasks {
+		if item.parentKey == "" {
+			continue
+		}
+		if _, ok := p.byKey[item.parentKey]; !ok {
+			p.result.Warnings = append(p.result.Warnings, models.TaskImportError{
+				Row:     item.row,
+				Field:   models.TaskImportFieldParent,
+				Message: fmt.Sprintf("parent %q is not in the file; the task is imported without a parent", item.parentKey),
+			})
+			item.parentKey = ""
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[*importTask]int, len(p.tasks))
+
+	var visit func(item *importTask) bool
+	visit = func(item *importTask) bool {
+		switch state[item] {
+		case visiting:
+			return false
+		case visited:
+			return true
+		}
+
+		state[item] = visiting
+		if item.parentKey != "" && !visit(p.byKey[item.parentKey]) {
+			return false
+		}
+		state[item] = visited
+		p.ordered = append(p.ordered, item)
+		return true
+	}
+
+	for _, item := range p.tasks {
+		if !visit(item) {
+			p.result.Errors = append(p.result.Errors, models.TaskImportError{
+				Row:     item.row,
+				Field:   models.TaskImportFieldParent,
+				Message: "the task is its own ancestor",
+			})
+			return
+		}
+	}
+}
+
+// resolveStatus returns the workflow state a status of an import file refers
+// to, by key, by name or by a common alias. Tasks without a status start in
+// the initial state.
+func resolveStatus(workflow *models.Workflow, value string) (models.TaskStatus, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return workflow.InitialState, true
+	}
+
+	key := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(value))
+	for _, state := range workflow.States {
+		if string(state.Key) == value || string(state.Key) == key || strings.EqualFold(state.Name, value) {
+			return state.Key, true
+		}
+	}
+
+	if status, ok := statusAliases[key]; ok {
+		if _, ok := workflow.State(status); ok {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// resolvePriority returns the priority a priority of an import file refers
+// to. Tasks without a priority get medium priority.
+func resolvePriority(value string) (models.TaskPriority, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch priority := models.TaskPriority(value); priority {
+	case "":
+		return models.TaskPriorityMedium, true
+	case models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh, models.TaskPriorityCritical:
+		return priority, true
+	}
+
+	priority, ok := priorityAliases[value]
+	return priority, ok
+}
@@ -0,0 +1,385 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/notification"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/timeentry"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/transfer"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfer(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	roleRepo := repository.NewPostgresRoleRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	timeEntryRepo := repository.NewPostgresTimeEntryRepository(tdb.DB)
+	policyRepo := repository.NewPostgresTimeEntryPolicyRepository(tdb.DB)
+
+	// Make the test user a member of the organization
+	role := &models.Role{
+		ID:        uuid.New(),
+		Name:      prefix + " member",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, roleRepo.CreateRole(ctx, role))
+	require.NoError(t, roleRepo.AssignRoleToUser(ctx, &models.UserRole{
+		UserID:         testUser.ID,
+		RoleID:         role.ID,
+		OrganizationID: testOrg.ID,
+	}))
+
+	// Create service and handlers
+	customFieldService := customfield.NewService(repository.NewPostgresCustomFieldRepository(tdb.DB), projectRepo, userRepo, roleRepo)
+	transferService := transfer.NewService(
+		taskRepo,
+		repository.NewPostgresTaskHistoryRepository(tdb.DB),
+		repository.NewPostgresCommentRepository(tdb.DB),
+		timeEntryRepo,
+		projectRepo,
+		userRepo,
+		roleRepo,
+		timeentry.NewService(
+			timeEntryRepo,
+			taskRepo,
+			userRepo,
+			repository.NewPostgresTimesheetRepository(tdb.DB),
+			policyRepo,
+			notification.NewService(repository.NewPostgresNotificationRepository(tdb.DB), userRepo),
+			repository.NewTxManager(tdb.DB),
+		),
+		workflow.NewService(repository.NewPostgresWorkflowRepository(tdb.DB), projectRepo, taskRepo, repository.NewTxManager(tdb.DB)),
+		customFieldService,
+		repository.NewTxManager(tdb.DB),
+	)
+	transferHandlers := transfer.NewHandlers(transferService)
+	e := echo.New()
+
+	listTasks := func(projectID *models.Project) []models.Task {
+		tasks, _, err := taskRepo.List(ctx, models.TaskListParams{
+			ProjectID: &projectID.ID,
+			SortBy:    "created_at",
+			SortOrder: "asc",
+			Page:      1,
+			PageSize:  100,
+		})
+		require.NoError(t, err)
+		return tasks
+	}
+
+	csvFile := "Summary,Ref,Parent Ref,State,Owner,Due,Labels\n" +
+		"Plan the release,R-1,,In Progress," + testUser.Email + ",2030-01-15,\"release, planning\"\n" +
+		"Write release notes,R-2,R-1,To Do,nobody@example.com,,release\n"
+	mapping := map[string]string{
+		"Summary":    models.TaskImportFieldTitle,
+		"Ref":        models.TaskImportFieldKey,
+		"Parent Ref": models.TaskImportFieldParent,
+		"State":      models.TaskImportFieldStatus,
+		"Owner":      models.TaskImportFieldAssignee,
+		"Due":        models.TaskImportFieldDueDate,
+		"Labels":     models.TaskImportFieldTags,
+	}
+
+	t.Run("DryRunReportsRowErrors", func(t *testing.T) {
+		file := csvFile + "No,R-3,R-9,Someday,,not a date,\n"
+		result, err := transferService.Import(ctx, testOrg.ID, testProject.ID, strings.NewReader(file), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+			DryRun:  true,
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Rows)
+		assert.Equal(t, 0, result.Imported)
+		fields := []string{}
+		for _, e := range result.Errors {
+			assert.Equal(t, 4, e.Row)
+			fields = append(fields, e.Field)
+		}
+		assert.ElementsMatch(t, []string{"due_date", "title", "status"}, fields)
+		assert.Empty(t, listTasks(testProject))
+	})
+
+	t.Run("ImportCSV", func(t *testing.T) {
+		result, err := transferService.Import(ctx, testOrg.ID, testProject.ID, strings.NewReader(csvFile), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, 2, result.Imported)
+
+		// Unknown users are reported, and their tasks left unassigned
+		if assert.Len(t, result.Warnings, 1) {
+			assert.Equal(t, 3, result.Warnings[0].Row)
+			assert.Equal(t, models.TaskImportFieldAssignee, result.Warnings[0].Field)
+		}
+
+		tasks := listTasks(testProject)
+		require.Len(t, tasks, 2)
+		parent, child := tasks[0], tasks[1]
+		if parent.ParentID != nil {
+			parent, child = child, parent
+		}
+		assert.Equal(t, "Plan the release", parent.Title)
+		assert.Equal(t, models.TaskStatusInProgress, parent.Status)
+		assert.Equal(t, models.TaskPriorityMedium, parent.Priority)
+		assert.Equal(t, &testUser.ID, parent.AssignedTo)
+		assert.ElementsMatch(t, []string{"release", "planning"}, parent.Tags)
+		require.NotNil(t, parent.DueDate)
+		assert.Equal(t, "2030-01-15", parent.DueDate.Format("2006-01-02"))
+		assert.Equal(t, &parent.ID, child.ParentID)
+		assert.Equal(t, models.TaskStatusTodo, child.Status)
+		assert.Nil(t, child.AssignedTo)
+	})
+
+	t.Run("NonMembersAreNotMatched", func(t *testing.T) {
+		outsider := tdb.CreateTestUser(t, prefix+"outsider")
+		outsiderProject := tdb.CreateTestProject(t, prefix+"outsider", testOrg.ID, testUser.ID)
+		file := "Summary,Owner\nAudit access," + outsider.Email + "\n"
+
+		result, err := transferService.Import(ctx, testOrg.ID, outsiderProject.ID, strings.NewReader(file), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Imported)
+		if assert.Len(t, result.Warnings, 1) {
+			assert.Equal(t, models.TaskImportFieldAssignee, result.Warnings[0].Field)
+			assert.Contains(t, result.Warnings[0].Message, "not a member")
+		}
+
+		tasks := listTasks(outsiderProject)
+		require.Len(t, tasks, 1)
+		assert.Nil(t, tasks[0].AssignedTo)
+	})
+
+	t.Run("ImportJiraXML", func(t *testing.T) {
+		jiraProject := tdb.CreateTestProject(t, prefix+"jira", testOrg.ID, testUser.ID)
+		file := `<rss version="0.92"><channel>
+			<item>
+				<key id="10001">OPS-1</key>
+				<summary>Rotate certificates</summary>
+				<description>&lt;p&gt;Before they expire&lt;/p&gt;</description>
+				<status>Done</status>
+				<priority>Highest</priority>
+				<assignee username="` + testUser.Email + `">Test User</assignee>
+				<labels><label>security</label></labels>
+				<comments>
+					<comment author="` + testUser.Email + `" created="Wed, 24 Jan 2024 10:00:00 +0000">&lt;p&gt;Done for prod&lt;/p&gt;</comment>
+				</comments>
+				<timeoriginalestimate seconds="7200">2 hours</timeoriginalestimate>
+			</item>
+		</channel></rss>`
+
+		result, err := transferService.Import(ctx, testOrg.ID, jiraProject.ID, strings.NewReader(file), models.TaskImportOptions{
+			Format: models.TaskTransferFormatJiraXML,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 1, result.Comments)
+
+		tasks := listTasks(jiraProject)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Before they expire", tasks[0].Description)
+		assert.Equal(t, models.TaskStatusDone, tasks[0].Status)
+		assert.Equal(t, models.TaskPriorityCritical, tasks[0].Priority)
+		require.NotNil(t, tasks[0].EstimatedHours)
+		assert.Equal(t, 2.0, *tasks[0].EstimatedHours)
+	})
+
+	t.Run("ExportRoundTrip", func(t *testing.T) {
+		// Record time on a task so that the export has a time entry
+		tasks := listTasks(testProject)
+		require.NotEmpty(t, tasks)
+		end := time.Now().Truncate(time.Second)
+		duration := 90
+		require.NoError(t, timeEntryRepo.Create(ctx, &models.TimeEntry{
+			ID:              tasks[0].ID,
+			TaskID:          tasks[0].ID,
+			UserID:          testUser.ID,
+			StartTime:       end.Add(-90 * time.Minute),
+			EndTime:         &end,
+			DurationMinutes: &duration,
+			Description:     "Planning meeting",
+			Billable:        true,
+			CreatedAt:       end,
+			UpdatedAt:       end,
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=json", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id/export")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+
+		require.NoError(t, transferHandlers.Export(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment")
+
+		var document models.TaskTransferDocument
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+		assert.Equal(t, models.TaskTransferDocumentFormat, document.Format)
+		assert.Equal(t, testProject.ID.String(), document.Project.ID)
+		require.Len(t, document.Tasks, 2)
+
+		// The copied time entry overlaps the original, which the organization
+		// does not allow by default, so nothing is imported
+		copyProject := tdb.CreateTestProject(t, prefix+"copy", testOrg.ID, testUser.ID)
+		result, err := transferService.Import(ctx, testOrg.ID, copyProject.ID, bytes.NewReader(rec.Body.Bytes()), models.TaskImportOptions{
+			Format: models.TaskTransferFormatJSON,
+		}, testUser.ID)
+		require.NoError(t, err)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "time_entries", result.Errors[0].Field)
+			assert.Contains(t, result.Errors[0].Message, timeentry.ErrTimeEntryOverlap.Error())
+		}
+		assert.Equal(t, 0, result.Imported)
+		assert.Empty(t, listTasks(copyProject))
+
+		// Once overlaps are allowed, importing the export copies the tasks
+		require.NoError(t, policyRepo.SavePolicy(ctx, &models.TimeEntryPolicy{
+			OrganizationID:     testOrg.ID,
+			SingleRunningTimer: true,
+			OverlapMode:        models.TimeEntryOverlapAllow,
+		}))
+		result, err = transferService.Import(ctx, testOrg.ID, copyProject.ID, bytes.NewReader(rec.Body.Bytes()), models.TaskImportOptions{
+			Format: models.TaskTransferFormatJSON,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, 2, result.Imported)
+		assert.Equal(t, 1, result.TimeEntries)
+
+		copied := listTasks(copyProject)
+		require.Len(t, copied, 2)
+		subtasks := 0
+		for _, task := range copied {
+			if task.ParentID != nil {
+				subtasks++
+			}
+		}
+		assert.Equal(t, 1, subtasks)
+	})
+
+	t.Run("ExportCSV", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, transferService.Export(ctx, testOrg.ID, testProject.ID, models.TaskTransferFormatCSV, out))
+
+		rows, err := csv.NewReader(out).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, models.TaskImportFields, rows[0])
+
+		// CSV exports can be imported without a mapping
+		csvProject := tdb.CreateTestProject(t, prefix+"csv", testOrg.ID, testUser.ID)
+		file := &bytes.Buffer{}
+		writer := csv.NewWriter(file)
+		require.NoError(t, writer.WriteAll(rows))
+		result, err := transferService.Import(ctx, testOrg.ID, csvProject.ID, file, models.TaskImportOptions{
+			Format: models.TaskTransferFormatCSV,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, 2, result.Imported)
+	})
+
+	t.Run("InvalidFiles", func(t *testing.T) {
+		_, err := transferService.Import(ctx, testOrg.ID, testProject.ID, strings.NewReader("Name\nSomething\n"), models.TaskImportOptions{
+			Format: models.TaskTransferFormatCSV,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, transfer.ErrInvalidImport)
+
+		_, err = transferService.Import(ctx, testOrg.ID, testProject.ID, strings.NewReader(`{"tasks": []}`), models.TaskImportOptions{
+			Format: models.TaskTransferFormatJSON,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, transfer.ErrInvalidImport)
+
+		err = transferService.Export(ctx, testOrg.ID, testProject.ID, models.TaskTransferFormatJiraXML, &bytes.Buffer{})
+		assert.ErrorIs(t, err, transfer.ErrUnsupportedFormat)
+	})
+
+	t.Run("ArchivedProject", func(t *testing.T) {
+		archivedProject := tdb.CreateTestProject(t, prefix+"archived", testOrg.ID, testUser.ID)
+		require.NoError(t, projectRepo.Archive(ctx, archivedProject.ID, testUser.ID))
+
+		_, err := transferService.Import(ctx, testOrg.ID, archivedProject.ID, strings.NewReader(csvFile), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectArchived)
+		assert.Empty(t, listTasks(archivedProject))
+	})
+	t.Run("OtherOrganization", func(t *testing.T) {
+		otherOrg := tdb.CreateTestOrganization(t, prefix+"other", testUser.ID)
+		otherProject := tdb.CreateTestProject(t, prefix+"other", otherOrg.ID, testUser.ID)
+
+		// Projects are only found within the organization of the route
+		_, err := transferService.Import(ctx, testOrg.ID, otherProject.ID, strings.NewReader(csvFile), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+		}, testUser.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+		assert.Empty(t, listTasks(otherProject))
+
+		err = transferService.Export(ctx, testOrg.ID, otherProject.ID, models.TaskTransferFormatJSON, &bytes.Buffer{})
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+	})
+
+	t.Run("RequiredCustomFields", func(t *testing.T) {
+		requiredProject := tdb.CreateTestProject(t, prefix+"required", testOrg.ID, testUser.ID)
+		_, err := customFieldService.Create(ctx, testOrg.ID, models.CustomFieldDefinitionRequest{
+			ProjectID: &requiredProject.ID,
+			Entity:    models.CustomFieldEntityTask,
+			Key:       "team",
+			Name:      "Team",
+			Type:      models.CustomFieldTypeText,
+			Required:  true,
+		})
+		require.NoError(t, err)
+
+		// Imported tasks have no values for the required field
+		result, err := transferService.Import(ctx, testOrg.ID, requiredProject.ID, strings.NewReader(csvFile), models.TaskImportOptions{
+			Format:  models.TaskTransferFormatCSV,
+			Mapping: mapping,
+		}, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Imported)
+		if assert.Len(t, result.Errors, 2) {
+			assert.Equal(t, "custom_fields", result.Errors[0].Field)
+		}
+		assert.Empty(t, listTasks(requiredProject))
+	})
+}
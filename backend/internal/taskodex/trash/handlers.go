@@ -0,0 +1,182 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for the trash
+type Handlers struct {
+	service Service
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+// List handles listing the items in the trash of an organization
+func (h *Handlers) List(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	params := models.TrashListParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Page:           1,
+		PageSize:       20,
+	}
+
+	// Parse type parameter
+	typeParam := c.QueryParam("type")
+	if typeParam != "" {
+		itemType := models.TrashItemType(typeParam)
+		if itemType != models.TrashItemTypeProject && itemType != models.TrashItemTypeTask {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid type parameter")
+		}
+		params.Type = &itemType
+	}
+
+	// Parse page parameter
+	pageParam := c.QueryParam("page")
+	if pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page parameter")
+		}
+		params.Page = page
+	}
+
+	// Parse page_size parameter
+	pageSizeParam := c.QueryParam("page_size")
+	if pageSizeParam != "" {
+		pageSize, err := strconv.Atoi(pageSizeParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid page_size parameter")
+		}
+		params.PageSize = pageSize
+	}
+
+	// Ensure page and page size are valid
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	// List trash
+	items, total, err := h.service.List(c.Request().Context(), params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve trash")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"items": items,
+		"pagination": map[string]interface{}{
+			"total":       total,
+			"page":        params.Page,
+			"page_size":   params.PageSize,
+			"total_pages": (total + params.PageSize - 1) / params.PageSize,
+		},
+	})
+}
+
+// RestoreProject handles restoring a project from the trash
+func (h *Handlers) RestoreProject(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get project ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Restore project
+	response, err := h.service.RestoreProject(c.Request().Context(), orgID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to restore project")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RestoreTask handles restoring a task from the trash
+func (h *Handlers) RestoreTask(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// Get task ID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID")
+	}
+
+	// Get user ID from context
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Restore task
+	response, err := h.service.RestoreTask(c.Request().Context(), orgID, userID, id)
+	if err != nil {
+		return h.handleError(err, "Failed to restore task")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// handleError maps errors from restoring items to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	if errors.Is(err, repository.ErrTrashItemNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Item not found in the trash")
+	}
+	if errors.Is(err, repository.ErrProjectNameExists) {
+		return echo.NewHTTPError(http.StatusConflict, "Project name already exists in this organization")
+	}
+	if errors.Is(err, ErrProjectInTrash) || errors.Is(err, repository.ErrProjectArchived) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the trash routes. Restoring an item needs the
+// permission that deleting it did.
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	trashGroup := g.Group("/trash")
+
+	// Routes that require taskodex:access permission
+	trashGroup.GET("", h.List, rbacMiddleware.RequirePermission(middleware.PermissionTaskodexAccess))
+
+	// Routes that require project:delete permission
+	trashGroup.POST("/projects/:id/restore", h.RestoreProject, rbacMiddleware.RequirePermission(middleware.PermissionProjectDelete))
+
+	// Routes that require task:delete permission
+	trashGroup.POST("/tasks/:id/restore", h.RestoreTask, rbacMiddleware.RequirePermission(middleware.PermissionTaskDelete))
+}
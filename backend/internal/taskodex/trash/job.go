@@ -0,0 +1,42 @@
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+)
+
+// Job purges the expired items in the trash when run by a jobs.Scheduler.
+// Each item is purged in its own transaction, so a run that is interrupted
+// and retried picks up where it stopped.
+type Job struct {
+	service Service
+}
+
+// NewJob creates a new Job
+func NewJob(service Service) *Job {
+	return &Job{service: service}
+}
+
+// Name returns the name of the job
+func (j *Job) Name() string {
+	return "trash_purge"
+}
+
+// Run purges the items expired at now
+func (j *Job) Run(ctx context.Context, now time.Time) error {
+	result, err := j.service.Purge(ctx, now)
+	if err != nil {
+		return err
+	}
+	if result.Projects > 0 || result.Tasks > 0 {
+		logger.FromContext(ctx).Info("purged trash",
+			slog.Int("projects", result.Projects),
+			slog.Int("tasks", result.Tasks),
+			slog.Int("files", result.Files),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/storage"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrProjectInTrash = errors.New("task belongs to a project in the trash; restore the project instead")
+)
+
+// purgeBatchSize is the number of expired items listed at a time by a purge
+const purgeBatchSize = 100
+
+// Service provides access to deleted projects and tasks
+type Service interface {
+	// List retrieves the items in the trash of an organization, most
+	// recently deleted first
+	List(ctx context.Context, params models.TrashListParams) ([]models.TrashItem, int, error)
+
+	// RestoreProject restores a project of an organization from the trash,
+	// along with the tasks deleted with it
+	RestoreProject(ctx context.Context, organizationID, id uuid.UUID) (*models.ProjectResponse, error)
+
+	// RestoreTask restores a task from the trash, along with the subtasks
+	// deleted with it. Tasks without a project can only be restored by the
+	// user who deleted them.
+	RestoreTask(ctx context.Context, organizationID, userID, id uuid.UUID) (*models.TaskResponse, error)
+
+	// Purge deletes the items that have been in the trash for longer than
+	// the retention period at now for good, along with their stored files
+	Purge(ctx context.Context, now time.Time) (*models.TrashPurgeResult, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	trashRepo   repository.TrashRepository
+	projectRepo repository.ProjectRepository
+	taskRepo    repository.TaskRepository
+	fileStorage storage.FileStorage
+	retention   time.Duration
+}
+
+// NewService creates a new trash service. Items are purged once they have
+// been in the trash for longer than retention, or models.DefaultTrashRetention
+// if it is not positive.
+func NewService(
+	trashRepo repository.TrashRepository,
+	projectRepo repository.ProjectRepository,
+	taskRepo repository.TaskRepository,
+	fileStorage storage.FileStorage,
+	retention time.Duration,
+) Service {
+	if retention <= 0 {
+		retention = models.DefaultTrashRetention
+	}
+
+	return &serviceImpl{
+		trashRepo:   trashRepo,
+		projectRepo: projectRepo,
+		taskRepo:    taskRepo,
+		fileStorage: fileStorage,
+		retention:   retention,
+	}
+}
+
+// List retrieves the items in the trash of an organization
+func (s *serviceImpl) List(ctx context.Context, params models.TrashListParams) ([]models.TrashItem, int, error) {
+	items, total, err := s.trashRepo.List(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(s.retention)
+	}
+
+	return items, total, nil
+}
+
+// RestoreProject restores a project from the trash
+func (s *serviceImpl) RestoreProject(ctx context.Context, organizationID, id uuid.UUID) (*models.ProjectResponse, error) {
+	item, err := s.trashRepo.Get(ctx, models.TrashItemTypeProject, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.OrganizationID == nil || *item.OrganizationID != organizationID {
+		return nil, repository.ErrTrashItemNotFound
+	}
+
+	if err := s.trashRepo.RestoreProject(ctx, id); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := project.ToResponse()
+	return &response, nil
+}
+
+// RestoreTask restores a task from the trash
+func (s *serviceImpl) RestoreTask(ctx context.Context, organizationID, userID, id uuid.UUID) (*models.TaskResponse, error) {
+	item, err := s.trashRepo.Get(ctx, models.TrashItemTypeTask, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.ProjectID == nil {
+		// Tasks without a project belong to no organization
+		if item.DeletedBy == nil || *item.DeletedBy != userID {
+			return nil, repository.ErrTrashItemNotFound
+		}
+	} else {
+		if item.OrganizationID == nil || *item.OrganizationID != organizationID {
+			return nil, repository.ErrTrashItemNotFound
+		}
+
+		// Tasks go back to their project, which must be restored first and
+		// must not be read-only
+		project, err := s.projectRepo.GetByID(ctx, *item.ProjectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrProjectNotFound) {
+				return nil, ErrProjectInTrash
+			}
+			return nil, err
+		}
+		if project.IsArchived() {
+			return nil, repository.ErrProjectArchived
+		}
+	}
+
+	if err := s.trashRepo.RestoreTask(ctx, id); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := task.ToResponse()
+	return &response, nil
+}
+
+// Purge deletes the expired items in the trash. Stored files are deleted
+// once the records referring to them are gone; files that cannot be deleted
+// are logged and left behind.
+func (s *serviceImpl) Purge(ctx context.Context, now time.Time) (*models.TrashPurgeResult, error) {
+	before := now.Add(-s.retention)
+	result := &models.TrashPurgeResult{}
+
+	for {
+		items, err := s.trashRepo.ListExpired(ctx, before, purgeBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, item := range items {
+			paths, err := s.trashRepo.Purge(ctx, item.Type, item.ID, before)
+			if err != nil {
+				// Restored, or purged along with its project, in the meantime
+				if errors.Is(err, repository.ErrTrashItemNotFound) {
+					continue
+				}
+				return result, err
+			}
+
+			if item.Type == models.TrashItemTypeProject {
+				result.Projects++
+			} else {
+				result.Tasks++
+			}
+			result.Files += s.deleteFiles(ctx, paths)
+		}
+
+		if len(items) < purgeBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// deleteFiles deletes stored files and returns how many were deleted. Files
+// that are already gone are not counted.
+func (s *serviceImpl) deleteFiles(ctx context.Context, paths []string) int {
+	deleted := 0
+	for _, path := range paths {
+		err := s.fileStorage.DeleteFile(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logger.FromContext(ctx).Error("failed to delete purged file",
+					slog.String("storage_path", path),
+					slog.Any("error", err),
+				)
+			}
+			continue
+		}
+		deleted++
+	}
+	return deleted
+}
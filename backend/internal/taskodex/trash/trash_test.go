@@ -0,0 +1,309 @@
+package trash_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/customfield"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/project"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/trash"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStorage records the files deleted from it
+type fakeStorage struct {
+	files   map[string]bool
+	deleted []string
+}
+
+func (s *fakeStorage) SaveFile(file *multipart.FileHeader, taskID, userID uuid.UUID) (string, error) {
+	return "", fmt.Errorf("not supported")
+}
+
+func (s *fakeStorage) GetFile(storagePath string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (s *fakeStorage) DeleteFile(storagePath string) error {
+	if !s.files[storagePath] {
+		return fmt.Errorf("file not found: %w", os.ErrNotExist)
+	}
+	delete(s.files, storagePath)
+	s.deleted = append(s.deleted, storagePath)
+	return nil
+}
+
+func TestTrash(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test user and organization
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+
+	// Create repositories
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	attachmentRepo := repository.NewPostgresFileAttachmentRepository(tdb.DB)
+
+	// Create services
+	storage := &fakeStorage{files: map[string]bool{}}
+	trashService := trash.NewService(
+		repository.NewPostgresTrashRepository(tdb.DB),
+		projectRepo,
+		taskRepo,
+		storage,
+		0,
+	)
+	projectService := project.NewService(
+		projectRepo,
+		taskRepo,
+		userRepo,
+		customfield.NewService(repository.NewPostgresCustomFieldRepository(tdb.DB), projectRepo, userRepo, repository.NewPostgresRoleRepository(tdb.DB)),
+	)
+
+	createTask := func(projectID *uuid.UUID, parentID *uuid.UUID, title string) *models.Task {
+		task := models.NewTask(models.TaskRequest{
+			ProjectID: projectID,
+			ParentID:  parentID,
+			Title:     prefix + title,
+			Status:    models.TaskStatusTodo,
+			Priority:  models.TaskPriorityMedium,
+		}, testUser.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+
+	attach := func(taskID uuid.UUID, name string) string {
+		path := prefix + name
+		storage.files[path] = true
+		require.NoError(t, attachmentRepo.Create(ctx, &models.FileAttachment{
+			ID:          uuid.New(),
+			TaskID:      taskID,
+			UserID:      testUser.ID,
+			FileName:    name,
+			FileSize:    1,
+			ContentType: "text/plain",
+			StoragePath: path,
+			CreatedAt:   time.Now(),
+		}))
+		return path
+	}
+
+	listTrash := func(itemType models.TrashItemType) []models.TrashItem {
+		items, _, err := trashService.List(ctx, models.TrashListParams{
+			OrganizationID: testOrg.ID,
+			UserID:         testUser.ID,
+			Type:           &itemType,
+			Page:           1,
+			PageSize:       100,
+		})
+		require.NoError(t, err)
+		return items
+	}
+
+	findItem := func(items []models.TrashItem, id uuid.UUID) *models.TrashItem {
+		for i := range items {
+			if items[i].ID == id {
+				return &items[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("RestoreProject", func(t *testing.T) {
+		testProject := tdb.CreateTestProject(t, prefix+"restore", testOrg.ID, testUser.ID)
+		parent := createTask(&testProject.ID, nil, "Parent")
+		child := createTask(&testProject.ID, &parent.ID, "Child")
+
+		require.NoError(t, projectService.Delete(ctx, testProject.ID, testUser.ID))
+
+		// The project and its tasks are hidden
+		_, err := projectRepo.GetByID(ctx, testProject.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+		_, err = taskRepo.GetByID(ctx, child.ID)
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+		// The project is one item, and its tasks are part of it
+		item := findItem(listTrash(models.TrashItemTypeProject), testProject.ID)
+		require.NotNil(t, item)
+		assert.Equal(t, 2, item.TaskCount)
+		assert.Equal(t, testUser.ID, *item.DeletedBy)
+		assert.True(t, item.PurgeAt.Equal(item.DeletedAt.Add(models.DefaultTrashRetention)))
+		assert.Nil(t, findItem(listTrash(models.TrashItemTypeTask), parent.ID))
+
+		// Its tasks go back with the project
+		_, err = trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, parent.ID)
+		assert.ErrorIs(t, err, trash.ErrProjectInTrash)
+
+		// Projects of other organizations cannot be restored
+		_, err = trashService.RestoreProject(ctx, uuid.New(), testProject.ID)
+		assert.ErrorIs(t, err, repository.ErrTrashItemNotFound)
+
+		restored, err := trashService.RestoreProject(ctx, testOrg.ID, testProject.ID)
+		require.NoError(t, err)
+		assert.Equal(t, testProject.ID, restored.ID)
+
+		restoredChild, err := taskRepo.GetByID(ctx, child.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parent.ID, *restoredChild.ParentID)
+		assert.Nil(t, findItem(listTrash(models.TrashItemTypeProject), testProject.ID))
+	})
+
+	t.Run("RestoreProjectNameTaken", func(t *testing.T) {
+		testProject := tdb.CreateTestProject(t, prefix+"taken", testOrg.ID, testUser.ID)
+		require.NoError(t, projectService.Delete(ctx, testProject.ID, testUser.ID))
+
+		// The name of a deleted project is free
+		_, err := projectService.Create(ctx, models.ProjectRequest{
+			OrganizationID: testOrg.ID,
+			Name:           testProject.Name,
+			Status:         models.ProjectStatusActive,
+		}, testUser.ID)
+		require.NoError(t, err)
+
+		_, err = trashService.RestoreProject(ctx, testOrg.ID, testProject.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectNameExists)
+	})
+
+	t.Run("RestoreTask", func(t *testing.T) {
+		testProject := tdb.CreateTestProject(t, prefix+"tasks", testOrg.ID, testUser.ID)
+		parent := createTask(&testProject.ID, nil, "Parent")
+		child := createTask(&testProject.ID, &parent.ID, "Child")
+		grandchild := createTask(&testProject.ID, &child.ID, "Grandchild")
+
+		// Deleting a subtask first makes it an item of its own
+		require.NoError(t, taskRepo.Delete(ctx, grandchild.ID, testUser.ID))
+		require.NoError(t, taskRepo.Delete(ctx, parent.ID, testUser.ID))
+
+		tasks := listTrash(models.TrashItemTypeTask)
+		parentItem := findItem(tasks, parent.ID)
+		require.NotNil(t, parentItem)
+		assert.Equal(t, 1, parentItem.TaskCount)
+		assert.Nil(t, findItem(tasks, child.ID))
+		assert.NotNil(t, findItem(tasks, grandchild.ID))
+
+		// A task whose parent is still in the trash is restored without one
+		_, err := trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, child.ID)
+		require.NoError(t, err)
+		restoredChild, err := taskRepo.GetByID(ctx, child.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restoredChild.ParentID)
+
+		// The parent comes back alone, and the grandchild deleted on its own stays
+		_, err = trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, parent.ID)
+		require.NoError(t, err)
+		_, err = taskRepo.GetByID(ctx, grandchild.ID)
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+		response, err := trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, grandchild.ID)
+		require.NoError(t, err)
+		assert.Equal(t, child.ID, *response.ParentID)
+
+		_, err = trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, grandchild.ID)
+		assert.ErrorIs(t, err, repository.ErrTrashItemNotFound)
+	})
+
+	t.Run("Archive", func(t *testing.T) {
+		testProject := tdb.CreateTestProject(t, prefix+"archive", testOrg.ID, testUser.ID)
+		task := createTask(&testProject.ID, nil, "Archived")
+		require.NoError(t, taskRepo.Delete(ctx, task.ID, testUser.ID))
+
+		archived, err := projectService.Archive(ctx, testProject.ID, testUser.ID)
+		require.NoError(t, err)
+		require.NotNil(t, archived.ArchivedAt)
+		assert.Equal(t, testUser.ID, *archived.ArchivedBy)
+
+		// Archived projects are read-only but still listed
+		_, err = projectService.Update(ctx, testProject.ID, models.ProjectRequest{
+			OrganizationID: testOrg.ID,
+			Name:           testProject.Name,
+			Status:         models.ProjectStatusCompleted,
+		})
+		assert.ErrorIs(t, err, repository.ErrProjectArchived)
+
+		archivedOnly := true
+		projects, _, err := projectService.List(ctx, models.ProjectListParams{
+			OrganizationID: testOrg.ID,
+			Archived:       &archivedOnly,
+			Page:           1,
+			PageSize:       100,
+		})
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, testProject.ID, projects[0].ID)
+
+		_, err = trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, task.ID)
+		assert.ErrorIs(t, err, repository.ErrProjectArchived)
+
+		unarchived, err := projectService.Unarchive(ctx, testProject.ID)
+		require.NoError(t, err)
+		assert.Nil(t, unarchived.ArchivedAt)
+
+		_, err = trashService.RestoreTask(ctx, testOrg.ID, testUser.ID, task.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		expired := tdb.CreateTestProject(t, prefix+"expired", testOrg.ID, testUser.ID)
+		expiredTask := createTask(&expired.ID, nil, "Expired project task")
+		projectFile := attach(expiredTask.ID, "project.txt")
+
+		standalone := createTask(nil, nil, "Expired task")
+		standaloneChild := createTask(nil, &standalone.ID, "Expired subtask")
+		childFile := attach(standaloneChild.ID, "subtask.txt")
+		missingFile := attach(standaloneChild.ID, "missing.txt")
+		delete(storage.files, missingFile)
+
+		recent := createTask(nil, nil, "Recent task")
+
+		require.NoError(t, projectService.Delete(ctx, expired.ID, testUser.ID))
+		require.NoError(t, taskRepo.Delete(ctx, standalone.ID, testUser.ID))
+		require.NoError(t, taskRepo.Delete(ctx, recent.ID, testUser.ID))
+
+		// Age the expired items past the retention period
+		deletedAt := time.Now().Add(-models.DefaultTrashRetention - time.Hour)
+		_, err := tdb.DB.Exec("UPDATE taskodex.projects SET deleted_at = $1 WHERE id = $2", deletedAt, expired.ID)
+		require.NoError(t, err)
+		_, err = tdb.DB.Exec("UPDATE taskodex.tasks SET deleted_at = $1 WHERE id IN ($2, $3, $4)",
+			deletedAt, expiredTask.ID, standalone.ID, standaloneChild.ID)
+		require.NoError(t, err)
+
+		// Tasks without a project are listed for the user who deleted them
+		assert.NotNil(t, findItem(listTrash(models.TrashItemTypeTask), standalone.ID))
+
+		result, err := trashService.Purge(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Projects)
+		assert.Equal(t, 1, result.Tasks)
+		assert.Equal(t, 2, result.Files)
+
+		assert.ElementsMatch(t, []string{projectFile, childFile}, storage.deleted)
+
+		var count int
+		err = tdb.DB.Get(&count, "SELECT COUNT(*) FROM taskodex.projects WHERE id = $1", expired.ID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		err = tdb.DB.Get(&count, "SELECT COUNT(*) FROM taskodex.tasks WHERE id IN ($1, $2, $3)",
+			expiredTask.ID, standalone.ID, standaloneChild.ID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		// Recently deleted items stay in the trash
+		assert.NotNil(t, findItem(listTrash(models.TrashItemTypeTask), recent.ID))
+	})
+}
@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for project workflow management
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get handles retrieving the workflow of a project
+func (h *Handlers) Get(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Get workflow
+	response, err := h.service.Get(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retrieve workflow")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Set handles defining the workflow of a project
+func (h *Handlers) Set(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Parse request body
+	var req models.WorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Set workflow
+	response, err := h.service.Set(c.Request().Context(), id, req)
+	if err != nil {
+		return h.handleError(err, "Failed to update workflow")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Reset handles reverting a project to the default workflow
+func (h *Handlers) Reset(c echo.Context) error {
+	// Get project ID from path parameter
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID")
+	}
+
+	// Reset workflow
+	response, err := h.service.Reset(c.Request().Context(), id)
+	if err != nil {
+		return h.handleError(err, "Failed to reset workflow")
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// handleError maps errors from updating a workflow to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	if errors.Is(err, repository.ErrProjectNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+	}
+	if errors.Is(err, ErrInvalidWorkflow) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, ErrStateInUse) || errors.Is(err, repository.ErrProjectArchived) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the workflow routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	workflowGroup := g.Group("/projects/:id/workflow")
+
+	// Routes that require project:read permission
+	workflowGroup.GET("", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	workflowGroup.PUT("", h.Set, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	workflowGroup.DELETE("", h.Reset, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+}
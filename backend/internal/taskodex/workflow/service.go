@@ -0,0 +1,183 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrInvalidWorkflow = errors.New("invalid workflow")
+	ErrStateInUse      = errors.New("workflow state is in use by tasks")
+)
+
+// Service provides project workflow management functionality
+type Service interface {
+	// Get retrieves the workflow of a project. Projects that do not define a
+	// workflow use the default workflow.
+	Get(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error)
+
+	// Set defines the workflow of a project
+	Set(ctx context.Context, projectID uuid.UUID, req models.WorkflowRequest) (*models.Workflow, error)
+
+	// Reset reverts a project to the default workflow
+	Reset(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error)
+
+	// ForTask retrieves the workflow that applies to a task. Within a
+	// transaction, the workflow cannot be replaced until it ends.
+	ForTask(ctx context.Context, task *models.Task) (*models.Workflow, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	workflowRepo repository.WorkflowRepository
+	projectRepo  repository.ProjectRepository
+	taskRepo     repository.TaskRepository
+	txManager    repository.TxManager
+}
+
+// NewService creates a new workflow service
+func NewService(
+	workflowRepo repository.WorkflowRepository,
+	projectRepo repository.ProjectRepository,
+	taskRepo repository.TaskRepository,
+	txManager repository.TxManager,
+) Service {
+	return &serviceImpl{
+		workflowRepo: workflowRepo,
+		projectRepo:  projectRepo,
+		taskRepo:     taskRepo,
+		txManager:    txManager,
+	}
+}
+
+// Get retrieves the workflow of a project
+func (s *serviceImpl) Get(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error) {
+	// Check if project exists
+	_, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.forProject(ctx, projectID)
+}
+
+// Set defines the workflow of a project
+func (s *serviceImpl) Set(ctx context.Context, projectID uuid.UUID, req models.WorkflowRequest) (*models.Workflow, error) {
+	// Check if project exists. Archived projects are read-only.
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	workflow := models.NewWorkflow(projectID, req)
+	if err := workflow.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+
+	// The tasks stay in the workflow's states until it is saved
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.workflowRepo.Lock(ctx, projectID); err != nil {
+			return err
+		}
+		if err := s.checkStatesInUse(ctx, projectID, workflow); err != nil {
+			return err
+		}
+		return s.workflowRepo.Save(ctx, workflow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.workflowRepo.GetByProject(ctx, projectID)
+}
+
+// Reset reverts a project to the default workflow
+func (s *serviceImpl) Reset(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error) {
+	// Check if project exists. Archived projects are read-only.
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.IsArchived() {
+		return nil, repository.ErrProjectArchived
+	}
+
+	workflow := models.DefaultWorkflow()
+	// The tasks stay in the default workflow's states until it applies
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.workflowRepo.Lock(ctx, projectID); err != nil {
+			return err
+		}
+		if err := s.checkStatesInUse(ctx, projectID, workflow); err != nil {
+			return err
+		}
+		err := s.workflowRepo.DeleteByProject(ctx, projectID)
+		if err != nil && !errors.Is(err, repository.ErrWorkflowNotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// ForTask retrieves the workflow that applies to a task
+func (s *serviceImpl) ForTask(ctx context.Context, task *models.Task) (*models.Workflow, error) {
+	if task.ProjectID == nil {
+		return models.DefaultWorkflow(), nil
+	}
+
+	if err := s.workflowRepo.LockShared(ctx, *task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	return s.forProject(ctx, *task.ProjectID)
+}
+
+// forProject retrieves the workflow of a project, falling back to the default workflow
+func (s *serviceImpl) forProject(ctx context.Context, projectID uuid.UUID) (*models.Workflow, error) {
+	workflow, err := s.workflowRepo.GetByProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkflowNotFound) {
+			return models.DefaultWorkflow(), nil
+		}
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// checkStatesInUse returns ErrStateInUse if any task of the project is in a
+// state that the workflow does not define
+func (s *serviceImpl) checkStatesInUse(ctx context.Context, projectID uuid.UUID, workflow *models.Workflow) error {
+	statuses, err := s.taskRepo.ListStatuses(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, status := range statuses {
+		if _, ok := workflow.State(status); !ok {
+			missing = append(missing, string(status))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrStateInUse, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package workflow_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workflow"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowAPI(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	// Create test user, organization and project
+	testUser := tdb.CreateTestUser(t, prefix)
+	testOrg := tdb.CreateTestOrganization(t, prefix, testUser.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, testUser.ID)
+
+	// Create repositories
+	workflowRepo := repository.NewPostgresWorkflowRepository(tdb.DB)
+	projectRepo := repository.NewPostgresProjectRepository(tdb.DB)
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+
+	// Create service and handlers
+	workflowService := workflow.NewService(workflowRepo, projectRepo, taskRepo, repository.NewTxManager(tdb.DB))
+	workflowHandlers := workflow.NewHandlers(workflowService)
+
+	// Setup Echo
+	e := echo.New()
+
+	newContext := func(method string, body interface{}) (echo.Context, *httptest.ResponseRecorder) {
+		var reqBody bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&reqBody).Encode(body)
+		}
+
+		req := httptest.NewRequest(method, "/", &reqBody)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/v1/organizations/:org_id/taskodex/projects/:id/workflow")
+		c.SetParamNames("org_id", "id")
+		c.SetParamValues(testOrg.ID.String(), testProject.ID.String())
+		return c, rec
+	}
+
+	qaWorkflow := models.WorkflowRequest{
+		Name:         "QA",
+		InitialState: "triage",
+		States: []models.WorkflowState{
+			{Key: "triage", Name: "Triage", Category: models.WorkflowStateCategoryOpen},
+			{Key: "in_test", Name: "In Test", Category: models.WorkflowStateCategoryInProgress},
+			{Key: "verified", Name: "Verified", Category: models.WorkflowStateCategoryClosed},
+		},
+		Transitions: []models.WorkflowTransition{
+			{FromState: "triage", ToState: "in_test"},
+			{FromState: "in_test", ToState: "verified"},
+		},
+	}
+
+	t.Run("GetDefaultWorkflow", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, nil)
+
+		err := workflowHandlers.Get(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.Workflow
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, response.InitialState)
+		assert.Len(t, response.States, 5)
+	})
+
+	t.Run("StateInUse", func(t *testing.T) {
+		// The test task is in the todo state, which the QA workflow does not define
+		tdb.CreateTestTask(t, prefix, testProject.ID, testUser.ID)
+
+		c, _ := newContext(http.MethodPut, qaWorkflow)
+		err := workflowHandlers.Set(c)
+		if httpErr, ok := err.(*echo.HTTPError); assert.True(t, ok) {
+			assert.Equal(t, http.StatusConflict, httpErr.Code)
+		}
+	})
+
+	t.Run("SetWorkflow", func(t *testing.T) {
+		emptyProject := tdb.CreateTestProject(t, prefix+"empty", testOrg.ID, testUser.ID)
+
+		saved, err := workflowService.Set(context.Background(), emptyProject.ID, qaWorkflow)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatus("triage"), saved.InitialState)
+		assert.Len(t, saved.States, 3)
+		assert.Len(t, saved.Transitions, 2)
+
+		// Replacing the workflow keeps its identity
+		qaWorkflow.Name = "QA v2"
+		updated, err := workflowService.Set(context.Background(), emptyProject.ID, qaWorkflow)
+		require.NoError(t, err)
+		assert.Equal(t, saved.ID, updated.ID)
+		assert.Equal(t, "QA v2", updated.Name)
+
+		// Reset reverts to the default workflow
+		reset, err := workflowService.Reset(context.Background(), emptyProject.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaskStatusTodo, reset.InitialState)
+	})
+
+	t.Run("InvalidWorkflow", func(t *testing.T) {
+		invalid := qaWorkflow
+		invalid.InitialState = "unknown"
+
+		_, err := workflowService.Set(context.Background(), testProject.ID, invalid)
+		assert.ErrorIs(t, err, workflow.ErrInvalidWorkflow)
+	})
+}
@@ -0,0 +1,218 @@
+package workload
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Handlers provides HTTP handlers for workloads
+type Handlers struct {
+	service  Service
+	validate *validator.Validate
+}
+
+// NewHandlers creates a new Handlers
+func NewHandlers(service Service) *Handlers {
+	return &Handlers{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get handles retrieving the workload of the members of an organization
+func (h *Handlers) Get(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	today := time.Now().UTC()
+	params := models.WorkloadParams{
+		OrganizationID: orgID,
+		From:           today,
+		Period:         models.WorkloadPeriodWeek,
+	}
+
+	// Parse from parameter
+	fromParam := c.QueryParam("from")
+	if fromParam != "" {
+		from, err := time.Parse(models.AnalyticsDateFormat, fromParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid from parameter")
+		}
+		params.From = from
+	}
+
+	// Parse to parameter
+	params.To = params.From.AddDate(0, 0, models.DefaultWorkloadDays-1)
+	toParam := c.QueryParam("to")
+	if toParam != "" {
+		to, err := time.Parse(models.AnalyticsDateFormat, toParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid to parameter")
+		}
+		params.To = to
+	}
+
+	// Parse period parameter
+	periodParam := c.QueryParam("period")
+	if periodParam != "" {
+		period := models.WorkloadPeriod(periodParam)
+		if period != models.WorkloadPeriodDay && period != models.WorkloadPeriodWeek {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid period parameter")
+		}
+		params.Period = period
+	}
+
+	// Parse user_id parameter
+	userIDParam := c.QueryParam("user_id")
+	if userIDParam != "" {
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid user_id parameter")
+		}
+		params.UserID = &userID
+	}
+
+	// Get workload
+	workload, err := h.service.Get(c.Request().Context(), params)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve workload")
+	}
+
+	return c.JSON(http.StatusOK, workload)
+}
+
+// ListCapacities handles listing the capacities of the members of an
+// organization
+func (h *Handlers) ListCapacities(c echo.Context) error {
+	// Get organization ID from path parameter
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	// List capacities
+	capacities, err := h.service.ListCapacities(c.Request().Context(), orgID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve capacities")
+	}
+
+	return c.JSON(http.StatusOK, capacities)
+}
+
+// GetCapacity handles retrieving the capacity of a member
+func (h *Handlers) GetCapacity(c echo.Context) error {
+	orgID, userID, err := parseMember(c)
+	if err != nil {
+		return err
+	}
+
+	// Get capacity
+	capacity, err := h.service.GetCapacity(c.Request().Context(), orgID, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to retrieve capacity")
+	}
+
+	return c.JSON(http.StatusOK, capacity)
+}
+
+// SetCapacity handles setting the capacity of a member
+func (h *Handlers) SetCapacity(c echo.Context) error {
+	orgID, userID, err := parseMember(c)
+	if err != nil {
+		return err
+	}
+
+	// Get user ID from context
+	updatedBy, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse request body
+	var req models.WorkloadCapacityRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	// Validate request
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Set capacity
+	capacity, err := h.service.SetCapacity(c.Request().Context(), orgID, userID, req, updatedBy)
+	if err != nil {
+		return h.handleError(err, "Failed to update capacity")
+	}
+
+	return c.JSON(http.StatusOK, capacity)
+}
+
+// ResetCapacity handles giving a member the default capacity
+func (h *Handlers) ResetCapacity(c echo.Context) error {
+	orgID, userID, err := parseMember(c)
+	if err != nil {
+		return err
+	}
+
+	// Reset capacity
+	capacity, err := h.service.ResetCapacity(c.Request().Context(), orgID, userID)
+	if err != nil {
+		return h.handleError(err, "Failed to reset capacity")
+	}
+
+	return c.JSON(http.StatusOK, capacity)
+}
+
+// parseMember parses the organization and user IDs from the path parameters
+func parseMember(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid organization ID")
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	return orgID, userID, nil
+}
+
+// handleError maps errors from workload operations to HTTP errors
+func (h *Handlers) handleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrOrganizationNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Organization not found")
+	case errors.Is(err, ErrNotAMember):
+		return echo.NewHTTPError(http.StatusNotFound, "User is not a member of this organization")
+	case errors.Is(err, ErrInvalidRange):
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid range: from must not be in the past, and to must be within a year after it")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// RegisterRoutes registers the workload routes
+func (h *Handlers) RegisterRoutes(g *echo.Group, rbacMiddleware *middleware.RBACMiddleware) {
+	workloadGroup := g.Group("/workload")
+
+	// Routes that require project:read permission
+	workloadGroup.GET("", h.Get, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	workloadGroup.GET("/capacities", h.ListCapacities, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+	workloadGroup.GET("/capacities/:user_id", h.GetCapacity, rbacMiddleware.RequirePermission(middleware.PermissionProjectRead))
+
+	// Routes that require project:write permission
+	workloadGroup.PUT("/capacities/:user_id", h.SetCapacity, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+	workloadGroup.DELETE("/capacities/:user_id", h.ResetCapacity, rbacMiddleware.RequirePermission(middleware.PermissionProjectWrite))
+}
@@ -0,0 +1,327 @@
+package workload
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxHorizonDays bounds the days tasks are spread over, so that a task due
+// years ahead does not allocate a schedule for every day until then
+const maxHorizonDays = 3 * 366
+
+// epsilon is the allocated hours beyond the capacity that are not counted
+// as over-allocation, to absorb rounding
+const epsilon = 0.005
+
+// priorityRanks orders priorities from the first tasks to reassign to the
+// last
+var priorityRanks = map[models.TaskPriority]int{
+	models.TaskPriorityLow:      0,
+	models.TaskPriorityMedium:   1,
+	models.TaskPriorityHigh:     2,
+	models.TaskPriorityCritical: 3,
+}
+
+// schedule is the capacity of a member and the hours allocated to them on
+// each day from today
+type schedule struct {
+	user     models.User
+	capacity models.WorkloadCapacity
+
+	// hours are the hours the member can work on each day, and timeOff
+	// whether they are off on a working day
+	hours   []float64
+	timeOff []bool
+
+	allocated []float64
+	tasks     []*allocation
+}
+
+// allocation is a task and its hours on each day of its assignee's schedule
+type allocation struct {
+	task  *models.WorkloadTask
+	hours []float64
+}
+
+// newSchedule creates the schedule of a member for days from today, without
+// the days of their time off
+func newSchedule(user models.User, capacity models.WorkloadCapacity, today time.Time, days int, timeOff []models.WorkloadTimeOff) *schedule {
+	s := &schedule{
+		user:      user,
+		capacity:  capacity,
+		hours:     make([]float64, days),
+		timeOff:   make([]bool, days),
+		allocated: make([]float64, days),
+	}
+
+	for i := range s.hours {
+		if capacity.IsWorkDay(today.AddDate(0, 0, i)) {
+			s.hours[i] = capacity.HoursPerDay
+		}
+	}
+
+	for _, off := range timeOff {
+		first := dayIndex(today, off.StartDate)
+		if first < 0 {
+			first = 0
+		}
+		last := dayIndex(today, off.EndDate)
+		for i := first; i <= last && i < days; i++ {
+			if s.hours[i] > 0 {
+				s.hours[i] = 0
+				s.timeOff[i] = true
+			}
+		}
+	}
+
+	return s
+}
+
+// spread spreads the remaining hours of a task evenly over the days the
+// member can work from today until it is due. A task that is overdue falls
+// on today, and a task with no such days on the day it is due.
+func (s *schedule) spread(task *models.WorkloadTask, today time.Time) []float64 {
+	hours := make([]float64, len(s.hours))
+
+	due := dayIndex(today, *task.DueDate)
+	if due < 0 {
+		due = 0
+	}
+	if due >= len(hours) {
+		due = len(hours) - 1
+	}
+
+	available := 0
+	for i := 0; i <= due; i++ {
+		if s.hours[i] > 0 {
+			available++
+		}
+	}
+
+	if available == 0 {
+		hours[due] = task.RemainingHours
+		return hours
+	}
+
+	share := task.RemainingHours / float64(available)
+	for i := 0; i <= due; i++ {
+		if s.hours[i] > 0 {
+			hours[i] = share
+		}
+	}
+	return hours
+}
+
+// add allocates a task to the member
+func (s *schedule) add(a *allocation) {
+	for i, h := range a.hours {
+		s.allocated[i] += h
+	}
+	s.tasks = append(s.tasks, a)
+}
+
+// remove takes a task allocated to the member away from them
+func (s *schedule) remove(a *allocation) {
+	for i, h := range a.hours {
+		s.allocated[i] -= h
+	}
+	for i, t := range s.tasks {
+		if t == a {
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			break
+		}
+	}
+}
+
+// period is a range of days of a workload, by index from today
+type period struct {
+	first int
+	last  int
+}
+
+// periods splits the days from first to last into days or weeks. Weeks
+// start on Monday; the first and last weeks are cut to the range.
+func periods(today time.Time, first, last int, length models.WorkloadPeriod) []period {
+	result := []period{}
+	for start := first; start <= last; {
+		end := start
+		if length == models.WorkloadPeriodWeek {
+			weekday := int(today.AddDate(0, 0, start).Weekday())
+			end = start + (7-weekday)%7
+			if end > last {
+				end = last
+			}
+		}
+		result = append(result, period{first: start, last: end})
+		start = end + 1
+	}
+	return result
+}
+
+// sum returns the hours in a period
+func (p period) sum(hours []float64) float64 {
+	total := 0.0
+	for i := p.first; i <= p.last; i++ {
+		total += hours[i]
+	}
+	return total
+}
+
+// overallocated returns the hours allocated beyond the capacity of the
+// member in a period
+func (s *schedule) overallocated(p period) float64 {
+	excess := p.sum(s.allocated) - p.sum(s.hours)
+	if excess <= epsilon {
+		return 0
+	}
+	return excess
+}
+
+// plan is the schedules of the members of an organization over the periods
+// of a workload
+type plan struct {
+	today     time.Time
+	periods   []period
+	schedules []*schedule
+}
+
+// suggest simulates reassigning tasks away from each member, most
+// over-allocated first, until they are no longer over-allocated or no task
+// can be reassigned. Tasks are considered by priority, lowest first, then
+// latest due first. A task is reassigned to the member with the most spare
+// capacity it fits into without over-allocating them.
+func (r *plan) suggest(from map[uuid.UUID]bool) []models.WorkloadSuggestion {
+	suggestions := []models.WorkloadSuggestion{}
+
+	members := []*schedule{}
+	for _, s := range r.schedules {
+		if from[s.user.ID] && r.overallocated(s) > 0 {
+			members = append(members, s)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return r.overallocated(members[i]) > r.overallocated(members[j])
+	})
+
+	for _, s := range members {
+		candidates := append([]*allocation{}, s.tasks...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i].task, candidates[j].task
+			if priorityRanks[a.Priority] != priorityRanks[b.Priority] {
+				return priorityRanks[a.Priority] < priorityRanks[b.Priority]
+			}
+			return a.DueDate.After(*b.DueDate)
+		})
+
+		for _, a := range candidates {
+			if r.overallocated(s) == 0 {
+				break
+			}
+			if !r.relieves(s, a) {
+				continue
+			}
+
+			target, moved := r.target(s, a)
+			if target == nil {
+				continue
+			}
+
+			s.remove(a)
+			target.add(moved)
+			suggestions = append(suggestions, models.WorkloadSuggestion{
+				TaskID:     a.task.TaskID,
+				Title:      a.task.Title,
+				Priority:   a.task.Priority,
+				DueDate:    a.task.DueDate,
+				FromUserID: s.user.ID,
+				ToUserID:   target.user.ID,
+				Hours:      round(r.inRange(a.hours)),
+			})
+		}
+	}
+
+	return suggestions
+}
+
+// overallocated returns the hours a member is over-allocated in the
+// periods of the workload
+func (r *plan) overallocated(s *schedule) float64 {
+	total := 0.0
+	for _, p := range r.periods {
+		total += s.overallocated(p)
+	}
+	return total
+}
+
+// relieves reports whether a task has hours in a period the member is
+// over-allocated in
+func (r *plan) relieves(s *schedule, a *allocation) bool {
+	for _, p := range r.periods {
+		if s.overallocated(p) > 0 && p.sum(a.hours) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// target finds the member a task can be reassigned to, along with the
+// task's allocation to them
+func (r *plan) target(from *schedule, a *allocation) (*schedule, *allocation) {
+	var best *schedule
+	var bestAllocation *allocation
+	bestSpare := 0.0
+
+	for _, s := range r.schedules {
+		if s == from {
+			continue
+		}
+
+		moved := &allocation{task: a.task, hours: s.spread(a.task, r.today)}
+		fits := true
+		spare := 0.0
+		for _, p := range r.periods {
+			free := p.sum(s.hours) - p.sum(s.allocated)
+			hours := p.sum(moved.hours)
+			if hours > 0 && hours > free+epsilon {
+				fits = false
+				break
+			}
+			spare += free
+		}
+
+		if fits && r.inRange(moved.hours) > 0 && (best == nil || spare > bestSpare) {
+			best, bestAllocation, bestSpare = s, moved, spare
+		}
+	}
+
+	return best, bestAllocation
+}
+
+// inRange returns the hours within the periods of the workload
+func (r *plan) inRange(hours []float64) float64 {
+	total := 0.0
+	for _, p := range r.periods {
+		total += p.sum(hours)
+	}
+	return total
+}
+
+// dayIndex returns the index of the UTC day of t from today
+func dayIndex(today, t time.Time) int {
+	return int(day(t).Sub(today).Hours() / 24)
+}
+
+// day returns the start of the UTC day of t
+func day(t time.Time) time.Time {
+	year, month, d := t.UTC().Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// round rounds hours to two decimals
+func round(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,357 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Common errors
+var (
+	ErrNotAMember   = errors.New("user is not a member of the organization")
+	ErrInvalidRange = errors.New("invalid workload range")
+)
+
+// Service provides the workload of the members of an organization: the
+// remaining estimated hours of their open tasks against their capacity,
+// less their approved time off in Qultrix.
+//
+// The remaining hours of a task are spread evenly over the days its
+// assignee can work from today until it is due. Days are UTC days and weeks
+// start on Monday.
+type Service interface {
+	// Get retrieves the workload of the members of an organization over a
+	// range of days from today on, with tasks that could be reassigned to
+	// relieve the members who are over-allocated
+	Get(ctx context.Context, params models.WorkloadParams) (*models.Workload, error)
+
+	// ListCapacities retrieves the capacity of every member of an
+	// organization
+	ListCapacities(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadCapacity, error)
+
+	// GetCapacity retrieves the capacity of a member
+	GetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error)
+
+	// SetCapacity sets the capacity of a member
+	SetCapacity(ctx context.Context, organizationID, userID uuid.UUID, req models.WorkloadCapacityRequest, updatedBy uuid.UUID) (*models.WorkloadCapacity, error)
+
+	// ResetCapacity gives a member the default capacity
+	ResetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error)
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	workloadRepo repository.WorkloadRepository
+	orgRepo      repository.OrganizationRepository
+}
+
+// NewService creates a new workload service
+func NewService(workloadRepo repository.WorkloadRepository, orgRepo repository.OrganizationRepository) Service {
+	return &serviceImpl{
+		workloadRepo: workloadRepo,
+		orgRepo:      orgRepo,
+	}
+}
+
+// Get retrieves the workload of the members of an organization
+func (s *serviceImpl) Get(ctx context.Context, params models.WorkloadParams) (*models.Workload, error) {
+	today := day(time.Now())
+	from := day(params.From)
+	to := day(params.To)
+
+	if from.Before(today) || to.Before(from) || dayIndex(from, to) >= models.MaxWorkloadDays {
+		return nil, ErrInvalidRange
+	}
+
+	users, err := s.members(ctx, params.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.UserID != nil {
+		if _, ok := users[*params.UserID]; !ok {
+			return nil, ErrNotAMember
+		}
+	}
+
+	capacities, err := s.capacities(ctx, params.OrganizationID, users)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.workloadRepo.ListTasks(ctx, params.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedules run from today to the range's last day, or the last day a
+	// task is due within the horizon
+	days := dayIndex(today, to) + 1
+	for _, task := range tasks {
+		if task.DueDate != nil && dayIndex(today, *task.DueDate) >= days {
+			days = dayIndex(today, *task.DueDate) + 1
+		}
+	}
+	if days > maxHorizonDays {
+		days = maxHorizonDays
+	}
+
+	timeOff, err := s.workloadRepo.ListTimeOff(ctx, params.OrganizationID, today, today.AddDate(0, 0, days-1))
+	if err != nil {
+		return nil, err
+	}
+	timeOffByUser := map[uuid.UUID][]models.WorkloadTimeOff{}
+	for _, off := range timeOff {
+		timeOffByUser[off.UserID] = append(timeOffByUser[off.UserID], off)
+	}
+
+	schedules := map[uuid.UUID]*schedule{}
+	plan := &plan{
+		today:   today,
+		periods: periods(today, dayIndex(today, from), dayIndex(today, to), params.Period),
+	}
+	for _, capacity := range capacities {
+		sch := newSchedule(users[capacity.UserID], capacity, today, days, timeOffByUser[capacity.UserID])
+		schedules[capacity.UserID] = sch
+		plan.schedules = append(plan.schedules, sch)
+	}
+
+	// Allocate the remaining hours of the tasks to their assignees
+	unscheduled := map[uuid.UUID]float64{}
+	for i := range tasks {
+		task := &tasks[i]
+		sch, ok := schedules[task.AssignedTo]
+		if !ok {
+			continue
+		}
+
+		task.RemainingHours = task.EstimatedHours
+		if task.ActualHours != nil {
+			task.RemainingHours -= *task.ActualHours
+		}
+		if task.RemainingHours <= 0 {
+			continue
+		}
+
+		if task.DueDate == nil {
+			unscheduled[task.AssignedTo] += task.RemainingHours
+			continue
+		}
+
+		sch.add(&allocation{task: task, hours: sch.spread(task, today)})
+	}
+
+	workload := &models.Workload{
+		From:        from.Format(models.AnalyticsDateFormat),
+		To:          to.Format(models.AnalyticsDateFormat),
+		Period:      params.Period,
+		Members:     []models.WorkloadMember{},
+		Suggestions: []models.WorkloadSuggestion{},
+	}
+
+	relieve := map[uuid.UUID]bool{}
+	for _, sch := range plan.schedules {
+		if params.UserID != nil && sch.user.ID != *params.UserID {
+			continue
+		}
+		relieve[sch.user.ID] = true
+
+		member := plan.member(sch)
+		member.UnscheduledHours = round(unscheduled[sch.user.ID])
+		workload.Members = append(workload.Members, member)
+	}
+
+	// Suggestions are simulated once the workload is reported, as they
+	// change the schedules
+	workload.Suggestions = plan.suggest(relieve)
+
+	return workload, nil
+}
+
+// member reports the workload of a member in the periods of the workload
+func (r *plan) member(s *schedule) models.WorkloadMember {
+	user := s.user.ToResponse()
+	member := models.WorkloadMember{
+		UserID:   s.user.ID,
+		User:     &user,
+		Capacity: s.capacity,
+		Periods:  []models.WorkloadPeriodLoad{},
+		Tasks:    []models.WorkloadTask{},
+	}
+
+	for _, p := range r.periods {
+		load := models.WorkloadPeriodLoad{
+			Start:          r.today.AddDate(0, 0, p.first).Format(models.AnalyticsDateFormat),
+			End:            r.today.AddDate(0, 0, p.last).Format(models.AnalyticsDateFormat),
+			CapacityHours:  round(p.sum(s.hours)),
+			AllocatedHours: round(p.sum(s.allocated)),
+		}
+		for i := p.first; i <= p.last; i++ {
+			if s.timeOff[i] {
+				load.TimeOffDays++
+			}
+		}
+		if load.CapacityHours > 0 {
+			utilization := round(p.sum(s.allocated) / p.sum(s.hours))
+			load.Utilization = &utilization
+		}
+
+		excess := s.overallocated(p)
+		load.Overallocated = excess > 0
+		member.CapacityHours += p.sum(s.hours)
+		member.AllocatedHours += p.sum(s.allocated)
+		member.OverallocatedHours += excess
+		member.Periods = append(member.Periods, load)
+	}
+
+	member.CapacityHours = round(member.CapacityHours)
+	member.AllocatedHours = round(member.AllocatedHours)
+	member.OverallocatedHours = round(member.OverallocatedHours)
+	member.Overallocated = member.OverallocatedHours > 0
+
+	for _, a := range s.tasks {
+		hours := r.inRange(a.hours)
+		if hours <= 0 {
+			continue
+		}
+		task := *a.task
+		task.RemainingHours = round(task.RemainingHours)
+		task.AllocatedHours = round(hours)
+		member.Tasks = append(member.Tasks, task)
+	}
+
+	return member
+}
+
+// ListCapacities retrieves the capacity of every member of an organization
+func (s *serviceImpl) ListCapacities(ctx context.Context, organizationID uuid.UUID) ([]models.WorkloadCapacity, error) {
+	users, err := s.members(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.capacities(ctx, organizationID, users)
+}
+
+// GetCapacity retrieves the capacity of a member
+func (s *serviceImpl) GetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error) {
+	if err := s.checkMember(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	capacity, err := s.workloadRepo.GetCapacity(ctx, organizationID, userID)
+	if errors.Is(err, repository.ErrWorkloadCapacityNotFound) {
+		return models.NewDefaultWorkloadCapacity(organizationID, userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
+
+// SetCapacity sets the capacity of a member
+func (s *serviceImpl) SetCapacity(ctx context.Context, organizationID, userID uuid.UUID, req models.WorkloadCapacityRequest, updatedBy uuid.UUID) (*models.WorkloadCapacity, error) {
+	if err := s.checkMember(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	capacity := &models.WorkloadCapacity{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		HoursPerDay:    round(req.HoursPerDay),
+		WorkDays:       req.WorkDays,
+		UpdatedBy:      &updatedBy,
+		UpdatedAt:      &now,
+	}
+
+	if err := s.workloadRepo.SetCapacity(ctx, capacity); err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
+
+// ResetCapacity gives a member the default capacity
+func (s *serviceImpl) ResetCapacity(ctx context.Context, organizationID, userID uuid.UUID) (*models.WorkloadCapacity, error) {
+	if err := s.checkMember(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+
+	err := s.workloadRepo.DeleteCapacity(ctx, organizationID, userID)
+	if err != nil && !errors.Is(err, repository.ErrWorkloadCapacityNotFound) {
+		return nil, err
+	}
+
+	return models.NewDefaultWorkloadCapacity(organizationID, userID), nil
+}
+
+// members retrieves the active members of an organization by ID
+func (s *serviceImpl) members(ctx context.Context, organizationID uuid.UUID) (map[uuid.UUID]models.User, error) {
+	if _, err := s.orgRepo.GetByID(ctx, organizationID); err != nil {
+		return nil, err
+	}
+
+	users, err := s.orgRepo.GetUsers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		members[user.ID] = user
+	}
+	return members, nil
+}
+
+// checkMember checks that a user is an active member of an organization
+func (s *serviceImpl) checkMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	members, err := s.members(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := members[userID]; !ok {
+		return ErrNotAMember
+	}
+	return nil
+}
+
+// capacities retrieves the capacity of each member, in the order of their
+// email addresses
+func (s *serviceImpl) capacities(ctx context.Context, organizationID uuid.UUID, users map[uuid.UUID]models.User) ([]models.WorkloadCapacity, error) {
+	set, err := s.workloadRepo.ListCapacities(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[uuid.UUID]models.WorkloadCapacity, len(set))
+	for _, capacity := range set {
+		byUser[capacity.UserID] = capacity
+	}
+
+	ordered := make([]models.User, 0, len(users))
+	for _, user := range users {
+		ordered = append(ordered, user)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].Email < ordered[j].Email
+	})
+
+	capacities := make([]models.WorkloadCapacity, 0, len(ordered))
+	for _, user := range ordered {
+		capacity, ok := byUser[user.ID]
+		if !ok {
+			capacity = *models.NewDefaultWorkloadCapacity(organizationID, user.ID)
+		}
+		capacities = append(capacities, capacity)
+	}
+
+	return capacities, nil
+}
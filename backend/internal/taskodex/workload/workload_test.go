@@ -0,0 +1,277 @@
+package workload_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/internal/repository"
+	"github.com/Jerinji2016/halooid/backend/internal/taskodex/workload"
+	"github.com/Jerinji2016/halooid/backend/internal/test"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkload(t *testing.T) {
+	// Setup test environment
+	tdb, prefix := test.SetupTestEnvironment(t)
+	defer test.TeardownTestEnvironment(t, tdb, prefix)
+
+	ctx := context.Background()
+
+	// Create test users, organization and project
+	lead := tdb.CreateTestUser(t, prefix)
+	busy := tdb.CreateTestUser(t, prefix+"2")
+	free := tdb.CreateTestUser(t, prefix+"3")
+	outsider := tdb.CreateTestUser(t, prefix+"4")
+	testOrg := tdb.CreateTestOrganization(t, prefix, lead.ID)
+	testProject := tdb.CreateTestProject(t, prefix, testOrg.ID, lead.ID)
+
+	// Create repositories
+	userRepo := repository.NewPostgresUserRepository(tdb.DB)
+	orgRepo := repository.NewPostgresOrganizationRepository(tdb.DB)
+	taskRepo := repository.NewPostgresTaskRepository(tdb.DB)
+	employeeRepo := repository.NewPostgresEmployeeRepository(tdb.DB)
+
+	// Create service and handlers
+	workloadService := workload.NewService(repository.NewPostgresWorkloadRepository(tdb.DB), orgRepo)
+	workloadHandlers := workload.NewHandlers(workloadService)
+	e := echo.New()
+
+	for _, user := range []*models.User{lead, busy, free} {
+		user.IsActive = true
+		require.NoError(t, userRepo.Update(ctx, user))
+		require.NoError(t, orgRepo.AddUser(ctx, &models.OrganizationUser{OrganizationID: testOrg.ID, UserID: user.ID}))
+	}
+
+	// Everyone works every day, so the days of the week do not matter
+	everyDay := []int64{0, 1, 2, 3, 4, 5, 6}
+	_, err := workloadService.SetCapacity(ctx, testOrg.ID, lead.ID, models.WorkloadCapacityRequest{HoursPerDay: 4, WorkDays: everyDay}, lead.ID)
+	require.NoError(t, err)
+	_, err = workloadService.SetCapacity(ctx, testOrg.ID, busy.ID, models.WorkloadCapacityRequest{HoursPerDay: 8, WorkDays: everyDay}, lead.ID)
+	require.NoError(t, err)
+	_, err = workloadService.SetCapacity(ctx, testOrg.ID, free.ID, models.WorkloadCapacityRequest{HoursPerDay: 8, WorkDays: everyDay}, lead.ID)
+	require.NoError(t, err)
+
+	today := time.Now().UTC()
+	date := func(days int) string {
+		return today.AddDate(0, 0, days).Format(models.AnalyticsDateFormat)
+	}
+
+	newTask := func(title string, assignee uuid.UUID, priority models.TaskPriority, status models.TaskStatus, estimate float64, dueDays *int) *models.Task {
+		req := models.TaskRequest{
+			ProjectID:      &testProject.ID,
+			Title:          prefix + title,
+			Status:         status,
+			Priority:       priority,
+			AssignedTo:     &assignee,
+			EstimatedHours: &estimate,
+		}
+		if dueDays != nil {
+			due := time.Date(today.Year(), today.Month(), today.Day(), 12, 0, 0, 0, time.UTC).AddDate(0, 0, *dueDays)
+			req.DueDate = &due
+		}
+		task := models.NewTask(req, lead.ID)
+		require.NoError(t, taskRepo.Create(ctx, task))
+		return task
+	}
+	tomorrow := 1
+
+	// busy has 40 hours left of a big task and 6 of a small one, both due
+	// tomorrow: 23 hours a day against 8
+	big := newTask("Big", busy.ID, models.TaskPriorityHigh, models.TaskStatusInProgress, 44, &tomorrow)
+	logged := 4.0
+	big.ActualHours = &logged
+	require.NoError(t, taskRepo.Update(ctx, big))
+	small := newTask("Small", busy.ID, models.TaskPriorityLow, models.TaskStatusTodo, 6, &tomorrow)
+
+	// Tasks without a due date are unscheduled, and closed tasks are ignored
+	newTask("Someday", busy.ID, models.TaskPriorityMedium, models.TaskStatusTodo, 5, nil)
+	newTask("Finished", busy.ID, models.TaskPriorityMedium, models.TaskStatusDone, 10, &tomorrow)
+
+	// free has approved time off on the fourth and fifth days, and a request
+	// for the sixth that is still pending
+	employee := models.NewEmployee(models.EmployeeRequest{
+		UserID:     free.ID,
+		EmployeeID: prefix + "E1",
+		Department: "Engineering",
+		Position:   "Engineer",
+		HireDate:   today.AddDate(-1, 0, 0),
+		Salary:     1,
+	}, testOrg.ID)
+	require.NoError(t, employeeRepo.Create(ctx, employee))
+	_, err = tdb.DB.Exec(`
+		INSERT INTO qultrix.time_off_requests (employee_id, start_date, end_date, type, status)
+		VALUES ($1, $2, $3, 'vacation', 'approved'), ($1, $4, $4, 'vacation', 'pending')
+	`, employee.ID, date(3), date(4), date(5))
+	require.NoError(t, err)
+
+	get := func(query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/workload?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("org_id")
+		c.SetParamValues(testOrg.ID.String())
+		return rec, workloadHandlers.Get(c)
+	}
+
+	t.Run("Workload", func(t *testing.T) {
+		result, err := workloadService.Get(ctx, models.WorkloadParams{
+			OrganizationID: testOrg.ID,
+			From:           today,
+			To:             today.AddDate(0, 0, 6),
+			Period:         models.WorkloadPeriodDay,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Members, 3)
+
+		members := map[uuid.UUID]models.WorkloadMember{}
+		for _, member := range result.Members {
+			require.Len(t, member.Periods, 7)
+			members[member.UserID] = member
+		}
+
+		// busy is over-allocated today and tomorrow
+		b := members[busy.ID]
+		assert.True(t, b.Overallocated)
+		assert.Equal(t, 56.0, b.CapacityHours)
+		assert.Equal(t, 46.0, b.AllocatedHours)
+		assert.Equal(t, 30.0, b.OverallocatedHours)
+		assert.Equal(t, 5.0, b.UnscheduledHours)
+		assert.Equal(t, 23.0, b.Periods[0].AllocatedHours)
+		assert.True(t, b.Periods[1].Overallocated)
+		assert.False(t, b.Periods[2].Overallocated)
+		require.NotNil(t, b.Periods[0].Utilization)
+		assert.Equal(t, 2.88, *b.Periods[0].Utilization)
+		require.Len(t, b.Tasks, 2)
+		for _, task := range b.Tasks {
+			if task.TaskID == big.ID {
+				assert.Equal(t, 40.0, task.RemainingHours)
+				assert.Equal(t, 40.0, task.AllocatedHours)
+			}
+		}
+
+		// free has no capacity while off
+		f := members[free.ID]
+		assert.False(t, f.Overallocated)
+		assert.Equal(t, 40.0, f.CapacityHours)
+		assert.Equal(t, 1, f.Periods[3].TimeOffDays)
+		assert.Nil(t, f.Periods[3].Utilization)
+		assert.Equal(t, 0, f.Periods[5].TimeOffDays)
+
+		// The small task moves to free, who has the most spare capacity; the
+		// big one fits nowhere
+		require.Len(t, result.Suggestions, 1)
+		assert.Equal(t, small.ID, result.Suggestions[0].TaskID)
+		assert.Equal(t, busy.ID, result.Suggestions[0].FromUserID)
+		assert.Equal(t, free.ID, result.Suggestions[0].ToUserID)
+		assert.Equal(t, 6.0, result.Suggestions[0].Hours)
+	})
+
+	t.Run("Weeks", func(t *testing.T) {
+		rec, err := get("period=week&user_id=" + busy.ID.String() + "&to=" + date(13))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.Workload
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		require.Len(t, result.Members, 1)
+		assert.Equal(t, busy.ID, result.Members[0].UserID)
+
+		// Weeks start on Monday and are cut to the range
+		periods := result.Members[0].Periods
+		assert.Equal(t, date(0), periods[0].Start)
+		assert.Equal(t, date(13), periods[len(periods)-1].End)
+		for _, period := range periods[1:] {
+			start, err := time.Parse(models.AnalyticsDateFormat, period.Start)
+			require.NoError(t, err)
+			assert.Equal(t, time.Monday, start.Weekday())
+		}
+		assert.Equal(t, 46.0, result.Members[0].AllocatedHours)
+		assert.Len(t, result.Suggestions, 1)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		_, err := get("from=" + date(-1))
+		assertHTTPError(t, err, http.StatusBadRequest)
+
+		_, err = get("from=" + date(2) + "&to=" + date(1))
+		assertHTTPError(t, err, http.StatusBadRequest)
+
+		_, err = get("to=" + date(models.MaxWorkloadDays))
+		assertHTTPError(t, err, http.StatusBadRequest)
+
+		_, err = get("user_id=" + outsider.ID.String())
+		assertHTTPError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		call := func(method string, userID uuid.UUID, body string, handler echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+			req := httptest.NewRequest(method, "/workload/capacities/"+userID.String(), strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("org_id", "user_id")
+			c.SetParamValues(testOrg.ID.String(), userID.String())
+			c.Set("user_id", lead.ID.String())
+			return rec, handler(c)
+		}
+
+		// Set a capacity
+		rec, err := call(http.MethodPut, free.ID, `{"hours_per_day": 6, "work_days": [1, 2, 3, 4]}`, workloadHandlers.SetCapacity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		capacity, err := workloadService.GetCapacity(ctx, testOrg.ID, free.ID)
+		require.NoError(t, err)
+		assert.False(t, capacity.Default)
+		assert.Equal(t, 6.0, capacity.HoursPerDay)
+		assert.Equal(t, []int64{1, 2, 3, 4}, capacity.WorkDays)
+
+		// Invalid capacities are rejected
+		_, err = call(http.MethodPut, free.ID, `{"hours_per_day": 25, "work_days": [1]}`, workloadHandlers.SetCapacity)
+		assertHTTPError(t, err, http.StatusBadRequest)
+		_, err = call(http.MethodPut, free.ID, `{"hours_per_day": 8, "work_days": [7]}`, workloadHandlers.SetCapacity)
+		assertHTTPError(t, err, http.StatusBadRequest)
+		_, err = call(http.MethodPut, free.ID, `{"hours_per_day": 8, "work_days": []}`, workloadHandlers.SetCapacity)
+		assertHTTPError(t, err, http.StatusBadRequest)
+
+		// Only members have a capacity
+		_, err = call(http.MethodPut, outsider.ID, `{"hours_per_day": 8, "work_days": [1]}`, workloadHandlers.SetCapacity)
+		assertHTTPError(t, err, http.StatusNotFound)
+
+		// Resetting gives the default capacity
+		rec, err = call(http.MethodDelete, free.ID, "", workloadHandlers.ResetCapacity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		capacities, err := workloadService.ListCapacities(ctx, testOrg.ID)
+		require.NoError(t, err)
+		require.Len(t, capacities, 3)
+		for _, capacity := range capacities {
+			if capacity.UserID == free.ID {
+				assert.True(t, capacity.Default)
+				assert.Equal(t, float64(models.DefaultCapacityHoursPerDay), capacity.HoursPerDay)
+				assert.Equal(t, models.DefaultWorkDays(), capacity.WorkDays)
+			} else {
+				assert.False(t, capacity.Default)
+			}
+		}
+	})
+}
+
+// assertHTTPError asserts that err is an HTTP error with a status code
+func assertHTTPError(t *testing.T, err error, code int) {
+	t.Helper()
+	require.Error(t, err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, code, httpErr.Code)
+}
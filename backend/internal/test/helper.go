@@ -30,7 +30,7 @@ func NewTestDB(t *testing.T) *TestDB {
 	}
 
 	// Connect to the database
-	db, err := sqlx.Connect("postgres", cfg.Database.URL)
+	db, err := sqlx.Connect("postgres", cfg.Database.DSN())
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
@@ -38,7 +38,7 @@ func NewTestDB(t *testing.T) *TestDB {
 	// Set connection pool settings
 	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
 	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	db.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	return &TestDB{DB: db}
 }
@@ -207,6 +207,7 @@ func (tdb *TestDB) CreateTestTimeEntry(t *testing.T, prefix string, taskID, user
 		EndTime:         &now,
 		DurationMinutes: &duration,
 		Description:     fmt.Sprintf("%s Test Time Entry", prefix),
+		Billable:        true,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
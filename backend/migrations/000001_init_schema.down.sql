@@ -0,0 +1,9 @@
+-- Drop the core schema for the Halooid platform
+
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS organization_users;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS users;
@@ -1,4 +1,4 @@
--- Initialize database schema for Halooid platform
+-- Create the core schema for the Halooid platform
 
 -- Create extensions
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
@@ -66,11 +66,11 @@ CREATE TABLE IF NOT EXISTS user_roles (
 );
 
 -- Create indexes
-CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_organizations_name ON organizations(name);
-CREATE INDEX idx_user_roles_user_id ON user_roles(user_id);
-CREATE INDEX idx_user_roles_organization_id ON user_roles(organization_id);
-CREATE INDEX idx_role_permissions_role_id ON role_permissions(role_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_organizations_name ON organizations(name);
+CREATE INDEX IF NOT EXISTS idx_user_roles_user_id ON user_roles(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_organization_id ON user_roles(organization_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_role_id ON role_permissions(role_id);
 
 -- Insert default roles
 INSERT INTO roles (name, description) VALUES
@@ -24,7 +24,6 @@ DROP TABLE IF EXISTS qultrix.employees;
 DROP SCHEMA IF EXISTS qultrix;
 
 -- Drop Taskodex schema
-DROP TABLE IF EXISTS taskodex.task_comments;
 DROP TABLE IF EXISTS taskodex.tasks;
 DROP TABLE IF EXISTS taskodex.projects;
 DROP SCHEMA IF EXISTS taskodex;
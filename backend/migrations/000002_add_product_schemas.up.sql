@@ -32,16 +32,6 @@ CREATE TABLE IF NOT EXISTS taskodex.tasks (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
--- Task comments table
-CREATE TABLE IF NOT EXISTS taskodex.task_comments (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    task_id UUID NOT NULL REFERENCES taskodex.tasks(id),
-    user_id UUID NOT NULL REFERENCES users(id),
-    content TEXT NOT NULL,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
 -- Qultrix schema
 CREATE SCHEMA IF NOT EXISTS qultrix;
 
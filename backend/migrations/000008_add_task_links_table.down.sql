@@ -0,0 +1,2 @@
+-- Drop task_links table
+DROP TABLE IF EXISTS taskodex.task_links;
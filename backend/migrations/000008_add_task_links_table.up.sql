@@ -0,0 +1,20 @@
+-- Create task_links table
+CREATE TABLE IF NOT EXISTS taskodex.task_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_task_id UUID NOT NULL,
+    target_task_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_task_links_source FOREIGN KEY (source_task_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_links_target FOREIGN KEY (target_task_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_links_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_task_links_type CHECK (type IN ('blocks', 'relates_to', 'duplicates')),
+    CONSTRAINT chk_task_links_not_self CHECK (source_task_id <> target_task_id),
+    CONSTRAINT uq_task_links_pair UNIQUE (source_task_id, target_task_id, type)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_task_links_source_task_id ON taskodex.task_links(source_task_id);
+CREATE INDEX IF NOT EXISTS idx_task_links_target_task_id ON taskodex.task_links(target_task_id);
+CREATE INDEX IF NOT EXISTS idx_task_links_type ON taskodex.task_links(type);
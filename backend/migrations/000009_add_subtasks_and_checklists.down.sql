@@ -0,0 +1,8 @@
+-- Drop task_checklist_items table
+DROP TABLE IF EXISTS taskodex.task_checklist_items;
+
+-- Drop parent task from tasks
+DROP INDEX IF EXISTS taskodex.idx_tasks_parent_id;
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS chk_tasks_not_own_parent;
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_parent;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS parent_id;
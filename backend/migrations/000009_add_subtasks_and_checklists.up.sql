@@ -0,0 +1,28 @@
+-- Add parent task to tasks. Deleting a parent deletes its subtasks unless
+-- they are reparented first.
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS parent_id UUID;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_parent;
+ALTER TABLE taskodex.tasks ADD CONSTRAINT fk_tasks_parent
+    FOREIGN KEY (parent_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS chk_tasks_not_own_parent;
+ALTER TABLE taskodex.tasks ADD CONSTRAINT chk_tasks_not_own_parent CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON taskodex.tasks(parent_id);
+
+-- Create task_checklist_items table
+CREATE TABLE IF NOT EXISTS taskodex.task_checklist_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    is_completed BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_checklist_items_task FOREIGN KEY (task_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_checklist_items_task_id ON taskodex.task_checklist_items(task_id, position);
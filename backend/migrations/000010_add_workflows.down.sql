@@ -0,0 +1,4 @@
+-- Drop workflow tables. Task statuses are kept as they are.
+DROP TABLE IF EXISTS taskodex.workflow_transitions;
+DROP TABLE IF EXISTS taskodex.workflow_states;
+DROP TABLE IF EXISTS taskodex.workflows;
@@ -0,0 +1,61 @@
+-- Create workflows table. Each project has at most one workflow; projects
+-- without one use the built-in default workflow.
+CREATE TABLE IF NOT EXISTS taskodex.workflows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    initial_state VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_workflows_project FOREIGN KEY (project_id) REFERENCES taskodex.projects(id) ON DELETE CASCADE,
+    CONSTRAINT uq_workflows_project UNIQUE (project_id)
+);
+
+-- Create workflow_states table
+CREATE TABLE IF NOT EXISTS taskodex.workflow_states (
+    workflow_id UUID NOT NULL,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (workflow_id, key),
+    CONSTRAINT fk_workflow_states_workflow FOREIGN KEY (workflow_id) REFERENCES taskodex.workflows(id) ON DELETE CASCADE,
+    CONSTRAINT chk_workflow_states_category CHECK (category IN ('open', 'in_progress', 'closed'))
+);
+
+-- Create workflow_transitions table
+CREATE TABLE IF NOT EXISTS taskodex.workflow_transitions (
+    workflow_id UUID NOT NULL,
+    from_state VARCHAR(50) NOT NULL,
+    to_state VARCHAR(50) NOT NULL,
+    required_fields TEXT[] NOT NULL DEFAULT '{}',
+    required_role VARCHAR(100),
+    PRIMARY KEY (workflow_id, from_state, to_state),
+    CONSTRAINT fk_workflow_transitions_from FOREIGN KEY (workflow_id, from_state) REFERENCES taskodex.workflow_states(workflow_id, key) ON DELETE CASCADE,
+    CONSTRAINT fk_workflow_transitions_to FOREIGN KEY (workflow_id, to_state) REFERENCES taskodex.workflow_states(workflow_id, key) ON DELETE CASCADE,
+    CONSTRAINT chk_workflow_transitions_not_self CHECK (from_state <> to_state)
+);
+
+-- Migrate existing projects to the default workflow, which allows every
+-- transition between the former fixed statuses
+INSERT INTO taskodex.workflows (project_id, name, initial_state)
+SELECT id, 'Default', 'todo' FROM taskodex.projects
+ON CONFLICT (project_id) DO NOTHING;
+
+INSERT INTO taskodex.workflow_states (workflow_id, key, name, category, position)
+SELECT w.id, s.key, s.name, s.category, s.position
+FROM taskodex.workflows w
+CROSS JOIN (VALUES
+    ('todo', 'To Do', 'open', 0),
+    ('in_progress', 'In Progress', 'in_progress', 1),
+    ('review', 'Review', 'in_progress', 2),
+    ('done', 'Done', 'closed', 3),
+    ('cancelled', 'Cancelled', 'closed', 4)
+) AS s(key, name, category, position)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO taskodex.workflow_transitions (workflow_id, from_state, to_state)
+SELECT f.workflow_id, f.key, t.key
+FROM taskodex.workflow_states f
+JOIN taskodex.workflow_states t ON t.workflow_id = f.workflow_id AND t.key <> f.key
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+-- Drop custom field values from tasks and projects
+DROP INDEX IF EXISTS taskodex.idx_projects_custom_fields;
+DROP INDEX IF EXISTS taskodex.idx_tasks_custom_fields;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS custom_fields;
+
+-- Drop custom_field_definitions table
+DROP TABLE IF EXISTS taskodex.custom_field_definitions;
@@ -0,0 +1,33 @@
+-- Create custom_field_definitions table. Definitions without a project apply
+-- to every project of the organization.
+CREATE TABLE IF NOT EXISTS taskodex.custom_field_definitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL,
+    project_id UUID,
+    entity VARCHAR(20) NOT NULL,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_custom_field_definitions_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_custom_field_definitions_project FOREIGN KEY (project_id) REFERENCES taskodex.projects(id) ON DELETE CASCADE,
+    CONSTRAINT chk_custom_field_definitions_entity CHECK (entity IN ('task', 'project')),
+    CONSTRAINT chk_custom_field_definitions_type CHECK (type IN ('text', 'number', 'date', 'select', 'multi_select', 'user'))
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS uq_custom_field_definitions_org_key
+    ON taskodex.custom_field_definitions(organization_id, entity, key) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_custom_field_definitions_project_key
+    ON taskodex.custom_field_definitions(project_id, entity, key) WHERE project_id IS NOT NULL;
+
+-- Add custom field values to tasks and projects
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_custom_fields ON taskodex.tasks USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_projects_custom_fields ON taskodex.projects USING GIN (custom_fields jsonb_path_ops);
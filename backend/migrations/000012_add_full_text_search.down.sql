@@ -0,0 +1,7 @@
+-- Drop full-text search vectors
+DROP INDEX IF EXISTS taskodex.idx_task_comments_search_vector;
+DROP INDEX IF EXISTS taskodex.idx_projects_search_vector;
+DROP INDEX IF EXISTS taskodex.idx_tasks_search_vector;
+ALTER TABLE taskodex.task_comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS search_vector;
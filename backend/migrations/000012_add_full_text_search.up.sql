@@ -0,0 +1,23 @@
+-- Add weighted full-text search vectors. Titles and names weigh most, then
+-- descriptions, then comment content.
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+ALTER TABLE taskodex.task_comments ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(content, '')), 'C')
+    ) STORED;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON taskodex.tasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_projects_search_vector ON taskodex.projects USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_task_comments_search_vector ON taskodex.task_comments USING GIN (search_vector);
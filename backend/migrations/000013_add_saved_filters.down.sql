@@ -0,0 +1,2 @@
+-- Drop saved_filters table
+DROP TABLE IF EXISTS taskodex.saved_filters;
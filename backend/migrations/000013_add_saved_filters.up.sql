@@ -0,0 +1,19 @@
+-- Create saved_filters table. A saved filter is a named task filter
+-- expression, visible to its owner or, when shared, to the organization.
+CREATE TABLE IF NOT EXISTS taskodex.saved_filters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL,
+    owner_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_saved_filters_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_saved_filters_owner FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_saved_filters_owner_name UNIQUE (organization_id, owner_id, name)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_saved_filters_owner_id ON taskodex.saved_filters(owner_id);
+CREATE INDEX IF NOT EXISTS idx_saved_filters_shared ON taskodex.saved_filters(organization_id) WHERE shared;
@@ -0,0 +1,3 @@
+-- Drop task recurrence tables
+DROP TABLE IF EXISTS taskodex.task_occurrences;
+DROP TABLE IF EXISTS taskodex.task_recurrences;
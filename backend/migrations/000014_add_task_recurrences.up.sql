@@ -0,0 +1,44 @@
+-- Create task_recurrences table. A recurrence repeats a task, its template,
+-- following an RRULE either on a schedule or when the latest instance is
+-- completed.
+CREATE TABLE IF NOT EXISTS taskodex.task_recurrences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL,
+    rule VARCHAR(500) NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT 'schedule',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    lead_days INTEGER NOT NULL DEFAULT 0,
+    next_occurrence TIMESTAMP WITH TIME ZONE,
+    occurrence_count INTEGER NOT NULL DEFAULT 0,
+    last_task_id UUID,
+    finished BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_task_recurrences_task FOREIGN KEY (task_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_recurrences_last_task FOREIGN KEY (last_task_id) REFERENCES taskodex.tasks(id) ON DELETE SET NULL,
+    CONSTRAINT fk_task_recurrences_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+    CONSTRAINT uq_task_recurrences_task UNIQUE (task_id),
+    CONSTRAINT chk_task_recurrences_mode CHECK (mode IN ('schedule', 'completion')),
+    CONSTRAINT chk_task_recurrences_lead_days CHECK (lead_days >= 0)
+);
+
+-- Create task_occurrences table. Each handled occurrence of a recurrence is
+-- recorded once, either with the task generated for it or as skipped, so an
+-- occurrence is never generated twice.
+CREATE TABLE IF NOT EXISTS taskodex.task_occurrences (
+    recurrence_id UUID NOT NULL,
+    occurs_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    task_id UUID,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (recurrence_id, occurs_at),
+    CONSTRAINT fk_task_occurrences_recurrence FOREIGN KEY (recurrence_id) REFERENCES taskodex.task_recurrences(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_occurrences_task FOREIGN KEY (task_id) REFERENCES taskodex.tasks(id) ON DELETE SET NULL,
+    CONSTRAINT chk_task_occurrences_status CHECK (status IN ('generated', 'skipped'))
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_task_recurrences_due ON taskodex.task_recurrences(next_occurrence) WHERE NOT finished;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_occurrences_task_id ON taskodex.task_occurrences(task_id) WHERE task_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Drop job runs and task reminders
+DROP INDEX IF EXISTS taskodex.idx_tasks_due_date;
+DROP TABLE IF EXISTS taskodex.task_reminders;
+DROP TABLE IF EXISTS job_runs;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
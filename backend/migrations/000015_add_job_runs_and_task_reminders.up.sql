@@ -0,0 +1,36 @@
+-- Add time zone to users, used to schedule reminders in their local time
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Create job_runs table. Each run of a background job is recorded, with the
+-- time of its retry if it failed.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    next_retry_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_job_runs_status CHECK (status IN ('running', 'succeeded', 'failed'))
+);
+
+-- Create task_reminders table. A reminder is recorded when an assignee is
+-- notified that a task is due soon or overdue, so each threshold is notified
+-- once per assignee and due date.
+CREATE TABLE IF NOT EXISTS taskodex.task_reminders (
+    task_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    threshold_days INTEGER NOT NULL DEFAULT 0,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (task_id, user_id, kind, threshold_days, due_date),
+    CONSTRAINT fk_task_reminders_task FOREIGN KEY (task_id) REFERENCES taskodex.tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_reminders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_task_reminders_kind CHECK (kind IN ('due_soon', 'overdue'))
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name_started_at ON job_runs(job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON taskodex.tasks(due_date) WHERE due_date IS NOT NULL AND assigned_to IS NOT NULL;
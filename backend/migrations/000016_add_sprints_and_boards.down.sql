@@ -0,0 +1,11 @@
+-- Drop rank from tasks
+DROP INDEX IF EXISTS taskodex.idx_tasks_project_rank;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS rank;
+
+-- Drop sprint from tasks
+DROP INDEX IF EXISTS taskodex.idx_tasks_sprint_id;
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_sprint;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS sprint_id;
+
+-- Drop sprints table
+DROP TABLE IF EXISTS taskodex.sprints;
@@ -0,0 +1,55 @@
+-- Create sprints table. A sprint is a time box of a project's work; at most
+-- one sprint of a project is active at a time.
+CREATE TABLE IF NOT EXISTS taskodex.sprints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    goal TEXT NOT NULL DEFAULT '',
+    state VARCHAR(20) NOT NULL DEFAULT 'planned',
+    start_date TIMESTAMP WITH TIME ZONE,
+    end_date TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    completed_tasks INTEGER NOT NULL DEFAULT 0,
+    carried_over_tasks INTEGER NOT NULL DEFAULT 0,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_sprints_project FOREIGN KEY (project_id) REFERENCES taskodex.projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_sprints_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+    CONSTRAINT chk_sprints_state CHECK (state IN ('planned', 'active', 'closed')),
+    CONSTRAINT chk_sprints_dates CHECK (end_date IS NULL OR start_date IS NULL OR end_date >= start_date)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_sprints_project_id ON taskodex.sprints(project_id, state);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sprints_active ON taskodex.sprints(project_id) WHERE state = 'active';
+
+-- Add sprint to tasks. Tasks without a sprint are in the project's backlog;
+-- deleting a sprint returns its tasks to the backlog.
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS sprint_id UUID;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_sprint;
+ALTER TABLE taskodex.tasks ADD CONSTRAINT fk_tasks_sprint
+    FOREIGN KEY (sprint_id) REFERENCES taskodex.sprints(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_sprint_id ON taskodex.tasks(sprint_id);
+
+-- Add rank to tasks. Ranks are strings of the digits 0-9 and a-z, compared
+-- byte by byte, that order the tasks of a project on its boards. A task is
+-- moved by giving it a rank between its new neighbours, so no other task
+-- changes.
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS rank TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+-- Rank existing tasks in the order they were created, starting halfway
+-- through the digits to leave room before them. Trailing zeros are trimmed,
+-- as ranks never end with one.
+UPDATE taskodex.tasks t
+SET rank = ranked.rank
+FROM (
+    SELECT id, rtrim('i' || lpad(to_hex(ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY created_at, id) * 4096), 8, '0'), '0') AS rank
+    FROM taskodex.tasks
+) ranked
+WHERE t.id = ranked.id AND t.rank = '';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_rank ON taskodex.tasks(project_id, rank);
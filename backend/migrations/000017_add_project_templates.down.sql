@@ -0,0 +1,3 @@
+-- Drop project template tables
+DROP TABLE IF EXISTS taskodex.project_template_tasks;
+DROP TABLE IF EXISTS taskodex.project_templates;
@@ -0,0 +1,37 @@
+-- Create project_templates table. A project template is a reusable outline
+-- of a project that new projects of the organization are created from.
+CREATE TABLE IF NOT EXISTS taskodex.project_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    workflow JSONB,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_project_templates_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_project_templates_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+    CONSTRAINT uq_project_templates_name UNIQUE (organization_id, name)
+);
+
+-- Create project_template_tasks table. Tasks are due a number of days after
+-- the start of the project, and are assigned by role.
+CREATE TABLE IF NOT EXISTS taskodex.project_template_tasks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL,
+    parent_id UUID,
+    position INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    priority VARCHAR(50) NOT NULL DEFAULT 'medium',
+    estimated_hours NUMERIC(10, 2),
+    due_offset_days INTEGER,
+    assignee_role VARCHAR(100),
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    checklist TEXT[] NOT NULL DEFAULT '{}',
+    CONSTRAINT fk_project_template_tasks_template FOREIGN KEY (template_id) REFERENCES taskodex.project_templates(id) ON DELETE CASCADE,
+    CONSTRAINT fk_project_template_tasks_parent FOREIGN KEY (parent_id) REFERENCES taskodex.project_template_tasks(id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_project_template_tasks_template_id ON taskodex.project_template_tasks(template_id, position);
@@ -0,0 +1,19 @@
+-- Drop archival from projects
+ALTER TABLE taskodex.projects DROP CONSTRAINT IF EXISTS fk_projects_archived_by;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS archived_by;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS archived_at;
+
+-- Drop soft delete from tasks and projects. Rows in the trash are deleted for good.
+DELETE FROM taskodex.tasks WHERE deleted_at IS NOT NULL OR project_id IN (SELECT id FROM taskodex.projects WHERE deleted_at IS NOT NULL);
+DELETE FROM taskodex.projects WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS taskodex.idx_tasks_deleted_at;
+DROP INDEX IF EXISTS taskodex.idx_projects_deleted_at;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_deleted_by;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE taskodex.projects DROP CONSTRAINT IF EXISTS fk_projects_deleted_by;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE taskodex.projects DROP COLUMN IF EXISTS deleted_at;
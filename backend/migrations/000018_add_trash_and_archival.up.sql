@@ -0,0 +1,29 @@
+-- Add soft delete to projects and tasks. Deleted projects and tasks are kept
+-- in the trash, hidden everywhere else, until they are restored or purged.
+-- Deleting a project moves its tasks to the trash with it, at the same time.
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS deleted_by UUID;
+
+ALTER TABLE taskodex.projects DROP CONSTRAINT IF EXISTS fk_projects_deleted_by;
+ALTER TABLE taskodex.projects ADD CONSTRAINT fk_projects_deleted_by
+    FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS deleted_by UUID;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS fk_tasks_deleted_by;
+ALTER TABLE taskodex.tasks ADD CONSTRAINT fk_tasks_deleted_by
+    FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Create indexes. Only deleted rows are indexed, for the trash and the purge job.
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON taskodex.projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON taskodex.tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Add archival to projects. Archived projects are read-only but still listed
+-- and searchable.
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE taskodex.projects ADD COLUMN IF NOT EXISTS archived_by UUID;
+
+ALTER TABLE taskodex.projects DROP CONSTRAINT IF EXISTS fk_projects_archived_by;
+ALTER TABLE taskodex.projects ADD CONSTRAINT fk_projects_archived_by
+    FOREIGN KEY (archived_by) REFERENCES users(id) ON DELETE SET NULL;
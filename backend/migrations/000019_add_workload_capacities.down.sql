@@ -0,0 +1,3 @@
+-- Drop workload capacities
+DROP INDEX IF EXISTS qultrix.idx_qultrix_time_off_requests_employee_id;
+DROP TABLE IF EXISTS taskodex.workload_capacities;
@@ -0,0 +1,21 @@
+-- Create workload_capacities table. The capacity of a member of an
+-- organization is the hours they can work on each of their working days,
+-- where the days are days of the week from 0 (Sunday) to 6 (Saturday).
+-- Members without a row have the default capacity.
+CREATE TABLE IF NOT EXISTS taskodex.workload_capacities (
+    organization_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    hours_per_day NUMERIC(4, 2) NOT NULL,
+    work_days SMALLINT[] NOT NULL,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id),
+    CONSTRAINT fk_workload_capacities_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_workload_capacities_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_workload_capacities_updated_by FOREIGN KEY (updated_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_workload_capacities_hours CHECK (hours_per_day >= 0 AND hours_per_day <= 24)
+);
+
+-- Create indexes. Workloads look up the approved time off of the employees
+-- linked to the members of an organization.
+CREATE INDEX IF NOT EXISTS idx_qultrix_time_off_requests_employee_id ON qultrix.time_off_requests(employee_id, start_date, end_date);
@@ -0,0 +1,4 @@
+-- Remove scheduling from tasks
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS chk_tasks_duration_days;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS duration_days;
+ALTER TABLE taskodex.tasks DROP COLUMN IF EXISTS start_date;
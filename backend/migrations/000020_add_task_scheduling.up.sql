@@ -0,0 +1,8 @@
+-- Add scheduling to tasks. A task is planned to start on its start date and
+-- to take its duration in days; blocking links between tasks are
+-- finish-to-start dependencies on the timeline of their project.
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS start_date TIMESTAMP WITH TIME ZONE;
+ALTER TABLE taskodex.tasks ADD COLUMN IF NOT EXISTS duration_days INTEGER;
+
+ALTER TABLE taskodex.tasks DROP CONSTRAINT IF EXISTS chk_tasks_duration_days;
+ALTER TABLE taskodex.tasks ADD CONSTRAINT chk_tasks_duration_days CHECK (duration_days IS NULL OR duration_days >= 0);
@@ -0,0 +1,5 @@
+-- Drop timesheet_comments table
+DROP TABLE IF EXISTS taskodex.timesheet_comments;
+
+-- Drop timesheets table
+DROP TABLE IF EXISTS taskodex.timesheets;
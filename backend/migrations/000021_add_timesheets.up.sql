@@ -0,0 +1,42 @@
+-- Create timesheets table. A timesheet is the week of time entries a member
+-- of an organization submits to their manager, starting on Monday. Weeks
+-- that were never submitted have no row.
+CREATE TABLE IF NOT EXISTS taskodex.timesheets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    week_start DATE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    total_minutes INTEGER NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_timesheets_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_timesheets_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_timesheets_reviewed_by FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_timesheets_status CHECK (status IN ('submitted', 'approved', 'rejected')),
+    CONSTRAINT chk_timesheets_week_start CHECK (EXTRACT(ISODOW FROM week_start) = 1),
+    CONSTRAINT uq_timesheets_week UNIQUE (organization_id, user_id, week_start)
+);
+
+-- Create timesheet_comments table. Every submission and review of a
+-- timesheet is recorded with its comment, so a timesheet that is rejected
+-- and submitted again keeps the whole exchange.
+CREATE TABLE IF NOT EXISTS taskodex.timesheet_comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    timesheet_id UUID NOT NULL,
+    user_id UUID,
+    action VARCHAR(20) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_timesheet_comments_timesheet FOREIGN KEY (timesheet_id) REFERENCES taskodex.timesheets(id) ON DELETE CASCADE,
+    CONSTRAINT fk_timesheet_comments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_timesheet_comments_action CHECK (action IN ('submitted', 'approved', 'rejected'))
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_timesheets_user_id ON taskodex.timesheets(user_id, week_start);
+CREATE INDEX IF NOT EXISTS idx_timesheets_status ON taskodex.timesheets(organization_id, status);
+CREATE INDEX IF NOT EXISTS idx_timesheet_comments_timesheet_id ON taskodex.timesheet_comments(timesheet_id, created_at);
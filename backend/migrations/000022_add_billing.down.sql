@@ -0,0 +1,8 @@
+-- Drop billing_rates table
+DROP TABLE IF EXISTS taskodex.billing_rates;
+
+-- Drop project_billing_settings table
+DROP TABLE IF EXISTS taskodex.project_billing_settings;
+
+-- Remove billable flag from time entries
+ALTER TABLE taskodex.task_time_entries DROP COLUMN IF EXISTS billable;
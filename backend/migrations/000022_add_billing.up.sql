@@ -0,0 +1,46 @@
+-- Add billable flag to time entries. Time is billable unless marked
+-- otherwise.
+ALTER TABLE taskodex.task_time_entries ADD COLUMN IF NOT EXISTS billable BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Create project_billing_settings table. Projects without a row are billed
+-- in USD without rounding.
+CREATE TABLE IF NOT EXISTS taskodex.project_billing_settings (
+    project_id UUID PRIMARY KEY,
+    client_name VARCHAR(255) NOT NULL DEFAULT '',
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    rounding_minutes INTEGER NOT NULL DEFAULT 0,
+    rounding_mode VARCHAR(20) NOT NULL DEFAULT 'nearest',
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_project_billing_settings_project FOREIGN KEY (project_id) REFERENCES taskodex.projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_project_billing_settings_updated_by FOREIGN KEY (updated_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_project_billing_settings_rounding_minutes CHECK (rounding_minutes BETWEEN 0 AND 60),
+    CONSTRAINT chk_project_billing_settings_rounding_mode CHECK (rounding_mode IN ('up', 'down', 'nearest'))
+);
+
+-- Create billing_rates table. The rate card of a project: hourly rates for
+-- one of its members, for a role, or for everyone else when neither is set,
+-- each over a period of days. effective_to is the last day of the period,
+-- or NULL while it lasts.
+CREATE TABLE IF NOT EXISTS taskodex.billing_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL,
+    user_id UUID,
+    role_id UUID,
+    hourly_rate NUMERIC(12, 2) NOT NULL,
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_billing_rates_project FOREIGN KEY (project_id) REFERENCES taskodex.projects(id) ON DELETE CASCADE,
+    CONSTRAINT fk_billing_rates_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_billing_rates_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    CONSTRAINT fk_billing_rates_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_billing_rates_scope CHECK (user_id IS NULL OR role_id IS NULL),
+    CONSTRAINT chk_billing_rates_hourly_rate CHECK (hourly_rate >= 0),
+    CONSTRAINT chk_billing_rates_period CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_billing_rates_project_id ON taskodex.billing_rates(project_id, effective_from);
@@ -0,0 +1,2 @@
+-- Drop time_entry_policies table
+DROP TABLE IF EXISTS taskodex.time_entry_policies;
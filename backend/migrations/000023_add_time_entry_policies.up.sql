@@ -0,0 +1,18 @@
+-- Create time_entry_policies table. The rules the time entries logged on the
+-- tasks of an organization's projects follow. Organizations without a row
+-- allow a single running timer per user and reject overlapping entries;
+-- max_entry_minutes and auto_stop_minutes are unlimited when 0.
+CREATE TABLE IF NOT EXISTS taskodex.time_entry_policies (
+    organization_id UUID PRIMARY KEY,
+    single_running_timer BOOLEAN NOT NULL DEFAULT TRUE,
+    overlap_mode VARCHAR(20) NOT NULL DEFAULT 'reject',
+    max_entry_minutes INTEGER NOT NULL DEFAULT 0,
+    auto_stop_minutes INTEGER NOT NULL DEFAULT 0,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_time_entry_policies_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_time_entry_policies_updated_by FOREIGN KEY (updated_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_time_entry_policies_overlap_mode CHECK (overlap_mode IN ('allow', 'reject', 'merge')),
+    CONSTRAINT chk_time_entry_policies_max_entry_minutes CHECK (max_entry_minutes >= 0),
+    CONSTRAINT chk_time_entry_policies_auto_stop_minutes CHECK (auto_stop_minutes >= 0)
+);
@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL schema migrations for the Halooid database.
+//
+// Migrations are named NNNNNN_description.up.sql and NNNNNN_description.down.sql
+// and are applied in version order by the migration runner.
+package migrations
+
+import "embed"
+
+// FS contains the SQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
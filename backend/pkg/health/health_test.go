@@ -0,0 +1,121 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeReport(t *testing.T, rec *httptest.ResponseRecorder) health.Report {
+	t.Helper()
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return report
+}
+
+func TestChecker(t *testing.T) {
+	t.Run("Liveness", func(t *testing.T) {
+		checker := health.NewChecker(time.Second)
+		checker.Register("failing", func(ctx context.Context) error {
+			return errors.New("down")
+		})
+
+		rec := httptest.NewRecorder()
+		checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, health.StatusOK, decodeReport(t, rec).Status)
+	})
+
+	t.Run("ReadinessHealthy", func(t *testing.T) {
+		checker := health.NewChecker(time.Second)
+		checker.Register("postgres", func(ctx context.Context) error { return nil })
+		checker.Register("redis", func(ctx context.Context) error { return nil })
+
+		rec := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		report := decodeReport(t, rec)
+		assert.Equal(t, health.StatusOK, report.Status)
+		assert.Equal(t, health.StatusOK, report.Checks["postgres"].Status)
+		assert.Equal(t, health.StatusOK, report.Checks["redis"].Status)
+	})
+
+	t.Run("ReadinessFailingDependency", func(t *testing.T) {
+		checker := health.NewChecker(time.Second)
+		checker.Register("postgres", func(ctx context.Context) error { return nil })
+		checker.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		rec := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		report := decodeReport(t, rec)
+		assert.Equal(t, health.StatusUnavailable, report.Status)
+		assert.Equal(t, health.StatusOK, report.Checks["postgres"].Status)
+		assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		checker := health.NewChecker(20 * time.Millisecond)
+		checker.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		start := time.Now()
+		report := checker.Check(context.Background())
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, health.StatusUnavailable, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		checker := health.NewChecker(time.Second)
+		checker.Register("broken", func(ctx context.Context) error {
+			panic("boom")
+		})
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, health.StatusUnavailable, report.Status)
+		assert.Contains(t, report.Checks["broken"].Error, "boom")
+	})
+
+	t.Run("ShuttingDown", func(t *testing.T) {
+		checker := health.NewChecker(time.Second)
+		checker.Register("postgres", func(ctx context.Context) error { return nil })
+		checker.SetShuttingDown()
+
+		rec := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, health.StatusShuttingDown, decodeReport(t, rec).Status)
+	})
+
+	t.Run("HTTPCheck", func(t *testing.T) {
+		healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer healthy.Close()
+
+		unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer unhealthy.Close()
+
+		assert.NoError(t, health.HTTPCheck(nil, healthy.URL)(context.Background()))
+		assert.Error(t, health.HTTPCheck(nil, unhealthy.URL)(context.Background()))
+	})
+}
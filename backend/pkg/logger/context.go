@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// contextKey is a custom type for context keys
+type contextKey string
+
+const fieldsKey contextKey = "logger_fields"
+
+// requestFieldsKey is the context key of the fields of the request being
+// served, which later middleware updates through WithUserID and WithOrgID
+const requestFieldsKey contextKey = "logger_request_fields"
+
+// fields holds the request-scoped values attached to every log record
+type fields struct {
+	RequestID string
+	TraceID   string
+	UserID    uuid.UUID
+	OrgID     uuid.UUID
+}
+
+// fieldsFromContext returns a copy of the fields stored in the context
+func fieldsFromContext(ctx context.Context) fields {
+	if ctx == nil {
+		return fields{}
+	}
+	f, _ := ctx.Value(fieldsKey).(*fields)
+	if f == nil {
+		return fields{}
+	}
+	return *f
+}
+
+// withFields stores a modified copy of the fields in the context. The
+// fields of the request being served are updated too, so that the log line
+// of its completion has them.
+func withFields(ctx context.Context, update func(f *fields)) context.Context {
+	f := fieldsFromContext(ctx)
+	update(&f)
+	if req, ok := ctx.Value(requestFieldsKey).(*fields); ok {
+		update(req)
+	}
+	return context.WithValue(ctx, fieldsKey, &f)
+}
+
+// WithRequestID sets the request ID in the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return withFields(ctx, func(f *fields) { f.RequestID = requestID })
+}
+
+// WithTraceID sets the trace ID in the context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return withFields(ctx, func(f *fields) { f.TraceID = traceID })
+}
+
+// WithUserID sets the user ID in the context
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return withFields(ctx, func(f *fields) { f.UserID = userID })
+}
+
+// WithOrgID sets the organization ID in the context
+func WithOrgID(ctx context.Context, orgID uuid.UUID) context.Context {
+	return withFields(ctx, func(f *fields) { f.OrgID = orgID })
+}
+
+// RequestIDFromContext gets the request ID from the context
+func RequestIDFromContext(ctx context.Context) string {
+	return fieldsFromContext(ctx).RequestID
+}
+
+// TraceIDFromContext gets the trace ID from the context
+func TraceIDFromContext(ctx context.Context) string {
+	return fieldsFromContext(ctx).TraceID
+}
+
+// FromContext returns the default logger annotated with the request-scoped
+// values (request ID, trace ID, user ID and organization ID) found in the context
+func FromContext(ctx context.Context) *slog.Logger {
+	attrs := attrsFromContext(ctx)
+	if len(attrs) == 0 {
+		return slog.Default()
+	}
+
+	args := make([]any, 0, len(attrs))
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+	return slog.Default().With(args...)
+}
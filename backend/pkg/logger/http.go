@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Header names used to propagate request-scoped identifiers
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceID     = "X-Trace-ID"
+	HeaderTraceParent = "traceparent"
+)
+
+// Middleware creates a middleware that attaches request and trace IDs to the
+// request context and logs every request once it has been served
+func Middleware(l *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Resolve request ID, generating one if the caller did not send it
+			requestID := r.Header.Get(HeaderRequestID)
+			if requestID == "" {
+				requestID = uuid.New().String()
+				r.Header.Set(HeaderRequestID, requestID)
+			}
+			w.Header().Set(HeaderRequestID, requestID)
+
+			ctx := WithRequestID(r.Context(), requestID)
+			if traceID := traceIDFromRequest(r); traceID != "" {
+				ctx = WithTraceID(ctx, traceID)
+			}
+			req := fieldsFromContext(ctx)
+			ctx = context.WithValue(ctx, requestFieldsKey, &req)
+			r = r.WithContext(ctx)
+
+			// Create response writer wrapper
+			ww := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			// Start timer
+			start := time.Now()
+
+			// Call next handler
+			next.ServeHTTP(ww, r)
+
+			// Log response
+			level := slog.LevelInfo
+			if ww.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			} else if ww.status >= http.StatusBadRequest {
+				level = slog.LevelWarn
+			}
+
+			// The user and organization are only known once later middleware
+			// has authenticated the request
+			l.LogAttrs(ctx, level, "request completed",
+				append(attrsFromFields(req),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", ww.status),
+					slog.Int("bytes", ww.bytes),
+					slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+					slog.String("remote_addr", r.RemoteAddr),
+				)...,
+			)
+		})
+	}
+}
+
+// attrsFromContext converts the request-scoped fields in the context to attributes
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	return attrsFromFields(fieldsFromContext(ctx))
+}
+
+// attrsFromFields converts request-scoped fields to attributes
+func attrsFromFields(f fields) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 4)
+	if f.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", f.RequestID))
+	}
+	if f.TraceID != "" {
+		attrs = append(attrs, slog.String("trace_id", f.TraceID))
+	}
+	if f.UserID != uuid.Nil {
+		attrs = append(attrs, slog.String("user_id", f.UserID.String()))
+	}
+	if f.OrgID != uuid.Nil {
+		attrs = append(attrs, slog.String("org_id", f.OrgID.String()))
+	}
+	return attrs
+}
+
+// traceIDFromRequest extracts the trace ID from a W3C traceparent header or X-Trace-ID
+func traceIDFromRequest(r *http.Request) string {
+	// traceparent format: version-traceid-parentid-flags
+	if tp := r.Header.Get(HeaderTraceParent); tp != "" {
+		parts := strings.Split(tp, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+	return r.Header.Get(HeaderTraceID)
+}
+
+// statusWriter records the status code and size of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements http.Flusher so streaming responses keep working
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker so connection upgrades keep working
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying response writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// levelRequest represents the body of a log level change request
+type levelRequest struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler that reports the current log level on
+// GET and changes it on PUT or POST with a body of {"level": "debug"}
+func LevelHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			old := l.Level()
+			if err := l.SetLevel(req.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			FromContext(r.Context()).Info("log level changed",
+				slog.String("old_level", strings.ToLower(old.String())),
+				slog.String("new_level", strings.ToLower(l.Level().String())),
+			)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levelRequest{Level: strings.ToLower(l.Level().String())})
+	})
+}
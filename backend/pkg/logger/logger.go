@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Supported output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config represents the logger configuration
+type Config struct {
+	Level  string `json:"level" yaml:"level"`
+	Format string `json:"format" yaml:"format"`
+}
+
+// Logger wraps a slog.Logger together with its adjustable level
+type Logger struct {
+	*slog.Logger
+	level *slog.LevelVar
+}
+
+// New creates a new Logger writing to w with the given configuration
+func New(cfg Config, w io.Writer) (*Logger, error) {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	// Parse level
+	level := new(slog.LevelVar)
+	parsed, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(parsed)
+
+	// Build handler
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s", cfg.Format)
+	}
+
+	return &Logger{
+		Logger: slog.New(handler),
+		level:  level,
+	}, nil
+}
+
+// Setup creates a new Logger writing to stdout and installs it as the default logger
+func Setup(cfg Config, service string) (*Logger, error) {
+	l, err := New(cfg, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if service != "" {
+		l.Logger = l.Logger.With(slog.String("service", service))
+	}
+
+	slog.SetDefault(l.Logger)
+	return l, nil
+}
+
+// Level returns the current level of the logger
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the level of the logger at runtime
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.level.Set(parsed)
+	return nil
+}
+
+// ParseLevel parses a level name such as "debug", "info", "warn" or "error"
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
+
+// Fatal logs a message at error level and exits the process
+func Fatal(l *slog.Logger, msg string, args ...any) {
+	l.Error(msg, args...)
+	os.Exit(1)
+}
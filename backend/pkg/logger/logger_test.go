@@ -0,0 +1,160 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	var entry map[string]interface{}
+	line := strings.TrimSpace(strings.Split(strings.TrimSpace(buf.String()), "\n")[0])
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+	return entry
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("RejectsUnknownLevelAndFormat", func(t *testing.T) {
+		_, err := logger.New(logger.Config{Level: "verbose"}, &bytes.Buffer{})
+		assert.Error(t, err)
+
+		_, err = logger.New(logger.Config{Format: "xml"}, &bytes.Buffer{})
+		assert.Error(t, err)
+	})
+
+	t.Run("TextFormat", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(logger.Config{Level: "info", Format: "text"}, &buf)
+		require.NoError(t, err)
+
+		l.Info("hello", "key", "value")
+		assert.Contains(t, buf.String(), "msg=hello")
+		assert.Contains(t, buf.String(), "key=value")
+	})
+
+	t.Run("RuntimeLevelChange", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(logger.Config{Level: "info", Format: "json"}, &buf)
+		require.NoError(t, err)
+
+		l.Debug("hidden")
+		assert.Empty(t, buf.String())
+
+		require.NoError(t, l.SetLevel("debug"))
+		l.Debug("visible")
+		assert.Contains(t, buf.String(), "visible")
+
+		assert.Error(t, l.SetLevel("loud"))
+		assert.Equal(t, slog.LevelDebug, l.Level())
+	})
+
+	t.Run("Redaction", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(logger.Config{Level: "info", Format: "json"}, &buf)
+		require.NoError(t, err)
+
+		l.Info("login",
+			"password", "hunter2",
+			"refresh_token", "abc",
+			slog.Group("request", slog.String("Authorization", "Bearer abc.def")),
+			"detail", "header was Bearer eyJhbGciOi.eyJzdWIiOi.c2lnbmF0dXJl",
+		)
+
+		out := buf.String()
+		assert.NotContains(t, out, "hunter2")
+		assert.NotContains(t, out, "abc.def")
+		assert.NotContains(t, out, "eyJhbGciOi")
+
+		entry := decodeLine(t, &buf)
+		assert.Equal(t, logger.RedactedValue, entry["password"])
+		assert.Equal(t, logger.RedactedValue, entry["refresh_token"])
+	})
+
+	t.Run("ContextFields", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(logger.Config{Level: "info", Format: "json"}, &buf)
+		require.NoError(t, err)
+
+		previous := slog.Default()
+		slog.SetDefault(l.Logger)
+		defer slog.SetDefault(previous)
+
+		userID := uuid.New()
+		orgID := uuid.New()
+		ctx := logger.WithRequestID(context.Background(), "req-1")
+		ctx = logger.WithTraceID(ctx, "trace-1")
+		ctx = logger.WithUserID(ctx, userID)
+		ctx = logger.WithOrgID(ctx, orgID)
+
+		logger.FromContext(ctx).Info("scoped")
+
+		entry := decodeLine(t, &buf)
+		assert.Equal(t, "req-1", entry["request_id"])
+		assert.Equal(t, "trace-1", entry["trace_id"])
+		assert.Equal(t, userID.String(), entry["user_id"])
+		assert.Equal(t, orgID.String(), entry["org_id"])
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(logger.Config{Level: "info", Format: "json"}, &buf)
+		require.NoError(t, err)
+
+		var seenRequestID, seenTraceID string
+		userID := uuid.New()
+		handler := logger.Middleware(l.Logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenRequestID = logger.RequestIDFromContext(r.Context())
+			seenTraceID = logger.TraceIDFromContext(r.Context())
+			// Authentication middleware sets the user further down the chain
+			logger.WithUserID(r.Context(), userID)
+			w.WriteHeader(http.StatusTeapot)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+		req.Header.Set(logger.HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.NotEmpty(t, seenRequestID)
+		assert.Equal(t, seenRequestID, rec.Header().Get(logger.HeaderRequestID))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", seenTraceID)
+
+		entry := decodeLine(t, &buf)
+		assert.Equal(t, "WARN", entry["level"])
+		assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+		assert.Equal(t, seenRequestID, entry["request_id"])
+		assert.Equal(t, userID.String(), entry["user_id"])
+	})
+
+	t.Run("LevelHandler", func(t *testing.T) {
+		l, err := logger.New(logger.Config{Level: "info", Format: "json"}, &bytes.Buffer{})
+		require.NoError(t, err)
+		handler := logger.LevelHandler(l)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"error"}`)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"level":"error"}`, rec.Body.String())
+		assert.Equal(t, slog.LevelError, l.Level())
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"loud"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in log output
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys are substrings of attribute keys whose values must never be logged
+var sensitiveKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"api_key",
+	"apikey",
+	"dsn",
+}
+
+// bearerPattern matches bearer tokens embedded in free-form strings
+var bearerPattern = regexp.MustCompile(`(?i)bearer\s+[a-z0-9\-._~+/]+=*`)
+
+// jwtPattern matches JSON Web Tokens embedded in free-form strings
+var jwtPattern = regexp.MustCompile(`eyJ[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+`)
+
+// IsSensitiveKey reports whether values stored under key must be redacted
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString removes bearer tokens and JWTs from a free-form string
+func RedactString(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+RedactedValue)
+	return jwtPattern.ReplaceAllString(s, RedactedValue)
+}
+
+// redactAttr is used as the slog ReplaceAttr hook to scrub sensitive values
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	if a.Value.Kind() == slog.KindString {
+		if s := a.Value.String(); s != "" {
+			if redacted := RedactString(s); redacted != s {
+				return slog.String(a.Key, redacted)
+			}
+		}
+	}
+
+	return a
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/Jerinji2016/halooid/backend/internal/auth"
 	"github.com/Jerinji2016/halooid/backend/internal/models"
+	"github.com/Jerinji2016/halooid/backend/pkg/logger"
 	"github.com/google/uuid"
 )
 
@@ -50,6 +51,10 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		ctx := auth.SetUserIDInContext(r.Context(), claims.UserID)
 		ctx = auth.SetTokenInContext(ctx, tokenString)
 		ctx = auth.SetTokenClaimsInContext(ctx, claims)
+		ctx = logger.WithUserID(ctx, claims.UserID)
+		if claims.OrgID != uuid.Nil {
+			ctx = logger.WithOrgID(ctx, claims.OrgID)
+		}
 
 		// Call the next handler with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))